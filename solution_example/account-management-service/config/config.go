@@ -9,13 +9,26 @@ import (
 
 type (
 	Config struct {
-		App    `yaml:"app"`
-		HTTP   `yaml:"http"`
-		Log    `yaml:"log"`
-		PG     `yaml:"postgres"`
-		JWT    `yaml:"jwt"`
-		Hasher `yaml:"hasher"`
-		WebAPI `yaml:"webapi"`
+		App                `yaml:"app"`
+		HTTP               `yaml:"http"`
+		Log                `yaml:"log"`
+		PG                 `yaml:"postgres"`
+		JWT                `yaml:"jwt"`
+		Users              `yaml:"users"`
+		Hasher             `yaml:"hasher"`
+		WebAPI             `yaml:"webapi"`
+		Reports            `yaml:"reports"`
+		ReportJobs         `yaml:"report_jobs"`
+		ReportSchedules    `yaml:"report_schedules"`
+		SMTP               `yaml:"smtp"`
+		Wallets            `yaml:"wallets"`
+		Fees               `yaml:"fees"`
+		Subscriptions      `yaml:"subscriptions"`
+		ScheduledTransfers `yaml:"scheduled_transfers"`
+		PaymentRequests    `yaml:"payment_requests"`
+		Installments       `yaml:"installments"`
+		Approvals          `yaml:"approvals"`
+		Batch              `yaml:"batch"`
 	}
 
 	App struct {
@@ -25,6 +38,8 @@ type (
 
 	HTTP struct {
 		Port string `env-required:"true" yaml:"port" env:"HTTP_PORT"`
+		// ExportWriteTimeout limits the time of writing the operations export response instead of the server write timeout
+		ExportWriteTimeout time.Duration `yaml:"export_write_timeout" env:"HTTP_EXPORT_WRITE_TIMEOUT"`
 	}
 
 	Log struct {
@@ -41,6 +56,11 @@ type (
 		TokenTTL time.Duration `env-required:"true" yaml:"token_ttl" env:"JWT_TOKEN_TTL"`
 	}
 
+	Users struct {
+		// Roles maps the usernames to the roles granted to them on start, e.g. the first admin
+		Roles map[string]string `yaml:"roles" env:"USERS_ROLES" env-separator:","`
+	}
+
 	Hasher struct {
 		Salt string `env-required:"true" env:"HASHER_SALT"`
 	}
@@ -48,6 +68,125 @@ type (
 	WebAPI struct {
 		GDriveJSONFilePath string `env-required:"false" env:"GOOGLE_DRIVE_JSON_FILE_PATH"`
 	}
+
+	Reports struct {
+		Storage string `env-required:"true" yaml:"storage"  env:"REPORTS_STORAGE"`
+		BaseURL string `env-required:"true" yaml:"base_url" env:"REPORTS_BASE_URL"`
+		Local   struct {
+			Dir string `yaml:"dir" env:"REPORTS_LOCAL_DIR"`
+		} `yaml:"local"`
+		S3 struct {
+			Endpoint  string        `yaml:"endpoint" env:"REPORTS_S3_ENDPOINT"`
+			Region    string        `yaml:"region"   env:"REPORTS_S3_REGION"`
+			Bucket    string        `yaml:"bucket"   env:"REPORTS_S3_BUCKET"`
+			AccessKey string        `env:"REPORTS_S3_ACCESS_KEY"`
+			SecretKey string        `env:"REPORTS_S3_SECRET_KEY"`
+			LinkTTL   time.Duration `yaml:"link_ttl" env:"REPORTS_S3_LINK_TTL"`
+		} `yaml:"s3"`
+		Links struct {
+			Signed  bool          `yaml:"signed"   env:"REPORTS_LINKS_SIGNED"`
+			SignKey string        `env:"REPORTS_LINKS_SIGN_KEY"`
+			TTL     time.Duration `yaml:"ttl"      env:"REPORTS_LINKS_TTL"`
+			OneTime bool          `yaml:"one_time" env:"REPORTS_LINKS_ONE_TIME"`
+		} `yaml:"links"`
+		Archive struct {
+			// Retention is how long the reports are kept in the storage, 0 keeps them forever
+			Retention     time.Duration `yaml:"retention"      env:"REPORTS_ARCHIVE_RETENTION"`
+			PurgeInterval time.Duration `yaml:"purge_interval" env:"REPORTS_ARCHIVE_PURGE_INTERVAL" env-default:"1h"`
+		} `yaml:"archive"`
+	}
+
+	ReportJobs struct {
+		RunInterval  time.Duration `env-required:"true" yaml:"run_interval"  env:"REPORT_JOBS_RUN_INTERVAL"`
+		MaxAttempts  int           `env-required:"true" yaml:"max_attempts"  env:"REPORT_JOBS_MAX_ATTEMPTS"`
+		RetryBackoff time.Duration `env-required:"true" yaml:"retry_backoff" env:"REPORT_JOBS_RETRY_BACKOFF"`
+		StaleAfter   time.Duration `env-required:"true" yaml:"stale_after"   env:"REPORT_JOBS_STALE_AFTER"`
+	}
+
+	ReportSchedules struct {
+		RunInterval time.Duration `env-required:"true" yaml:"run_interval" env:"REPORT_SCHEDULES_RUN_INTERVAL"`
+		// Timezone is the time zone of the cron expressions and of the report periods
+		Timezone      string           `yaml:"timezone"       env:"REPORT_SCHEDULES_TIMEZONE" env-default:"UTC"`
+		CatchUp       time.Duration    `env-required:"true" yaml:"catch_up"       env:"REPORT_SCHEDULES_CATCH_UP"`
+		MaxAttempts   int              `env-required:"true" yaml:"max_attempts"   env:"REPORT_SCHEDULES_MAX_ATTEMPTS"`
+		RetryInterval time.Duration    `env-required:"true" yaml:"retry_interval" env:"REPORT_SCHEDULES_RETRY_INTERVAL"`
+		StaleAfter    time.Duration    `env-required:"true" yaml:"stale_after"    env:"REPORT_SCHEDULES_STALE_AFTER"`
+		Schedules     []ReportSchedule `yaml:"schedules"`
+	}
+
+	ReportSchedule struct {
+		Name     string          `yaml:"name"`
+		Cron     string          `yaml:"cron"`
+		Period   string          `yaml:"period"`
+		Report   ReportParams    `yaml:"report"`
+		Channels []ReportChannel `yaml:"channels"`
+	}
+
+	ReportParams struct {
+		Version     int    `yaml:"version"`
+		Mode        string `yaml:"mode"`
+		Granularity string `yaml:"granularity"`
+		ProductIds  []int  `yaml:"product_ids"`
+		AccountIds  []int  `yaml:"account_ids"`
+		Format      string `yaml:"format"`
+		Delimiter   string `yaml:"delimiter"`
+		BOM         bool   `yaml:"bom"`
+	}
+
+	ReportChannel struct {
+		Type       string   `yaml:"type"`
+		Recipients []string `yaml:"recipients"`
+		Attach     bool     `yaml:"attach"`
+	}
+
+	// SMTP is the server the reports are sent by email through, the email channel is not available without the host
+	SMTP struct {
+		Host     string `yaml:"host" env:"SMTP_HOST"`
+		Port     int    `yaml:"port" env:"SMTP_PORT"`
+		Username string `env:"SMTP_USERNAME"`
+		Password string `env:"SMTP_PASSWORD"`
+		From     string `yaml:"from" env:"SMTP_FROM"`
+	}
+
+	Wallets struct {
+		SpendingOrder []string `yaml:"spending_order" env:"WALLETS_SPENDING_ORDER" env-separator:","`
+	}
+
+	Fees struct {
+		AccountId int `env-required:"true" yaml:"account_id" env:"FEES_ACCOUNT_ID"`
+	}
+
+	Subscriptions struct {
+		ChargeInterval time.Duration `env-required:"true" yaml:"charge_interval" env:"SUBSCRIPTIONS_CHARGE_INTERVAL"`
+		RetryInterval  time.Duration `env-required:"true" yaml:"retry_interval"  env:"SUBSCRIPTIONS_RETRY_INTERVAL"`
+		GracePeriod    time.Duration `env-required:"true" yaml:"grace_period"    env:"SUBSCRIPTIONS_GRACE_PERIOD"`
+	}
+
+	ScheduledTransfers struct {
+		RunInterval time.Duration `env-required:"true" yaml:"run_interval" env:"SCHEDULED_TRANSFERS_RUN_INTERVAL"`
+	}
+
+	PaymentRequests struct {
+		TTL time.Duration `env-required:"true" yaml:"ttl" env:"PAYMENT_REQUESTS_TTL"`
+	}
+
+	Installments struct {
+		RunInterval   time.Duration `env-required:"true" yaml:"run_interval"   env:"INSTALLMENTS_RUN_INTERVAL"`
+		RetryInterval time.Duration `env-required:"true" yaml:"retry_interval" env:"INSTALLMENTS_RETRY_INTERVAL"`
+		MaxAttempts   int           `env-required:"true" yaml:"max_attempts"   env:"INSTALLMENTS_MAX_ATTEMPTS"`
+		CancelOrder   bool          `yaml:"cancel_order" env:"INSTALLMENTS_CANCEL_ORDER"`
+	}
+
+	Approvals struct {
+		Thresholds  map[string]int `yaml:"thresholds" env:"APPROVALS_THRESHOLDS" env-separator:","`
+		TTL         time.Duration  `env-required:"true" yaml:"ttl"          env:"APPROVALS_TTL"`
+		RunInterval time.Duration  `env-required:"true" yaml:"run_interval" env:"APPROVALS_RUN_INTERVAL"`
+	}
+
+	Batch struct {
+		ChunkSize int `env-required:"true" yaml:"chunk_size" env:"BATCH_CHUNK_SIZE"`
+		MaxItems  int `env-required:"true" yaml:"max_items"  env:"BATCH_MAX_ITEMS"`
+	}
 )
 
 func NewConfig(configPath string) (*Config, error) {
@@ -44,7 +44,7 @@ const docTemplate = `{
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/account-management-service_internal_controller_http_v1.getBalanceInput"
+                            "$ref": "#/definitions/internal_controller_http_v1.getBalanceInput"
                         }
                     }
                 ],
@@ -52,7 +52,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/account-management-service_internal_controller_http_v1.accountRoutes"
+                            "$ref": "#/definitions/internal_controller_http_v1.accountRoutes"
                         }
                     },
                     "400": {
@@ -70,14 +70,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/accounts/create": {
+        "/api/v1/accounts/close": {
             "post": {
                 "security": [
                     {
                         "JWT": []
                     }
                 ],
-                "description": "Create account",
+                "description": "Close account. Account must have zero balance unless payout of the remainder is requested. Admin only",
                 "consumes": [
                     "application/json"
                 ],
@@ -87,12 +87,23 @@ const docTemplate = `{
                 "tags": [
                     "accounts"
                 ],
-                "summary": "Create account",
+                "summary": "Close account",
+                "parameters": [
+                    {
+                        "description": "input",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_controller_http_v1.accountCloseInput"
+                        }
+                    }
+                ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/account-management-service_internal_controller_http_v1.accountRoutes"
+                            "$ref": "#/definitions/internal_controller_http_v1.accountRoutes"
                         }
                     },
                     "400": {
@@ -101,6 +112,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/echo.HTTPError"
                         }
                     },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -110,14 +127,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/accounts/deposit": {
+        "/api/v1/accounts/create": {
             "post": {
                 "security": [
                     {
                         "JWT": []
                     }
                 ],
-                "description": "Deposit",
+                "description": "Create account",
                 "consumes": [
                     "application/json"
                 ],
@@ -127,21 +144,23 @@ const docTemplate = `{
                 "tags": [
                     "accounts"
                 ],
-                "summary": "Deposit",
+                "summary": "Create account",
                 "parameters": [
                     {
                         "description": "input",
                         "name": "input",
                         "in": "body",
-                        "required": true,
                         "schema": {
-                            "$ref": "#/definitions/account-management-service_internal_controller_http_v1.accountDepositInput"
+                            "$ref": "#/definitions/internal_controller_http_v1.accountCreateInput"
                         }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK"
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/internal_controller_http_v1.accountRoutes"
+                        }
                     },
                     "400": {
                         "description": "Bad Request",
@@ -158,14 +177,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/accounts/transfer": {
+        "/api/v1/accounts/deposit": {
             "post": {
                 "security": [
                     {
                         "JWT": []
                     }
                 ],
-                "description": "Transfer",
+                "description": "Deposit. Deposits which need approval are submitted as operation requests and executed after another user approves them",
                 "consumes": [
                     "application/json"
                 ],
@@ -175,7 +194,7 @@ const docTemplate = `{
                 "tags": [
                     "accounts"
                 ],
-                "summary": "Transfer",
+                "summary": "Deposit",
                 "parameters": [
                     {
                         "description": "input",
@@ -183,7 +202,7 @@ const docTemplate = `{
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/account-management-service_internal_controller_http_v1.accountTransferInput"
+                            "$ref": "#/definitions/internal_controller_http_v1.accountDepositInput"
                         }
                     }
                 ],
@@ -191,6 +210,9 @@ const docTemplate = `{
                     "200": {
                         "description": "OK"
                     },
+                    "202": {
+                        "description": "Accepted"
+                    },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
@@ -206,14 +228,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/accounts/withdraw": {
+        "/api/v1/accounts/freeze": {
             "post": {
                 "security": [
                     {
                         "JWT": []
                     }
                 ],
-                "description": "Withdraw",
+                "description": "Freeze account fully or for outgoing operations only. Admin only",
                 "consumes": [
                     "application/json"
                 ],
@@ -223,7 +245,7 @@ const docTemplate = `{
                 "tags": [
                     "accounts"
                 ],
-                "summary": "Withdraw",
+                "summary": "Freeze account",
                 "parameters": [
                     {
                         "description": "input",
@@ -231,7 +253,7 @@ const docTemplate = `{
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/account-management-service_internal_controller_http_v1.accountWithdrawInput"
+                            "$ref": "#/definitions/internal_controller_http_v1.accountFreezeInput"
                         }
                     }
                 ],
@@ -245,6 +267,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/echo.HTTPError"
                         }
                     },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -254,14 +282,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/operations/history": {
+        "/api/v1/accounts/obligations": {
             "get": {
                 "security": [
                     {
                         "JWT": []
                     }
                 ],
-                "description": "Get history of operations",
+                "description": "Get the unpaid parts of the account installment plans, the closest due first. Overdue parts which are being retried are included",
                 "consumes": [
                     "application/json"
                 ],
@@ -269,9 +297,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "operations"
+                    "accounts"
                 ],
-                "summary": "Get history",
+                "summary": "Get upcoming obligations",
                 "parameters": [
                     {
                         "description": "input",
@@ -279,7 +307,7 @@ const docTemplate = `{
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/internal_controller_http_v1.getHistoryInput"
+                            "$ref": "#/definitions/internal_controller_http_v1.getBalanceInput"
                         }
                     }
                 ],
@@ -287,7 +315,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/internal_controller_http_v1.operationRoutes"
+                            "$ref": "#/definitions/internal_controller_http_v1.accountRoutes"
                         }
                     },
                     "400": {
@@ -305,24 +333,24 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/operations/report-file": {
+        "/api/v1/accounts/scheduled-transfers": {
             "get": {
                 "security": [
                     {
                         "JWT": []
                     }
                 ],
-                "description": "Get report file",
+                "description": "Get scheduled transfer with the results of its runs",
                 "consumes": [
                     "application/json"
                 ],
                 "produces": [
-                    "text/csv"
+                    "application/json"
                 ],
                 "tags": [
-                    "operations"
+                    "accounts"
                 ],
-                "summary": "Get report file",
+                "summary": "Get scheduled transfer",
                 "parameters": [
                     {
                         "description": "input",
@@ -330,13 +358,16 @@ const docTemplate = `{
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/internal_controller_http_v1.getReportInput"
+                            "$ref": "#/definitions/internal_controller_http_v1.scheduledTransferInput"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK"
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_controller_http_v1.accountRoutes"
+                        }
                     },
                     "400": {
                         "description": "Bad Request",
@@ -353,14 +384,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/operations/report-link": {
-            "get": {
+        "/api/v1/accounts/scheduled-transfers/cancel": {
+            "post": {
                 "security": [
                     {
                         "JWT": []
                     }
                 ],
-                "description": "Get link to report",
+                "description": "Cancel scheduled transfer which has not been executed yet",
                 "consumes": [
                     "application/json"
                 ],
@@ -368,9 +399,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "operations"
+                    "accounts"
                 ],
-                "summary": "Get report link",
+                "summary": "Cancel scheduled transfer",
                 "parameters": [
                     {
                         "description": "input",
@@ -378,16 +409,13 @@ const docTemplate = `{
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/internal_controller_http_v1.getReportInput"
+                            "$ref": "#/definitions/internal_controller_http_v1.scheduledTransferInput"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/internal_controller_http_v1.operationRoutes"
-                        }
+                        "description": "OK"
                     },
                     "400": {
                         "description": "Bad Request",
@@ -395,6 +423,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/echo.HTTPError"
                         }
                     },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -404,14 +438,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/products/create": {
-            "post": {
+        "/api/v1/accounts/status-history": {
+            "get": {
                 "security": [
                     {
                         "JWT": []
                     }
                 ],
-                "description": "Create product",
+                "description": "Get history of account status changes with reason codes. Admin only",
                 "consumes": [
                     "application/json"
                 ],
@@ -419,14 +453,25 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "products"
+                    "accounts"
+                ],
+                "summary": "Get status history",
+                "parameters": [
+                    {
+                        "description": "input",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_controller_http_v1.getStatusHistoryInput"
+                        }
+                    }
                 ],
-                "summary": "Create product",
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/account-management-service_internal_controller_http_v1.productRoutes"
+                            "$ref": "#/definitions/internal_controller_http_v1.accountRoutes"
                         }
                     },
                     "400": {
@@ -435,6 +480,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/echo.HTTPError"
                         }
                     },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -444,14 +495,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/products/getById": {
-            "get": {
+        "/api/v1/accounts/transfer": {
+            "post": {
                 "security": [
                     {
                         "JWT": []
                     }
                 ],
-                "description": "Get product by id",
+                "description": "Transfer right away, or schedule it if execute_at or repeat is set",
                 "consumes": [
                     "application/json"
                 ],
@@ -459,14 +510,28 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "products"
+                    "accounts"
+                ],
+                "summary": "Transfer",
+                "parameters": [
+                    {
+                        "description": "input",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_controller_http_v1.accountTransferInput"
+                        }
+                    }
                 ],
-                "summary": "Get product by id",
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "OK"
+                    },
+                    "202": {
+                        "description": "Accepted",
                         "schema": {
-                            "$ref": "#/definitions/account-management-service_internal_controller_http_v1.productRoutes"
+                            "$ref": "#/definitions/internal_controller_http_v1.accountRoutes"
                         }
                     },
                     "400": {
@@ -475,6 +540,18 @@ const docTemplate = `{
                             "$ref": "#/definitions/echo.HTTPError"
                         }
                     },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -484,14 +561,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/reservations/create": {
+        "/api/v1/accounts/unfreeze": {
             "post": {
                 "security": [
                     {
                         "JWT": []
                     }
                 ],
-                "description": "Create reservation",
+                "description": "Make frozen account active again. Admin only",
                 "consumes": [
                     "application/json"
                 ],
@@ -499,9 +576,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "reservations"
+                    "accounts"
                 ],
-                "summary": "Create reservation",
+                "summary": "Unfreeze account",
                 "parameters": [
                     {
                         "description": "input",
@@ -509,16 +586,13 @@ const docTemplate = `{
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/account-management-service_internal_controller_http_v1.reservationCreateInput"
+                            "$ref": "#/definitions/internal_controller_http_v1.accountUnfreezeInput"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
-                        "schema": {
-                            "$ref": "#/definitions/account-management-service_internal_controller_http_v1.reservationRoutes"
-                        }
+                    "200": {
+                        "description": "OK"
                     },
                     "400": {
                         "description": "Bad Request",
@@ -526,6 +600,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/echo.HTTPError"
                         }
                     },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -535,14 +615,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/reservations/refund": {
+        "/api/v1/accounts/withdraw": {
             "post": {
                 "security": [
                     {
                         "JWT": []
                     }
                 ],
-                "description": "Refund reservation",
+                "description": "Withdraw. Withdrawals from the approval threshold are submitted as operation requests and executed after another user approves them",
                 "consumes": [
                     "application/json"
                 ],
@@ -550,9 +630,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "reservations"
+                    "accounts"
                 ],
-                "summary": "Refund reservation",
+                "summary": "Withdraw",
                 "parameters": [
                     {
                         "description": "input",
@@ -560,7 +640,7 @@ const docTemplate = `{
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/account-management-service_internal_controller_http_v1.reservationRefundInput"
+                            "$ref": "#/definitions/internal_controller_http_v1.accountWithdrawInput"
                         }
                     }
                 ],
@@ -568,12 +648,27 @@ const docTemplate = `{
                     "200": {
                         "description": "OK"
                     },
+                    "202": {
+                        "description": "Accepted"
+                    },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
                             "$ref": "#/definitions/echo.HTTPError"
                         }
                     },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -583,14 +678,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/reservations/revenue": {
-            "post": {
+        "/api/v1/adjustments/": {
+            "get": {
                 "security": [
                     {
                         "JWT": []
                     }
                 ],
-                "description": "Revenue reservation",
+                "description": "Get adjustments of the account with their reasons and tickets, newest first. Admins only",
                 "consumes": [
                     "application/json"
                 ],
@@ -598,9 +693,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "reservations"
+                    "adjustments"
                 ],
-                "summary": "Revenue reservation",
+                "summary": "Get account adjustments",
                 "parameters": [
                     {
                         "description": "input",
@@ -608,13 +703,16 @@ const docTemplate = `{
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/account-management-service_internal_controller_http_v1.reservationRevenueInput"
+                            "$ref": "#/definitions/internal_controller_http_v1.adjustmentGetAccountAdjustmentsInput"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK"
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_controller_http_v1.adjustmentRoutes"
+                        }
                     },
                     "400": {
                         "description": "Bad Request",
@@ -622,6 +720,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/echo.HTTPError"
                         }
                     },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -631,9 +735,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/auth/sign-in": {
+        "/api/v1/adjustments/create": {
             "post": {
-                "description": "Sign in",
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Credit or debit the account to correct its balance. The reason code must be one of the active reasons. Adjustments which need approval are submitted as operation requests. Admins only",
                 "consumes": [
                     "application/json"
                 ],
@@ -641,9 +750,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "auth"
+                    "adjustments"
                 ],
-                "summary": "Sign in",
+                "summary": "Create adjustment",
                 "parameters": [
                     {
                         "description": "input",
@@ -651,23 +760,32 @@ const docTemplate = `{
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/account-management-service_internal_controller_http_v1.signInInput"
+                            "$ref": "#/definitions/internal_controller_http_v1.adjustmentCreateInput"
                         }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/account-management-service_internal_controller_http_v1.authRoutes"
+                            "$ref": "#/definitions/internal_controller_http_v1.adjustmentRoutes"
                         }
                     },
+                    "202": {
+                        "description": "Accepted"
+                    },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
                             "$ref": "#/definitions/echo.HTTPError"
                         }
                     },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -677,9 +795,51 @@ const docTemplate = `{
                 }
             }
         },
-        "/auth/sign-up": {
+        "/api/v1/adjustments/reasons": {
+            "get": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Get all adjustment reasons including inactive ones. Admins only",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "adjustments"
+                ],
+                "summary": "Get adjustment reasons",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_controller_http_v1.adjustmentRoutes"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/adjustments/reasons/deactivate": {
             "post": {
-                "description": "Sign up",
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Forbid new adjustments with the reason, existing adjustments keep it. Admins only",
                 "consumes": [
                     "application/json"
                 ],
@@ -687,9 +847,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "auth"
+                    "adjustments"
                 ],
-                "summary": "Sign up",
+                "summary": "Deactivate adjustment reason",
                 "parameters": [
                     {
                         "description": "input",
@@ -697,16 +857,13 @@ const docTemplate = `{
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/account-management-service_internal_controller_http_v1.signUpInput"
+                            "$ref": "#/definitions/internal_controller_http_v1.adjustmentDeactivateReasonInput"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
-                        "schema": {
-                            "$ref": "#/definitions/account-management-service_internal_controller_http_v1.authRoutes"
-                        }
+                    "200": {
+                        "description": "OK"
                     },
                     "400": {
                         "description": "Bad Request",
@@ -714,6 +871,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/echo.HTTPError"
                         }
                     },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -722,120 +885,6041 @@ const docTemplate = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "account-management-service_internal_controller_http_v1.accountDepositInput": {
+        },
+        "/api/v1/adjustments/reasons/set": {
+            "post": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Add the adjustment reason or update the description of the existing one, the reason becomes active. Admins only",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "adjustments"
+                ],
+                "summary": "Set adjustment reason",
+                "parameters": [
+                    {
+                        "description": "input",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_controller_http_v1.adjustmentSetReasonInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/approvals/": {
+            "get": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Get operation requests, optionally filtered by status, newest first. Approvers only",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "approvals"
+                ],
+                "summary": "Get operation requests",
+                "parameters": [
+                    {
+                        "description": "input",
+                        "name": "input",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/account-management-service_internal_controller_http_v1.approvalGetRequestsInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/account-management-service_internal_controller_http_v1.approvalRoutes"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/approvals/approve": {
+            "post": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Approve the pending operation request and execute the operation. The request must be approved by another user than the one who submitted it. Approvers only",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "approvals"
+                ],
+                "summary": "Approve operation",
+                "parameters": [
+                    {
+                        "description": "input",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/account-management-service_internal_controller_http_v1.approvalResolveInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/account-management-service_internal_controller_http_v1.approvalRoutes"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/approvals/reject": {
+            "post": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Reject the pending operation request. The request must be rejected by another user than the one who submitted it. Approvers only",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "approvals"
+                ],
+                "summary": "Reject operation",
+                "parameters": [
+                    {
+                        "description": "input",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/account-management-service_internal_controller_http_v1.approvalResolveInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/approvals/request": {
+            "get": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Get the operation request with its approval audit trail. Approvers only",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "approvals"
+                ],
+                "summary": "Get operation request",
+                "parameters": [
+                    {
+                        "description": "input",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/account-management-service_internal_controller_http_v1.approvalGetRequestInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/account-management-service_internal_controller_http_v1.approvalRoutes"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/batch/execute": {
+            "post": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Execute a list of deposits and transfers. Every item is validated before anything is executed.\nIn atomic mode all the items are executed in one transaction, in best_effort mode items are executed in chunks and failed ones are skipped.\nTransfers are subject to the sender limits, verification and fees like a single transfer.\nDeposits which need approval are refused, they must be submitted through /accounts/deposit",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "batch"
+                ],
+                "summary": "Execute batch",
+                "parameters": [
+                    {
+                        "description": "input",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/account-management-service_internal_controller_http_v1.batchExecuteInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/account-management-service_internal_controller_http_v1.batchRoutes"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/account-management-service_internal_controller_http_v1.batchRoutes"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/cashback/": {
+            "get": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Get all cashback rules. Admin only",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "cashback"
+                ],
+                "summary": "Get cashback rules",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_controller_http_v1.cashbackRoutes"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/cashback/create": {
+            "post": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Create cashback rule for a product. Rate is in basis points of the money spent from the main wallet, 100 is 1%, cap limits the cashback per order, 0 is no limit. Admin only",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "cashback"
+                ],
+                "summary": "Create cashback rule",
+                "parameters": [
+                    {
+                        "description": "input",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_controller_http_v1.cashbackCreateInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/internal_controller_http_v1.cashbackRoutes"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/cashback/delete": {
+            "post": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Delete cashback rule, the cashback already granted by it is kept. Admin only",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "cashback"
+                ],
+                "summary": "Delete cashback rule",
+                "parameters": [
+                    {
+                        "description": "input",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_controller_http_v1.cashbackDeleteInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/fees/": {
+            "get": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Get all fee rules. Admin only",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "fees"
+                ],
+                "summary": "Get fee rules",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_controller_http_v1.feeRoutes"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/fees/create": {
+            "post": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Create fee rule for an operation type, optionally narrowed to a product and an account type. Rate is in basis points, 100 is 1%. Admin only",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "fees"
+                ],
+                "summary": "Create fee rule",
+                "parameters": [
+                    {
+                        "description": "input",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_controller_http_v1.feeCreateInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/internal_controller_http_v1.feeRoutes"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/fees/delete": {
+            "post": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Delete fee rule. Admin only",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "fees"
+                ],
+                "summary": "Delete fee rule",
+                "parameters": [
+                    {
+                        "description": "input",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_controller_http_v1.feeDeleteInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/fees/income": {
+            "get": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Get the fees collected in the [from, to) range. Fees are not in the revenue report, as they are not the revenue of any product. Admin only",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "fees"
+                ],
+                "summary": "Get fee income",
+                "parameters": [
+                    {
+                        "description": "input",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/account-management-service_internal_controller_http_v1.feeIncomeInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/account-management-service_internal_controller_http_v1.feeRoutes"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/fees/quote": {
+            "get": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Preview the fee which would be charged for the operation, the total is the amount debited from the main wallet",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "fees"
+                ],
+                "summary": "Quote fee",
+                "parameters": [
+                    {
+                        "description": "input",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_controller_http_v1.feeQuoteInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_controller_http_v1.feeRoutes"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/groups/create": {
+            "post": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Create business account shared by several users, the current user becomes its owner",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "groups"
+                ],
+                "summary": "Create group account",
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/account-management-service_internal_controller_http_v1.groupRoutes"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/groups/members": {
+            "get": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Get members of the group account with their roles and limits. Members only",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "groups"
+                ],
+                "summary": "Get group members",
+                "parameters": [
+                    {
+                        "description": "input",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/account-management-service_internal_controller_http_v1.groupMembersInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/account-management-service_internal_controller_http_v1.groupRoutes"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/groups/members/remove": {
+            "post": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Remove member from the group account. Owner only",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "groups"
+                ],
+                "summary": "Remove group member",
+                "parameters": [
+                    {
+                        "description": "input",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/account-management-service_internal_controller_http_v1.groupRemoveMemberInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/groups/members/set": {
+            "post": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Add member to the group account or change the role and the monthly spending limit of a member, 0 is no limit. Owner only",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "groups"
+                ],
+                "summary": "Set group member",
+                "parameters": [
+                    {
+                        "description": "input",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/account-management-service_internal_controller_http_v1.groupSetMemberInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/groups/reserve": {
+            "post": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Create reservation from the group account on behalf of the current user. The user must be an owner or a spender and stay within the monthly limit",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "groups"
+                ],
+                "summary": "Reserve from group account",
+                "parameters": [
+                    {
+                        "description": "input",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/account-management-service_internal_controller_http_v1.groupReserveInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/account-management-service_internal_controller_http_v1.groupRoutes"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/installments/": {
+            "get": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Get installment plan with its parts and the paid and outstanding amounts",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "installments"
+                ],
+                "summary": "Get installment plan",
+                "parameters": [
+                    {
+                        "description": "input",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/account-management-service_internal_controller_http_v1.installmentGetInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/account-management-service_internal_controller_http_v1.installmentRoutes"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/installments/create": {
+            "post": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Pay for the order in parts: first_payment (or an equal part) is reserved right away, the rest of the amount is split evenly and reserved once per period",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "installments"
+                ],
+                "summary": "Create installment plan",
+                "parameters": [
+                    {
+                        "description": "input",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/account-management-service_internal_controller_http_v1.installmentCreateInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/account-management-service_internal_controller_http_v1.installmentRoutes"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/limits/": {
+            "get": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Get all limit rules. Admin only",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "limits"
+                ],
+                "summary": "Get limit rules",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_controller_http_v1.limitRoutes"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/limits/create": {
+            "post": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Create limit rule for all accounts, an account type or a single account. Admin only",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "limits"
+                ],
+                "summary": "Create limit rule",
+                "parameters": [
+                    {
+                        "description": "input",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_controller_http_v1.limitCreateInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/internal_controller_http_v1.limitRoutes"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/limits/delete": {
+            "post": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Delete limit rule. Admin only",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "limits"
+                ],
+                "summary": "Delete limit rule",
+                "parameters": [
+                    {
+                        "description": "input",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_controller_http_v1.limitDeleteInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/operations/export": {
+            "get": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Export operations created in the [from, to) range. The operations are streamed as they are read from the database, without the format param the format is chosen by the Accept header",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "text/csv",
+                    "application/json",
+                    "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+                    "application/vnd.apache.parquet"
+                ],
+                "tags": [
+                    "operations"
+                ],
+                "summary": "Export operations",
+                "parameters": [
+                    {
+                        "description": "input",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_controller_http_v1.getExportInput"
+                        }
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "export only the operations of the types",
+                        "name": "operation_type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "export format: csv (default), xlsx, json or parquet",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "csv delimiter: comma (default), semicolon or tab",
+                        "name": "delimiter",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "prepend the byte order mark to the csv export",
+                        "name": "bom",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/operations/export-link": {
+            "get": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Export operations created in the [from, to) range to the report storage and get the link to the file",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "operations"
+                ],
+                "summary": "Get operations export link",
+                "parameters": [
+                    {
+                        "description": "input",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_controller_http_v1.getExportInput"
+                        }
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "export only the operations of the types",
+                        "name": "operation_type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "export format: csv (default), xlsx, json or parquet",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "csv delimiter: comma (default), semicolon or tab",
+                        "name": "delimiter",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "prepend the byte order mark to the csv export",
+                        "name": "bom",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_controller_http_v1.operationRoutes"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/operations/history": {
+            "get": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Get history of operations. For shared accounts every operation names the member who made it and members contains the spending of each member",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "operations"
+                ],
+                "summary": "Get history",
+                "parameters": [
+                    {
+                        "description": "input",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_controller_http_v1.getHistoryInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_controller_http_v1.operationRoutes"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/operations/report-file": {
+            "get": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Get report file, the report is streamed as it is generated. Without the format param the format is chosen by the Accept header",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "text/csv",
+                    "application/json",
+                    "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+                    "application/vnd.apache.parquet"
+                ],
+                "tags": [
+                    "operations"
+                ],
+                "summary": "Get report file",
+                "parameters": [
+                    {
+                        "description": "input",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_controller_http_v1.getReportInput"
+                        }
+                    },
+                    {
+                        "type": "integer",
+                        "description": "report version: 1 - legacy headerless report, 2 - current (default)",
+                        "name": "version",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "report mode: products (default) or accounts - revenue of every product by accounts",
+                        "name": "mode",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "integer"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "limit the accounts report to the products",
+                        "name": "product_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "integer"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "limit the accounts report to the accounts",
+                        "name": "account_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "make the products report the time series by day, week, month or quarter",
+                        "name": "granularity",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "report format: csv (default), xlsx, json or parquet",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "csv delimiter: comma (default), semicolon or tab",
+                        "name": "delimiter",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "prepend the byte order mark to the csv report",
+                        "name": "bom",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/operations/report-link": {
+            "get": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Get link to report",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "operations"
+                ],
+                "summary": "Get report link",
+                "parameters": [
+                    {
+                        "description": "input",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_controller_http_v1.getReportInput"
+                        }
+                    },
+                    {
+                        "type": "integer",
+                        "description": "report version: 1 - legacy headerless report, 2 - current (default)",
+                        "name": "version",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "report mode: products (default) or accounts - revenue of every product by accounts",
+                        "name": "mode",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "integer"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "limit the accounts report to the products",
+                        "name": "product_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "integer"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "limit the accounts report to the accounts",
+                        "name": "account_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "make the products report the time series by day, week, month or quarter",
+                        "name": "granularity",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "report format: csv (default), xlsx, json or parquet",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "csv delimiter: comma (default), semicolon or tab",
+                        "name": "delimiter",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "prepend the byte order mark to the csv report",
+                        "name": "bom",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_controller_http_v1.operationRoutes"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/payment-requests/": {
+            "get": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Get payment request by id",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "payment-requests"
+                ],
+                "summary": "Get payment request",
+                "parameters": [
+                    {
+                        "description": "input",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_controller_http_v1.paymentRequestIdInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/entity.PaymentRequest"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/payment-requests/accept": {
+            "post": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Pay the request, the amount is transferred from the payer to the requester",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "payment-requests"
+                ],
+                "summary": "Accept payment request",
+                "parameters": [
+                    {
+                        "description": "input",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_controller_http_v1.paymentRequestIdInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/payment-requests/create": {
+            "post": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Request money from the payer account to the requester account",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "payment-requests"
+                ],
+                "summary": "Create payment request",
+                "parameters": [
+                    {
+                        "description": "input",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_controller_http_v1.paymentRequestCreateInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/internal_controller_http_v1.paymentRequestRoutes"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/payment-requests/decline": {
+            "post": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Decline the request without paying it",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "payment-requests"
+                ],
+                "summary": "Decline payment request",
+                "parameters": [
+                    {
+                        "description": "input",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_controller_http_v1.paymentRequestIdInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/payment-requests/list": {
+            "get": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Get incoming and outgoing payment requests of the account, optionally filtered by status",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "payment-requests"
+                ],
+                "summary": "Get account payment requests",
+                "parameters": [
+                    {
+                        "description": "input",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_controller_http_v1.paymentRequestListInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_controller_http_v1.paymentRequestRoutes"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/products/create": {
+            "post": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Create product",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "products"
+                ],
+                "summary": "Create product",
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/internal_controller_http_v1.productRoutes"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/products/getById": {
+            "get": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Get product by id",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "products"
+                ],
+                "summary": "Get product by id",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_controller_http_v1.productRoutes"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/report-archive/": {
+            "get": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Get the reports and exports in the report storage, the latest first. The files uploaded before the archive existed have the unknown kind and no metadata. Admins only",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reports"
+                ],
+                "summary": "Get archived reports",
+                "parameters": [
+                    {
+                        "description": "input",
+                        "name": "input",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/internal_controller_http_v1.reportArchiveGetReportsInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_controller_http_v1.reportArchiveRoutes"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/report-archive/delete": {
+            "post": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Delete the report from the report storage and the archive. Admins only",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reports"
+                ],
+                "summary": "Delete archived report",
+                "parameters": [
+                    {
+                        "description": "input",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_controller_http_v1.reportArchiveIdInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/report-archive/download": {
+            "get": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Download the file of the archived report. Admins only",
+                "produces": [
+                    "application/octet-stream"
+                ],
+                "tags": [
+                    "reports"
+                ],
+                "summary": "Download archived report",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "archived report id",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/report-jobs/": {
+            "get": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Get status and progress of the report job, the finished job contains the link to the report",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reports"
+                ],
+                "summary": "Get report job",
+                "parameters": [
+                    {
+                        "description": "input",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_controller_http_v1.reportJobGetInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_controller_http_v1.reportJobRoutes"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/report-jobs/create": {
+            "post": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Queue generation of the revenue report. If the same report is already being generated, the id of that job is returned",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reports"
+                ],
+                "summary": "Create report job",
+                "parameters": [
+                    {
+                        "description": "input",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_controller_http_v1.reportJobCreateInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/internal_controller_http_v1.reportJobRoutes"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/report-links/accesses": {
+            "get": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Get all attempts to download the report by the link, including rejected ones. Admins only",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reports"
+                ],
+                "summary": "Get report link accesses",
+                "parameters": [
+                    {
+                        "description": "input",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/account-management-service_internal_controller_http_v1.reportLinkGetAccessesInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/account-management-service_internal_controller_http_v1.reportLinkRoutes"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/report-schedules/": {
+            "get": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Get the configured report schedules with the next time they are run",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reports"
+                ],
+                "summary": "Get report schedules",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_controller_http_v1.reportScheduleRoutes"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/report-schedules/runs": {
+            "get": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Get the history of the scheduled runs, the latest first. Without the schedule the runs of all schedules are returned",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reports"
+                ],
+                "summary": "Get report schedule runs",
+                "parameters": [
+                    {
+                        "description": "input",
+                        "name": "input",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/internal_controller_http_v1.reportScheduleRunsInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_controller_http_v1.reportScheduleRoutes"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/reservations/create": {
+            "post": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Create reservation",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reservations"
+                ],
+                "summary": "Create reservation",
+                "parameters": [
+                    {
+                        "description": "input",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/account-management-service_internal_controller_http_v1.reservationCreateInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/account-management-service_internal_controller_http_v1.reservationRoutes"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/reservations/refund": {
+            "post": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Refund reservation of the order which is not captured yet, captured orders are refunded through /reservations/revenue-refund.\nIf refunds need approval, the refund is submitted as an operation request and executed after another user approves it",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reservations"
+                ],
+                "summary": "Refund reservation",
+                "parameters": [
+                    {
+                        "description": "input",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/account-management-service_internal_controller_http_v1.reservationRefundInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "202": {
+                        "description": "Accepted"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/reservations/revenue": {
+            "post": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Revenue reservation",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reservations"
+                ],
+                "summary": "Revenue reservation",
+                "parameters": [
+                    {
+                        "description": "input",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/account-management-service_internal_controller_http_v1.reservationRevenueInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/reservations/revenue-refund": {
+            "post": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Refund the order whose revenue is already recognized: the money is returned to the wallets it was taken from and the cashback is taken back.\nIf refunds of captured orders need approval, the refund is submitted as an operation request and executed after another user approves it",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reservations"
+                ],
+                "summary": "Refund captured order",
+                "parameters": [
+                    {
+                        "description": "input",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/account-management-service_internal_controller_http_v1.reservationRefundInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "202": {
+                        "description": "Accepted"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/subscriptions/": {
+            "get": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Get subscription by id",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "Get subscription",
+                "parameters": [
+                    {
+                        "description": "input",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_controller_http_v1.subscriptionIdInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/entity.Subscription"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/subscriptions/cancel": {
+            "post": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Cancel subscription, cancelled subscription cannot be resumed",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "Cancel subscription",
+                "parameters": [
+                    {
+                        "description": "input",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_controller_http_v1.subscriptionIdInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/subscriptions/charges": {
+            "get": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Get all charge attempts of the subscription, both succeeded and failed",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "Get subscription charges",
+                "parameters": [
+                    {
+                        "description": "input",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_controller_http_v1.subscriptionIdInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_controller_http_v1.subscriptionRoutes"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/subscriptions/create": {
+            "post": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Create subscription, the first period is charged at start_at or right away",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "Create subscription",
+                "parameters": [
+                    {
+                        "description": "input",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_controller_http_v1.subscriptionCreateInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/internal_controller_http_v1.subscriptionRoutes"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/subscriptions/list": {
+            "get": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Get all subscriptions of the account",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "Get account subscriptions",
+                "parameters": [
+                    {
+                        "description": "input",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_controller_http_v1.subscriptionListInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_controller_http_v1.subscriptionRoutes"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/subscriptions/pause": {
+            "post": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Pause active or past due subscription, it is not charged until resumed",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "Pause subscription",
+                "parameters": [
+                    {
+                        "description": "input",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_controller_http_v1.subscriptionIdInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/subscriptions/resume": {
+            "post": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Resume paused or suspended subscription, a missed period is charged right away",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "Resume subscription",
+                "parameters": [
+                    {
+                        "description": "input",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_controller_http_v1.subscriptionIdInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/verification/history": {
+            "get": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Get history of account verification level changes. Admin only",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "verification"
+                ],
+                "summary": "Get verification history",
+                "parameters": [
+                    {
+                        "description": "input",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_controller_http_v1.verificationHistoryInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_controller_http_v1.verificationRoutes"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/verification/level": {
+            "post": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Set account verification level after the identity check. Identity service only",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "verification"
+                ],
+                "summary": "Set verification level",
+                "parameters": [
+                    {
+                        "description": "input",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_controller_http_v1.verificationSetLevelInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/verification/policies": {
+            "get": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Get the operations allowed to each verification level and their limits. Admin only",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "verification"
+                ],
+                "summary": "Get verification policies",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_controller_http_v1.verificationRoutes"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/verification/policies/delete": {
+            "post": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Forbid the operation to the verification level. Admin only",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "verification"
+                ],
+                "summary": "Delete verification policy",
+                "parameters": [
+                    {
+                        "description": "input",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_controller_http_v1.verificationDeletePolicyInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/verification/policies/set": {
+            "post": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Allow the operation to the verification level with the given limits, zero limits mean no limit. Admin only",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "verification"
+                ],
+                "summary": "Set verification policy",
+                "parameters": [
+                    {
+                        "description": "input",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_controller_http_v1.verificationSetPolicyInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/vouchers/campaigns/create": {
+            "post": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Generate count unique voucher codes, each can be redeemed max_uses times but once per account. Admin only",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "vouchers"
+                ],
+                "summary": "Create voucher campaign",
+                "parameters": [
+                    {
+                        "description": "input",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_controller_http_v1.voucherCreateCampaignInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/internal_controller_http_v1.voucherRoutes"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/vouchers/campaigns/report": {
+            "get": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Get issued vs redeemed codes and amounts of the campaign. Admin only",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "vouchers"
+                ],
+                "summary": "Get voucher campaign report",
+                "parameters": [
+                    {
+                        "description": "input",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_controller_http_v1.voucherCampaignReportInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_controller_http_v1.voucherRoutes"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/vouchers/redeem": {
+            "post": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Credit the voucher amount to the account wallet",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "vouchers"
+                ],
+                "summary": "Redeem voucher",
+                "parameters": [
+                    {
+                        "description": "input",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_controller_http_v1.voucherRedeemInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_controller_http_v1.voucherRoutes"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/sign-in": {
+            "post": {
+                "description": "Sign in",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Sign in",
+                "parameters": [
+                    {
+                        "description": "input",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/account-management-service_internal_controller_http_v1.signInInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/account-management-service_internal_controller_http_v1.authRoutes"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/sign-up": {
+            "post": {
+                "description": "Sign up",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Sign up",
+                "parameters": [
+                    {
+                        "description": "input",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/account-management-service_internal_controller_http_v1.signUpInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/account-management-service_internal_controller_http_v1.authRoutes"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/reports/download/{id}": {
+            "get": {
+                "description": "Download the report by the signed link returned by /api/v1/operations/report-link. The link works until it expires, one-time links work only once. No authorization required",
+                "produces": [
+                    "text/csv"
+                ],
+                "tags": [
+                    "reports"
+                ],
+                "summary": "Download report",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "link id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "expiration time",
+                        "name": "expires",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "signature",
+                        "name": "signature",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "410": {
+                        "description": "Gone",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "account-management-service_internal_controller_http_v1.accountCloseInput": {
+            "type": "object",
+            "required": [
+                "id",
+                "reason_code"
+            ],
+            "properties": {
+                "comment": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "payout": {
+                    "type": "boolean"
+                },
+                "reason_code": {
+                    "type": "string",
+                    "enum": [
+                        "fraud_suspected",
+                        "security_incident",
+                        "compliance_check",
+                        "user_request",
+                        "other"
+                    ]
+                }
+            }
+        },
+        "account-management-service_internal_controller_http_v1.accountCreateInput": {
+            "type": "object",
+            "properties": {
+                "type": {
+                    "type": "string",
+                    "enum": [
+                        "personal",
+                        "business"
+                    ]
+                }
+            }
+        },
+        "account-management-service_internal_controller_http_v1.accountDepositInput": {
+            "type": "object",
+            "required": [
+                "amount",
+                "id"
+            ],
+            "properties": {
+                "amount": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "wallet": {
+                    "type": "string",
+                    "enum": [
+                        "main",
+                        "bonus"
+                    ]
+                }
+            }
+        },
+        "account-management-service_internal_controller_http_v1.accountFreezeInput": {
+            "type": "object",
+            "required": [
+                "id",
+                "reason_code"
+            ],
+            "properties": {
+                "comment": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "debit_only": {
+                    "type": "boolean"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "reason_code": {
+                    "type": "string",
+                    "enum": [
+                        "fraud_suspected",
+                        "security_incident",
+                        "compliance_check",
+                        "user_request",
+                        "other"
+                    ]
+                }
+            }
+        },
+        "account-management-service_internal_controller_http_v1.accountRoutes": {
+            "type": "object"
+        },
+        "account-management-service_internal_controller_http_v1.accountTransferInput": {
+            "type": "object",
+            "required": [
+                "amount",
+                "from",
+                "to"
+            ],
+            "properties": {
+                "amount": {
+                    "type": "integer"
+                },
+                "execute_at": {
+                    "type": "string"
+                },
+                "from": {
+                    "type": "integer"
+                },
+                "repeat": {
+                    "type": "string",
+                    "enum": [
+                        "weekly"
+                    ]
+                },
+                "to": {
+                    "type": "integer"
+                }
+            }
+        },
+        "account-management-service_internal_controller_http_v1.accountUnfreezeInput": {
+            "type": "object",
+            "required": [
+                "id",
+                "reason_code"
+            ],
+            "properties": {
+                "comment": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "reason_code": {
+                    "type": "string",
+                    "enum": [
+                        "resolved",
+                        "compliance_check",
+                        "user_request",
+                        "other"
+                    ]
+                }
+            }
+        },
+        "account-management-service_internal_controller_http_v1.accountWithdrawInput": {
+            "type": "object",
+            "required": [
+                "amount",
+                "id"
+            ],
+            "properties": {
+                "amount": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "account-management-service_internal_controller_http_v1.adjustmentCreateInput": {
+            "type": "object",
+            "required": [
+                "account_id",
+                "amount",
+                "comment",
+                "direction",
+                "reason_code"
+            ],
+            "properties": {
+                "account_id": {
+                    "type": "integer"
+                },
+                "amount": {
+                    "type": "integer"
+                },
+                "comment": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "direction": {
+                    "type": "string",
+                    "enum": [
+                        "credit",
+                        "debit"
+                    ]
+                },
+                "reason_code": {
+                    "type": "string",
+                    "maxLength": 64
+                },
+                "ticket": {
+                    "type": "string",
+                    "maxLength": 64
+                },
+                "wallet": {
+                    "type": "string",
+                    "enum": [
+                        "main",
+                        "bonus"
+                    ]
+                }
+            }
+        },
+        "account-management-service_internal_controller_http_v1.adjustmentDeactivateReasonInput": {
+            "type": "object",
+            "required": [
+                "code"
+            ],
+            "properties": {
+                "code": {
+                    "type": "string"
+                }
+            }
+        },
+        "account-management-service_internal_controller_http_v1.adjustmentGetAccountAdjustmentsInput": {
+            "type": "object",
+            "required": [
+                "account_id"
+            ],
+            "properties": {
+                "account_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "account-management-service_internal_controller_http_v1.adjustmentRoutes": {
+            "type": "object"
+        },
+        "account-management-service_internal_controller_http_v1.adjustmentSetReasonInput": {
+            "type": "object",
+            "required": [
+                "code",
+                "description"
+            ],
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "maxLength": 64
+                },
+                "description": {
+                    "type": "string",
+                    "maxLength": 255
+                }
+            }
+        },
+        "account-management-service_internal_controller_http_v1.approvalGetRequestInput": {
+            "type": "object",
+            "required": [
+                "id"
+            ],
+            "properties": {
+                "id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "account-management-service_internal_controller_http_v1.approvalGetRequestsInput": {
+            "type": "object",
+            "properties": {
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "pending",
+                        "approved",
+                        "executed",
+                        "failed",
+                        "rejected",
+                        "expired"
+                    ]
+                }
+            }
+        },
+        "account-management-service_internal_controller_http_v1.approvalResolveInput": {
+            "type": "object",
+            "required": [
+                "id"
+            ],
+            "properties": {
+                "comment": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "account-management-service_internal_controller_http_v1.approvalRoutes": {
+            "type": "object"
+        },
+        "account-management-service_internal_controller_http_v1.authRoutes": {
+            "type": "object"
+        },
+        "account-management-service_internal_controller_http_v1.batchExecuteInput": {
+            "type": "object",
+            "required": [
+                "items",
+                "mode"
+            ],
+            "properties": {
+                "description": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/account-management-service_internal_controller_http_v1.batchItemInput"
+                    }
+                },
+                "mode": {
+                    "type": "string",
+                    "enum": [
+                        "atomic",
+                        "best_effort"
+                    ]
+                }
+            }
+        },
+        "account-management-service_internal_controller_http_v1.batchItemInput": {
+            "type": "object",
+            "properties": {
+                "account_id": {
+                    "type": "integer"
+                },
+                "amount": {
+                    "type": "integer"
+                },
+                "from": {
+                    "type": "integer"
+                },
+                "to": {
+                    "type": "integer"
+                },
+                "type": {
+                    "type": "string"
+                },
+                "wallet": {
+                    "type": "string"
+                }
+            }
+        },
+        "account-management-service_internal_controller_http_v1.batchRoutes": {
+            "type": "object"
+        },
+        "account-management-service_internal_controller_http_v1.cashbackCreateInput": {
+            "type": "object",
+            "required": [
+                "product_id",
+                "rate"
+            ],
+            "properties": {
+                "cap": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "product_id": {
+                    "type": "integer"
+                },
+                "rate": {
+                    "type": "integer",
+                    "maximum": 10000
+                },
+                "valid_from": {
+                    "type": "string"
+                },
+                "valid_to": {
+                    "type": "string"
+                }
+            }
+        },
+        "account-management-service_internal_controller_http_v1.cashbackDeleteInput": {
+            "type": "object",
+            "required": [
+                "id"
+            ],
+            "properties": {
+                "id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "account-management-service_internal_controller_http_v1.cashbackRoutes": {
+            "type": "object"
+        },
+        "account-management-service_internal_controller_http_v1.feeCreateInput": {
+            "type": "object",
+            "required": [
+                "operation_type"
+            ],
+            "properties": {
+                "account_type": {
+                    "type": "string",
+                    "enum": [
+                        "personal",
+                        "business"
+                    ]
+                },
+                "fixed": {
+                    "type": "integer"
+                },
+                "free_up_to": {
+                    "type": "integer"
+                },
+                "max_fee": {
+                    "type": "integer"
+                },
+                "min_fee": {
+                    "type": "integer"
+                },
+                "operation_type": {
+                    "type": "string",
+                    "enum": [
+                        "withdraw",
+                        "transfer_from",
+                        "reservation"
+                    ]
+                },
+                "product_id": {
+                    "type": "integer"
+                },
+                "rate": {
+                    "type": "integer"
+                }
+            }
+        },
+        "account-management-service_internal_controller_http_v1.feeDeleteInput": {
+            "type": "object",
+            "required": [
+                "id"
+            ],
+            "properties": {
+                "id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "account-management-service_internal_controller_http_v1.feeIncomeInput": {
+            "type": "object",
+            "required": [
+                "from",
+                "to"
+            ],
+            "properties": {
+                "from": {
+                    "type": "string"
+                },
+                "to": {
+                    "type": "string"
+                }
+            }
+        },
+        "account-management-service_internal_controller_http_v1.feeQuoteInput": {
+            "type": "object",
+            "required": [
+                "account_id",
+                "amount",
+                "operation_type"
+            ],
+            "properties": {
+                "account_id": {
+                    "type": "integer"
+                },
+                "amount": {
+                    "type": "integer"
+                },
+                "operation_type": {
+                    "type": "string",
+                    "enum": [
+                        "withdraw",
+                        "transfer_from",
+                        "reservation"
+                    ]
+                },
+                "product_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "account-management-service_internal_controller_http_v1.feeRoutes": {
+            "type": "object"
+        },
+        "account-management-service_internal_controller_http_v1.getBalanceInput": {
+            "type": "object",
+            "required": [
+                "id"
+            ],
+            "properties": {
+                "id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "account-management-service_internal_controller_http_v1.getExportInput": {
+            "type": "object",
+            "required": [
+                "from",
+                "to"
+            ],
+            "properties": {
+                "account_id": {
+                    "type": "integer"
+                },
+                "from": {
+                    "type": "string"
+                },
+                "to": {
+                    "type": "string"
+                }
+            }
+        },
+        "account-management-service_internal_controller_http_v1.getHistoryInput": {
+            "type": "object",
+            "required": [
+                "account_id"
+            ],
+            "properties": {
+                "account_id": {
+                    "type": "integer"
+                },
+                "limit": {
+                    "type": "integer"
+                },
+                "offset": {
+                    "type": "integer"
+                },
+                "sort_type": {
+                    "type": "string"
+                }
+            }
+        },
+        "account-management-service_internal_controller_http_v1.getReportInput": {
+            "type": "object",
+            "properties": {
+                "from": {
+                    "type": "string"
+                },
+                "month": {
+                    "type": "integer"
+                },
+                "to": {
+                    "type": "string"
+                },
+                "year": {
+                    "type": "integer"
+                }
+            }
+        },
+        "account-management-service_internal_controller_http_v1.getStatusHistoryInput": {
+            "type": "object",
+            "required": [
+                "id"
+            ],
+            "properties": {
+                "id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "account-management-service_internal_controller_http_v1.groupMembersInput": {
+            "type": "object",
+            "required": [
+                "account_id"
+            ],
+            "properties": {
+                "account_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "account-management-service_internal_controller_http_v1.groupRemoveMemberInput": {
+            "type": "object",
+            "required": [
+                "account_id",
+                "user_id"
+            ],
+            "properties": {
+                "account_id": {
+                    "type": "integer"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "account-management-service_internal_controller_http_v1.groupReserveInput": {
+            "type": "object",
+            "required": [
+                "account_id",
+                "amount",
+                "order_id",
+                "product_id"
+            ],
+            "properties": {
+                "account_id": {
+                    "type": "integer"
+                },
+                "amount": {
+                    "type": "integer"
+                },
+                "order_id": {
+                    "type": "integer"
+                },
+                "product_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "account-management-service_internal_controller_http_v1.groupRoutes": {
+            "type": "object"
+        },
+        "account-management-service_internal_controller_http_v1.groupSetMemberInput": {
+            "type": "object",
+            "required": [
+                "account_id",
+                "role",
+                "user_id"
+            ],
+            "properties": {
+                "account_id": {
+                    "type": "integer"
+                },
+                "monthly_limit": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "role": {
+                    "type": "string",
+                    "enum": [
+                        "owner",
+                        "spender",
+                        "viewer"
+                    ]
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "account-management-service_internal_controller_http_v1.installmentCreateInput": {
+            "type": "object",
+            "required": [
+                "account_id",
+                "amount",
+                "order_id",
+                "parts",
+                "period",
+                "product_id"
+            ],
+            "properties": {
+                "account_id": {
+                    "type": "integer"
+                },
+                "amount": {
+                    "type": "integer"
+                },
+                "first_payment": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "order_id": {
+                    "type": "integer"
+                },
+                "parts": {
+                    "type": "integer",
+                    "maximum": 24,
+                    "minimum": 2
+                },
+                "period": {
+                    "type": "string",
+                    "enum": [
+                        "day",
+                        "week",
+                        "month"
+                    ]
+                },
+                "product_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "account-management-service_internal_controller_http_v1.installmentGetInput": {
+            "type": "object",
+            "required": [
+                "id"
+            ],
+            "properties": {
+                "id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "account-management-service_internal_controller_http_v1.installmentRoutes": {
+            "type": "object"
+        },
+        "account-management-service_internal_controller_http_v1.limitCreateInput": {
+            "type": "object",
+            "required": [
+                "limit_type",
+                "operation_type",
+                "scope",
+                "value"
+            ],
+            "properties": {
+                "account_id": {
+                    "type": "integer"
+                },
+                "account_type": {
+                    "type": "string",
+                    "enum": [
+                        "personal",
+                        "business"
+                    ]
+                },
+                "limit_type": {
+                    "type": "string",
+                    "enum": [
+                        "max_amount",
+                        "max_total",
+                        "max_count"
+                    ]
+                },
+                "operation_type": {
+                    "type": "string",
+                    "enum": [
+                        "withdraw",
+                        "transfer_from",
+                        "reservation"
+                    ]
+                },
+                "scope": {
+                    "type": "string",
+                    "enum": [
+                        "global",
+                        "account_type",
+                        "account"
+                    ]
+                },
+                "value": {
+                    "type": "integer"
+                },
+                "window_seconds": {
+                    "type": "integer"
+                }
+            }
+        },
+        "account-management-service_internal_controller_http_v1.limitDeleteInput": {
+            "type": "object",
+            "required": [
+                "id"
+            ],
+            "properties": {
+                "id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "account-management-service_internal_controller_http_v1.limitRoutes": {
+            "type": "object"
+        },
+        "account-management-service_internal_controller_http_v1.operationRoutes": {
+            "type": "object",
+            "properties": {
+                "service.Operation": {}
+            }
+        },
+        "account-management-service_internal_controller_http_v1.paymentRequestCreateInput": {
+            "type": "object",
+            "required": [
+                "amount",
+                "payer_account_id",
+                "requester_account_id"
+            ],
+            "properties": {
+                "amount": {
+                    "type": "integer"
+                },
+                "description": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "payer_account_id": {
+                    "type": "integer"
+                },
+                "requester_account_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "account-management-service_internal_controller_http_v1.paymentRequestIdInput": {
+            "type": "object",
+            "required": [
+                "id"
+            ],
+            "properties": {
+                "id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "account-management-service_internal_controller_http_v1.paymentRequestListInput": {
+            "type": "object",
+            "required": [
+                "account_id"
+            ],
+            "properties": {
+                "account_id": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "pending",
+                        "paid",
+                        "declined",
+                        "expired"
+                    ]
+                }
+            }
+        },
+        "account-management-service_internal_controller_http_v1.paymentRequestRoutes": {
+            "type": "object"
+        },
+        "account-management-service_internal_controller_http_v1.productRoutes": {
+            "type": "object"
+        },
+        "account-management-service_internal_controller_http_v1.reportArchiveGetReportsInput": {
+            "type": "object",
+            "properties": {
+                "created_by": {
+                    "type": "integer"
+                },
+                "kind": {
+                    "type": "string",
+                    "enum": [
+                        "report",
+                        "export",
+                        "unknown"
+                    ]
+                },
+                "limit": {
+                    "type": "integer",
+                    "minimum": 1
+                },
+                "offset": {
+                    "type": "integer",
+                    "minimum": 0
+                }
+            }
+        },
+        "account-management-service_internal_controller_http_v1.reportArchiveIdInput": {
+            "type": "object",
+            "required": [
+                "id"
+            ],
+            "properties": {
+                "id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "account-management-service_internal_controller_http_v1.reportArchiveRoutes": {
+            "type": "object"
+        },
+        "account-management-service_internal_controller_http_v1.reportJobCreateInput": {
+            "type": "object",
+            "properties": {
+                "account_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "bom": {
+                    "type": "boolean"
+                },
+                "delimiter": {
+                    "type": "string",
+                    "enum": [
+                        "comma",
+                        "semicolon",
+                        "tab"
+                    ]
+                },
+                "format": {
+                    "type": "string",
+                    "enum": [
+                        "csv",
+                        "xlsx",
+                        "json",
+                        "parquet"
+                    ]
+                },
+                "from": {
+                    "type": "string"
+                },
+                "granularity": {
+                    "type": "string",
+                    "enum": [
+                        "day",
+                        "week",
+                        "month",
+                        "quarter"
+                    ]
+                },
+                "mode": {
+                    "type": "string",
+                    "enum": [
+                        "products",
+                        "accounts"
+                    ]
+                },
+                "month": {
+                    "type": "integer",
+                    "maximum": 12,
+                    "minimum": 1
+                },
+                "product_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "to": {
+                    "type": "string"
+                },
+                "version": {
+                    "type": "integer",
+                    "enum": [
+                        1,
+                        2
+                    ]
+                },
+                "year": {
+                    "type": "integer"
+                }
+            }
+        },
+        "account-management-service_internal_controller_http_v1.reportJobGetInput": {
+            "type": "object",
+            "required": [
+                "id"
+            ],
+            "properties": {
+                "id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "account-management-service_internal_controller_http_v1.reportJobRoutes": {
+            "type": "object"
+        },
+        "account-management-service_internal_controller_http_v1.reportLinkGetAccessesInput": {
+            "type": "object",
+            "required": [
+                "link_id"
+            ],
+            "properties": {
+                "link_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "account-management-service_internal_controller_http_v1.reportLinkRoutes": {
+            "type": "object"
+        },
+        "account-management-service_internal_controller_http_v1.reportScheduleRoutes": {
+            "type": "object"
+        },
+        "account-management-service_internal_controller_http_v1.reportScheduleRunsInput": {
+            "type": "object",
+            "properties": {
+                "limit": {
+                    "type": "integer",
+                    "minimum": 1
+                },
+                "offset": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "schedule": {
+                    "type": "string"
+                }
+            }
+        },
+        "account-management-service_internal_controller_http_v1.reservationCreateInput": {
+            "type": "object",
+            "required": [
+                "account_id",
+                "amount",
+                "order_id",
+                "product_id"
+            ],
+            "properties": {
+                "account_id": {
+                    "type": "integer"
+                },
+                "amount": {
+                    "type": "integer"
+                },
+                "order_id": {
+                    "type": "integer"
+                },
+                "product_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "account-management-service_internal_controller_http_v1.reservationRefundInput": {
+            "type": "object",
+            "required": [
+                "order_id"
+            ],
+            "properties": {
+                "order_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "account-management-service_internal_controller_http_v1.reservationRevenueInput": {
+            "type": "object",
+            "required": [
+                "account_id",
+                "amount",
+                "order_id",
+                "product_id"
+            ],
+            "properties": {
+                "account_id": {
+                    "type": "integer"
+                },
+                "amount": {
+                    "type": "integer"
+                },
+                "order_id": {
+                    "type": "integer"
+                },
+                "product_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "account-management-service_internal_controller_http_v1.reservationRoutes": {
+            "type": "object"
+        },
+        "account-management-service_internal_controller_http_v1.scheduledTransferInput": {
+            "type": "object",
+            "required": [
+                "id"
+            ],
+            "properties": {
+                "id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "account-management-service_internal_controller_http_v1.signInInput": {
+            "type": "object",
+            "required": [
+                "password",
+                "username"
+            ],
+            "properties": {
+                "password": {
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string",
+                    "maxLength": 32,
+                    "minLength": 4
+                }
+            }
+        },
+        "account-management-service_internal_controller_http_v1.signUpInput": {
+            "type": "object",
+            "required": [
+                "password",
+                "username"
+            ],
+            "properties": {
+                "password": {
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string",
+                    "maxLength": 32,
+                    "minLength": 4
+                }
+            }
+        },
+        "account-management-service_internal_controller_http_v1.subscriptionCreateInput": {
+            "type": "object",
+            "required": [
+                "account_id",
+                "amount",
+                "period",
+                "product_id"
+            ],
+            "properties": {
+                "account_id": {
+                    "type": "integer"
+                },
+                "amount": {
+                    "type": "integer"
+                },
+                "period": {
+                    "type": "string",
+                    "enum": [
+                        "day",
+                        "week",
+                        "month"
+                    ]
+                },
+                "product_id": {
+                    "type": "integer"
+                },
+                "start_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "account-management-service_internal_controller_http_v1.subscriptionIdInput": {
+            "type": "object",
+            "required": [
+                "id"
+            ],
+            "properties": {
+                "id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "account-management-service_internal_controller_http_v1.subscriptionListInput": {
+            "type": "object",
+            "required": [
+                "account_id"
+            ],
+            "properties": {
+                "account_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "account-management-service_internal_controller_http_v1.subscriptionRoutes": {
+            "type": "object"
+        },
+        "account-management-service_internal_controller_http_v1.verificationDeletePolicyInput": {
+            "type": "object",
+            "required": [
+                "level",
+                "operation_type"
+            ],
+            "properties": {
+                "level": {
+                    "type": "string"
+                },
+                "operation_type": {
+                    "type": "string"
+                }
+            }
+        },
+        "account-management-service_internal_controller_http_v1.verificationHistoryInput": {
+            "type": "object",
+            "required": [
+                "account_id"
+            ],
+            "properties": {
+                "account_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "account-management-service_internal_controller_http_v1.verificationRoutes": {
+            "type": "object"
+        },
+        "account-management-service_internal_controller_http_v1.verificationSetLevelInput": {
+            "type": "object",
+            "required": [
+                "account_id",
+                "level"
+            ],
+            "properties": {
+                "account_id": {
+                    "type": "integer"
+                },
+                "level": {
+                    "type": "string",
+                    "enum": [
+                        "none",
+                        "basic",
+                        "full"
+                    ]
+                },
+                "reference": {
+                    "type": "string",
+                    "maxLength": 255
+                }
+            }
+        },
+        "account-management-service_internal_controller_http_v1.verificationSetPolicyInput": {
+            "type": "object",
+            "required": [
+                "level",
+                "operation_type"
+            ],
+            "properties": {
+                "level": {
+                    "type": "string",
+                    "enum": [
+                        "none",
+                        "basic",
+                        "full"
+                    ]
+                },
+                "max_amount": {
+                    "type": "integer"
+                },
+                "max_total": {
+                    "type": "integer"
+                },
+                "operation_type": {
+                    "type": "string",
+                    "enum": [
+                        "withdraw",
+                        "transfer_from"
+                    ]
+                },
+                "window_seconds": {
+                    "type": "integer"
+                }
+            }
+        },
+        "account-management-service_internal_controller_http_v1.voucherCampaignReportInput": {
+            "type": "object",
+            "required": [
+                "campaign_id"
+            ],
+            "properties": {
+                "campaign_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "account-management-service_internal_controller_http_v1.voucherCreateCampaignInput": {
+            "type": "object",
+            "required": [
+                "amount",
+                "count",
+                "expires_at",
+                "name"
+            ],
+            "properties": {
+                "amount": {
+                    "type": "integer"
+                },
+                "count": {
+                    "type": "integer",
+                    "maximum": 10000
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "max_uses": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "wallet": {
+                    "type": "string",
+                    "enum": [
+                        "main",
+                        "bonus"
+                    ]
+                }
+            }
+        },
+        "account-management-service_internal_controller_http_v1.voucherRedeemInput": {
+            "type": "object",
+            "required": [
+                "account_id",
+                "code"
+            ],
+            "properties": {
+                "account_id": {
+                    "type": "integer"
+                },
+                "code": {
+                    "type": "string"
+                }
+            }
+        },
+        "account-management-service_internal_controller_http_v1.voucherRoutes": {
+            "type": "object"
+        },
+        "echo.HTTPError": {
+            "type": "object",
+            "properties": {
+                "message": {}
+            }
+        },
+        "entity.PaymentRequest": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "integer"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "expiresAt": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "payerAccountId": {
+                    "type": "integer"
+                },
+                "requesterAccountId": {
+                    "type": "integer"
+                },
+                "resolvedAt": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "entity.Subscription": {
+            "type": "object",
+            "properties": {
+                "accountId": {
+                    "type": "integer"
+                },
+                "amount": {
+                    "type": "integer"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "failedAttempts": {
+                    "type": "integer"
+                },
+                "graceUntil": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "nextAttemptAt": {
+                    "type": "string"
+                },
+                "nextChargeAt": {
+                    "type": "string"
+                },
+                "period": {
+                    "type": "string"
+                },
+                "productId": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_controller_http_v1.accountCloseInput": {
+            "type": "object",
+            "required": [
+                "id",
+                "reason_code"
+            ],
+            "properties": {
+                "comment": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "payout": {
+                    "type": "boolean"
+                },
+                "reason_code": {
+                    "type": "string",
+                    "enum": [
+                        "fraud_suspected",
+                        "security_incident",
+                        "compliance_check",
+                        "user_request",
+                        "other"
+                    ]
+                }
+            }
+        },
+        "internal_controller_http_v1.accountCreateInput": {
+            "type": "object",
+            "properties": {
+                "type": {
+                    "type": "string",
+                    "enum": [
+                        "personal",
+                        "business"
+                    ]
+                }
+            }
+        },
+        "internal_controller_http_v1.accountDepositInput": {
+            "type": "object",
+            "required": [
+                "amount",
+                "id"
+            ],
+            "properties": {
+                "amount": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "wallet": {
+                    "type": "string",
+                    "enum": [
+                        "main",
+                        "bonus"
+                    ]
+                }
+            }
+        },
+        "internal_controller_http_v1.accountFreezeInput": {
+            "type": "object",
+            "required": [
+                "id",
+                "reason_code"
+            ],
+            "properties": {
+                "comment": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "debit_only": {
+                    "type": "boolean"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "reason_code": {
+                    "type": "string",
+                    "enum": [
+                        "fraud_suspected",
+                        "security_incident",
+                        "compliance_check",
+                        "user_request",
+                        "other"
+                    ]
+                }
+            }
+        },
+        "internal_controller_http_v1.accountRoutes": {
+            "type": "object"
+        },
+        "internal_controller_http_v1.accountTransferInput": {
+            "type": "object",
+            "required": [
+                "amount",
+                "from",
+                "to"
+            ],
+            "properties": {
+                "amount": {
+                    "type": "integer"
+                },
+                "execute_at": {
+                    "type": "string"
+                },
+                "from": {
+                    "type": "integer"
+                },
+                "repeat": {
+                    "type": "string",
+                    "enum": [
+                        "weekly"
+                    ]
+                },
+                "to": {
+                    "type": "integer"
+                }
+            }
+        },
+        "internal_controller_http_v1.accountUnfreezeInput": {
+            "type": "object",
+            "required": [
+                "id",
+                "reason_code"
+            ],
+            "properties": {
+                "comment": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "reason_code": {
+                    "type": "string",
+                    "enum": [
+                        "resolved",
+                        "compliance_check",
+                        "user_request",
+                        "other"
+                    ]
+                }
+            }
+        },
+        "internal_controller_http_v1.accountWithdrawInput": {
+            "type": "object",
+            "required": [
+                "amount",
+                "id"
+            ],
+            "properties": {
+                "amount": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "internal_controller_http_v1.adjustmentCreateInput": {
+            "type": "object",
+            "required": [
+                "account_id",
+                "amount",
+                "comment",
+                "direction",
+                "reason_code"
+            ],
+            "properties": {
+                "account_id": {
+                    "type": "integer"
+                },
+                "amount": {
+                    "type": "integer"
+                },
+                "comment": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "direction": {
+                    "type": "string",
+                    "enum": [
+                        "credit",
+                        "debit"
+                    ]
+                },
+                "reason_code": {
+                    "type": "string",
+                    "maxLength": 64
+                },
+                "ticket": {
+                    "type": "string",
+                    "maxLength": 64
+                },
+                "wallet": {
+                    "type": "string",
+                    "enum": [
+                        "main",
+                        "bonus"
+                    ]
+                }
+            }
+        },
+        "internal_controller_http_v1.adjustmentDeactivateReasonInput": {
+            "type": "object",
+            "required": [
+                "code"
+            ],
+            "properties": {
+                "code": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_controller_http_v1.adjustmentGetAccountAdjustmentsInput": {
+            "type": "object",
+            "required": [
+                "account_id"
+            ],
+            "properties": {
+                "account_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "internal_controller_http_v1.adjustmentRoutes": {
+            "type": "object"
+        },
+        "internal_controller_http_v1.adjustmentSetReasonInput": {
+            "type": "object",
+            "required": [
+                "code",
+                "description"
+            ],
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "maxLength": 64
+                },
+                "description": {
+                    "type": "string",
+                    "maxLength": 255
+                }
+            }
+        },
+        "internal_controller_http_v1.approvalGetRequestInput": {
+            "type": "object",
+            "required": [
+                "id"
+            ],
+            "properties": {
+                "id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "internal_controller_http_v1.approvalGetRequestsInput": {
+            "type": "object",
+            "properties": {
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "pending",
+                        "approved",
+                        "executed",
+                        "failed",
+                        "rejected",
+                        "expired"
+                    ]
+                }
+            }
+        },
+        "internal_controller_http_v1.approvalResolveInput": {
+            "type": "object",
+            "required": [
+                "id"
+            ],
+            "properties": {
+                "comment": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "internal_controller_http_v1.approvalRoutes": {
+            "type": "object"
+        },
+        "internal_controller_http_v1.authRoutes": {
+            "type": "object"
+        },
+        "internal_controller_http_v1.batchExecuteInput": {
+            "type": "object",
+            "required": [
+                "items",
+                "mode"
+            ],
+            "properties": {
+                "description": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/internal_controller_http_v1.batchItemInput"
+                    }
+                },
+                "mode": {
+                    "type": "string",
+                    "enum": [
+                        "atomic",
+                        "best_effort"
+                    ]
+                }
+            }
+        },
+        "internal_controller_http_v1.batchItemInput": {
+            "type": "object",
+            "properties": {
+                "account_id": {
+                    "type": "integer"
+                },
+                "amount": {
+                    "type": "integer"
+                },
+                "from": {
+                    "type": "integer"
+                },
+                "to": {
+                    "type": "integer"
+                },
+                "type": {
+                    "type": "string"
+                },
+                "wallet": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_controller_http_v1.batchRoutes": {
+            "type": "object"
+        },
+        "internal_controller_http_v1.cashbackCreateInput": {
+            "type": "object",
+            "required": [
+                "product_id",
+                "rate"
+            ],
+            "properties": {
+                "cap": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "product_id": {
+                    "type": "integer"
+                },
+                "rate": {
+                    "type": "integer",
+                    "maximum": 10000
+                },
+                "valid_from": {
+                    "type": "string"
+                },
+                "valid_to": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_controller_http_v1.cashbackDeleteInput": {
+            "type": "object",
+            "required": [
+                "id"
+            ],
+            "properties": {
+                "id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "internal_controller_http_v1.cashbackRoutes": {
+            "type": "object"
+        },
+        "internal_controller_http_v1.feeCreateInput": {
+            "type": "object",
+            "required": [
+                "operation_type"
+            ],
+            "properties": {
+                "account_type": {
+                    "type": "string",
+                    "enum": [
+                        "personal",
+                        "business"
+                    ]
+                },
+                "fixed": {
+                    "type": "integer"
+                },
+                "free_up_to": {
+                    "type": "integer"
+                },
+                "max_fee": {
+                    "type": "integer"
+                },
+                "min_fee": {
+                    "type": "integer"
+                },
+                "operation_type": {
+                    "type": "string",
+                    "enum": [
+                        "withdraw",
+                        "transfer_from",
+                        "reservation"
+                    ]
+                },
+                "product_id": {
+                    "type": "integer"
+                },
+                "rate": {
+                    "type": "integer"
+                }
+            }
+        },
+        "internal_controller_http_v1.feeDeleteInput": {
+            "type": "object",
+            "required": [
+                "id"
+            ],
+            "properties": {
+                "id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "internal_controller_http_v1.feeIncomeInput": {
+            "type": "object",
+            "required": [
+                "from",
+                "to"
+            ],
+            "properties": {
+                "from": {
+                    "type": "string"
+                },
+                "to": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_controller_http_v1.feeQuoteInput": {
+            "type": "object",
+            "required": [
+                "account_id",
+                "amount",
+                "operation_type"
+            ],
+            "properties": {
+                "account_id": {
+                    "type": "integer"
+                },
+                "amount": {
+                    "type": "integer"
+                },
+                "operation_type": {
+                    "type": "string",
+                    "enum": [
+                        "withdraw",
+                        "transfer_from",
+                        "reservation"
+                    ]
+                },
+                "product_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "internal_controller_http_v1.feeRoutes": {
+            "type": "object"
+        },
+        "internal_controller_http_v1.getBalanceInput": {
+            "type": "object",
+            "required": [
+                "id"
+            ],
+            "properties": {
+                "id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "internal_controller_http_v1.getExportInput": {
+            "type": "object",
+            "required": [
+                "from",
+                "to"
+            ],
+            "properties": {
+                "account_id": {
+                    "type": "integer"
+                },
+                "from": {
+                    "type": "string"
+                },
+                "to": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_controller_http_v1.getHistoryInput": {
+            "type": "object",
+            "required": [
+                "account_id"
+            ],
+            "properties": {
+                "account_id": {
+                    "type": "integer"
+                },
+                "limit": {
+                    "type": "integer"
+                },
+                "offset": {
+                    "type": "integer"
+                },
+                "sort_type": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_controller_http_v1.getReportInput": {
+            "type": "object",
+            "properties": {
+                "from": {
+                    "type": "string"
+                },
+                "month": {
+                    "type": "integer"
+                },
+                "to": {
+                    "type": "string"
+                },
+                "year": {
+                    "type": "integer"
+                }
+            }
+        },
+        "internal_controller_http_v1.getStatusHistoryInput": {
+            "type": "object",
+            "required": [
+                "id"
+            ],
+            "properties": {
+                "id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "internal_controller_http_v1.groupMembersInput": {
+            "type": "object",
+            "required": [
+                "account_id"
+            ],
+            "properties": {
+                "account_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "internal_controller_http_v1.groupRemoveMemberInput": {
+            "type": "object",
+            "required": [
+                "account_id",
+                "user_id"
+            ],
+            "properties": {
+                "account_id": {
+                    "type": "integer"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "internal_controller_http_v1.groupReserveInput": {
+            "type": "object",
+            "required": [
+                "account_id",
+                "amount",
+                "order_id",
+                "product_id"
+            ],
+            "properties": {
+                "account_id": {
+                    "type": "integer"
+                },
+                "amount": {
+                    "type": "integer"
+                },
+                "order_id": {
+                    "type": "integer"
+                },
+                "product_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "internal_controller_http_v1.groupRoutes": {
+            "type": "object"
+        },
+        "internal_controller_http_v1.groupSetMemberInput": {
+            "type": "object",
+            "required": [
+                "account_id",
+                "role",
+                "user_id"
+            ],
+            "properties": {
+                "account_id": {
+                    "type": "integer"
+                },
+                "monthly_limit": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "role": {
+                    "type": "string",
+                    "enum": [
+                        "owner",
+                        "spender",
+                        "viewer"
+                    ]
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "internal_controller_http_v1.installmentCreateInput": {
+            "type": "object",
+            "required": [
+                "account_id",
+                "amount",
+                "order_id",
+                "parts",
+                "period",
+                "product_id"
+            ],
+            "properties": {
+                "account_id": {
+                    "type": "integer"
+                },
+                "amount": {
+                    "type": "integer"
+                },
+                "first_payment": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "order_id": {
+                    "type": "integer"
+                },
+                "parts": {
+                    "type": "integer",
+                    "maximum": 24,
+                    "minimum": 2
+                },
+                "period": {
+                    "type": "string",
+                    "enum": [
+                        "day",
+                        "week",
+                        "month"
+                    ]
+                },
+                "product_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "internal_controller_http_v1.installmentGetInput": {
+            "type": "object",
+            "required": [
+                "id"
+            ],
+            "properties": {
+                "id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "internal_controller_http_v1.installmentRoutes": {
+            "type": "object"
+        },
+        "internal_controller_http_v1.limitCreateInput": {
+            "type": "object",
+            "required": [
+                "limit_type",
+                "operation_type",
+                "scope",
+                "value"
+            ],
+            "properties": {
+                "account_id": {
+                    "type": "integer"
+                },
+                "account_type": {
+                    "type": "string",
+                    "enum": [
+                        "personal",
+                        "business"
+                    ]
+                },
+                "limit_type": {
+                    "type": "string",
+                    "enum": [
+                        "max_amount",
+                        "max_total",
+                        "max_count"
+                    ]
+                },
+                "operation_type": {
+                    "type": "string",
+                    "enum": [
+                        "withdraw",
+                        "transfer_from",
+                        "reservation"
+                    ]
+                },
+                "scope": {
+                    "type": "string",
+                    "enum": [
+                        "global",
+                        "account_type",
+                        "account"
+                    ]
+                },
+                "value": {
+                    "type": "integer"
+                },
+                "window_seconds": {
+                    "type": "integer"
+                }
+            }
+        },
+        "internal_controller_http_v1.limitDeleteInput": {
+            "type": "object",
+            "required": [
+                "id"
+            ],
+            "properties": {
+                "id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "internal_controller_http_v1.limitRoutes": {
+            "type": "object"
+        },
+        "internal_controller_http_v1.operationRoutes": {
+            "type": "object",
+            "properties": {
+                "service.Operation": {}
+            }
+        },
+        "internal_controller_http_v1.paymentRequestCreateInput": {
+            "type": "object",
+            "required": [
+                "amount",
+                "payer_account_id",
+                "requester_account_id"
+            ],
+            "properties": {
+                "amount": {
+                    "type": "integer"
+                },
+                "description": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "payer_account_id": {
+                    "type": "integer"
+                },
+                "requester_account_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "internal_controller_http_v1.paymentRequestIdInput": {
+            "type": "object",
+            "required": [
+                "id"
+            ],
+            "properties": {
+                "id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "internal_controller_http_v1.paymentRequestListInput": {
+            "type": "object",
+            "required": [
+                "account_id"
+            ],
+            "properties": {
+                "account_id": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "pending",
+                        "paid",
+                        "declined",
+                        "expired"
+                    ]
+                }
+            }
+        },
+        "internal_controller_http_v1.paymentRequestRoutes": {
+            "type": "object"
+        },
+        "internal_controller_http_v1.productRoutes": {
+            "type": "object"
+        },
+        "internal_controller_http_v1.reportArchiveGetReportsInput": {
+            "type": "object",
+            "properties": {
+                "created_by": {
+                    "type": "integer"
+                },
+                "kind": {
+                    "type": "string",
+                    "enum": [
+                        "report",
+                        "export",
+                        "unknown"
+                    ]
+                },
+                "limit": {
+                    "type": "integer",
+                    "minimum": 1
+                },
+                "offset": {
+                    "type": "integer",
+                    "minimum": 0
+                }
+            }
+        },
+        "internal_controller_http_v1.reportArchiveIdInput": {
             "type": "object",
             "required": [
-                "amount",
                 "id"
             ],
             "properties": {
-                "amount": {
-                    "type": "integer"
-                },
                 "id": {
                     "type": "integer"
                 }
             }
         },
-        "account-management-service_internal_controller_http_v1.accountRoutes": {
+        "internal_controller_http_v1.reportArchiveRoutes": {
             "type": "object"
         },
-        "account-management-service_internal_controller_http_v1.accountTransferInput": {
+        "internal_controller_http_v1.reportJobCreateInput": {
             "type": "object",
-            "required": [
-                "amount",
-                "from",
-                "to"
-            ],
             "properties": {
-                "amount": {
-                    "type": "integer"
+                "account_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "bom": {
+                    "type": "boolean"
+                },
+                "delimiter": {
+                    "type": "string",
+                    "enum": [
+                        "comma",
+                        "semicolon",
+                        "tab"
+                    ]
+                },
+                "format": {
+                    "type": "string",
+                    "enum": [
+                        "csv",
+                        "xlsx",
+                        "json",
+                        "parquet"
+                    ]
                 },
                 "from": {
-                    "type": "integer"
+                    "type": "string"
+                },
+                "granularity": {
+                    "type": "string",
+                    "enum": [
+                        "day",
+                        "week",
+                        "month",
+                        "quarter"
+                    ]
+                },
+                "mode": {
+                    "type": "string",
+                    "enum": [
+                        "products",
+                        "accounts"
+                    ]
+                },
+                "month": {
+                    "type": "integer",
+                    "maximum": 12,
+                    "minimum": 1
+                },
+                "product_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
                 },
                 "to": {
+                    "type": "string"
+                },
+                "version": {
+                    "type": "integer",
+                    "enum": [
+                        1,
+                        2
+                    ]
+                },
+                "year": {
                     "type": "integer"
                 }
             }
         },
-        "account-management-service_internal_controller_http_v1.accountWithdrawInput": {
+        "internal_controller_http_v1.reportJobGetInput": {
             "type": "object",
             "required": [
-                "amount",
                 "id"
             ],
             "properties": {
-                "amount": {
-                    "type": "integer"
-                },
                 "id": {
                     "type": "integer"
                 }
             }
         },
-        "account-management-service_internal_controller_http_v1.authRoutes": {
+        "internal_controller_http_v1.reportJobRoutes": {
             "type": "object"
         },
-        "account-management-service_internal_controller_http_v1.getBalanceInput": {
+        "internal_controller_http_v1.reportLinkGetAccessesInput": {
             "type": "object",
             "required": [
-                "id"
+                "link_id"
             ],
             "properties": {
-                "id": {
+                "link_id": {
                     "type": "integer"
                 }
             }
         },
-        "account-management-service_internal_controller_http_v1.getHistoryInput": {
+        "internal_controller_http_v1.reportLinkRoutes": {
+            "type": "object"
+        },
+        "internal_controller_http_v1.reportScheduleRoutes": {
+            "type": "object"
+        },
+        "internal_controller_http_v1.reportScheduleRunsInput": {
             "type": "object",
-            "required": [
-                "account_id"
-            ],
             "properties": {
-                "account_id": {
-                    "type": "integer"
-                },
                 "limit": {
-                    "type": "integer"
+                    "type": "integer",
+                    "minimum": 1
                 },
                 "offset": {
-                    "type": "integer"
+                    "type": "integer",
+                    "minimum": 0
                 },
-                "sort_type": {
+                "schedule": {
                     "type": "string"
                 }
             }
         },
-        "account-management-service_internal_controller_http_v1.getReportInput": {
-            "type": "object",
-            "required": [
-                "month",
-                "year"
-            ],
-            "properties": {
-                "month": {
-                    "type": "integer"
-                },
-                "year": {
-                    "type": "integer"
-                }
-            }
-        },
-        "account-management-service_internal_controller_http_v1.operationRoutes": {
-            "type": "object",
-            "properties": {
-                "service.Operation": {}
-            }
-        },
-        "account-management-service_internal_controller_http_v1.productRoutes": {
-            "type": "object"
-        },
-        "account-management-service_internal_controller_http_v1.reservationCreateInput": {
+        "internal_controller_http_v1.reservationCreateInput": {
             "type": "object",
             "required": [
                 "account_id",
@@ -858,7 +6942,7 @@ const docTemplate = `{
                 }
             }
         },
-        "account-management-service_internal_controller_http_v1.reservationRefundInput": {
+        "internal_controller_http_v1.reservationRefundInput": {
             "type": "object",
             "required": [
                 "order_id"
@@ -869,7 +6953,7 @@ const docTemplate = `{
                 }
             }
         },
-        "account-management-service_internal_controller_http_v1.reservationRevenueInput": {
+        "internal_controller_http_v1.reservationRevenueInput": {
             "type": "object",
             "required": [
                 "account_id",
@@ -892,10 +6976,21 @@ const docTemplate = `{
                 }
             }
         },
-        "account-management-service_internal_controller_http_v1.reservationRoutes": {
+        "internal_controller_http_v1.reservationRoutes": {
             "type": "object"
         },
-        "account-management-service_internal_controller_http_v1.signInInput": {
+        "internal_controller_http_v1.scheduledTransferInput": {
+            "type": "object",
+            "required": [
+                "id"
+            ],
+            "properties": {
+                "id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "internal_controller_http_v1.signInInput": {
             "type": "object",
             "required": [
                 "password",
@@ -912,7 +7007,7 @@ const docTemplate = `{
                 }
             }
         },
-        "account-management-service_internal_controller_http_v1.signUpInput": {
+        "internal_controller_http_v1.signUpInput": {
             "type": "object",
             "required": [
                 "password",
@@ -929,79 +7024,78 @@ const docTemplate = `{
                 }
             }
         },
-        "echo.HTTPError": {
-            "type": "object",
-            "properties": {
-                "message": {}
-            }
-        },
-        "internal_controller_http_v1.accountDepositInput": {
+        "internal_controller_http_v1.subscriptionCreateInput": {
             "type": "object",
             "required": [
+                "account_id",
                 "amount",
-                "id"
+                "period",
+                "product_id"
             ],
             "properties": {
+                "account_id": {
+                    "type": "integer"
+                },
                 "amount": {
                     "type": "integer"
                 },
-                "id": {
+                "period": {
+                    "type": "string",
+                    "enum": [
+                        "day",
+                        "week",
+                        "month"
+                    ]
+                },
+                "product_id": {
                     "type": "integer"
+                },
+                "start_at": {
+                    "type": "string"
                 }
             }
         },
-        "internal_controller_http_v1.accountRoutes": {
-            "type": "object"
-        },
-        "internal_controller_http_v1.accountTransferInput": {
+        "internal_controller_http_v1.subscriptionIdInput": {
             "type": "object",
             "required": [
-                "amount",
-                "from",
-                "to"
+                "id"
             ],
             "properties": {
-                "amount": {
-                    "type": "integer"
-                },
-                "from": {
-                    "type": "integer"
-                },
-                "to": {
+                "id": {
                     "type": "integer"
                 }
             }
         },
-        "internal_controller_http_v1.accountWithdrawInput": {
+        "internal_controller_http_v1.subscriptionListInput": {
             "type": "object",
             "required": [
-                "amount",
-                "id"
+                "account_id"
             ],
             "properties": {
-                "amount": {
-                    "type": "integer"
-                },
-                "id": {
+                "account_id": {
                     "type": "integer"
                 }
             }
         },
-        "internal_controller_http_v1.authRoutes": {
+        "internal_controller_http_v1.subscriptionRoutes": {
             "type": "object"
         },
-        "internal_controller_http_v1.getBalanceInput": {
+        "internal_controller_http_v1.verificationDeletePolicyInput": {
             "type": "object",
             "required": [
-                "id"
+                "level",
+                "operation_type"
             ],
             "properties": {
-                "id": {
-                    "type": "integer"
+                "level": {
+                    "type": "string"
+                },
+                "operation_type": {
+                    "type": "string"
                 }
             }
         },
-        "internal_controller_http_v1.getHistoryInput": {
+        "internal_controller_http_v1.verificationHistoryInput": {
             "type": "object",
             "required": [
                 "account_id"
@@ -1009,135 +7103,132 @@ const docTemplate = `{
             "properties": {
                 "account_id": {
                     "type": "integer"
-                },
-                "limit": {
-                    "type": "integer"
-                },
-                "offset": {
-                    "type": "integer"
-                },
-                "sort_type": {
-                    "type": "string"
                 }
             }
         },
-        "internal_controller_http_v1.getReportInput": {
+        "internal_controller_http_v1.verificationRoutes": {
+            "type": "object"
+        },
+        "internal_controller_http_v1.verificationSetLevelInput": {
             "type": "object",
             "required": [
-                "month",
-                "year"
+                "account_id",
+                "level"
             ],
             "properties": {
-                "month": {
+                "account_id": {
                     "type": "integer"
                 },
-                "year": {
-                    "type": "integer"
+                "level": {
+                    "type": "string",
+                    "enum": [
+                        "none",
+                        "basic",
+                        "full"
+                    ]
+                },
+                "reference": {
+                    "type": "string",
+                    "maxLength": 255
                 }
             }
         },
-        "internal_controller_http_v1.operationRoutes": {
-            "type": "object",
-            "properties": {
-                "service.Operation": {}
-            }
-        },
-        "internal_controller_http_v1.productRoutes": {
-            "type": "object"
-        },
-        "internal_controller_http_v1.reservationCreateInput": {
+        "internal_controller_http_v1.verificationSetPolicyInput": {
             "type": "object",
             "required": [
-                "account_id",
-                "amount",
-                "order_id",
-                "product_id"
+                "level",
+                "operation_type"
             ],
             "properties": {
-                "account_id": {
-                    "type": "integer"
+                "level": {
+                    "type": "string",
+                    "enum": [
+                        "none",
+                        "basic",
+                        "full"
+                    ]
                 },
-                "amount": {
+                "max_amount": {
                     "type": "integer"
                 },
-                "order_id": {
+                "max_total": {
                     "type": "integer"
                 },
-                "product_id": {
+                "operation_type": {
+                    "type": "string",
+                    "enum": [
+                        "withdraw",
+                        "transfer_from"
+                    ]
+                },
+                "window_seconds": {
                     "type": "integer"
                 }
             }
         },
-        "internal_controller_http_v1.reservationRefundInput": {
+        "internal_controller_http_v1.voucherCampaignReportInput": {
             "type": "object",
             "required": [
-                "order_id"
+                "campaign_id"
             ],
             "properties": {
-                "order_id": {
+                "campaign_id": {
                     "type": "integer"
                 }
             }
         },
-        "internal_controller_http_v1.reservationRevenueInput": {
+        "internal_controller_http_v1.voucherCreateCampaignInput": {
             "type": "object",
             "required": [
-                "account_id",
                 "amount",
-                "order_id",
-                "product_id"
+                "count",
+                "expires_at",
+                "name"
             ],
             "properties": {
-                "account_id": {
-                    "type": "integer"
-                },
                 "amount": {
                     "type": "integer"
                 },
-                "order_id": {
-                    "type": "integer"
+                "count": {
+                    "type": "integer",
+                    "maximum": 10000
                 },
-                "product_id": {
-                    "type": "integer"
-                }
-            }
-        },
-        "internal_controller_http_v1.reservationRoutes": {
-            "type": "object"
-        },
-        "internal_controller_http_v1.signInInput": {
-            "type": "object",
-            "required": [
-                "password",
-                "username"
-            ],
-            "properties": {
-                "password": {
+                "expires_at": {
                     "type": "string"
                 },
-                "username": {
+                "max_uses": {
+                    "type": "integer"
+                },
+                "name": {
                     "type": "string",
-                    "maxLength": 32,
-                    "minLength": 4
+                    "maxLength": 255
+                },
+                "wallet": {
+                    "type": "string",
+                    "enum": [
+                        "main",
+                        "bonus"
+                    ]
                 }
             }
         },
-        "internal_controller_http_v1.signUpInput": {
+        "internal_controller_http_v1.voucherRedeemInput": {
             "type": "object",
             "required": [
-                "password",
-                "username"
+                "account_id",
+                "code"
             ],
             "properties": {
-                "password": {
-                    "type": "string"
+                "account_id": {
+                    "type": "integer"
                 },
-                "username": {
-                    "type": "string",
-                    "maxLength": 32,
-                    "minLength": 4
+                "code": {
+                    "type": "string"
                 }
             }
+        },
+        "internal_controller_http_v1.voucherRoutes": {
+            "type": "object"
         }
     },
     "securityDefinitions": {
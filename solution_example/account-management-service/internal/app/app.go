@@ -5,11 +5,13 @@ import (
 	v1 "account-management-service/internal/controller/http/v1"
 	"account-management-service/internal/repo"
 	"account-management-service/internal/service"
-	"account-management-service/internal/webapi/gdrive"
+	"account-management-service/internal/webapi/localfs"
 	"account-management-service/pkg/hasher"
 	"account-management-service/pkg/httpserver"
 	"account-management-service/pkg/postgres"
+	"account-management-service/pkg/scheduler"
 	"account-management-service/pkg/validator"
+	"context"
 	"fmt"
 	"github.com/labstack/echo/v4"
 	log "github.com/sirupsen/logrus"
@@ -55,29 +57,100 @@ func Run(configPath string) {
 	log.Info("Initializing repositories...")
 	repositories := repo.NewRepositories(pg)
 
+	// Report schedules
+	reportChannels := newReportChannels(cfg)
+	reportSchedules := newReportSchedules(cfg)
+	if err = service.ValidateReportSchedules(reportSchedules, reportChannels); err != nil {
+		log.Fatalf("Report schedules error: %s", err)
+	}
+
 	// Services dependencies
 	log.Info("Initializing services...")
 	deps := service.ServicesDependencies{
-		Repos:    repositories,
-		GDrive:   gdrive.New(cfg.WebAPI.GDriveJSONFilePath),
-		Hasher:   hasher.NewSHA1Hasher(cfg.Hasher.Salt),
-		SignKey:  cfg.JWT.SignKey,
-		TokenTTL: cfg.JWT.TokenTTL,
+		Repos:         repositories,
+		ReportStorage: newReportStorage(cfg),
+		Hasher:        hasher.NewSHA1Hasher(cfg.Hasher.Salt),
+		SignKey:       cfg.JWT.SignKey,
+		TokenTTL:      cfg.JWT.TokenTTL,
+
+		SpendingOrder: cfg.Wallets.SpendingOrder,
+
+		FeeAccountId: cfg.Fees.AccountId,
+
+		ReportLinksSigned:  cfg.Reports.Links.Signed,
+		ReportLinksSignKey: reportLinksSignKey(cfg),
+		ReportLinksBaseURL: cfg.Reports.BaseURL,
+		ReportLinksTTL:     cfg.Reports.Links.TTL,
+		ReportLinksOneTime: cfg.Reports.Links.OneTime,
+
+		ReportJobMaxAttempts:  cfg.ReportJobs.MaxAttempts,
+		ReportJobRetryBackoff: cfg.ReportJobs.RetryBackoff,
+		ReportJobStaleAfter:   cfg.ReportJobs.StaleAfter,
+
+		ReportChannels:              reportChannels,
+		ReportSchedules:             reportSchedules,
+		ReportScheduleLocation:      reportScheduleLocation(cfg),
+		ReportScheduleCatchUp:       cfg.ReportSchedules.CatchUp,
+		ReportScheduleMaxAttempts:   cfg.ReportSchedules.MaxAttempts,
+		ReportScheduleRetryInterval: cfg.ReportSchedules.RetryInterval,
+		ReportScheduleStaleAfter:    cfg.ReportSchedules.StaleAfter,
+
+		ReportArchiveRetention: cfg.Reports.Archive.Retention,
+
+		SubscriptionRetryInterval: cfg.Subscriptions.RetryInterval,
+		SubscriptionGracePeriod:   cfg.Subscriptions.GracePeriod,
+
+		PaymentRequestTTL: cfg.PaymentRequests.TTL,
+
+		InstallmentRetryInterval: cfg.Installments.RetryInterval,
+		InstallmentMaxAttempts:   cfg.Installments.MaxAttempts,
+		InstallmentCancelOrder:   cfg.Installments.CancelOrder,
+
+		ApprovalThresholds: cfg.Approvals.Thresholds,
+		ApprovalTTL:        cfg.Approvals.TTL,
+
+		BatchChunkSize: cfg.Batch.ChunkSize,
+		BatchMaxItems:  cfg.Batch.MaxItems,
 	}
 	services := service.NewServices(deps)
 
+	// Roles
+	log.Info("Granting user roles...")
+	if err = services.Auth.GrantRoles(context.Background(), cfg.Users.Roles); err != nil {
+		log.Fatalf("User roles error: %s", err)
+	}
+
 	// Echo handler
 	log.Info("Initializing handlers and routes...")
 	handler := echo.New()
 	// setup handler validator as lib validator
 	handler.Validator = validator.NewCustomValidator()
-	v1.NewRouter(handler, services)
+	v1.NewRouter(handler, services, cfg.HTTP.ExportWriteTimeout)
+	// reports kept on the local filesystem are served by the service itself,
+	// with signed links they are available only through the download route
+	if cfg.Reports.Storage == reportStorageLocal && !cfg.Reports.Links.Signed {
+		handler.Static(localfs.URLPrefix, cfg.Reports.Local.Dir)
+	}
 
 	// HTTP server
 	log.Info("Starting http server...")
 	log.Debugf("Server port: %s", cfg.HTTP.Port)
 	httpServer := httpserver.New(handler, httpserver.Port(cfg.HTTP.Port))
 
+	// Background jobs
+	log.Info("Starting schedulers...")
+	subscriptionScheduler := scheduler.New(services.Subscription.ChargeDueSubscriptions, scheduler.Interval(cfg.Subscriptions.ChargeInterval))
+	transferScheduler := scheduler.New(services.Account.ExecuteDueTransfers, scheduler.Interval(cfg.ScheduledTransfers.RunInterval))
+	installmentScheduler := scheduler.New(services.Installment.ChargeDueInstallments, scheduler.Interval(cfg.Installments.RunInterval))
+	approvalScheduler := scheduler.New(services.Approval.ExpireStaleRequests, scheduler.Interval(cfg.Approvals.RunInterval))
+	// a job is considered stale after stale_after, so a run must not take longer
+	reportJobScheduler := scheduler.New(services.ReportJob.RunReportJobs,
+		scheduler.Interval(cfg.ReportJobs.RunInterval), scheduler.RunTimeout(cfg.ReportJobs.StaleAfter))
+	// the run is claimed again by another replica after stale_after, so it must not take longer
+	reportScheduleScheduler := scheduler.New(services.ReportSchedule.RunReportSchedules,
+		scheduler.Interval(cfg.ReportSchedules.RunInterval), scheduler.RunTimeout(cfg.ReportSchedules.StaleAfter))
+	reportArchiveScheduler := scheduler.New(services.ReportArchive.PurgeExpiredReports, scheduler.Interval(cfg.Reports.Archive.PurgeInterval))
+
 	// Waiting signal
 	log.Info("Configuring graceful shutdown...")
 	interrupt := make(chan os.Signal, 1)
@@ -96,4 +169,12 @@ func Run(configPath string) {
 	if err != nil {
 		log.Error(fmt.Errorf("app - Run - httpServer.Shutdown: %w", err))
 	}
+
+	subscriptionScheduler.Shutdown()
+	transferScheduler.Shutdown()
+	installmentScheduler.Shutdown()
+	approvalScheduler.Shutdown()
+	reportJobScheduler.Shutdown()
+	reportScheduleScheduler.Shutdown()
+	reportArchiveScheduler.Shutdown()
 }
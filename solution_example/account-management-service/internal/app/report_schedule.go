@@ -0,0 +1,71 @@
+package app
+
+import (
+	"account-management-service/config"
+	"account-management-service/internal/entity"
+	"account-management-service/internal/webapi"
+	"account-management-service/internal/webapi/email"
+	"account-management-service/internal/webapi/webhook"
+	log "github.com/sirupsen/logrus"
+	"time"
+	// the final image has no time zone database
+	_ "time/tzdata"
+)
+
+const (
+	reportChannelEmail   = "email"
+	reportChannelWebhook = "webhook"
+)
+
+// newReportChannels returns the report delivery channels by their type, email is available only with the SMTP server
+func newReportChannels(cfg *config.Config) map[string]webapi.ReportChannel {
+	channels := map[string]webapi.ReportChannel{
+		reportChannelWebhook: webhook.New(),
+	}
+	if cfg.SMTP.Host != "" {
+		channels[reportChannelEmail] = email.New(cfg.SMTP.Host, cfg.SMTP.Port, cfg.SMTP.Username, cfg.SMTP.Password, cfg.SMTP.From)
+	}
+	return channels
+}
+
+// newReportSchedules returns the configured report schedules
+func newReportSchedules(cfg *config.Config) []entity.ReportSchedule {
+	schedules := make([]entity.ReportSchedule, 0, len(cfg.ReportSchedules.Schedules))
+	for _, s := range cfg.ReportSchedules.Schedules {
+		channels := make([]entity.ReportChannel, 0, len(s.Channels))
+		for _, c := range s.Channels {
+			channels = append(channels, entity.ReportChannel{
+				Type:       c.Type,
+				Recipients: c.Recipients,
+				Attach:     c.Attach,
+			})
+		}
+
+		schedules = append(schedules, entity.ReportSchedule{
+			Name:   s.Name,
+			Cron:   s.Cron,
+			Period: s.Period,
+			Params: entity.ReportParams{
+				Version:      s.Report.Version,
+				Mode:         s.Report.Mode,
+				Granularity:  s.Report.Granularity,
+				ProductIds:   s.Report.ProductIds,
+				AccountIds:   s.Report.AccountIds,
+				Format:       s.Report.Format,
+				CSVDelimiter: s.Report.Delimiter,
+				CSVBOM:       s.Report.BOM,
+			},
+			Channels: channels,
+		})
+	}
+	return schedules
+}
+
+// reportScheduleLocation returns the time zone of the report schedules
+func reportScheduleLocation(cfg *config.Config) *time.Location {
+	location, err := time.LoadLocation(cfg.ReportSchedules.Timezone)
+	if err != nil {
+		log.Fatalf("invalid report schedules time zone %q: %s", cfg.ReportSchedules.Timezone, err)
+	}
+	return location
+}
@@ -0,0 +1,47 @@
+package app
+
+import (
+	"account-management-service/config"
+	"account-management-service/internal/webapi"
+	"account-management-service/internal/webapi/gdrive"
+	"account-management-service/internal/webapi/localfs"
+	"account-management-service/internal/webapi/s3"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	reportStorageLocal  = "local"
+	reportStorageS3     = "s3"
+	reportStorageGDrive = "gdrive"
+)
+
+// newReportStorage returns the report storage selected in the config
+func newReportStorage(cfg *config.Config) webapi.ReportStorage {
+	switch cfg.Reports.Storage {
+	case reportStorageLocal:
+		return localfs.New(cfg.Reports.Local.Dir, cfg.Reports.BaseURL)
+	case reportStorageS3:
+		return s3.New(
+			cfg.Reports.S3.Endpoint,
+			cfg.Reports.S3.Region,
+			cfg.Reports.S3.Bucket,
+			cfg.Reports.S3.AccessKey,
+			cfg.Reports.S3.SecretKey,
+			cfg.Reports.S3.LinkTTL,
+		)
+	case reportStorageGDrive:
+		// files are shared with anyone only if the service does not sign the links itself
+		return gdrive.New(cfg.WebAPI.GDriveJSONFilePath, !cfg.Reports.Links.Signed)
+	default:
+		log.Fatalf("unknown report storage %q", cfg.Reports.Storage)
+		return nil
+	}
+}
+
+// reportLinksSignKey returns the key to sign report links with, the JWT sign key is used if it is not set
+func reportLinksSignKey(cfg *config.Config) string {
+	if cfg.Reports.Links.SignKey != "" {
+		return cfg.Reports.Links.SignKey
+	}
+	return cfg.JWT.SignKey
+}
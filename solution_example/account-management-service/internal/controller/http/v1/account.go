@@ -1,25 +1,44 @@
 package v1
 
 import (
+	"account-management-service/internal/entity"
 	"account-management-service/internal/service"
+	"errors"
 	"github.com/labstack/echo/v4"
 	"net/http"
+	"time"
 )
 
 type accountRoutes struct {
-	accountService service.Account
+	accountService     service.Account
+	installmentService service.Installment
+	approvalService    service.Approval
 }
 
-func newAccountRoutes(g *echo.Group, accountService service.Account) {
+func newAccountRoutes(g *echo.Group, accountService service.Account, installmentService service.Installment, approvalService service.Approval, adminOnly echo.MiddlewareFunc) {
 	r := &accountRoutes{
-		accountService: accountService,
+		accountService:     accountService,
+		installmentService: installmentService,
+		approvalService:    approvalService,
 	}
 
 	g.POST("/create", r.create)
 	g.POST("/deposit", r.deposit) // POST, а не PUT, потому что неидемпотентно
 	g.POST("/withdraw", r.withdraw)
 	g.POST("/transfer", r.transfer)
+	g.GET("/scheduled-transfers", r.getScheduledTransfer)
+	g.POST("/scheduled-transfers/cancel", r.cancelScheduledTransfer)
+	g.GET("/obligations", r.getObligations)
 	g.GET("/", r.getBalance)
+
+	g.POST("/freeze", r.freeze, adminOnly)
+	g.POST("/unfreeze", r.unfreeze, adminOnly)
+	g.POST("/close", r.close, adminOnly)
+	g.GET("/status-history", r.getStatusHistory, adminOnly)
+}
+
+type accountCreateInput struct {
+	Type string `json:"type,omitempty" validate:"omitempty,oneof=personal business"`
 }
 
 // @Summary Create account
@@ -27,13 +46,28 @@ func newAccountRoutes(g *echo.Group, accountService service.Account) {
 // @Tags accounts
 // @Accept json
 // @Produce json
+// @Param input body v1.accountCreateInput false "input"
 // @Success 201 {object} v1.accountRoutes.create.response
 // @Failure 400 {object} echo.HTTPError
 // @Failure 500 {object} echo.HTTPError
 // @Security JWT
 // @Router /api/v1/accounts/create [post]
 func (r *accountRoutes) create(c echo.Context) error {
-	id, err := r.accountService.CreateAccount(c.Request().Context())
+	var input accountCreateInput
+
+	if err := c.Bind(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid request body")
+		return err
+	}
+
+	if err := c.Validate(input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return err
+	}
+
+	id, err := r.accountService.CreateAccount(c.Request().Context(), service.AccountCreateInput{
+		Type: input.Type,
+	})
 	if err != nil {
 		if err == service.ErrAccountAlreadyExists {
 			newErrorResponse(c, http.StatusBadRequest, err.Error())
@@ -53,17 +87,19 @@ func (r *accountRoutes) create(c echo.Context) error {
 }
 
 type accountDepositInput struct {
-	Id     int `json:"id" validate:"required"`
-	Amount int `json:"amount" validate:"required"`
+	Id     int    `json:"id" validate:"required"`
+	Amount int    `json:"amount" validate:"required,gt=0"`
+	Wallet string `json:"wallet,omitempty" validate:"omitempty,oneof=main bonus"`
 }
 
 // @Summary Deposit
-// @Description Deposit
+// @Description Deposit. Deposits which need approval are submitted as operation requests and executed after another user approves them
 // @Tags accounts
 // @Accept json
 // @Produce json
 // @Param input body v1.accountDepositInput true "input"
 // @Success 200
+// @Success 202
 // @Failure 400 {object} echo.HTTPError
 // @Failure 500 {object} echo.HTTPError
 // @Security JWT
@@ -81,15 +117,29 @@ func (r *accountRoutes) deposit(c echo.Context) error {
 		return err
 	}
 
+	if r.approvalService.RequiresApproval(entity.OperationTypeDeposit, input.Amount) {
+		return submitForApproval(c, r.approvalService, service.ApprovalCreateInput{
+			OperationType: entity.OperationTypeDeposit,
+			AccountId:     input.Id,
+			Amount:        input.Amount,
+			Wallet:        input.Wallet,
+		})
+	}
+
 	err := r.accountService.Deposit(c.Request().Context(), service.AccountDepositInput{
 		Id:     input.Id,
 		Amount: input.Amount,
+		Wallet: input.Wallet,
 	})
 	if err != nil {
 		if err == service.ErrAccountNotFound {
 			newErrorResponse(c, http.StatusBadRequest, err.Error())
 			return err
 		}
+		if err == service.ErrAccountFrozen || err == service.ErrAccountClosed {
+			newErrorResponse(c, http.StatusForbidden, err.Error())
+			return err
+		}
 		newErrorResponse(c, http.StatusInternalServerError, "internal server error")
 		return err
 	}
@@ -101,17 +151,20 @@ func (r *accountRoutes) deposit(c echo.Context) error {
 
 type accountWithdrawInput struct {
 	Id     int `json:"id" validate:"required"`
-	Amount int `json:"amount" validate:"required"`
+	Amount int `json:"amount" validate:"required,gt=0"`
 }
 
 // @Summary Withdraw
-// @Description Withdraw
+// @Description Withdraw. Withdrawals from the approval threshold are submitted as operation requests and executed after another user approves them
 // @Tags accounts
 // @Accept json
 // @Produce json
 // @Param input body v1.accountWithdrawInput true "input"
 // @Success 200
+// @Success 202
 // @Failure 400 {object} echo.HTTPError
+// @Failure 403 {object} echo.HTTPError
+// @Failure 422 {object} echo.HTTPError
 // @Failure 500 {object} echo.HTTPError
 // @Security JWT
 // @Router /api/v1/accounts/withdraw [post]
@@ -128,15 +181,33 @@ func (r *accountRoutes) withdraw(c echo.Context) error {
 		return err
 	}
 
+	if r.approvalService.RequiresApproval(entity.OperationTypeWithdraw, input.Amount) {
+		return submitForApproval(c, r.approvalService, service.ApprovalCreateInput{
+			OperationType: entity.OperationTypeWithdraw,
+			AccountId:     input.Id,
+			Amount:        input.Amount,
+		})
+	}
+
 	err := r.accountService.Withdraw(c.Request().Context(), service.AccountWithdrawInput{
 		Id:     input.Id,
 		Amount: input.Amount,
+		UserId: c.Get(userIdCtx).(int),
 	})
 	if err != nil {
-		if err == service.ErrAccountNotFound {
+		if err == service.ErrAccountNotFound || err == service.ErrNotEnoughBalance {
 			newErrorResponse(c, http.StatusBadRequest, err.Error())
 			return err
 		}
+		if err == service.ErrAccountFrozen || err == service.ErrAccountClosed || errors.Is(err, service.ErrVerificationRequired) ||
+			err == service.ErrNotGroupMember || err == service.ErrGroupAccessDenied {
+			newErrorResponse(c, http.StatusForbidden, err.Error())
+			return err
+		}
+		if err == service.ErrMemberLimitExceeded || errors.Is(err, service.ErrLimitExceeded) {
+			newErrorResponse(c, http.StatusUnprocessableEntity, err.Error())
+			return err
+		}
 		newErrorResponse(c, http.StatusInternalServerError, "internal server error")
 		return err
 	}
@@ -147,19 +218,24 @@ func (r *accountRoutes) withdraw(c echo.Context) error {
 }
 
 type accountTransferInput struct {
-	From   int `json:"from" validate:"required"`
-	To     int `json:"to" validate:"required"`
-	Amount int `json:"amount" validate:"required"`
+	From      int        `json:"from" validate:"required"`
+	To        int        `json:"to" validate:"required"`
+	Amount    int        `json:"amount" validate:"required,gt=0"`
+	ExecuteAt *time.Time `json:"execute_at,omitempty"`
+	Repeat    string     `json:"repeat,omitempty" validate:"omitempty,oneof=weekly"`
 }
 
 // @Summary Transfer
-// @Description Transfer
+// @Description Transfer right away, or schedule it if execute_at or repeat is set
 // @Tags accounts
 // @Accept json
 // @Produce json
 // @Param input body v1.accountTransferInput true "input"
 // @Success 200
+// @Success 202 {object} v1.accountRoutes.transfer.response
 // @Failure 400 {object} echo.HTTPError
+// @Failure 403 {object} echo.HTTPError
+// @Failure 422 {object} echo.HTTPError
 // @Failure 500 {object} echo.HTTPError
 // @Security JWT
 // @Router /api/v1/accounts/transfer [post]
@@ -176,16 +252,154 @@ func (r *accountRoutes) transfer(c echo.Context) error {
 		return err
 	}
 
+	type response struct {
+		Id int `json:"id"`
+	}
+
+	if input.ExecuteAt != nil || input.Repeat != "" {
+		scheduleInput := service.AccountScheduleTransferInput{
+			From:   input.From,
+			To:     input.To,
+			Amount: input.Amount,
+			Repeat: input.Repeat,
+			UserId: c.Get(userIdCtx).(int),
+		}
+		if input.ExecuteAt != nil {
+			scheduleInput.ExecuteAt = *input.ExecuteAt
+		}
+
+		id, err := r.accountService.ScheduleTransfer(c.Request().Context(), scheduleInput)
+		if err != nil {
+			if err == service.ErrCannotScheduleTransfer {
+				newErrorResponse(c, http.StatusBadRequest, err.Error())
+				return err
+			}
+			if err == service.ErrNotGroupMember || err == service.ErrGroupAccessDenied {
+				newErrorResponse(c, http.StatusForbidden, err.Error())
+				return err
+			}
+			newErrorResponse(c, http.StatusInternalServerError, "internal server error")
+			return err
+		}
+
+		return c.JSON(http.StatusAccepted, response{
+			Id: id,
+		})
+	}
+
 	err := r.accountService.Transfer(c.Request().Context(), service.AccountTransferInput{
 		From:   input.From,
 		To:     input.To,
 		Amount: input.Amount,
+		UserId: c.Get(userIdCtx).(int),
 	})
 	if err != nil {
-		if err == service.ErrAccountNotFound {
+		if err == service.ErrAccountNotFound || err == service.ErrNotEnoughBalance {
 			newErrorResponse(c, http.StatusBadRequest, err.Error())
 			return err
 		}
+		if err == service.ErrAccountFrozen || err == service.ErrAccountClosed || errors.Is(err, service.ErrVerificationRequired) ||
+			err == service.ErrNotGroupMember || err == service.ErrGroupAccessDenied {
+			newErrorResponse(c, http.StatusForbidden, err.Error())
+			return err
+		}
+		if err == service.ErrMemberLimitExceeded || errors.Is(err, service.ErrLimitExceeded) {
+			newErrorResponse(c, http.StatusUnprocessableEntity, err.Error())
+			return err
+		}
+		newErrorResponse(c, http.StatusInternalServerError, "internal server error")
+		return err
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": "success",
+	})
+}
+
+type scheduledTransferInput struct {
+	Id int `json:"id" validate:"required"`
+}
+
+// @Summary Get scheduled transfer
+// @Description Get scheduled transfer with the results of its runs
+// @Tags accounts
+// @Accept json
+// @Produce json
+// @Param input body v1.scheduledTransferInput true "input"
+// @Success 200 {object} v1.accountRoutes.getScheduledTransfer.response
+// @Failure 400 {object} echo.HTTPError
+// @Failure 500 {object} echo.HTTPError
+// @Security JWT
+// @Router /api/v1/accounts/scheduled-transfers [get]
+func (r *accountRoutes) getScheduledTransfer(c echo.Context) error {
+	var input scheduledTransferInput
+
+	if err := c.Bind(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid request body")
+		return err
+	}
+
+	if err := c.Validate(input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return err
+	}
+
+	transfer, runs, err := r.accountService.GetScheduledTransfer(c.Request().Context(), input.Id)
+	if err != nil {
+		if err == service.ErrScheduledTransferNotFound {
+			newErrorResponse(c, http.StatusBadRequest, err.Error())
+			return err
+		}
+		newErrorResponse(c, http.StatusInternalServerError, "internal server error")
+		return err
+	}
+
+	type response struct {
+		Transfer entity.ScheduledTransfer      `json:"transfer"`
+		Runs     []entity.ScheduledTransferRun `json:"runs"`
+	}
+
+	return c.JSON(http.StatusOK, response{
+		Transfer: transfer,
+		Runs:     runs,
+	})
+}
+
+// @Summary Cancel scheduled transfer
+// @Description Cancel scheduled transfer which has not been executed yet
+// @Tags accounts
+// @Accept json
+// @Produce json
+// @Param input body v1.scheduledTransferInput true "input"
+// @Success 200
+// @Failure 400 {object} echo.HTTPError
+// @Failure 409 {object} echo.HTTPError
+// @Failure 500 {object} echo.HTTPError
+// @Security JWT
+// @Router /api/v1/accounts/scheduled-transfers/cancel [post]
+func (r *accountRoutes) cancelScheduledTransfer(c echo.Context) error {
+	var input scheduledTransferInput
+
+	if err := c.Bind(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid request body")
+		return err
+	}
+
+	if err := c.Validate(input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return err
+	}
+
+	err := r.accountService.CancelScheduledTransfer(c.Request().Context(), input.Id)
+	if err != nil {
+		if err == service.ErrScheduledTransferNotFound {
+			newErrorResponse(c, http.StatusBadRequest, err.Error())
+			return err
+		}
+		if err == service.ErrCannotCancelScheduledTransfer {
+			newErrorResponse(c, http.StatusConflict, err.Error())
+			return err
+		}
 		newErrorResponse(c, http.StatusInternalServerError, "internal server error")
 		return err
 	}
@@ -233,13 +447,288 @@ func (r *accountRoutes) getBalance(c echo.Context) error {
 		return err
 	}
 
+	wallets, err := r.accountService.GetAccountWallets(c.Request().Context(), input.Id)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, "internal server error")
+		return err
+	}
+
+	type wallet struct {
+		Type    string `json:"type"`
+		Balance int    `json:"balance"`
+	}
+
 	type response struct {
-		Id      int `json:"id"`
-		Balance int `json:"balance"`
+		Id      int      `json:"id"`
+		Balance int      `json:"balance"`
+		Status  string   `json:"status"`
+		Wallets []wallet `json:"wallets"`
 	}
 
-	return c.JSON(http.StatusOK, response{
+	resp := response{
 		Id:      account.Id,
 		Balance: account.Balance,
+		Status:  account.Status,
+		Wallets: make([]wallet, 0, len(wallets)),
+	}
+	for _, w := range wallets {
+		resp.Wallets = append(resp.Wallets, wallet{
+			Type:    w.Type,
+			Balance: w.Balance,
+		})
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+type accountFreezeInput struct {
+	Id         int    `json:"id" validate:"required"`
+	DebitOnly  bool   `json:"debit_only,omitempty"`
+	ReasonCode string `json:"reason_code" validate:"required,oneof=fraud_suspected security_incident compliance_check user_request other"`
+	Comment    string `json:"comment,omitempty" validate:"max=255"`
+}
+
+// @Summary Freeze account
+// @Description Freeze account fully or for outgoing operations only. Admin only
+// @Tags accounts
+// @Accept json
+// @Produce json
+// @Param input body v1.accountFreezeInput true "input"
+// @Success 200
+// @Failure 400 {object} echo.HTTPError
+// @Failure 403 {object} echo.HTTPError
+// @Failure 500 {object} echo.HTTPError
+// @Security JWT
+// @Router /api/v1/accounts/freeze [post]
+func (r *accountRoutes) freeze(c echo.Context) error {
+	var input accountFreezeInput
+
+	if err := c.Bind(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid request body")
+		return err
+	}
+
+	if err := c.Validate(input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return err
+	}
+
+	err := r.accountService.FreezeAccount(c.Request().Context(), service.AccountFreezeInput{
+		Id:         input.Id,
+		DebitOnly:  input.DebitOnly,
+		ReasonCode: input.ReasonCode,
+		Comment:    input.Comment,
+		ChangedBy:  c.Get(userIdCtx).(int),
+	})
+	if err != nil {
+		if err == service.ErrAccountNotFound || err == service.ErrAccountClosed {
+			newErrorResponse(c, http.StatusBadRequest, err.Error())
+			return err
+		}
+		newErrorResponse(c, http.StatusInternalServerError, "internal server error")
+		return err
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": "success",
+	})
+}
+
+type accountUnfreezeInput struct {
+	Id         int    `json:"id" validate:"required"`
+	ReasonCode string `json:"reason_code" validate:"required,oneof=resolved compliance_check user_request other"`
+	Comment    string `json:"comment,omitempty" validate:"max=255"`
+}
+
+// @Summary Unfreeze account
+// @Description Make frozen account active again. Admin only
+// @Tags accounts
+// @Accept json
+// @Produce json
+// @Param input body v1.accountUnfreezeInput true "input"
+// @Success 200
+// @Failure 400 {object} echo.HTTPError
+// @Failure 403 {object} echo.HTTPError
+// @Failure 500 {object} echo.HTTPError
+// @Security JWT
+// @Router /api/v1/accounts/unfreeze [post]
+func (r *accountRoutes) unfreeze(c echo.Context) error {
+	var input accountUnfreezeInput
+
+	if err := c.Bind(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid request body")
+		return err
+	}
+
+	if err := c.Validate(input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return err
+	}
+
+	err := r.accountService.UnfreezeAccount(c.Request().Context(), service.AccountUnfreezeInput{
+		Id:         input.Id,
+		ReasonCode: input.ReasonCode,
+		Comment:    input.Comment,
+		ChangedBy:  c.Get(userIdCtx).(int),
+	})
+	if err != nil {
+		if err == service.ErrAccountNotFound || err == service.ErrAccountClosed {
+			newErrorResponse(c, http.StatusBadRequest, err.Error())
+			return err
+		}
+		newErrorResponse(c, http.StatusInternalServerError, "internal server error")
+		return err
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": "success",
+	})
+}
+
+type accountCloseInput struct {
+	Id         int    `json:"id" validate:"required"`
+	Payout     bool   `json:"payout,omitempty"`
+	ReasonCode string `json:"reason_code" validate:"required,oneof=fraud_suspected security_incident compliance_check user_request other"`
+	Comment    string `json:"comment,omitempty" validate:"max=255"`
+}
+
+// @Summary Close account
+// @Description Close account. Account must have zero balance unless payout of the remainder is requested. Admin only
+// @Tags accounts
+// @Accept json
+// @Produce json
+// @Param input body v1.accountCloseInput true "input"
+// @Success 200 {object} v1.accountRoutes.close.response
+// @Failure 400 {object} echo.HTTPError
+// @Failure 403 {object} echo.HTTPError
+// @Failure 500 {object} echo.HTTPError
+// @Security JWT
+// @Router /api/v1/accounts/close [post]
+func (r *accountRoutes) close(c echo.Context) error {
+	var input accountCloseInput
+
+	if err := c.Bind(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid request body")
+		return err
+	}
+
+	if err := c.Validate(input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return err
+	}
+
+	payout, err := r.accountService.CloseAccount(c.Request().Context(), service.AccountCloseInput{
+		Id:         input.Id,
+		Payout:     input.Payout,
+		ReasonCode: input.ReasonCode,
+		Comment:    input.Comment,
+		ChangedBy:  c.Get(userIdCtx).(int),
+	})
+	if err != nil {
+		if err == service.ErrAccountNotFound || err == service.ErrAccountClosed ||
+			err == service.ErrAccountHasBalance || err == service.ErrAccountHasReservations {
+			newErrorResponse(c, http.StatusBadRequest, err.Error())
+			return err
+		}
+		newErrorResponse(c, http.StatusInternalServerError, "internal server error")
+		return err
+	}
+
+	type response struct {
+		Payout int `json:"payout"`
+	}
+
+	return c.JSON(http.StatusOK, response{
+		Payout: payout,
+	})
+}
+
+type getStatusHistoryInput struct {
+	Id int `json:"id" validate:"required"`
+}
+
+// @Summary Get status history
+// @Description Get history of account status changes with reason codes. Admin only
+// @Tags accounts
+// @Accept json
+// @Produce json
+// @Param input body v1.getStatusHistoryInput true "input"
+// @Success 200 {object} v1.accountRoutes.getStatusHistory.response
+// @Failure 400 {object} echo.HTTPError
+// @Failure 403 {object} echo.HTTPError
+// @Failure 500 {object} echo.HTTPError
+// @Security JWT
+// @Router /api/v1/accounts/status-history [get]
+func (r *accountRoutes) getStatusHistory(c echo.Context) error {
+	var input getStatusHistoryInput
+
+	if err := c.Bind(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid request body")
+		return err
+	}
+
+	if err := c.Validate(input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return err
+	}
+
+	changes, err := r.accountService.GetAccountStatusHistory(c.Request().Context(), input.Id)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, "internal server error")
+		return err
+	}
+
+	type response struct {
+		Changes []entity.AccountStatusChange `json:"changes"`
+	}
+
+	return c.JSON(http.StatusOK, response{
+		Changes: changes,
+	})
+}
+
+// @Summary Get upcoming obligations
+// @Description Get the unpaid parts of the account installment plans, the closest due first. Overdue parts which are being retried are included
+// @Tags accounts
+// @Accept json
+// @Produce json
+// @Param input body v1.getBalanceInput true "input"
+// @Success 200 {object} v1.accountRoutes.getObligations.response
+// @Failure 400 {object} echo.HTTPError
+// @Failure 500 {object} echo.HTTPError
+// @Security JWT
+// @Router /api/v1/accounts/obligations [get]
+func (r *accountRoutes) getObligations(c echo.Context) error {
+	var input getBalanceInput
+
+	if err := c.Bind(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid request body")
+		return err
+	}
+
+	if err := c.Validate(input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return err
+	}
+
+	obligations, err := r.installmentService.GetAccountObligations(c.Request().Context(), input.Id)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, "internal server error")
+		return err
+	}
+
+	total := 0
+	for _, obligation := range obligations {
+		total += obligation.Amount
+	}
+
+	type response struct {
+		Obligations []entity.InstallmentObligation `json:"obligations"`
+		Total       int                            `json:"total"`
+	}
+
+	return c.JSON(http.StatusOK, response{
+		Obligations: obligations,
+		Total:       total,
 	})
 }
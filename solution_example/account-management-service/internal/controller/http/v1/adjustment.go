@@ -0,0 +1,266 @@
+package v1
+
+import (
+	"account-management-service/internal/entity"
+	"account-management-service/internal/service"
+	"github.com/labstack/echo/v4"
+	"net/http"
+)
+
+type adjustmentRoutes struct {
+	adjustmentService service.Adjustment
+	approvalService   service.Approval
+}
+
+func newAdjustmentRoutes(g *echo.Group, adjustmentService service.Adjustment, approvalService service.Approval) {
+	r := &adjustmentRoutes{
+		adjustmentService: adjustmentService,
+		approvalService:   approvalService,
+	}
+
+	g.POST("/create", r.create)
+	g.GET("/", r.getAccountAdjustments)
+	g.POST("/reasons/set", r.setReason)
+	g.POST("/reasons/deactivate", r.deactivateReason)
+	g.GET("/reasons", r.getReasons)
+}
+
+type adjustmentCreateInput struct {
+	AccountId  int    `json:"account_id" validate:"required"`
+	Direction  string `json:"direction" validate:"required,oneof=credit debit"`
+	Amount     int    `json:"amount" validate:"required,gt=0"`
+	Wallet     string `json:"wallet,omitempty" validate:"omitempty,oneof=main bonus"`
+	ReasonCode string `json:"reason_code" validate:"required,max=64"`
+	Comment    string `json:"comment" validate:"required,max=255"`
+	Ticket     string `json:"ticket,omitempty" validate:"max=64"`
+}
+
+// @Summary Create adjustment
+// @Description Credit or debit the account to correct its balance. The reason code must be one of the active reasons. Adjustments which need approval are submitted as operation requests. Admins only
+// @Tags adjustments
+// @Accept json
+// @Produce json
+// @Param input body v1.adjustmentCreateInput true "input"
+// @Success 201 {object} v1.adjustmentRoutes.create.response
+// @Success 202
+// @Failure 400 {object} echo.HTTPError
+// @Failure 403 {object} echo.HTTPError
+// @Failure 500 {object} echo.HTTPError
+// @Security JWT
+// @Router /api/v1/adjustments/create [post]
+func (r *adjustmentRoutes) create(c echo.Context) error {
+	var input adjustmentCreateInput
+
+	if err := c.Bind(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid request body")
+		return err
+	}
+
+	if err := c.Validate(input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return err
+	}
+
+	operationType := entity.OperationTypeAdjustmentCredit
+	if input.Direction == entity.AdjustmentDirectionDebit {
+		operationType = entity.OperationTypeAdjustmentDebit
+	}
+
+	if r.approvalService.RequiresApproval(operationType, input.Amount) {
+		return submitForApproval(c, r.approvalService, service.ApprovalCreateInput{
+			OperationType: operationType,
+			AccountId:     input.AccountId,
+			Amount:        input.Amount,
+			Wallet:        input.Wallet,
+			ReasonCode:    input.ReasonCode,
+			Comment:       input.Comment,
+			Ticket:        input.Ticket,
+		})
+	}
+
+	id, err := r.adjustmentService.CreateAdjustment(c.Request().Context(), service.AdjustmentCreateInput{
+		AccountId:  input.AccountId,
+		Direction:  input.Direction,
+		Amount:     input.Amount,
+		Wallet:     input.Wallet,
+		ReasonCode: input.ReasonCode,
+		Comment:    input.Comment,
+		Ticket:     input.Ticket,
+		CreatedBy:  c.Get(userIdCtx).(int),
+	})
+	if err != nil {
+		if err == service.ErrInvalidAdjustment || err == service.ErrUnknownAdjustmentReason ||
+			err == service.ErrAccountNotFound || err == service.ErrNotEnoughBalance {
+			newErrorResponse(c, http.StatusBadRequest, err.Error())
+			return err
+		}
+		if err == service.ErrAccountFrozen || err == service.ErrAccountClosed {
+			newErrorResponse(c, http.StatusForbidden, err.Error())
+			return err
+		}
+		newErrorResponse(c, http.StatusInternalServerError, "internal server error")
+		return err
+	}
+
+	type response struct {
+		Id int `json:"id"`
+	}
+
+	return c.JSON(http.StatusCreated, response{
+		Id: id,
+	})
+}
+
+type adjustmentGetAccountAdjustmentsInput struct {
+	AccountId int `json:"account_id" validate:"required"`
+}
+
+// @Summary Get account adjustments
+// @Description Get adjustments of the account with their reasons and tickets, newest first. Admins only
+// @Tags adjustments
+// @Accept json
+// @Produce json
+// @Param input body v1.adjustmentGetAccountAdjustmentsInput true "input"
+// @Success 200 {object} v1.adjustmentRoutes.getAccountAdjustments.response
+// @Failure 400 {object} echo.HTTPError
+// @Failure 403 {object} echo.HTTPError
+// @Failure 500 {object} echo.HTTPError
+// @Security JWT
+// @Router /api/v1/adjustments/ [get]
+func (r *adjustmentRoutes) getAccountAdjustments(c echo.Context) error {
+	var input adjustmentGetAccountAdjustmentsInput
+
+	if err := c.Bind(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid request body")
+		return err
+	}
+
+	if err := c.Validate(input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return err
+	}
+
+	adjustments, err := r.adjustmentService.GetAccountAdjustments(c.Request().Context(), input.AccountId)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, "internal server error")
+		return err
+	}
+
+	type response struct {
+		Adjustments []entity.Adjustment `json:"adjustments"`
+	}
+
+	return c.JSON(http.StatusOK, response{
+		Adjustments: adjustments,
+	})
+}
+
+type adjustmentSetReasonInput struct {
+	Code        string `json:"code" validate:"required,max=64"`
+	Description string `json:"description" validate:"required,max=255"`
+}
+
+// @Summary Set adjustment reason
+// @Description Add the adjustment reason or update the description of the existing one, the reason becomes active. Admins only
+// @Tags adjustments
+// @Accept json
+// @Produce json
+// @Param input body v1.adjustmentSetReasonInput true "input"
+// @Success 200
+// @Failure 400 {object} echo.HTTPError
+// @Failure 403 {object} echo.HTTPError
+// @Failure 500 {object} echo.HTTPError
+// @Security JWT
+// @Router /api/v1/adjustments/reasons/set [post]
+func (r *adjustmentRoutes) setReason(c echo.Context) error {
+	var input adjustmentSetReasonInput
+
+	if err := c.Bind(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid request body")
+		return err
+	}
+
+	if err := c.Validate(input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return err
+	}
+
+	err := r.adjustmentService.SetAdjustmentReason(c.Request().Context(), input.Code, input.Description)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, "internal server error")
+		return err
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": "success",
+	})
+}
+
+type adjustmentDeactivateReasonInput struct {
+	Code string `json:"code" validate:"required"`
+}
+
+// @Summary Deactivate adjustment reason
+// @Description Forbid new adjustments with the reason, existing adjustments keep it. Admins only
+// @Tags adjustments
+// @Accept json
+// @Produce json
+// @Param input body v1.adjustmentDeactivateReasonInput true "input"
+// @Success 200
+// @Failure 400 {object} echo.HTTPError
+// @Failure 403 {object} echo.HTTPError
+// @Failure 500 {object} echo.HTTPError
+// @Security JWT
+// @Router /api/v1/adjustments/reasons/deactivate [post]
+func (r *adjustmentRoutes) deactivateReason(c echo.Context) error {
+	var input adjustmentDeactivateReasonInput
+
+	if err := c.Bind(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid request body")
+		return err
+	}
+
+	if err := c.Validate(input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return err
+	}
+
+	err := r.adjustmentService.DeactivateAdjustmentReason(c.Request().Context(), input.Code)
+	if err != nil {
+		if err == service.ErrUnknownAdjustmentReason {
+			newErrorResponse(c, http.StatusBadRequest, err.Error())
+			return err
+		}
+		newErrorResponse(c, http.StatusInternalServerError, "internal server error")
+		return err
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": "success",
+	})
+}
+
+// @Summary Get adjustment reasons
+// @Description Get all adjustment reasons including inactive ones. Admins only
+// @Tags adjustments
+// @Produce json
+// @Success 200 {object} v1.adjustmentRoutes.getReasons.response
+// @Failure 403 {object} echo.HTTPError
+// @Failure 500 {object} echo.HTTPError
+// @Security JWT
+// @Router /api/v1/adjustments/reasons [get]
+func (r *adjustmentRoutes) getReasons(c echo.Context) error {
+	reasons, err := r.adjustmentService.GetAdjustmentReasons(c.Request().Context())
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, "internal server error")
+		return err
+	}
+
+	type response struct {
+		Reasons []entity.AdjustmentReason `json:"reasons"`
+	}
+
+	return c.JSON(http.StatusOK, response{
+		Reasons: reasons,
+	})
+}
@@ -0,0 +1,252 @@
+package v1
+
+import (
+	"account-management-service/internal/entity"
+	"account-management-service/internal/service"
+	"github.com/labstack/echo/v4"
+	"net/http"
+)
+
+type approvalRoutes struct {
+	approvalService service.Approval
+}
+
+func newApprovalRoutes(g *echo.Group, approvalService service.Approval) {
+	r := &approvalRoutes{
+		approvalService: approvalService,
+	}
+
+	g.POST("/approve", r.approve)
+	g.POST("/reject", r.reject)
+	g.GET("/request", r.getRequest)
+	g.GET("/", r.getRequests)
+}
+
+type approvalResolveInput struct {
+	Id      int    `json:"id" validate:"required"`
+	Comment string `json:"comment,omitempty" validate:"max=255"`
+}
+
+// @Summary Approve operation
+// @Description Approve the pending operation request and execute the operation. The request must be approved by another user than the one who submitted it. Approvers only
+// @Tags approvals
+// @Accept json
+// @Produce json
+// @Param input body v1.approvalResolveInput true "input"
+// @Success 200 {object} v1.approvalRoutes.approve.response
+// @Failure 400 {object} echo.HTTPError
+// @Failure 403 {object} echo.HTTPError
+// @Failure 409 {object} echo.HTTPError
+// @Failure 500 {object} echo.HTTPError
+// @Security JWT
+// @Router /api/v1/approvals/approve [post]
+func (r *approvalRoutes) approve(c echo.Context) error {
+	var input approvalResolveInput
+
+	if err := c.Bind(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid request body")
+		return err
+	}
+
+	if err := c.Validate(input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return err
+	}
+
+	request, err := r.approvalService.ApproveRequest(c.Request().Context(), service.ApprovalResolveInput{
+		Id:        input.Id,
+		CheckerId: c.Get(userIdCtx).(int),
+		Comment:   input.Comment,
+	})
+	if err != nil {
+		if err == service.ErrApprovalRequestNotFound {
+			newErrorResponse(c, http.StatusBadRequest, err.Error())
+			return err
+		}
+		if err == service.ErrSelfApproval {
+			newErrorResponse(c, http.StatusForbidden, err.Error())
+			return err
+		}
+		if err == service.ErrApprovalRequestNotPending || err == service.ErrApprovalRequestExpired {
+			newErrorResponse(c, http.StatusConflict, err.Error())
+			return err
+		}
+		newErrorResponse(c, http.StatusInternalServerError, "internal server error")
+		return err
+	}
+
+	type response struct {
+		Request entity.ApprovalRequest `json:"request"`
+	}
+
+	return c.JSON(http.StatusOK, response{
+		Request: request,
+	})
+}
+
+// @Summary Reject operation
+// @Description Reject the pending operation request. The request must be rejected by another user than the one who submitted it. Approvers only
+// @Tags approvals
+// @Accept json
+// @Produce json
+// @Param input body v1.approvalResolveInput true "input"
+// @Success 200
+// @Failure 400 {object} echo.HTTPError
+// @Failure 403 {object} echo.HTTPError
+// @Failure 409 {object} echo.HTTPError
+// @Failure 500 {object} echo.HTTPError
+// @Security JWT
+// @Router /api/v1/approvals/reject [post]
+func (r *approvalRoutes) reject(c echo.Context) error {
+	var input approvalResolveInput
+
+	if err := c.Bind(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid request body")
+		return err
+	}
+
+	if err := c.Validate(input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return err
+	}
+
+	err := r.approvalService.RejectRequest(c.Request().Context(), service.ApprovalResolveInput{
+		Id:        input.Id,
+		CheckerId: c.Get(userIdCtx).(int),
+		Comment:   input.Comment,
+	})
+	if err != nil {
+		if err == service.ErrApprovalRequestNotFound {
+			newErrorResponse(c, http.StatusBadRequest, err.Error())
+			return err
+		}
+		if err == service.ErrSelfApproval {
+			newErrorResponse(c, http.StatusForbidden, err.Error())
+			return err
+		}
+		if err == service.ErrApprovalRequestNotPending || err == service.ErrApprovalRequestExpired {
+			newErrorResponse(c, http.StatusConflict, err.Error())
+			return err
+		}
+		newErrorResponse(c, http.StatusInternalServerError, "internal server error")
+		return err
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": "success",
+	})
+}
+
+type approvalGetRequestInput struct {
+	Id int `json:"id" validate:"required"`
+}
+
+// @Summary Get operation request
+// @Description Get the operation request with its approval audit trail. Approvers only
+// @Tags approvals
+// @Accept json
+// @Produce json
+// @Param input body v1.approvalGetRequestInput true "input"
+// @Success 200 {object} v1.approvalRoutes.getRequest.response
+// @Failure 400 {object} echo.HTTPError
+// @Failure 403 {object} echo.HTTPError
+// @Failure 500 {object} echo.HTTPError
+// @Security JWT
+// @Router /api/v1/approvals/request [get]
+func (r *approvalRoutes) getRequest(c echo.Context) error {
+	var input approvalGetRequestInput
+
+	if err := c.Bind(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid request body")
+		return err
+	}
+
+	if err := c.Validate(input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return err
+	}
+
+	request, events, err := r.approvalService.GetApprovalRequest(c.Request().Context(), input.Id)
+	if err != nil {
+		if err == service.ErrApprovalRequestNotFound {
+			newErrorResponse(c, http.StatusBadRequest, err.Error())
+			return err
+		}
+		newErrorResponse(c, http.StatusInternalServerError, "internal server error")
+		return err
+	}
+
+	type response struct {
+		Request entity.ApprovalRequest `json:"request"`
+		Events  []entity.ApprovalEvent `json:"events"`
+	}
+
+	return c.JSON(http.StatusOK, response{
+		Request: request,
+		Events:  events,
+	})
+}
+
+type approvalGetRequestsInput struct {
+	Status string `json:"status,omitempty" validate:"omitempty,oneof=pending approved executed failed rejected expired"`
+}
+
+// @Summary Get operation requests
+// @Description Get operation requests, optionally filtered by status, newest first. Approvers only
+// @Tags approvals
+// @Accept json
+// @Produce json
+// @Param input body v1.approvalGetRequestsInput false "input"
+// @Success 200 {object} v1.approvalRoutes.getRequests.response
+// @Failure 400 {object} echo.HTTPError
+// @Failure 403 {object} echo.HTTPError
+// @Failure 500 {object} echo.HTTPError
+// @Security JWT
+// @Router /api/v1/approvals/ [get]
+func (r *approvalRoutes) getRequests(c echo.Context) error {
+	var input approvalGetRequestsInput
+
+	if err := c.Bind(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid request body")
+		return err
+	}
+
+	if err := c.Validate(input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return err
+	}
+
+	requests, err := r.approvalService.GetApprovalRequests(c.Request().Context(), input.Status)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, "internal server error")
+		return err
+	}
+
+	type response struct {
+		Requests []entity.ApprovalRequest `json:"requests"`
+	}
+
+	return c.JSON(http.StatusOK, response{
+		Requests: requests,
+	})
+}
+
+// submitForApproval creates a request for the operation on behalf of the caller instead of executing it
+func submitForApproval(c echo.Context, approvalService service.Approval, input service.ApprovalCreateInput) error {
+	input.MakerId = c.Get(userIdCtx).(int)
+
+	id, err := approvalService.CreateApprovalRequest(c.Request().Context(), input)
+	if err != nil {
+		if err == service.ErrInvalidApprovalRequest {
+			newErrorResponse(c, http.StatusBadRequest, err.Error())
+			return err
+		}
+		newErrorResponse(c, http.StatusInternalServerError, "internal server error")
+		return err
+	}
+
+	return c.JSON(http.StatusAccepted, map[string]interface{}{
+		"message":     "operation is waiting for approval",
+		"approval_id": id,
+	})
+}
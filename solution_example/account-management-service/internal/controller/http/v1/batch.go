@@ -0,0 +1,122 @@
+package v1
+
+import (
+	"account-management-service/internal/entity"
+	"account-management-service/internal/service"
+	"github.com/labstack/echo/v4"
+	"net/http"
+)
+
+type batchRoutes struct {
+	batchService service.Batch
+}
+
+func newBatchRoutes(g *echo.Group, batchService service.Batch) {
+	r := &batchRoutes{
+		batchService: batchService,
+	}
+
+	g.POST("/execute", r.execute)
+}
+
+type batchItemInput struct {
+	Type      string `json:"type"`
+	AccountId int    `json:"account_id,omitempty"`
+	From      int    `json:"from,omitempty"`
+	To        int    `json:"to,omitempty"`
+	Amount    int    `json:"amount"`
+	Wallet    string `json:"wallet,omitempty"`
+}
+
+type batchExecuteInput struct {
+	Mode        string           `json:"mode" validate:"required,oneof=atomic best_effort"`
+	Description string           `json:"description,omitempty" validate:"max=255"`
+	Items       []batchItemInput `json:"items" validate:"required"`
+}
+
+// @Summary Execute batch
+// @Description Execute a list of deposits and transfers. Every item is validated before anything is executed.
+// @Description In atomic mode all the items are executed in one transaction, in best_effort mode items are executed in chunks and failed ones are skipped.
+// @Description Transfers are subject to the sender limits, verification and fees like a single transfer.
+// @Description Deposits which need approval are refused, they must be submitted through /accounts/deposit
+// @Tags batch
+// @Accept json
+// @Produce json
+// @Param input body v1.batchExecuteInput true "input"
+// @Success 200 {object} v1.batchRoutes.execute.response
+// @Failure 400 {object} echo.HTTPError
+// @Failure 422 {object} v1.batchRoutes.execute.response
+// @Failure 500 {object} echo.HTTPError
+// @Security JWT
+// @Router /api/v1/batch/execute [post]
+func (r *batchRoutes) execute(c echo.Context) error {
+	var input batchExecuteInput
+
+	if err := c.Bind(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid request body")
+		return err
+	}
+
+	if err := c.Validate(input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return err
+	}
+
+	items := make([]entity.BatchItem, 0, len(input.Items))
+	for _, item := range input.Items {
+		items = append(items, entity.BatchItem{
+			Type:      item.Type,
+			AccountId: item.AccountId,
+			From:      item.From,
+			To:        item.To,
+			Amount:    item.Amount,
+			Wallet:    item.Wallet,
+		})
+	}
+
+	results, err := r.batchService.ExecuteBatch(c.Request().Context(), service.BatchExecuteInput{
+		Mode:        input.Mode,
+		Description: input.Description,
+		Items:       items,
+	})
+
+	type response struct {
+		Succeeded int                      `json:"succeeded"`
+		Failed    int                      `json:"failed"`
+		Error     string                   `json:"error,omitempty"`
+		Results   []entity.BatchItemResult `json:"results"`
+	}
+
+	if err != nil {
+		if err == service.ErrInvalidBatchSize {
+			newErrorResponse(c, http.StatusBadRequest, err.Error())
+			return err
+		}
+		if err == service.ErrInvalidBatch || err == service.ErrBatchRolledBack {
+			_ = c.JSON(http.StatusUnprocessableEntity, response{
+				Failed:  countBatchResults(results, entity.BatchItemStatusFailed),
+				Error:   err.Error(),
+				Results: results,
+			})
+			return err
+		}
+		newErrorResponse(c, http.StatusInternalServerError, "internal server error")
+		return err
+	}
+
+	return c.JSON(http.StatusOK, response{
+		Succeeded: countBatchResults(results, entity.BatchItemStatusSucceeded),
+		Failed:    countBatchResults(results, entity.BatchItemStatusFailed),
+		Results:   results,
+	})
+}
+
+func countBatchResults(results []entity.BatchItemResult, status string) int {
+	count := 0
+	for _, result := range results {
+		if result.Status == status {
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,151 @@
+package v1
+
+import (
+	"account-management-service/internal/entity"
+	"account-management-service/internal/service"
+	"github.com/labstack/echo/v4"
+	"net/http"
+	"time"
+)
+
+type cashbackRoutes struct {
+	cashbackService service.Cashback
+}
+
+func newCashbackRoutes(g *echo.Group, cashbackService service.Cashback) {
+	r := &cashbackRoutes{
+		cashbackService: cashbackService,
+	}
+
+	g.POST("/create", r.create)
+	g.POST("/delete", r.delete)
+	g.GET("/", r.getAll)
+}
+
+type cashbackCreateInput struct {
+	ProductId int        `json:"product_id" validate:"required"`
+	Rate      int        `json:"rate" validate:"required,gt=0,lte=10000"`
+	Cap       int        `json:"cap,omitempty" validate:"gte=0"`
+	ValidFrom time.Time  `json:"valid_from,omitempty"`
+	ValidTo   *time.Time `json:"valid_to,omitempty"`
+}
+
+// @Summary Create cashback rule
+// @Description Create cashback rule for a product. Rate is in basis points of the money spent from the main wallet, 100 is 1%, cap limits the cashback per order, 0 is no limit. Admin only
+// @Tags cashback
+// @Accept json
+// @Produce json
+// @Param input body v1.cashbackCreateInput true "input"
+// @Success 201 {object} v1.cashbackRoutes.create.response
+// @Failure 400 {object} echo.HTTPError
+// @Failure 403 {object} echo.HTTPError
+// @Failure 500 {object} echo.HTTPError
+// @Security JWT
+// @Router /api/v1/cashback/create [post]
+func (r *cashbackRoutes) create(c echo.Context) error {
+	var input cashbackCreateInput
+
+	if err := c.Bind(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid request body")
+		return err
+	}
+
+	if err := c.Validate(input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return err
+	}
+
+	id, err := r.cashbackService.CreateCashbackRule(c.Request().Context(), service.CashbackCreateRuleInput{
+		ProductId: input.ProductId,
+		Rate:      input.Rate,
+		Cap:       input.Cap,
+		ValidFrom: input.ValidFrom,
+		ValidTo:   input.ValidTo,
+	})
+	if err != nil {
+		if err == service.ErrInvalidCashbackRule {
+			newErrorResponse(c, http.StatusBadRequest, err.Error())
+			return err
+		}
+		newErrorResponse(c, http.StatusInternalServerError, "internal server error")
+		return err
+	}
+
+	type response struct {
+		Id int `json:"id"`
+	}
+
+	return c.JSON(http.StatusCreated, response{
+		Id: id,
+	})
+}
+
+type cashbackDeleteInput struct {
+	Id int `json:"id" validate:"required"`
+}
+
+// @Summary Delete cashback rule
+// @Description Delete cashback rule, the cashback already granted by it is kept. Admin only
+// @Tags cashback
+// @Accept json
+// @Produce json
+// @Param input body v1.cashbackDeleteInput true "input"
+// @Success 200
+// @Failure 400 {object} echo.HTTPError
+// @Failure 403 {object} echo.HTTPError
+// @Failure 500 {object} echo.HTTPError
+// @Security JWT
+// @Router /api/v1/cashback/delete [post]
+func (r *cashbackRoutes) delete(c echo.Context) error {
+	var input cashbackDeleteInput
+
+	if err := c.Bind(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid request body")
+		return err
+	}
+
+	if err := c.Validate(input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return err
+	}
+
+	err := r.cashbackService.DeleteCashbackRule(c.Request().Context(), input.Id)
+	if err != nil {
+		if err == service.ErrCashbackRuleNotFound {
+			newErrorResponse(c, http.StatusBadRequest, err.Error())
+			return err
+		}
+		newErrorResponse(c, http.StatusInternalServerError, "internal server error")
+		return err
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": "success",
+	})
+}
+
+// @Summary Get cashback rules
+// @Description Get all cashback rules. Admin only
+// @Tags cashback
+// @Accept json
+// @Produce json
+// @Success 200 {object} v1.cashbackRoutes.getAll.response
+// @Failure 403 {object} echo.HTTPError
+// @Failure 500 {object} echo.HTTPError
+// @Security JWT
+// @Router /api/v1/cashback/ [get]
+func (r *cashbackRoutes) getAll(c echo.Context) error {
+	rules, err := r.cashbackService.GetAllCashbackRules(c.Request().Context())
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, "internal server error")
+		return err
+	}
+
+	type response struct {
+		Rules []entity.CashbackRule `json:"rules"`
+	}
+
+	return c.JSON(http.StatusOK, response{
+		Rules: rules,
+	})
+}
@@ -9,6 +9,7 @@ import (
 var (
 	ErrInvalidAuthHeader = fmt.Errorf("invalid auth header")
 	ErrCannotParseToken  = fmt.Errorf("cannot parse token")
+	ErrAccessDenied      = fmt.Errorf("access denied")
 )
 
 func newErrorResponse(c echo.Context, errStatus int, message string) {
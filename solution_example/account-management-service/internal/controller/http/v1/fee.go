@@ -0,0 +1,266 @@
+package v1
+
+import (
+	"account-management-service/internal/entity"
+	"account-management-service/internal/service"
+	"github.com/labstack/echo/v4"
+	"net/http"
+)
+
+type feeRoutes struct {
+	feeService service.Fee
+}
+
+func newFeeRoutes(g *echo.Group, feeService service.Fee, adminOnly echo.MiddlewareFunc) {
+	r := &feeRoutes{
+		feeService: feeService,
+	}
+
+	g.POST("/create", r.create, adminOnly)
+	g.POST("/delete", r.delete, adminOnly)
+	g.GET("/", r.getAll, adminOnly)
+	g.GET("/quote", r.quote)
+	g.GET("/income", r.income, adminOnly)
+}
+
+type feeCreateInput struct {
+	OperationType string `json:"operation_type" validate:"required,oneof=withdraw transfer_from reservation"`
+	ProductId     int    `json:"product_id,omitempty"`
+	AccountType   string `json:"account_type,omitempty" validate:"omitempty,oneof=personal business"`
+	Rate          int    `json:"rate,omitempty"`
+	Fixed         int    `json:"fixed,omitempty"`
+	MinFee        int    `json:"min_fee,omitempty"`
+	MaxFee        int    `json:"max_fee,omitempty"`
+	FreeUpTo      int    `json:"free_up_to,omitempty"`
+}
+
+// @Summary Create fee rule
+// @Description Create fee rule for an operation type, optionally narrowed to a product and an account type. Rate is in basis points, 100 is 1%. Admin only
+// @Tags fees
+// @Accept json
+// @Produce json
+// @Param input body v1.feeCreateInput true "input"
+// @Success 201 {object} v1.feeRoutes.create.response
+// @Failure 400 {object} echo.HTTPError
+// @Failure 403 {object} echo.HTTPError
+// @Failure 500 {object} echo.HTTPError
+// @Security JWT
+// @Router /api/v1/fees/create [post]
+func (r *feeRoutes) create(c echo.Context) error {
+	var input feeCreateInput
+
+	if err := c.Bind(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid request body")
+		return err
+	}
+
+	if err := c.Validate(input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return err
+	}
+
+	id, err := r.feeService.CreateFeeRule(c.Request().Context(), service.FeeCreateRuleInput{
+		OperationType: input.OperationType,
+		ProductId:     input.ProductId,
+		AccountType:   input.AccountType,
+		Rate:          input.Rate,
+		Fixed:         input.Fixed,
+		MinFee:        input.MinFee,
+		MaxFee:        input.MaxFee,
+		FreeUpTo:      input.FreeUpTo,
+	})
+	if err != nil {
+		if err == service.ErrInvalidFeeRule {
+			newErrorResponse(c, http.StatusBadRequest, err.Error())
+			return err
+		}
+		newErrorResponse(c, http.StatusInternalServerError, "internal server error")
+		return err
+	}
+
+	type response struct {
+		Id int `json:"id"`
+	}
+
+	return c.JSON(http.StatusCreated, response{
+		Id: id,
+	})
+}
+
+type feeDeleteInput struct {
+	Id int `json:"id" validate:"required"`
+}
+
+// @Summary Delete fee rule
+// @Description Delete fee rule. Admin only
+// @Tags fees
+// @Accept json
+// @Produce json
+// @Param input body v1.feeDeleteInput true "input"
+// @Success 200
+// @Failure 400 {object} echo.HTTPError
+// @Failure 403 {object} echo.HTTPError
+// @Failure 500 {object} echo.HTTPError
+// @Security JWT
+// @Router /api/v1/fees/delete [post]
+func (r *feeRoutes) delete(c echo.Context) error {
+	var input feeDeleteInput
+
+	if err := c.Bind(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid request body")
+		return err
+	}
+
+	if err := c.Validate(input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return err
+	}
+
+	err := r.feeService.DeleteFeeRule(c.Request().Context(), input.Id)
+	if err != nil {
+		if err == service.ErrFeeRuleNotFound {
+			newErrorResponse(c, http.StatusBadRequest, err.Error())
+			return err
+		}
+		newErrorResponse(c, http.StatusInternalServerError, "internal server error")
+		return err
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": "success",
+	})
+}
+
+// @Summary Get fee rules
+// @Description Get all fee rules. Admin only
+// @Tags fees
+// @Accept json
+// @Produce json
+// @Success 200 {object} v1.feeRoutes.getAll.response
+// @Failure 403 {object} echo.HTTPError
+// @Failure 500 {object} echo.HTTPError
+// @Security JWT
+// @Router /api/v1/fees/ [get]
+func (r *feeRoutes) getAll(c echo.Context) error {
+	rules, err := r.feeService.GetAllFeeRules(c.Request().Context())
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, "internal server error")
+		return err
+	}
+
+	type response struct {
+		Rules []entity.FeeRule `json:"rules"`
+	}
+
+	return c.JSON(http.StatusOK, response{
+		Rules: rules,
+	})
+}
+
+type feeQuoteInput struct {
+	AccountId     int    `json:"account_id" validate:"required"`
+	OperationType string `json:"operation_type" validate:"required,oneof=withdraw transfer_from reservation"`
+	ProductId     int    `json:"product_id,omitempty"`
+	Amount        int    `json:"amount" validate:"required,gt=0"`
+}
+
+// @Summary Quote fee
+// @Description Preview the fee which would be charged for the operation, the total is the amount debited from the main wallet
+// @Tags fees
+// @Accept json
+// @Produce json
+// @Param input body v1.feeQuoteInput true "input"
+// @Success 200 {object} v1.feeRoutes.quote.response
+// @Failure 400 {object} echo.HTTPError
+// @Failure 500 {object} echo.HTTPError
+// @Security JWT
+// @Router /api/v1/fees/quote [get]
+func (r *feeRoutes) quote(c echo.Context) error {
+	var input feeQuoteInput
+
+	if err := c.Bind(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid request body")
+		return err
+	}
+
+	if err := c.Validate(input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return err
+	}
+
+	fee, err := r.feeService.CalculateFee(c.Request().Context(), service.FeeCalculateInput{
+		AccountId:     input.AccountId,
+		OperationType: input.OperationType,
+		ProductId:     input.ProductId,
+		Amount:        input.Amount,
+	})
+	if err != nil {
+		if err == service.ErrAccountNotFound {
+			newErrorResponse(c, http.StatusBadRequest, err.Error())
+			return err
+		}
+		newErrorResponse(c, http.StatusInternalServerError, "internal server error")
+		return err
+	}
+
+	type response struct {
+		Amount int `json:"amount"`
+		Fee    int `json:"fee"`
+		Total  int `json:"total"`
+	}
+
+	return c.JSON(http.StatusOK, response{
+		Amount: input.Amount,
+		Fee:    fee.Amount,
+		Total:  input.Amount + fee.Amount,
+	})
+}
+
+type feeIncomeInput struct {
+	From string `json:"from" validate:"required,datetime=2006-01-02"`
+	To   string `json:"to" validate:"required,datetime=2006-01-02"`
+}
+
+// @Summary Get fee income
+// @Description Get the fees collected in the [from, to) range. Fees are not in the revenue report, as they are not the revenue of any product. Admin only
+// @Tags fees
+// @Accept json
+// @Produce json
+// @Param input body v1.feeIncomeInput true "input"
+// @Success 200 {object} v1.feeRoutes.income.response
+// @Failure 400 {object} echo.HTTPError
+// @Failure 403 {object} echo.HTTPError
+// @Failure 500 {object} echo.HTTPError
+// @Security JWT
+// @Router /api/v1/fees/income [get]
+func (r *feeRoutes) income(c echo.Context) error {
+	var input feeIncomeInput
+
+	if err := c.Bind(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid request body")
+		return err
+	}
+
+	if err := c.Validate(input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return err
+	}
+
+	income, err := r.feeService.GetFeeIncome(c.Request().Context(), parseReportDate(input.From), parseReportDate(input.To))
+	if err != nil {
+		if isReportParamsError(err) {
+			newErrorResponse(c, http.StatusBadRequest, err.Error())
+			return err
+		}
+		newErrorResponse(c, http.StatusInternalServerError, "internal server error")
+		return err
+	}
+
+	type response struct {
+		Income int `json:"income"`
+	}
+
+	return c.JSON(http.StatusOK, response{
+		Income: income,
+	})
+}
@@ -0,0 +1,274 @@
+package v1
+
+import (
+	"account-management-service/internal/entity"
+	"account-management-service/internal/service"
+	"errors"
+	"github.com/labstack/echo/v4"
+	"net/http"
+)
+
+type groupRoutes struct {
+	groupService       service.Group
+	reservationService service.Reservation
+}
+
+func newGroupRoutes(g *echo.Group, groupService service.Group, reservationService service.Reservation) {
+	r := &groupRoutes{
+		groupService:       groupService,
+		reservationService: reservationService,
+	}
+
+	g.POST("/create", r.create)
+	g.POST("/members/set", r.setMember)
+	g.POST("/members/remove", r.removeMember)
+	g.GET("/members", r.getMembers)
+	g.POST("/reserve", r.reserve)
+}
+
+// @Summary Create group account
+// @Description Create business account shared by several users, the current user becomes its owner
+// @Tags groups
+// @Accept json
+// @Produce json
+// @Success 201 {object} v1.groupRoutes.create.response
+// @Failure 500 {object} echo.HTTPError
+// @Security JWT
+// @Router /api/v1/groups/create [post]
+func (r *groupRoutes) create(c echo.Context) error {
+	id, err := r.groupService.CreateGroupAccount(c.Request().Context(), c.Get(userIdCtx).(int))
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, "internal server error")
+		return err
+	}
+
+	type response struct {
+		Id int `json:"id"`
+	}
+
+	return c.JSON(http.StatusCreated, response{
+		Id: id,
+	})
+}
+
+type groupSetMemberInput struct {
+	AccountId    int    `json:"account_id" validate:"required"`
+	UserId       int    `json:"user_id" validate:"required"`
+	Role         string `json:"role" validate:"required,oneof=owner spender viewer"`
+	MonthlyLimit int    `json:"monthly_limit,omitempty" validate:"gte=0"`
+}
+
+// @Summary Set group member
+// @Description Add member to the group account or change the role and the monthly spending limit of a member, 0 is no limit. Owner only
+// @Tags groups
+// @Accept json
+// @Produce json
+// @Param input body v1.groupSetMemberInput true "input"
+// @Success 200
+// @Failure 400 {object} echo.HTTPError
+// @Failure 403 {object} echo.HTTPError
+// @Failure 500 {object} echo.HTTPError
+// @Security JWT
+// @Router /api/v1/groups/members/set [post]
+func (r *groupRoutes) setMember(c echo.Context) error {
+	var input groupSetMemberInput
+
+	if err := c.Bind(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid request body")
+		return err
+	}
+
+	if err := c.Validate(input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return err
+	}
+
+	err := r.groupService.SetGroupMember(c.Request().Context(), service.GroupSetMemberInput{
+		AccountId:    input.AccountId,
+		UserId:       input.UserId,
+		Role:         input.Role,
+		MonthlyLimit: input.MonthlyLimit,
+		ActorId:      c.Get(userIdCtx).(int),
+	})
+	if err != nil {
+		if err == service.ErrUserNotFound || err == service.ErrCannotChangeOwnMembership {
+			newErrorResponse(c, http.StatusBadRequest, err.Error())
+			return err
+		}
+		if err == service.ErrNotGroupMember || err == service.ErrGroupAccessDenied {
+			newErrorResponse(c, http.StatusForbidden, err.Error())
+			return err
+		}
+		newErrorResponse(c, http.StatusInternalServerError, "internal server error")
+		return err
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": "success",
+	})
+}
+
+type groupRemoveMemberInput struct {
+	AccountId int `json:"account_id" validate:"required"`
+	UserId    int `json:"user_id" validate:"required"`
+}
+
+// @Summary Remove group member
+// @Description Remove member from the group account. Owner only
+// @Tags groups
+// @Accept json
+// @Produce json
+// @Param input body v1.groupRemoveMemberInput true "input"
+// @Success 200
+// @Failure 400 {object} echo.HTTPError
+// @Failure 403 {object} echo.HTTPError
+// @Failure 500 {object} echo.HTTPError
+// @Security JWT
+// @Router /api/v1/groups/members/remove [post]
+func (r *groupRoutes) removeMember(c echo.Context) error {
+	var input groupRemoveMemberInput
+
+	if err := c.Bind(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid request body")
+		return err
+	}
+
+	if err := c.Validate(input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return err
+	}
+
+	actorId := c.Get(userIdCtx).(int)
+	err := r.groupService.RemoveGroupMember(c.Request().Context(), input.AccountId, input.UserId, actorId)
+	if err != nil {
+		if err == service.ErrCannotChangeOwnMembership {
+			newErrorResponse(c, http.StatusBadRequest, err.Error())
+			return err
+		}
+		if err == service.ErrNotGroupMember || err == service.ErrGroupAccessDenied {
+			newErrorResponse(c, http.StatusForbidden, err.Error())
+			return err
+		}
+		newErrorResponse(c, http.StatusInternalServerError, "internal server error")
+		return err
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": "success",
+	})
+}
+
+type groupMembersInput struct {
+	AccountId int `json:"account_id" validate:"required"`
+}
+
+// @Summary Get group members
+// @Description Get members of the group account with their roles and limits. Members only
+// @Tags groups
+// @Accept json
+// @Produce json
+// @Param input body v1.groupMembersInput true "input"
+// @Success 200 {object} v1.groupRoutes.getMembers.response
+// @Failure 400 {object} echo.HTTPError
+// @Failure 403 {object} echo.HTTPError
+// @Failure 500 {object} echo.HTTPError
+// @Security JWT
+// @Router /api/v1/groups/members [get]
+func (r *groupRoutes) getMembers(c echo.Context) error {
+	var input groupMembersInput
+
+	if err := c.Bind(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid request body")
+		return err
+	}
+
+	if err := c.Validate(input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return err
+	}
+
+	members, err := r.groupService.GetGroupMembers(c.Request().Context(), input.AccountId, c.Get(userIdCtx).(int))
+	if err != nil {
+		if err == service.ErrNotGroupMember {
+			newErrorResponse(c, http.StatusForbidden, err.Error())
+			return err
+		}
+		newErrorResponse(c, http.StatusInternalServerError, "internal server error")
+		return err
+	}
+
+	type response struct {
+		Members []entity.GroupMember `json:"members"`
+	}
+
+	return c.JSON(http.StatusOK, response{
+		Members: members,
+	})
+}
+
+type groupReserveInput struct {
+	AccountId int `json:"account_id" validate:"required"`
+	ProductId int `json:"product_id" validate:"required"`
+	OrderId   int `json:"order_id" validate:"required"`
+	Amount    int `json:"amount" validate:"required,gt=0"`
+}
+
+// @Summary Reserve from group account
+// @Description Create reservation from the group account on behalf of the current user. The user must be an owner or a spender and stay within the monthly limit
+// @Tags groups
+// @Accept json
+// @Produce json
+// @Param input body v1.groupReserveInput true "input"
+// @Success 201 {object} v1.groupRoutes.reserve.response
+// @Failure 400 {object} echo.HTTPError
+// @Failure 403 {object} echo.HTTPError
+// @Failure 422 {object} echo.HTTPError
+// @Failure 500 {object} echo.HTTPError
+// @Security JWT
+// @Router /api/v1/groups/reserve [post]
+func (r *groupRoutes) reserve(c echo.Context) error {
+	var input groupReserveInput
+
+	if err := c.Bind(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid request body")
+		return err
+	}
+
+	if err := c.Validate(input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return err
+	}
+
+	id, err := r.reservationService.CreateReservation(c.Request().Context(), service.ReservationCreateInput{
+		AccountId: input.AccountId,
+		ProductId: input.ProductId,
+		OrderId:   input.OrderId,
+		Amount:    input.Amount,
+		UserId:    c.Get(userIdCtx).(int),
+	})
+	if err != nil {
+		if err == service.ErrCannotCreateReservation {
+			newErrorResponse(c, http.StatusBadRequest, err.Error())
+			return err
+		}
+		if err == service.ErrAccountFrozen || err == service.ErrAccountClosed ||
+			err == service.ErrNotGroupMember || err == service.ErrGroupAccessDenied {
+			newErrorResponse(c, http.StatusForbidden, err.Error())
+			return err
+		}
+		if err == service.ErrMemberLimitExceeded || errors.Is(err, service.ErrLimitExceeded) {
+			newErrorResponse(c, http.StatusUnprocessableEntity, err.Error())
+			return err
+		}
+		newErrorResponse(c, http.StatusInternalServerError, "internal server error")
+		return err
+	}
+
+	type response struct {
+		Id int `json:"id"`
+	}
+
+	return c.JSON(http.StatusCreated, response{
+		Id: id,
+	})
+}
@@ -0,0 +1,152 @@
+package v1
+
+import (
+	"account-management-service/internal/entity"
+	"account-management-service/internal/service"
+	"github.com/labstack/echo/v4"
+	"net/http"
+)
+
+type installmentRoutes struct {
+	installmentService service.Installment
+}
+
+func newInstallmentRoutes(g *echo.Group, installmentService service.Installment) {
+	r := &installmentRoutes{
+		installmentService: installmentService,
+	}
+
+	g.POST("/create", r.create)
+	g.GET("/", r.getById)
+}
+
+type installmentCreateInput struct {
+	AccountId    int    `json:"account_id" validate:"required"`
+	ProductId    int    `json:"product_id" validate:"required"`
+	OrderId      int    `json:"order_id" validate:"required"`
+	Amount       int    `json:"amount" validate:"required,gt=0"`
+	FirstPayment int    `json:"first_payment,omitempty" validate:"gte=0"`
+	Parts        int    `json:"parts" validate:"required,gte=2,lte=24"`
+	Period       string `json:"period" validate:"required,oneof=day week month"`
+}
+
+// @Summary Create installment plan
+// @Description Pay for the order in parts: first_payment (or an equal part) is reserved right away, the rest of the amount is split evenly and reserved once per period
+// @Tags installments
+// @Accept json
+// @Produce json
+// @Param input body v1.installmentCreateInput true "input"
+// @Success 201 {object} v1.installmentRoutes.create.response
+// @Failure 400 {object} echo.HTTPError
+// @Failure 403 {object} echo.HTTPError
+// @Failure 409 {object} echo.HTTPError
+// @Failure 422 {object} echo.HTTPError
+// @Failure 500 {object} echo.HTTPError
+// @Security JWT
+// @Router /api/v1/installments/create [post]
+func (r *installmentRoutes) create(c echo.Context) error {
+	var input installmentCreateInput
+
+	if err := c.Bind(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid request body")
+		return err
+	}
+
+	if err := c.Validate(input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return err
+	}
+
+	id, err := r.installmentService.CreateInstallmentPlan(c.Request().Context(), service.InstallmentCreatePlanInput{
+		AccountId:    input.AccountId,
+		ProductId:    input.ProductId,
+		OrderId:      input.OrderId,
+		Amount:       input.Amount,
+		FirstPayment: input.FirstPayment,
+		Parts:        input.Parts,
+		Period:       input.Period,
+		UserId:       c.Get(userIdCtx).(int),
+	})
+	if err != nil {
+		if err == service.ErrInvalidInstallmentPlan || err == service.ErrAccountNotFound || err == service.ErrNotEnoughBalance {
+			newErrorResponse(c, http.StatusBadRequest, err.Error())
+			return err
+		}
+		if err == service.ErrAccountFrozen || err == service.ErrAccountClosed ||
+			err == service.ErrNotGroupMember || err == service.ErrGroupAccessDenied {
+			newErrorResponse(c, http.StatusForbidden, err.Error())
+			return err
+		}
+		if err == service.ErrMemberLimitExceeded {
+			newErrorResponse(c, http.StatusUnprocessableEntity, err.Error())
+			return err
+		}
+		if err == service.ErrOrderAlreadyReserved {
+			newErrorResponse(c, http.StatusConflict, err.Error())
+			return err
+		}
+		newErrorResponse(c, http.StatusInternalServerError, "internal server error")
+		return err
+	}
+
+	type response struct {
+		Id int `json:"id"`
+	}
+
+	return c.JSON(http.StatusCreated, response{
+		Id: id,
+	})
+}
+
+type installmentGetInput struct {
+	Id int `json:"id" validate:"required"`
+}
+
+// @Summary Get installment plan
+// @Description Get installment plan with its parts and the paid and outstanding amounts
+// @Tags installments
+// @Accept json
+// @Produce json
+// @Param input body v1.installmentGetInput true "input"
+// @Success 200 {object} v1.installmentRoutes.getById.response
+// @Failure 400 {object} echo.HTTPError
+// @Failure 500 {object} echo.HTTPError
+// @Security JWT
+// @Router /api/v1/installments/ [get]
+func (r *installmentRoutes) getById(c echo.Context) error {
+	var input installmentGetInput
+
+	if err := c.Bind(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid request body")
+		return err
+	}
+
+	if err := c.Validate(input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return err
+	}
+
+	plan, parts, err := r.installmentService.GetInstallmentPlan(c.Request().Context(), input.Id)
+	if err != nil {
+		if err == service.ErrInstallmentPlanNotFound {
+			newErrorResponse(c, http.StatusBadRequest, err.Error())
+			return err
+		}
+		newErrorResponse(c, http.StatusInternalServerError, "internal server error")
+		return err
+	}
+
+	type response struct {
+		Plan        entity.InstallmentPlan   `json:"plan"`
+		Parts       []entity.InstallmentPart `json:"parts"`
+		Paid        int                      `json:"paid"`
+		Outstanding int                      `json:"outstanding"`
+	}
+
+	return c.JSON(http.StatusOK, response{
+		Plan:        plan,
+		Parts:       parts,
+		Paid:        plan.PaidAmount,
+		Outstanding: plan.OutstandingAmount(),
+	})
+}
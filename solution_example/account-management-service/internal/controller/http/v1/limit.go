@@ -0,0 +1,154 @@
+package v1
+
+import (
+	"account-management-service/internal/entity"
+	"account-management-service/internal/service"
+	"github.com/labstack/echo/v4"
+	"net/http"
+)
+
+type limitRoutes struct {
+	limitService service.Limit
+}
+
+func newLimitRoutes(g *echo.Group, limitService service.Limit) {
+	r := &limitRoutes{
+		limitService: limitService,
+	}
+
+	g.POST("/create", r.create)
+	g.POST("/delete", r.delete)
+	g.GET("/", r.getAll)
+}
+
+type limitCreateInput struct {
+	Scope         string `json:"scope" validate:"required,oneof=global account_type account"`
+	AccountType   string `json:"account_type,omitempty" validate:"omitempty,oneof=personal business"`
+	AccountId     int    `json:"account_id,omitempty"`
+	OperationType string `json:"operation_type" validate:"required,oneof=withdraw transfer_from reservation"`
+	LimitType     string `json:"limit_type" validate:"required,oneof=max_amount max_total max_count"`
+	Value         int    `json:"value" validate:"required"`
+	WindowSeconds int    `json:"window_seconds,omitempty"`
+}
+
+// @Summary Create limit rule
+// @Description Create limit rule for all accounts, an account type or a single account. Admin only
+// @Tags limits
+// @Accept json
+// @Produce json
+// @Param input body v1.limitCreateInput true "input"
+// @Success 201 {object} v1.limitRoutes.create.response
+// @Failure 400 {object} echo.HTTPError
+// @Failure 403 {object} echo.HTTPError
+// @Failure 500 {object} echo.HTTPError
+// @Security JWT
+// @Router /api/v1/limits/create [post]
+func (r *limitRoutes) create(c echo.Context) error {
+	var input limitCreateInput
+
+	if err := c.Bind(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid request body")
+		return err
+	}
+
+	if err := c.Validate(input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return err
+	}
+
+	id, err := r.limitService.CreateLimitRule(c.Request().Context(), service.LimitCreateRuleInput{
+		Scope:         input.Scope,
+		AccountType:   input.AccountType,
+		AccountId:     input.AccountId,
+		OperationType: input.OperationType,
+		LimitType:     input.LimitType,
+		Value:         input.Value,
+		WindowSeconds: input.WindowSeconds,
+	})
+	if err != nil {
+		if err == service.ErrInvalidLimitRule {
+			newErrorResponse(c, http.StatusBadRequest, err.Error())
+			return err
+		}
+		newErrorResponse(c, http.StatusInternalServerError, "internal server error")
+		return err
+	}
+
+	type response struct {
+		Id int `json:"id"`
+	}
+
+	return c.JSON(http.StatusCreated, response{
+		Id: id,
+	})
+}
+
+type limitDeleteInput struct {
+	Id int `json:"id" validate:"required"`
+}
+
+// @Summary Delete limit rule
+// @Description Delete limit rule. Admin only
+// @Tags limits
+// @Accept json
+// @Produce json
+// @Param input body v1.limitDeleteInput true "input"
+// @Success 200
+// @Failure 400 {object} echo.HTTPError
+// @Failure 403 {object} echo.HTTPError
+// @Failure 500 {object} echo.HTTPError
+// @Security JWT
+// @Router /api/v1/limits/delete [post]
+func (r *limitRoutes) delete(c echo.Context) error {
+	var input limitDeleteInput
+
+	if err := c.Bind(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid request body")
+		return err
+	}
+
+	if err := c.Validate(input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return err
+	}
+
+	err := r.limitService.DeleteLimitRule(c.Request().Context(), input.Id)
+	if err != nil {
+		if err == service.ErrLimitRuleNotFound {
+			newErrorResponse(c, http.StatusBadRequest, err.Error())
+			return err
+		}
+		newErrorResponse(c, http.StatusInternalServerError, "internal server error")
+		return err
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": "success",
+	})
+}
+
+// @Summary Get limit rules
+// @Description Get all limit rules. Admin only
+// @Tags limits
+// @Accept json
+// @Produce json
+// @Success 200 {object} v1.limitRoutes.getAll.response
+// @Failure 403 {object} echo.HTTPError
+// @Failure 500 {object} echo.HTTPError
+// @Security JWT
+// @Router /api/v1/limits/ [get]
+func (r *limitRoutes) getAll(c echo.Context) error {
+	rules, err := r.limitService.GetAllLimitRules(c.Request().Context())
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, "internal server error")
+		return err
+	}
+
+	type response struct {
+		Rules []entity.LimitRule `json:"rules"`
+	}
+
+	return c.JSON(http.StatusOK, response{
+		Rules: rules,
+	})
+}
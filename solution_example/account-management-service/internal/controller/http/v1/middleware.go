@@ -2,10 +2,12 @@ package v1
 
 import (
 	"account-management-service/internal/service"
+	"account-management-service/pkg/httpserver"
 	"github.com/labstack/echo/v4"
 	log "github.com/sirupsen/logrus"
 	"net/http"
 	"strings"
+	"time"
 )
 
 const (
@@ -38,6 +40,48 @@ func (h *AuthMiddleware) UserIdentity(next echo.HandlerFunc) echo.HandlerFunc {
 	}
 }
 
+func (h *AuthMiddleware) RequireRole(roles ...string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			userId, ok := c.Get(userIdCtx).(int)
+			if !ok {
+				newErrorResponse(c, http.StatusUnauthorized, ErrCannotParseToken.Error())
+				return nil
+			}
+
+			role, err := h.authService.GetUserRole(c.Request().Context(), userId)
+			if err != nil {
+				log.Errorf("AuthMiddleware.RequireRole: h.authService.GetUserRole: %v", err)
+				newErrorResponse(c, http.StatusUnauthorized, ErrCannotParseToken.Error())
+				return err
+			}
+
+			for _, r := range roles {
+				if r == role {
+					return next(c)
+				}
+			}
+
+			newErrorResponse(c, http.StatusForbidden, ErrAccessDenied.Error())
+			return nil
+		}
+	}
+}
+
+// writeTimeout gives the route its own write timeout instead of the server one, without the timeout the server one is kept
+func writeTimeout(timeout time.Duration) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if timeout > 0 {
+				if err := httpserver.SetWriteDeadline(c.Request(), time.Now().Add(timeout)); err != nil {
+					log.Errorf("writeTimeout: httpserver.SetWriteDeadline: %v", err)
+				}
+			}
+			return next(c)
+		}
+	}
+}
+
 func bearerToken(r *http.Request) (string, bool) {
 	const prefix = "Bearer "
 
@@ -1,17 +1,23 @@
 package v1
 
 import (
+	"account-management-service/internal/entity"
 	"account-management-service/internal/service"
+	"account-management-service/internal/webapi"
+	"errors"
 	"github.com/labstack/echo/v4"
 	log "github.com/sirupsen/logrus"
+	"io"
 	"net/http"
+	"strings"
+	"time"
 )
 
 type operationRoutes struct {
 	service.Operation
 }
 
-func newOperationRoutes(g *echo.Group, operationService service.Operation) *operationRoutes {
+func newOperationRoutes(g *echo.Group, operationService service.Operation, exportTimeout echo.MiddlewareFunc) *operationRoutes {
 	r := &operationRoutes{
 		Operation: operationService,
 	}
@@ -19,6 +25,9 @@ func newOperationRoutes(g *echo.Group, operationService service.Operation) *oper
 	g.GET("/history", r.getHistory)
 	g.GET("/report-link", r.getReportLink)
 	g.GET("/report-file", r.getReportFile)
+	// exports are written for longer than the server write timeout allows
+	g.GET("/export", r.getExport, exportTimeout)
+	g.GET("/export-link", r.getExportLink, exportTimeout)
 
 	return r
 }
@@ -31,7 +40,7 @@ type getHistoryInput struct {
 }
 
 // @Summary Get history
-// @Description Get history of operations
+// @Description Get history of operations. For shared accounts every operation names the member who made it and members contains the spending of each member
 // @Tags operations
 // @Accept json
 // @Produce json
@@ -66,18 +75,89 @@ func (r *operationRoutes) getHistory(c echo.Context) error {
 		return err
 	}
 
+	members, err := r.Operation.MemberSpending(c.Request().Context(), input.AccountId)
+	if err != nil {
+		log.Debugf("error while getting member spending: %s", err.Error())
+		newErrorResponse(c, http.StatusInternalServerError, "internal server error")
+		return err
+	}
+
 	type response struct {
 		Operations []service.OperationHistoryOutput `json:"operations"`
+		Members    []entity.MemberSpending          `json:"members,omitempty"`
 	}
 
 	return c.JSON(http.StatusOK, response{
 		Operations: operations,
+		Members:    members,
 	})
 }
 
+// reportDateLayout is the layout of the report range dates
+const reportDateLayout = "2006-01-02"
+
 type getReportInput struct {
-	Month int `json:"month" validate:"required"`
-	Year  int `json:"year" validate:"required"`
+	Month       int    `json:"month,omitempty" validate:"required_without=From"`
+	Year        int    `json:"year,omitempty" validate:"required_without=From"`
+	From        string `json:"from,omitempty" validate:"required_without=Month,omitempty,datetime=2006-01-02"`
+	To          string `json:"to,omitempty" validate:"required_with=From,omitempty,datetime=2006-01-02"`
+	Version     int    `json:"-" query:"version" validate:"omitempty,oneof=1 2"`
+	Mode        string `json:"-" query:"mode" validate:"omitempty,oneof=products accounts"`
+	Granularity string `json:"-" query:"granularity" validate:"omitempty,oneof=day week month quarter"`
+	ProductIds  []int  `json:"-" query:"product_id"`
+	AccountIds  []int  `json:"-" query:"account_id"`
+	Format      string `json:"-" query:"format" validate:"omitempty,oneof=csv xlsx json parquet"`
+	Delimiter   string `json:"-" query:"delimiter" validate:"omitempty,oneof=comma semicolon tab"`
+	BOM         bool   `json:"-" query:"bom"`
+}
+
+func (i getReportInput) params() entity.ReportParams {
+	return entity.ReportParams{
+		Month:        i.Month,
+		Year:         i.Year,
+		From:         parseReportDate(i.From),
+		To:           parseReportDate(i.To),
+		Version:      i.Version,
+		Mode:         i.Mode,
+		Granularity:  i.Granularity,
+		ProductIds:   i.ProductIds,
+		AccountIds:   i.AccountIds,
+		Format:       i.Format,
+		CSVDelimiter: i.Delimiter,
+		CSVBOM:       i.BOM,
+	}
+}
+
+// reportFormats are the report formats by the media types of the Accept header
+var reportFormats = map[string]string{
+	"text/csv":         entity.ReportFormatCSV,
+	"application/json": entity.ReportFormatJSON,
+	"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet": entity.ReportFormatXLSX,
+	"application/vnd.apache.parquet":                                    entity.ReportFormatParquet,
+	"application/x-parquet":                                             entity.ReportFormatParquet,
+}
+
+// acceptedReportFormat returns the format of the first media type of the Accept header that is a report format,
+// the empty format stands for the default csv
+func acceptedReportFormat(accept string) string {
+	for _, mediaType := range strings.Split(accept, ",") {
+		mediaType, _, _ = strings.Cut(mediaType, ";")
+		if format, ok := reportFormats[strings.ToLower(strings.TrimSpace(mediaType))]; ok {
+			return format
+		}
+	}
+	return ""
+}
+
+// parseReportDate parses the date checked by the datetime validation, the empty date is zero
+func parseReportDate(date string) time.Time {
+	t, _ := time.Parse(reportDateLayout, date)
+	return t
+}
+
+// isReportParamsError reports whether the report failed because of the requested params
+func isReportParamsError(err error) bool {
+	return err == service.ErrUnknownReportVersion || err == service.ErrUnknownReportMode || errors.Is(err, service.ErrInvalidReportParams)
 }
 
 // @Summary Get report link
@@ -86,6 +166,14 @@ type getReportInput struct {
 // @Accept json
 // @Produce json
 // @Param input body getReportInput true "input"
+// @Param version query int false "report version: 1 - legacy headerless report, 2 - current (default)"
+// @Param mode query string false "report mode: products (default) or accounts - revenue of every product by accounts"
+// @Param product_id query []int false "limit the accounts report to the products" collectionFormat(multi)
+// @Param account_id query []int false "limit the accounts report to the accounts" collectionFormat(multi)
+// @Param granularity query string false "make the products report the time series by day, week, month or quarter"
+// @Param format query string false "report format: csv (default), xlsx, json or parquet"
+// @Param delimiter query string false "csv delimiter: comma (default), semicolon or tab"
+// @Param bom query bool false "prepend the byte order mark to the csv report"
 // @Success 200 {object} v1.operationRoutes.getReportLink.response
 // @Failure 400 {object} echo.HTTPError
 // @Failure 500 {object} echo.HTTPError
@@ -104,9 +192,13 @@ func (r *operationRoutes) getReportLink(c echo.Context) error {
 		return err
 	}
 
-	link, err := r.Operation.MakeReportLink(c.Request().Context(), input.Month, input.Year)
+	link, err := r.Operation.MakeReportLink(c.Request().Context(), input.params(), c.Get(userIdCtx).(int))
 	if err != nil {
 		log.Debugf("error while getting report link: %s", err.Error())
+		if isReportParamsError(err) {
+			newErrorResponse(c, http.StatusBadRequest, err.Error())
+			return err
+		}
 		newErrorResponse(c, http.StatusInternalServerError, "internal server error")
 		return err
 	}
@@ -121,11 +213,19 @@ func (r *operationRoutes) getReportLink(c echo.Context) error {
 }
 
 // @Summary Get report file
-// @Description Get report file
+// @Description Get report file, the report is streamed as it is generated. Without the format param the format is chosen by the Accept header
 // @Tags operations
 // @Accept json
-// @Produce text/csv
+// @Produce text/csv,application/json,application/vnd.openxmlformats-officedocument.spreadsheetml.sheet,application/vnd.apache.parquet
 // @Param input body getReportInput true "input"
+// @Param version query int false "report version: 1 - legacy headerless report, 2 - current (default)"
+// @Param mode query string false "report mode: products (default) or accounts - revenue of every product by accounts"
+// @Param product_id query []int false "limit the accounts report to the products" collectionFormat(multi)
+// @Param account_id query []int false "limit the accounts report to the accounts" collectionFormat(multi)
+// @Param granularity query string false "make the products report the time series by day, week, month or quarter"
+// @Param format query string false "report format: csv (default), xlsx, json or parquet"
+// @Param delimiter query string false "csv delimiter: comma (default), semicolon or tab"
+// @Param bom query bool false "prepend the byte order mark to the csv report"
 // @Success 200
 // @Failure 400 {object} echo.HTTPError
 // @Failure 500 {object} echo.HTTPError
@@ -144,12 +244,145 @@ func (r *operationRoutes) getReportFile(c echo.Context) error {
 		return err
 	}
 
-	file, err := r.Operation.MakeReportFile(c.Request().Context(), input.Month, input.Year)
+	params := input.params()
+	if params.Format == "" {
+		params.Format = acceptedReportFormat(c.Request().Header.Get(echo.HeaderAccept))
+	}
+
+	// the content type depends on the extension only, the params are not normalized yet
+	return streamFile(c, webapi.ContentType(params.Filename()), func(w io.Writer) error {
+		return r.Operation.WriteReport(c.Request().Context(), params, w)
+	})
+}
+
+// streamFile writes the file to the response as it is generated, the response is sent with chunked encoding.
+// Once the file has started the status cannot be changed, so the error is sent only if nothing is written yet
+func streamFile(c echo.Context, contentType string, write func(w io.Writer) error) error {
+	c.Response().Header().Set(echo.HeaderContentType, contentType)
+
+	err := write(c.Response())
+	if err != nil {
+		log.Debugf("error while streaming file: %s", err.Error())
+		if c.Response().Committed {
+			return err
+		}
+		c.Response().Header().Del(echo.HeaderContentType)
+		if isReportParamsError(err) {
+			newErrorResponse(c, http.StatusBadRequest, err.Error())
+			return err
+		}
+		newErrorResponse(c, http.StatusInternalServerError, "internal server error")
+		return err
+	}
+
+	return nil
+}
+
+type getExportInput struct {
+	From           string   `json:"from" validate:"required,datetime=2006-01-02"`
+	To             string   `json:"to" validate:"required,datetime=2006-01-02"`
+	AccountId      int      `json:"account_id,omitempty"`
+	OperationTypes []string `json:"-" query:"operation_type"`
+	Format         string   `json:"-" query:"format" validate:"omitempty,oneof=csv xlsx json parquet"`
+	Delimiter      string   `json:"-" query:"delimiter" validate:"omitempty,oneof=comma semicolon tab"`
+	BOM            bool     `json:"-" query:"bom"`
+}
+
+func (i getExportInput) params() entity.OperationExportParams {
+	return entity.OperationExportParams{
+		From:           parseReportDate(i.From),
+		To:             parseReportDate(i.To),
+		AccountId:      i.AccountId,
+		OperationTypes: i.OperationTypes,
+		Format:         i.Format,
+		CSVDelimiter:   i.Delimiter,
+		CSVBOM:         i.BOM,
+	}
+}
+
+// @Summary Export operations
+// @Description Export operations created in the [from, to) range. The operations are streamed as they are read from the database, without the format param the format is chosen by the Accept header
+// @Tags operations
+// @Accept json
+// @Produce text/csv,application/json,application/vnd.openxmlformats-officedocument.spreadsheetml.sheet,application/vnd.apache.parquet
+// @Param input body getExportInput true "input"
+// @Param operation_type query []string false "export only the operations of the types" collectionFormat(multi)
+// @Param format query string false "export format: csv (default), xlsx, json or parquet"
+// @Param delimiter query string false "csv delimiter: comma (default), semicolon or tab"
+// @Param bom query bool false "prepend the byte order mark to the csv export"
+// @Success 200
+// @Failure 400 {object} echo.HTTPError
+// @Failure 500 {object} echo.HTTPError
+// @Security JWT
+// @Router /api/v1/operations/export [get]
+func (r *operationRoutes) getExport(c echo.Context) error {
+	var input getExportInput
+
+	if err := c.Bind(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid request body")
+		return err
+	}
+
+	if err := c.Validate(input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return err
+	}
+
+	params := input.params()
+	if params.Format == "" {
+		params.Format = acceptedReportFormat(c.Request().Header.Get(echo.HeaderAccept))
+	}
+
+	// the request context is cancelled when the client disconnects, which stops the export
+	return streamFile(c, webapi.ContentType(params.Filename()), func(w io.Writer) error {
+		return r.Operation.WriteOperationsExport(c.Request().Context(), params, w)
+	})
+}
+
+// @Summary Get operations export link
+// @Description Export operations created in the [from, to) range to the report storage and get the link to the file
+// @Tags operations
+// @Accept json
+// @Produce json
+// @Param input body getExportInput true "input"
+// @Param operation_type query []string false "export only the operations of the types" collectionFormat(multi)
+// @Param format query string false "export format: csv (default), xlsx, json or parquet"
+// @Param delimiter query string false "csv delimiter: comma (default), semicolon or tab"
+// @Param bom query bool false "prepend the byte order mark to the csv export"
+// @Success 200 {object} v1.operationRoutes.getExportLink.response
+// @Failure 400 {object} echo.HTTPError
+// @Failure 500 {object} echo.HTTPError
+// @Security JWT
+// @Router /api/v1/operations/export-link [get]
+func (r *operationRoutes) getExportLink(c echo.Context) error {
+	var input getExportInput
+
+	if err := c.Bind(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid request body")
+		return err
+	}
+
+	if err := c.Validate(input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return err
+	}
+
+	link, err := r.Operation.MakeOperationsExportLink(c.Request().Context(), input.params(), c.Get(userIdCtx).(int))
 	if err != nil {
-		log.Debugf("error while getting report file: %s", err.Error())
+		log.Debugf("error while getting export link: %s", err.Error())
+		if isReportParamsError(err) {
+			newErrorResponse(c, http.StatusBadRequest, err.Error())
+			return err
+		}
 		newErrorResponse(c, http.StatusInternalServerError, "internal server error")
 		return err
 	}
 
-	return c.Blob(http.StatusOK, "text/csv", file)
+	type response struct {
+		Link string `json:"link"`
+	}
+
+	return c.JSON(http.StatusOK, response{
+		Link: link,
+	})
 }
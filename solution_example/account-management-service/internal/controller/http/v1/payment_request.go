@@ -0,0 +1,243 @@
+package v1
+
+import (
+	"account-management-service/internal/entity"
+	"account-management-service/internal/service"
+	"context"
+	"errors"
+	"github.com/labstack/echo/v4"
+	"net/http"
+)
+
+type paymentRequestRoutes struct {
+	paymentRequestService service.PaymentRequest
+}
+
+func newPaymentRequestRoutes(g *echo.Group, paymentRequestService service.PaymentRequest) {
+	r := &paymentRequestRoutes{
+		paymentRequestService: paymentRequestService,
+	}
+
+	g.POST("/create", r.create)
+	g.POST("/accept", r.accept)
+	g.POST("/decline", r.decline)
+	g.GET("/", r.getById)
+	g.GET("/list", r.getByAccountId)
+}
+
+type paymentRequestCreateInput struct {
+	RequesterAccountId int    `json:"requester_account_id" validate:"required"`
+	PayerAccountId     int    `json:"payer_account_id" validate:"required"`
+	Amount             int    `json:"amount" validate:"required,gt=0"`
+	Description        string `json:"description,omitempty" validate:"max=255"`
+}
+
+// @Summary Create payment request
+// @Description Request money from the payer account to the requester account
+// @Tags payment-requests
+// @Accept json
+// @Produce json
+// @Param input body v1.paymentRequestCreateInput true "input"
+// @Success 201 {object} v1.paymentRequestRoutes.create.response
+// @Failure 400 {object} echo.HTTPError
+// @Failure 500 {object} echo.HTTPError
+// @Security JWT
+// @Router /api/v1/payment-requests/create [post]
+func (r *paymentRequestRoutes) create(c echo.Context) error {
+	var input paymentRequestCreateInput
+
+	if err := c.Bind(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid request body")
+		return err
+	}
+
+	if err := c.Validate(input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return err
+	}
+
+	id, err := r.paymentRequestService.CreatePaymentRequest(c.Request().Context(), service.PaymentRequestCreateInput{
+		RequesterAccountId: input.RequesterAccountId,
+		PayerAccountId:     input.PayerAccountId,
+		Amount:             input.Amount,
+		Description:        input.Description,
+	})
+	if err != nil {
+		if err == service.ErrCannotCreatePaymentRequest {
+			newErrorResponse(c, http.StatusBadRequest, err.Error())
+			return err
+		}
+		newErrorResponse(c, http.StatusInternalServerError, "internal server error")
+		return err
+	}
+
+	type response struct {
+		Id int `json:"id"`
+	}
+
+	return c.JSON(http.StatusCreated, response{
+		Id: id,
+	})
+}
+
+type paymentRequestIdInput struct {
+	Id int `json:"id" validate:"required"`
+}
+
+// @Summary Accept payment request
+// @Description Pay the request, the amount is transferred from the payer to the requester
+// @Tags payment-requests
+// @Accept json
+// @Produce json
+// @Param input body v1.paymentRequestIdInput true "input"
+// @Success 200
+// @Failure 400 {object} echo.HTTPError
+// @Failure 403 {object} echo.HTTPError
+// @Failure 409 {object} echo.HTTPError
+// @Failure 422 {object} echo.HTTPError
+// @Failure 500 {object} echo.HTTPError
+// @Security JWT
+// @Router /api/v1/payment-requests/accept [post]
+func (r *paymentRequestRoutes) accept(c echo.Context) error {
+	return r.resolve(c, func(ctx context.Context, id int) error {
+		return r.paymentRequestService.AcceptPaymentRequest(ctx, id, c.Get(userIdCtx).(int))
+	})
+}
+
+// @Summary Decline payment request
+// @Description Decline the request without paying it
+// @Tags payment-requests
+// @Accept json
+// @Produce json
+// @Param input body v1.paymentRequestIdInput true "input"
+// @Success 200
+// @Failure 400 {object} echo.HTTPError
+// @Failure 409 {object} echo.HTTPError
+// @Failure 500 {object} echo.HTTPError
+// @Security JWT
+// @Router /api/v1/payment-requests/decline [post]
+func (r *paymentRequestRoutes) decline(c echo.Context) error {
+	return r.resolve(c, r.paymentRequestService.DeclinePaymentRequest)
+}
+
+func (r *paymentRequestRoutes) resolve(c echo.Context, resolve func(ctx context.Context, id int) error) error {
+	var input paymentRequestIdInput
+
+	if err := c.Bind(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid request body")
+		return err
+	}
+
+	if err := c.Validate(input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return err
+	}
+
+	err := resolve(c.Request().Context(), input.Id)
+	if err != nil {
+		if err == service.ErrPaymentRequestNotFound || err == service.ErrNotEnoughBalance {
+			newErrorResponse(c, http.StatusBadRequest, err.Error())
+			return err
+		}
+		if err == service.ErrPaymentRequestNotPending || err == service.ErrPaymentRequestExpired {
+			newErrorResponse(c, http.StatusConflict, err.Error())
+			return err
+		}
+		if err == service.ErrAccountFrozen || err == service.ErrAccountClosed || errors.Is(err, service.ErrVerificationRequired) ||
+			err == service.ErrNotGroupMember || err == service.ErrGroupAccessDenied {
+			newErrorResponse(c, http.StatusForbidden, err.Error())
+			return err
+		}
+		if err == service.ErrMemberLimitExceeded || errors.Is(err, service.ErrLimitExceeded) {
+			newErrorResponse(c, http.StatusUnprocessableEntity, err.Error())
+			return err
+		}
+		newErrorResponse(c, http.StatusInternalServerError, "internal server error")
+		return err
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": "success",
+	})
+}
+
+// @Summary Get payment request
+// @Description Get payment request by id
+// @Tags payment-requests
+// @Accept json
+// @Produce json
+// @Param input body v1.paymentRequestIdInput true "input"
+// @Success 200 {object} entity.PaymentRequest
+// @Failure 400 {object} echo.HTTPError
+// @Failure 500 {object} echo.HTTPError
+// @Security JWT
+// @Router /api/v1/payment-requests/ [get]
+func (r *paymentRequestRoutes) getById(c echo.Context) error {
+	var input paymentRequestIdInput
+
+	if err := c.Bind(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid request body")
+		return err
+	}
+
+	if err := c.Validate(input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return err
+	}
+
+	request, err := r.paymentRequestService.GetPaymentRequestById(c.Request().Context(), input.Id)
+	if err != nil {
+		if err == service.ErrPaymentRequestNotFound {
+			newErrorResponse(c, http.StatusBadRequest, err.Error())
+			return err
+		}
+		newErrorResponse(c, http.StatusInternalServerError, "internal server error")
+		return err
+	}
+
+	return c.JSON(http.StatusOK, request)
+}
+
+type paymentRequestListInput struct {
+	AccountId int    `json:"account_id" validate:"required"`
+	Status    string `json:"status,omitempty" validate:"omitempty,oneof=pending paid declined expired"`
+}
+
+// @Summary Get account payment requests
+// @Description Get incoming and outgoing payment requests of the account, optionally filtered by status
+// @Tags payment-requests
+// @Accept json
+// @Produce json
+// @Param input body v1.paymentRequestListInput true "input"
+// @Success 200 {object} v1.paymentRequestRoutes.getByAccountId.response
+// @Failure 400 {object} echo.HTTPError
+// @Failure 500 {object} echo.HTTPError
+// @Security JWT
+// @Router /api/v1/payment-requests/list [get]
+func (r *paymentRequestRoutes) getByAccountId(c echo.Context) error {
+	var input paymentRequestListInput
+
+	if err := c.Bind(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid request body")
+		return err
+	}
+
+	if err := c.Validate(input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return err
+	}
+
+	requests, err := r.paymentRequestService.GetAccountPaymentRequests(c.Request().Context(), input.AccountId, input.Status)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, "internal server error")
+		return err
+	}
+
+	type response struct {
+		Requests []entity.PaymentRequest `json:"requests"`
+	}
+
+	return c.JSON(http.StatusOK, response{
+		Requests: requests,
+	})
+}
@@ -0,0 +1,158 @@
+package v1
+
+import (
+	"account-management-service/internal/entity"
+	"account-management-service/internal/service"
+	"account-management-service/internal/webapi"
+	"fmt"
+	"github.com/labstack/echo/v4"
+	"net/http"
+)
+
+type reportArchiveRoutes struct {
+	reportArchiveService service.ReportArchive
+}
+
+func newReportArchiveRoutes(g *echo.Group, reportArchiveService service.ReportArchive) {
+	r := &reportArchiveRoutes{
+		reportArchiveService: reportArchiveService,
+	}
+
+	g.GET("/", r.getReports)
+	g.GET("/download", r.download)
+	g.POST("/delete", r.delete)
+}
+
+type reportArchiveGetReportsInput struct {
+	Kind      string `json:"kind,omitempty" validate:"omitempty,oneof=report export unknown"`
+	CreatedBy int    `json:"created_by,omitempty"`
+	Offset    int    `json:"offset,omitempty" validate:"omitempty,min=0"`
+	Limit     int    `json:"limit,omitempty" validate:"omitempty,min=1"`
+}
+
+// @Summary Get archived reports
+// @Description Get the reports and exports in the report storage, the latest first. The files uploaded before the archive existed have the unknown kind and no metadata. Admins only
+// @Tags reports
+// @Accept json
+// @Produce json
+// @Param input body v1.reportArchiveGetReportsInput false "input"
+// @Success 200 {object} v1.reportArchiveRoutes.getReports.response
+// @Failure 400 {object} echo.HTTPError
+// @Failure 403 {object} echo.HTTPError
+// @Failure 500 {object} echo.HTTPError
+// @Security JWT
+// @Router /api/v1/report-archive/ [get]
+func (r *reportArchiveRoutes) getReports(c echo.Context) error {
+	var input reportArchiveGetReportsInput
+
+	if err := c.Bind(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid request body")
+		return err
+	}
+
+	if err := c.Validate(input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return err
+	}
+
+	reports, err := r.reportArchiveService.GetArchivedReports(c.Request().Context(), service.ReportArchiveListInput{
+		Kind:      input.Kind,
+		CreatedBy: input.CreatedBy,
+		Offset:    input.Offset,
+		Limit:     input.Limit,
+	})
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, "internal server error")
+		return err
+	}
+
+	type response struct {
+		Reports []entity.ArchivedReport `json:"reports"`
+	}
+
+	return c.JSON(http.StatusOK, response{
+		Reports: reports,
+	})
+}
+
+type reportArchiveIdInput struct {
+	Id int `json:"id" query:"id" validate:"required"`
+}
+
+// @Summary Download archived report
+// @Description Download the file of the archived report. Admins only
+// @Tags reports
+// @Produce octet-stream
+// @Param id query int true "archived report id"
+// @Success 200
+// @Failure 400 {object} echo.HTTPError
+// @Failure 403 {object} echo.HTTPError
+// @Failure 500 {object} echo.HTTPError
+// @Security JWT
+// @Router /api/v1/report-archive/download [get]
+func (r *reportArchiveRoutes) download(c echo.Context) error {
+	var input reportArchiveIdInput
+
+	if err := c.Bind(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid request")
+		return err
+	}
+
+	if err := c.Validate(input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return err
+	}
+
+	output, err := r.reportArchiveService.DownloadArchivedReport(c.Request().Context(), input.Id)
+	if err != nil {
+		if err == service.ErrArchivedReportNotFound {
+			newErrorResponse(c, http.StatusBadRequest, err.Error())
+			return err
+		}
+		newErrorResponse(c, http.StatusInternalServerError, "internal server error")
+		return err
+	}
+
+	c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", output.Filename))
+	return c.Blob(http.StatusOK, webapi.ContentType(output.Filename), output.Data)
+}
+
+// @Summary Delete archived report
+// @Description Delete the report from the report storage and the archive. Admins only
+// @Tags reports
+// @Accept json
+// @Produce json
+// @Param input body v1.reportArchiveIdInput true "input"
+// @Success 200
+// @Failure 400 {object} echo.HTTPError
+// @Failure 403 {object} echo.HTTPError
+// @Failure 500 {object} echo.HTTPError
+// @Security JWT
+// @Router /api/v1/report-archive/delete [post]
+func (r *reportArchiveRoutes) delete(c echo.Context) error {
+	var input reportArchiveIdInput
+
+	if err := c.Bind(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid request body")
+		return err
+	}
+
+	if err := c.Validate(input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return err
+	}
+
+	err := r.reportArchiveService.DeleteArchivedReport(c.Request().Context(), input.Id)
+	if err != nil {
+		if err == service.ErrArchivedReportNotFound {
+			newErrorResponse(c, http.StatusBadRequest, err.Error())
+			return err
+		}
+		newErrorResponse(c, http.StatusInternalServerError, "internal server error")
+		return err
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": "success",
+	})
+}
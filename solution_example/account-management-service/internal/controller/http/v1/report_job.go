@@ -0,0 +1,140 @@
+package v1
+
+import (
+	"account-management-service/internal/entity"
+	"account-management-service/internal/service"
+	"github.com/labstack/echo/v4"
+	"net/http"
+)
+
+type reportJobRoutes struct {
+	reportJobService service.ReportJob
+}
+
+func newReportJobRoutes(g *echo.Group, reportJobService service.ReportJob) {
+	r := &reportJobRoutes{
+		reportJobService: reportJobService,
+	}
+
+	g.POST("/create", r.create)
+	g.GET("/", r.getJob)
+}
+
+type reportJobCreateInput struct {
+	Month       int    `json:"month,omitempty" validate:"required_without=From,omitempty,min=1,max=12"`
+	Year        int    `json:"year,omitempty" validate:"required_without=From"`
+	From        string `json:"from,omitempty" validate:"required_without=Month,omitempty,datetime=2006-01-02"`
+	To          string `json:"to,omitempty" validate:"required_with=From,omitempty,datetime=2006-01-02"`
+	Version     int    `json:"version,omitempty" validate:"omitempty,oneof=1 2"`
+	Mode        string `json:"mode,omitempty" validate:"omitempty,oneof=products accounts"`
+	Granularity string `json:"granularity,omitempty" validate:"omitempty,oneof=day week month quarter"`
+	ProductIds  []int  `json:"product_ids,omitempty"`
+	AccountIds  []int  `json:"account_ids,omitempty"`
+	Format      string `json:"format,omitempty" validate:"omitempty,oneof=csv xlsx json parquet"`
+	Delimiter   string `json:"delimiter,omitempty" validate:"omitempty,oneof=comma semicolon tab"`
+	BOM         bool   `json:"bom,omitempty"`
+}
+
+// @Summary Create report job
+// @Description Queue generation of the revenue report. If the same report is already being generated, the id of that job is returned
+// @Tags reports
+// @Accept json
+// @Produce json
+// @Param input body v1.reportJobCreateInput true "input"
+// @Success 202 {object} v1.reportJobRoutes.create.response
+// @Failure 400 {object} echo.HTTPError
+// @Failure 500 {object} echo.HTTPError
+// @Security JWT
+// @Router /api/v1/report-jobs/create [post]
+func (r *reportJobRoutes) create(c echo.Context) error {
+	var input reportJobCreateInput
+
+	if err := c.Bind(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid request body")
+		return err
+	}
+
+	if err := c.Validate(input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return err
+	}
+
+	id, err := r.reportJobService.CreateReportJob(c.Request().Context(), service.ReportJobCreateInput{
+		Month:        input.Month,
+		Year:         input.Year,
+		From:         parseReportDate(input.From),
+		To:           parseReportDate(input.To),
+		Version:      input.Version,
+		Mode:         input.Mode,
+		Granularity:  input.Granularity,
+		ProductIds:   input.ProductIds,
+		AccountIds:   input.AccountIds,
+		Format:       input.Format,
+		CSVDelimiter: input.Delimiter,
+		CSVBOM:       input.BOM,
+		CreatedBy:    c.Get(userIdCtx).(int),
+	})
+	if err != nil {
+		if isReportParamsError(err) {
+			newErrorResponse(c, http.StatusBadRequest, err.Error())
+			return err
+		}
+		newErrorResponse(c, http.StatusInternalServerError, "internal server error")
+		return err
+	}
+
+	type response struct {
+		Id int `json:"id"`
+	}
+
+	return c.JSON(http.StatusAccepted, response{
+		Id: id,
+	})
+}
+
+type reportJobGetInput struct {
+	Id int `json:"id" validate:"required"`
+}
+
+// @Summary Get report job
+// @Description Get status and progress of the report job, the finished job contains the link to the report
+// @Tags reports
+// @Accept json
+// @Produce json
+// @Param input body v1.reportJobGetInput true "input"
+// @Success 200 {object} v1.reportJobRoutes.getJob.response
+// @Failure 400 {object} echo.HTTPError
+// @Failure 500 {object} echo.HTTPError
+// @Security JWT
+// @Router /api/v1/report-jobs/ [get]
+func (r *reportJobRoutes) getJob(c echo.Context) error {
+	var input reportJobGetInput
+
+	if err := c.Bind(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid request body")
+		return err
+	}
+
+	if err := c.Validate(input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return err
+	}
+
+	job, err := r.reportJobService.GetReportJob(c.Request().Context(), input.Id)
+	if err != nil {
+		if err == service.ErrReportJobNotFound {
+			newErrorResponse(c, http.StatusBadRequest, err.Error())
+			return err
+		}
+		newErrorResponse(c, http.StatusInternalServerError, "internal server error")
+		return err
+	}
+
+	type response struct {
+		Job entity.ReportJob `json:"job"`
+	}
+
+	return c.JSON(http.StatusOK, response{
+		Job: job,
+	})
+}
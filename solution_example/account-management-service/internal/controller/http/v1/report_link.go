@@ -0,0 +1,136 @@
+package v1
+
+import (
+	"account-management-service/internal/entity"
+	"account-management-service/internal/service"
+	"account-management-service/internal/webapi"
+	"fmt"
+	"github.com/labstack/echo/v4"
+	"net/http"
+)
+
+type reportLinkRoutes struct {
+	reportLinkService service.ReportLink
+}
+
+// newReportDownloadRoutes registers the route serving signed report links, it is available without authorization
+func newReportDownloadRoutes(g *echo.Group, reportLinkService service.ReportLink) {
+	r := &reportLinkRoutes{
+		reportLinkService: reportLinkService,
+	}
+
+	g.GET("/:id", r.download)
+}
+
+func newReportLinkRoutes(g *echo.Group, reportLinkService service.ReportLink) {
+	r := &reportLinkRoutes{
+		reportLinkService: reportLinkService,
+	}
+
+	g.GET("/accesses", r.getAccesses)
+}
+
+type reportDownloadInput struct {
+	Id        int    `param:"id" validate:"required"`
+	Expires   int64  `query:"expires" validate:"required"`
+	Signature string `query:"signature" validate:"required"`
+}
+
+// @Summary Download report
+// @Description Download the report by the signed link returned by /api/v1/operations/report-link. The link works until it expires, one-time links work only once. No authorization required
+// @Tags reports
+// @Produce text/csv
+// @Param id path int true "link id"
+// @Param expires query int true "expiration time"
+// @Param signature query string true "signature"
+// @Success 200
+// @Failure 400 {object} echo.HTTPError
+// @Failure 403 {object} echo.HTTPError
+// @Failure 404 {object} echo.HTTPError
+// @Failure 410 {object} echo.HTTPError
+// @Failure 500 {object} echo.HTTPError
+// @Router /reports/download/{id} [get]
+func (r *reportLinkRoutes) download(c echo.Context) error {
+	var input reportDownloadInput
+
+	if err := c.Bind(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid request")
+		return err
+	}
+
+	if err := c.Validate(input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return err
+	}
+
+	output, err := r.reportLinkService.Download(c.Request().Context(), service.ReportDownloadInput{
+		LinkId:    input.Id,
+		Expires:   input.Expires,
+		Signature: input.Signature,
+		IP:        c.RealIP(),
+		UserAgent: c.Request().UserAgent(),
+	})
+	if err != nil {
+		if err == service.ErrInvalidReportLink {
+			newErrorResponse(c, http.StatusForbidden, err.Error())
+			return err
+		}
+		if err == service.ErrReportLinkNotFound {
+			newErrorResponse(c, http.StatusNotFound, err.Error())
+			return err
+		}
+		if err == service.ErrReportLinkExpired || err == service.ErrReportLinkUsed {
+			newErrorResponse(c, http.StatusGone, err.Error())
+			return err
+		}
+		newErrorResponse(c, http.StatusInternalServerError, "internal server error")
+		return err
+	}
+
+	c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", output.Filename))
+	return c.Blob(http.StatusOK, webapi.ContentType(output.Filename), output.Data)
+}
+
+type reportLinkGetAccessesInput struct {
+	LinkId int `json:"link_id" validate:"required"`
+}
+
+// @Summary Get report link accesses
+// @Description Get all attempts to download the report by the link, including rejected ones. Admins only
+// @Tags reports
+// @Accept json
+// @Produce json
+// @Param input body v1.reportLinkGetAccessesInput true "input"
+// @Success 200 {object} v1.reportLinkRoutes.getAccesses.response
+// @Failure 400 {object} echo.HTTPError
+// @Failure 403 {object} echo.HTTPError
+// @Failure 500 {object} echo.HTTPError
+// @Security JWT
+// @Router /api/v1/report-links/accesses [get]
+func (r *reportLinkRoutes) getAccesses(c echo.Context) error {
+	var input reportLinkGetAccessesInput
+
+	if err := c.Bind(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid request body")
+		return err
+	}
+
+	if err := c.Validate(input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return err
+	}
+
+	accesses, err := r.reportLinkService.GetLinkAccesses(c.Request().Context(), input.LinkId)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, "internal server error")
+		return err
+	}
+
+	type response struct {
+		Accesses []entity.ReportLinkAccess `json:"accesses"`
+	}
+
+	return c.JSON(http.StatusOK, response{
+		Accesses: accesses,
+	})
+}
@@ -0,0 +1,90 @@
+package v1
+
+import (
+	"account-management-service/internal/entity"
+	"account-management-service/internal/service"
+	"github.com/labstack/echo/v4"
+	"net/http"
+)
+
+type reportScheduleRoutes struct {
+	reportScheduleService service.ReportSchedule
+}
+
+func newReportScheduleRoutes(g *echo.Group, reportScheduleService service.ReportSchedule) {
+	r := &reportScheduleRoutes{
+		reportScheduleService: reportScheduleService,
+	}
+
+	g.GET("/", r.getSchedules)
+	g.GET("/runs", r.getRuns)
+}
+
+// @Summary Get report schedules
+// @Description Get the configured report schedules with the next time they are run
+// @Tags reports
+// @Produce json
+// @Success 200 {object} v1.reportScheduleRoutes.getSchedules.response
+// @Failure 500 {object} echo.HTTPError
+// @Security JWT
+// @Router /api/v1/report-schedules/ [get]
+func (r *reportScheduleRoutes) getSchedules(c echo.Context) error {
+	schedules, err := r.reportScheduleService.GetReportSchedules(c.Request().Context())
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, "internal server error")
+		return err
+	}
+
+	type response struct {
+		Schedules []service.ReportScheduleOutput `json:"schedules"`
+	}
+
+	return c.JSON(http.StatusOK, response{
+		Schedules: schedules,
+	})
+}
+
+type reportScheduleRunsInput struct {
+	Schedule string `json:"schedule,omitempty"`
+	Offset   int    `json:"offset,omitempty" validate:"omitempty,min=0"`
+	Limit    int    `json:"limit,omitempty" validate:"omitempty,min=1"`
+}
+
+// @Summary Get report schedule runs
+// @Description Get the history of the scheduled runs, the latest first. Without the schedule the runs of all schedules are returned
+// @Tags reports
+// @Accept json
+// @Produce json
+// @Param input body v1.reportScheduleRunsInput false "input"
+// @Success 200 {object} v1.reportScheduleRoutes.getRuns.response
+// @Failure 400 {object} echo.HTTPError
+// @Failure 500 {object} echo.HTTPError
+// @Security JWT
+// @Router /api/v1/report-schedules/runs [get]
+func (r *reportScheduleRoutes) getRuns(c echo.Context) error {
+	var input reportScheduleRunsInput
+
+	if err := c.Bind(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid request body")
+		return err
+	}
+
+	if err := c.Validate(input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return err
+	}
+
+	runs, err := r.reportScheduleService.GetReportScheduleRuns(c.Request().Context(), input.Schedule, input.Offset, input.Limit)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, "internal server error")
+		return err
+	}
+
+	type response struct {
+		Runs []entity.ReportScheduleRun `json:"runs"`
+	}
+
+	return c.JSON(http.StatusOK, response{
+		Runs: runs,
+	})
+}
@@ -1,30 +1,35 @@
 package v1
 
 import (
+	"account-management-service/internal/entity"
 	"account-management-service/internal/service"
+	"errors"
 	"github.com/labstack/echo/v4"
 	"net/http"
 )
 
 type reservationRoutes struct {
 	reservationService service.Reservation
+	approvalService    service.Approval
 }
 
-func newReservationRoutes(g *echo.Group, reservationService service.Reservation) {
+func newReservationRoutes(g *echo.Group, reservationService service.Reservation, approvalService service.Approval) {
 	r := &reservationRoutes{
 		reservationService: reservationService,
+		approvalService:    approvalService,
 	}
 
 	g.POST("/create", r.create)
 	g.POST("/revenue", r.revenue)
 	g.POST("/refund", r.refund)
+	g.POST("/revenue-refund", r.revenueRefund)
 }
 
 type reservationCreateInput struct {
 	AccountId int `json:"account_id" validate:"required"`
 	ProductId int `json:"product_id" validate:"required"`
 	OrderId   int `json:"order_id" validate:"required"`
-	Amount    int `json:"amount" validate:"required"`
+	Amount    int `json:"amount" validate:"required,gt=0"`
 }
 
 // @Summary Create reservation
@@ -35,6 +40,8 @@ type reservationCreateInput struct {
 // @Param input body reservationCreateInput true "input"
 // @Success 201 {object} v1.reservationRoutes.create.response
 // @Failure 400 {object} echo.HTTPError
+// @Failure 403 {object} echo.HTTPError
+// @Failure 422 {object} echo.HTTPError
 // @Failure 500 {object} echo.HTTPError
 // @Security JWT
 // @Router /api/v1/reservations/create [post]
@@ -56,12 +63,22 @@ func (r *reservationRoutes) create(c echo.Context) error {
 		ProductId: input.ProductId,
 		OrderId:   input.OrderId,
 		Amount:    input.Amount,
+		UserId:    c.Get(userIdCtx).(int),
 	})
 	if err != nil {
 		if err == service.ErrCannotCreateReservation {
 			newErrorResponse(c, http.StatusBadRequest, err.Error())
 			return err
 		}
+		if err == service.ErrAccountFrozen || err == service.ErrAccountClosed ||
+			err == service.ErrNotGroupMember || err == service.ErrGroupAccessDenied {
+			newErrorResponse(c, http.StatusForbidden, err.Error())
+			return err
+		}
+		if err == service.ErrMemberLimitExceeded || errors.Is(err, service.ErrLimitExceeded) {
+			newErrorResponse(c, http.StatusUnprocessableEntity, err.Error())
+			return err
+		}
 		newErrorResponse(c, http.StatusInternalServerError, "internal server error")
 		return err
 	}
@@ -79,7 +96,7 @@ type reservationRevenueInput struct {
 	AccountId int `json:"account_id" validate:"required"`
 	ProductId int `json:"product_id" validate:"required"`
 	OrderId   int `json:"order_id" validate:"required"`
-	Amount    int `json:"amount" validate:"required"`
+	Amount    int `json:"amount" validate:"required,gt=0"`
 }
 
 // @Summary Revenue reservation
@@ -92,6 +109,7 @@ type reservationRevenueInput struct {
 // @Failure 400 {object} echo.HTTPError
 // @Failure 500 {object} echo.HTTPError
 // @Security JWT
+// @Failure 409 {object} echo.HTTPError
 // @Router /api/v1/reservations/revenue [post]
 func (r *reservationRoutes) revenue(c echo.Context) error {
 	var input reservationRevenueInput
@@ -108,6 +126,14 @@ func (r *reservationRoutes) revenue(c echo.Context) error {
 
 	err := r.reservationService.RevenueReservationByOrderId(c.Request().Context(), input.OrderId)
 	if err != nil {
+		if err == service.ErrAccountFrozen || err == service.ErrAccountClosed {
+			newErrorResponse(c, http.StatusForbidden, err.Error())
+			return err
+		}
+		if err == service.ErrOutstandingInstallments {
+			newErrorResponse(c, http.StatusConflict, err.Error())
+			return err
+		}
 		newErrorResponse(c, http.StatusInternalServerError, "internal server error")
 		return err
 	}
@@ -122,12 +148,14 @@ type reservationRefundInput struct {
 }
 
 // @Summary Refund reservation
-// @Description Refund reservation
+// @Description Refund reservation of the order which is not captured yet, captured orders are refunded through /reservations/revenue-refund.
+// @Description If refunds need approval, the refund is submitted as an operation request and executed after another user approves it
 // @Tags reservations
 // @Accept json
 // @Produce json
 // @Param input body reservationRefundInput true "input"
 // @Success 200
+// @Success 202
 // @Failure 400 {object} echo.HTTPError
 // @Failure 500 {object} echo.HTTPError
 // @Security JWT
@@ -145,8 +173,73 @@ func (r *reservationRoutes) refund(c echo.Context) error {
 		return err
 	}
 
+	if r.approvalService.RequiresApproval(entity.OperationTypeRefund, 0) {
+		return submitForApproval(c, r.approvalService, service.ApprovalCreateInput{
+			OperationType: entity.OperationTypeRefund,
+			OrderId:       input.OrderId,
+		})
+	}
+
 	err := r.reservationService.RefundReservationByOrderId(c.Request().Context(), input.OrderId)
 	if err != nil {
+		if err == service.ErrAccountFrozen || err == service.ErrAccountClosed {
+			newErrorResponse(c, http.StatusForbidden, err.Error())
+			return err
+		}
+		newErrorResponse(c, http.StatusInternalServerError, "internal server error")
+		return err
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": "success",
+	})
+}
+
+// @Summary Refund captured order
+// @Description Refund the order whose revenue is already recognized: the money is returned to the wallets it was taken from and the cashback is taken back.
+// @Description If refunds of captured orders need approval, the refund is submitted as an operation request and executed after another user approves it
+// @Tags reservations
+// @Accept json
+// @Produce json
+// @Param input body reservationRefundInput true "input"
+// @Success 200
+// @Success 202
+// @Failure 400 {object} echo.HTTPError
+// @Failure 403 {object} echo.HTTPError
+// @Failure 404 {object} echo.HTTPError
+// @Failure 500 {object} echo.HTTPError
+// @Security JWT
+// @Router /api/v1/reservations/revenue-refund [post]
+func (r *reservationRoutes) revenueRefund(c echo.Context) error {
+	var input reservationRefundInput
+
+	if err := c.Bind(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid request body")
+		return err
+	}
+
+	if err := c.Validate(input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return err
+	}
+
+	if r.approvalService.RequiresApproval(entity.OperationTypeRevenueRefund, 0) {
+		return submitForApproval(c, r.approvalService, service.ApprovalCreateInput{
+			OperationType: entity.OperationTypeRevenueRefund,
+			OrderId:       input.OrderId,
+		})
+	}
+
+	err := r.reservationService.RefundCapturedOrder(c.Request().Context(), input.OrderId)
+	if err != nil {
+		if err == service.ErrCapturedOrderNotFound {
+			newErrorResponse(c, http.StatusNotFound, err.Error())
+			return err
+		}
+		if err == service.ErrAccountFrozen || err == service.ErrAccountClosed {
+			newErrorResponse(c, http.StatusForbidden, err.Error())
+			return err
+		}
 		newErrorResponse(c, http.StatusInternalServerError, "internal server error")
 		return err
 	}
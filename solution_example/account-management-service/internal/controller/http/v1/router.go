@@ -2,15 +2,18 @@ package v1
 
 import (
 	_ "account-management-service/docs"
+	"account-management-service/internal/entity"
 	"account-management-service/internal/service"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
 	log "github.com/sirupsen/logrus"
 	echoSwagger "github.com/swaggo/echo-swagger"
 	"os"
+	"time"
 )
 
-func NewRouter(handler *echo.Echo, services *service.Services) {
+// NewRouter registers the routes, exportWriteTimeout replaces the server write timeout for the operations exports
+func NewRouter(handler *echo.Echo, services *service.Services, exportWriteTimeout time.Duration) {
 	handler.Use(middleware.LoggerWithConfig(middleware.LoggerConfig{
 		Format: `{"time":"${time_rfc3339_nano}", "method":"${method}","uri":"${uri}", "status":${status},"error":"${error}"}` + "\n",
 		Output: setLogsFile(),
@@ -25,13 +28,35 @@ func NewRouter(handler *echo.Echo, services *service.Services) {
 		newAuthRoutes(auth, services.Auth)
 	}
 
+	newReportDownloadRoutes(handler.Group(service.ReportDownloadPath), services.ReportLink)
+
 	authMiddleware := &AuthMiddleware{services.Auth}
+	adminOnly := authMiddleware.RequireRole(entity.UserRoleAdmin)
+	identityOnly := authMiddleware.RequireRole(entity.UserRoleIdentity)
+	approverOnly := authMiddleware.RequireRole(entity.UserRoleApprover)
+	exportTimeout := writeTimeout(exportWriteTimeout)
 	v1 := handler.Group("/api/v1", authMiddleware.UserIdentity)
 	{
-		newAccountRoutes(v1.Group("/accounts"), services.Account)
-		newReservationRoutes(v1.Group("/reservations"), services.Reservation)
+		newAccountRoutes(v1.Group("/accounts"), services.Account, services.Installment, services.Approval, adminOnly)
+		newReservationRoutes(v1.Group("/reservations"), services.Reservation, services.Approval)
 		newProductRoutes(v1.Group("/products"), services.Product)
-		newOperationRoutes(v1.Group("/operations"), services.Operation)
+		newOperationRoutes(v1.Group("/operations"), services.Operation, exportTimeout)
+		newReportLinkRoutes(v1.Group("/report-links", adminOnly), services.ReportLink)
+		newReportJobRoutes(v1.Group("/report-jobs"), services.ReportJob)
+		newReportScheduleRoutes(v1.Group("/report-schedules", adminOnly), services.ReportSchedule)
+		newReportArchiveRoutes(v1.Group("/report-archive", adminOnly), services.ReportArchive)
+		newLimitRoutes(v1.Group("/limits", adminOnly), services.Limit)
+		newVerificationRoutes(v1.Group("/verification"), services.Verification, identityOnly, adminOnly)
+		newFeeRoutes(v1.Group("/fees"), services.Fee, adminOnly)
+		newCashbackRoutes(v1.Group("/cashback", adminOnly), services.Cashback)
+		newSubscriptionRoutes(v1.Group("/subscriptions"), services.Subscription)
+		newPaymentRequestRoutes(v1.Group("/payment-requests"), services.PaymentRequest)
+		newInstallmentRoutes(v1.Group("/installments"), services.Installment)
+		newGroupRoutes(v1.Group("/groups"), services.Group, services.Reservation)
+		newApprovalRoutes(v1.Group("/approvals", approverOnly), services.Approval)
+		newAdjustmentRoutes(v1.Group("/adjustments", adminOnly), services.Adjustment, services.Approval)
+		newVoucherRoutes(v1.Group("/vouchers"), services.Voucher, adminOnly)
+		newBatchRoutes(v1.Group("/batch", adminOnly), services.Batch)
 	}
 }
 
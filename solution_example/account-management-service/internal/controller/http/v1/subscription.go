@@ -0,0 +1,294 @@
+package v1
+
+import (
+	"account-management-service/internal/entity"
+	"account-management-service/internal/service"
+	"context"
+	"github.com/labstack/echo/v4"
+	"net/http"
+	"time"
+)
+
+type subscriptionRoutes struct {
+	subscriptionService service.Subscription
+}
+
+func newSubscriptionRoutes(g *echo.Group, subscriptionService service.Subscription) {
+	r := &subscriptionRoutes{
+		subscriptionService: subscriptionService,
+	}
+
+	g.POST("/create", r.create)
+	g.POST("/pause", r.pause)
+	g.POST("/resume", r.resume)
+	g.POST("/cancel", r.cancel)
+	g.GET("/", r.getById)
+	g.GET("/list", r.getByAccountId)
+	g.GET("/charges", r.getCharges)
+}
+
+type subscriptionCreateInput struct {
+	AccountId int       `json:"account_id" validate:"required"`
+	ProductId int       `json:"product_id" validate:"required"`
+	Amount    int       `json:"amount" validate:"required,gt=0"`
+	Period    string    `json:"period" validate:"required,oneof=day week month"`
+	StartAt   time.Time `json:"start_at,omitempty"`
+}
+
+// @Summary Create subscription
+// @Description Create subscription, the first period is charged at start_at or right away
+// @Tags subscriptions
+// @Accept json
+// @Produce json
+// @Param input body v1.subscriptionCreateInput true "input"
+// @Success 201 {object} v1.subscriptionRoutes.create.response
+// @Failure 400 {object} echo.HTTPError
+// @Failure 403 {object} echo.HTTPError
+// @Failure 500 {object} echo.HTTPError
+// @Security JWT
+// @Router /api/v1/subscriptions/create [post]
+func (r *subscriptionRoutes) create(c echo.Context) error {
+	var input subscriptionCreateInput
+
+	if err := c.Bind(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid request body")
+		return err
+	}
+
+	if err := c.Validate(input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return err
+	}
+
+	id, err := r.subscriptionService.CreateSubscription(c.Request().Context(), service.SubscriptionCreateInput{
+		AccountId: input.AccountId,
+		ProductId: input.ProductId,
+		Amount:    input.Amount,
+		Period:    input.Period,
+		StartAt:   input.StartAt,
+		UserId:    c.Get(userIdCtx).(int),
+	})
+	if err != nil {
+		if err == service.ErrCannotCreateSubscription {
+			newErrorResponse(c, http.StatusBadRequest, err.Error())
+			return err
+		}
+		if err == service.ErrNotGroupMember || err == service.ErrGroupAccessDenied {
+			newErrorResponse(c, http.StatusForbidden, err.Error())
+			return err
+		}
+		newErrorResponse(c, http.StatusInternalServerError, "internal server error")
+		return err
+	}
+
+	type response struct {
+		Id int `json:"id"`
+	}
+
+	return c.JSON(http.StatusCreated, response{
+		Id: id,
+	})
+}
+
+type subscriptionIdInput struct {
+	Id int `json:"id" validate:"required"`
+}
+
+// @Summary Pause subscription
+// @Description Pause active or past due subscription, it is not charged until resumed
+// @Tags subscriptions
+// @Accept json
+// @Produce json
+// @Param input body v1.subscriptionIdInput true "input"
+// @Success 200
+// @Failure 400 {object} echo.HTTPError
+// @Failure 409 {object} echo.HTTPError
+// @Failure 500 {object} echo.HTTPError
+// @Security JWT
+// @Router /api/v1/subscriptions/pause [post]
+func (r *subscriptionRoutes) pause(c echo.Context) error {
+	return r.changeStatus(c, r.subscriptionService.PauseSubscription)
+}
+
+// @Summary Resume subscription
+// @Description Resume paused or suspended subscription, a missed period is charged right away
+// @Tags subscriptions
+// @Accept json
+// @Produce json
+// @Param input body v1.subscriptionIdInput true "input"
+// @Success 200
+// @Failure 400 {object} echo.HTTPError
+// @Failure 409 {object} echo.HTTPError
+// @Failure 500 {object} echo.HTTPError
+// @Security JWT
+// @Router /api/v1/subscriptions/resume [post]
+func (r *subscriptionRoutes) resume(c echo.Context) error {
+	return r.changeStatus(c, r.subscriptionService.ResumeSubscription)
+}
+
+// @Summary Cancel subscription
+// @Description Cancel subscription, cancelled subscription cannot be resumed
+// @Tags subscriptions
+// @Accept json
+// @Produce json
+// @Param input body v1.subscriptionIdInput true "input"
+// @Success 200
+// @Failure 400 {object} echo.HTTPError
+// @Failure 409 {object} echo.HTTPError
+// @Failure 500 {object} echo.HTTPError
+// @Security JWT
+// @Router /api/v1/subscriptions/cancel [post]
+func (r *subscriptionRoutes) cancel(c echo.Context) error {
+	return r.changeStatus(c, r.subscriptionService.CancelSubscription)
+}
+
+func (r *subscriptionRoutes) changeStatus(c echo.Context, change func(ctx context.Context, id int) error) error {
+	var input subscriptionIdInput
+
+	if err := c.Bind(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid request body")
+		return err
+	}
+
+	if err := c.Validate(input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return err
+	}
+
+	err := change(c.Request().Context(), input.Id)
+	if err != nil {
+		if err == service.ErrSubscriptionNotFound {
+			newErrorResponse(c, http.StatusBadRequest, err.Error())
+			return err
+		}
+		if err == service.ErrCannotChangeSubscriptionStatus {
+			newErrorResponse(c, http.StatusConflict, err.Error())
+			return err
+		}
+		newErrorResponse(c, http.StatusInternalServerError, "internal server error")
+		return err
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": "success",
+	})
+}
+
+// @Summary Get subscription
+// @Description Get subscription by id
+// @Tags subscriptions
+// @Accept json
+// @Produce json
+// @Param input body v1.subscriptionIdInput true "input"
+// @Success 200 {object} entity.Subscription
+// @Failure 400 {object} echo.HTTPError
+// @Failure 500 {object} echo.HTTPError
+// @Security JWT
+// @Router /api/v1/subscriptions/ [get]
+func (r *subscriptionRoutes) getById(c echo.Context) error {
+	var input subscriptionIdInput
+
+	if err := c.Bind(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid request body")
+		return err
+	}
+
+	if err := c.Validate(input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return err
+	}
+
+	subscription, err := r.subscriptionService.GetSubscriptionById(c.Request().Context(), input.Id)
+	if err != nil {
+		if err == service.ErrSubscriptionNotFound {
+			newErrorResponse(c, http.StatusBadRequest, err.Error())
+			return err
+		}
+		newErrorResponse(c, http.StatusInternalServerError, "internal server error")
+		return err
+	}
+
+	return c.JSON(http.StatusOK, subscription)
+}
+
+type subscriptionListInput struct {
+	AccountId int `json:"account_id" validate:"required"`
+}
+
+// @Summary Get account subscriptions
+// @Description Get all subscriptions of the account
+// @Tags subscriptions
+// @Accept json
+// @Produce json
+// @Param input body v1.subscriptionListInput true "input"
+// @Success 200 {object} v1.subscriptionRoutes.getByAccountId.response
+// @Failure 400 {object} echo.HTTPError
+// @Failure 500 {object} echo.HTTPError
+// @Security JWT
+// @Router /api/v1/subscriptions/list [get]
+func (r *subscriptionRoutes) getByAccountId(c echo.Context) error {
+	var input subscriptionListInput
+
+	if err := c.Bind(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid request body")
+		return err
+	}
+
+	if err := c.Validate(input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return err
+	}
+
+	subscriptions, err := r.subscriptionService.GetAccountSubscriptions(c.Request().Context(), input.AccountId)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, "internal server error")
+		return err
+	}
+
+	type response struct {
+		Subscriptions []entity.Subscription `json:"subscriptions"`
+	}
+
+	return c.JSON(http.StatusOK, response{
+		Subscriptions: subscriptions,
+	})
+}
+
+// @Summary Get subscription charges
+// @Description Get all charge attempts of the subscription, both succeeded and failed
+// @Tags subscriptions
+// @Accept json
+// @Produce json
+// @Param input body v1.subscriptionIdInput true "input"
+// @Success 200 {object} v1.subscriptionRoutes.getCharges.response
+// @Failure 400 {object} echo.HTTPError
+// @Failure 500 {object} echo.HTTPError
+// @Security JWT
+// @Router /api/v1/subscriptions/charges [get]
+func (r *subscriptionRoutes) getCharges(c echo.Context) error {
+	var input subscriptionIdInput
+
+	if err := c.Bind(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid request body")
+		return err
+	}
+
+	if err := c.Validate(input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return err
+	}
+
+	charges, err := r.subscriptionService.GetSubscriptionCharges(c.Request().Context(), input.Id)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, "internal server error")
+		return err
+	}
+
+	type response struct {
+		Charges []entity.SubscriptionCharge `json:"charges"`
+	}
+
+	return c.JSON(http.StatusOK, response{
+		Charges: charges,
+	})
+}
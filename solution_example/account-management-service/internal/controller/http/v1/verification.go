@@ -0,0 +1,245 @@
+package v1
+
+import (
+	"account-management-service/internal/entity"
+	"account-management-service/internal/service"
+	"github.com/labstack/echo/v4"
+	"net/http"
+)
+
+type verificationRoutes struct {
+	verificationService service.Verification
+}
+
+func newVerificationRoutes(g *echo.Group, verificationService service.Verification, identityOnly, adminOnly echo.MiddlewareFunc) {
+	r := &verificationRoutes{
+		verificationService: verificationService,
+	}
+
+	g.POST("/level", r.setLevel, identityOnly)
+
+	g.GET("/history", r.getHistory, adminOnly)
+	g.POST("/policies/set", r.setPolicy, adminOnly)
+	g.POST("/policies/delete", r.deletePolicy, adminOnly)
+	g.GET("/policies", r.getPolicies, adminOnly)
+}
+
+type verificationSetLevelInput struct {
+	AccountId int    `json:"account_id" validate:"required"`
+	Level     string `json:"level" validate:"required,oneof=none basic full"`
+	Reference string `json:"reference,omitempty" validate:"max=255"`
+}
+
+// @Summary Set verification level
+// @Description Set account verification level after the identity check. Identity service only
+// @Tags verification
+// @Accept json
+// @Produce json
+// @Param input body v1.verificationSetLevelInput true "input"
+// @Success 200
+// @Failure 400 {object} echo.HTTPError
+// @Failure 403 {object} echo.HTTPError
+// @Failure 500 {object} echo.HTTPError
+// @Security JWT
+// @Router /api/v1/verification/level [post]
+func (r *verificationRoutes) setLevel(c echo.Context) error {
+	var input verificationSetLevelInput
+
+	if err := c.Bind(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid request body")
+		return err
+	}
+
+	if err := c.Validate(input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return err
+	}
+
+	err := r.verificationService.SetVerificationLevel(c.Request().Context(), service.VerificationSetLevelInput{
+		AccountId: input.AccountId,
+		Level:     input.Level,
+		Reference: input.Reference,
+		ChangedBy: c.Get(userIdCtx).(int),
+	})
+	if err != nil {
+		if err == service.ErrAccountNotFound || err == service.ErrInvalidVerificationLevel {
+			newErrorResponse(c, http.StatusBadRequest, err.Error())
+			return err
+		}
+		newErrorResponse(c, http.StatusInternalServerError, "internal server error")
+		return err
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": "success",
+	})
+}
+
+type verificationHistoryInput struct {
+	AccountId int `json:"account_id" validate:"required"`
+}
+
+// @Summary Get verification history
+// @Description Get history of account verification level changes. Admin only
+// @Tags verification
+// @Accept json
+// @Produce json
+// @Param input body v1.verificationHistoryInput true "input"
+// @Success 200 {object} v1.verificationRoutes.getHistory.response
+// @Failure 400 {object} echo.HTTPError
+// @Failure 403 {object} echo.HTTPError
+// @Failure 500 {object} echo.HTTPError
+// @Security JWT
+// @Router /api/v1/verification/history [get]
+func (r *verificationRoutes) getHistory(c echo.Context) error {
+	var input verificationHistoryInput
+
+	if err := c.Bind(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid request body")
+		return err
+	}
+
+	if err := c.Validate(input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return err
+	}
+
+	changes, err := r.verificationService.GetVerificationHistory(c.Request().Context(), input.AccountId)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, "internal server error")
+		return err
+	}
+
+	type response struct {
+		Changes []entity.VerificationChange `json:"changes"`
+	}
+
+	return c.JSON(http.StatusOK, response{
+		Changes: changes,
+	})
+}
+
+type verificationSetPolicyInput struct {
+	Level         string `json:"level" validate:"required,oneof=none basic full"`
+	OperationType string `json:"operation_type" validate:"required,oneof=withdraw transfer_from"`
+	MaxAmount     int    `json:"max_amount,omitempty"`
+	MaxTotal      int    `json:"max_total,omitempty"`
+	WindowSeconds int    `json:"window_seconds,omitempty"`
+}
+
+// @Summary Set verification policy
+// @Description Allow the operation to the verification level with the given limits, zero limits mean no limit. Admin only
+// @Tags verification
+// @Accept json
+// @Produce json
+// @Param input body v1.verificationSetPolicyInput true "input"
+// @Success 200
+// @Failure 400 {object} echo.HTTPError
+// @Failure 403 {object} echo.HTTPError
+// @Failure 500 {object} echo.HTTPError
+// @Security JWT
+// @Router /api/v1/verification/policies/set [post]
+func (r *verificationRoutes) setPolicy(c echo.Context) error {
+	var input verificationSetPolicyInput
+
+	if err := c.Bind(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid request body")
+		return err
+	}
+
+	if err := c.Validate(input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return err
+	}
+
+	err := r.verificationService.SetVerificationPolicy(c.Request().Context(), service.VerificationSetPolicyInput{
+		Level:         input.Level,
+		OperationType: input.OperationType,
+		MaxAmount:     input.MaxAmount,
+		MaxTotal:      input.MaxTotal,
+		WindowSeconds: input.WindowSeconds,
+	})
+	if err != nil {
+		if err == service.ErrInvalidVerificationPolicy {
+			newErrorResponse(c, http.StatusBadRequest, err.Error())
+			return err
+		}
+		newErrorResponse(c, http.StatusInternalServerError, "internal server error")
+		return err
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": "success",
+	})
+}
+
+type verificationDeletePolicyInput struct {
+	Level         string `json:"level" validate:"required"`
+	OperationType string `json:"operation_type" validate:"required"`
+}
+
+// @Summary Delete verification policy
+// @Description Forbid the operation to the verification level. Admin only
+// @Tags verification
+// @Accept json
+// @Produce json
+// @Param input body v1.verificationDeletePolicyInput true "input"
+// @Success 200
+// @Failure 400 {object} echo.HTTPError
+// @Failure 403 {object} echo.HTTPError
+// @Failure 500 {object} echo.HTTPError
+// @Security JWT
+// @Router /api/v1/verification/policies/delete [post]
+func (r *verificationRoutes) deletePolicy(c echo.Context) error {
+	var input verificationDeletePolicyInput
+
+	if err := c.Bind(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid request body")
+		return err
+	}
+
+	if err := c.Validate(input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return err
+	}
+
+	err := r.verificationService.DeleteVerificationPolicy(c.Request().Context(), input.Level, input.OperationType)
+	if err != nil {
+		if err == service.ErrVerificationPolicyNotFound {
+			newErrorResponse(c, http.StatusBadRequest, err.Error())
+			return err
+		}
+		newErrorResponse(c, http.StatusInternalServerError, "internal server error")
+		return err
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": "success",
+	})
+}
+
+// @Summary Get verification policies
+// @Description Get the operations allowed to each verification level and their limits. Admin only
+// @Tags verification
+// @Accept json
+// @Produce json
+// @Success 200 {object} v1.verificationRoutes.getPolicies.response
+// @Failure 403 {object} echo.HTTPError
+// @Failure 500 {object} echo.HTTPError
+// @Security JWT
+// @Router /api/v1/verification/policies [get]
+func (r *verificationRoutes) getPolicies(c echo.Context) error {
+	policies, err := r.verificationService.GetAllVerificationPolicies(c.Request().Context())
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, "internal server error")
+		return err
+	}
+
+	type response struct {
+		Policies []entity.VerificationPolicy `json:"policies"`
+	}
+
+	return c.JSON(http.StatusOK, response{
+		Policies: policies,
+	})
+}
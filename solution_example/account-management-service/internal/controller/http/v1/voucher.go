@@ -0,0 +1,194 @@
+package v1
+
+import (
+	"account-management-service/internal/entity"
+	"account-management-service/internal/service"
+	"github.com/labstack/echo/v4"
+	"net/http"
+	"time"
+)
+
+type voucherRoutes struct {
+	voucherService service.Voucher
+}
+
+func newVoucherRoutes(g *echo.Group, voucherService service.Voucher, adminOnly echo.MiddlewareFunc) {
+	r := &voucherRoutes{
+		voucherService: voucherService,
+	}
+
+	g.POST("/campaigns/create", r.createCampaign, adminOnly)
+	g.GET("/campaigns/report", r.getCampaignReport, adminOnly)
+	g.POST("/redeem", r.redeem)
+}
+
+type voucherCreateCampaignInput struct {
+	Name      string    `json:"name" validate:"required,max=255"`
+	Amount    int       `json:"amount" validate:"required,gt=0"`
+	Wallet    string    `json:"wallet,omitempty" validate:"omitempty,oneof=main bonus"`
+	Count     int       `json:"count" validate:"required,gt=0,max=10000"`
+	MaxUses   int       `json:"max_uses,omitempty"`
+	ExpiresAt time.Time `json:"expires_at" validate:"required"`
+}
+
+// @Summary Create voucher campaign
+// @Description Generate count unique voucher codes, each can be redeemed max_uses times but once per account. Admin only
+// @Tags vouchers
+// @Accept json
+// @Produce json
+// @Param input body v1.voucherCreateCampaignInput true "input"
+// @Success 201 {object} v1.voucherRoutes.createCampaign.response
+// @Failure 400 {object} echo.HTTPError
+// @Failure 403 {object} echo.HTTPError
+// @Failure 500 {object} echo.HTTPError
+// @Security JWT
+// @Router /api/v1/vouchers/campaigns/create [post]
+func (r *voucherRoutes) createCampaign(c echo.Context) error {
+	var input voucherCreateCampaignInput
+
+	if err := c.Bind(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid request body")
+		return err
+	}
+
+	if err := c.Validate(input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return err
+	}
+
+	id, codes, err := r.voucherService.CreateVoucherCampaign(c.Request().Context(), service.VoucherCreateCampaignInput{
+		Name:      input.Name,
+		Amount:    input.Amount,
+		Wallet:    input.Wallet,
+		Count:     input.Count,
+		MaxUses:   input.MaxUses,
+		ExpiresAt: input.ExpiresAt,
+	})
+	if err != nil {
+		if err == service.ErrInvalidVoucherCampaign {
+			newErrorResponse(c, http.StatusBadRequest, err.Error())
+			return err
+		}
+		newErrorResponse(c, http.StatusInternalServerError, "internal server error")
+		return err
+	}
+
+	type response struct {
+		Id    int      `json:"id"`
+		Codes []string `json:"codes"`
+	}
+
+	return c.JSON(http.StatusCreated, response{
+		Id:    id,
+		Codes: codes,
+	})
+}
+
+type voucherRedeemInput struct {
+	Code      string `json:"code" validate:"required"`
+	AccountId int    `json:"account_id" validate:"required"`
+}
+
+// @Summary Redeem voucher
+// @Description Credit the voucher amount to the account wallet
+// @Tags vouchers
+// @Accept json
+// @Produce json
+// @Param input body v1.voucherRedeemInput true "input"
+// @Success 200 {object} v1.voucherRoutes.redeem.response
+// @Failure 400 {object} echo.HTTPError
+// @Failure 403 {object} echo.HTTPError
+// @Failure 409 {object} echo.HTTPError
+// @Failure 500 {object} echo.HTTPError
+// @Security JWT
+// @Router /api/v1/vouchers/redeem [post]
+func (r *voucherRoutes) redeem(c echo.Context) error {
+	var input voucherRedeemInput
+
+	if err := c.Bind(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid request body")
+		return err
+	}
+
+	if err := c.Validate(input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return err
+	}
+
+	redemption, err := r.voucherService.RedeemVoucher(c.Request().Context(), service.VoucherRedeemInput{
+		Code:      input.Code,
+		AccountId: input.AccountId,
+	})
+	if err != nil {
+		if err == service.ErrAccountNotFound || err == service.ErrVoucherNotFound {
+			newErrorResponse(c, http.StatusBadRequest, err.Error())
+			return err
+		}
+		if err == service.ErrAccountFrozen || err == service.ErrAccountClosed {
+			newErrorResponse(c, http.StatusForbidden, err.Error())
+			return err
+		}
+		if err == service.ErrVoucherExpired || err == service.ErrVoucherUsedUp || err == service.ErrVoucherAlreadyRedeemed {
+			newErrorResponse(c, http.StatusConflict, err.Error())
+			return err
+		}
+		newErrorResponse(c, http.StatusInternalServerError, "internal server error")
+		return err
+	}
+
+	type response struct {
+		Amount int `json:"amount"`
+	}
+
+	return c.JSON(http.StatusOK, response{
+		Amount: redemption.Amount,
+	})
+}
+
+type voucherCampaignReportInput struct {
+	CampaignId int `json:"campaign_id" validate:"required"`
+}
+
+// @Summary Get voucher campaign report
+// @Description Get issued vs redeemed codes and amounts of the campaign. Admin only
+// @Tags vouchers
+// @Accept json
+// @Produce json
+// @Param input body v1.voucherCampaignReportInput true "input"
+// @Success 200 {object} v1.voucherRoutes.getCampaignReport.response
+// @Failure 400 {object} echo.HTTPError
+// @Failure 403 {object} echo.HTTPError
+// @Failure 500 {object} echo.HTTPError
+// @Security JWT
+// @Router /api/v1/vouchers/campaigns/report [get]
+func (r *voucherRoutes) getCampaignReport(c echo.Context) error {
+	var input voucherCampaignReportInput
+
+	if err := c.Bind(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid request body")
+		return err
+	}
+
+	if err := c.Validate(input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return err
+	}
+
+	report, err := r.voucherService.GetVoucherCampaignReport(c.Request().Context(), input.CampaignId)
+	if err != nil {
+		if err == service.ErrCampaignNotFound {
+			newErrorResponse(c, http.StatusBadRequest, err.Error())
+			return err
+		}
+		newErrorResponse(c, http.StatusInternalServerError, "internal server error")
+		return err
+	}
+
+	type response struct {
+		Report entity.VoucherCampaignReport `json:"report"`
+	}
+
+	return c.JSON(http.StatusOK, response{
+		Report: report,
+	})
+}
@@ -3,7 +3,44 @@ package entity
 import "time"
 
 type Account struct {
-	Id        int       `db:"id"`
-	Balance   int       `db:"balance"`
-	CreatedAt time.Time `db:"created_at"`
+	Id                int       `db:"id"`
+	Balance           int       `db:"balance"`
+	CreatedAt         time.Time `db:"created_at"`
+	Type              string    `db:"type"`
+	Status            string    `db:"status"`
+	VerificationLevel string    `db:"verification_level"`
 }
+
+const (
+	AccountTypePersonal = "personal"
+	AccountTypeBusiness = "business"
+)
+
+const (
+	AccountStatusActive = "active"
+	// AccountStatusFrozenDebit allows only incoming money
+	AccountStatusFrozenDebit = "frozen_debit"
+	// AccountStatusFrozen blocks any money movement
+	AccountStatusFrozen = "frozen"
+	AccountStatusClosed = "closed"
+)
+
+type AccountStatusChange struct {
+	Id         int       `db:"id"`
+	AccountId  int       `db:"account_id"`
+	OldStatus  string    `db:"old_status"`
+	NewStatus  string    `db:"new_status"`
+	ReasonCode string    `db:"reason_code"`
+	Comment    string    `db:"comment"`
+	ChangedBy  *int      `db:"changed_by"`
+	CreatedAt  time.Time `db:"created_at"`
+}
+
+const (
+	StatusReasonFraudSuspected   = "fraud_suspected"
+	StatusReasonSecurityIncident = "security_incident"
+	StatusReasonComplianceCheck  = "compliance_check"
+	StatusReasonUserRequest      = "user_request"
+	StatusReasonResolved         = "resolved"
+	StatusReasonOther            = "other"
+)
@@ -0,0 +1,31 @@
+package entity
+
+import "time"
+
+// Adjustment is a manual correction of the account balance, it is recorded as
+// an adjustment_credit or adjustment_debit operation
+type Adjustment struct {
+	Id         int       `db:"id"`
+	AccountId  int       `db:"account_id"`
+	Direction  string    `db:"direction"`
+	Amount     int       `db:"amount"`
+	Wallet     string    `db:"wallet"`
+	ReasonCode string    `db:"reason_code"`
+	Comment    string    `db:"comment"`
+	Ticket     string    `db:"ticket"`
+	CreatedBy  int       `db:"created_by"`
+	CreatedAt  time.Time `db:"created_at"`
+}
+
+const (
+	AdjustmentDirectionCredit = "credit"
+	AdjustmentDirectionDebit  = "debit"
+)
+
+// AdjustmentReason is an entry of the managed list of reasons, inactive reasons cannot be used for new adjustments
+type AdjustmentReason struct {
+	Code        string    `db:"code"`
+	Description string    `db:"description"`
+	Active      bool      `db:"active"`
+	CreatedAt   time.Time `db:"created_at"`
+}
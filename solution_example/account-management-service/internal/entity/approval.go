@@ -0,0 +1,53 @@
+package entity
+
+import "time"
+
+// ApprovalRequest is an operation submitted by the maker which is executed
+// only after another user with the approver role approves it
+type ApprovalRequest struct {
+	Id            int        `db:"id"`
+	OperationType string     `db:"operation_type"`
+	AccountId     *int       `db:"account_id"`
+	OrderId       *int       `db:"order_id"`
+	Amount        int        `db:"amount"`
+	Wallet        string     `db:"wallet"`
+	ReasonCode    string     `db:"reason_code"`
+	Comment       string     `db:"comment"`
+	Ticket        string     `db:"ticket"`
+	Status        string     `db:"status"`
+	MakerId       int        `db:"maker_id"`
+	CheckerId     *int       `db:"checker_id"`
+	Error         string     `db:"error"`
+	ExpiresAt     time.Time  `db:"expires_at"`
+	ResolvedAt    *time.Time `db:"resolved_at"`
+	CreatedAt     time.Time  `db:"created_at"`
+}
+
+const (
+	ApprovalStatusPending = "pending"
+	// ApprovalStatusApproved is set while the approved operation is being executed
+	ApprovalStatusApproved = "approved"
+	ApprovalStatusExecuted = "executed"
+	ApprovalStatusFailed   = "failed"
+	ApprovalStatusRejected = "rejected"
+	ApprovalStatusExpired  = "expired"
+)
+
+// ApprovalEvent is a record of the approval audit trail
+type ApprovalEvent struct {
+	Id        int       `db:"id"`
+	RequestId int       `db:"request_id"`
+	Action    string    `db:"action"`
+	UserId    *int      `db:"user_id"`
+	Comment   string    `db:"comment"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+const (
+	ApprovalActionCreated  = "created"
+	ApprovalActionApproved = "approved"
+	ApprovalActionRejected = "rejected"
+	ApprovalActionExecuted = "executed"
+	ApprovalActionFailed   = "failed"
+	ApprovalActionExpired  = "expired"
+)
@@ -0,0 +1,43 @@
+package entity
+
+// BatchItem is a single money movement of a bulk request
+type BatchItem struct {
+	Type      string
+	AccountId int
+	From      int
+	To        int
+	Amount    int
+	Wallet    string
+	// Fee, Limits and Verification of a transfer are found by the service,
+	// the rolling window totals are checked by the repo against the operations made so far
+	Fee          Fee
+	Limits       []LimitRule
+	Verification VerificationCheck
+	// RejectReason is set by the service if the transfer cannot be executed, the item is then reported as failed
+	RejectReason string
+}
+
+const (
+	BatchItemTypeDeposit  = "deposit"
+	BatchItemTypeTransfer = "transfer"
+)
+
+const (
+	// BatchModeAtomic executes all the items or none of them
+	BatchModeAtomic = "atomic"
+	// BatchModeBestEffort executes the items in chunks and skips the ones which fail
+	BatchModeBestEffort = "best_effort"
+)
+
+type BatchItemResult struct {
+	Index  int
+	Status string
+	Error  string
+}
+
+const (
+	BatchItemStatusSucceeded = "succeeded"
+	BatchItemStatusFailed    = "failed"
+	// BatchItemStatusSkipped is set for valid items which were not executed because another item failed
+	BatchItemStatusSkipped = "skipped"
+)
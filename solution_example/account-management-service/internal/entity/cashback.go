@@ -0,0 +1,25 @@
+package entity
+
+import "time"
+
+// CashbackRule returns Rate basis points (100 is 1%) of the money spent on the product as bonus credit,
+// at most Cap per order, zero Cap means no limit. ValidTo is nil for rules without an end date
+type CashbackRule struct {
+	Id        int        `db:"id"`
+	ProductId int        `db:"product_id"`
+	Rate      int        `db:"rate"`
+	Cap       int        `db:"cap"`
+	ValidFrom time.Time  `db:"valid_from"`
+	ValidTo   *time.Time `db:"valid_to"`
+	CreatedAt time.Time  `db:"created_at"`
+}
+
+// Calculate returns the cashback for the spent amount, rounded down
+func (r CashbackRule) Calculate(amount int) int {
+	cashback := amount * r.Rate / 10000
+	if r.Cap > 0 && cashback > r.Cap {
+		cashback = r.Cap
+	}
+
+	return cashback
+}
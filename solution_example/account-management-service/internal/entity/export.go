@@ -0,0 +1,47 @@
+package entity
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// OperationExportParams describe the export of the operations created in the [From, To) range
+type OperationExportParams struct {
+	From time.Time
+	To   time.Time
+	// AccountId and OperationTypes limit the export, all accounts and operation types are exported by default
+	AccountId      int
+	OperationTypes []string
+	// Format, CSVDelimiter and CSVBOM are the same as those of the reports
+	Format       string
+	CSVDelimiter string
+	CSVBOM       bool
+}
+
+// Filename is the name the export is stored with, exports limited to the operation types are told apart
+// by the hash of the types
+func (p OperationExportParams) Filename() string {
+	name := fmt.Sprintf("operations_%s_%s", p.From.Format(reportDateLayout), p.To.Format(reportDateLayout))
+	if p.AccountId != 0 {
+		name += fmt.Sprintf("_account_%d", p.AccountId)
+	}
+	if len(p.OperationTypes) > 0 {
+		hash := sha256.Sum256([]byte(strings.Join(p.OperationTypes, ",")))
+		name += "_" + hex.EncodeToString(hash[:4])
+	}
+
+	format := p.Format
+	if format == "" {
+		format = ReportFormatCSV
+	}
+	return name + csvSuffix("_", p.CSVDelimiter, p.CSVBOM) + "." + format
+}
+
+// ExportedOperation is the operation with the name of its product, the name is empty for operations without product
+type ExportedOperation struct {
+	Operation
+	Product string `db:"product"`
+}
@@ -0,0 +1,42 @@
+package entity
+
+import "time"
+
+// FeeRule describes the commission charged for an operation. ProductId and AccountType narrow the rule down,
+// nil means any. The fee is Rate basis points (100 is 1%) of the amount plus Fixed, clamped to MinFee and MaxFee,
+// zero MaxFee means no upper bound. Operations up to FreeUpTo are not charged at all
+type FeeRule struct {
+	Id            int       `db:"id"`
+	OperationType string    `db:"operation_type"`
+	ProductId     *int      `db:"product_id"`
+	AccountType   *string   `db:"account_type"`
+	Rate          int       `db:"rate"`
+	Fixed         int       `db:"fixed"`
+	MinFee        int       `db:"min_fee"`
+	MaxFee        int       `db:"max_fee"`
+	FreeUpTo      int       `db:"free_up_to"`
+	CreatedAt     time.Time `db:"created_at"`
+}
+
+// Calculate returns the fee for the operation amount, percentage part is rounded down
+func (r FeeRule) Calculate(amount int) int {
+	if amount <= r.FreeUpTo {
+		return 0
+	}
+
+	fee := amount*r.Rate/10000 + r.Fixed
+	if fee < r.MinFee {
+		fee = r.MinFee
+	}
+	if r.MaxFee > 0 && fee > r.MaxFee {
+		fee = r.MaxFee
+	}
+
+	return fee
+}
+
+// Fee is the commission collected into the company fee account together with an operation
+type Fee struct {
+	AccountId int
+	Amount    int
+}
@@ -0,0 +1,30 @@
+package entity
+
+import "time"
+
+// GroupMember is a user allowed to use a shared account. MonthlyLimit caps the money the member
+// reserves from the account during a calendar month, zero means no limit
+type GroupMember struct {
+	AccountId    int       `db:"account_id"`
+	UserId       int       `db:"user_id"`
+	Role         string    `db:"role"`
+	MonthlyLimit int       `db:"monthly_limit"`
+	CreatedAt    time.Time `db:"created_at"`
+}
+
+const (
+	// GroupRoleOwner manages members and may spend
+	GroupRoleOwner   = "owner"
+	GroupRoleSpender = "spender"
+	// GroupRoleViewer may only see the members and the history
+	GroupRoleViewer = "viewer"
+)
+
+// MemberSpending is the money reserved from a shared account by a single member,
+// net of the refunded orders
+type MemberSpending struct {
+	UserId     int `db:"user_id"`
+	Operations int `db:"operations"`
+	Spent      int `db:"spent"`
+	MonthSpent int `db:"month_spent"`
+}
@@ -0,0 +1,96 @@
+package entity
+
+import "time"
+
+// InstallmentPlan splits the payment for an order into parts reserved on their due dates.
+// All the paid parts are kept in a single reservation of the order, so the order is captured
+// or cancelled as usual once the plan is paid
+type InstallmentPlan struct {
+	Id         int       `db:"id"`
+	AccountId  int       `db:"account_id"`
+	ProductId  int       `db:"product_id"`
+	OrderId    int       `db:"order_id"`
+	Amount     int       `db:"amount"`
+	Period     string    `db:"period"`
+	Status     string    `db:"status"`
+	PaidAmount int       `db:"paid_amount"`
+	CreatedAt  time.Time `db:"created_at"`
+}
+
+// OutstandingAmount returns the part of the order amount which is not paid yet
+func (p InstallmentPlan) OutstandingAmount() int {
+	return p.Amount - p.PaidAmount
+}
+
+const (
+	InstallmentPlanStatusActive = "active"
+	InstallmentPlanStatusPaid   = "paid"
+	// InstallmentPlanStatusDefaulted means a part was not paid after all the attempts and the order is kept for a manual decision
+	InstallmentPlanStatusDefaulted = "defaulted"
+	InstallmentPlanStatusCancelled = "cancelled"
+)
+
+type InstallmentPart struct {
+	Id            int        `db:"id"`
+	PlanId        int        `db:"plan_id"`
+	Number        int        `db:"number"`
+	Amount        int        `db:"amount"`
+	DueAt         time.Time  `db:"due_at"`
+	Status        string     `db:"status"`
+	Attempts      int        `db:"attempts"`
+	NextAttemptAt time.Time  `db:"next_attempt_at"`
+	Error         string     `db:"error"`
+	PaidAt        *time.Time `db:"paid_at"`
+}
+
+const (
+	InstallmentPartStatusPending   = "pending"
+	InstallmentPartStatusPaid      = "paid"
+	InstallmentPartStatusCancelled = "cancelled"
+)
+
+// InstallmentObligation is an upcoming or overdue part of an account installment plan
+type InstallmentObligation struct {
+	PlanId    int       `db:"plan_id"`
+	OrderId   int       `db:"order_id"`
+	ProductId int       `db:"product_id"`
+	Number    int       `db:"number"`
+	Amount    int       `db:"amount"`
+	DueAt     time.Time `db:"due_at"`
+	Attempts  int       `db:"attempts"`
+}
+
+// DueInstallmentPart is a part whose due date has come, with what is needed to check its limits and fee
+type DueInstallmentPart struct {
+	Id        int `db:"id"`
+	AccountId int `db:"account_id"`
+	ProductId int `db:"product_id"`
+	Amount    int `db:"amount"`
+}
+
+// MissedInstallmentPolicy tells what to do when a part cannot be reserved on its due date:
+// it is retried every RetryInterval, and after MaxAttempts the order is either cancelled
+// with the paid parts returned to the account, or the plan is marked as defaulted
+type MissedInstallmentPolicy struct {
+	MaxAttempts   int
+	RetryInterval time.Duration
+	CancelOrder   bool
+}
+
+// SplitInstallments returns the amounts of count parts, the first one is first and the rest of the amount
+// is split evenly with the remainder added to the last part. Zero first means an even split
+func SplitInstallments(amount, first, count int) []int {
+	if first == 0 {
+		first = amount/count + amount%count
+	}
+
+	parts := make([]int, count)
+	parts[0] = first
+	rest := amount - first
+	for i := 1; i < count; i++ {
+		parts[i] = rest / (count - 1)
+	}
+	parts[count-1] += rest % (count - 1)
+
+	return parts
+}
@@ -0,0 +1,30 @@
+package entity
+
+import "time"
+
+type LimitRule struct {
+	Id            int       `db:"id"`
+	Scope         string    `db:"scope"`
+	AccountType   *string   `db:"account_type"`
+	AccountId     *int      `db:"account_id"`
+	OperationType string    `db:"operation_type"`
+	LimitType     string    `db:"limit_type"`
+	Value         int       `db:"value"`
+	WindowSeconds int       `db:"window_seconds"`
+	CreatedAt     time.Time `db:"created_at"`
+}
+
+const (
+	LimitScopeGlobal      = "global"
+	LimitScopeAccountType = "account_type"
+	LimitScopeAccount     = "account"
+)
+
+const (
+	// LimitTypeMaxAmount limits the amount of a single operation
+	LimitTypeMaxAmount = "max_amount"
+	// LimitTypeMaxTotal limits the sum of operations within the rolling window
+	LimitTypeMaxTotal = "max_total"
+	// LimitTypeMaxCount limits the number of operations within the rolling window
+	LimitTypeMaxCount = "max_count"
+)
@@ -8,10 +8,13 @@ type Operation struct {
 	Amount        int       `db:"amount"`
 	OperationType string    `db:"operation_type"`
 	CreatedAt     time.Time `db:"created_at"`
+	WalletType    string    `db:"wallet_type"`
 
 	ProductId   *int   `db:"product_id"`
 	OrderId     *int   `db:"order_id"`
 	Description string `db:"description"`
+	// UserId is the member who spent the money of a shared account
+	UserId *int `db:"user_id"`
 }
 
 // TODO: make operation_type a distinct type with it's own table
@@ -24,4 +27,44 @@ const (
 	OperationTypeReservation  = "reservation"
 	OperationTypeRevenue      = "revenue"
 	OperationTypeRefund       = "refund"
+	// OperationTypeFee is the commission paid by the account, OperationTypeFeeIncome is the same commission
+	// received by the company fee account
+	OperationTypeFee       = "fee"
+	OperationTypeFeeIncome = "fee_income"
+	// OperationTypeRevenueRefund returns money of an order whose revenue has already been recognized
+	OperationTypeRevenueRefund = "revenue_refund"
+	// OperationTypeCashback is bonus credit granted for an order, OperationTypeCashbackReversal takes it back
+	// when the order is refunded
+	OperationTypeCashback         = "cashback"
+	OperationTypeCashbackReversal = "cashback_reversal"
+	// OperationTypeAdjustmentCredit and OperationTypeAdjustmentDebit are manual balance corrections made by support
+	OperationTypeAdjustmentCredit = "adjustment_credit"
+	OperationTypeAdjustmentDebit  = "adjustment_debit"
 )
+
+// ProductRevenue is the revenue of a single product split by wallets which funded it.
+// Amount is the net revenue: Revenue less Refunded and Cashback
+type ProductRevenue struct {
+	Product     string `db:"product"`
+	Amount      int    `db:"amount"`
+	MainAmount  int    `db:"main_amount"`
+	BonusAmount int    `db:"bonus_amount"`
+	// Orders is the number of orders whose revenue was recognized
+	Orders   int `db:"orders"`
+	Revenue  int `db:"revenue"`
+	Refunded int `db:"refunded"`
+	// Cashback is the cashback granted on the orders less the reversed one
+	Cashback int `db:"cashback"`
+}
+
+// AccountProductRevenue is the revenue of the product paid by the account
+type AccountProductRevenue struct {
+	AccountId int `db:"account_id"`
+	ProductRevenue
+}
+
+// PeriodProductRevenue is the revenue of the product during the period starting at Period
+type PeriodProductRevenue struct {
+	Period time.Time `db:"period"`
+	ProductRevenue
+}
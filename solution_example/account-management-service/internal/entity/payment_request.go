@@ -0,0 +1,23 @@
+package entity
+
+import "time"
+
+// PaymentRequest is a request of the requester account to be paid by the payer account
+type PaymentRequest struct {
+	Id                 int        `db:"id"`
+	RequesterAccountId int        `db:"requester_account_id"`
+	PayerAccountId     int        `db:"payer_account_id"`
+	Amount             int        `db:"amount"`
+	Description        string     `db:"description"`
+	Status             string     `db:"status"`
+	ExpiresAt          time.Time  `db:"expires_at"`
+	ResolvedAt         *time.Time `db:"resolved_at"`
+	CreatedAt          time.Time  `db:"created_at"`
+}
+
+const (
+	PaymentRequestStatusPending  = "pending"
+	PaymentRequestStatusPaid     = "paid"
+	PaymentRequestStatusDeclined = "declined"
+	PaymentRequestStatusExpired  = "expired"
+)
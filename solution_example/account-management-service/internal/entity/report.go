@@ -0,0 +1,143 @@
+package entity
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Versions of the revenue report schema. Without the version the legacy report is made if it can be,
+// so the requests made before the versions existed get the same file. Other reports are of the current version
+const (
+	// ReportVersionLegacy is the original headerless csv report: a row per product with the product name
+	// and the gross revenue recognized during the period, without refunds, cashback and fees subtracted
+	ReportVersionLegacy = 1
+	// ReportVersionCurrent is the report with the header, order counts, refunds and the total row
+	ReportVersionCurrent = 2
+)
+
+// Modes of the revenue report
+const (
+	// ReportModeProducts is the summary by product
+	ReportModeProducts = "products"
+	// ReportModeAccounts breaks the revenue of every product down by accounts
+	ReportModeAccounts = "accounts"
+)
+
+// Granularities of the time series report, the names are the date_trunc fields of postgres
+const (
+	ReportGranularityDay     = "day"
+	ReportGranularityWeek    = "week"
+	ReportGranularityMonth   = "month"
+	ReportGranularityQuarter = "quarter"
+)
+
+// Formats of the report file, the names are the file extensions
+const (
+	ReportFormatCSV     = "csv"
+	ReportFormatXLSX    = "xlsx"
+	ReportFormatJSON    = "json"
+	ReportFormatParquet = "parquet"
+)
+
+// Delimiters of the csv report
+const (
+	ReportDelimiterComma     = "comma"
+	ReportDelimiterSemicolon = "semicolon"
+	ReportDelimiterTab       = "tab"
+)
+
+// reportDateLayout is the layout of the report range in keys and file names
+const reportDateLayout = "20060102"
+
+// ReportParams describe the report to generate, jobs with the same params produce the same report.
+// The report covers the [From, To) range, Month and Year are the shorthand for the range of the whole month
+type ReportParams struct {
+	Month   int
+	Year    int
+	From    time.Time
+	To      time.Time
+	Version int
+	Mode    string
+	// Granularity makes the products report the time series of every product by the periods of the granularity
+	Granularity string
+	// ProductIds and AccountIds limit the accounts report to the given products and accounts
+	ProductIds []int
+	AccountIds []int
+	// Format is the file format, csv by default. CSVDelimiter and CSVBOM tune the csv file
+	// for the spreadsheets expecting the semicolon or the byte order mark
+	Format       string
+	CSVDelimiter string
+	CSVBOM       bool
+}
+
+// Key identifies the report, identical requests are deduplicated by it
+func (p ReportParams) Key() string {
+	key := fmt.Sprintf("revenue:v%d:%d:%d", p.Version, p.Year, p.Month)
+	if p.Month == 0 {
+		key = fmt.Sprintf("revenue:v%d:%s:%s", p.Version, p.From.Format(reportDateLayout), p.To.Format(reportDateLayout))
+	}
+	if p.Granularity != "" {
+		key += ":" + p.Granularity
+	}
+	if p.Mode == ReportModeAccounts {
+		key += fmt.Sprintf(":%s:p%s:a%s", p.Mode, joinIds(p.ProductIds), joinIds(p.AccountIds))
+	}
+	if p.extension() != ReportFormatCSV {
+		key += ":" + p.extension()
+	}
+	return key + csvSuffix(":", p.CSVDelimiter, p.CSVBOM)
+}
+
+// Filename is the name the report is stored with, the legacy report keeps its original name.
+// Reports filtered by products or accounts are told apart by the hash of the filters
+func (p ReportParams) Filename() string {
+	name := fmt.Sprintf("report_%d_%d", p.Month, p.Year)
+	if p.Month == 0 {
+		name = fmt.Sprintf("report_%s_%s", p.From.Format(reportDateLayout), p.To.Format(reportDateLayout))
+	}
+
+	switch {
+	case p.Mode == ReportModeAccounts && (len(p.ProductIds) > 0 || len(p.AccountIds) > 0):
+		hash := sha256.Sum256([]byte(p.Key()))
+		name += "_accounts_" + hex.EncodeToString(hash[:4])
+	case p.Mode == ReportModeAccounts:
+		name += "_accounts"
+	case p.Granularity != "":
+		name += fmt.Sprintf("_%s_v%d", p.Granularity, p.Version)
+	case p.Version != ReportVersionLegacy:
+		name += fmt.Sprintf("_v%d", p.Version)
+	}
+
+	return name + csvSuffix("_", p.CSVDelimiter, p.CSVBOM) + "." + p.extension()
+}
+
+// csvSuffix tells apart the csv files with other than the default options
+func csvSuffix(sep, delimiter string, bom bool) string {
+	var suffix string
+	if delimiter != "" && delimiter != ReportDelimiterComma {
+		suffix += sep + delimiter
+	}
+	if bom {
+		suffix += sep + "bom"
+	}
+	return suffix
+}
+
+func (p ReportParams) extension() string {
+	if p.Format == "" {
+		return ReportFormatCSV
+	}
+	return p.Format
+}
+
+func joinIds(ids []int) string {
+	s := make([]string, 0, len(ids))
+	for _, id := range ids {
+		s = append(s, strconv.Itoa(id))
+	}
+	return strings.Join(s, ",")
+}
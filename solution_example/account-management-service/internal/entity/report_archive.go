@@ -0,0 +1,30 @@
+package entity
+
+import "time"
+
+// ArchivedReport is the file in the report storage. The files found in the storage which have not been
+// uploaded by the service, e.g. before the archive existed, have the unknown kind and no metadata
+type ArchivedReport struct {
+	Id         int        `db:"id"`
+	Filename   string     `db:"filename"`
+	Kind       string     `db:"kind"`
+	PeriodFrom *time.Time `db:"period_from"`
+	PeriodTo   *time.Time `db:"period_to"`
+	Format     string     `db:"format"`
+	CreatedBy  *int       `db:"created_by"`
+	Size       *int64     `db:"size"`
+	CreatedAt  time.Time  `db:"created_at"`
+}
+
+const (
+	ArchivedReportKindReport  = "report"
+	ArchivedReportKindExport  = "export"
+	ArchivedReportKindUnknown = "unknown"
+)
+
+// ArchivedReportFilter selects the archived reports, zero fields are not filtered by
+type ArchivedReportFilter struct {
+	Kind          string
+	CreatedBy     int
+	CreatedBefore time.Time
+}
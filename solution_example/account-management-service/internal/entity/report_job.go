@@ -0,0 +1,29 @@
+package entity
+
+import "time"
+
+// ReportJob generates the report in the background, failed attempts are retried with backoff
+type ReportJob struct {
+	Id         int          `db:"id"`
+	Params     ReportParams `db:"params"`
+	Status     string       `db:"status"`
+	Progress   int          `db:"progress"`
+	Filename   string       `db:"filename"`
+	Link       string       `db:"link"`
+	Error      string       `db:"error"`
+	Attempts   int          `db:"attempts"`
+	NextRunAt  time.Time    `db:"next_run_at"`
+	CreatedBy  int          `db:"created_by"`
+	CreatedAt  time.Time    `db:"created_at"`
+	UpdatedAt  time.Time    `db:"updated_at"`
+	FinishedAt *time.Time   `db:"finished_at"`
+}
+
+const (
+	ReportJobStatusPending = "pending"
+	ReportJobStatusRunning = "running"
+	ReportJobStatusDone    = "done"
+	ReportJobStatusFailed  = "failed"
+	// ReportJobStatusExpired is the done job whose report is deleted from the archive
+	ReportJobStatusExpired = "expired"
+)
@@ -0,0 +1,32 @@
+package entity
+
+import "time"
+
+// ReportLink is a time-limited link to download the stored report file without authorization
+type ReportLink struct {
+	Id        int        `db:"id"`
+	Filename  string     `db:"filename"`
+	ExpiresAt time.Time  `db:"expires_at"`
+	OneTime   bool       `db:"one_time"`
+	UsedAt    *time.Time `db:"used_at"`
+	CreatedAt time.Time  `db:"created_at"`
+}
+
+// ReportLinkAccess is an attempt to download the report by the link, successful or not
+type ReportLinkAccess struct {
+	Id        int       `db:"id"`
+	LinkId    int       `db:"link_id"`
+	IP        string    `db:"ip"`
+	UserAgent string    `db:"user_agent"`
+	Result    string    `db:"result"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+const (
+	ReportLinkAccessOK               = "ok"
+	ReportLinkAccessInvalidSignature = "invalid_signature"
+	ReportLinkAccessNotFound         = "not_found"
+	ReportLinkAccessExpired          = "expired"
+	ReportLinkAccessUsed             = "used"
+	ReportLinkAccessError            = "error"
+)
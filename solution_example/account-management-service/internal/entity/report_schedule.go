@@ -0,0 +1,77 @@
+package entity
+
+import "time"
+
+// ReportSchedule generates the report on the cron schedule and delivers it through the channels.
+// The report covers the period preceding the scheduled time, e.g. the previous month
+type ReportSchedule struct {
+	Name     string
+	Cron     string
+	Period   string
+	Params   ReportParams
+	Channels []ReportChannel
+}
+
+const (
+	ReportSchedulePeriodDay   = "previous_day"
+	ReportSchedulePeriodWeek  = "previous_week"
+	ReportSchedulePeriodMonth = "previous_month"
+)
+
+// ReportChannel delivers the report to the recipients, e.g. by email. Attach sends the file itself
+// in addition to the link, the link expires while the file does not
+type ReportChannel struct {
+	Type       string
+	Recipients []string
+	Attach     bool
+}
+
+// Key tells the channels of the schedule apart, delivered channels are not delivered again when the run is retried
+func (c ReportChannel) Key() string {
+	key := c.Type
+	for _, recipient := range c.Recipients {
+		key += ":" + recipient
+	}
+	return key
+}
+
+// ReportDelivery is the report sent through the channel, Data is set only for the channels with Attach
+type ReportDelivery struct {
+	Schedule   string
+	From       time.Time
+	To         time.Time
+	Filename   string
+	Link       string
+	Data       []byte
+	Recipients []string
+}
+
+// ReportScheduleRun is the run of the schedule for the scheduled time, there is only one run of each time.
+// Link is the storage link of the uploaded report, a new link is shared on every delivery
+type ReportScheduleRun struct {
+	Id          int        `db:"id"`
+	Schedule    string     `db:"schedule"`
+	ScheduledAt time.Time  `db:"scheduled_at"`
+	Status      string     `db:"status"`
+	Attempts    int        `db:"attempts"`
+	Filename    string     `db:"filename"`
+	Link        string     `db:"link"`
+	Delivered   []string   `db:"delivered"`
+	Error       string     `db:"error"`
+	StartedAt   time.Time  `db:"started_at"`
+	FinishedAt  *time.Time `db:"finished_at"`
+}
+
+const (
+	ReportScheduleRunStatusRunning = "running"
+	ReportScheduleRunStatusDone    = "done"
+	ReportScheduleRunStatusFailed  = "failed"
+)
+
+// ReportScheduleRetryPolicy tells when the run may be claimed again: the failed run is retried after
+// RetryInterval until it has made MaxAttempts, the running one is considered dead after StaleAfter
+type ReportScheduleRetryPolicy struct {
+	MaxAttempts   int
+	RetryInterval time.Duration
+	StaleAfter    time.Duration
+}
@@ -7,4 +7,13 @@ type Reservation struct {
 	OrderId   int `db:"order_id"`
 	Amount    int `db:"amount"`
 	CreatedAt int `db:"created_at"`
+	// UserId is the member who made the reservation from a shared account
+	UserId *int `db:"user_id"`
+}
+
+// ReservationFund is the part of the reservation amount taken from a single wallet
+type ReservationFund struct {
+	ReservationId int    `db:"reservation_id"`
+	WalletType    string `db:"wallet_type"`
+	Amount        int    `db:"amount"`
 }
@@ -0,0 +1,36 @@
+package entity
+
+import "time"
+
+type ScheduledTransfer struct {
+	Id            int       `db:"id"`
+	FromAccountId int       `db:"from_account_id"`
+	ToAccountId   int       `db:"to_account_id"`
+	Amount        int       `db:"amount"`
+	ExecuteAt     time.Time `db:"execute_at"`
+	Repeat        string    `db:"repeat"`
+	Status        string    `db:"status"`
+	CreatedAt     time.Time `db:"created_at"`
+}
+
+const (
+	TransferRepeatNone   = ""
+	TransferRepeatWeekly = "weekly"
+)
+
+const (
+	// ScheduledTransferStatusPending is kept by repeating transfers between runs
+	ScheduledTransferStatusPending   = "pending"
+	ScheduledTransferStatusSucceeded = "succeeded"
+	ScheduledTransferStatusFailed    = "failed"
+	ScheduledTransferStatusCancelled = "cancelled"
+)
+
+// ScheduledTransferRun is the result of a single execution of the scheduled transfer
+type ScheduledTransferRun struct {
+	Id                  int       `db:"id"`
+	ScheduledTransferId int       `db:"scheduled_transfer_id"`
+	Status              string    `db:"status"`
+	Error               string    `db:"error"`
+	CreatedAt           time.Time `db:"created_at"`
+}
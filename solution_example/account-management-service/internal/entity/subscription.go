@@ -0,0 +1,61 @@
+package entity
+
+import "time"
+
+type Subscription struct {
+	Id             int        `db:"id"`
+	AccountId      int        `db:"account_id"`
+	ProductId      int        `db:"product_id"`
+	Amount         int        `db:"amount"`
+	Period         string     `db:"period"`
+	Status         string     `db:"status"`
+	NextChargeAt   time.Time  `db:"next_charge_at"`
+	NextAttemptAt  time.Time  `db:"next_attempt_at"`
+	FailedAttempts int        `db:"failed_attempts"`
+	GraceUntil     *time.Time `db:"grace_until"`
+	CreatedAt      time.Time  `db:"created_at"`
+}
+
+const (
+	SubscriptionPeriodDay   = "day"
+	SubscriptionPeriodWeek  = "week"
+	SubscriptionPeriodMonth = "month"
+)
+
+const (
+	SubscriptionStatusActive = "active"
+	// SubscriptionStatusPastDue means the last charge failed and is being retried within the grace period
+	SubscriptionStatusPastDue = "past_due"
+	SubscriptionStatusPaused  = "paused"
+	// SubscriptionStatusSuspended means the grace period has expired, the subscription may be resumed
+	SubscriptionStatusSuspended = "suspended"
+	SubscriptionStatusCancelled = "cancelled"
+)
+
+// NextPeriodStart returns the start of the period following the one started at t
+func NextPeriodStart(t time.Time, period string) time.Time {
+	switch period {
+	case SubscriptionPeriodDay:
+		return t.AddDate(0, 0, 1)
+	case SubscriptionPeriodWeek:
+		return t.AddDate(0, 0, 7)
+	default:
+		return t.AddDate(0, 1, 0)
+	}
+}
+
+type SubscriptionCharge struct {
+	Id             int       `db:"id"`
+	SubscriptionId int       `db:"subscription_id"`
+	PeriodStart    time.Time `db:"period_start"`
+	Amount         int       `db:"amount"`
+	Status         string    `db:"status"`
+	Attempt        int       `db:"attempt"`
+	Error          string    `db:"error"`
+	CreatedAt      time.Time `db:"created_at"`
+}
+
+const (
+	SubscriptionChargeSucceeded = "succeeded"
+	SubscriptionChargeFailed    = "failed"
+)
@@ -7,4 +7,14 @@ type User struct {
 	Username  string    `db:"username"`
 	Password  string    `db:"password"`
 	CreatedAt time.Time `db:"created_at"`
+	Role      string    `db:"role"`
 }
+
+const (
+	UserRoleUser  = "user"
+	UserRoleAdmin = "admin"
+	// UserRoleIdentity is the role of the identity service, the only source of verification levels
+	UserRoleIdentity = "identity"
+	// UserRoleApprover approves operations submitted by other users
+	UserRoleApprover = "approver"
+)
@@ -0,0 +1,68 @@
+package entity
+
+import "time"
+
+const (
+	VerificationLevelNone  = "none"
+	VerificationLevelBasic = "basic"
+	VerificationLevelFull  = "full"
+)
+
+// VerificationLevels are ordered from the lowest level to the highest one
+var VerificationLevels = []string{
+	VerificationLevelNone,
+	VerificationLevelBasic,
+	VerificationLevelFull,
+}
+
+// VerificationPolicy allows the operation to accounts with the level. An operation is forbidden
+// at the levels it has no policy for. Zero limits mean no limit
+type VerificationPolicy struct {
+	Level         string    `db:"level"`
+	OperationType string    `db:"operation_type"`
+	MaxAmount     int       `db:"max_amount"`
+	MaxTotal      int       `db:"max_total"`
+	WindowSeconds int       `db:"window_seconds"`
+	UpdatedAt     time.Time `db:"updated_at"`
+}
+
+// Allows reports whether the policy allows an operation of amount when total has already been
+// spent by the same operation type within the policy window
+func (p VerificationPolicy) Allows(amount, total int) bool {
+	if p.MaxAmount > 0 && amount > p.MaxAmount {
+		return false
+	}
+	if p.MaxTotal > 0 && total+amount > p.MaxTotal {
+		return false
+	}
+	return true
+}
+
+// VerificationCheck is the part of the verification check which depends on the amounts already spent
+// within the policy windows, it is done once the account is locked. Policies are the ones of the account
+// level and the higher levels, from the lowest level to the highest one
+type VerificationCheck struct {
+	Level    string
+	Policies []VerificationPolicy
+}
+
+// RequiredLevel returns the lowest level whose policy allows an operation of amount, totals are the amounts
+// already spent by window seconds. False is returned if no level allows the operation
+func (c VerificationCheck) RequiredLevel(amount int, totals map[int]int) (string, bool) {
+	for _, policy := range c.Policies {
+		if policy.Allows(amount, totals[policy.WindowSeconds]) {
+			return policy.Level, true
+		}
+	}
+	return "", false
+}
+
+type VerificationChange struct {
+	Id        int       `db:"id"`
+	AccountId int       `db:"account_id"`
+	OldLevel  string    `db:"old_level"`
+	NewLevel  string    `db:"new_level"`
+	Reference string    `db:"reference"`
+	ChangedBy *int      `db:"changed_by"`
+	CreatedAt time.Time `db:"created_at"`
+}
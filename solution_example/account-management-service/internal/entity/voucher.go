@@ -0,0 +1,41 @@
+package entity
+
+import "time"
+
+// VoucherCampaign is a batch of voucher codes sharing the same amount, wallet and expiry.
+// Every code can be redeemed MaxUses times, but only once by the same account
+type VoucherCampaign struct {
+	Id        int       `db:"id"`
+	Name      string    `db:"name"`
+	Amount    int       `db:"amount"`
+	Wallet    string    `db:"wallet_type"`
+	MaxUses   int       `db:"max_uses"`
+	ExpiresAt time.Time `db:"expires_at"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+type Voucher struct {
+	Id         int    `db:"id"`
+	CampaignId int    `db:"campaign_id"`
+	Code       string `db:"code"`
+	UsedCount  int    `db:"used_count"`
+}
+
+type VoucherRedemption struct {
+	Id        int       `db:"id"`
+	VoucherId int       `db:"voucher_id"`
+	AccountId int       `db:"account_id"`
+	Amount    int       `db:"amount"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+// VoucherCampaignReport compares the money which can be redeemed by the campaign codes with the money already redeemed
+type VoucherCampaignReport struct {
+	Campaign        VoucherCampaign
+	IssuedCodes     int
+	IssuedAmount    int
+	RedeemedCodes   int
+	Redemptions     int
+	RedeemedAmount  int
+	RemainingAmount int
+}
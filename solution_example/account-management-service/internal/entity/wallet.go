@@ -0,0 +1,14 @@
+package entity
+
+type Wallet struct {
+	AccountId int    `db:"account_id"`
+	Type      string `db:"wallet_type"`
+	Balance   int    `db:"balance"`
+}
+
+const (
+	// WalletTypeMain holds real money, the only wallet which can be withdrawn or transferred
+	WalletTypeMain = "main"
+	// WalletTypeBonus holds promotional credit, which can only be spent on products
+	WalletTypeBonus = "bonus"
+)
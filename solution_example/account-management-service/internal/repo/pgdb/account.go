@@ -7,7 +7,6 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"github.com/Masterminds/squirrel"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
 	log "github.com/sirupsen/logrus"
@@ -21,10 +20,11 @@ func NewAccountRepo(pg *postgres.Postgres) *AccountRepo {
 	return &AccountRepo{pg}
 }
 
-func (r *AccountRepo) CreateAccount(ctx context.Context) (int, error) {
+func (r *AccountRepo) CreateAccount(ctx context.Context, accountType string) (int, error) {
 	sql, args, _ := r.Builder.
 		Insert("accounts").
-		Values(squirrel.Expr("DEFAULT")).
+		Columns("type").
+		Values(accountType).
 		Suffix("RETURNING id").
 		ToSql()
 
@@ -46,9 +46,10 @@ func (r *AccountRepo) CreateAccount(ctx context.Context) (int, error) {
 
 func (r *AccountRepo) GetAccountById(ctx context.Context, id int) (entity.Account, error) {
 	sql, args, _ := r.Builder.
-		Select("*").
+		Select("accounts.id", "COALESCE(wallets.balance, 0)", "accounts.created_at", "accounts.type", "accounts.status", "accounts.verification_level").
 		From("accounts").
-		Where("id = ?", id).
+		LeftJoin("wallets on wallets.account_id = accounts.id and wallets.wallet_type = ?", entity.WalletTypeMain).
+		Where("accounts.id = ?", id).
 		ToSql()
 
 	var account entity.Account
@@ -56,6 +57,9 @@ func (r *AccountRepo) GetAccountById(ctx context.Context, id int) (entity.Accoun
 		&account.Id,
 		&account.Balance,
 		&account.CreatedAt,
+		&account.Type,
+		&account.Status,
+		&account.VerificationLevel,
 	)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
@@ -67,169 +71,282 @@ func (r *AccountRepo) GetAccountById(ctx context.Context, id int) (entity.Accoun
 	return account, nil
 }
 
-func (r *AccountRepo) Deposit(ctx context.Context, id, amount int) error {
+func (r *AccountRepo) GetWalletsByAccountId(ctx context.Context, accountId int) ([]entity.Wallet, error) {
+	sql, args, _ := r.Builder.
+		Select("account_id", "wallet_type", "balance").
+		From("wallets").
+		Where("account_id = ?", accountId).
+		OrderBy("wallet_type").
+		ToSql()
+
+	rows, err := r.Pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("AccountRepo.GetWalletsByAccountId - r.Pool.Query: %v", err)
+	}
+	defer rows.Close()
+
+	var wallets []entity.Wallet
+	for rows.Next() {
+		var wallet entity.Wallet
+		err = rows.Scan(&wallet.AccountId, &wallet.Type, &wallet.Balance)
+		if err != nil {
+			return nil, fmt.Errorf("AccountRepo.GetWalletsByAccountId - rows.Scan: %v", err)
+		}
+		wallets = append(wallets, wallet)
+	}
+
+	return wallets, nil
+}
+
+func (r *AccountRepo) Deposit(ctx context.Context, id, amount int, walletType string) error {
 	tx, err := r.Pool.Begin(ctx)
 	if err != nil {
 		return fmt.Errorf("AccountRepo.Deposit - r.Pool.Begin: %v", err)
 	}
 	defer func() { _ = tx.Rollback(ctx) }()
 
-	sql, args, _ := r.Builder.
-		Update("accounts").
-		Set("balance", squirrel.Expr("balance + ?", amount)).
-		Where("id = ?", id).
-		ToSql()
+	err = depositMoney(ctx, tx, r.Builder, id, amount, walletType)
+	if err != nil {
+		if isLedgerError(err) {
+			return err
+		}
+		return fmt.Errorf("AccountRepo.Deposit - depositMoney: %v", err)
+	}
 
-	_, err = tx.Exec(ctx, sql, args...)
+	err = tx.Commit(ctx)
 	if err != nil {
-		return fmt.Errorf("AccountRepo.Deposit - tx.Exec: %v", err)
+		return fmt.Errorf("AccountRepo.Deposit - tx.Commit: %v", err)
 	}
 
-	sql, args, _ = r.Builder.
-		Insert("operations").
-		Columns("account_id", "amount", "operation_type").
-		Values(id, amount, entity.OperationTypeDeposit).
-		ToSql()
+	return nil
+}
 
-	_, err = tx.Exec(ctx, sql, args...)
+// Withdraw takes money from the main wallet only, bonus credit cannot be withdrawn.
+// The fee is taken from the main wallet on top of the amount
+func (r *AccountRepo) Withdraw(ctx context.Context, id, amount int, userId *int, fee entity.Fee, limits []entity.LimitRule, verification entity.VerificationCheck) error {
+	tx, err := r.Pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("AccountRepo.Withdraw - r.Pool.Begin: %v", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	err = withdrawMoney(ctx, tx, r.Builder, id, amount, userId, fee, limits, verification)
 	if err != nil {
-		return fmt.Errorf("AccountRepo.Deposit - tx.Exec: %v", err)
+		if isLedgerError(err) {
+			return err
+		}
+		return fmt.Errorf("AccountRepo.Withdraw - withdrawMoney: %v", err)
 	}
 
 	err = tx.Commit(ctx)
 	if err != nil {
-		return fmt.Errorf("AccountRepo.Deposit - tx.Commit: %v", err)
+		return fmt.Errorf("AccountRepo.Withdraw - tx.Commit: %v", err)
 	}
 
 	return nil
 }
 
-func (r *AccountRepo) Withdraw(ctx context.Context, id, amount int) error {
+// Transfer moves money between main wallets, bonus credit cannot be transferred
+func (r *AccountRepo) Transfer(ctx context.Context, from, to, amount int, userId *int, fee entity.Fee, limits []entity.LimitRule, verification entity.VerificationCheck) error {
 	tx, err := r.Pool.Begin(ctx)
 	if err != nil {
-		return fmt.Errorf("AccountRepo.Withdraw - r.Pool.Begin: %v", err)
+		return fmt.Errorf("AccountRepo.Transfer - r.Pool.Begin: %v", err)
 	}
 	defer func() { _ = tx.Rollback(ctx) }()
 
-	// check if account has enough balance to withdraw
-	sql, args, _ := r.Builder.
-		Select("balance").
-		From("accounts").
-		Where("id = ?", id).
-		ToSql()
-
-	var balance int
-	err = tx.QueryRow(ctx, sql, args...).Scan(&balance)
+	err = transferMoney(ctx, tx, r.Builder, from, to, amount, userId, fee, limits, verification, "")
 	if err != nil {
-		return fmt.Errorf("AccountRepo.Withdraw - tx.QueryRow: %v", err)
+		if isLedgerError(err) {
+			return err
+		}
+		return fmt.Errorf("AccountRepo.Transfer - transferMoney: %v", err)
 	}
 
-	if balance < amount {
-		return repoerrs.ErrNotEnoughBalance
+	err = tx.Commit(ctx)
+	if err != nil {
+		return fmt.Errorf("AccountRepo.Transfer - tx.Commit: %v", err)
 	}
 
-	sql, args, _ = r.Builder.
-		Update("accounts").
-		Set("balance", squirrel.Expr("balance - ?", amount)).
-		Where("id = ?", id).
-		ToSql()
+	return nil
+}
 
-	_, err = tx.Exec(ctx, sql, args...)
+func (r *AccountRepo) ChangeAccountStatus(ctx context.Context, change entity.AccountStatusChange) error {
+	tx, err := r.Pool.Begin(ctx)
 	if err != nil {
-		return fmt.Errorf("AccountRepo.Withdraw - tx.Exec: %v", err)
+		return fmt.Errorf("AccountRepo.ChangeAccountStatus - r.Pool.Begin: %v", err)
 	}
+	defer func() { _ = tx.Rollback(ctx) }()
 
-	sql, args, _ = r.Builder.
-		Insert("operations").
-		Columns("account_id", "amount", "operation_type").
-		Values(id, amount, entity.OperationTypeWithdraw).
-		ToSql()
+	status, err := lockAccount(ctx, tx, r.Builder, change.AccountId)
+	if err != nil {
+		if errors.Is(err, repoerrs.ErrNotFound) {
+			return err
+		}
+		return fmt.Errorf("AccountRepo.ChangeAccountStatus - lockAccount: %v", err)
+	}
 
-	_, err = tx.Exec(ctx, sql, args...)
+	// closed is the final status
+	if status == entity.AccountStatusClosed {
+		return repoerrs.ErrAccountClosed
+	}
+
+	change.OldStatus = status
+	err = r.updateStatus(ctx, tx, change)
 	if err != nil {
-		return fmt.Errorf("AccountRepo.Withdraw - tx.Exec: %v", err)
+		return fmt.Errorf("AccountRepo.ChangeAccountStatus - r.updateStatus: %v", err)
 	}
 
 	err = tx.Commit(ctx)
 	if err != nil {
-		return fmt.Errorf("AccountRepo.Withdraw - tx.Commit: %v", err)
+		return fmt.Errorf("AccountRepo.ChangeAccountStatus - tx.Commit: %v", err)
 	}
 
 	return nil
 }
 
-func (r *AccountRepo) Transfer(ctx context.Context, from, to, amount int) error {
+// CloseAccount closes the account. If the account still has money, it is paid out
+// with a withdraw operation when payout is set, otherwise ErrAccountHasBalance is returned
+func (r *AccountRepo) CloseAccount(ctx context.Context, change entity.AccountStatusChange, payout bool) (int, error) {
 	tx, err := r.Pool.Begin(ctx)
 	if err != nil {
-		return fmt.Errorf("AccountRepo.Transfer - r.Pool.Begin: %v", err)
+		return 0, fmt.Errorf("AccountRepo.CloseAccount - r.Pool.Begin: %v", err)
 	}
 	defer func() { _ = tx.Rollback(ctx) }()
 
-	// check if account 'from' has enough balance to transfer
-	sql, args, _ := r.Builder.
-		Select("balance").
-		From("accounts").
-		Where("id = ?", from).
-		ToSql()
-
-	var balance int
-	err = tx.QueryRow(ctx, sql, args...).Scan(&balance)
+	status, err := lockAccount(ctx, tx, r.Builder, change.AccountId)
 	if err != nil {
-		return fmt.Errorf("AccountRepo.Transfer - tx.QueryRow: %v", err)
+		if errors.Is(err, repoerrs.ErrNotFound) {
+			return 0, err
+		}
+		return 0, fmt.Errorf("AccountRepo.CloseAccount - lockAccount: %v", err)
 	}
 
-	if balance < amount {
-		return repoerrs.ErrNotEnoughBalance
+	if status == entity.AccountStatusClosed {
+		return 0, repoerrs.ErrAccountClosed
 	}
 
-	sql, args, _ = r.Builder.
-		Update("accounts").
-		Set("balance", squirrel.Expr("balance - ?", amount)).
-		Where("id = ?", from).
+	// reserved money belongs to orders in progress, they have to be finished first
+	sql, args, _ := r.Builder.
+		Select("count(*)").
+		From("reservations").
+		Where("account_id = ?", change.AccountId).
 		ToSql()
 
-	_, err = tx.Exec(ctx, sql, args...)
+	var reservations int
+	err = tx.QueryRow(ctx, sql, args...).Scan(&reservations)
 	if err != nil {
-		return fmt.Errorf("AccountRepo.Transfer - tx.Exec: %v", err)
+		return 0, fmt.Errorf("AccountRepo.CloseAccount - tx.QueryRow: %v", err)
 	}
 
-	sql, args, _ = r.Builder.
-		Update("accounts").
-		Set("balance", squirrel.Expr("balance + ?", amount)).
-		Where("id = ?", to).
-		ToSql()
+	if reservations > 0 {
+		return 0, repoerrs.ErrAccountHasReservations
+	}
 
-	_, err = tx.Exec(ctx, sql, args...)
+	// only real money is paid out, bonus credit stays with the closed account
+	balances, err := walletBalances(ctx, tx, r.Builder, change.AccountId)
 	if err != nil {
-		return fmt.Errorf("AccountRepo.Transfer - tx.Exec: %v", err)
+		return 0, fmt.Errorf("AccountRepo.CloseAccount - walletBalances: %v", err)
 	}
 
-	sql, args, _ = r.Builder.
-		Insert("operations").
-		Columns("account_id", "amount", "operation_type").
-		Values(from, amount, entity.OperationTypeTransferFrom).
-		ToSql()
+	balance := balances[entity.WalletTypeMain]
+	if balance > 0 {
+		if !payout {
+			return 0, repoerrs.ErrAccountHasBalance
+		}
 
-	_, err = tx.Exec(ctx, sql, args...)
+		err = debitWallet(ctx, tx, r.Builder, change.AccountId, entity.WalletTypeMain, balance)
+		if err != nil {
+			return 0, fmt.Errorf("AccountRepo.CloseAccount - debitWallet: %v", err)
+		}
+
+		err = insertOperation(ctx, tx, r.Builder, entity.Operation{
+			AccountId:     change.AccountId,
+			Amount:        balance,
+			OperationType: entity.OperationTypeWithdraw,
+			WalletType:    entity.WalletTypeMain,
+			Description:   "account closure payout",
+		})
+		if err != nil {
+			return 0, fmt.Errorf("AccountRepo.CloseAccount - insertOperation: %v", err)
+		}
+	}
+
+	change.OldStatus = status
+	change.NewStatus = entity.AccountStatusClosed
+	err = r.updateStatus(ctx, tx, change)
 	if err != nil {
-		return fmt.Errorf("AccountRepo.Transfer - tx.Exec: %v", err)
+		return 0, fmt.Errorf("AccountRepo.CloseAccount - r.updateStatus: %v", err)
 	}
 
-	sql, args, _ = r.Builder.
-		Insert("operations").
-		Columns("account_id", "amount", "operation_type").
-		Values(to, amount, entity.OperationTypeTransferTo).
+	err = tx.Commit(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("AccountRepo.CloseAccount - tx.Commit: %v", err)
+	}
+
+	return balance, nil
+}
+
+func (r *AccountRepo) GetAccountStatusChanges(ctx context.Context, accountId int) ([]entity.AccountStatusChange, error) {
+	sql, args, _ := r.Builder.
+		Select("id", "account_id", "old_status", "new_status", "reason_code", "COALESCE(comment, '')", "changed_by", "created_at").
+		From("account_status_changes").
+		Where("account_id = ?", accountId).
+		OrderBy("created_at DESC").
 		ToSql()
 
-	_, err = tx.Exec(ctx, sql, args...)
+	rows, err := r.Pool.Query(ctx, sql, args...)
 	if err != nil {
-		return fmt.Errorf("AccountRepo.Transfer - tx.Exec: %v", err)
+		return nil, fmt.Errorf("AccountRepo.GetAccountStatusChanges - r.Pool.Query: %v", err)
+	}
+	defer rows.Close()
+
+	var changes []entity.AccountStatusChange
+	for rows.Next() {
+		var change entity.AccountStatusChange
+		err = rows.Scan(
+			&change.Id,
+			&change.AccountId,
+			&change.OldStatus,
+			&change.NewStatus,
+			&change.ReasonCode,
+			&change.Comment,
+			&change.ChangedBy,
+			&change.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("AccountRepo.GetAccountStatusChanges - rows.Scan: %v", err)
+		}
+		changes = append(changes, change)
 	}
 
-	err = tx.Commit(ctx)
+	return changes, nil
+}
+
+func (r *AccountRepo) updateStatus(ctx context.Context, tx pgx.Tx, change entity.AccountStatusChange) error {
+	sql, args, _ := r.Builder.
+		Update("accounts").
+		Set("status", change.NewStatus).
+		Where("id = ?", change.AccountId).
+		ToSql()
+
+	_, err := tx.Exec(ctx, sql, args...)
 	if err != nil {
-		return fmt.Errorf("AccountRepo.Transfer - tx.Commit: %v", err)
+		return err
 	}
 
-	return nil
+	sql, args, _ = r.Builder.
+		Insert("account_status_changes").
+		Columns("account_id", "old_status", "new_status", "reason_code", "comment", "changed_by").
+		Values(
+			change.AccountId,
+			change.OldStatus,
+			change.NewStatus,
+			change.ReasonCode,
+			change.Comment,
+			change.ChangedBy,
+		).
+		ToSql()
+
+	_, err = tx.Exec(ctx, sql, args...)
+	return err
 }
@@ -0,0 +1,391 @@
+package pgdb
+
+import (
+	"account-management-service/internal/entity"
+	"account-management-service/internal/repo/repoerrs"
+	"account-management-service/pkg/postgres"
+	"context"
+	"github.com/Masterminds/squirrel"
+	"github.com/pashagolub/pgxmock/v2"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestAccountRepo_Withdraw(t *testing.T) {
+	memberId := 7
+
+	type args struct {
+		ctx          context.Context
+		id           int
+		amount       int
+		userId       *int
+		fee          entity.Fee
+		limits       []entity.LimitRule
+		verification entity.VerificationCheck
+	}
+
+	type MockBehavior func(m pgxmock.PgxPoolIface, args args)
+
+	testCases := []struct {
+		name         string
+		args         args
+		mockBehavior MockBehavior
+		wantErr      error
+	}{
+		{
+			name: "OK",
+			args: args{
+				ctx:    context.Background(),
+				id:     1,
+				amount: 100,
+			},
+			mockBehavior: func(m pgxmock.PgxPoolIface, args args) {
+				m.ExpectBegin()
+				m.ExpectQuery("SELECT status FROM accounts").
+					WithArgs(args.id).
+					WillReturnRows(pgxmock.NewRows([]string{"status"}).AddRow(entity.AccountStatusActive))
+				m.ExpectQuery("SELECT wallet_type, balance FROM wallets").
+					WithArgs(args.id).
+					WillReturnRows(pgxmock.NewRows([]string{"wallet_type", "balance"}).
+						AddRow(entity.WalletTypeMain, 200).
+						AddRow(entity.WalletTypeBonus, 500))
+				m.ExpectExec("UPDATE wallets").
+					WithArgs(args.amount, args.id, entity.WalletTypeMain).
+					WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+				m.ExpectExec("INSERT INTO operations").
+					WithArgs(args.id, args.amount, entity.OperationTypeWithdraw, entity.WalletTypeMain).
+					WillReturnResult(pgxmock.NewResult("INSERT", 1))
+				m.ExpectCommit()
+			},
+			wantErr: nil,
+		},
+		{
+			name: "OK with fee",
+			args: args{
+				ctx:    context.Background(),
+				id:     1,
+				amount: 100,
+				fee:    entity.Fee{AccountId: 99, Amount: 10},
+			},
+			mockBehavior: func(m pgxmock.PgxPoolIface, args args) {
+				m.ExpectBegin()
+				m.ExpectQuery("SELECT status FROM accounts").
+					WithArgs(args.id).
+					WillReturnRows(pgxmock.NewRows([]string{"status"}).AddRow(entity.AccountStatusActive))
+				m.ExpectQuery("SELECT wallet_type, balance FROM wallets").
+					WithArgs(args.id).
+					WillReturnRows(pgxmock.NewRows([]string{"wallet_type", "balance"}).
+						AddRow(entity.WalletTypeMain, 110))
+				m.ExpectExec("UPDATE wallets").
+					WithArgs(args.amount, args.id, entity.WalletTypeMain).
+					WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+				m.ExpectExec("INSERT INTO operations").
+					WithArgs(args.id, args.amount, entity.OperationTypeWithdraw, entity.WalletTypeMain).
+					WillReturnResult(pgxmock.NewResult("INSERT", 1))
+				m.ExpectExec("UPDATE wallets").
+					WithArgs(args.fee.Amount, args.id, entity.WalletTypeMain).
+					WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+				m.ExpectExec("INSERT INTO wallets").
+					WithArgs(args.fee.AccountId, entity.WalletTypeMain, args.fee.Amount).
+					WillReturnResult(pgxmock.NewResult("INSERT", 1))
+				m.ExpectExec("INSERT INTO operations").
+					WithArgs(args.id, args.fee.Amount, entity.OperationTypeFee, entity.WalletTypeMain, "withdraw fee").
+					WillReturnResult(pgxmock.NewResult("INSERT", 1))
+				m.ExpectExec("INSERT INTO operations").
+					WithArgs(args.fee.AccountId, args.fee.Amount, entity.OperationTypeFeeIncome, entity.WalletTypeMain, "withdraw fee from account #1").
+					WillReturnResult(pgxmock.NewResult("INSERT", 1))
+				m.ExpectCommit()
+			},
+			wantErr: nil,
+		},
+		{
+			name: "not enough balance for fee",
+			args: args{
+				ctx:    context.Background(),
+				id:     1,
+				amount: 100,
+				fee:    entity.Fee{AccountId: 99, Amount: 10},
+			},
+			mockBehavior: func(m pgxmock.PgxPoolIface, args args) {
+				m.ExpectBegin()
+				m.ExpectQuery("SELECT status FROM accounts").
+					WithArgs(args.id).
+					WillReturnRows(pgxmock.NewRows([]string{"status"}).AddRow(entity.AccountStatusActive))
+				m.ExpectQuery("SELECT wallet_type, balance FROM wallets").
+					WithArgs(args.id).
+					WillReturnRows(pgxmock.NewRows([]string{"wallet_type", "balance"}).
+						AddRow(entity.WalletTypeMain, 105))
+				m.ExpectRollback()
+			},
+			wantErr: repoerrs.ErrNotEnoughBalance,
+		},
+		{
+			name: "account frozen for debit",
+			args: args{
+				ctx:    context.Background(),
+				id:     1,
+				amount: 100,
+			},
+			mockBehavior: func(m pgxmock.PgxPoolIface, args args) {
+				m.ExpectBegin()
+				m.ExpectQuery("SELECT status FROM accounts").
+					WithArgs(args.id).
+					WillReturnRows(pgxmock.NewRows([]string{"status"}).AddRow(entity.AccountStatusFrozenDebit))
+				m.ExpectRollback()
+			},
+			wantErr: repoerrs.ErrAccountFrozen,
+		},
+		{
+			name: "account closed",
+			args: args{
+				ctx:    context.Background(),
+				id:     1,
+				amount: 100,
+			},
+			mockBehavior: func(m pgxmock.PgxPoolIface, args args) {
+				m.ExpectBegin()
+				m.ExpectQuery("SELECT status FROM accounts").
+					WithArgs(args.id).
+					WillReturnRows(pgxmock.NewRows([]string{"status"}).AddRow(entity.AccountStatusClosed))
+				m.ExpectRollback()
+			},
+			wantErr: repoerrs.ErrAccountClosed,
+		},
+		{
+			name: "not enough balance",
+			args: args{
+				ctx:    context.Background(),
+				id:     1,
+				amount: 100,
+			},
+			mockBehavior: func(m pgxmock.PgxPoolIface, args args) {
+				m.ExpectBegin()
+				m.ExpectQuery("SELECT status FROM accounts").
+					WithArgs(args.id).
+					WillReturnRows(pgxmock.NewRows([]string{"status"}).AddRow(entity.AccountStatusActive))
+				m.ExpectQuery("SELECT wallet_type, balance FROM wallets").
+					WithArgs(args.id).
+					WillReturnRows(pgxmock.NewRows([]string{"wallet_type", "balance"}).
+						AddRow(entity.WalletTypeMain, 50).
+						AddRow(entity.WalletTypeBonus, 500))
+				m.ExpectRollback()
+			},
+			wantErr: repoerrs.ErrNotEnoughBalance,
+		},
+		{
+			name: "window limit exceeded",
+			args: args{
+				ctx:    context.Background(),
+				id:     1,
+				amount: 100,
+				limits: []entity.LimitRule{
+					{LimitType: entity.LimitTypeMaxCount, Value: 5, WindowSeconds: 3600},
+					{LimitType: entity.LimitTypeMaxTotal, Value: 1000, WindowSeconds: 86400},
+				},
+			},
+			mockBehavior: func(m pgxmock.PgxPoolIface, args args) {
+				m.ExpectBegin()
+				// the window is counted only after the account is locked
+				m.ExpectQuery("SELECT status FROM accounts").
+					WithArgs(args.id).
+					WillReturnRows(pgxmock.NewRows([]string{"status"}).AddRow(entity.AccountStatusActive))
+				m.ExpectQuery("SELECT count").
+					WithArgs(args.id, entity.OperationTypeWithdraw, 3600).
+					WillReturnRows(pgxmock.NewRows([]string{"count", "sum"}).AddRow(2, 300))
+				m.ExpectQuery("SELECT count").
+					WithArgs(args.id, entity.OperationTypeWithdraw, 86400).
+					WillReturnRows(pgxmock.NewRows([]string{"count", "sum"}).AddRow(4, 950))
+				m.ExpectRollback()
+			},
+			wantErr: repoerrs.ErrLimitExceeded,
+		},
+		{
+			name: "verification window exceeded",
+			args: args{
+				ctx:    context.Background(),
+				id:     1,
+				amount: 100,
+				verification: entity.VerificationCheck{
+					Level: entity.VerificationLevelNone,
+					Policies: []entity.VerificationPolicy{
+						{Level: entity.VerificationLevelNone, MaxTotal: 300, WindowSeconds: 86400},
+						{Level: entity.VerificationLevelBasic, MaxTotal: 3000, WindowSeconds: 86400},
+					},
+				},
+			},
+			mockBehavior: func(m pgxmock.PgxPoolIface, args args) {
+				m.ExpectBegin()
+				// the window is summed only after the account is locked, once for both levels
+				m.ExpectQuery("SELECT status FROM accounts").
+					WithArgs(args.id).
+					WillReturnRows(pgxmock.NewRows([]string{"status"}).AddRow(entity.AccountStatusActive))
+				m.ExpectQuery("SELECT count\\(\\*\\), COALESCE\\(sum\\(amount\\), 0\\) FROM operations").
+					WithArgs(args.id, entity.OperationTypeWithdraw, 86400).
+					WillReturnRows(pgxmock.NewRows([]string{"count", "sum"}).AddRow(2, 250))
+				m.ExpectRollback()
+			},
+			wantErr: repoerrs.ErrVerificationRequired,
+		},
+		{
+			name: "account without members",
+			args: args{
+				ctx:    context.Background(),
+				id:     1,
+				amount: 100,
+				userId: &memberId,
+			},
+			mockBehavior: func(m pgxmock.PgxPoolIface, args args) {
+				m.ExpectBegin()
+				m.ExpectQuery("SELECT status FROM accounts").
+					WithArgs(args.id).
+					WillReturnRows(pgxmock.NewRows([]string{"status"}).AddRow(entity.AccountStatusActive))
+				m.ExpectQuery("SELECT count\\(\\*\\) > 0 FROM account_members").
+					WithArgs(args.id).
+					WillReturnRows(pgxmock.NewRows([]string{"shared"}).AddRow(false))
+				m.ExpectQuery("SELECT wallet_type, balance FROM wallets").
+					WithArgs(args.id).
+					WillReturnRows(pgxmock.NewRows([]string{"wallet_type", "balance"}).AddRow(entity.WalletTypeMain, 200))
+				m.ExpectExec("UPDATE wallets").
+					WithArgs(args.amount, args.id, entity.WalletTypeMain).
+					WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+				// the user is not recorded for the operations of an account without members
+				m.ExpectExec("INSERT INTO operations").
+					WithArgs(args.id, args.amount, entity.OperationTypeWithdraw, entity.WalletTypeMain).
+					WillReturnResult(pgxmock.NewResult("INSERT", 1))
+				m.ExpectCommit()
+			},
+			wantErr: nil,
+		},
+		{
+			name: "group member",
+			args: args{
+				ctx:    context.Background(),
+				id:     1,
+				amount: 100,
+				userId: &memberId,
+			},
+			mockBehavior: func(m pgxmock.PgxPoolIface, args args) {
+				m.ExpectBegin()
+				m.ExpectQuery("SELECT status FROM accounts").
+					WithArgs(args.id).
+					WillReturnRows(pgxmock.NewRows([]string{"status"}).AddRow(entity.AccountStatusActive))
+				m.ExpectQuery("SELECT count\\(\\*\\) > 0 FROM account_members").
+					WithArgs(args.id).
+					WillReturnRows(pgxmock.NewRows([]string{"shared"}).AddRow(true))
+				m.ExpectQuery("SELECT role, monthly_limit FROM account_members").
+					WithArgs(args.id, *args.userId).
+					WillReturnRows(pgxmock.NewRows([]string{"role", "monthly_limit"}).AddRow(entity.GroupRoleSpender, 1000))
+				m.ExpectQuery("FROM operations").
+					WithArgs(args.id, *args.userId).
+					WillReturnRows(pgxmock.NewRows([]string{"spent"}).AddRow(900))
+				m.ExpectQuery("SELECT wallet_type, balance FROM wallets").
+					WithArgs(args.id).
+					WillReturnRows(pgxmock.NewRows([]string{"wallet_type", "balance"}).AddRow(entity.WalletTypeMain, 200))
+				m.ExpectExec("UPDATE wallets").
+					WithArgs(args.amount, args.id, entity.WalletTypeMain).
+					WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+				m.ExpectExec("INSERT INTO operations").
+					WithArgs(args.id, args.amount, entity.OperationTypeWithdraw, entity.WalletTypeMain, *args.userId).
+					WillReturnResult(pgxmock.NewResult("INSERT", 1))
+				m.ExpectCommit()
+			},
+			wantErr: nil,
+		},
+		{
+			name: "not a group member",
+			args: args{
+				ctx:    context.Background(),
+				id:     1,
+				amount: 100,
+				userId: &memberId,
+			},
+			mockBehavior: func(m pgxmock.PgxPoolIface, args args) {
+				m.ExpectBegin()
+				m.ExpectQuery("SELECT status FROM accounts").
+					WithArgs(args.id).
+					WillReturnRows(pgxmock.NewRows([]string{"status"}).AddRow(entity.AccountStatusActive))
+				m.ExpectQuery("SELECT count\\(\\*\\) > 0 FROM account_members").
+					WithArgs(args.id).
+					WillReturnRows(pgxmock.NewRows([]string{"shared"}).AddRow(true))
+				m.ExpectQuery("SELECT role, monthly_limit FROM account_members").
+					WithArgs(args.id, *args.userId).
+					WillReturnRows(pgxmock.NewRows([]string{"role", "monthly_limit"}))
+				m.ExpectRollback()
+			},
+			wantErr: repoerrs.ErrNotGroupMember,
+		},
+		{
+			name: "viewer",
+			args: args{
+				ctx:    context.Background(),
+				id:     1,
+				amount: 100,
+				userId: &memberId,
+			},
+			mockBehavior: func(m pgxmock.PgxPoolIface, args args) {
+				m.ExpectBegin()
+				m.ExpectQuery("SELECT status FROM accounts").
+					WithArgs(args.id).
+					WillReturnRows(pgxmock.NewRows([]string{"status"}).AddRow(entity.AccountStatusActive))
+				m.ExpectQuery("SELECT count\\(\\*\\) > 0 FROM account_members").
+					WithArgs(args.id).
+					WillReturnRows(pgxmock.NewRows([]string{"shared"}).AddRow(true))
+				m.ExpectQuery("SELECT role, monthly_limit FROM account_members").
+					WithArgs(args.id, *args.userId).
+					WillReturnRows(pgxmock.NewRows([]string{"role", "monthly_limit"}).AddRow(entity.GroupRoleViewer, 0))
+				m.ExpectRollback()
+			},
+			wantErr: repoerrs.ErrMemberCannotSpend,
+		},
+		{
+			name: "member limit exceeded",
+			args: args{
+				ctx:    context.Background(),
+				id:     1,
+				amount: 100,
+				userId: &memberId,
+			},
+			mockBehavior: func(m pgxmock.PgxPoolIface, args args) {
+				m.ExpectBegin()
+				m.ExpectQuery("SELECT status FROM accounts").
+					WithArgs(args.id).
+					WillReturnRows(pgxmock.NewRows([]string{"status"}).AddRow(entity.AccountStatusActive))
+				m.ExpectQuery("SELECT count\\(\\*\\) > 0 FROM account_members").
+					WithArgs(args.id).
+					WillReturnRows(pgxmock.NewRows([]string{"shared"}).AddRow(true))
+				m.ExpectQuery("SELECT role, monthly_limit FROM account_members").
+					WithArgs(args.id, *args.userId).
+					WillReturnRows(pgxmock.NewRows([]string{"role", "monthly_limit"}).AddRow(entity.GroupRoleSpender, 1000))
+				m.ExpectQuery("FROM operations").
+					WithArgs(args.id, *args.userId).
+					WillReturnRows(pgxmock.NewRows([]string{"spent"}).AddRow(950))
+				m.ExpectRollback()
+			},
+			wantErr: repoerrs.ErrMemberLimitExceeded,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			poolMock, _ := pgxmock.NewPool()
+			defer poolMock.Close()
+			tc.mockBehavior(poolMock, tc.args)
+
+			postgresMock := &postgres.Postgres{
+				Builder: squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar),
+				Pool:    poolMock,
+			}
+			accountRepoMock := NewAccountRepo(postgresMock)
+
+			err := accountRepoMock.Withdraw(tc.args.ctx, tc.args.id, tc.args.amount, tc.args.userId, tc.args.fee, tc.args.limits, tc.args.verification)
+			if tc.wantErr != nil {
+				assert.ErrorIs(t, err, tc.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			err = poolMock.ExpectationsWereMet()
+			assert.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,259 @@
+package pgdb
+
+import (
+	"account-management-service/internal/entity"
+	"account-management-service/internal/repo/repoerrs"
+	"account-management-service/pkg/postgres"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+)
+
+// operationDescriptionMaxLength is the size of the operations description column
+const operationDescriptionMaxLength = 255
+
+var adjustmentColumns = []string{
+	"id",
+	"account_id",
+	"direction",
+	"amount",
+	"wallet",
+	"reason_code",
+	"comment",
+	"ticket",
+	"created_by",
+	"created_at",
+}
+
+type AdjustmentRepo struct {
+	*postgres.Postgres
+}
+
+func NewAdjustmentRepo(pg *postgres.Postgres) *AdjustmentRepo {
+	return &AdjustmentRepo{pg}
+}
+
+// CreateAdjustment credits or debits the account wallet and records both the adjustment and its operation.
+// Credits are allowed to frozen accounts as refunds are, debits follow the usual outgoing money rules
+func (r *AdjustmentRepo) CreateAdjustment(ctx context.Context, adjustment entity.Adjustment) (int, error) {
+	tx, err := r.Pool.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("AdjustmentRepo.CreateAdjustment - r.Pool.Begin: %v", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	id, err := createAdjustmentTx(ctx, tx, r.Builder, adjustment)
+	if err != nil {
+		if errors.Is(err, repoerrs.ErrUnknownReason) || isLedgerError(err) {
+			return 0, err
+		}
+		return 0, fmt.Errorf("AdjustmentRepo.CreateAdjustment - createAdjustmentTx: %v", err)
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("AdjustmentRepo.CreateAdjustment - tx.Commit: %v", err)
+	}
+
+	return id, nil
+}
+
+// createAdjustmentTx moves the money of the adjustment and records it within the caller transaction
+func createAdjustmentTx(ctx context.Context, tx pgx.Tx, builder squirrel.StatementBuilderType, adjustment entity.Adjustment) (int, error) {
+	sql, args, _ := builder.
+		Select("active").
+		From("adjustment_reasons").
+		Where("code = ?", adjustment.ReasonCode).
+		ToSql()
+
+	var active bool
+	err := tx.QueryRow(ctx, sql, args...).Scan(&active)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return 0, err
+	}
+	if !active {
+		return 0, repoerrs.ErrUnknownReason
+	}
+
+	status, err := lockAccount(ctx, tx, builder, adjustment.AccountId)
+	if err != nil {
+		return 0, err
+	}
+
+	operationType := entity.OperationTypeAdjustmentCredit
+	if adjustment.Direction == entity.AdjustmentDirectionDebit {
+		operationType = entity.OperationTypeAdjustmentDebit
+
+		if err = checkAccountStatus(status, canDebit...); err != nil {
+			return 0, err
+		}
+
+		balances, err := walletBalances(ctx, tx, builder, adjustment.AccountId)
+		if err != nil {
+			return 0, err
+		}
+		if balances[adjustment.Wallet] < adjustment.Amount {
+			return 0, repoerrs.ErrNotEnoughBalance
+		}
+
+		err = debitWallet(ctx, tx, builder, adjustment.AccountId, adjustment.Wallet, adjustment.Amount)
+		if err != nil {
+			return 0, err
+		}
+	} else {
+		if err = checkAccountStatus(status, canRefund...); err != nil {
+			return 0, err
+		}
+
+		err = creditWallet(ctx, tx, builder, adjustment.AccountId, adjustment.Wallet, adjustment.Amount)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	sql, args, _ = builder.
+		Insert("adjustments").
+		Columns("account_id", "direction", "amount", "wallet", "reason_code", "comment", "ticket", "created_by").
+		Values(
+			adjustment.AccountId,
+			adjustment.Direction,
+			adjustment.Amount,
+			adjustment.Wallet,
+			adjustment.ReasonCode,
+			adjustment.Comment,
+			adjustment.Ticket,
+			adjustment.CreatedBy,
+		).
+		Suffix("RETURNING id").
+		ToSql()
+
+	var id int
+	err = tx.QueryRow(ctx, sql, args...).Scan(&id)
+	if err != nil {
+		return 0, err
+	}
+
+	description := fmt.Sprintf("adjustment #%d, %s: %s", id, adjustment.ReasonCode, adjustment.Comment)
+	if adjustment.Ticket != "" {
+		description = fmt.Sprintf("adjustment #%d, ticket %s, %s: %s", id, adjustment.Ticket, adjustment.ReasonCode, adjustment.Comment)
+	}
+	if len(description) > operationDescriptionMaxLength {
+		description = description[:operationDescriptionMaxLength]
+	}
+
+	err = insertOperation(ctx, tx, builder, entity.Operation{
+		AccountId:     adjustment.AccountId,
+		Amount:        adjustment.Amount,
+		OperationType: operationType,
+		WalletType:    adjustment.Wallet,
+		Description:   description,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+func (r *AdjustmentRepo) GetAdjustmentsByAccountId(ctx context.Context, accountId int) ([]entity.Adjustment, error) {
+	sql, args, _ := r.Builder.
+		Select(adjustmentColumns...).
+		From("adjustments").
+		Where("account_id = ?", accountId).
+		OrderBy("id DESC").
+		ToSql()
+
+	rows, err := r.Pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("AdjustmentRepo.GetAdjustmentsByAccountId - r.Pool.Query: %v", err)
+	}
+	defer rows.Close()
+
+	var adjustments []entity.Adjustment
+	for rows.Next() {
+		var a entity.Adjustment
+		err = rows.Scan(
+			&a.Id,
+			&a.AccountId,
+			&a.Direction,
+			&a.Amount,
+			&a.Wallet,
+			&a.ReasonCode,
+			&a.Comment,
+			&a.Ticket,
+			&a.CreatedBy,
+			&a.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("AdjustmentRepo.GetAdjustmentsByAccountId - rows.Scan: %v", err)
+		}
+		adjustments = append(adjustments, a)
+	}
+
+	return adjustments, nil
+}
+
+// SetAdjustmentReason adds the reason to the list or updates the description of the existing one and activates it
+func (r *AdjustmentRepo) SetAdjustmentReason(ctx context.Context, reason entity.AdjustmentReason) error {
+	sql, args, _ := r.Builder.
+		Insert("adjustment_reasons").
+		Columns("code", "description").
+		Values(reason.Code, reason.Description).
+		Suffix("ON CONFLICT (code) DO UPDATE SET description = excluded.description, active = true").
+		ToSql()
+
+	_, err := r.Pool.Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("AdjustmentRepo.SetAdjustmentReason - r.Pool.Exec: %v", err)
+	}
+
+	return nil
+}
+
+// DeactivateAdjustmentReason keeps the reason for the existing adjustments but forbids new ones
+func (r *AdjustmentRepo) DeactivateAdjustmentReason(ctx context.Context, code string) error {
+	sql, args, _ := r.Builder.
+		Update("adjustment_reasons").
+		Set("active", false).
+		Where("code = ?", code).
+		ToSql()
+
+	tag, err := r.Pool.Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("AdjustmentRepo.DeactivateAdjustmentReason - r.Pool.Exec: %v", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return repoerrs.ErrNotFound
+	}
+
+	return nil
+}
+
+func (r *AdjustmentRepo) GetAdjustmentReasons(ctx context.Context) ([]entity.AdjustmentReason, error) {
+	sql, args, _ := r.Builder.
+		Select("code", "description", "active", "created_at").
+		From("adjustment_reasons").
+		OrderBy("code").
+		ToSql()
+
+	rows, err := r.Pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("AdjustmentRepo.GetAdjustmentReasons - r.Pool.Query: %v", err)
+	}
+	defer rows.Close()
+
+	var reasons []entity.AdjustmentReason
+	for rows.Next() {
+		var reason entity.AdjustmentReason
+		err = rows.Scan(&reason.Code, &reason.Description, &reason.Active, &reason.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("AdjustmentRepo.GetAdjustmentReasons - rows.Scan: %v", err)
+		}
+		reasons = append(reasons, reason)
+	}
+
+	return reasons, nil
+}
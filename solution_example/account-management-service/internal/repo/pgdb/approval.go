@@ -0,0 +1,496 @@
+package pgdb
+
+import (
+	"account-management-service/internal/entity"
+	"account-management-service/internal/repo/repoerrs"
+	"account-management-service/pkg/postgres"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+)
+
+// pending requests past their expiration time are shown as expired even before the scheduler marks them
+var approvalRequestColumns = []string{
+	"id",
+	"operation_type",
+	"account_id",
+	"order_id",
+	"amount",
+	"wallet",
+	"reason_code",
+	"comment",
+	"ticket",
+	"case when status = 'pending' and expires_at <= now() then 'expired' else status end",
+	"maker_id",
+	"checker_id",
+	"error",
+	"expires_at",
+	"resolved_at",
+	"created_at",
+}
+
+// approvalErrorMaxLength is the size of the error column
+const approvalErrorMaxLength = 255
+
+type ApprovalRepo struct {
+	*postgres.Postgres
+}
+
+func NewApprovalRepo(pg *postgres.Postgres) *ApprovalRepo {
+	return &ApprovalRepo{pg}
+}
+
+func (r *ApprovalRepo) CreateApprovalRequest(ctx context.Context, request entity.ApprovalRequest) (int, error) {
+	tx, err := r.Pool.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("ApprovalRepo.CreateApprovalRequest - r.Pool.Begin: %v", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	sql, args, _ := r.Builder.
+		Insert("approval_requests").
+		Columns("operation_type", "account_id", "order_id", "amount", "wallet", "reason_code", "comment", "ticket", "maker_id", "expires_at").
+		Values(
+			request.OperationType,
+			request.AccountId,
+			request.OrderId,
+			request.Amount,
+			request.Wallet,
+			request.ReasonCode,
+			request.Comment,
+			request.Ticket,
+			request.MakerId,
+			request.ExpiresAt,
+		).
+		Suffix("RETURNING id").
+		ToSql()
+
+	var id int
+	err = tx.QueryRow(ctx, sql, args...).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("ApprovalRepo.CreateApprovalRequest - tx.QueryRow: %v", err)
+	}
+
+	err = insertApprovalEvent(ctx, tx, r.Builder, entity.ApprovalEvent{
+		RequestId: id,
+		Action:    entity.ApprovalActionCreated,
+		UserId:    &request.MakerId,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("ApprovalRepo.CreateApprovalRequest - insertApprovalEvent: %v", err)
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("ApprovalRepo.CreateApprovalRequest - tx.Commit: %v", err)
+	}
+
+	return id, nil
+}
+
+func (r *ApprovalRepo) GetApprovalRequestById(ctx context.Context, id int) (entity.ApprovalRequest, error) {
+	sql, args, _ := r.Builder.
+		Select(approvalRequestColumns...).
+		From("approval_requests").
+		Where("id = ?", id).
+		ToSql()
+
+	request, err := scanApprovalRequest(r.Pool.QueryRow(ctx, sql, args...))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return entity.ApprovalRequest{}, repoerrs.ErrNotFound
+		}
+		return entity.ApprovalRequest{}, fmt.Errorf("ApprovalRepo.GetApprovalRequestById - r.Pool.QueryRow: %v", err)
+	}
+
+	return request, nil
+}
+
+// GetApprovalRequests returns the requests with the status, or all of them if the status is empty, newest first
+func (r *ApprovalRepo) GetApprovalRequests(ctx context.Context, status string) ([]entity.ApprovalRequest, error) {
+	builder := r.Builder.
+		Select(approvalRequestColumns...).
+		From("approval_requests").
+		OrderBy("id DESC")
+
+	switch status {
+	case "":
+	case entity.ApprovalStatusPending:
+		builder = builder.Where("status = ? and expires_at > now()", status)
+	case entity.ApprovalStatusExpired:
+		builder = builder.Where("(status = ? or status = ? and expires_at <= now())", status, entity.ApprovalStatusPending)
+	default:
+		builder = builder.Where("status = ?", status)
+	}
+
+	sql, args, _ := builder.ToSql()
+
+	rows, err := r.Pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("ApprovalRepo.GetApprovalRequests - r.Pool.Query: %v", err)
+	}
+	defer rows.Close()
+
+	var requests []entity.ApprovalRequest
+	for rows.Next() {
+		request, err := scanApprovalRequest(rows)
+		if err != nil {
+			return nil, fmt.Errorf("ApprovalRepo.GetApprovalRequests - rows.Scan: %v", err)
+		}
+		requests = append(requests, request)
+	}
+
+	return requests, nil
+}
+
+func (r *ApprovalRepo) GetApprovalEvents(ctx context.Context, requestId int) ([]entity.ApprovalEvent, error) {
+	sql, args, _ := r.Builder.
+		Select("id", "request_id", "action", "user_id", "comment", "created_at").
+		From("approval_events").
+		Where("request_id = ?", requestId).
+		OrderBy("id").
+		ToSql()
+
+	rows, err := r.Pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("ApprovalRepo.GetApprovalEvents - r.Pool.Query: %v", err)
+	}
+	defer rows.Close()
+
+	var events []entity.ApprovalEvent
+	for rows.Next() {
+		var event entity.ApprovalEvent
+		err = rows.Scan(
+			&event.Id,
+			&event.RequestId,
+			&event.Action,
+			&event.UserId,
+			&event.Comment,
+			&event.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("ApprovalRepo.GetApprovalEvents - rows.Scan: %v", err)
+		}
+		events = append(events, event)
+	}
+
+	return events, nil
+}
+
+// ExecuteApprovalRequest approves the pending request on behalf of the checker and executes its operation
+// in the same transaction, so the request is never left approved without the result. The operation runs
+// in a savepoint and the reason it fails for is recorded as the failed request, as is a non-empty rejectReason
+// found by the caller. The fee, limits and verification apply to a withdrawal
+func (r *ApprovalRepo) ExecuteApprovalRequest(ctx context.Context, id, checkerId int, comment, rejectReason string, fee entity.Fee, limits []entity.LimitRule, verification entity.VerificationCheck) (entity.ApprovalRequest, error) {
+	tx, err := r.Pool.Begin(ctx)
+	if err != nil {
+		return entity.ApprovalRequest{}, fmt.Errorf("ApprovalRepo.ExecuteApprovalRequest - r.Pool.Begin: %v", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	request, err := r.resolveApprovalRequestTx(ctx, tx, id, checkerId, entity.ApprovalStatusApproved, entity.ApprovalActionApproved, comment)
+	if err != nil {
+		return entity.ApprovalRequest{}, r.commitResolveError(ctx, tx, "ApprovalRepo.ExecuteApprovalRequest", err)
+	}
+
+	execErr := rejectReason
+	if execErr == "" {
+		err = r.executeInSavepoint(ctx, tx, request, fee, limits, verification)
+		if err != nil {
+			if !isLedgerError(err) && !errors.Is(err, repoerrs.ErrUnknownReason) {
+				return entity.ApprovalRequest{}, fmt.Errorf("ApprovalRepo.ExecuteApprovalRequest - r.executeInSavepoint: %v", err)
+			}
+			execErr = err.Error()
+		}
+	}
+
+	status, action := entity.ApprovalStatusExecuted, entity.ApprovalActionExecuted
+	if execErr != "" {
+		status, action = entity.ApprovalStatusFailed, entity.ApprovalActionFailed
+	}
+	if len(execErr) > approvalErrorMaxLength {
+		execErr = execErr[:approvalErrorMaxLength]
+	}
+
+	sql, args, _ := r.Builder.
+		Update("approval_requests").
+		Set("status", status).
+		Set("error", execErr).
+		Where("id = ?", id).
+		ToSql()
+
+	_, err = tx.Exec(ctx, sql, args...)
+	if err != nil {
+		return entity.ApprovalRequest{}, fmt.Errorf("ApprovalRepo.ExecuteApprovalRequest - tx.Exec: %v", err)
+	}
+
+	err = insertApprovalEvent(ctx, tx, r.Builder, entity.ApprovalEvent{
+		RequestId: id,
+		Action:    action,
+		Comment:   execErr,
+	})
+	if err != nil {
+		return entity.ApprovalRequest{}, fmt.Errorf("ApprovalRepo.ExecuteApprovalRequest - insertApprovalEvent: %v", err)
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return entity.ApprovalRequest{}, fmt.Errorf("ApprovalRepo.ExecuteApprovalRequest - tx.Commit: %v", err)
+	}
+
+	request.Status = status
+	request.Error = execErr
+	return request, nil
+}
+
+func (r *ApprovalRepo) RejectApprovalRequest(ctx context.Context, id, checkerId int, comment string) error {
+	tx, err := r.Pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("ApprovalRepo.RejectApprovalRequest - r.Pool.Begin: %v", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	_, err = r.resolveApprovalRequestTx(ctx, tx, id, checkerId, entity.ApprovalStatusRejected, entity.ApprovalActionRejected, comment)
+	if err != nil {
+		return r.commitResolveError(ctx, tx, "ApprovalRepo.RejectApprovalRequest", err)
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return fmt.Errorf("ApprovalRepo.RejectApprovalRequest - tx.Commit: %v", err)
+	}
+
+	return nil
+}
+
+// commitResolveError commits the expiration of the request the resolution has found,
+// and returns the resolution error wrapped with the caller name
+func (r *ApprovalRepo) commitResolveError(ctx context.Context, tx pgx.Tx, caller string, err error) error {
+	if errors.Is(err, repoerrs.ErrExpired) {
+		if commitErr := tx.Commit(ctx); commitErr != nil {
+			return fmt.Errorf("%s - tx.Commit: %v", caller, commitErr)
+		}
+	}
+	return fmt.Errorf("%s - r.resolveApprovalRequestTx: %w", caller, err)
+}
+
+// executeInSavepoint executes the operation of the approved request, so that its failure can still be recorded
+func (r *ApprovalRepo) executeInSavepoint(ctx context.Context, tx pgx.Tx, request entity.ApprovalRequest, fee entity.Fee, limits []entity.LimitRule, verification entity.VerificationCheck) error {
+	sp, err := tx.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = sp.Rollback(ctx) }()
+
+	switch request.OperationType {
+	case entity.OperationTypeDeposit:
+		err = depositMoney(ctx, sp, r.Builder, *request.AccountId, request.Amount, request.Wallet)
+	case entity.OperationTypeWithdraw:
+		err = withdrawMoney(ctx, sp, r.Builder, *request.AccountId, request.Amount, &request.MakerId, fee, limits, verification)
+	case entity.OperationTypeRefund:
+		err = finishReservationTx(ctx, sp, r.Builder, "order_id = ?", *request.OrderId, entity.OperationTypeRefund)
+		if errors.Is(err, pgx.ErrNoRows) {
+			err = repoerrs.ErrNotFound
+		}
+	case entity.OperationTypeRevenueRefund:
+		err = refundCapturedOrderTx(ctx, sp, r.Builder, *request.OrderId)
+		if errors.Is(err, pgx.ErrNoRows) {
+			err = repoerrs.ErrNotFound
+		}
+	case entity.OperationTypeAdjustmentCredit, entity.OperationTypeAdjustmentDebit:
+		direction := entity.AdjustmentDirectionCredit
+		if request.OperationType == entity.OperationTypeAdjustmentDebit {
+			direction = entity.AdjustmentDirectionDebit
+		}
+		_, err = createAdjustmentTx(ctx, sp, r.Builder, entity.Adjustment{
+			AccountId:  *request.AccountId,
+			Direction:  direction,
+			Amount:     request.Amount,
+			Wallet:     request.Wallet,
+			ReasonCode: request.ReasonCode,
+			Comment:    request.Comment,
+			Ticket:     request.Ticket,
+			CreatedBy:  request.MakerId,
+		})
+	default:
+		err = fmt.Errorf("unsupported operation type %s", request.OperationType)
+	}
+	if err != nil {
+		return err
+	}
+
+	return sp.Commit(ctx)
+}
+
+// ExpireApprovalRequests marks the pending requests past their expiration time as expired
+// and returns the number of expired requests
+func (r *ApprovalRepo) ExpireApprovalRequests(ctx context.Context) (int, error) {
+	tx, err := r.Pool.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("ApprovalRepo.ExpireApprovalRequests - r.Pool.Begin: %v", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	sql, args, _ := r.Builder.
+		Update("approval_requests").
+		Set("status", entity.ApprovalStatusExpired).
+		Set("resolved_at", squirrel.Expr("now()")).
+		Where("status = ? and expires_at <= now()", entity.ApprovalStatusPending).
+		Suffix("RETURNING id").
+		ToSql()
+
+	rows, err := tx.Query(ctx, sql, args...)
+	if err != nil {
+		return 0, fmt.Errorf("ApprovalRepo.ExpireApprovalRequests - tx.Query: %v", err)
+	}
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		err = rows.Scan(&id)
+		if err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("ApprovalRepo.ExpireApprovalRequests - rows.Scan: %v", err)
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	builder := r.Builder.
+		Insert("approval_events").
+		Columns("request_id", "action")
+	for _, id := range ids {
+		builder = builder.Values(id, entity.ApprovalActionExpired)
+	}
+
+	sql, args, _ = builder.ToSql()
+	_, err = tx.Exec(ctx, sql, args...)
+	if err != nil {
+		return 0, fmt.Errorf("ApprovalRepo.ExpireApprovalRequests - tx.Exec: %v", err)
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("ApprovalRepo.ExpireApprovalRequests - tx.Commit: %v", err)
+	}
+
+	return len(ids), nil
+}
+
+// resolveApprovalRequestTx moves a pending request to the given status on behalf of the checker.
+// An expired request is marked as expired and ErrExpired is returned, the caller commits the expiration.
+// ErrWrongStatus is returned if the request is already resolved and ErrSameUser if the checker is the maker of the request
+func (r *ApprovalRepo) resolveApprovalRequestTx(ctx context.Context, tx pgx.Tx, id, checkerId int, status, action, comment string) (entity.ApprovalRequest, error) {
+	sql, args, _ := r.Builder.
+		Select(approvalRequestColumns...).
+		From("approval_requests").
+		Where("id = ?", id).
+		Suffix("FOR UPDATE").
+		ToSql()
+
+	request, err := scanApprovalRequest(tx.QueryRow(ctx, sql, args...))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return entity.ApprovalRequest{}, repoerrs.ErrNotFound
+		}
+		return entity.ApprovalRequest{}, err
+	}
+
+	switch request.Status {
+	case entity.ApprovalStatusPending:
+	case entity.ApprovalStatusExpired:
+		// remember the expiration, it is committed even though the resolution fails
+		err = r.setApprovalRequestStatus(ctx, tx, id, entity.ApprovalStatusExpired, nil)
+		if err != nil {
+			return entity.ApprovalRequest{}, err
+		}
+		err = insertApprovalEvent(ctx, tx, r.Builder, entity.ApprovalEvent{
+			RequestId: id,
+			Action:    entity.ApprovalActionExpired,
+		})
+		if err != nil {
+			return entity.ApprovalRequest{}, err
+		}
+		return entity.ApprovalRequest{}, repoerrs.ErrExpired
+	default:
+		return entity.ApprovalRequest{}, repoerrs.ErrWrongStatus
+	}
+
+	if request.MakerId == checkerId {
+		return entity.ApprovalRequest{}, repoerrs.ErrSameUser
+	}
+
+	err = r.setApprovalRequestStatus(ctx, tx, id, status, &checkerId)
+	if err != nil {
+		return entity.ApprovalRequest{}, err
+	}
+
+	err = insertApprovalEvent(ctx, tx, r.Builder, entity.ApprovalEvent{
+		RequestId: id,
+		Action:    action,
+		UserId:    &checkerId,
+		Comment:   comment,
+	})
+	if err != nil {
+		return entity.ApprovalRequest{}, err
+	}
+
+	request.Status = status
+	request.CheckerId = &checkerId
+	return request, nil
+}
+
+func (r *ApprovalRepo) setApprovalRequestStatus(ctx context.Context, tx pgx.Tx, id int, status string, checkerId *int) error {
+	builder := r.Builder.
+		Update("approval_requests").
+		Set("status", status).
+		Set("resolved_at", squirrel.Expr("now()")).
+		Where("id = ?", id)
+	if checkerId != nil {
+		builder = builder.Set("checker_id", *checkerId)
+	}
+
+	sql, args, _ := builder.ToSql()
+	_, err := tx.Exec(ctx, sql, args...)
+	return err
+}
+
+func insertApprovalEvent(ctx context.Context, tx pgx.Tx, builder squirrel.StatementBuilderType, event entity.ApprovalEvent) error {
+	sql, args, _ := builder.
+		Insert("approval_events").
+		Columns("request_id", "action", "user_id", "comment").
+		Values(event.RequestId, event.Action, event.UserId, event.Comment).
+		ToSql()
+
+	_, err := tx.Exec(ctx, sql, args...)
+	return err
+}
+
+func scanApprovalRequest(row pgx.Row) (entity.ApprovalRequest, error) {
+	var a entity.ApprovalRequest
+	err := row.Scan(
+		&a.Id,
+		&a.OperationType,
+		&a.AccountId,
+		&a.OrderId,
+		&a.Amount,
+		&a.Wallet,
+		&a.ReasonCode,
+		&a.Comment,
+		&a.Ticket,
+		&a.Status,
+		&a.MakerId,
+		&a.CheckerId,
+		&a.Error,
+		&a.ExpiresAt,
+		&a.ResolvedAt,
+		&a.CreatedAt,
+	)
+	return a, err
+}
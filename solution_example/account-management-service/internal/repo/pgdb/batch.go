@@ -0,0 +1,373 @@
+package pgdb
+
+import (
+	"account-management-service/internal/entity"
+	"account-management-service/internal/repo/repoerrs"
+	"account-management-service/pkg/postgres"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/jackc/pgx/v5"
+	"sort"
+)
+
+type BatchRepo struct {
+	*postgres.Postgres
+}
+
+func NewBatchRepo(pg *postgres.Postgres) *BatchRepo {
+	return &BatchRepo{pg}
+}
+
+type walletKey struct {
+	accountId  int
+	walletType string
+}
+
+// ExecuteBatch executes deposits and transfers in a single transaction. All the involved accounts are locked
+// at once, items are checked one by one against the balances and the rolling window totals updated
+// by the previous items, and then the resulting wallet changes are applied with a pgx batch and operations
+// are written with COPY. The fee of a transfer is paid by the sender and recorded as separate fee lines.
+// If stopOnError is set the first failed item rolls back everything and ErrBatchItemFailed is returned,
+// otherwise failed items are skipped and the rest is committed. Result indexes are positions in items
+func (r *BatchRepo) ExecuteBatch(ctx context.Context, items []entity.BatchItem, description string, stopOnError bool) ([]entity.BatchItemResult, error) {
+	tx, err := r.Pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("BatchRepo.ExecuteBatch - r.Pool.Begin: %v", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	statuses, err := r.lockAccounts(ctx, tx, items)
+	if err != nil {
+		return nil, fmt.Errorf("BatchRepo.ExecuteBatch - r.lockAccounts: %v", err)
+	}
+
+	balances, err := r.mainBalances(ctx, tx, statuses)
+	if err != nil {
+		return nil, fmt.Errorf("BatchRepo.ExecuteBatch - r.mainBalances: %v", err)
+	}
+
+	var opDescription interface{}
+	if description != "" {
+		opDescription = description
+	}
+
+	results := make([]entity.BatchItemResult, len(items))
+	deltas := make(map[walletKey]int)
+	windows := newBatchWindows()
+	var operations [][]interface{}
+	failed := false
+
+	for i, item := range items {
+		results[i] = entity.BatchItemResult{Index: i, Status: entity.BatchItemStatusSucceeded}
+
+		if failed && stopOnError {
+			results[i].Status = entity.BatchItemStatusSkipped
+			continue
+		}
+
+		err = checkBatchItem(item, statuses, balances)
+		if err == nil && item.Type == entity.BatchItemTypeTransfer {
+			err = r.checkBatchTransfer(ctx, tx, item, windows)
+			if err != nil && !isLedgerError(err) {
+				return nil, fmt.Errorf("BatchRepo.ExecuteBatch - r.checkBatchTransfer: %v", err)
+			}
+		}
+		if err != nil {
+			results[i].Status = entity.BatchItemStatusFailed
+			results[i].Error = err.Error()
+			failed = true
+			continue
+		}
+
+		switch item.Type {
+		case entity.BatchItemTypeDeposit:
+			deltas[walletKey{item.AccountId, item.Wallet}] += item.Amount
+			if item.Wallet == entity.WalletTypeMain {
+				balances[item.AccountId] += item.Amount
+			}
+			operations = append(operations,
+				[]interface{}{item.AccountId, item.Amount, entity.OperationTypeDeposit, item.Wallet, opDescription},
+			)
+		case entity.BatchItemTypeTransfer:
+			deltas[walletKey{item.From, entity.WalletTypeMain}] -= item.Amount
+			deltas[walletKey{item.To, entity.WalletTypeMain}] += item.Amount
+			balances[item.From] -= item.Amount
+			balances[item.To] += item.Amount
+			windows.add(item.From, item.Amount)
+			operations = append(operations,
+				[]interface{}{item.From, item.Amount, entity.OperationTypeTransferFrom, entity.WalletTypeMain, opDescription},
+				[]interface{}{item.To, item.Amount, entity.OperationTypeTransferTo, entity.WalletTypeMain, opDescription},
+			)
+
+			// the same lines as collectFee writes
+			if fee := item.Fee; fee.Amount > 0 {
+				deltas[walletKey{item.From, entity.WalletTypeMain}] -= fee.Amount
+				deltas[walletKey{fee.AccountId, entity.WalletTypeMain}] += fee.Amount
+				balances[item.From] -= fee.Amount
+				if _, ok := balances[fee.AccountId]; ok {
+					balances[fee.AccountId] += fee.Amount
+				}
+				operations = append(operations,
+					[]interface{}{item.From, fee.Amount, entity.OperationTypeFee, entity.WalletTypeMain,
+						fmt.Sprintf("%s fee", entity.OperationTypeTransferFrom)},
+					[]interface{}{fee.AccountId, fee.Amount, entity.OperationTypeFeeIncome, entity.WalletTypeMain,
+						fmt.Sprintf("%s fee from account #%d", entity.OperationTypeTransferFrom, item.From)},
+				)
+			}
+		}
+	}
+
+	if failed && stopOnError {
+		// items checked before the failure are not applied either
+		for i := range results {
+			if results[i].Status == entity.BatchItemStatusSucceeded {
+				results[i].Status = entity.BatchItemStatusSkipped
+			}
+		}
+		return results, repoerrs.ErrBatchItemFailed
+	}
+
+	err = r.applyDeltas(ctx, tx, deltas)
+	if err != nil {
+		return nil, fmt.Errorf("BatchRepo.ExecuteBatch - r.applyDeltas: %v", err)
+	}
+
+	_, err = tx.CopyFrom(ctx,
+		pgx.Identifier{"operations"},
+		[]string{"account_id", "amount", "operation_type", "wallet_type", "description"},
+		pgx.CopyFromRows(operations),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("BatchRepo.ExecuteBatch - tx.CopyFrom: %v", err)
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("BatchRepo.ExecuteBatch - tx.Commit: %v", err)
+	}
+
+	return results, nil
+}
+
+// lockAccounts locks every account referenced by the items in ascending id order and returns their statuses
+func (r *BatchRepo) lockAccounts(ctx context.Context, tx pgx.Tx, items []entity.BatchItem) (map[int]string, error) {
+	seen := make(map[int]bool)
+	var ids []int
+	for _, item := range items {
+		for _, id := range []int{item.AccountId, item.From, item.To} {
+			if id != 0 && !seen[id] {
+				seen[id] = true
+				ids = append(ids, id)
+			}
+		}
+	}
+	sort.Ints(ids)
+
+	sql, args, _ := r.Builder.
+		Select("id", "status").
+		From("accounts").
+		Where("id = ANY(?)", ids).
+		OrderBy("id").
+		Suffix("FOR UPDATE").
+		ToSql()
+
+	rows, err := tx.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	statuses := make(map[int]string, len(ids))
+	for rows.Next() {
+		var id int
+		var status string
+		err = rows.Scan(&id, &status)
+		if err != nil {
+			return nil, err
+		}
+		statuses[id] = status
+	}
+
+	return statuses, rows.Err()
+}
+
+// mainBalances returns main wallet balances of the given accounts
+func (r *BatchRepo) mainBalances(ctx context.Context, tx pgx.Tx, statuses map[int]string) (map[int]int, error) {
+	ids := make([]int, 0, len(statuses))
+	for id := range statuses {
+		ids = append(ids, id)
+	}
+
+	sql, args, _ := r.Builder.
+		Select("account_id", "balance").
+		From("wallets").
+		Where("account_id = ANY(?) and wallet_type = ?", ids, entity.WalletTypeMain).
+		ToSql()
+
+	rows, err := tx.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	balances := make(map[int]int, len(ids))
+	for rows.Next() {
+		var id, balance int
+		err = rows.Scan(&id, &balance)
+		if err != nil {
+			return nil, err
+		}
+		balances[id] = balance
+	}
+
+	return balances, rows.Err()
+}
+
+func (r *BatchRepo) applyDeltas(ctx context.Context, tx pgx.Tx, deltas map[walletKey]int) error {
+	batch := &pgx.Batch{}
+	for key, delta := range deltas {
+		if delta == 0 {
+			continue
+		}
+
+		sql, args, _ := r.Builder.
+			Insert("wallets").
+			Columns("account_id", "wallet_type", "balance").
+			Values(key.accountId, key.walletType, delta).
+			Suffix("ON CONFLICT (account_id, wallet_type) DO UPDATE SET balance = wallets.balance + excluded.balance").
+			ToSql()
+
+		batch.Queue(sql, args...)
+	}
+
+	return tx.SendBatch(ctx, batch).Close()
+}
+
+// checkBatchItem returns an error describing why the item cannot be executed, prefixed with the account id
+func checkBatchItem(item entity.BatchItem, statuses map[int]string, balances map[int]int) error {
+	if item.RejectReason != "" {
+		return errors.New(item.RejectReason)
+	}
+
+	switch item.Type {
+	case entity.BatchItemTypeDeposit:
+		return checkBatchAccount(item.AccountId, statuses, canCredit)
+	case entity.BatchItemTypeTransfer:
+		if err := checkBatchAccount(item.From, statuses, canDebit); err != nil {
+			return err
+		}
+		if err := checkBatchAccount(item.To, statuses, canCredit); err != nil {
+			return err
+		}
+		if balances[item.From] < item.Amount+item.Fee.Amount {
+			return fmt.Errorf("account %d: %w", item.From, repoerrs.ErrNotEnoughBalance)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown item type %q", item.Type)
+	}
+}
+
+func checkBatchAccount(id int, statuses map[int]string, allowed []string) error {
+	status, ok := statuses[id]
+	if !ok {
+		return fmt.Errorf("account %d: %w", id, repoerrs.ErrNotFound)
+	}
+	if err := checkAccountStatus(status, allowed...); err != nil {
+		return fmt.Errorf("account %d: %w", id, err)
+	}
+	return nil
+}
+
+// batchWindowKey is the rolling window of the outgoing transfers of the account
+type batchWindowKey struct {
+	accountId     int
+	windowSeconds int
+}
+
+type batchWindowTotals struct {
+	count int
+	total int
+}
+
+// batchWindows keeps the outgoing transfer totals of the accounts within the windows. The totals are read
+// once per window, and the transfers of the batch are added to them as they are not written until the end
+type batchWindows struct {
+	read  map[batchWindowKey]batchWindowTotals
+	batch map[int]batchWindowTotals
+}
+
+func newBatchWindows() *batchWindows {
+	return &batchWindows{
+		read:  make(map[batchWindowKey]batchWindowTotals),
+		batch: make(map[int]batchWindowTotals),
+	}
+}
+
+func (w *batchWindows) add(accountId, amount int) {
+	totals := w.batch[accountId]
+	totals.count++
+	totals.total += amount
+	w.batch[accountId] = totals
+}
+
+func (r *BatchRepo) windowTotals(ctx context.Context, tx pgx.Tx, windows *batchWindows, accountId, windowSeconds int) (batchWindowTotals, error) {
+	key := batchWindowKey{accountId, windowSeconds}
+	totals, ok := windows.read[key]
+	if !ok {
+		count, total, err := windowTotals(ctx, tx, r.Builder, accountId, entity.OperationTypeTransferFrom, windowSeconds)
+		if err != nil {
+			return batchWindowTotals{}, err
+		}
+		totals = batchWindowTotals{count, total}
+		windows.read[key] = totals
+	}
+
+	batch := windows.batch[accountId]
+	return batchWindowTotals{totals.count + batch.count, totals.total + batch.total}, nil
+}
+
+// checkBatchTransfer checks the transfer against the rolling window limits and verification policies of the sender,
+// the same way checkWindowLimits and checkVerification do for a single transfer
+func (r *BatchRepo) checkBatchTransfer(ctx context.Context, tx pgx.Tx, item entity.BatchItem, windows *batchWindows) error {
+	for _, rule := range item.Limits {
+		totals, err := r.windowTotals(ctx, tx, windows, item.From, rule.WindowSeconds)
+		if err != nil {
+			return err
+		}
+
+		if exceedsLimit(rule, totals.count, totals.total, item.Amount) {
+			return fmt.Errorf("account %d: %w", item.From,
+				&repoerrs.LimitExceededError{Rule: rule, OperationType: entity.OperationTypeTransferFrom})
+		}
+	}
+
+	if len(item.Verification.Policies) == 0 {
+		return nil
+	}
+
+	amounts := make(map[int]int)
+	for _, policy := range item.Verification.Policies {
+		if policy.MaxTotal == 0 {
+			continue
+		}
+
+		totals, err := r.windowTotals(ctx, tx, windows, item.From, policy.WindowSeconds)
+		if err != nil {
+			return err
+		}
+		amounts[policy.WindowSeconds] = totals.total
+	}
+
+	level, ok := item.Verification.RequiredLevel(item.Amount, amounts)
+	if ok && level == item.Verification.Level {
+		return nil
+	}
+
+	return fmt.Errorf("account %d: %w", item.From, &repoerrs.VerificationRequiredError{
+		Level:         level,
+		OperationType: entity.OperationTypeTransferFrom,
+		Amount:        item.Amount,
+	})
+}
@@ -0,0 +1,206 @@
+package pgdb
+
+import (
+	"account-management-service/internal/entity"
+	"account-management-service/internal/repo/repoerrs"
+	"account-management-service/pkg/postgres"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+)
+
+var cashbackRuleColumns = []string{
+	"id",
+	"product_id",
+	"rate",
+	"cap",
+	"valid_from",
+	"valid_to",
+	"created_at",
+}
+
+type CashbackRepo struct {
+	*postgres.Postgres
+}
+
+func NewCashbackRepo(pg *postgres.Postgres) *CashbackRepo {
+	return &CashbackRepo{pg}
+}
+
+func (r *CashbackRepo) CreateCashbackRule(ctx context.Context, rule entity.CashbackRule) (int, error) {
+	sql, args, _ := r.Builder.
+		Insert("cashback_rules").
+		Columns("product_id", "rate", "cap", "valid_from", "valid_to").
+		Values(rule.ProductId, rule.Rate, rule.Cap, rule.ValidFrom, rule.ValidTo).
+		Suffix("RETURNING id").
+		ToSql()
+
+	var id int
+	err := r.Pool.QueryRow(ctx, sql, args...).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("CashbackRepo.CreateCashbackRule - r.Pool.QueryRow: %v", err)
+	}
+
+	return id, nil
+}
+
+func (r *CashbackRepo) GetAllCashbackRules(ctx context.Context) ([]entity.CashbackRule, error) {
+	sql, args, _ := r.Builder.
+		Select(cashbackRuleColumns...).
+		From("cashback_rules").
+		OrderBy("id").
+		ToSql()
+
+	rows, err := r.Pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("CashbackRepo.GetAllCashbackRules - r.Pool.Query: %v", err)
+	}
+	defer rows.Close()
+
+	var rules []entity.CashbackRule
+	for rows.Next() {
+		rule, err := scanCashbackRule(rows)
+		if err != nil {
+			return nil, fmt.Errorf("CashbackRepo.GetAllCashbackRules - scanCashbackRule: %v", err)
+		}
+		rules = append(rules, rule)
+	}
+
+	return rules, nil
+}
+
+func (r *CashbackRepo) DeleteCashbackRule(ctx context.Context, id int) error {
+	sql, args, _ := r.Builder.
+		Delete("cashback_rules").
+		Where("id = ?", id).
+		ToSql()
+
+	tag, err := r.Pool.Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("CashbackRepo.DeleteCashbackRule - r.Pool.Exec: %v", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return repoerrs.ErrNotFound
+	}
+
+	return nil
+}
+
+func scanCashbackRule(row pgx.Row) (entity.CashbackRule, error) {
+	var rule entity.CashbackRule
+	err := row.Scan(
+		&rule.Id,
+		&rule.ProductId,
+		&rule.Rate,
+		&rule.Cap,
+		&rule.ValidFrom,
+		&rule.ValidTo,
+		&rule.CreatedAt,
+	)
+	return rule, err
+}
+
+// grantCashback credits bonus cashback for the money spent from the main wallet on a captured order
+// or a subscription charge without the order, using the latest rule of the product which is valid now.
+// Bonus credit spent on the order earns nothing
+func grantCashback(ctx context.Context, tx pgx.Tx, builder squirrel.StatementBuilderType, accountId, productId int, orderId *int, funds []entity.ReservationFund) error {
+	sql, args, _ := builder.
+		Select(cashbackRuleColumns...).
+		From("cashback_rules").
+		Where("product_id = ? and valid_from <= now() and (valid_to is null or valid_to > now())", productId).
+		OrderBy("id DESC").
+		Limit(1).
+		ToSql()
+
+	rule, err := scanCashbackRule(tx.QueryRow(ctx, sql, args...))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil
+		}
+		return err
+	}
+
+	amount := rule.Calculate(mainFundsAmount(funds))
+	if amount == 0 {
+		return nil
+	}
+
+	err = creditWallet(ctx, tx, builder, accountId, entity.WalletTypeBonus, amount)
+	if err != nil {
+		return err
+	}
+
+	return insertOperation(ctx, tx, builder, entity.Operation{
+		AccountId:     accountId,
+		Amount:        amount,
+		OperationType: entity.OperationTypeCashback,
+		WalletType:    entity.WalletTypeBonus,
+		ProductId:     &productId,
+		OrderId:       orderId,
+		Description:   fmt.Sprintf("cashback rule #%d", rule.Id),
+	})
+}
+
+// reverseCashback takes back the cashback granted for the order. What is left of it on the bonus wallet is debited
+// from there and the already spent part is taken from the main wallet, which has just received the refund.
+// The account must be locked by the caller
+func reverseCashback(ctx context.Context, tx pgx.Tx, builder squirrel.StatementBuilderType, accountId, productId, orderId int) error {
+	sql, args, _ := builder.
+		Select("COALESCE(sum(amount), 0)").
+		From("operations").
+		Where("order_id = ? and operation_type = ?", orderId, entity.OperationTypeCashback).
+		ToSql()
+
+	var cashback int
+	err := tx.QueryRow(ctx, sql, args...).Scan(&cashback)
+	if err != nil {
+		return err
+	}
+	if cashback == 0 {
+		return nil
+	}
+
+	balances, err := walletBalances(ctx, tx, builder, accountId)
+	if err != nil {
+		return err
+	}
+
+	parts, err := splitByWallets(balances, []string{entity.WalletTypeBonus, entity.WalletTypeMain}, cashback)
+	if err != nil {
+		return err
+	}
+
+	for _, part := range parts {
+		err = debitWallet(ctx, tx, builder, accountId, part.WalletType, part.Amount)
+		if err != nil {
+			return err
+		}
+
+		err = insertOperation(ctx, tx, builder, entity.Operation{
+			AccountId:     accountId,
+			Amount:        part.Amount,
+			OperationType: entity.OperationTypeCashbackReversal,
+			WalletType:    part.WalletType,
+			ProductId:     &productId,
+			OrderId:       &orderId,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func mainFundsAmount(funds []entity.ReservationFund) int {
+	amount := 0
+	for _, fund := range funds {
+		if fund.WalletType == entity.WalletTypeMain {
+			amount += fund.Amount
+		}
+	}
+	return amount
+}
@@ -0,0 +1,156 @@
+package pgdb
+
+import (
+	"account-management-service/internal/entity"
+	"account-management-service/internal/repo/repoerrs"
+	"account-management-service/pkg/postgres"
+	"context"
+	"fmt"
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+)
+
+var feeRuleColumns = []string{
+	"id",
+	"operation_type",
+	"product_id",
+	"account_type",
+	"rate",
+	"fixed",
+	"min_fee",
+	"max_fee",
+	"free_up_to",
+	"created_at",
+}
+
+type FeeRepo struct {
+	*postgres.Postgres
+}
+
+func NewFeeRepo(pg *postgres.Postgres) *FeeRepo {
+	return &FeeRepo{pg}
+}
+
+func (r *FeeRepo) CreateFeeRule(ctx context.Context, rule entity.FeeRule) (int, error) {
+	sql, args, _ := r.Builder.
+		Insert("fee_rules").
+		Columns("operation_type", "product_id", "account_type", "rate", "fixed", "min_fee", "max_fee", "free_up_to").
+		Values(
+			rule.OperationType,
+			rule.ProductId,
+			rule.AccountType,
+			rule.Rate,
+			rule.Fixed,
+			rule.MinFee,
+			rule.MaxFee,
+			rule.FreeUpTo,
+		).
+		Suffix("RETURNING id").
+		ToSql()
+
+	var id int
+	err := r.Pool.QueryRow(ctx, sql, args...).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("FeeRepo.CreateFeeRule - r.Pool.QueryRow: %v", err)
+	}
+
+	return id, nil
+}
+
+func (r *FeeRepo) GetAllFeeRules(ctx context.Context) ([]entity.FeeRule, error) {
+	sql, args, _ := r.Builder.
+		Select(feeRuleColumns...).
+		From("fee_rules").
+		OrderBy("id").
+		ToSql()
+
+	rows, err := r.Pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("FeeRepo.GetAllFeeRules - r.Pool.Query: %v", err)
+	}
+	defer rows.Close()
+
+	rules, err := scanFeeRules(rows)
+	if err != nil {
+		return nil, fmt.Errorf("FeeRepo.GetAllFeeRules - scanFeeRules: %v", err)
+	}
+
+	return rules, nil
+}
+
+// GetFeeRulesForOperation returns the rules applicable to the operation, the most specific ones first:
+// product rules go before the rules for any product, account type rules before the rules for any account
+func (r *FeeRepo) GetFeeRulesForOperation(ctx context.Context, operationType string, productId int, accountType string) ([]entity.FeeRule, error) {
+	products := squirrel.Or{squirrel.Eq{"product_id": nil}}
+	if productId != 0 {
+		products = append(products, squirrel.Eq{"product_id": productId})
+	}
+
+	sql, args, _ := r.Builder.
+		Select(feeRuleColumns...).
+		From("fee_rules").
+		Where("operation_type = ?", operationType).
+		Where(products).
+		Where(squirrel.Or{
+			squirrel.Eq{"account_type": nil},
+			squirrel.Eq{"account_type": accountType},
+		}).
+		OrderBy("product_id is null", "account_type is null", "id").
+		ToSql()
+
+	rows, err := r.Pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("FeeRepo.GetFeeRulesForOperation - r.Pool.Query: %v", err)
+	}
+	defer rows.Close()
+
+	rules, err := scanFeeRules(rows)
+	if err != nil {
+		return nil, fmt.Errorf("FeeRepo.GetFeeRulesForOperation - scanFeeRules: %v", err)
+	}
+
+	return rules, nil
+}
+
+func (r *FeeRepo) DeleteFeeRule(ctx context.Context, id int) error {
+	sql, args, _ := r.Builder.
+		Delete("fee_rules").
+		Where("id = ?", id).
+		ToSql()
+
+	tag, err := r.Pool.Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("FeeRepo.DeleteFeeRule - r.Pool.Exec: %v", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return repoerrs.ErrNotFound
+	}
+
+	return nil
+}
+
+func scanFeeRules(rows pgx.Rows) ([]entity.FeeRule, error) {
+	var rules []entity.FeeRule
+	for rows.Next() {
+		var rule entity.FeeRule
+		err := rows.Scan(
+			&rule.Id,
+			&rule.OperationType,
+			&rule.ProductId,
+			&rule.AccountType,
+			&rule.Rate,
+			&rule.Fixed,
+			&rule.MinFee,
+			&rule.MaxFee,
+			&rule.FreeUpTo,
+			&rule.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+
+	return rules, rows.Err()
+}
@@ -0,0 +1,254 @@
+package pgdb
+
+import (
+	"account-management-service/internal/entity"
+	"account-management-service/internal/repo/repoerrs"
+	"account-management-service/pkg/postgres"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+var groupMemberColumns = []string{
+	"account_id",
+	"user_id",
+	"role",
+	"monthly_limit",
+	"created_at",
+}
+
+// memberSpentSum sums the money reserved, withdrawn and transferred by a member net of the refunded orders
+var memberSpentSum = fmt.Sprintf(
+	"sum(case when operation_type in ('%s', '%s', '%s') then amount when operation_type in ('%s', '%s') then -amount else 0 end)",
+	entity.OperationTypeReservation, entity.OperationTypeWithdraw, entity.OperationTypeTransferFrom,
+	entity.OperationTypeRefund, entity.OperationTypeRevenueRefund,
+)
+
+type GroupRepo struct {
+	*postgres.Postgres
+}
+
+func NewGroupRepo(pg *postgres.Postgres) *GroupRepo {
+	return &GroupRepo{pg}
+}
+
+// CreateGroupAccount creates a business account shared by its members, the creator becomes its owner
+func (r *GroupRepo) CreateGroupAccount(ctx context.Context, ownerId int) (int, error) {
+	tx, err := r.Pool.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("GroupRepo.CreateGroupAccount - r.Pool.Begin: %v", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	sql, args, _ := r.Builder.
+		Insert("accounts").
+		Columns("type").
+		Values(entity.AccountTypeBusiness).
+		Suffix("RETURNING id").
+		ToSql()
+
+	var id int
+	err = tx.QueryRow(ctx, sql, args...).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("GroupRepo.CreateGroupAccount - tx.QueryRow: %v", err)
+	}
+
+	sql, args, _ = r.Builder.
+		Insert("account_members").
+		Columns("account_id", "user_id", "role").
+		Values(id, ownerId, entity.GroupRoleOwner).
+		ToSql()
+
+	_, err = tx.Exec(ctx, sql, args...)
+	if err != nil {
+		return 0, fmt.Errorf("GroupRepo.CreateGroupAccount - tx.Exec: %v", err)
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("GroupRepo.CreateGroupAccount - tx.Commit: %v", err)
+	}
+
+	return id, nil
+}
+
+// SetGroupMember adds the member or changes the role and the limit of an existing one.
+// ErrNotFound is returned if there is no such user or account
+func (r *GroupRepo) SetGroupMember(ctx context.Context, member entity.GroupMember) error {
+	sql, args, _ := r.Builder.
+		Insert("account_members").
+		Columns("account_id", "user_id", "role", "monthly_limit").
+		Values(member.AccountId, member.UserId, member.Role, member.MonthlyLimit).
+		Suffix("ON CONFLICT (account_id, user_id) DO UPDATE SET role = excluded.role, monthly_limit = excluded.monthly_limit").
+		ToSql()
+
+	_, err := r.Pool.Exec(ctx, sql, args...)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if ok := errors.As(err, &pgErr); ok {
+			if pgErr.Code == "23503" {
+				return repoerrs.ErrNotFound
+			}
+		}
+		return fmt.Errorf("GroupRepo.SetGroupMember - r.Pool.Exec: %v", err)
+	}
+
+	return nil
+}
+
+func (r *GroupRepo) RemoveGroupMember(ctx context.Context, accountId, userId int) error {
+	sql, args, _ := r.Builder.
+		Delete("account_members").
+		Where("account_id = ? and user_id = ?", accountId, userId).
+		ToSql()
+
+	tag, err := r.Pool.Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("GroupRepo.RemoveGroupMember - r.Pool.Exec: %v", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return repoerrs.ErrNotFound
+	}
+
+	return nil
+}
+
+func (r *GroupRepo) GetGroupMember(ctx context.Context, accountId, userId int) (entity.GroupMember, error) {
+	sql, args, _ := r.Builder.
+		Select(groupMemberColumns...).
+		From("account_members").
+		Where("account_id = ? and user_id = ?", accountId, userId).
+		ToSql()
+
+	member, err := scanGroupMember(r.Pool.QueryRow(ctx, sql, args...))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return entity.GroupMember{}, repoerrs.ErrNotFound
+		}
+		return entity.GroupMember{}, fmt.Errorf("GroupRepo.GetGroupMember - r.Pool.QueryRow: %v", err)
+	}
+
+	return member, nil
+}
+
+func (r *GroupRepo) GetGroupMembers(ctx context.Context, accountId int) ([]entity.GroupMember, error) {
+	sql, args, _ := r.Builder.
+		Select(groupMemberColumns...).
+		From("account_members").
+		Where("account_id = ?", accountId).
+		OrderBy("created_at", "user_id").
+		ToSql()
+
+	rows, err := r.Pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("GroupRepo.GetGroupMembers - r.Pool.Query: %v", err)
+	}
+	defer rows.Close()
+
+	var members []entity.GroupMember
+	for rows.Next() {
+		member, err := scanGroupMember(rows)
+		if err != nil {
+			return nil, fmt.Errorf("GroupRepo.GetGroupMembers - rows.Scan: %v", err)
+		}
+		members = append(members, member)
+	}
+
+	return members, nil
+}
+
+// checkAccountSpending checks that the user may spend amount from the account and returns the member
+// to record as the spender of the operation. Anyone may spend from an account without members and nil is returned,
+// the shared account may be spent only by its members within their limits. userId is nil for the operations
+// the service runs itself, like scheduled transfers and subscription charges, they are checked when created.
+// The account must be locked by the caller
+func checkAccountSpending(ctx context.Context, tx pgx.Tx, builder squirrel.StatementBuilderType, accountId int, userId *int, amount int) (*int, error) {
+	if userId == nil {
+		return nil, nil
+	}
+
+	sql, args, _ := builder.
+		Select("count(*) > 0").
+		From("account_members").
+		Where("account_id = ?", accountId).
+		ToSql()
+
+	var shared bool
+	err := tx.QueryRow(ctx, sql, args...).Scan(&shared)
+	if err != nil {
+		return nil, err
+	}
+	if !shared {
+		return nil, nil
+	}
+
+	err = checkMemberSpending(ctx, tx, builder, accountId, *userId, amount)
+	if err != nil {
+		return nil, err
+	}
+
+	return userId, nil
+}
+
+// checkMemberSpending returns an error if the user may not spend amount from the shared account:
+// the user is not a member or is a viewer, or the amount exceeds what is left of the member monthly limit.
+// The account must be locked by the caller, so concurrent reservations of the member are serialized
+func checkMemberSpending(ctx context.Context, tx pgx.Tx, builder squirrel.StatementBuilderType, accountId, userId, amount int) error {
+	sql, args, _ := builder.
+		Select("role", "monthly_limit").
+		From("account_members").
+		Where("account_id = ? and user_id = ?", accountId, userId).
+		ToSql()
+
+	var role string
+	var monthlyLimit int
+	err := tx.QueryRow(ctx, sql, args...).Scan(&role, &monthlyLimit)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return repoerrs.ErrNotGroupMember
+		}
+		return err
+	}
+
+	if role == entity.GroupRoleViewer {
+		return repoerrs.ErrMemberCannotSpend
+	}
+	if monthlyLimit == 0 {
+		return nil
+	}
+
+	sql, args, _ = builder.
+		Select("COALESCE("+memberSpentSum+", 0)").
+		From("operations").
+		Where("account_id = ? and user_id = ?", accountId, userId).
+		Where("created_at >= date_trunc('month', now())").
+		ToSql()
+
+	var spent int
+	err = tx.QueryRow(ctx, sql, args...).Scan(&spent)
+	if err != nil {
+		return err
+	}
+
+	if spent+amount > monthlyLimit {
+		return repoerrs.ErrMemberLimitExceeded
+	}
+
+	return nil
+}
+
+func scanGroupMember(row pgx.Row) (entity.GroupMember, error) {
+	var member entity.GroupMember
+	err := row.Scan(
+		&member.AccountId,
+		&member.UserId,
+		&member.Role,
+		&member.MonthlyLimit,
+		&member.CreatedAt,
+	)
+	return member, err
+}
@@ -0,0 +1,644 @@
+package pgdb
+
+import (
+	"account-management-service/internal/entity"
+	"account-management-service/internal/repo/repoerrs"
+	"account-management-service/pkg/postgres"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"time"
+)
+
+var installmentPlanColumns = []string{
+	"id",
+	"account_id",
+	"product_id",
+	"order_id",
+	"amount",
+	"period",
+	"status",
+	"(select COALESCE(sum(amount), 0) from installment_parts where plan_id = installment_plans.id and status = 'paid')",
+	"created_at",
+}
+
+var installmentPartColumns = []string{
+	"installment_parts.id",
+	"plan_id",
+	"number",
+	"installment_parts.amount",
+	"due_at",
+	"installment_parts.status",
+	"attempts",
+	"next_attempt_at",
+	"COALESCE(error, '')",
+	"paid_at",
+}
+
+// unpaidPlanStatuses are the statuses of plans which still hold the order
+var unpaidPlanStatuses = []string{entity.InstallmentPlanStatusActive, entity.InstallmentPlanStatusDefaulted}
+
+type InstallmentRepo struct {
+	*postgres.Postgres
+}
+
+func NewInstallmentRepo(pg *postgres.Postgres) *InstallmentRepo {
+	return &InstallmentRepo{pg}
+}
+
+// CreateInstallmentPlan creates the plan with its parts and reserves the first part right away,
+// the reservation limits are checked and the fee is collected for the first part like for a reservation.
+// ErrAlreadyExists is returned if the order already has a reservation or a plan
+func (r *InstallmentRepo) CreateInstallmentPlan(ctx context.Context, plan entity.InstallmentPlan, userId *int, parts []entity.InstallmentPart, spendingOrder []string, fee entity.Fee, limits []entity.LimitRule) (int, error) {
+	tx, err := r.Pool.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("InstallmentRepo.CreateInstallmentPlan - r.Pool.Begin: %v", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	status, err := lockAccount(ctx, tx, r.Builder, plan.AccountId)
+	if err != nil {
+		if errors.Is(err, repoerrs.ErrNotFound) {
+			return 0, err
+		}
+		return 0, fmt.Errorf("InstallmentRepo.CreateInstallmentPlan - lockAccount: %v", err)
+	}
+
+	if err = checkAccountStatus(status, canDebit...); err != nil {
+		return 0, err
+	}
+
+	err = checkWindowLimits(ctx, tx, r.Builder, plan.AccountId, entity.OperationTypeReservation, parts[0].Amount, limits)
+	if err != nil {
+		if errors.Is(err, repoerrs.ErrLimitExceeded) {
+			return 0, err
+		}
+		return 0, fmt.Errorf("InstallmentRepo.CreateInstallmentPlan - checkWindowLimits: %v", err)
+	}
+
+	userId, err = checkAccountSpending(ctx, tx, r.Builder, plan.AccountId, userId, parts[0].Amount)
+	if err != nil {
+		if errors.Is(err, repoerrs.ErrNotGroupMember) || errors.Is(err, repoerrs.ErrMemberCannotSpend) ||
+			errors.Is(err, repoerrs.ErrMemberLimitExceeded) {
+			return 0, err
+		}
+		return 0, fmt.Errorf("InstallmentRepo.CreateInstallmentPlan - checkAccountSpending: %v", err)
+	}
+
+	sql, args, _ := r.Builder.
+		Select("count(*)").
+		From("reservations").
+		Where("order_id = ?", plan.OrderId).
+		ToSql()
+
+	var reservations int
+	err = tx.QueryRow(ctx, sql, args...).Scan(&reservations)
+	if err != nil {
+		return 0, fmt.Errorf("InstallmentRepo.CreateInstallmentPlan - tx.QueryRow: %v", err)
+	}
+	if reservations > 0 {
+		return 0, repoerrs.ErrAlreadyExists
+	}
+
+	funds, err := installmentFunds(ctx, tx, r.Builder, plan.AccountId, parts[0].Amount, fee, spendingOrder)
+	if err != nil {
+		if errors.Is(err, repoerrs.ErrNotEnoughBalance) {
+			return 0, err
+		}
+		return 0, fmt.Errorf("InstallmentRepo.CreateInstallmentPlan - installmentFunds: %v", err)
+	}
+
+	sql, args, _ = r.Builder.
+		Insert("installment_plans").
+		Columns("account_id", "product_id", "order_id", "amount", "period").
+		Values(plan.AccountId, plan.ProductId, plan.OrderId, plan.Amount, plan.Period).
+		Suffix("RETURNING id").
+		ToSql()
+
+	err = tx.QueryRow(ctx, sql, args...).Scan(&plan.Id)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if ok := errors.As(err, &pgErr); ok {
+			if pgErr.Code == "23505" {
+				return 0, repoerrs.ErrAlreadyExists
+			}
+		}
+		return 0, fmt.Errorf("InstallmentRepo.CreateInstallmentPlan - tx.QueryRow: %v", err)
+	}
+
+	insert := r.Builder.
+		Insert("installment_parts").
+		Columns("plan_id", "number", "amount", "due_at", "status", "attempts", "next_attempt_at", "paid_at")
+	for i, part := range parts {
+		if i == 0 {
+			insert = insert.Values(plan.Id, part.Number, part.Amount, part.DueAt, entity.InstallmentPartStatusPaid, 1, part.DueAt, squirrel.Expr("now()"))
+			continue
+		}
+		insert = insert.Values(plan.Id, part.Number, part.Amount, part.DueAt, entity.InstallmentPartStatusPending, 0, part.DueAt, nil)
+	}
+
+	sql, args, _ = insert.ToSql()
+	_, err = tx.Exec(ctx, sql, args...)
+	if err != nil {
+		return 0, fmt.Errorf("InstallmentRepo.CreateInstallmentPlan - tx.Exec: %v", err)
+	}
+
+	err = reserveInstallmentPart(ctx, tx, r.Builder, plan, parts[0], userId, funds)
+	if err != nil {
+		return 0, fmt.Errorf("InstallmentRepo.CreateInstallmentPlan - reserveInstallmentPart: %v", err)
+	}
+
+	err = collectFee(ctx, tx, r.Builder, plan.AccountId, fee, entity.OperationTypeReservation)
+	if err != nil {
+		return 0, fmt.Errorf("InstallmentRepo.CreateInstallmentPlan - collectFee: %v", err)
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("InstallmentRepo.CreateInstallmentPlan - tx.Commit: %v", err)
+	}
+
+	return plan.Id, nil
+}
+
+func (r *InstallmentRepo) GetInstallmentPlanById(ctx context.Context, id int) (entity.InstallmentPlan, error) {
+	sql, args, _ := r.Builder.
+		Select(installmentPlanColumns...).
+		From("installment_plans").
+		Where("id = ?", id).
+		ToSql()
+
+	var plan entity.InstallmentPlan
+	err := r.Pool.QueryRow(ctx, sql, args...).Scan(
+		&plan.Id,
+		&plan.AccountId,
+		&plan.ProductId,
+		&plan.OrderId,
+		&plan.Amount,
+		&plan.Period,
+		&plan.Status,
+		&plan.PaidAmount,
+		&plan.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return entity.InstallmentPlan{}, repoerrs.ErrNotFound
+		}
+		return entity.InstallmentPlan{}, fmt.Errorf("InstallmentRepo.GetInstallmentPlanById - r.Pool.QueryRow: %v", err)
+	}
+
+	return plan, nil
+}
+
+func (r *InstallmentRepo) GetInstallmentParts(ctx context.Context, planId int) ([]entity.InstallmentPart, error) {
+	sql, args, _ := r.Builder.
+		Select(installmentPartColumns...).
+		From("installment_parts").
+		Where("plan_id = ?", planId).
+		OrderBy("number").
+		ToSql()
+
+	rows, err := r.Pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("InstallmentRepo.GetInstallmentParts - r.Pool.Query: %v", err)
+	}
+	defer rows.Close()
+
+	var parts []entity.InstallmentPart
+	for rows.Next() {
+		part, err := scanInstallmentPart(rows)
+		if err != nil {
+			return nil, fmt.Errorf("InstallmentRepo.GetInstallmentParts - rows.Scan: %v", err)
+		}
+		parts = append(parts, part)
+	}
+
+	return parts, nil
+}
+
+// GetInstallmentObligations returns the unpaid parts of the account plans which still hold their orders,
+// the closest due first
+func (r *InstallmentRepo) GetInstallmentObligations(ctx context.Context, accountId int) ([]entity.InstallmentObligation, error) {
+	sql, args, _ := r.Builder.
+		Select(
+			"installment_plans.id",
+			"order_id",
+			"product_id",
+			"number",
+			"installment_parts.amount",
+			"due_at",
+			"attempts",
+		).
+		From("installment_parts").
+		InnerJoin("installment_plans on installment_plans.id = installment_parts.plan_id").
+		Where(squirrel.Eq{
+			"account_id":               accountId,
+			"installment_plans.status": unpaidPlanStatuses,
+			"installment_parts.status": entity.InstallmentPartStatusPending,
+		}).
+		OrderBy("due_at", "installment_parts.id").
+		ToSql()
+
+	rows, err := r.Pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("InstallmentRepo.GetInstallmentObligations - r.Pool.Query: %v", err)
+	}
+	defer rows.Close()
+
+	var obligations []entity.InstallmentObligation
+	for rows.Next() {
+		var obligation entity.InstallmentObligation
+		err = rows.Scan(
+			&obligation.PlanId,
+			&obligation.OrderId,
+			&obligation.ProductId,
+			&obligation.Number,
+			&obligation.Amount,
+			&obligation.DueAt,
+			&obligation.Attempts,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("InstallmentRepo.GetInstallmentObligations - rows.Scan: %v", err)
+		}
+		obligations = append(obligations, obligation)
+	}
+
+	return obligations, nil
+}
+
+// GetDueInstallmentParts returns parts of active plans which have to be reserved now
+func (r *InstallmentRepo) GetDueInstallmentParts(ctx context.Context, limit int) ([]entity.DueInstallmentPart, error) {
+	sql, args, _ := r.dueParts("installment_parts.id", "account_id", "product_id", "installment_parts.amount").
+		OrderBy("next_attempt_at").
+		Limit(uint64(limit)).
+		ToSql()
+
+	rows, err := r.Pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("InstallmentRepo.GetDueInstallmentParts - r.Pool.Query: %v", err)
+	}
+	defer rows.Close()
+
+	var parts []entity.DueInstallmentPart
+	for rows.Next() {
+		var part entity.DueInstallmentPart
+		err = rows.Scan(&part.Id, &part.AccountId, &part.ProductId, &part.Amount)
+		if err != nil {
+			return nil, fmt.Errorf("InstallmentRepo.GetDueInstallmentParts - rows.Scan: %v", err)
+		}
+		parts = append(parts, part)
+	}
+
+	return parts, nil
+}
+
+// ChargeInstallmentPart adds a due part to the order reservation, the reservation limits are checked
+// and the fee is collected like for a reservation. If rejectReason is not empty the charge is not attempted
+// and is recorded as failed with that reason. A failed attempt is recorded and retried as the policy says,
+// after the last attempt the order is either cancelled with all the paid parts returned
+// or the plan is marked as defaulted. ErrNotFound is returned if the part is not due anymore
+func (r *InstallmentRepo) ChargeInstallmentPart(ctx context.Context, id int, rejectReason string, fee entity.Fee, limits []entity.LimitRule, spendingOrder []string, policy entity.MissedInstallmentPolicy) (entity.InstallmentPart, error) {
+	tx, err := r.Pool.Begin(ctx)
+	if err != nil {
+		return entity.InstallmentPart{}, fmt.Errorf("InstallmentRepo.ChargeInstallmentPart - r.Pool.Begin: %v", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	sql, args, _ := r.dueParts("installment_plans.account_id", "installment_plans.order_id").
+		Where("installment_parts.id = ?", id).
+		ToSql()
+
+	var plan entity.InstallmentPlan
+	err = tx.QueryRow(ctx, sql, args...).Scan(&plan.AccountId, &plan.OrderId)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return entity.InstallmentPart{}, repoerrs.ErrNotFound
+		}
+		return entity.InstallmentPart{}, fmt.Errorf("InstallmentRepo.ChargeInstallmentPart - tx.QueryRow: %v", err)
+	}
+
+	// the order reservation and then the account are locked first, in the same order as refunds do,
+	// so a concurrent refund either cancels the plan before the part is locked or waits for the charge
+	sql, args, _ = r.Builder.
+		Select("id").
+		From("reservations").
+		Where("order_id = ?", plan.OrderId).
+		Suffix("FOR UPDATE").
+		ToSql()
+
+	var reservationId int
+	err = tx.QueryRow(ctx, sql, args...).Scan(&reservationId)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return entity.InstallmentPart{}, fmt.Errorf("InstallmentRepo.ChargeInstallmentPart - tx.QueryRow: %v", err)
+	}
+
+	status, err := lockAccount(ctx, tx, r.Builder, plan.AccountId)
+	if err != nil {
+		return entity.InstallmentPart{}, fmt.Errorf("InstallmentRepo.ChargeInstallmentPart - lockAccount: %v", err)
+	}
+
+	// skip locked rows, they are being charged by another instance right now
+	sql, args, _ = r.dueParts(append(installmentPartColumns, "installment_plans.product_id", "installment_plans.id", "now()")...).
+		Where("installment_parts.id = ?", id).
+		Suffix("FOR UPDATE OF installment_parts SKIP LOCKED").
+		ToSql()
+
+	var part entity.InstallmentPart
+	var now time.Time
+	err = tx.QueryRow(ctx, sql, args...).Scan(
+		&part.Id,
+		&part.PlanId,
+		&part.Number,
+		&part.Amount,
+		&part.DueAt,
+		&part.Status,
+		&part.Attempts,
+		&part.NextAttemptAt,
+		&part.Error,
+		&part.PaidAt,
+		&plan.ProductId,
+		&plan.Id,
+		&now,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return entity.InstallmentPart{}, repoerrs.ErrNotFound
+		}
+		return entity.InstallmentPart{}, fmt.Errorf("InstallmentRepo.ChargeInstallmentPart - tx.QueryRow: %v", err)
+	}
+
+	part.Attempts++
+
+	var funds []entity.ReservationFund
+	var chargeErr error
+	if rejectReason != "" {
+		chargeErr = errors.New(rejectReason)
+	} else {
+		chargeErr = checkAccountStatus(status, canDebit...)
+		if chargeErr == nil {
+			chargeErr = checkWindowLimits(ctx, tx, r.Builder, plan.AccountId, entity.OperationTypeReservation, part.Amount, limits)
+		}
+		if chargeErr == nil {
+			funds, chargeErr = installmentFunds(ctx, tx, r.Builder, plan.AccountId, part.Amount, fee, spendingOrder)
+		}
+		if chargeErr != nil && !errors.Is(chargeErr, repoerrs.ErrNotEnoughBalance) && !errors.Is(chargeErr, repoerrs.ErrAccountFrozen) &&
+			!errors.Is(chargeErr, repoerrs.ErrAccountClosed) && !errors.Is(chargeErr, repoerrs.ErrLimitExceeded) {
+			return entity.InstallmentPart{}, fmt.Errorf("InstallmentRepo.ChargeInstallmentPart - installmentFunds: %v", chargeErr)
+		}
+	}
+
+	if chargeErr != nil {
+
+		part.Error = chargeErr.Error()
+		part.NextAttemptAt = now.Add(policy.RetryInterval)
+		err = r.missInstallmentPart(ctx, tx, plan, &part, policy)
+		if err != nil {
+			return entity.InstallmentPart{}, fmt.Errorf("InstallmentRepo.ChargeInstallmentPart - r.missInstallmentPart: %v", err)
+		}
+	} else {
+		err = reserveInstallmentPart(ctx, tx, r.Builder, plan, part, nil, funds)
+		if err != nil {
+			return entity.InstallmentPart{}, fmt.Errorf("InstallmentRepo.ChargeInstallmentPart - reserveInstallmentPart: %v", err)
+		}
+
+		err = collectFee(ctx, tx, r.Builder, plan.AccountId, fee, entity.OperationTypeReservation)
+		if err != nil {
+			return entity.InstallmentPart{}, fmt.Errorf("InstallmentRepo.ChargeInstallmentPart - collectFee: %v", err)
+		}
+
+		part.Status = entity.InstallmentPartStatusPaid
+		part.Error = ""
+		part.PaidAt = &now
+		err = r.payInstallmentPart(ctx, tx, plan, part)
+		if err != nil {
+			return entity.InstallmentPart{}, fmt.Errorf("InstallmentRepo.ChargeInstallmentPart - r.payInstallmentPart: %v", err)
+		}
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return entity.InstallmentPart{}, fmt.Errorf("InstallmentRepo.ChargeInstallmentPart - tx.Commit: %v", err)
+	}
+
+	return part, nil
+}
+
+// dueParts selects the pending parts of active plans whose next attempt has come
+func (r *InstallmentRepo) dueParts(columns ...string) squirrel.SelectBuilder {
+	return r.Builder.
+		Select(columns...).
+		From("installment_parts").
+		InnerJoin("installment_plans on installment_plans.id = installment_parts.plan_id").
+		Where(squirrel.Eq{
+			"installment_parts.status": entity.InstallmentPartStatusPending,
+			"installment_plans.status": entity.InstallmentPlanStatusActive,
+		}).
+		Where("next_attempt_at <= now()")
+}
+
+// payInstallmentPart marks the part as paid and the plan as paid if it was the last unpaid part
+func (r *InstallmentRepo) payInstallmentPart(ctx context.Context, tx pgx.Tx, plan entity.InstallmentPlan, part entity.InstallmentPart) error {
+	sql, args, _ := r.Builder.
+		Update("installment_parts").
+		Set("status", part.Status).
+		Set("attempts", part.Attempts).
+		Set("error", nil).
+		Set("paid_at", part.PaidAt).
+		Where("id = ?", part.Id).
+		ToSql()
+
+	_, err := tx.Exec(ctx, sql, args...)
+	if err != nil {
+		return err
+	}
+
+	sql, args, _ = r.Builder.
+		Update("installment_plans").
+		Set("status", entity.InstallmentPlanStatusPaid).
+		Where("id = ?", plan.Id).
+		Where("not exists (select 1 from installment_parts where plan_id = ? and status = ?)", plan.Id, entity.InstallmentPartStatusPending).
+		ToSql()
+
+	_, err = tx.Exec(ctx, sql, args...)
+	return err
+}
+
+// missInstallmentPart records the failed attempt and applies the policy once the attempts are over
+func (r *InstallmentRepo) missInstallmentPart(ctx context.Context, tx pgx.Tx, plan entity.InstallmentPlan, part *entity.InstallmentPart, policy entity.MissedInstallmentPolicy) error {
+	sql, args, _ := r.Builder.
+		Update("installment_parts").
+		Set("attempts", part.Attempts).
+		Set("error", part.Error).
+		Set("next_attempt_at", part.NextAttemptAt).
+		Where("id = ?", part.Id).
+		ToSql()
+
+	_, err := tx.Exec(ctx, sql, args...)
+	if err != nil {
+		return err
+	}
+
+	if part.Attempts < policy.MaxAttempts {
+		return nil
+	}
+
+	if !policy.CancelOrder {
+		sql, args, _ = r.Builder.
+			Update("installment_plans").
+			Set("status", entity.InstallmentPlanStatusDefaulted).
+			Where("id = ?", plan.Id).
+			ToSql()
+
+		_, err = tx.Exec(ctx, sql, args...)
+		return err
+	}
+
+	// the refund of the order also cancels the plan with its pending parts
+	err = finishReservationTx(ctx, tx, r.Builder, "order_id = ?", plan.OrderId, entity.OperationTypeRefund)
+	if errors.Is(err, pgx.ErrNoRows) {
+		err = cancelInstallmentPlan(ctx, tx, r.Builder, plan.OrderId)
+	}
+	if err != nil {
+		return err
+	}
+
+	part.Status = entity.InstallmentPartStatusCancelled
+	return nil
+}
+
+// installmentFunds returns the wallets to take the part amount from, leaving the fee in the main wallet.
+// The account must be locked by the caller
+func installmentFunds(ctx context.Context, tx pgx.Tx, builder squirrel.StatementBuilderType, accountId, amount int, fee entity.Fee, spendingOrder []string) ([]entity.ReservationFund, error) {
+	balances, err := walletBalances(ctx, tx, builder, accountId)
+	if err != nil {
+		return nil, err
+	}
+
+	// the fee can be paid from the main wallet only, so it is set aside before the amount is split
+	balances[entity.WalletTypeMain] -= fee.Amount
+	if balances[entity.WalletTypeMain] < 0 {
+		return nil, repoerrs.ErrNotEnoughBalance
+	}
+
+	return splitByWallets(balances, spendingOrder, amount)
+}
+
+// reserveInstallmentPart takes the part from the account wallets and adds it to the order reservation,
+// which is created by the first part
+func reserveInstallmentPart(ctx context.Context, tx pgx.Tx, builder squirrel.StatementBuilderType, plan entity.InstallmentPlan, part entity.InstallmentPart, userId *int, funds []entity.ReservationFund) error {
+	sql, args, _ := builder.
+		Insert("reservations").
+		Columns("account_id", "product_id", "order_id", "amount", "user_id").
+		Values(plan.AccountId, plan.ProductId, plan.OrderId, part.Amount, userId).
+		Suffix("ON CONFLICT (order_id) DO UPDATE SET amount = reservations.amount + excluded.amount RETURNING id").
+		ToSql()
+
+	var reservationId int
+	err := tx.QueryRow(ctx, sql, args...).Scan(&reservationId)
+	if err != nil {
+		return err
+	}
+
+	for _, fund := range funds {
+		err = debitWallet(ctx, tx, builder, plan.AccountId, fund.WalletType, fund.Amount)
+		if err != nil {
+			return err
+		}
+
+		sql, args, _ = builder.
+			Insert("reservation_funds").
+			Columns("reservation_id", "wallet_type", "amount").
+			Values(reservationId, fund.WalletType, fund.Amount).
+			Suffix("ON CONFLICT (reservation_id, wallet_type) DO UPDATE SET amount = reservation_funds.amount + excluded.amount").
+			ToSql()
+
+		_, err = tx.Exec(ctx, sql, args...)
+		if err != nil {
+			return err
+		}
+
+		err = insertOperation(ctx, tx, builder, entity.Operation{
+			AccountId:     plan.AccountId,
+			Amount:        fund.Amount,
+			OperationType: entity.OperationTypeReservation,
+			WalletType:    fund.WalletType,
+			ProductId:     &plan.ProductId,
+			OrderId:       &plan.OrderId,
+			UserId:        userId,
+			Description:   fmt.Sprintf("installment plan #%d part %d", plan.Id, part.Number),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// checkInstallmentsPaid returns ErrOutstandingInstallments if the order is paid by a plan which is not paid yet
+func checkInstallmentsPaid(ctx context.Context, tx pgx.Tx, builder squirrel.StatementBuilderType, orderId int) error {
+	sql, args, _ := builder.
+		Select("count(*)").
+		From("installment_plans").
+		Where(squirrel.Eq{"order_id": orderId, "status": unpaidPlanStatuses}).
+		ToSql()
+
+	var plans int
+	err := tx.QueryRow(ctx, sql, args...).Scan(&plans)
+	if err != nil {
+		return err
+	}
+	if plans > 0 {
+		return repoerrs.ErrOutstandingInstallments
+	}
+
+	return nil
+}
+
+// cancelInstallmentPlan cancels the unpaid plan of the order together with its pending parts
+func cancelInstallmentPlan(ctx context.Context, tx pgx.Tx, builder squirrel.StatementBuilderType, orderId int) error {
+	sql, args, _ := builder.
+		Update("installment_plans").
+		Set("status", entity.InstallmentPlanStatusCancelled).
+		Where(squirrel.Eq{"order_id": orderId, "status": unpaidPlanStatuses}).
+		Suffix("RETURNING id").
+		ToSql()
+
+	var planId int
+	err := tx.QueryRow(ctx, sql, args...).Scan(&planId)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil
+		}
+		return err
+	}
+
+	sql, args, _ = builder.
+		Update("installment_parts").
+		Set("status", entity.InstallmentPartStatusCancelled).
+		Where("plan_id = ? and status = ?", planId, entity.InstallmentPartStatusPending).
+		ToSql()
+
+	_, err = tx.Exec(ctx, sql, args...)
+	return err
+}
+
+func scanInstallmentPart(row pgx.Row) (entity.InstallmentPart, error) {
+	var part entity.InstallmentPart
+	err := row.Scan(
+		&part.Id,
+		&part.PlanId,
+		&part.Number,
+		&part.Amount,
+		&part.DueAt,
+		&part.Status,
+		&part.Attempts,
+		&part.NextAttemptAt,
+		&part.Error,
+		&part.PaidAt,
+	)
+	return part, err
+}
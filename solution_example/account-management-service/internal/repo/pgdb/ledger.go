@@ -0,0 +1,399 @@
+package pgdb
+
+import (
+	"account-management-service/internal/entity"
+	"account-management-service/internal/repo/repoerrs"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"sort"
+)
+
+// lockAccount locks the account row until the end of the transaction, so that concurrent
+// money movements on the same account and its wallets are serialized, and returns its status
+func lockAccount(ctx context.Context, tx pgx.Tx, builder squirrel.StatementBuilderType, id int) (string, error) {
+	sql, args, _ := builder.
+		Select("status").
+		From("accounts").
+		Where("id = ?", id).
+		Suffix("FOR UPDATE").
+		ToSql()
+
+	var status string
+	err := tx.QueryRow(ctx, sql, args...).Scan(&status)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", repoerrs.ErrNotFound
+		}
+		return "", err
+	}
+
+	return status, nil
+}
+
+// checkAccountStatus returns an error describing why money cannot be moved
+// if the account status is not one of allowed
+func checkAccountStatus(status string, allowed ...string) error {
+	for _, s := range allowed {
+		if s == status {
+			return nil
+		}
+	}
+
+	if status == entity.AccountStatusClosed {
+		return repoerrs.ErrAccountClosed
+	}
+	return repoerrs.ErrAccountFrozen
+}
+
+// canCredit and canDebit are the statuses which allow incoming and outgoing money respectively.
+// Already reserved money may still be recognized as revenue unless the account is fully frozen,
+// and returned back to the account unless it is closed
+var (
+	canCredit = []string{entity.AccountStatusActive, entity.AccountStatusFrozenDebit}
+	canDebit  = []string{entity.AccountStatusActive}
+	canSettle = []string{entity.AccountStatusActive, entity.AccountStatusFrozenDebit}
+	canRefund = []string{entity.AccountStatusActive, entity.AccountStatusFrozenDebit, entity.AccountStatusFrozen}
+)
+
+// sortedIds returns account ids in ascending order, the order in which accounts must be locked
+func sortedIds(ids ...int) []int {
+	sort.Ints(ids)
+	return ids
+}
+
+// walletBalances returns balances of all account wallets, wallets which were never credited are absent.
+// The account must be locked by the caller
+func walletBalances(ctx context.Context, tx pgx.Tx, builder squirrel.StatementBuilderType, accountId int) (map[string]int, error) {
+	sql, args, _ := builder.
+		Select("wallet_type", "balance").
+		From("wallets").
+		Where("account_id = ?", accountId).
+		ToSql()
+
+	rows, err := tx.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	balances := make(map[string]int)
+	for rows.Next() {
+		var walletType string
+		var balance int
+		err = rows.Scan(&walletType, &balance)
+		if err != nil {
+			return nil, err
+		}
+		balances[walletType] = balance
+	}
+
+	return balances, rows.Err()
+}
+
+// creditWallet adds amount to the wallet, creating it on the first credit
+func creditWallet(ctx context.Context, tx pgx.Tx, builder squirrel.StatementBuilderType, accountId int, walletType string, amount int) error {
+	sql, args, _ := builder.
+		Insert("wallets").
+		Columns("account_id", "wallet_type", "balance").
+		Values(accountId, walletType, amount).
+		Suffix("ON CONFLICT (account_id, wallet_type) DO UPDATE SET balance = wallets.balance + excluded.balance").
+		ToSql()
+
+	_, err := tx.Exec(ctx, sql, args...)
+	return err
+}
+
+// debitWallet subtracts amount from the wallet. Balance must be checked by the caller
+func debitWallet(ctx context.Context, tx pgx.Tx, builder squirrel.StatementBuilderType, accountId int, walletType string, amount int) error {
+	sql, args, _ := builder.
+		Update("wallets").
+		Set("balance", squirrel.Expr("balance - ?", amount)).
+		Where("account_id = ? and wallet_type = ?", accountId, walletType).
+		ToSql()
+
+	_, err := tx.Exec(ctx, sql, args...)
+	return err
+}
+
+// splitByWallets takes amount from wallets in the given order and returns non-empty parts.
+// ErrNotEnoughBalance is returned if all the wallets together are not enough
+func splitByWallets(balances map[string]int, order []string, amount int) ([]entity.ReservationFund, error) {
+	var funds []entity.ReservationFund
+	left := amount
+	for _, walletType := range order {
+		if left == 0 {
+			break
+		}
+
+		part := balances[walletType]
+		if part > left {
+			part = left
+		}
+		if part <= 0 {
+			continue
+		}
+
+		funds = append(funds, entity.ReservationFund{
+			WalletType: walletType,
+			Amount:     part,
+		})
+		left -= part
+	}
+
+	if left > 0 {
+		return nil, repoerrs.ErrNotEnoughBalance
+	}
+
+	return funds, nil
+}
+
+func insertOperation(ctx context.Context, tx pgx.Tx, builder squirrel.StatementBuilderType, operation entity.Operation) error {
+	walletType := operation.WalletType
+	if walletType == "" {
+		walletType = entity.WalletTypeMain
+	}
+
+	columns := []string{"account_id", "amount", "operation_type", "wallet_type"}
+	values := []interface{}{operation.AccountId, operation.Amount, operation.OperationType, walletType}
+	if operation.ProductId != nil {
+		columns = append(columns, "product_id")
+		values = append(values, *operation.ProductId)
+	}
+	if operation.OrderId != nil {
+		columns = append(columns, "order_id")
+		values = append(values, *operation.OrderId)
+	}
+	if operation.Description != "" {
+		columns = append(columns, "description")
+		values = append(values, operation.Description)
+	}
+	if operation.UserId != nil {
+		columns = append(columns, "user_id")
+		values = append(values, *operation.UserId)
+	}
+
+	sql, args, _ := builder.
+		Insert("operations").
+		Columns(columns...).
+		Values(values...).
+		ToSql()
+
+	_, err := tx.Exec(ctx, sql, args...)
+	return err
+}
+
+// collectFee moves the fee from the main wallet of the account to the company fee account and records it
+// as a separate operation on both sides. The account must be locked and its balance checked by the caller
+func collectFee(ctx context.Context, tx pgx.Tx, builder squirrel.StatementBuilderType, accountId int, fee entity.Fee, operationType string) error {
+	if fee.Amount == 0 {
+		return nil
+	}
+
+	err := debitWallet(ctx, tx, builder, accountId, entity.WalletTypeMain, fee.Amount)
+	if err != nil {
+		return err
+	}
+
+	err = creditWallet(ctx, tx, builder, fee.AccountId, entity.WalletTypeMain, fee.Amount)
+	if err != nil {
+		return err
+	}
+
+	err = insertOperation(ctx, tx, builder, entity.Operation{
+		AccountId:     accountId,
+		Amount:        fee.Amount,
+		OperationType: entity.OperationTypeFee,
+		Description:   fmt.Sprintf("%s fee", operationType),
+	})
+	if err != nil {
+		return err
+	}
+
+	return insertOperation(ctx, tx, builder, entity.Operation{
+		AccountId:     fee.AccountId,
+		Amount:        fee.Amount,
+		OperationType: entity.OperationTypeFeeIncome,
+		Description:   fmt.Sprintf("%s fee from account #%d", operationType, accountId),
+	})
+}
+
+// isLedgerError reports whether err is one of the reasons why money cannot be moved,
+// such errors are returned to the caller as is
+func isLedgerError(err error) bool {
+	for _, target := range []error{
+		repoerrs.ErrNotFound,
+		repoerrs.ErrNotEnoughBalance,
+		repoerrs.ErrAccountFrozen,
+		repoerrs.ErrAccountClosed,
+		repoerrs.ErrLimitExceeded,
+		repoerrs.ErrVerificationRequired,
+		repoerrs.ErrNotGroupMember,
+		repoerrs.ErrMemberCannotSpend,
+		repoerrs.ErrMemberLimitExceeded,
+	} {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// depositMoney credits the wallet of the account, locking it
+func depositMoney(ctx context.Context, tx pgx.Tx, builder squirrel.StatementBuilderType, id, amount int, walletType string) error {
+	status, err := lockAccount(ctx, tx, builder, id)
+	if err != nil {
+		return err
+	}
+
+	if err = checkAccountStatus(status, canCredit...); err != nil {
+		return err
+	}
+
+	err = creditWallet(ctx, tx, builder, id, walletType, amount)
+	if err != nil {
+		return err
+	}
+
+	return insertOperation(ctx, tx, builder, entity.Operation{
+		AccountId:     id,
+		Amount:        amount,
+		OperationType: entity.OperationTypeDeposit,
+		WalletType:    walletType,
+	})
+}
+
+// withdrawMoney takes amount from the main wallet of the account, locking it, the fee is taken on top of the amount.
+// The limits, verification and member spending are checked once the account is locked
+func withdrawMoney(ctx context.Context, tx pgx.Tx, builder squirrel.StatementBuilderType, id, amount int, userId *int, fee entity.Fee, limits []entity.LimitRule, verification entity.VerificationCheck) error {
+	status, err := lockAccount(ctx, tx, builder, id)
+	if err != nil {
+		return err
+	}
+
+	if err = checkAccountStatus(status, canDebit...); err != nil {
+		return err
+	}
+
+	if err = checkWindowLimits(ctx, tx, builder, id, entity.OperationTypeWithdraw, amount, limits); err != nil {
+		return err
+	}
+
+	if err = checkVerification(ctx, tx, builder, id, entity.OperationTypeWithdraw, amount, verification); err != nil {
+		return err
+	}
+
+	userId, err = checkAccountSpending(ctx, tx, builder, id, userId, amount)
+	if err != nil {
+		return err
+	}
+
+	// check if account has enough balance to withdraw
+	balances, err := walletBalances(ctx, tx, builder, id)
+	if err != nil {
+		return err
+	}
+
+	if balances[entity.WalletTypeMain] < amount+fee.Amount {
+		return repoerrs.ErrNotEnoughBalance
+	}
+
+	err = debitWallet(ctx, tx, builder, id, entity.WalletTypeMain, amount)
+	if err != nil {
+		return err
+	}
+
+	err = insertOperation(ctx, tx, builder, entity.Operation{
+		AccountId:     id,
+		Amount:        amount,
+		OperationType: entity.OperationTypeWithdraw,
+		WalletType:    entity.WalletTypeMain,
+		UserId:        userId,
+	})
+	if err != nil {
+		return err
+	}
+
+	return collectFee(ctx, tx, builder, id, fee, entity.OperationTypeWithdraw)
+}
+
+// transferMoney moves amount between main wallets of two accounts, locking both of them,
+// the fee is paid by the sender on top of the amount. The sender limits and verification are checked once the accounts are locked
+func transferMoney(ctx context.Context, tx pgx.Tx, builder squirrel.StatementBuilderType, from, to, amount int, userId *int, fee entity.Fee, limits []entity.LimitRule, verification entity.VerificationCheck, description string) error {
+	// lock both accounts in the same order to avoid deadlocks between opposite transfers
+	statuses := make(map[int]string, 2)
+	for _, id := range sortedIds(from, to) {
+		status, err := lockAccount(ctx, tx, builder, id)
+		if err != nil {
+			return err
+		}
+		statuses[id] = status
+	}
+
+	if err := checkAccountStatus(statuses[from], canDebit...); err != nil {
+		return err
+	}
+	if err := checkAccountStatus(statuses[to], canCredit...); err != nil {
+		return err
+	}
+
+	if err := checkWindowLimits(ctx, tx, builder, from, entity.OperationTypeTransferFrom, amount, limits); err != nil {
+		return err
+	}
+
+	if err := checkVerification(ctx, tx, builder, from, entity.OperationTypeTransferFrom, amount, verification); err != nil {
+		return err
+	}
+
+	userId, err := checkAccountSpending(ctx, tx, builder, from, userId, amount)
+	if err != nil {
+		return err
+	}
+
+	// check if account 'from' has enough balance to transfer
+	balances, err := walletBalances(ctx, tx, builder, from)
+	if err != nil {
+		return err
+	}
+
+	if balances[entity.WalletTypeMain] < amount+fee.Amount {
+		return repoerrs.ErrNotEnoughBalance
+	}
+
+	err = debitWallet(ctx, tx, builder, from, entity.WalletTypeMain, amount)
+	if err != nil {
+		return err
+	}
+
+	err = creditWallet(ctx, tx, builder, to, entity.WalletTypeMain, amount)
+	if err != nil {
+		return err
+	}
+
+	err = insertOperation(ctx, tx, builder, entity.Operation{
+		AccountId:     from,
+		Amount:        amount,
+		OperationType: entity.OperationTypeTransferFrom,
+		WalletType:    entity.WalletTypeMain,
+		Description:   description,
+		UserId:        userId,
+	})
+	if err != nil {
+		return err
+	}
+
+	err = insertOperation(ctx, tx, builder, entity.Operation{
+		AccountId:     to,
+		Amount:        amount,
+		OperationType: entity.OperationTypeTransferTo,
+		WalletType:    entity.WalletTypeMain,
+		Description:   description,
+	})
+	if err != nil {
+		return err
+	}
+
+	return collectFee(ctx, tx, builder, from, fee, "transfer")
+}
@@ -0,0 +1,65 @@
+package pgdb
+
+import (
+	"account-management-service/internal/entity"
+	"account-management-service/internal/repo/repoerrs"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestSplitByWallets(t *testing.T) {
+	order := []string{entity.WalletTypeBonus, entity.WalletTypeMain}
+
+	testCases := []struct {
+		name     string
+		balances map[string]int
+		amount   int
+		want     []entity.ReservationFund
+		wantErr  error
+	}{
+		{
+			name:     "bonus is enough",
+			balances: map[string]int{entity.WalletTypeMain: 100, entity.WalletTypeBonus: 100},
+			amount:   80,
+			want: []entity.ReservationFund{
+				{WalletType: entity.WalletTypeBonus, Amount: 80},
+			},
+		},
+		{
+			name:     "bonus then main",
+			balances: map[string]int{entity.WalletTypeMain: 100, entity.WalletTypeBonus: 30},
+			amount:   80,
+			want: []entity.ReservationFund{
+				{WalletType: entity.WalletTypeBonus, Amount: 30},
+				{WalletType: entity.WalletTypeMain, Amount: 50},
+			},
+		},
+		{
+			name:     "no bonus wallet",
+			balances: map[string]int{entity.WalletTypeMain: 100},
+			amount:   100,
+			want: []entity.ReservationFund{
+				{WalletType: entity.WalletTypeMain, Amount: 100},
+			},
+		},
+		{
+			name:     "not enough balance",
+			balances: map[string]int{entity.WalletTypeMain: 50, entity.WalletTypeBonus: 20},
+			amount:   80,
+			wantErr:  repoerrs.ErrNotEnoughBalance,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := splitByWallets(tc.balances, order, tc.amount)
+			if tc.wantErr != nil {
+				assert.ErrorIs(t, err, tc.wantErr)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
@@ -0,0 +1,184 @@
+package pgdb
+
+import (
+	"account-management-service/internal/entity"
+	"account-management-service/internal/repo/repoerrs"
+	"account-management-service/pkg/postgres"
+	"context"
+	"fmt"
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+)
+
+var limitRuleColumns = []string{
+	"id",
+	"scope",
+	"account_type",
+	"account_id",
+	"operation_type",
+	"limit_type",
+	"value",
+	"window_seconds",
+	"created_at",
+}
+
+type LimitRepo struct {
+	*postgres.Postgres
+}
+
+func NewLimitRepo(pg *postgres.Postgres) *LimitRepo {
+	return &LimitRepo{pg}
+}
+
+func (r *LimitRepo) CreateLimitRule(ctx context.Context, rule entity.LimitRule) (int, error) {
+	sql, args, _ := r.Builder.
+		Insert("limit_rules").
+		Columns("scope", "account_type", "account_id", "operation_type", "limit_type", "value", "window_seconds").
+		Values(
+			rule.Scope,
+			rule.AccountType,
+			rule.AccountId,
+			rule.OperationType,
+			rule.LimitType,
+			rule.Value,
+			rule.WindowSeconds,
+		).
+		Suffix("RETURNING id").
+		ToSql()
+
+	var id int
+	err := r.Pool.QueryRow(ctx, sql, args...).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("LimitRepo.CreateLimitRule - r.Pool.QueryRow: %v", err)
+	}
+
+	return id, nil
+}
+
+func (r *LimitRepo) GetAllLimitRules(ctx context.Context) ([]entity.LimitRule, error) {
+	sql, args, _ := r.Builder.
+		Select(limitRuleColumns...).
+		From("limit_rules").
+		OrderBy("id").
+		ToSql()
+
+	rows, err := r.Pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("LimitRepo.GetAllLimitRules - r.Pool.Query: %v", err)
+	}
+	defer rows.Close()
+
+	rules, err := scanLimitRules(rows)
+	if err != nil {
+		return nil, fmt.Errorf("LimitRepo.GetAllLimitRules - scanLimitRules: %v", err)
+	}
+
+	return rules, nil
+}
+
+func (r *LimitRepo) GetLimitRulesForAccount(ctx context.Context, accountId int, accountType, operationType string) ([]entity.LimitRule, error) {
+	sql, args, _ := r.Builder.
+		Select(limitRuleColumns...).
+		From("limit_rules").
+		Where("operation_type = ?", operationType).
+		Where(squirrel.Or{
+			squirrel.Eq{"scope": entity.LimitScopeGlobal},
+			squirrel.Eq{"scope": entity.LimitScopeAccountType, "account_type": accountType},
+			squirrel.Eq{"scope": entity.LimitScopeAccount, "account_id": accountId},
+		}).
+		OrderBy("id").
+		ToSql()
+
+	rows, err := r.Pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("LimitRepo.GetLimitRulesForAccount - r.Pool.Query: %v", err)
+	}
+	defer rows.Close()
+
+	rules, err := scanLimitRules(rows)
+	if err != nil {
+		return nil, fmt.Errorf("LimitRepo.GetLimitRulesForAccount - scanLimitRules: %v", err)
+	}
+
+	return rules, nil
+}
+
+func (r *LimitRepo) DeleteLimitRule(ctx context.Context, id int) error {
+	sql, args, _ := r.Builder.
+		Delete("limit_rules").
+		Where("id = ?", id).
+		ToSql()
+
+	tag, err := r.Pool.Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("LimitRepo.DeleteLimitRule - r.Pool.Exec: %v", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return repoerrs.ErrNotFound
+	}
+
+	return nil
+}
+
+// checkWindowLimits checks the outgoing operation against the rolling window limit rules and returns
+// ErrLimitExceeded wrapped with the violated rule description. The account must be locked by the caller,
+// so concurrent operations of the account cannot all fit into what is left of the window
+func checkWindowLimits(ctx context.Context, tx pgx.Tx, builder squirrel.StatementBuilderType, accountId int, operationType string, amount int, rules []entity.LimitRule) error {
+	for _, rule := range rules {
+		count, total, err := windowTotals(ctx, tx, builder, accountId, operationType, rule.WindowSeconds)
+		if err != nil {
+			return err
+		}
+
+		if exceedsLimit(rule, count, total, amount) {
+			return &repoerrs.LimitExceededError{Rule: rule, OperationType: operationType}
+		}
+	}
+
+	return nil
+}
+
+// windowTotals returns the number and the total amount of the operations of the account within the window
+func windowTotals(ctx context.Context, tx pgx.Tx, builder squirrel.StatementBuilderType, accountId int, operationType string, windowSeconds int) (int, int, error) {
+	sql, args, _ := builder.
+		Select("count(*)", "COALESCE(sum(amount), 0)").
+		From("operations").
+		Where("account_id = ? and operation_type = ?", accountId, operationType).
+		Where("created_at > now() - ? * interval '1 second'", windowSeconds).
+		ToSql()
+
+	var count, total int
+	err := tx.QueryRow(ctx, sql, args...).Scan(&count, &total)
+	return count, total, err
+}
+
+// exceedsLimit reports whether one more operation of amount breaks the window rule
+func exceedsLimit(rule entity.LimitRule, count, total, amount int) bool {
+	return rule.LimitType == entity.LimitTypeMaxTotal && total+amount > rule.Value ||
+		rule.LimitType == entity.LimitTypeMaxCount && count+1 > rule.Value
+}
+
+func scanLimitRules(rows pgx.Rows) ([]entity.LimitRule, error) {
+	var rules []entity.LimitRule
+	for rows.Next() {
+		var rule entity.LimitRule
+		err := rows.Scan(
+			&rule.Id,
+			&rule.Scope,
+			&rule.AccountType,
+			&rule.AccountId,
+			&rule.OperationType,
+			&rule.LimitType,
+			&rule.Value,
+			&rule.WindowSeconds,
+			&rule.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+
+	return rules, rows.Err()
+}
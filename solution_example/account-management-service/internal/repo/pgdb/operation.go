@@ -5,12 +5,18 @@ import (
 	"account-management-service/pkg/postgres"
 	"context"
 	"fmt"
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"time"
 )
 
 const (
 	maxPaginationLimit     = 10
 	defaultPaginationLimit = 10
 
+	// exportFetchSize is the number of operations fetched from the export cursor at once
+	exportFetchSize = 1000
+
 	DateSortType   string = "date"
 	AmountSortType string = "amount"
 )
@@ -23,35 +29,228 @@ func NewOperationRepo(pg *postgres.Postgres) *OperationRepo {
 	return &OperationRepo{pg}
 }
 
-func (r *OperationRepo) GetAllRevenueOperationsGroupedByProduct(ctx context.Context, month, year int) ([]string, []int, error) {
-	sql, args, _ := r.Builder.
-		Select("products.name", "sum(amount)").
+// revenueOperationTypes are the operations which make up the revenue of the products
+var revenueOperationTypes = []string{
+	entity.OperationTypeRevenue,
+	entity.OperationTypeRevenueRefund,
+	entity.OperationTypeCashback,
+	entity.OperationTypeCashbackReversal,
+}
+
+// reportGranularities are the date_trunc fields of the report granularities, the field is put into
+// the query as is, as the placeholders of the select and group by clauses are not the same expression
+var reportGranularities = map[string]string{
+	entity.ReportGranularityDay:     "'day'",
+	entity.ReportGranularityWeek:    "'week'",
+	entity.ReportGranularityMonth:   "'month'",
+	entity.ReportGranularityQuarter: "'quarter'",
+}
+
+// selectRevenue adds the revenue columns of entity.ProductRevenue following the product name to the query
+// of the revenue operations in the [from, to) range
+func selectRevenue(query squirrel.SelectBuilder, from, to time.Time) squirrel.SelectBuilder {
+	signedAmount := "case when operation_type in ('" + entity.OperationTypeRevenue + "', '" + entity.OperationTypeCashbackReversal + "') then amount else -amount end"
+
+	return query.
+		Column("products.name").
+		Column("sum("+signedAmount+")").
+		Column(squirrel.Expr("COALESCE(sum("+signedAmount+") filter (where wallet_type = ? or operation_type in (?, ?)), 0)",
+			entity.WalletTypeMain, entity.OperationTypeCashback, entity.OperationTypeCashbackReversal)).
+		Column(squirrel.Expr("COALESCE(sum("+signedAmount+") filter (where wallet_type = ? and operation_type in (?, ?)), 0)",
+			entity.WalletTypeBonus, entity.OperationTypeRevenue, entity.OperationTypeRevenueRefund)).
+		Column(squirrel.Expr("count(distinct order_id) filter (where operation_type = ?)", entity.OperationTypeRevenue)).
+		Column(squirrel.Expr("COALESCE(sum(amount) filter (where operation_type = ?), 0)", entity.OperationTypeRevenue)).
+		Column(squirrel.Expr("COALESCE(sum(amount) filter (where operation_type = ?), 0)", entity.OperationTypeRevenueRefund)).
+		Column(squirrel.Expr("COALESCE(sum(-("+signedAmount+")) filter (where operation_type in (?, ?)), 0)",
+			entity.OperationTypeCashback, entity.OperationTypeCashbackReversal)).
 		From("operations").
 		InnerJoin("products on operations.product_id = products.id").
-		Where("operation_type = ? and extract(month from operations.created_at) = ? and extract(year from operations.created_at) = ?", entity.OperationTypeRevenue, month, year).
+		Where(squirrel.Eq{"operation_type": revenueOperationTypes}).
+		Where("operations.created_at >= ? and operations.created_at < ?", from, to)
+}
+
+// revenueFields are the scan destinations of the columns added by selectRevenue
+func revenueFields(revenue *entity.ProductRevenue) []any {
+	return []any{&revenue.Product, &revenue.Amount, &revenue.MainAmount, &revenue.BonusAmount,
+		&revenue.Orders, &revenue.Revenue, &revenue.Refunded, &revenue.Cashback}
+}
+
+// GetAllRevenueOperationsGroupedByProduct returns the net revenue of the month: refunds of captured orders are
+// subtracted, and so is the cashback granted on them, which is money given back from the main wallet revenue.
+// The gross revenue, refunds, cashback and the number of orders are returned alongside the net revenue
+func (r *OperationRepo) GetAllRevenueOperationsGroupedByProduct(ctx context.Context, from, to time.Time) ([]entity.ProductRevenue, error) {
+	sql, args, _ := selectRevenue(r.Builder.Select(), from, to).
 		GroupBy("products.name").
+		OrderBy("products.name").
 		ToSql()
 
 	rows, err := r.Pool.Query(ctx, sql, args...)
 	if err != nil {
-		return nil, nil, fmt.Errorf("OperationRepo.GetAllRevenueOperationsGroupedByProductId - r.Pool.Query: %v", err)
+		return nil, fmt.Errorf("OperationRepo.GetAllRevenueOperationsGroupedByProductId - r.Pool.Query: %v", err)
 	}
 	defer rows.Close()
 
-	var productNames []string
-	var amounts []int
+	var revenues []entity.ProductRevenue
 	for rows.Next() {
-		var productName string
-		var amount int
-		err = rows.Scan(&productName, &amount)
+		var revenue entity.ProductRevenue
+		err = rows.Scan(revenueFields(&revenue)...)
 		if err != nil {
-			return nil, nil, fmt.Errorf("OperationRepo.GetAllRevenueOperationsGroupedByProductId - rows.Scan: %v", err)
+			return nil, fmt.Errorf("OperationRepo.GetAllRevenueOperationsGroupedByProductId - rows.Scan: %v", err)
 		}
-		productNames = append(productNames, productName)
-		amounts = append(amounts, amount)
+		revenues = append(revenues, revenue)
+	}
+
+	return revenues, nil
+}
+
+// GetProductRevenueSeries returns the revenue of every product by the periods of the granularity,
+// ordered by product and period. Periods without revenue are not returned
+func (r *OperationRepo) GetProductRevenueSeries(ctx context.Context, from, to time.Time, granularity string) ([]entity.PeriodProductRevenue, error) {
+	field, ok := reportGranularities[granularity]
+	if !ok {
+		return nil, fmt.Errorf("OperationRepo.GetProductRevenueSeries: unknown granularity %q", granularity)
+	}
+	period := "date_trunc(" + field + ", operations.created_at)"
+
+	sql, args, _ := selectRevenue(r.Builder.Select(period), from, to).
+		GroupBy(period, "products.name").
+		OrderBy("products.name", period).
+		ToSql()
+
+	rows, err := r.Pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("OperationRepo.GetProductRevenueSeries - r.Pool.Query: %v", err)
+	}
+	defer rows.Close()
+
+	var revenues []entity.PeriodProductRevenue
+	for rows.Next() {
+		var revenue entity.PeriodProductRevenue
+		err = rows.Scan(append([]any{&revenue.Period}, revenueFields(&revenue.ProductRevenue)...)...)
+		if err != nil {
+			return nil, fmt.Errorf("OperationRepo.GetProductRevenueSeries - rows.Scan: %v", err)
+		}
+		revenues = append(revenues, revenue)
 	}
 
-	return productNames, amounts, nil
+	return revenues, nil
+}
+
+// StreamAccountProductRevenue passes the revenue of the [from, to) range by account and product to fn as the rows are read,
+// ordered by account and product name, so that the report of any size is not held in memory.
+// Empty productIds or accountIds do not limit the products or accounts
+func (r *OperationRepo) StreamAccountProductRevenue(ctx context.Context, from, to time.Time, productIds, accountIds []int, fn func(entity.AccountProductRevenue) error) error {
+	query := selectRevenue(r.Builder.Select("account_id"), from, to).
+		GroupBy("account_id", "products.name").
+		OrderBy("account_id", "products.name")
+	if len(productIds) > 0 {
+		query = query.Where(squirrel.Eq{"operations.product_id": productIds})
+	}
+	if len(accountIds) > 0 {
+		query = query.Where(squirrel.Eq{"account_id": accountIds})
+	}
+	sql, args, _ := query.ToSql()
+
+	rows, err := r.Pool.Query(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("OperationRepo.StreamAccountProductRevenue - r.Pool.Query: %v", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var revenue entity.AccountProductRevenue
+		err = rows.Scan(append([]any{&revenue.AccountId}, revenueFields(&revenue.ProductRevenue)...)...)
+		if err != nil {
+			return fmt.Errorf("OperationRepo.StreamAccountProductRevenue - rows.Scan: %v", err)
+		}
+		if err = fn(revenue); err != nil {
+			return err
+		}
+	}
+
+	if err = rows.Err(); err != nil {
+		return fmt.Errorf("OperationRepo.StreamAccountProductRevenue - rows.Err: %v", err)
+	}
+
+	return nil
+}
+
+// StreamOperations calls fn for every operation created in the [from, to) range in the order of creation.
+// The operations are fetched from the server side cursor in batches, so the memory used does not depend on
+// the number of operations. The cursor lives in the read only transaction, which also gives the export
+// the consistent snapshot. Cancelling ctx stops the export
+func (r *OperationRepo) StreamOperations(ctx context.Context, from, to time.Time, accountId int, operationTypes []string, fn func(entity.ExportedOperation) error) error {
+	query := r.Builder.
+		Select("operations.id", "account_id", "operation_type", "amount", "wallet_type", "COALESCE(products.name, '')",
+			"order_id", "user_id", "COALESCE(description, '')", "operations.created_at").
+		From("operations").
+		LeftJoin("products on operations.product_id = products.id").
+		Where("operations.created_at >= ? and operations.created_at < ?", from, to).
+		OrderBy("operations.created_at", "operations.id")
+	if accountId != 0 {
+		query = query.Where("account_id = ?", accountId)
+	}
+	if len(operationTypes) > 0 {
+		query = query.Where(squirrel.Eq{"operation_type": operationTypes})
+	}
+	sql, args, _ := query.ToSql()
+
+	tx, err := r.Pool.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return fmt.Errorf("OperationRepo.StreamOperations - r.Pool.BeginTx: %v", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	_, err = tx.Exec(ctx, "DECLARE operations_export NO SCROLL CURSOR FOR "+sql, args...)
+	if err != nil {
+		return fmt.Errorf("OperationRepo.StreamOperations - tx.Exec: %v", err)
+	}
+
+	for {
+		fetched, err := fetchOperations(ctx, tx, fn)
+		if err != nil {
+			return err
+		}
+		if fetched < exportFetchSize {
+			break
+		}
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return fmt.Errorf("OperationRepo.StreamOperations - tx.Commit: %v", err)
+	}
+
+	return nil
+}
+
+// fetchOperations fetches the next batch of the export cursor and returns the number of the fetched operations
+func fetchOperations(ctx context.Context, tx pgx.Tx, fn func(entity.ExportedOperation) error) (int, error) {
+	rows, err := tx.Query(ctx, fmt.Sprintf("FETCH %d FROM operations_export", exportFetchSize))
+	if err != nil {
+		return 0, fmt.Errorf("OperationRepo.fetchOperations - tx.Query: %v", err)
+	}
+	defer rows.Close()
+
+	var fetched int
+	for rows.Next() {
+		var operation entity.ExportedOperation
+		err = rows.Scan(&operation.Id, &operation.AccountId, &operation.OperationType, &operation.Amount, &operation.WalletType,
+			&operation.Product, &operation.OrderId, &operation.UserId, &operation.Description, &operation.CreatedAt)
+		if err != nil {
+			return 0, fmt.Errorf("OperationRepo.fetchOperations - rows.Scan: %v", err)
+		}
+		if err = fn(operation); err != nil {
+			return 0, err
+		}
+		fetched++
+	}
+
+	if err = rows.Err(); err != nil {
+		return 0, fmt.Errorf("OperationRepo.fetchOperations - rows.Err: %v", err)
+	}
+
+	return fetched, nil
 }
 
 func (r *OperationRepo) OperationsPagination(ctx context.Context, accountId int, sortType string, offset int, limit int) ([]entity.Operation, []string, error) {
@@ -75,7 +274,7 @@ func (r *OperationRepo) OperationsPagination(ctx context.Context, accountId int,
 	}
 
 	sqlQuery, args, _ := r.Builder.
-		Select("operations.id", "account_id", "amount", "operation_type", "created_at", "COALESCE((case when operations.product_id is null then null else products.name end), '') as product_name", "order_id", "COALESCE(description, '')").
+		Select("operations.id", "account_id", "amount", "operation_type", "created_at", "wallet_type", "COALESCE((case when operations.product_id is null then null else products.name end), '') as product_name", "order_id", "COALESCE(description, '')", "user_id").
 		From("operations").
 		InnerJoin("products on operations.product_id = products.id or operations.product_id is null").
 		Where("account_id = ?", accountId).
@@ -95,7 +294,7 @@ func (r *OperationRepo) OperationsPagination(ctx context.Context, accountId int,
 	for rows.Next() {
 		var operation entity.Operation
 		var productName string
-		err = rows.Scan(&operation.Id, &operation.AccountId, &operation.Amount, &operation.OperationType, &operation.CreatedAt, &productName, &operation.OrderId, &operation.Description)
+		err = rows.Scan(&operation.Id, &operation.AccountId, &operation.Amount, &operation.OperationType, &operation.CreatedAt, &operation.WalletType, &productName, &operation.OrderId, &operation.Description, &operation.UserId)
 		if err != nil {
 			return nil, nil, fmt.Errorf("OperationRepo.paginationOperationsByDate - rows.Scan: %v", err)
 		}
@@ -105,3 +304,51 @@ func (r *OperationRepo) OperationsPagination(ctx context.Context, accountId int,
 
 	return operations, productNames, nil
 }
+
+// GetFeeIncome returns the sum of fees collected into the company fee account in the [from, to) range
+func (r *OperationRepo) GetFeeIncome(ctx context.Context, from, to time.Time) (int, error) {
+	sql, args, _ := r.Builder.
+		Select("COALESCE(sum(amount), 0)").
+		From("operations").
+		Where("operation_type = ? and created_at >= ? and created_at < ?", entity.OperationTypeFeeIncome, from, to).
+		ToSql()
+
+	var amount int
+	err := r.Pool.QueryRow(ctx, sql, args...).Scan(&amount)
+	if err != nil {
+		return 0, fmt.Errorf("OperationRepo.GetFeeIncome - r.Pool.QueryRow: %v", err)
+	}
+
+	return amount, nil
+}
+
+// GetMemberSpending returns the money reserved from the shared account by each of its members
+// in total and during the current month, net of the refunded orders
+func (r *OperationRepo) GetMemberSpending(ctx context.Context, accountId int) ([]entity.MemberSpending, error) {
+	sql, args, _ := r.Builder.
+		Select("user_id", "count(*)", "COALESCE("+memberSpentSum+", 0)").
+		Column("COALESCE("+memberSpentSum+" filter (where created_at >= date_trunc('month', now())), 0)").
+		From("operations").
+		Where("account_id = ? and user_id is not null", accountId).
+		GroupBy("user_id").
+		OrderBy("user_id").
+		ToSql()
+
+	rows, err := r.Pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("OperationRepo.GetMemberSpending - r.Pool.Query: %v", err)
+	}
+	defer rows.Close()
+
+	var spending []entity.MemberSpending
+	for rows.Next() {
+		var member entity.MemberSpending
+		err = rows.Scan(&member.UserId, &member.Operations, &member.Spent, &member.MonthSpent)
+		if err != nil {
+			return nil, fmt.Errorf("OperationRepo.GetMemberSpending - rows.Scan: %v", err)
+		}
+		spending = append(spending, member)
+	}
+
+	return spending, nil
+}
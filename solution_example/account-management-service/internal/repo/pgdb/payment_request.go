@@ -0,0 +1,222 @@
+package pgdb
+
+import (
+	"account-management-service/internal/entity"
+	"account-management-service/internal/repo/repoerrs"
+	"account-management-service/pkg/postgres"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+)
+
+// pending requests past their expiration time are shown as expired even before anyone touched them
+var paymentRequestColumns = []string{
+	"id",
+	"requester_account_id",
+	"payer_account_id",
+	"amount",
+	"description",
+	"case when status = 'pending' and expires_at <= now() then 'expired' else status end",
+	"expires_at",
+	"resolved_at",
+	"created_at",
+}
+
+type PaymentRequestRepo struct {
+	*postgres.Postgres
+}
+
+func NewPaymentRequestRepo(pg *postgres.Postgres) *PaymentRequestRepo {
+	return &PaymentRequestRepo{pg}
+}
+
+func (r *PaymentRequestRepo) CreatePaymentRequest(ctx context.Context, request entity.PaymentRequest) (int, error) {
+	sql, args, _ := r.Builder.
+		Insert("payment_requests").
+		Columns("requester_account_id", "payer_account_id", "amount", "description", "expires_at").
+		Values(
+			request.RequesterAccountId,
+			request.PayerAccountId,
+			request.Amount,
+			request.Description,
+			request.ExpiresAt,
+		).
+		Suffix("RETURNING id").
+		ToSql()
+
+	var id int
+	err := r.Pool.QueryRow(ctx, sql, args...).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("PaymentRequestRepo.CreatePaymentRequest - r.Pool.QueryRow: %v", err)
+	}
+
+	return id, nil
+}
+
+func (r *PaymentRequestRepo) GetPaymentRequestById(ctx context.Context, id int) (entity.PaymentRequest, error) {
+	sql, args, _ := r.Builder.
+		Select(paymentRequestColumns...).
+		From("payment_requests").
+		Where("id = ?", id).
+		ToSql()
+
+	request, err := scanPaymentRequest(r.Pool.QueryRow(ctx, sql, args...))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return entity.PaymentRequest{}, repoerrs.ErrNotFound
+		}
+		return entity.PaymentRequest{}, fmt.Errorf("PaymentRequestRepo.GetPaymentRequestById - r.Pool.QueryRow: %v", err)
+	}
+
+	return request, nil
+}
+
+// GetPaymentRequestsByAccountId returns both incoming and outgoing requests of the account, newest first
+func (r *PaymentRequestRepo) GetPaymentRequestsByAccountId(ctx context.Context, accountId int, status string) ([]entity.PaymentRequest, error) {
+	builder := r.Builder.
+		Select(paymentRequestColumns...).
+		From("payment_requests").
+		Where(squirrel.Or{
+			squirrel.Eq{"requester_account_id": accountId},
+			squirrel.Eq{"payer_account_id": accountId},
+		}).
+		OrderBy("id DESC")
+
+	switch status {
+	case "":
+	case entity.PaymentRequestStatusPending:
+		builder = builder.Where("status = ? and expires_at > now()", status)
+	case entity.PaymentRequestStatusExpired:
+		builder = builder.Where("(status = ? or status = ? and expires_at <= now())", status, entity.PaymentRequestStatusPending)
+	default:
+		builder = builder.Where("status = ?", status)
+	}
+
+	sql, args, _ := builder.ToSql()
+
+	rows, err := r.Pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("PaymentRequestRepo.GetPaymentRequestsByAccountId - r.Pool.Query: %v", err)
+	}
+	defer rows.Close()
+
+	var requests []entity.PaymentRequest
+	for rows.Next() {
+		request, err := scanPaymentRequest(rows)
+		if err != nil {
+			return nil, fmt.Errorf("PaymentRequestRepo.GetPaymentRequestsByAccountId - rows.Scan: %v", err)
+		}
+		requests = append(requests, request)
+	}
+
+	return requests, nil
+}
+
+// AcceptPaymentRequest transfers the requested amount from the payer to the requester
+// and marks the request as paid in the same transaction, the fee is paid by the payer.
+// The rolling window limits and the verification of the payer are checked once the accounts are locked
+func (r *PaymentRequestRepo) AcceptPaymentRequest(ctx context.Context, id int, userId *int, fee entity.Fee, limits []entity.LimitRule, verification entity.VerificationCheck) error {
+	err := r.resolvePaymentRequest(ctx, id, entity.PaymentRequestStatusPaid, userId, fee, limits, verification)
+	if err != nil {
+		return fmt.Errorf("PaymentRequestRepo.AcceptPaymentRequest - r.resolvePaymentRequest: %w", err)
+	}
+	return nil
+}
+
+func (r *PaymentRequestRepo) DeclinePaymentRequest(ctx context.Context, id int) error {
+	err := r.resolvePaymentRequest(ctx, id, entity.PaymentRequestStatusDeclined, nil, entity.Fee{}, nil, entity.VerificationCheck{})
+	if err != nil {
+		return fmt.Errorf("PaymentRequestRepo.DeclinePaymentRequest - r.resolvePaymentRequest: %w", err)
+	}
+	return nil
+}
+
+// resolvePaymentRequest moves a pending request to the given status. An expired request is marked
+// as expired and ErrExpired is returned, ErrWrongStatus is returned if the request is already resolved
+func (r *PaymentRequestRepo) resolvePaymentRequest(ctx context.Context, id int, status string, userId *int, fee entity.Fee, limits []entity.LimitRule, verification entity.VerificationCheck) error {
+	tx, err := r.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	sql, args, _ := r.Builder.
+		Select(paymentRequestColumns...).
+		From("payment_requests").
+		Where("id = ?", id).
+		Suffix("FOR UPDATE").
+		ToSql()
+
+	request, err := scanPaymentRequest(tx.QueryRow(ctx, sql, args...))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return repoerrs.ErrNotFound
+		}
+		return err
+	}
+
+	switch request.Status {
+	case entity.PaymentRequestStatusPending:
+	case entity.PaymentRequestStatusExpired:
+		// remember the expiration, it is committed even though the resolution fails
+		err = r.setPaymentRequestStatus(ctx, tx, id, entity.PaymentRequestStatusExpired)
+		if err != nil {
+			return err
+		}
+		if err = tx.Commit(ctx); err != nil {
+			return err
+		}
+		return repoerrs.ErrExpired
+	default:
+		return repoerrs.ErrWrongStatus
+	}
+
+	if status == entity.PaymentRequestStatusPaid {
+		description := fmt.Sprintf("payment request #%d", request.Id)
+		if request.Description != "" {
+			description += ": " + request.Description
+		}
+
+		err = transferMoney(ctx, tx, r.Builder, request.PayerAccountId, request.RequesterAccountId, request.Amount, userId, fee, limits, verification, description)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = r.setPaymentRequestStatus(ctx, tx, id, status)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (r *PaymentRequestRepo) setPaymentRequestStatus(ctx context.Context, tx pgx.Tx, id int, status string) error {
+	sql, args, _ := r.Builder.
+		Update("payment_requests").
+		Set("status", status).
+		Set("resolved_at", squirrel.Expr("now()")).
+		Where("id = ?", id).
+		ToSql()
+
+	_, err := tx.Exec(ctx, sql, args...)
+	return err
+}
+
+func scanPaymentRequest(row pgx.Row) (entity.PaymentRequest, error) {
+	var p entity.PaymentRequest
+	err := row.Scan(
+		&p.Id,
+		&p.RequesterAccountId,
+		&p.PayerAccountId,
+		&p.Amount,
+		&p.Description,
+		&p.Status,
+		&p.ExpiresAt,
+		&p.ResolvedAt,
+		&p.CreatedAt,
+	)
+	return p, err
+}
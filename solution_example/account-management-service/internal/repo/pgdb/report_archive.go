@@ -0,0 +1,206 @@
+package pgdb
+
+import (
+	"account-management-service/internal/entity"
+	"account-management-service/internal/repo/repoerrs"
+	"account-management-service/pkg/postgres"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+)
+
+var archivedReportColumns = []string{
+	"id",
+	"filename",
+	"kind",
+	"period_from",
+	"period_to",
+	"format",
+	"created_by",
+	"size",
+	"created_at",
+}
+
+const (
+	maxArchivedReportsLimit     = 100
+	defaultArchivedReportsLimit = 20
+)
+
+type ReportArchiveRepo struct {
+	*postgres.Postgres
+}
+
+func NewReportArchiveRepo(pg *postgres.Postgres) *ReportArchiveRepo {
+	return &ReportArchiveRepo{pg}
+}
+
+// SaveArchivedReport records the uploaded report. The file uploaded again under the same name
+// replaces the previous one in the storage, so its record is replaced as well
+func (r *ReportArchiveRepo) SaveArchivedReport(ctx context.Context, report entity.ArchivedReport) (int, error) {
+	sql, args, _ := r.Builder.
+		Insert("report_archive").
+		Columns("filename", "kind", "period_from", "period_to", "format", "created_by", "size").
+		Values(report.Filename, report.Kind, report.PeriodFrom, report.PeriodTo, report.Format, report.CreatedBy, report.Size).
+		Suffix("ON CONFLICT (filename) DO UPDATE SET " +
+			"kind = excluded.kind, period_from = excluded.period_from, period_to = excluded.period_to, " +
+			"format = excluded.format, created_by = excluded.created_by, size = excluded.size, created_at = now() " +
+			"RETURNING id").
+		ToSql()
+
+	var id int
+	err := r.Pool.QueryRow(ctx, sql, args...).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("ReportArchiveRepo.SaveArchivedReport - r.Pool.QueryRow: %v", err)
+	}
+
+	return id, nil
+}
+
+// AddUnknownArchivedReports records the files of the storage which are not in the archive yet
+func (r *ReportArchiveRepo) AddUnknownArchivedReports(ctx context.Context, filenames []string) error {
+	if len(filenames) == 0 {
+		return nil
+	}
+
+	builder := r.Builder.
+		Insert("report_archive").
+		Columns("filename", "kind")
+	for _, filename := range filenames {
+		builder = builder.Values(filename, entity.ArchivedReportKindUnknown)
+	}
+	sql, args, _ := builder.
+		Suffix("ON CONFLICT (filename) DO NOTHING").
+		ToSql()
+
+	_, err := r.Pool.Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("ReportArchiveRepo.AddUnknownArchivedReports - r.Pool.Exec: %v", err)
+	}
+
+	return nil
+}
+
+// GetArchivedReports returns the latest reports first
+func (r *ReportArchiveRepo) GetArchivedReports(ctx context.Context, filter entity.ArchivedReportFilter, offset, limit int) ([]entity.ArchivedReport, error) {
+	if limit > maxArchivedReportsLimit {
+		limit = maxArchivedReportsLimit
+	}
+	if limit == 0 {
+		limit = defaultArchivedReportsLimit
+	}
+
+	query := r.Builder.
+		Select(archivedReportColumns...).
+		From("report_archive").
+		OrderBy("created_at DESC", "id DESC").
+		Limit(uint64(limit)).
+		Offset(uint64(offset))
+	if filter.Kind != "" {
+		query = query.Where("kind = ?", filter.Kind)
+	}
+	if filter.CreatedBy != 0 {
+		query = query.Where("created_by = ?", filter.CreatedBy)
+	}
+	if !filter.CreatedBefore.IsZero() {
+		query = query.Where("created_at < ?", filter.CreatedBefore.UTC())
+	}
+
+	sql, args, _ := query.ToSql()
+	rows, err := r.Pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("ReportArchiveRepo.GetArchivedReports - r.Pool.Query: %v", err)
+	}
+	defer rows.Close()
+
+	var reports []entity.ArchivedReport
+	for rows.Next() {
+		report, err := scanArchivedReport(rows)
+		if err != nil {
+			return nil, fmt.Errorf("ReportArchiveRepo.GetArchivedReports - rows.Scan: %v", err)
+		}
+		reports = append(reports, report)
+	}
+
+	return reports, nil
+}
+
+func (r *ReportArchiveRepo) GetArchivedReportById(ctx context.Context, id int) (entity.ArchivedReport, error) {
+	sql, args, _ := r.Builder.
+		Select(archivedReportColumns...).
+		From("report_archive").
+		Where("id = ?", id).
+		ToSql()
+
+	report, err := scanArchivedReport(r.Pool.QueryRow(ctx, sql, args...))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return entity.ArchivedReport{}, repoerrs.ErrNotFound
+		}
+		return entity.ArchivedReport{}, fmt.Errorf("ReportArchiveRepo.GetArchivedReportById - r.Pool.QueryRow: %v", err)
+	}
+
+	return report, nil
+}
+
+// DeleteArchivedReport deletes the record and expires the finished report jobs of its file
+// in the same transaction, so that no link is issued for the deleted file
+func (r *ReportArchiveRepo) DeleteArchivedReport(ctx context.Context, id int) error {
+	tx, err := r.Pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("ReportArchiveRepo.DeleteArchivedReport - r.Pool.Begin: %v", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	sql, args, _ := r.Builder.
+		Delete("report_archive").
+		Where("id = ?", id).
+		Suffix("RETURNING filename").
+		ToSql()
+
+	var filename string
+	err = tx.QueryRow(ctx, sql, args...).Scan(&filename)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return repoerrs.ErrNotFound
+		}
+		return fmt.Errorf("ReportArchiveRepo.DeleteArchivedReport - tx.QueryRow: %v", err)
+	}
+
+	sql, args, _ = r.Builder.
+		Update("report_jobs").
+		Set("status", entity.ReportJobStatusExpired).
+		Set("link", "").
+		Set("updated_at", squirrel.Expr("now()")).
+		Where("filename = ? AND status = ?", filename, entity.ReportJobStatusDone).
+		ToSql()
+
+	_, err = tx.Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("ReportArchiveRepo.DeleteArchivedReport - tx.Exec: %v", err)
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return fmt.Errorf("ReportArchiveRepo.DeleteArchivedReport - tx.Commit: %v", err)
+	}
+
+	return nil
+}
+
+func scanArchivedReport(row pgx.Row) (entity.ArchivedReport, error) {
+	var report entity.ArchivedReport
+	err := row.Scan(
+		&report.Id,
+		&report.Filename,
+		&report.Kind,
+		&report.PeriodFrom,
+		&report.PeriodTo,
+		&report.Format,
+		&report.CreatedBy,
+		&report.Size,
+		&report.CreatedAt,
+	)
+	return report, err
+}
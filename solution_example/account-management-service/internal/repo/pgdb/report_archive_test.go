@@ -0,0 +1,102 @@
+package pgdb
+
+import (
+	"account-management-service/internal/entity"
+	"account-management-service/internal/repo/repoerrs"
+	"account-management-service/pkg/postgres"
+	"context"
+	"errors"
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/pashagolub/pgxmock/v2"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestReportArchiveRepo_DeleteArchivedReport(t *testing.T) {
+	type args struct {
+		ctx context.Context
+		id  int
+	}
+
+	type MockBehavior func(m pgxmock.PgxPoolIface, args args)
+
+	testCases := []struct {
+		name         string
+		args         args
+		mockBehavior MockBehavior
+		wantErr      error
+	}{
+		{
+			name: "OK",
+			args: args{
+				ctx: context.Background(),
+				id:  1,
+			},
+			mockBehavior: func(m pgxmock.PgxPoolIface, args args) {
+				m.ExpectBegin()
+				m.ExpectQuery("DELETE FROM report_archive").
+					WithArgs(args.id).
+					WillReturnRows(pgxmock.NewRows([]string{"filename"}).AddRow("report.csv"))
+				m.ExpectExec("UPDATE report_jobs").
+					WithArgs(entity.ReportJobStatusExpired, "", "report.csv", entity.ReportJobStatusDone).
+					WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+				m.ExpectCommit()
+			},
+			wantErr: nil,
+		},
+		{
+			name: "report not found",
+			args: args{
+				ctx: context.Background(),
+				id:  1,
+			},
+			mockBehavior: func(m pgxmock.PgxPoolIface, args args) {
+				m.ExpectBegin()
+				m.ExpectQuery("DELETE FROM report_archive").
+					WithArgs(args.id).
+					WillReturnError(pgx.ErrNoRows)
+				m.ExpectRollback()
+			},
+			wantErr: repoerrs.ErrNotFound,
+		},
+		{
+			name: "cannot expire report jobs",
+			args: args{
+				ctx: context.Background(),
+				id:  1,
+			},
+			mockBehavior: func(m pgxmock.PgxPoolIface, args args) {
+				m.ExpectBegin()
+				m.ExpectQuery("DELETE FROM report_archive").
+					WithArgs(args.id).
+					WillReturnRows(pgxmock.NewRows([]string{"filename"}).AddRow("report.csv"))
+				m.ExpectExec("UPDATE report_jobs").
+					WithArgs(entity.ReportJobStatusExpired, "", "report.csv", entity.ReportJobStatusDone).
+					WillReturnError(errors.New("some error"))
+				m.ExpectRollback()
+			},
+			wantErr: errors.New("ReportArchiveRepo.DeleteArchivedReport - tx.Exec: some error"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			poolMock, _ := pgxmock.NewPool()
+			defer poolMock.Close()
+			tc.mockBehavior(poolMock, tc.args)
+
+			postgresMock := &postgres.Postgres{
+				Builder: squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar),
+				Pool:    poolMock,
+			}
+			reportArchiveRepoMock := NewReportArchiveRepo(postgresMock)
+
+			err := reportArchiveRepoMock.DeleteArchivedReport(tc.args.ctx, tc.args.id)
+			assert.Equal(t, tc.wantErr, err)
+
+			err = poolMock.ExpectationsWereMet()
+			assert.NoError(t, err)
+		})
+	}
+}
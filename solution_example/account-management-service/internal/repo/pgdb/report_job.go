@@ -0,0 +1,275 @@
+package pgdb
+
+import (
+	"account-management-service/internal/entity"
+	"account-management-service/internal/repo/repoerrs"
+	"account-management-service/pkg/postgres"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"strings"
+	"time"
+)
+
+var reportJobColumns = []string{
+	"id",
+	"month",
+	"year",
+	"date_from",
+	"date_to",
+	"version",
+	"mode",
+	"product_ids",
+	"account_ids",
+	"granularity",
+	"format",
+	"csv_delimiter",
+	"csv_bom",
+	"status",
+	"progress",
+	"filename",
+	"link",
+	"error",
+	"attempts",
+	"next_run_at",
+	"created_by",
+	"created_at",
+	"updated_at",
+	"finished_at",
+}
+
+// reportJobErrorMaxLength is the size of the error column
+const reportJobErrorMaxLength = 255
+
+var activeReportJobStatuses = []string{entity.ReportJobStatusPending, entity.ReportJobStatusRunning}
+
+type ReportJobRepo struct {
+	*postgres.Postgres
+}
+
+func NewReportJobRepo(pg *postgres.Postgres) *ReportJobRepo {
+	return &ReportJobRepo{pg}
+}
+
+// CreateReportJob creates the job unless the same report is already being generated,
+// in which case the id of the active job is returned and created is false
+func (r *ReportJobRepo) CreateReportJob(ctx context.Context, params entity.ReportParams, createdBy int) (int, bool, error) {
+	insert, insertArgs, _ := r.Builder.
+		Insert("report_jobs").
+		Columns("key", "month", "year", "date_from", "date_to", "version", "mode", "product_ids", "account_ids", "granularity",
+			"format", "csv_delimiter", "csv_bom", "created_by").
+		Values(params.Key(), params.Month, params.Year, params.From, params.To, params.Version, params.Mode,
+			nonNilIds(params.ProductIds), nonNilIds(params.AccountIds), params.Granularity,
+			params.Format, params.CSVDelimiter, params.CSVBOM, createdBy).
+		Suffix("ON CONFLICT (key) WHERE status IN ('pending', 'running') DO NOTHING RETURNING id").
+		ToSql()
+
+	selectActive, selectArgs, _ := r.Builder.
+		Select("id").
+		From("report_jobs").
+		Where(squirrel.Eq{"key": params.Key(), "status": activeReportJobStatuses}).
+		ToSql()
+
+	// the active job may finish between the statements, then the insert is tried again
+	for attempt := 0; attempt < 3; attempt++ {
+		var id int
+		err := r.Pool.QueryRow(ctx, insert, insertArgs...).Scan(&id)
+		if err == nil {
+			return id, true, nil
+		}
+		if !errors.Is(err, pgx.ErrNoRows) {
+			return 0, false, fmt.Errorf("ReportJobRepo.CreateReportJob - r.Pool.QueryRow: %v", err)
+		}
+
+		err = r.Pool.QueryRow(ctx, selectActive, selectArgs...).Scan(&id)
+		if err == nil {
+			return id, false, nil
+		}
+		if !errors.Is(err, pgx.ErrNoRows) {
+			return 0, false, fmt.Errorf("ReportJobRepo.CreateReportJob - r.Pool.QueryRow: %v", err)
+		}
+	}
+
+	return 0, false, fmt.Errorf("ReportJobRepo.CreateReportJob: cannot create or find the active job for %s", params.Key())
+}
+
+func (r *ReportJobRepo) GetReportJobById(ctx context.Context, id int) (entity.ReportJob, error) {
+	sql, args, _ := r.Builder.
+		Select(reportJobColumns...).
+		From("report_jobs").
+		Where("id = ?", id).
+		ToSql()
+
+	job, err := scanReportJob(r.Pool.QueryRow(ctx, sql, args...))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return entity.ReportJob{}, repoerrs.ErrNotFound
+		}
+		return entity.ReportJob{}, fmt.Errorf("ReportJobRepo.GetReportJobById - r.Pool.QueryRow: %v", err)
+	}
+
+	return job, nil
+}
+
+// ClaimReportJob marks the next due job as running and counts the attempt. Running jobs which have not
+// reported progress for staleAfter are claimed again, as the instance running them has likely died.
+// ErrNotFound is returned if there are no jobs to run
+func (r *ReportJobRepo) ClaimReportJob(ctx context.Context, staleAfter time.Duration) (entity.ReportJob, error) {
+	tx, err := r.Pool.Begin(ctx)
+	if err != nil {
+		return entity.ReportJob{}, fmt.Errorf("ReportJobRepo.ClaimReportJob - r.Pool.Begin: %v", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	sql, args, _ := r.Builder.
+		Select("id").
+		From("report_jobs").
+		Where(squirrel.Or{
+			squirrel.Expr("status = ? AND next_run_at <= now()", entity.ReportJobStatusPending),
+			squirrel.Expr("status = ? AND updated_at < now() - make_interval(secs => ?)", entity.ReportJobStatusRunning, staleAfter.Seconds()),
+		}).
+		OrderBy("id").
+		Limit(1).
+		Suffix("FOR UPDATE SKIP LOCKED").
+		ToSql()
+
+	var id int
+	err = tx.QueryRow(ctx, sql, args...).Scan(&id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return entity.ReportJob{}, repoerrs.ErrNotFound
+		}
+		return entity.ReportJob{}, fmt.Errorf("ReportJobRepo.ClaimReportJob - tx.QueryRow: %v", err)
+	}
+
+	sql, args, _ = r.Builder.
+		Update("report_jobs").
+		Set("status", entity.ReportJobStatusRunning).
+		Set("progress", 0).
+		Set("attempts", squirrel.Expr("attempts + 1")).
+		Set("updated_at", squirrel.Expr("now()")).
+		Where("id = ?", id).
+		Suffix("RETURNING " + strings.Join(reportJobColumns, ", ")).
+		ToSql()
+
+	job, err := scanReportJob(tx.QueryRow(ctx, sql, args...))
+	if err != nil {
+		return entity.ReportJob{}, fmt.Errorf("ReportJobRepo.ClaimReportJob - tx.QueryRow: %v", err)
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return entity.ReportJob{}, fmt.Errorf("ReportJobRepo.ClaimReportJob - tx.Commit: %v", err)
+	}
+
+	return job, nil
+}
+
+func (r *ReportJobRepo) SetReportJobProgress(ctx context.Context, id, progress int) error {
+	sql, args, _ := r.Builder.
+		Update("report_jobs").
+		Set("progress", progress).
+		Set("updated_at", squirrel.Expr("now()")).
+		Where("id = ? AND status = ?", id, entity.ReportJobStatusRunning).
+		ToSql()
+
+	_, err := r.Pool.Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("ReportJobRepo.SetReportJobProgress - r.Pool.Exec: %v", err)
+	}
+
+	return nil
+}
+
+func (r *ReportJobRepo) CompleteReportJob(ctx context.Context, id int, filename, link string) error {
+	sql, args, _ := r.Builder.
+		Update("report_jobs").
+		Set("status", entity.ReportJobStatusDone).
+		Set("progress", 100).
+		Set("filename", filename).
+		Set("link", link).
+		Set("error", "").
+		Set("updated_at", squirrel.Expr("now()")).
+		Set("finished_at", squirrel.Expr("now()")).
+		Where("id = ?", id).
+		ToSql()
+
+	_, err := r.Pool.Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("ReportJobRepo.CompleteReportJob - r.Pool.Exec: %v", err)
+	}
+
+	return nil
+}
+
+// FailReportJobAttempt records the error of the attempt. The job is run again at nextRunAt,
+// or fails for good if nextRunAt is nil
+func (r *ReportJobRepo) FailReportJobAttempt(ctx context.Context, id int, jobErr string, nextRunAt *time.Time) error {
+	if len(jobErr) > reportJobErrorMaxLength {
+		jobErr = jobErr[:reportJobErrorMaxLength]
+	}
+
+	update := r.Builder.
+		Update("report_jobs").
+		Set("error", jobErr).
+		Set("updated_at", squirrel.Expr("now()")).
+		Where("id = ?", id)
+	if nextRunAt != nil {
+		update = update.
+			Set("status", entity.ReportJobStatusPending).
+			Set("next_run_at", *nextRunAt)
+	} else {
+		update = update.
+			Set("status", entity.ReportJobStatusFailed).
+			Set("finished_at", squirrel.Expr("now()"))
+	}
+
+	sql, args, _ := update.ToSql()
+	_, err := r.Pool.Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("ReportJobRepo.FailReportJobAttempt - r.Pool.Exec: %v", err)
+	}
+
+	return nil
+}
+
+func scanReportJob(row pgx.Row) (entity.ReportJob, error) {
+	var job entity.ReportJob
+	err := row.Scan(
+		&job.Id,
+		&job.Params.Month,
+		&job.Params.Year,
+		&job.Params.From,
+		&job.Params.To,
+		&job.Params.Version,
+		&job.Params.Mode,
+		&job.Params.ProductIds,
+		&job.Params.AccountIds,
+		&job.Params.Granularity,
+		&job.Params.Format,
+		&job.Params.CSVDelimiter,
+		&job.Params.CSVBOM,
+		&job.Status,
+		&job.Progress,
+		&job.Filename,
+		&job.Link,
+		&job.Error,
+		&job.Attempts,
+		&job.NextRunAt,
+		&job.CreatedBy,
+		&job.CreatedAt,
+		&job.UpdatedAt,
+		&job.FinishedAt,
+	)
+	return job, err
+}
+
+// nonNilIds keeps the empty filter from being stored as null
+func nonNilIds(ids []int) []int {
+	if ids == nil {
+		return []int{}
+	}
+	return ids
+}
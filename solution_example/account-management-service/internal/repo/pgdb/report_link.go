@@ -0,0 +1,125 @@
+package pgdb
+
+import (
+	"account-management-service/internal/entity"
+	"account-management-service/internal/repo/repoerrs"
+	"account-management-service/pkg/postgres"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+)
+
+type ReportLinkRepo struct {
+	*postgres.Postgres
+}
+
+func NewReportLinkRepo(pg *postgres.Postgres) *ReportLinkRepo {
+	return &ReportLinkRepo{pg}
+}
+
+func (r *ReportLinkRepo) CreateReportLink(ctx context.Context, link entity.ReportLink) (int, error) {
+	sql, args, _ := r.Builder.
+		Insert("report_links").
+		Columns("filename", "expires_at", "one_time").
+		Values(link.Filename, link.ExpiresAt, link.OneTime).
+		Suffix("RETURNING id").
+		ToSql()
+
+	var id int
+	err := r.Pool.QueryRow(ctx, sql, args...).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("ReportLinkRepo.CreateReportLink - r.Pool.QueryRow: %v", err)
+	}
+
+	return id, nil
+}
+
+func (r *ReportLinkRepo) GetReportLinkById(ctx context.Context, id int) (entity.ReportLink, error) {
+	sql, args, _ := r.Builder.
+		Select("id", "filename", "expires_at", "one_time", "used_at", "created_at").
+		From("report_links").
+		Where("id = ?", id).
+		ToSql()
+
+	var link entity.ReportLink
+	err := r.Pool.QueryRow(ctx, sql, args...).Scan(
+		&link.Id,
+		&link.Filename,
+		&link.ExpiresAt,
+		&link.OneTime,
+		&link.UsedAt,
+		&link.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return entity.ReportLink{}, repoerrs.ErrNotFound
+		}
+		return entity.ReportLink{}, fmt.Errorf("ReportLinkRepo.GetReportLinkById - r.Pool.QueryRow: %v", err)
+	}
+
+	return link, nil
+}
+
+// UseReportLink marks the one-time link as used, only one of concurrent downloads succeeds
+func (r *ReportLinkRepo) UseReportLink(ctx context.Context, id int) error {
+	sql, args, _ := r.Builder.
+		Update("report_links").
+		Set("used_at", squirrel.Expr("now()")).
+		Where("id = ? AND used_at IS NULL", id).
+		ToSql()
+
+	tag, err := r.Pool.Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("ReportLinkRepo.UseReportLink - r.Pool.Exec: %v", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return repoerrs.ErrWrongStatus
+	}
+
+	return nil
+}
+
+func (r *ReportLinkRepo) CreateReportLinkAccess(ctx context.Context, access entity.ReportLinkAccess) error {
+	sql, args, _ := r.Builder.
+		Insert("report_link_accesses").
+		Columns("link_id", "ip", "user_agent", "result").
+		Values(access.LinkId, access.IP, access.UserAgent, access.Result).
+		ToSql()
+
+	_, err := r.Pool.Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("ReportLinkRepo.CreateReportLinkAccess - r.Pool.Exec: %v", err)
+	}
+
+	return nil
+}
+
+func (r *ReportLinkRepo) GetReportLinkAccesses(ctx context.Context, linkId int) ([]entity.ReportLinkAccess, error) {
+	sql, args, _ := r.Builder.
+		Select("id", "link_id", "ip", "user_agent", "result", "created_at").
+		From("report_link_accesses").
+		Where("link_id = ?", linkId).
+		OrderBy("id").
+		ToSql()
+
+	rows, err := r.Pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("ReportLinkRepo.GetReportLinkAccesses - r.Pool.Query: %v", err)
+	}
+	defer rows.Close()
+
+	var accesses []entity.ReportLinkAccess
+	for rows.Next() {
+		var access entity.ReportLinkAccess
+		err = rows.Scan(&access.Id, &access.LinkId, &access.IP, &access.UserAgent, &access.Result, &access.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("ReportLinkRepo.GetReportLinkAccesses - rows.Scan: %v", err)
+		}
+		accesses = append(accesses, access)
+	}
+
+	return accesses, nil
+}
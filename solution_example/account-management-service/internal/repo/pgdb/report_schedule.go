@@ -0,0 +1,178 @@
+package pgdb
+
+import (
+	"account-management-service/internal/entity"
+	"account-management-service/internal/repo/repoerrs"
+	"account-management-service/pkg/postgres"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"strings"
+	"time"
+)
+
+var reportScheduleRunColumns = []string{
+	"id",
+	"schedule",
+	"scheduled_at",
+	"status",
+	"attempts",
+	"filename",
+	"link",
+	"delivered",
+	"error",
+	"started_at",
+	"finished_at",
+}
+
+const (
+	// reportScheduleRunErrorMaxLength is the size of the error column
+	reportScheduleRunErrorMaxLength = 255
+	maxReportScheduleRunsLimit      = 100
+	defaultReportScheduleRunsLimit  = 20
+)
+
+type ReportScheduleRepo struct {
+	*postgres.Postgres
+}
+
+func NewReportScheduleRepo(pg *postgres.Postgres) *ReportScheduleRepo {
+	return &ReportScheduleRepo{pg}
+}
+
+// ClaimReportScheduleRun starts the run of the schedule for the scheduled time. The run is created only once,
+// so of the replicas claiming the same time only one gets it. The existing run is claimed again only if it has failed
+// and may be retried or if it is running for too long. ErrNotFound is returned if the run cannot be claimed
+func (r *ReportScheduleRepo) ClaimReportScheduleRun(ctx context.Context, schedule string, scheduledAt time.Time, policy entity.ReportScheduleRetryPolicy) (entity.ReportScheduleRun, error) {
+	sql, args, _ := r.Builder.
+		Insert("report_schedule_runs").
+		Columns("schedule", "scheduled_at").
+		Values(schedule, scheduledAt.UTC()).
+		Suffix("ON CONFLICT (schedule, scheduled_at) DO UPDATE SET "+
+			"status = ?, attempts = report_schedule_runs.attempts + 1, error = '', started_at = now(), finished_at = null "+
+			"WHERE report_schedule_runs.status = ? AND report_schedule_runs.attempts < ? "+
+			"AND report_schedule_runs.finished_at < now() - make_interval(secs => ?) "+
+			"OR report_schedule_runs.status = ? AND report_schedule_runs.started_at < now() - make_interval(secs => ?) "+
+			"RETURNING "+strings.Join(reportScheduleRunColumns, ", "),
+			entity.ReportScheduleRunStatusRunning,
+			entity.ReportScheduleRunStatusFailed, policy.MaxAttempts, policy.RetryInterval.Seconds(),
+			entity.ReportScheduleRunStatusRunning, policy.StaleAfter.Seconds()).
+		ToSql()
+
+	run, err := scanReportScheduleRun(r.Pool.QueryRow(ctx, sql, args...))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return entity.ReportScheduleRun{}, repoerrs.ErrNotFound
+		}
+		return entity.ReportScheduleRun{}, fmt.Errorf("ReportScheduleRepo.ClaimReportScheduleRun - r.Pool.QueryRow: %v", err)
+	}
+
+	return run, nil
+}
+
+// SetReportScheduleRunFile records the uploaded report, so that it is not generated again when the run is retried
+func (r *ReportScheduleRepo) SetReportScheduleRunFile(ctx context.Context, id int, filename, link string) error {
+	sql, args, _ := r.Builder.
+		Update("report_schedule_runs").
+		Set("filename", filename).
+		Set("link", link).
+		Where("id = ?", id).
+		ToSql()
+
+	_, err := r.Pool.Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("ReportScheduleRepo.SetReportScheduleRunFile - r.Pool.Exec: %v", err)
+	}
+
+	return nil
+}
+
+// FinishReportScheduleRun records the channels the report has been delivered through,
+// the run fails if runErr is not empty
+func (r *ReportScheduleRepo) FinishReportScheduleRun(ctx context.Context, id int, delivered []string, runErr string) error {
+	if len(runErr) > reportScheduleRunErrorMaxLength {
+		runErr = runErr[:reportScheduleRunErrorMaxLength]
+	}
+
+	status := entity.ReportScheduleRunStatusDone
+	if runErr != "" {
+		status = entity.ReportScheduleRunStatusFailed
+	}
+	if delivered == nil {
+		delivered = []string{}
+	}
+
+	sql, args, _ := r.Builder.
+		Update("report_schedule_runs").
+		Set("status", status).
+		Set("delivered", delivered).
+		Set("error", runErr).
+		Set("finished_at", squirrel.Expr("now()")).
+		Where("id = ?", id).
+		ToSql()
+
+	_, err := r.Pool.Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("ReportScheduleRepo.FinishReportScheduleRun - r.Pool.Exec: %v", err)
+	}
+
+	return nil
+}
+
+// GetReportScheduleRuns returns the latest runs first, the runs of all schedules if schedule is empty
+func (r *ReportScheduleRepo) GetReportScheduleRuns(ctx context.Context, schedule string, offset, limit int) ([]entity.ReportScheduleRun, error) {
+	if limit > maxReportScheduleRunsLimit {
+		limit = maxReportScheduleRunsLimit
+	}
+	if limit == 0 {
+		limit = defaultReportScheduleRunsLimit
+	}
+
+	query := r.Builder.
+		Select(reportScheduleRunColumns...).
+		From("report_schedule_runs").
+		OrderBy("scheduled_at DESC", "id DESC").
+		Limit(uint64(limit)).
+		Offset(uint64(offset))
+	if schedule != "" {
+		query = query.Where("schedule = ?", schedule)
+	}
+
+	sql, args, _ := query.ToSql()
+	rows, err := r.Pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("ReportScheduleRepo.GetReportScheduleRuns - r.Pool.Query: %v", err)
+	}
+	defer rows.Close()
+
+	var runs []entity.ReportScheduleRun
+	for rows.Next() {
+		run, err := scanReportScheduleRun(rows)
+		if err != nil {
+			return nil, fmt.Errorf("ReportScheduleRepo.GetReportScheduleRuns - rows.Scan: %v", err)
+		}
+		runs = append(runs, run)
+	}
+
+	return runs, nil
+}
+
+func scanReportScheduleRun(row pgx.Row) (entity.ReportScheduleRun, error) {
+	var run entity.ReportScheduleRun
+	err := row.Scan(
+		&run.Id,
+		&run.Schedule,
+		&run.ScheduledAt,
+		&run.Status,
+		&run.Attempts,
+		&run.Filename,
+		&run.Link,
+		&run.Delivered,
+		&run.Error,
+		&run.StartedAt,
+		&run.FinishedAt,
+	)
+	return run, err
+}
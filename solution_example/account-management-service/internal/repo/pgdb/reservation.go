@@ -5,8 +5,10 @@ import (
 	"account-management-service/internal/repo/repoerrs"
 	"account-management-service/pkg/postgres"
 	"context"
+	"errors"
 	"fmt"
 	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
 )
 
 type ReservationRepo struct {
@@ -17,49 +19,70 @@ func NewReservationRepo(pg *postgres.Postgres) *ReservationRepo {
 	return &ReservationRepo{pg}
 }
 
-func (r *ReservationRepo) CreateReservation(ctx context.Context, reservation entity.Reservation) (int, error) {
+// CreateReservation takes the reservation amount from account wallets in spendingOrder
+// and remembers how much was taken from each of them
+func (r *ReservationRepo) CreateReservation(ctx context.Context, reservation entity.Reservation, spendingOrder []string, fee entity.Fee, limits []entity.LimitRule) (int, error) {
 	tx, err := r.Pool.Begin(ctx)
 	if err != nil {
 		return 0, fmt.Errorf("ReservationRepo.CreateReservation - r.Pool.Begin: %v", err)
 	}
 	defer func() { _ = tx.Rollback(ctx) }()
 
-	// check if account has enough balance to create reservation
-	sql, args, _ := r.Builder.
-		Select("balance").
-		From("accounts").
-		Where("id = ?", reservation.AccountId).
-		ToSql()
+	status, err := lockAccount(ctx, tx, r.Builder, reservation.AccountId)
+	if err != nil {
+		if errors.Is(err, repoerrs.ErrNotFound) {
+			return 0, err
+		}
+		return 0, fmt.Errorf("ReservationRepo.CreateReservation - lockAccount: %v", err)
+	}
+
+	if err = checkAccountStatus(status, canDebit...); err != nil {
+		return 0, err
+	}
 
-	var balance int
-	err = tx.QueryRow(ctx, sql, args...).Scan(&balance)
+	err = checkWindowLimits(ctx, tx, r.Builder, reservation.AccountId, entity.OperationTypeReservation, reservation.Amount, limits)
 	if err != nil {
-		return 0, fmt.Errorf("ReservationRepo.CreateReservation - tx.QueryRow: %v", err)
+		if errors.Is(err, repoerrs.ErrLimitExceeded) {
+			return 0, err
+		}
+		return 0, fmt.Errorf("ReservationRepo.CreateReservation - checkWindowLimits: %v", err)
 	}
 
-	if balance < reservation.Amount {
-		return 0, repoerrs.ErrNotEnoughBalance
+	reservation.UserId, err = checkAccountSpending(ctx, tx, r.Builder, reservation.AccountId, reservation.UserId, reservation.Amount)
+	if err != nil {
+		if errors.Is(err, repoerrs.ErrNotGroupMember) || errors.Is(err, repoerrs.ErrMemberCannotSpend) ||
+			errors.Is(err, repoerrs.ErrMemberLimitExceeded) {
+			return 0, err
+		}
+		return 0, fmt.Errorf("ReservationRepo.CreateReservation - checkAccountSpending: %v", err)
 	}
 
-	sql, args, _ = r.Builder.
-		Update("accounts").
-		Set("balance", squirrel.Expr("balance - ?", reservation.Amount)).
-		Where("id = ?", reservation.AccountId).
-		ToSql()
+	// check if account wallets have enough balance to create reservation
+	balances, err := walletBalances(ctx, tx, r.Builder, reservation.AccountId)
+	if err != nil {
+		return 0, fmt.Errorf("ReservationRepo.CreateReservation - walletBalances: %v", err)
+	}
 
-	_, err = tx.Exec(ctx, sql, args...)
+	// the fee can be paid from the main wallet only, so it is set aside before the amount is split
+	balances[entity.WalletTypeMain] -= fee.Amount
+	if balances[entity.WalletTypeMain] < 0 {
+		return 0, repoerrs.ErrNotEnoughBalance
+	}
+
+	funds, err := splitByWallets(balances, spendingOrder, reservation.Amount)
 	if err != nil {
-		return 0, fmt.Errorf("ReservationRepo.CreateReservation - tx.Exec: %v", err)
+		return 0, err
 	}
 
-	sql, args, _ = r.Builder.
+	sql, args, _ := r.Builder.
 		Insert("reservations").
-		Columns("account_id", "product_id", "order_id", "amount").
+		Columns("account_id", "product_id", "order_id", "amount", "user_id").
 		Values(
 			reservation.AccountId,
 			reservation.ProductId,
 			reservation.OrderId,
 			reservation.Amount,
+			reservation.UserId,
 		).
 		Suffix("RETURNING id").
 		ToSql()
@@ -70,21 +93,40 @@ func (r *ReservationRepo) CreateReservation(ctx context.Context, reservation ent
 		return 0, fmt.Errorf("ReservationRepo.CreateReservation - tx.QueryRow: %v", err)
 	}
 
-	sql, args, _ = r.Builder.
-		Insert("operations").
-		Columns("account_id", "amount", "operation_type", "product_id", "order_id").
-		Values(
-			reservation.AccountId,
-			reservation.Amount,
-			entity.OperationTypeReservation,
-			reservation.ProductId,
-			reservation.OrderId,
-		).
-		ToSql()
+	for _, fund := range funds {
+		err = debitWallet(ctx, tx, r.Builder, reservation.AccountId, fund.WalletType, fund.Amount)
+		if err != nil {
+			return 0, fmt.Errorf("ReservationRepo.CreateReservation - debitWallet: %v", err)
+		}
+
+		sql, args, _ = r.Builder.
+			Insert("reservation_funds").
+			Columns("reservation_id", "wallet_type", "amount").
+			Values(id, fund.WalletType, fund.Amount).
+			ToSql()
+
+		_, err = tx.Exec(ctx, sql, args...)
+		if err != nil {
+			return 0, fmt.Errorf("ReservationRepo.CreateReservation - tx.Exec: %v", err)
+		}
+
+		err = insertOperation(ctx, tx, r.Builder, entity.Operation{
+			AccountId:     reservation.AccountId,
+			Amount:        fund.Amount,
+			OperationType: entity.OperationTypeReservation,
+			WalletType:    fund.WalletType,
+			ProductId:     &reservation.ProductId,
+			OrderId:       &reservation.OrderId,
+			UserId:        reservation.UserId,
+		})
+		if err != nil {
+			return 0, fmt.Errorf("ReservationRepo.CreateReservation - insertOperation: %v", err)
+		}
+	}
 
-	_, err = tx.Exec(ctx, sql, args...)
+	err = collectFee(ctx, tx, r.Builder, reservation.AccountId, fee, entity.OperationTypeReservation)
 	if err != nil {
-		return 0, fmt.Errorf("ReservationRepo.CreateReservation - tx.Exec: %v", err)
+		return 0, fmt.Errorf("ReservationRepo.CreateReservation - collectFee: %v", err)
 	}
 
 	err = tx.Commit(ctx)
@@ -97,7 +139,7 @@ func (r *ReservationRepo) CreateReservation(ctx context.Context, reservation ent
 
 func (r *ReservationRepo) GetReservationById(ctx context.Context, id int) (entity.Reservation, error) {
 	sql, args, _ := r.Builder.
-		Select("*").
+		Select("id", "account_id", "product_id", "order_id", "amount", "created_at", "user_id").
 		From("reservations").
 		Where("id = ?", id).
 		ToSql()
@@ -110,6 +152,7 @@ func (r *ReservationRepo) GetReservationById(ctx context.Context, id int) (entit
 		&reservation.OrderId,
 		&reservation.Amount,
 		&reservation.CreatedAt,
+		&reservation.UserId,
 	)
 	if err != nil {
 		return entity.Reservation{}, fmt.Errorf("ReservationRepo.GetReservationById - r.Pool.QueryRow: %v", err)
@@ -118,220 +161,298 @@ func (r *ReservationRepo) GetReservationById(ctx context.Context, id int) (entit
 	return reservation, nil
 }
 
-func (r *ReservationRepo) RefundReservationById(ctx context.Context, id int) error {
-	tx, err := r.Pool.Begin(ctx)
-	if err != nil {
-		return fmt.Errorf("ReservationRepo.DeleteReservationById - r.Pool.Begin: %v", err)
-	}
-	defer func() { _ = tx.Rollback(ctx) }()
-
+func (r *ReservationRepo) GetReservationFunds(ctx context.Context, reservationId int) ([]entity.ReservationFund, error) {
 	sql, args, _ := r.Builder.
-		Delete("reservations").
-		Where("id = ?", id).
-		Suffix("RETURNING account_id, product_id, order_id, amount").
+		Select("reservation_id", "wallet_type", "amount").
+		From("reservation_funds").
+		Where("reservation_id = ?", reservationId).
 		ToSql()
 
-	var reservation entity.Reservation
-	err = tx.QueryRow(ctx, sql, args...).Scan(
-		&reservation.AccountId,
-		&reservation.ProductId,
-		&reservation.OrderId,
-		&reservation.Amount,
-	)
+	rows, err := r.Pool.Query(ctx, sql, args...)
 	if err != nil {
-		return fmt.Errorf("ReservationRepo.DeleteReservationById - tx.QueryRow: %v", err)
+		return nil, fmt.Errorf("ReservationRepo.GetReservationFunds - r.Pool.Query: %v", err)
 	}
+	defer rows.Close()
 
-	sql, args, _ = r.Builder.
-		Update("accounts").
-		Set("balance", squirrel.Expr("balance + ?", reservation.Amount)).
-		Where("id = ?", reservation.AccountId).
-		ToSql()
-
-	_, err = tx.Exec(ctx, sql, args...)
+	funds, err := scanReservationFunds(rows)
 	if err != nil {
-		return fmt.Errorf("ReservationRepo.DeleteReservationById - tx.Exec: %v", err)
+		return nil, fmt.Errorf("ReservationRepo.GetReservationFunds - scanReservationFunds: %v", err)
 	}
 
-	sql, args, _ = r.Builder.
-		Insert("operations").
-		Columns("account_id", "amount", "operation_type", "product_id", "order_id").
-		Values(
-			reservation.AccountId,
-			reservation.Amount,
-			entity.OperationTypeRefund,
-			reservation.ProductId,
-			reservation.OrderId,
-		).
-		ToSql()
+	return funds, nil
+}
 
-	_, err = tx.Exec(ctx, sql, args...)
+func (r *ReservationRepo) RefundReservationById(ctx context.Context, id int) error {
+	err := r.finishReservation(ctx, "id = ?", id, entity.OperationTypeRefund)
 	if err != nil {
-		return fmt.Errorf("ReservationRepo.DeleteReservationById - tx.Exec: %v", err)
+		return fmt.Errorf("ReservationRepo.RefundReservationById - r.finishReservation: %w", err)
 	}
+	return nil
+}
 
-	err = tx.Commit(ctx)
+func (r *ReservationRepo) RefundReservationByOrderId(ctx context.Context, orderId int) error {
+	err := r.finishReservation(ctx, "order_id = ?", orderId, entity.OperationTypeRefund)
 	if err != nil {
-		return fmt.Errorf("ReservationRepo.DeleteReservationById - tx.Commit: %v", err)
+		return fmt.Errorf("ReservationRepo.RefundReservationByOrderId - r.finishReservation: %w", err)
 	}
-
 	return nil
 }
 
-func (r *ReservationRepo) RefundReservationByOrderId(ctx context.Context, orderId int) error {
+// RefundCapturedOrder returns the money of the order whose revenue is already recognized and takes back
+// its cashback. ErrNotFound is returned if the order is not captured or is already refunded
+func (r *ReservationRepo) RefundCapturedOrder(ctx context.Context, orderId int) error {
 	tx, err := r.Pool.Begin(ctx)
 	if err != nil {
-		return fmt.Errorf("ReservationRepo.DeleteReservationByOrderId - r.Pool.Begin: %v", err)
+		return fmt.Errorf("ReservationRepo.RefundCapturedOrder - r.Pool.Begin: %v", err)
 	}
 	defer func() { _ = tx.Rollback(ctx) }()
 
-	sql, args, _ := r.Builder.
-		Delete("reservations").
-		Where("order_id = ?", orderId).
-		Suffix("RETURNING account_id, product_id, order_id, amount").
-		ToSql()
-
-	var reservation entity.Reservation
-	err = tx.QueryRow(ctx, sql, args...).Scan(
-		&reservation.AccountId,
-		&reservation.ProductId,
-		&reservation.OrderId,
-		&reservation.Amount,
-	)
+	err = refundCapturedOrderTx(ctx, tx, r.Builder, orderId)
 	if err != nil {
-		return fmt.Errorf("ReservationRepo.DeleteReservationByOrderId - tx.QueryRow: %v", err)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return repoerrs.ErrNotFound
+		}
+		return fmt.Errorf("ReservationRepo.RefundCapturedOrder - refundCapturedOrderTx: %w", err)
 	}
 
-	sql, args, _ = r.Builder.
-		Update("accounts").
-		Set("balance", squirrel.Expr("balance + ?", reservation.Amount)).
-		Where("id = ?", reservation.AccountId).
-		ToSql()
-
-	_, err = tx.Exec(ctx, sql, args...)
+	err = tx.Commit(ctx)
 	if err != nil {
-		return fmt.Errorf("ReservationRepo.DeleteReservationByOrderId - tx.Exec: %v", err)
+		return fmt.Errorf("ReservationRepo.RefundCapturedOrder - tx.Commit: %v", err)
 	}
 
-	sql, args, _ = r.Builder.
-		Insert("operations").
-		Columns("account_id", "amount", "operation_type", "product_id", "order_id").
-		Values(
-			reservation.AccountId,
-			reservation.Amount,
-			entity.OperationTypeRefund,
-			reservation.ProductId,
-			reservation.OrderId,
-		).
-		ToSql()
+	return nil
+}
 
-	_, err = tx.Exec(ctx, sql, args...)
+func (r *ReservationRepo) RevenueReservationById(ctx context.Context, id int) error {
+	err := r.finishReservation(ctx, "id = ?", id, entity.OperationTypeRevenue)
 	if err != nil {
-		return fmt.Errorf("ReservationRepo.DeleteReservationByOrderId - tx.Exec: %v", err)
+		return fmt.Errorf("ReservationRepo.RevenueReservationById - r.finishReservation: %w", err)
 	}
+	return nil
+}
 
-	err = tx.Commit(ctx)
+func (r *ReservationRepo) RevenueReservationByOrderId(ctx context.Context, orderId int) error {
+	err := r.finishReservation(ctx, "order_id = ?", orderId, entity.OperationTypeRevenue)
 	if err != nil {
-		return fmt.Errorf("ReservationRepo.DeleteReservationByOrderId - tx.Commit: %v", err)
+		return fmt.Errorf("ReservationRepo.RevenueReservationByOrderId - r.finishReservation: %w", err)
 	}
-
 	return nil
 }
 
-func (r *ReservationRepo) RevenueReservationById(ctx context.Context, id int) error {
+// finishReservation deletes the reservation matching the condition and either returns its funds
+// back to the wallets they were taken from (refund) or recognizes them as revenue and grants cashback
+func (r *ReservationRepo) finishReservation(ctx context.Context, pred string, arg int, operationType string) error {
 	tx, err := r.Pool.Begin(ctx)
 	if err != nil {
-		return fmt.Errorf("ReservationRepo.RevenueReservationById - r.Pool.Begin: %v", err)
+		return err
 	}
 	defer func() { _ = tx.Rollback(ctx) }()
 
-	sql, args, _ := r.Builder.
-		Delete("reservations").
-		Where("id = ?", id).
-		Suffix("RETURNING account_id, product_id, order_id, amount").
+	err = finishReservationTx(ctx, tx, r.Builder, pred, arg, operationType)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// finishReservationTx is finishReservation within the caller transaction. An order paid by installments
+// cannot be recognized as revenue until the plan is paid, and refunding it cancels the plan
+func finishReservationTx(ctx context.Context, tx pgx.Tx, builder squirrel.StatementBuilderType, pred string, arg int, operationType string) error {
+	sql, args, _ := builder.
+		Select("id", "account_id", "product_id", "order_id", "amount", "user_id").
+		From("reservations").
+		Where(pred, arg).
+		Suffix("FOR UPDATE").
 		ToSql()
 
 	var reservation entity.Reservation
-	err = tx.QueryRow(ctx, sql, args...).Scan(
+	err := tx.QueryRow(ctx, sql, args...).Scan(
+		&reservation.Id,
 		&reservation.AccountId,
 		&reservation.ProductId,
 		&reservation.OrderId,
 		&reservation.Amount,
+		&reservation.UserId,
 	)
 	if err != nil {
-		return fmt.Errorf("ReservationRepo.RevenueReservationById - tx.QueryRow: %v", err)
+		return err
 	}
 
-	sql, args, _ = r.Builder.
-		Insert("operations").
-		Columns("account_id", "amount", "operation_type", "product_id", "order_id").
-		Values(
-			reservation.AccountId,
-			reservation.Amount,
-			entity.OperationTypeRevenue,
-			reservation.ProductId,
-			reservation.OrderId,
-		).
+	status, err := lockAccount(ctx, tx, builder, reservation.AccountId)
+	if err != nil {
+		return err
+	}
+
+	allowed := canSettle
+	if operationType == entity.OperationTypeRefund {
+		allowed = canRefund
+	}
+	if err = checkAccountStatus(status, allowed...); err != nil {
+		return err
+	}
+
+	if operationType == entity.OperationTypeRefund {
+		err = cancelInstallmentPlan(ctx, tx, builder, reservation.OrderId)
+	} else {
+		err = checkInstallmentsPaid(ctx, tx, builder, reservation.OrderId)
+	}
+	if err != nil {
+		return err
+	}
+
+	// funds are read before the reservation is deleted as they are removed with it
+	sql, args, _ = builder.
+		Select("reservation_id", "wallet_type", "amount").
+		From("reservation_funds").
+		Where("reservation_id = ?", reservation.Id).
 		ToSql()
 
-	_, err = tx.Exec(ctx, sql, args...)
+	rows, err := tx.Query(ctx, sql, args...)
+	if err != nil {
+		return err
+	}
+	funds, err := scanReservationFunds(rows)
+	rows.Close()
 	if err != nil {
-		return fmt.Errorf("ReservationRepo.RevenueReservationById - tx.Exec: %v", err)
+		return err
 	}
 
-	err = tx.Commit(ctx)
+	sql, args, _ = builder.
+		Delete("reservations").
+		Where("id = ?", reservation.Id).
+		ToSql()
+
+	_, err = tx.Exec(ctx, sql, args...)
 	if err != nil {
-		return fmt.Errorf("ReservationRepo.RevenueReservationById - tx.Commit: %v", err)
+		return err
+	}
+
+	for _, fund := range funds {
+		if operationType == entity.OperationTypeRefund {
+			err = creditWallet(ctx, tx, builder, reservation.AccountId, fund.WalletType, fund.Amount)
+			if err != nil {
+				return err
+			}
+		}
+
+		err = insertOperation(ctx, tx, builder, entity.Operation{
+			AccountId:     reservation.AccountId,
+			Amount:        fund.Amount,
+			OperationType: operationType,
+			WalletType:    fund.WalletType,
+			ProductId:     &reservation.ProductId,
+			OrderId:       &reservation.OrderId,
+			UserId:        reservation.UserId,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	if operationType == entity.OperationTypeRevenue {
+		err = grantCashback(ctx, tx, builder, reservation.AccountId, reservation.ProductId, &reservation.OrderId, funds)
+		if err != nil {
+			return err
+		}
 	}
 
 	return nil
 }
 
-func (r *ReservationRepo) RevenueReservationByOrderId(ctx context.Context, orderId int) error {
-	tx, err := r.Pool.Begin(ctx)
+// refundCapturedOrderTx returns the revenue of the order to the wallets it was taken from and takes back
+// its cashback. pgx.ErrNoRows is returned if the order was not captured or is already refunded
+func refundCapturedOrderTx(ctx context.Context, tx pgx.Tx, builder squirrel.StatementBuilderType, orderId int) error {
+	sql, args, _ := builder.
+		Select("account_id", "product_id", "wallet_type", "amount", "user_id").
+		From("operations").
+		Where("order_id = ? and operation_type = ?", orderId, entity.OperationTypeRevenue).
+		OrderBy("id").
+		ToSql()
+
+	rows, err := tx.Query(ctx, sql, args...)
 	if err != nil {
-		return fmt.Errorf("ReservationRepo.RevenueReservationByOrderId - r.Pool.Begin: %v", err)
+		return err
 	}
-	defer func() { _ = tx.Rollback(ctx) }()
 
-	sql, args, _ := r.Builder.
-		Delete("reservations").
-		Where("order_id = ?", orderId).
-		Suffix("RETURNING account_id, product_id, order_id, amount").
-		ToSql()
+	var revenues []entity.Operation
+	for rows.Next() {
+		var revenue entity.Operation
+		err = rows.Scan(&revenue.AccountId, &revenue.ProductId, &revenue.WalletType, &revenue.Amount, &revenue.UserId)
+		if err != nil {
+			rows.Close()
+			return err
+		}
+		revenues = append(revenues, revenue)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return err
+	}
 
-	var reservation entity.Reservation
-	err = tx.QueryRow(ctx, sql, args...).Scan(
-		&reservation.AccountId,
-		&reservation.ProductId,
-		&reservation.OrderId,
-		&reservation.Amount,
-	)
+	if len(revenues) == 0 {
+		return pgx.ErrNoRows
+	}
+	accountId, productId := revenues[0].AccountId, *revenues[0].ProductId
+
+	// the account lock also serializes concurrent refunds of the same order
+	status, err := lockAccount(ctx, tx, builder, accountId)
 	if err != nil {
-		return fmt.Errorf("ReservationRepo.RevenueReservationByOrderId - tx.QueryRow: %v", err)
+		return err
 	}
 
-	sql, args, _ = r.Builder.
-		Insert("operations").
-		Columns("account_id", "amount", "operation_type", "product_id", "order_id").
-		Values(
-			reservation.AccountId,
-			reservation.Amount,
-			entity.OperationTypeRevenue,
-			reservation.ProductId,
-			reservation.OrderId,
-		).
+	if err = checkAccountStatus(status, canRefund...); err != nil {
+		return err
+	}
+
+	sql, args, _ = builder.
+		Select("count(*)").
+		From("operations").
+		Where("order_id = ? and operation_type = ?", orderId, entity.OperationTypeRevenueRefund).
 		ToSql()
 
-	_, err = tx.Exec(ctx, sql, args...)
+	var refunds int
+	err = tx.QueryRow(ctx, sql, args...).Scan(&refunds)
 	if err != nil {
-		return fmt.Errorf("ReservationRepo.RevenueReservationByOrderId - tx.Exec: %v", err)
+		return err
+	}
+	if refunds > 0 {
+		return pgx.ErrNoRows
 	}
 
-	err = tx.Commit(ctx)
-	if err != nil {
-		return fmt.Errorf("ReservationRepo.RevenueReservationByOrderId - tx.Commit: %v", err)
+	for _, revenue := range revenues {
+		err = creditWallet(ctx, tx, builder, accountId, revenue.WalletType, revenue.Amount)
+		if err != nil {
+			return err
+		}
+
+		err = insertOperation(ctx, tx, builder, entity.Operation{
+			AccountId:     accountId,
+			Amount:        revenue.Amount,
+			OperationType: entity.OperationTypeRevenueRefund,
+			WalletType:    revenue.WalletType,
+			ProductId:     &productId,
+			OrderId:       &orderId,
+			UserId:        revenue.UserId,
+		})
+		if err != nil {
+			return err
+		}
 	}
 
-	return nil
+	return reverseCashback(ctx, tx, builder, accountId, productId, orderId)
+}
+
+func scanReservationFunds(rows pgx.Rows) ([]entity.ReservationFund, error) {
+	var funds []entity.ReservationFund
+	for rows.Next() {
+		var fund entity.ReservationFund
+		err := rows.Scan(&fund.ReservationId, &fund.WalletType, &fund.Amount)
+		if err != nil {
+			return nil, err
+		}
+		funds = append(funds, fund)
+	}
+
+	return funds, rows.Err()
 }
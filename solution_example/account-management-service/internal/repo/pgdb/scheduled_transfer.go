@@ -0,0 +1,259 @@
+package pgdb
+
+import (
+	"account-management-service/internal/entity"
+	"account-management-service/internal/repo/repoerrs"
+	"account-management-service/pkg/postgres"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/jackc/pgx/v5"
+)
+
+var scheduledTransferColumns = []string{
+	"id",
+	"from_account_id",
+	"to_account_id",
+	"amount",
+	"execute_at",
+	"repeat",
+	"status",
+	"created_at",
+}
+
+type ScheduledTransferRepo struct {
+	*postgres.Postgres
+}
+
+func NewScheduledTransferRepo(pg *postgres.Postgres) *ScheduledTransferRepo {
+	return &ScheduledTransferRepo{pg}
+}
+
+func (r *ScheduledTransferRepo) CreateScheduledTransfer(ctx context.Context, transfer entity.ScheduledTransfer) (int, error) {
+	sql, args, _ := r.Builder.
+		Insert("scheduled_transfers").
+		Columns("from_account_id", "to_account_id", "amount", "execute_at", "repeat").
+		Values(
+			transfer.FromAccountId,
+			transfer.ToAccountId,
+			transfer.Amount,
+			transfer.ExecuteAt,
+			transfer.Repeat,
+		).
+		Suffix("RETURNING id").
+		ToSql()
+
+	var id int
+	err := r.Pool.QueryRow(ctx, sql, args...).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("ScheduledTransferRepo.CreateScheduledTransfer - r.Pool.QueryRow: %v", err)
+	}
+
+	return id, nil
+}
+
+func (r *ScheduledTransferRepo) GetScheduledTransferById(ctx context.Context, id int) (entity.ScheduledTransfer, error) {
+	sql, args, _ := r.Builder.
+		Select(scheduledTransferColumns...).
+		From("scheduled_transfers").
+		Where("id = ?", id).
+		ToSql()
+
+	transfer, err := scanScheduledTransfer(r.Pool.QueryRow(ctx, sql, args...))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return entity.ScheduledTransfer{}, repoerrs.ErrNotFound
+		}
+		return entity.ScheduledTransfer{}, fmt.Errorf("ScheduledTransferRepo.GetScheduledTransferById - r.Pool.QueryRow: %v", err)
+	}
+
+	return transfer, nil
+}
+
+func (r *ScheduledTransferRepo) GetScheduledTransferRuns(ctx context.Context, id int) ([]entity.ScheduledTransferRun, error) {
+	sql, args, _ := r.Builder.
+		Select("id", "scheduled_transfer_id", "status", "COALESCE(error, '')", "created_at").
+		From("scheduled_transfer_runs").
+		Where("scheduled_transfer_id = ?", id).
+		OrderBy("id").
+		ToSql()
+
+	rows, err := r.Pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("ScheduledTransferRepo.GetScheduledTransferRuns - r.Pool.Query: %v", err)
+	}
+	defer rows.Close()
+
+	var runs []entity.ScheduledTransferRun
+	for rows.Next() {
+		var run entity.ScheduledTransferRun
+		err = rows.Scan(&run.Id, &run.ScheduledTransferId, &run.Status, &run.Error, &run.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("ScheduledTransferRepo.GetScheduledTransferRuns - rows.Scan: %v", err)
+		}
+		runs = append(runs, run)
+	}
+
+	return runs, nil
+}
+
+// CancelScheduledTransfer cancels the transfer if it is still pending, ErrNotFound is returned otherwise
+func (r *ScheduledTransferRepo) CancelScheduledTransfer(ctx context.Context, id int) error {
+	sql, args, _ := r.Builder.
+		Update("scheduled_transfers").
+		Set("status", entity.ScheduledTransferStatusCancelled).
+		Where("id = ? and status = ?", id, entity.ScheduledTransferStatusPending).
+		ToSql()
+
+	tag, err := r.Pool.Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("ScheduledTransferRepo.CancelScheduledTransfer - r.Pool.Exec: %v", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return repoerrs.ErrNotFound
+	}
+
+	return nil
+}
+
+func (r *ScheduledTransferRepo) GetDueScheduledTransfers(ctx context.Context, limit int) ([]entity.ScheduledTransfer, error) {
+	sql, args, _ := r.Builder.
+		Select(scheduledTransferColumns...).
+		From("scheduled_transfers").
+		Where("status = ? and execute_at <= now()", entity.ScheduledTransferStatusPending).
+		OrderBy("execute_at").
+		Limit(uint64(limit)).
+		ToSql()
+
+	rows, err := r.Pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("ScheduledTransferRepo.GetDueScheduledTransfers - r.Pool.Query: %v", err)
+	}
+	defer rows.Close()
+
+	var transfers []entity.ScheduledTransfer
+	for rows.Next() {
+		transfer, err := scanScheduledTransfer(rows)
+		if err != nil {
+			return nil, fmt.Errorf("ScheduledTransferRepo.GetDueScheduledTransfers - rows.Scan: %v", err)
+		}
+		transfers = append(transfers, transfer)
+	}
+
+	return transfers, nil
+}
+
+// ExecuteScheduledTransfer moves money for a due transfer and records the result of the run.
+// If rejectReason is not empty the transfer is not attempted and the run is recorded as failed with that reason.
+// One-off transfers are finished by the run, repeating ones are moved to the next date regardless of the result.
+// ErrNotFound is returned if the transfer is not due anymore, e.g. it was cancelled or executed by another instance
+func (r *ScheduledTransferRepo) ExecuteScheduledTransfer(ctx context.Context, id int, rejectReason string, fee entity.Fee, limits []entity.LimitRule, verification entity.VerificationCheck) (entity.ScheduledTransferRun, error) {
+	tx, err := r.Pool.Begin(ctx)
+	if err != nil {
+		return entity.ScheduledTransferRun{}, fmt.Errorf("ScheduledTransferRepo.ExecuteScheduledTransfer - r.Pool.Begin: %v", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	sql, args, _ := r.Builder.
+		Select(scheduledTransferColumns...).
+		From("scheduled_transfers").
+		Where("id = ? and status = ? and execute_at <= now()", id, entity.ScheduledTransferStatusPending).
+		Suffix("FOR UPDATE SKIP LOCKED").
+		ToSql()
+
+	transfer, err := scanScheduledTransfer(tx.QueryRow(ctx, sql, args...))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return entity.ScheduledTransferRun{}, repoerrs.ErrNotFound
+		}
+		return entity.ScheduledTransferRun{}, fmt.Errorf("ScheduledTransferRepo.ExecuteScheduledTransfer - tx.QueryRow: %v", err)
+	}
+
+	run := entity.ScheduledTransferRun{
+		ScheduledTransferId: transfer.Id,
+		Status:              entity.ScheduledTransferStatusSucceeded,
+		Error:               rejectReason,
+	}
+
+	if rejectReason != "" {
+		run.Status = entity.ScheduledTransferStatusFailed
+	} else {
+		// the transfer runs in a savepoint, so that its failure can still be recorded
+		err = r.transferInSavepoint(ctx, tx, transfer, fee, limits, verification)
+		if err != nil {
+			if !errors.Is(err, repoerrs.ErrNotFound) && !errors.Is(err, repoerrs.ErrNotEnoughBalance) &&
+				!errors.Is(err, repoerrs.ErrAccountFrozen) && !errors.Is(err, repoerrs.ErrAccountClosed) &&
+				!errors.Is(err, repoerrs.ErrLimitExceeded) && !errors.Is(err, repoerrs.ErrVerificationRequired) {
+				return entity.ScheduledTransferRun{}, fmt.Errorf("ScheduledTransferRepo.ExecuteScheduledTransfer - r.transferInSavepoint: %v", err)
+			}
+			run.Status = entity.ScheduledTransferStatusFailed
+			run.Error = err.Error()
+		}
+	}
+
+	update := r.Builder.
+		Update("scheduled_transfers").
+		Where("id = ?", transfer.Id)
+	if transfer.Repeat == entity.TransferRepeatWeekly {
+		update = update.Set("execute_at", transfer.ExecuteAt.AddDate(0, 0, 7))
+	} else {
+		update = update.Set("status", run.Status)
+	}
+
+	sql, args, _ = update.ToSql()
+	_, err = tx.Exec(ctx, sql, args...)
+	if err != nil {
+		return entity.ScheduledTransferRun{}, fmt.Errorf("ScheduledTransferRepo.ExecuteScheduledTransfer - tx.Exec: %v", err)
+	}
+
+	sql, args, _ = r.Builder.
+		Insert("scheduled_transfer_runs").
+		Columns("scheduled_transfer_id", "status", "error").
+		Values(run.ScheduledTransferId, run.Status, run.Error).
+		Suffix("RETURNING id, created_at").
+		ToSql()
+
+	err = tx.QueryRow(ctx, sql, args...).Scan(&run.Id, &run.CreatedAt)
+	if err != nil {
+		return entity.ScheduledTransferRun{}, fmt.Errorf("ScheduledTransferRepo.ExecuteScheduledTransfer - tx.QueryRow: %v", err)
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return entity.ScheduledTransferRun{}, fmt.Errorf("ScheduledTransferRepo.ExecuteScheduledTransfer - tx.Commit: %v", err)
+	}
+
+	return run, nil
+}
+
+func (r *ScheduledTransferRepo) transferInSavepoint(ctx context.Context, tx pgx.Tx, transfer entity.ScheduledTransfer, fee entity.Fee, limits []entity.LimitRule, verification entity.VerificationCheck) error {
+	sp, err := tx.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = sp.Rollback(ctx) }()
+
+	err = transferMoney(ctx, sp, r.Builder, transfer.FromAccountId, transfer.ToAccountId, transfer.Amount, nil, fee, limits, verification,
+		fmt.Sprintf("scheduled transfer #%d", transfer.Id))
+	if err != nil {
+		return err
+	}
+
+	return sp.Commit(ctx)
+}
+
+func scanScheduledTransfer(row pgx.Row) (entity.ScheduledTransfer, error) {
+	var t entity.ScheduledTransfer
+	err := row.Scan(
+		&t.Id,
+		&t.FromAccountId,
+		&t.ToAccountId,
+		&t.Amount,
+		&t.ExecuteAt,
+		&t.Repeat,
+		&t.Status,
+		&t.CreatedAt,
+	)
+	return t, err
+}
@@ -0,0 +1,420 @@
+package pgdb
+
+import (
+	"account-management-service/internal/entity"
+	"account-management-service/internal/repo/repoerrs"
+	"account-management-service/pkg/postgres"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"time"
+)
+
+var subscriptionColumns = []string{
+	"id",
+	"account_id",
+	"product_id",
+	"amount",
+	"period",
+	"status",
+	"next_charge_at",
+	"next_attempt_at",
+	"failed_attempts",
+	"grace_until",
+	"created_at",
+}
+
+var subscriptionChargeColumns = []string{
+	"id",
+	"subscription_id",
+	"period_start",
+	"amount",
+	"status",
+	"attempt",
+	"COALESCE(error, '')",
+	"created_at",
+}
+
+// chargeableStatuses are the statuses of subscriptions which are charged by the scheduler
+var chargeableStatuses = []string{entity.SubscriptionStatusActive, entity.SubscriptionStatusPastDue}
+
+type SubscriptionRepo struct {
+	*postgres.Postgres
+}
+
+func NewSubscriptionRepo(pg *postgres.Postgres) *SubscriptionRepo {
+	return &SubscriptionRepo{pg}
+}
+
+func (r *SubscriptionRepo) CreateSubscription(ctx context.Context, subscription entity.Subscription) (int, error) {
+	sql, args, _ := r.Builder.
+		Insert("subscriptions").
+		Columns("account_id", "product_id", "amount", "period", "next_charge_at", "next_attempt_at").
+		Values(
+			subscription.AccountId,
+			subscription.ProductId,
+			subscription.Amount,
+			subscription.Period,
+			subscription.NextChargeAt,
+			subscription.NextChargeAt,
+		).
+		Suffix("RETURNING id").
+		ToSql()
+
+	var id int
+	err := r.Pool.QueryRow(ctx, sql, args...).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("SubscriptionRepo.CreateSubscription - r.Pool.QueryRow: %v", err)
+	}
+
+	return id, nil
+}
+
+func (r *SubscriptionRepo) GetSubscriptionById(ctx context.Context, id int) (entity.Subscription, error) {
+	sql, args, _ := r.Builder.
+		Select(subscriptionColumns...).
+		From("subscriptions").
+		Where("id = ?", id).
+		ToSql()
+
+	subscription, err := scanSubscription(r.Pool.QueryRow(ctx, sql, args...))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return entity.Subscription{}, repoerrs.ErrNotFound
+		}
+		return entity.Subscription{}, fmt.Errorf("SubscriptionRepo.GetSubscriptionById - r.Pool.QueryRow: %v", err)
+	}
+
+	return subscription, nil
+}
+
+func (r *SubscriptionRepo) GetSubscriptionsByAccountId(ctx context.Context, accountId int) ([]entity.Subscription, error) {
+	sql, args, _ := r.Builder.
+		Select(subscriptionColumns...).
+		From("subscriptions").
+		Where("account_id = ?", accountId).
+		OrderBy("id").
+		ToSql()
+
+	rows, err := r.Pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("SubscriptionRepo.GetSubscriptionsByAccountId - r.Pool.Query: %v", err)
+	}
+	defer rows.Close()
+
+	var subscriptions []entity.Subscription
+	for rows.Next() {
+		subscription, err := scanSubscription(rows)
+		if err != nil {
+			return nil, fmt.Errorf("SubscriptionRepo.GetSubscriptionsByAccountId - rows.Scan: %v", err)
+		}
+		subscriptions = append(subscriptions, subscription)
+	}
+
+	return subscriptions, nil
+}
+
+// UpdateSubscriptionStatus changes the status only if the current one is among from.
+// Activation starts a new period if the scheduled one has already passed and forgets previous failures.
+// ErrNotFound is returned if there is no such subscription in one of from statuses
+func (r *SubscriptionRepo) UpdateSubscriptionStatus(ctx context.Context, id int, from []string, to string) error {
+	builder := r.Builder.
+		Update("subscriptions").
+		Set("status", to).
+		Where(squirrel.Eq{"id": id, "status": from})
+
+	if to == entity.SubscriptionStatusActive {
+		builder = builder.
+			Set("next_charge_at", squirrel.Expr("greatest(next_charge_at, now())")).
+			Set("next_attempt_at", squirrel.Expr("greatest(next_charge_at, now())")).
+			Set("failed_attempts", 0).
+			Set("grace_until", nil)
+	}
+
+	sql, args, _ := builder.ToSql()
+
+	tag, err := r.Pool.Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("SubscriptionRepo.UpdateSubscriptionStatus - r.Pool.Exec: %v", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return repoerrs.ErrNotFound
+	}
+
+	return nil
+}
+
+// GetDueSubscriptionIds returns ids of subscriptions which have to be charged now
+func (r *SubscriptionRepo) GetDueSubscriptionIds(ctx context.Context, limit int) ([]int, error) {
+	sql, args, _ := r.Builder.
+		Select("id").
+		From("subscriptions").
+		Where(squirrel.Eq{"status": chargeableStatuses}).
+		Where("next_attempt_at <= now()").
+		OrderBy("next_attempt_at").
+		Limit(uint64(limit)).
+		ToSql()
+
+	rows, err := r.Pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("SubscriptionRepo.GetDueSubscriptionIds - r.Pool.Query: %v", err)
+	}
+	defer rows.Close()
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		err = rows.Scan(&id)
+		if err != nil {
+			return nil, fmt.Errorf("SubscriptionRepo.GetDueSubscriptionIds - rows.Scan: %v", err)
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, nil
+}
+
+// ChargeSubscription reserves and immediately recognizes as revenue the amount for the current period
+// of a due subscription, like a captured order: the reservation limits are checked, the fee is collected
+// and the cashback is granted. The subscription row stays locked during the charge and the charged period
+// is recorded under a unique key, so the same period is never charged twice.
+// If rejectReason is not empty the charge is not attempted and is recorded as failed with that reason.
+// A failed charge is recorded and retried after retryInterval until the grace period expires,
+// then the subscription is suspended. ErrNotFound is returned if the subscription is not due anymore
+func (r *SubscriptionRepo) ChargeSubscription(ctx context.Context, id int, rejectReason string, fee entity.Fee, limits []entity.LimitRule, spendingOrder []string, retryInterval, gracePeriod time.Duration) (entity.SubscriptionCharge, error) {
+	tx, err := r.Pool.Begin(ctx)
+	if err != nil {
+		return entity.SubscriptionCharge{}, fmt.Errorf("SubscriptionRepo.ChargeSubscription - r.Pool.Begin: %v", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	// skip locked rows, they are being charged by another instance right now
+	sql, args, _ := r.Builder.
+		Select(append(subscriptionColumns, "now()")...).
+		From("subscriptions").
+		Where(squirrel.Eq{"id": id, "status": chargeableStatuses}).
+		Where("next_attempt_at <= now()").
+		Suffix("FOR UPDATE SKIP LOCKED").
+		ToSql()
+
+	var s entity.Subscription
+	var now time.Time
+	err = tx.QueryRow(ctx, sql, args...).Scan(
+		&s.Id,
+		&s.AccountId,
+		&s.ProductId,
+		&s.Amount,
+		&s.Period,
+		&s.Status,
+		&s.NextChargeAt,
+		&s.NextAttemptAt,
+		&s.FailedAttempts,
+		&s.GraceUntil,
+		&s.CreatedAt,
+		&now,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return entity.SubscriptionCharge{}, repoerrs.ErrNotFound
+		}
+		return entity.SubscriptionCharge{}, fmt.Errorf("SubscriptionRepo.ChargeSubscription - tx.QueryRow: %v", err)
+	}
+
+	charge := entity.SubscriptionCharge{
+		SubscriptionId: s.Id,
+		PeriodStart:    s.NextChargeAt,
+		Amount:         s.Amount,
+		Status:         entity.SubscriptionChargeSucceeded,
+		Attempt:        s.FailedAttempts + 1,
+	}
+
+	var funds []entity.ReservationFund
+	var chargeErr error
+	if rejectReason != "" {
+		chargeErr = errors.New(rejectReason)
+	} else {
+		funds, chargeErr = r.prepareCharge(ctx, tx, s, fee, limits, spendingOrder)
+		if chargeErr != nil && !errors.Is(chargeErr, repoerrs.ErrNotEnoughBalance) && !errors.Is(chargeErr, repoerrs.ErrAccountFrozen) &&
+			!errors.Is(chargeErr, repoerrs.ErrAccountClosed) && !errors.Is(chargeErr, repoerrs.ErrLimitExceeded) {
+			return entity.SubscriptionCharge{}, fmt.Errorf("SubscriptionRepo.ChargeSubscription - r.prepareCharge: %v", chargeErr)
+		}
+	}
+
+	if chargeErr != nil {
+		charge.Status = entity.SubscriptionChargeFailed
+		charge.Error = chargeErr.Error()
+
+		graceUntil := s.NextChargeAt.Add(gracePeriod)
+		if s.GraceUntil != nil {
+			graceUntil = *s.GraceUntil
+		}
+
+		status := entity.SubscriptionStatusPastDue
+		if !now.Add(retryInterval).Before(graceUntil) {
+			status = entity.SubscriptionStatusSuspended
+		}
+
+		sql, args, _ = r.Builder.
+			Update("subscriptions").
+			Set("status", status).
+			Set("failed_attempts", charge.Attempt).
+			Set("grace_until", graceUntil).
+			Set("next_attempt_at", now.Add(retryInterval)).
+			Where("id = ?", s.Id).
+			ToSql()
+	} else {
+		for _, fund := range funds {
+			err = debitWallet(ctx, tx, r.Builder, s.AccountId, fund.WalletType, fund.Amount)
+			if err != nil {
+				return entity.SubscriptionCharge{}, fmt.Errorf("SubscriptionRepo.ChargeSubscription - debitWallet: %v", err)
+			}
+
+			for _, operationType := range []string{entity.OperationTypeReservation, entity.OperationTypeRevenue} {
+				err = insertOperation(ctx, tx, r.Builder, entity.Operation{
+					AccountId:     s.AccountId,
+					Amount:        fund.Amount,
+					OperationType: operationType,
+					WalletType:    fund.WalletType,
+					ProductId:     &s.ProductId,
+					Description:   fmt.Sprintf("subscription #%d", s.Id),
+				})
+				if err != nil {
+					return entity.SubscriptionCharge{}, fmt.Errorf("SubscriptionRepo.ChargeSubscription - insertOperation: %v", err)
+				}
+			}
+		}
+
+		err = collectFee(ctx, tx, r.Builder, s.AccountId, fee, entity.OperationTypeReservation)
+		if err != nil {
+			return entity.SubscriptionCharge{}, fmt.Errorf("SubscriptionRepo.ChargeSubscription - collectFee: %v", err)
+		}
+
+		err = grantCashback(ctx, tx, r.Builder, s.AccountId, s.ProductId, nil, funds)
+		if err != nil {
+			return entity.SubscriptionCharge{}, fmt.Errorf("SubscriptionRepo.ChargeSubscription - grantCashback: %v", err)
+		}
+
+		nextChargeAt := entity.NextPeriodStart(s.NextChargeAt, s.Period)
+		sql, args, _ = r.Builder.
+			Update("subscriptions").
+			Set("status", entity.SubscriptionStatusActive).
+			Set("failed_attempts", 0).
+			Set("grace_until", nil).
+			Set("next_charge_at", nextChargeAt).
+			Set("next_attempt_at", nextChargeAt).
+			Where("id = ?", s.Id).
+			ToSql()
+	}
+
+	_, err = tx.Exec(ctx, sql, args...)
+	if err != nil {
+		return entity.SubscriptionCharge{}, fmt.Errorf("SubscriptionRepo.ChargeSubscription - tx.Exec: %v", err)
+	}
+
+	sql, args, _ = r.Builder.
+		Insert("subscription_charges").
+		Columns("subscription_id", "period_start", "amount", "status", "attempt", "error").
+		Values(charge.SubscriptionId, charge.PeriodStart, charge.Amount, charge.Status, charge.Attempt, charge.Error).
+		Suffix("RETURNING id, created_at").
+		ToSql()
+
+	err = tx.QueryRow(ctx, sql, args...).Scan(&charge.Id, &charge.CreatedAt)
+	if err != nil {
+		return entity.SubscriptionCharge{}, fmt.Errorf("SubscriptionRepo.ChargeSubscription - tx.QueryRow: %v", err)
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return entity.SubscriptionCharge{}, fmt.Errorf("SubscriptionRepo.ChargeSubscription - tx.Commit: %v", err)
+	}
+
+	return charge, nil
+}
+
+// prepareCharge checks that the subscription amount can be taken from the account and returns
+// the wallets to take it from. Nothing is changed, so the charge may still be recorded as failed
+func (r *SubscriptionRepo) prepareCharge(ctx context.Context, tx pgx.Tx, s entity.Subscription, fee entity.Fee, limits []entity.LimitRule, spendingOrder []string) ([]entity.ReservationFund, error) {
+	status, err := lockAccount(ctx, tx, r.Builder, s.AccountId)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = checkAccountStatus(status, canDebit...); err != nil {
+		return nil, err
+	}
+
+	err = checkWindowLimits(ctx, tx, r.Builder, s.AccountId, entity.OperationTypeReservation, s.Amount, limits)
+	if err != nil {
+		return nil, err
+	}
+
+	balances, err := walletBalances(ctx, tx, r.Builder, s.AccountId)
+	if err != nil {
+		return nil, err
+	}
+
+	// the fee can be paid from the main wallet only, so it is set aside before the amount is split
+	balances[entity.WalletTypeMain] -= fee.Amount
+	if balances[entity.WalletTypeMain] < 0 {
+		return nil, repoerrs.ErrNotEnoughBalance
+	}
+
+	return splitByWallets(balances, spendingOrder, s.Amount)
+}
+
+func (r *SubscriptionRepo) GetSubscriptionCharges(ctx context.Context, subscriptionId int) ([]entity.SubscriptionCharge, error) {
+	sql, args, _ := r.Builder.
+		Select(subscriptionChargeColumns...).
+		From("subscription_charges").
+		Where("subscription_id = ?", subscriptionId).
+		OrderBy("id").
+		ToSql()
+
+	rows, err := r.Pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("SubscriptionRepo.GetSubscriptionCharges - r.Pool.Query: %v", err)
+	}
+	defer rows.Close()
+
+	var charges []entity.SubscriptionCharge
+	for rows.Next() {
+		var charge entity.SubscriptionCharge
+		err = rows.Scan(
+			&charge.Id,
+			&charge.SubscriptionId,
+			&charge.PeriodStart,
+			&charge.Amount,
+			&charge.Status,
+			&charge.Attempt,
+			&charge.Error,
+			&charge.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("SubscriptionRepo.GetSubscriptionCharges - rows.Scan: %v", err)
+		}
+		charges = append(charges, charge)
+	}
+
+	return charges, nil
+}
+
+func scanSubscription(row pgx.Row) (entity.Subscription, error) {
+	var s entity.Subscription
+	err := row.Scan(
+		&s.Id,
+		&s.AccountId,
+		&s.ProductId,
+		&s.Amount,
+		&s.Period,
+		&s.Status,
+		&s.NextChargeAt,
+		&s.NextAttemptAt,
+		&s.FailedAttempts,
+		&s.GraceUntil,
+		&s.CreatedAt,
+	)
+	return s, err
+}
@@ -44,7 +44,7 @@ func (r *UserRepo) CreateUser(ctx context.Context, user entity.User) (int, error
 
 func (r *UserRepo) GetUserByUsernameAndPassword(ctx context.Context, username, password string) (entity.User, error) {
 	sql, args, _ := r.Builder.
-		Select("id, username, password, created_at").
+		Select("id, username, password, created_at, role").
 		From("users").
 		Where("username = ? AND password = ?", username, password).
 		ToSql()
@@ -55,6 +55,7 @@ func (r *UserRepo) GetUserByUsernameAndPassword(ctx context.Context, username, p
 		&user.Username,
 		&user.Password,
 		&user.CreatedAt,
+		&user.Role,
 	)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
@@ -68,7 +69,7 @@ func (r *UserRepo) GetUserByUsernameAndPassword(ctx context.Context, username, p
 
 func (r *UserRepo) GetUserById(ctx context.Context, id int) (entity.User, error) {
 	sql, args, _ := r.Builder.
-		Select("id, username, password, created_at").
+		Select("id, username, password, created_at, role").
 		From("users").
 		Where("id = ?", id).
 		ToSql()
@@ -79,6 +80,7 @@ func (r *UserRepo) GetUserById(ctx context.Context, id int) (entity.User, error)
 		&user.Username,
 		&user.Password,
 		&user.CreatedAt,
+		&user.Role,
 	)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
@@ -92,7 +94,7 @@ func (r *UserRepo) GetUserById(ctx context.Context, id int) (entity.User, error)
 
 func (r *UserRepo) GetUserByUsername(ctx context.Context, username string) (entity.User, error) {
 	sql, args, _ := r.Builder.
-		Select("id, username, password, created_at").
+		Select("id, username, password, created_at, role").
 		From("users").
 		Where("username = ?", username).
 		ToSql()
@@ -103,6 +105,7 @@ func (r *UserRepo) GetUserByUsername(ctx context.Context, username string) (enti
 		&user.Username,
 		&user.Password,
 		&user.CreatedAt,
+		&user.Role,
 	)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
@@ -113,3 +116,22 @@ func (r *UserRepo) GetUserByUsername(ctx context.Context, username string) (enti
 
 	return user, nil
 }
+
+func (r *UserRepo) SetUserRole(ctx context.Context, username, role string) error {
+	sql, args, _ := r.Builder.
+		Update("users").
+		Set("role", role).
+		Where("username = ?", username).
+		ToSql()
+
+	tag, err := r.Pool.Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("UserRepo.SetUserRole - r.Pool.Exec: %v", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return repoerrs.ErrNotFound
+	}
+
+	return nil
+}
@@ -2,6 +2,7 @@ package pgdb
 
 import (
 	"account-management-service/internal/entity"
+	"account-management-service/internal/repo/repoerrs"
 	"account-management-service/pkg/postgres"
 	"context"
 	"errors"
@@ -137,10 +138,10 @@ func TestUserRepo_GetUserByUsernameAndPassword(t *testing.T) {
 				password: "Qwerty1!",
 			},
 			mockBehavior: func(m pgxmock.PgxPoolIface, args args) {
-				rows := pgxmock.NewRows([]string{"id", "username", "password", "created_at"}).
-					AddRow(1, args.username, args.password, time.UnixMilli(123456))
+				rows := pgxmock.NewRows([]string{"id", "username", "password", "created_at", "role"}).
+					AddRow(1, args.username, args.password, time.UnixMilli(123456), entity.UserRoleUser)
 
-				m.ExpectQuery("SELECT id, username, password, created_at FROM users").
+				m.ExpectQuery("SELECT id, username, password, created_at, role FROM users").
 					WithArgs(args.username, args.password).
 					WillReturnRows(rows)
 			},
@@ -149,6 +150,7 @@ func TestUserRepo_GetUserByUsernameAndPassword(t *testing.T) {
 				Username:  "test_user",
 				Password:  "Qwerty1!",
 				CreatedAt: time.UnixMilli(123456),
+				Role:      entity.UserRoleUser,
 			},
 			wantErr: false,
 		},
@@ -160,7 +162,7 @@ func TestUserRepo_GetUserByUsernameAndPassword(t *testing.T) {
 				password: "Qwerty1!",
 			},
 			mockBehavior: func(m pgxmock.PgxPoolIface, args args) {
-				m.ExpectQuery("SELECT id, username, password, created_at FROM users").
+				m.ExpectQuery("SELECT id, username, password, created_at, role FROM users").
 					WithArgs(args.username, args.password).
 					WillReturnError(pgx.ErrNoRows)
 			},
@@ -175,7 +177,7 @@ func TestUserRepo_GetUserByUsernameAndPassword(t *testing.T) {
 				password: "Qwerty1!",
 			},
 			mockBehavior: func(m pgxmock.PgxPoolIface, args args) {
-				m.ExpectQuery("SELECT id, username, password, created_at FROM users").
+				m.ExpectQuery("SELECT id, username, password, created_at, role FROM users").
 					WithArgs(args.username, args.password).
 					WillReturnError(errors.New("some error"))
 			},
@@ -232,10 +234,10 @@ func TestUserRepo_GetUserById(t *testing.T) {
 				id:  1,
 			},
 			mockBehavior: func(m pgxmock.PgxPoolIface, args args) {
-				rows := pgxmock.NewRows([]string{"id", "username", "password", "created_at"}).
-					AddRow(args.id, "test_user", "Qwerty1!", time.UnixMilli(123456))
+				rows := pgxmock.NewRows([]string{"id", "username", "password", "created_at", "role"}).
+					AddRow(args.id, "test_user", "Qwerty1!", time.UnixMilli(123456), entity.UserRoleUser)
 
-				m.ExpectQuery("SELECT id, username, password, created_at FROM users").
+				m.ExpectQuery("SELECT id, username, password, created_at, role FROM users").
 					WithArgs(args.id).
 					WillReturnRows(rows)
 			},
@@ -244,6 +246,7 @@ func TestUserRepo_GetUserById(t *testing.T) {
 				Username:  "test_user",
 				Password:  "Qwerty1!",
 				CreatedAt: time.UnixMilli(123456),
+				Role:      entity.UserRoleUser,
 			},
 			wantErr: false,
 		},
@@ -254,7 +257,7 @@ func TestUserRepo_GetUserById(t *testing.T) {
 				id:  1,
 			},
 			mockBehavior: func(m pgxmock.PgxPoolIface, args args) {
-				m.ExpectQuery("SELECT id, username, password, created_at FROM users").
+				m.ExpectQuery("SELECT id, username, password, created_at, role FROM users").
 					WithArgs(args.id).
 					WillReturnError(pgx.ErrNoRows)
 			},
@@ -268,7 +271,7 @@ func TestUserRepo_GetUserById(t *testing.T) {
 				id:  1,
 			},
 			mockBehavior: func(m pgxmock.PgxPoolIface, args args) {
-				m.ExpectQuery("SELECT id, username, password, created_at FROM users").
+				m.ExpectQuery("SELECT id, username, password, created_at, role FROM users").
 					WithArgs(args.id).
 					WillReturnError(errors.New("some error"))
 			},
@@ -325,10 +328,10 @@ func TestUserRepo_GetUserByUsername(t *testing.T) {
 				username: "test_user",
 			},
 			mockBehavior: func(m pgxmock.PgxPoolIface, args args) {
-				rows := pgxmock.NewRows([]string{"id", "username", "password", "created_at"}).
-					AddRow(1, args.username, "Qwerty1!", time.UnixMilli(123456))
+				rows := pgxmock.NewRows([]string{"id", "username", "password", "created_at", "role"}).
+					AddRow(1, args.username, "Qwerty1!", time.UnixMilli(123456), entity.UserRoleUser)
 
-				m.ExpectQuery("SELECT id, username, password, created_at FROM users").
+				m.ExpectQuery("SELECT id, username, password, created_at, role FROM users").
 					WithArgs(args.username).
 					WillReturnRows(rows)
 			},
@@ -337,6 +340,7 @@ func TestUserRepo_GetUserByUsername(t *testing.T) {
 				Username:  "test_user",
 				Password:  "Qwerty1!",
 				CreatedAt: time.UnixMilli(123456),
+				Role:      entity.UserRoleUser,
 			},
 			wantErr: false,
 		},
@@ -347,7 +351,7 @@ func TestUserRepo_GetUserByUsername(t *testing.T) {
 				username: "test_user",
 			},
 			mockBehavior: func(m pgxmock.PgxPoolIface, args args) {
-				m.ExpectQuery("SELECT id, username, password, created_at FROM users").
+				m.ExpectQuery("SELECT id, username, password, created_at, role FROM users").
 					WithArgs(args.username).
 					WillReturnError(pgx.ErrNoRows)
 			},
@@ -361,7 +365,7 @@ func TestUserRepo_GetUserByUsername(t *testing.T) {
 				username: "test_user",
 			},
 			mockBehavior: func(m pgxmock.PgxPoolIface, args args) {
-				m.ExpectQuery("SELECT id, username, password, created_at FROM users").
+				m.ExpectQuery("SELECT id, username, password, created_at, role FROM users").
 					WithArgs(args.username).
 					WillReturnError(errors.New("some error"))
 			},
@@ -395,3 +399,73 @@ func TestUserRepo_GetUserByUsername(t *testing.T) {
 		})
 	}
 }
+
+func TestUserRepo_SetUserRole(t *testing.T) {
+	type args struct {
+		ctx      context.Context
+		username string
+		role     string
+	}
+
+	type MockBehavior func(m pgxmock.PgxPoolIface, args args)
+
+	testCases := []struct {
+		name         string
+		args         args
+		mockBehavior MockBehavior
+		wantErr      error
+	}{
+		{
+			name: "OK",
+			args: args{
+				ctx:      context.Background(),
+				username: "test_user",
+				role:     entity.UserRoleAdmin,
+			},
+			mockBehavior: func(m pgxmock.PgxPoolIface, args args) {
+				m.ExpectExec("UPDATE users").
+					WithArgs(args.role, args.username).
+					WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+			},
+			wantErr: nil,
+		},
+		{
+			name: "user not found",
+			args: args{
+				ctx:      context.Background(),
+				username: "test_user",
+				role:     entity.UserRoleAdmin,
+			},
+			mockBehavior: func(m pgxmock.PgxPoolIface, args args) {
+				m.ExpectExec("UPDATE users").
+					WithArgs(args.role, args.username).
+					WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+			},
+			wantErr: repoerrs.ErrNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			poolMock, _ := pgxmock.NewPool()
+			defer poolMock.Close()
+			tc.mockBehavior(poolMock, tc.args)
+
+			postgresMock := &postgres.Postgres{
+				Builder: squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar),
+				Pool:    poolMock,
+			}
+			userRepoMock := NewUserRepo(postgresMock)
+
+			err := userRepoMock.SetUserRole(tc.args.ctx, tc.args.username, tc.args.role)
+			if tc.wantErr != nil {
+				assert.ErrorIs(t, err, tc.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			err = poolMock.ExpectationsWereMet()
+			assert.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,247 @@
+package pgdb
+
+import (
+	"account-management-service/internal/entity"
+	"account-management-service/internal/repo/repoerrs"
+	"account-management-service/pkg/postgres"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+)
+
+var verificationPolicyColumns = []string{
+	"level",
+	"operation_type",
+	"max_amount",
+	"max_total",
+	"window_seconds",
+	"updated_at",
+}
+
+type VerificationRepo struct {
+	*postgres.Postgres
+}
+
+func NewVerificationRepo(pg *postgres.Postgres) *VerificationRepo {
+	return &VerificationRepo{pg}
+}
+
+// SetVerificationLevel changes the account verification level and records the change
+func (r *VerificationRepo) SetVerificationLevel(ctx context.Context, change entity.VerificationChange) error {
+	tx, err := r.Pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("VerificationRepo.SetVerificationLevel - r.Pool.Begin: %v", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	sql, args, _ := r.Builder.
+		Select("verification_level").
+		From("accounts").
+		Where("id = ?", change.AccountId).
+		Suffix("FOR UPDATE").
+		ToSql()
+
+	err = tx.QueryRow(ctx, sql, args...).Scan(&change.OldLevel)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return repoerrs.ErrNotFound
+		}
+		return fmt.Errorf("VerificationRepo.SetVerificationLevel - tx.QueryRow: %v", err)
+	}
+
+	sql, args, _ = r.Builder.
+		Update("accounts").
+		Set("verification_level", change.NewLevel).
+		Where("id = ?", change.AccountId).
+		ToSql()
+
+	_, err = tx.Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("VerificationRepo.SetVerificationLevel - tx.Exec: %v", err)
+	}
+
+	sql, args, _ = r.Builder.
+		Insert("verification_changes").
+		Columns("account_id", "old_level", "new_level", "reference", "changed_by").
+		Values(change.AccountId, change.OldLevel, change.NewLevel, change.Reference, change.ChangedBy).
+		ToSql()
+
+	_, err = tx.Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("VerificationRepo.SetVerificationLevel - tx.Exec: %v", err)
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return fmt.Errorf("VerificationRepo.SetVerificationLevel - tx.Commit: %v", err)
+	}
+
+	return nil
+}
+
+func (r *VerificationRepo) GetVerificationChanges(ctx context.Context, accountId int) ([]entity.VerificationChange, error) {
+	sql, args, _ := r.Builder.
+		Select("id", "account_id", "old_level", "new_level", "COALESCE(reference, '')", "changed_by", "created_at").
+		From("verification_changes").
+		Where("account_id = ?", accountId).
+		OrderBy("created_at DESC").
+		ToSql()
+
+	rows, err := r.Pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("VerificationRepo.GetVerificationChanges - r.Pool.Query: %v", err)
+	}
+	defer rows.Close()
+
+	var changes []entity.VerificationChange
+	for rows.Next() {
+		var change entity.VerificationChange
+		err = rows.Scan(
+			&change.Id,
+			&change.AccountId,
+			&change.OldLevel,
+			&change.NewLevel,
+			&change.Reference,
+			&change.ChangedBy,
+			&change.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("VerificationRepo.GetVerificationChanges - rows.Scan: %v", err)
+		}
+		changes = append(changes, change)
+	}
+
+	return changes, nil
+}
+
+// SetVerificationPolicy creates the policy or replaces the limits of the existing one
+func (r *VerificationRepo) SetVerificationPolicy(ctx context.Context, policy entity.VerificationPolicy) error {
+	sql, args, _ := r.Builder.
+		Insert("verification_policies").
+		Columns("level", "operation_type", "max_amount", "max_total", "window_seconds").
+		Values(policy.Level, policy.OperationType, policy.MaxAmount, policy.MaxTotal, policy.WindowSeconds).
+		Suffix("ON CONFLICT (level, operation_type) DO UPDATE SET max_amount = excluded.max_amount, " +
+			"max_total = excluded.max_total, window_seconds = excluded.window_seconds, updated_at = now()").
+		ToSql()
+
+	_, err := r.Pool.Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("VerificationRepo.SetVerificationPolicy - r.Pool.Exec: %v", err)
+	}
+
+	return nil
+}
+
+func (r *VerificationRepo) DeleteVerificationPolicy(ctx context.Context, level, operationType string) error {
+	sql, args, _ := r.Builder.
+		Delete("verification_policies").
+		Where("level = ? and operation_type = ?", level, operationType).
+		ToSql()
+
+	tag, err := r.Pool.Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("VerificationRepo.DeleteVerificationPolicy - r.Pool.Exec: %v", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return repoerrs.ErrNotFound
+	}
+
+	return nil
+}
+
+func (r *VerificationRepo) GetAllVerificationPolicies(ctx context.Context) ([]entity.VerificationPolicy, error) {
+	sql, args, _ := r.Builder.
+		Select(verificationPolicyColumns...).
+		From("verification_policies").
+		OrderBy("operation_type", "level").
+		ToSql()
+
+	rows, err := r.Pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("VerificationRepo.GetAllVerificationPolicies - r.Pool.Query: %v", err)
+	}
+	defer rows.Close()
+
+	policies, err := scanVerificationPolicies(rows)
+	if err != nil {
+		return nil, fmt.Errorf("VerificationRepo.GetAllVerificationPolicies - scanVerificationPolicies: %v", err)
+	}
+
+	return policies, nil
+}
+
+// GetVerificationPoliciesForOperation returns the policies of every level for the operation type
+func (r *VerificationRepo) GetVerificationPoliciesForOperation(ctx context.Context, operationType string) ([]entity.VerificationPolicy, error) {
+	sql, args, _ := r.Builder.
+		Select(verificationPolicyColumns...).
+		From("verification_policies").
+		Where("operation_type = ?", operationType).
+		ToSql()
+
+	rows, err := r.Pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("VerificationRepo.GetVerificationPoliciesForOperation - r.Pool.Query: %v", err)
+	}
+	defer rows.Close()
+
+	policies, err := scanVerificationPolicies(rows)
+	if err != nil {
+		return nil, fmt.Errorf("VerificationRepo.GetVerificationPoliciesForOperation - scanVerificationPolicies: %v", err)
+	}
+
+	return policies, nil
+}
+
+// checkVerification checks the outgoing operation against the verification policies limiting the total
+// within a window and returns VerificationRequiredError with the lowest level allowing it. The account
+// must be locked by the caller, so concurrent operations of the account cannot all fit into the window
+func checkVerification(ctx context.Context, tx pgx.Tx, builder squirrel.StatementBuilderType, accountId int, operationType string, amount int, check entity.VerificationCheck) error {
+	if len(check.Policies) == 0 {
+		return nil
+	}
+
+	// the totals are shared by the levels with the same window
+	totals := make(map[int]int)
+	for _, policy := range check.Policies {
+		if _, ok := totals[policy.WindowSeconds]; ok || policy.MaxTotal == 0 {
+			continue
+		}
+
+		_, total, err := windowTotals(ctx, tx, builder, accountId, operationType, policy.WindowSeconds)
+		if err != nil {
+			return err
+		}
+		totals[policy.WindowSeconds] = total
+	}
+
+	level, ok := check.RequiredLevel(amount, totals)
+	if ok && level == check.Level {
+		return nil
+	}
+
+	return &repoerrs.VerificationRequiredError{Level: level, OperationType: operationType, Amount: amount}
+}
+
+func scanVerificationPolicies(rows pgx.Rows) ([]entity.VerificationPolicy, error) {
+	var policies []entity.VerificationPolicy
+	for rows.Next() {
+		var policy entity.VerificationPolicy
+		err := rows.Scan(
+			&policy.Level,
+			&policy.OperationType,
+			&policy.MaxAmount,
+			&policy.MaxTotal,
+			&policy.WindowSeconds,
+			&policy.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		policies = append(policies, policy)
+	}
+
+	return policies, nil
+}
@@ -0,0 +1,267 @@
+package pgdb
+
+import (
+	"account-management-service/internal/entity"
+	"account-management-service/internal/repo/repoerrs"
+	"account-management-service/pkg/postgres"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+var voucherCampaignColumns = []string{
+	"id",
+	"name",
+	"amount",
+	"wallet_type",
+	"max_uses",
+	"expires_at",
+	"created_at",
+}
+
+type VoucherRepo struct {
+	*postgres.Postgres
+}
+
+func NewVoucherRepo(pg *postgres.Postgres) *VoucherRepo {
+	return &VoucherRepo{pg}
+}
+
+// CreateVoucherCampaign creates the campaign together with all its codes, the codes are written with COPY.
+// ErrAlreadyExists is returned if any of the codes is already taken
+func (r *VoucherRepo) CreateVoucherCampaign(ctx context.Context, campaign entity.VoucherCampaign, codes []string) (int, error) {
+	tx, err := r.Pool.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("VoucherRepo.CreateVoucherCampaign - r.Pool.Begin: %v", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	sql, args, _ := r.Builder.
+		Insert("voucher_campaigns").
+		Columns("name", "amount", "wallet_type", "max_uses", "expires_at").
+		Values(campaign.Name, campaign.Amount, campaign.Wallet, campaign.MaxUses, campaign.ExpiresAt).
+		Suffix("RETURNING id").
+		ToSql()
+
+	var id int
+	err = tx.QueryRow(ctx, sql, args...).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("VoucherRepo.CreateVoucherCampaign - tx.QueryRow: %v", err)
+	}
+
+	rows := make([][]interface{}, 0, len(codes))
+	for _, code := range codes {
+		rows = append(rows, []interface{}{id, code})
+	}
+
+	_, err = tx.CopyFrom(ctx, pgx.Identifier{"vouchers"}, []string{"campaign_id", "code"}, pgx.CopyFromRows(rows))
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if ok := errors.As(err, &pgErr); ok {
+			if pgErr.Code == "23505" {
+				return 0, repoerrs.ErrAlreadyExists
+			}
+		}
+		return 0, fmt.Errorf("VoucherRepo.CreateVoucherCampaign - tx.CopyFrom: %v", err)
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("VoucherRepo.CreateVoucherCampaign - tx.Commit: %v", err)
+	}
+
+	return id, nil
+}
+
+func (r *VoucherRepo) GetVoucherCampaignById(ctx context.Context, id int) (entity.VoucherCampaign, error) {
+	sql, args, _ := r.Builder.
+		Select(voucherCampaignColumns...).
+		From("voucher_campaigns").
+		Where("id = ?", id).
+		ToSql()
+
+	var campaign entity.VoucherCampaign
+	err := r.Pool.QueryRow(ctx, sql, args...).Scan(
+		&campaign.Id,
+		&campaign.Name,
+		&campaign.Amount,
+		&campaign.Wallet,
+		&campaign.MaxUses,
+		&campaign.ExpiresAt,
+		&campaign.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return entity.VoucherCampaign{}, repoerrs.ErrNotFound
+		}
+		return entity.VoucherCampaign{}, fmt.Errorf("VoucherRepo.GetVoucherCampaignById - r.Pool.QueryRow: %v", err)
+	}
+
+	return campaign, nil
+}
+
+// RedeemVoucher credits the voucher amount to the account wallet. The usage counter is incremented
+// with a conditional update, so concurrent redemptions of the last use cannot both succeed.
+// ErrNotFound is returned for unknown codes, ErrExpired for expired ones, ErrWrongStatus if the code is used up
+// and ErrAlreadyExists if the account has already redeemed the code. The account must exist
+func (r *VoucherRepo) RedeemVoucher(ctx context.Context, code string, accountId int) (entity.VoucherRedemption, error) {
+	tx, err := r.Pool.Begin(ctx)
+	if err != nil {
+		return entity.VoucherRedemption{}, fmt.Errorf("VoucherRepo.RedeemVoucher - r.Pool.Begin: %v", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	// squirrel cannot build UPDATE ... FROM, so the campaign conditions are checked with a subquery
+	sql, args, _ := r.Builder.
+		Update("vouchers").
+		Set("used_count", squirrel.Expr("used_count + 1")).
+		Where("code = ?", code).
+		Where("used_count < (select max_uses from voucher_campaigns c where c.id = campaign_id and c.expires_at > now())").
+		Suffix("RETURNING id, campaign_id").
+		ToSql()
+
+	var voucherId, campaignId int
+	err = tx.QueryRow(ctx, sql, args...).Scan(&voucherId, &campaignId)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return entity.VoucherRedemption{}, r.whyNotRedeemable(ctx, tx, code)
+		}
+		return entity.VoucherRedemption{}, fmt.Errorf("VoucherRepo.RedeemVoucher - tx.QueryRow: %v", err)
+	}
+
+	sql, args, _ = r.Builder.
+		Select("amount", "wallet_type").
+		From("voucher_campaigns").
+		Where("id = ?", campaignId).
+		ToSql()
+
+	var amount int
+	var walletType string
+	err = tx.QueryRow(ctx, sql, args...).Scan(&amount, &walletType)
+	if err != nil {
+		return entity.VoucherRedemption{}, fmt.Errorf("VoucherRepo.RedeemVoucher - tx.QueryRow: %v", err)
+	}
+
+	status, err := lockAccount(ctx, tx, r.Builder, accountId)
+	if err != nil {
+		return entity.VoucherRedemption{}, fmt.Errorf("VoucherRepo.RedeemVoucher - lockAccount: %v", err)
+	}
+
+	if err = checkAccountStatus(status, canCredit...); err != nil {
+		return entity.VoucherRedemption{}, err
+	}
+
+	redemption := entity.VoucherRedemption{
+		VoucherId: voucherId,
+		AccountId: accountId,
+		Amount:    amount,
+	}
+
+	sql, args, _ = r.Builder.
+		Insert("voucher_redemptions").
+		Columns("voucher_id", "account_id", "amount").
+		Values(voucherId, accountId, amount).
+		Suffix("RETURNING id, created_at").
+		ToSql()
+
+	err = tx.QueryRow(ctx, sql, args...).Scan(&redemption.Id, &redemption.CreatedAt)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if ok := errors.As(err, &pgErr); ok {
+			if pgErr.Code == "23505" {
+				return entity.VoucherRedemption{}, repoerrs.ErrAlreadyExists
+			}
+		}
+		return entity.VoucherRedemption{}, fmt.Errorf("VoucherRepo.RedeemVoucher - tx.QueryRow: %v", err)
+	}
+
+	err = creditWallet(ctx, tx, r.Builder, accountId, walletType, amount)
+	if err != nil {
+		return entity.VoucherRedemption{}, fmt.Errorf("VoucherRepo.RedeemVoucher - creditWallet: %v", err)
+	}
+
+	err = insertOperation(ctx, tx, r.Builder, entity.Operation{
+		AccountId:     accountId,
+		Amount:        amount,
+		OperationType: entity.OperationTypeDeposit,
+		WalletType:    walletType,
+		Description:   fmt.Sprintf("voucher %s", code),
+	})
+	if err != nil {
+		return entity.VoucherRedemption{}, fmt.Errorf("VoucherRepo.RedeemVoucher - insertOperation: %v", err)
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return entity.VoucherRedemption{}, fmt.Errorf("VoucherRepo.RedeemVoucher - tx.Commit: %v", err)
+	}
+
+	return redemption, nil
+}
+
+// whyNotRedeemable tells an unknown code from an expired or used up one
+func (r *VoucherRepo) whyNotRedeemable(ctx context.Context, tx pgx.Tx, code string) error {
+	sql, args, _ := r.Builder.
+		Select("c.expires_at > now()").
+		From("vouchers").
+		InnerJoin("voucher_campaigns c on vouchers.campaign_id = c.id").
+		Where("vouchers.code = ?", code).
+		ToSql()
+
+	var active bool
+	err := tx.QueryRow(ctx, sql, args...).Scan(&active)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return repoerrs.ErrNotFound
+		}
+		return fmt.Errorf("VoucherRepo.RedeemVoucher - tx.QueryRow: %v", err)
+	}
+
+	if !active {
+		return repoerrs.ErrExpired
+	}
+	return repoerrs.ErrWrongStatus
+}
+
+func (r *VoucherRepo) GetVoucherCampaignReport(ctx context.Context, campaignId int) (entity.VoucherCampaignReport, error) {
+	campaign, err := r.GetVoucherCampaignById(ctx, campaignId)
+	if err != nil {
+		return entity.VoucherCampaignReport{}, err
+	}
+
+	sql, args, _ := r.Builder.
+		Select(
+			"count(*)",
+			"count(*) filter (where used_count > 0)",
+			"COALESCE(sum(used_count), 0)",
+		).
+		From("vouchers").
+		Where("campaign_id = ?", campaignId).
+		ToSql()
+
+	report := entity.VoucherCampaignReport{Campaign: campaign}
+	err = r.Pool.QueryRow(ctx, sql, args...).Scan(&report.IssuedCodes, &report.RedeemedCodes, &report.Redemptions)
+	if err != nil {
+		return entity.VoucherCampaignReport{}, fmt.Errorf("VoucherRepo.GetVoucherCampaignReport - r.Pool.QueryRow: %v", err)
+	}
+
+	sql, args, _ = r.Builder.
+		Select("COALESCE(sum(voucher_redemptions.amount), 0)").
+		From("voucher_redemptions").
+		InnerJoin("vouchers on voucher_redemptions.voucher_id = vouchers.id").
+		Where("vouchers.campaign_id = ?", campaignId).
+		ToSql()
+
+	err = r.Pool.QueryRow(ctx, sql, args...).Scan(&report.RedeemedAmount)
+	if err != nil {
+		return entity.VoucherCampaignReport{}, fmt.Errorf("VoucherRepo.GetVoucherCampaignReport - r.Pool.QueryRow: %v", err)
+	}
+
+	report.IssuedAmount = report.IssuedCodes * campaign.MaxUses * campaign.Amount
+	report.RemainingAmount = report.IssuedAmount - report.RedeemedAmount
+
+	return report, nil
+}
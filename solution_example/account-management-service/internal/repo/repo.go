@@ -5,6 +5,7 @@ import (
 	"account-management-service/internal/repo/pgdb"
 	"account-management-service/pkg/postgres"
 	"context"
+	"time"
 )
 
 type User interface {
@@ -12,14 +13,19 @@ type User interface {
 	GetUserByUsernameAndPassword(ctx context.Context, username, password string) (entity.User, error)
 	GetUserById(ctx context.Context, id int) (entity.User, error)
 	GetUserByUsername(ctx context.Context, username string) (entity.User, error)
+	SetUserRole(ctx context.Context, username, role string) error
 }
 
 type Account interface {
-	CreateAccount(ctx context.Context) (int, error)
+	CreateAccount(ctx context.Context, accountType string) (int, error)
 	GetAccountById(ctx context.Context, id int) (entity.Account, error)
-	Deposit(ctx context.Context, id, amount int) error
-	Withdraw(ctx context.Context, id, amount int) error
-	Transfer(ctx context.Context, from, to, amount int) error
+	GetWalletsByAccountId(ctx context.Context, accountId int) ([]entity.Wallet, error)
+	Deposit(ctx context.Context, id, amount int, walletType string) error
+	Withdraw(ctx context.Context, id, amount int, userId *int, fee entity.Fee, limits []entity.LimitRule, verification entity.VerificationCheck) error
+	Transfer(ctx context.Context, from, to, amount int, userId *int, fee entity.Fee, limits []entity.LimitRule, verification entity.VerificationCheck) error
+	ChangeAccountStatus(ctx context.Context, change entity.AccountStatusChange) error
+	CloseAccount(ctx context.Context, change entity.AccountStatusChange, payout bool) (int, error)
+	GetAccountStatusChanges(ctx context.Context, accountId int) ([]entity.AccountStatusChange, error)
 }
 
 type Product interface {
@@ -29,15 +35,155 @@ type Product interface {
 }
 
 type Reservation interface {
-	CreateReservation(ctx context.Context, reservation entity.Reservation) (int, error)
+	CreateReservation(ctx context.Context, reservation entity.Reservation, spendingOrder []string, fee entity.Fee, limits []entity.LimitRule) (int, error)
 	GetReservationById(ctx context.Context, id int) (entity.Reservation, error)
 	RefundReservationByOrderId(ctx context.Context, id int) error
+	RefundCapturedOrder(ctx context.Context, orderId int) error
 	RevenueReservationByOrderId(ctx context.Context, orderId int) error
 }
 
 type Operation interface {
-	GetAllRevenueOperationsGroupedByProduct(ctx context.Context, month, year int) ([]string, []int, error)
+	GetAllRevenueOperationsGroupedByProduct(ctx context.Context, from, to time.Time) ([]entity.ProductRevenue, error)
+	GetProductRevenueSeries(ctx context.Context, from, to time.Time, granularity string) ([]entity.PeriodProductRevenue, error)
+	StreamAccountProductRevenue(ctx context.Context, from, to time.Time, productIds, accountIds []int, fn func(entity.AccountProductRevenue) error) error
+	StreamOperations(ctx context.Context, from, to time.Time, accountId int, operationTypes []string, fn func(entity.ExportedOperation) error) error
 	OperationsPagination(ctx context.Context, accountId int, sortType string, offset int, limit int) ([]entity.Operation, []string, error)
+	GetFeeIncome(ctx context.Context, from, to time.Time) (int, error)
+	GetMemberSpending(ctx context.Context, accountId int) ([]entity.MemberSpending, error)
+}
+
+type Limit interface {
+	CreateLimitRule(ctx context.Context, rule entity.LimitRule) (int, error)
+	GetAllLimitRules(ctx context.Context) ([]entity.LimitRule, error)
+	GetLimitRulesForAccount(ctx context.Context, accountId int, accountType, operationType string) ([]entity.LimitRule, error)
+	DeleteLimitRule(ctx context.Context, id int) error
+}
+
+type Verification interface {
+	SetVerificationLevel(ctx context.Context, change entity.VerificationChange) error
+	GetVerificationChanges(ctx context.Context, accountId int) ([]entity.VerificationChange, error)
+	SetVerificationPolicy(ctx context.Context, policy entity.VerificationPolicy) error
+	DeleteVerificationPolicy(ctx context.Context, level, operationType string) error
+	GetAllVerificationPolicies(ctx context.Context) ([]entity.VerificationPolicy, error)
+	GetVerificationPoliciesForOperation(ctx context.Context, operationType string) ([]entity.VerificationPolicy, error)
+}
+
+type Fee interface {
+	CreateFeeRule(ctx context.Context, rule entity.FeeRule) (int, error)
+	GetAllFeeRules(ctx context.Context) ([]entity.FeeRule, error)
+	GetFeeRulesForOperation(ctx context.Context, operationType string, productId int, accountType string) ([]entity.FeeRule, error)
+	DeleteFeeRule(ctx context.Context, id int) error
+}
+
+type Cashback interface {
+	CreateCashbackRule(ctx context.Context, rule entity.CashbackRule) (int, error)
+	GetAllCashbackRules(ctx context.Context) ([]entity.CashbackRule, error)
+	DeleteCashbackRule(ctx context.Context, id int) error
+}
+
+type Subscription interface {
+	CreateSubscription(ctx context.Context, subscription entity.Subscription) (int, error)
+	GetSubscriptionById(ctx context.Context, id int) (entity.Subscription, error)
+	GetSubscriptionsByAccountId(ctx context.Context, accountId int) ([]entity.Subscription, error)
+	UpdateSubscriptionStatus(ctx context.Context, id int, from []string, to string) error
+	GetDueSubscriptionIds(ctx context.Context, limit int) ([]int, error)
+	ChargeSubscription(ctx context.Context, id int, rejectReason string, fee entity.Fee, limits []entity.LimitRule, spendingOrder []string, retryInterval, gracePeriod time.Duration) (entity.SubscriptionCharge, error)
+	GetSubscriptionCharges(ctx context.Context, subscriptionId int) ([]entity.SubscriptionCharge, error)
+}
+
+type ScheduledTransfer interface {
+	CreateScheduledTransfer(ctx context.Context, transfer entity.ScheduledTransfer) (int, error)
+	GetScheduledTransferById(ctx context.Context, id int) (entity.ScheduledTransfer, error)
+	GetScheduledTransferRuns(ctx context.Context, id int) ([]entity.ScheduledTransferRun, error)
+	CancelScheduledTransfer(ctx context.Context, id int) error
+	GetDueScheduledTransfers(ctx context.Context, limit int) ([]entity.ScheduledTransfer, error)
+	ExecuteScheduledTransfer(ctx context.Context, id int, rejectReason string, fee entity.Fee, limits []entity.LimitRule, verification entity.VerificationCheck) (entity.ScheduledTransferRun, error)
+}
+
+type Group interface {
+	CreateGroupAccount(ctx context.Context, ownerId int) (int, error)
+	SetGroupMember(ctx context.Context, member entity.GroupMember) error
+	RemoveGroupMember(ctx context.Context, accountId, userId int) error
+	GetGroupMember(ctx context.Context, accountId, userId int) (entity.GroupMember, error)
+	GetGroupMembers(ctx context.Context, accountId int) ([]entity.GroupMember, error)
+}
+
+type Installment interface {
+	CreateInstallmentPlan(ctx context.Context, plan entity.InstallmentPlan, userId *int, parts []entity.InstallmentPart, spendingOrder []string, fee entity.Fee, limits []entity.LimitRule) (int, error)
+	GetInstallmentPlanById(ctx context.Context, id int) (entity.InstallmentPlan, error)
+	GetInstallmentParts(ctx context.Context, planId int) ([]entity.InstallmentPart, error)
+	GetInstallmentObligations(ctx context.Context, accountId int) ([]entity.InstallmentObligation, error)
+	GetDueInstallmentParts(ctx context.Context, limit int) ([]entity.DueInstallmentPart, error)
+	ChargeInstallmentPart(ctx context.Context, id int, rejectReason string, fee entity.Fee, limits []entity.LimitRule, spendingOrder []string, policy entity.MissedInstallmentPolicy) (entity.InstallmentPart, error)
+}
+
+type PaymentRequest interface {
+	CreatePaymentRequest(ctx context.Context, request entity.PaymentRequest) (int, error)
+	GetPaymentRequestById(ctx context.Context, id int) (entity.PaymentRequest, error)
+	GetPaymentRequestsByAccountId(ctx context.Context, accountId int, status string) ([]entity.PaymentRequest, error)
+	AcceptPaymentRequest(ctx context.Context, id int, userId *int, fee entity.Fee, limits []entity.LimitRule, verification entity.VerificationCheck) error
+	DeclinePaymentRequest(ctx context.Context, id int) error
+}
+
+type Adjustment interface {
+	CreateAdjustment(ctx context.Context, adjustment entity.Adjustment) (int, error)
+	GetAdjustmentsByAccountId(ctx context.Context, accountId int) ([]entity.Adjustment, error)
+	SetAdjustmentReason(ctx context.Context, reason entity.AdjustmentReason) error
+	DeactivateAdjustmentReason(ctx context.Context, code string) error
+	GetAdjustmentReasons(ctx context.Context) ([]entity.AdjustmentReason, error)
+}
+
+type Approval interface {
+	CreateApprovalRequest(ctx context.Context, request entity.ApprovalRequest) (int, error)
+	GetApprovalRequestById(ctx context.Context, id int) (entity.ApprovalRequest, error)
+	GetApprovalRequests(ctx context.Context, status string) ([]entity.ApprovalRequest, error)
+	GetApprovalEvents(ctx context.Context, requestId int) ([]entity.ApprovalEvent, error)
+	ExecuteApprovalRequest(ctx context.Context, id, checkerId int, comment, rejectReason string, fee entity.Fee, limits []entity.LimitRule, verification entity.VerificationCheck) (entity.ApprovalRequest, error)
+	RejectApprovalRequest(ctx context.Context, id, checkerId int, comment string) error
+	ExpireApprovalRequests(ctx context.Context) (int, error)
+}
+
+type Voucher interface {
+	CreateVoucherCampaign(ctx context.Context, campaign entity.VoucherCampaign, codes []string) (int, error)
+	GetVoucherCampaignById(ctx context.Context, id int) (entity.VoucherCampaign, error)
+	RedeemVoucher(ctx context.Context, code string, accountId int) (entity.VoucherRedemption, error)
+	GetVoucherCampaignReport(ctx context.Context, campaignId int) (entity.VoucherCampaignReport, error)
+}
+
+type ReportLink interface {
+	CreateReportLink(ctx context.Context, link entity.ReportLink) (int, error)
+	GetReportLinkById(ctx context.Context, id int) (entity.ReportLink, error)
+	UseReportLink(ctx context.Context, id int) error
+	CreateReportLinkAccess(ctx context.Context, access entity.ReportLinkAccess) error
+	GetReportLinkAccesses(ctx context.Context, linkId int) ([]entity.ReportLinkAccess, error)
+}
+
+type ReportJob interface {
+	CreateReportJob(ctx context.Context, params entity.ReportParams, createdBy int) (int, bool, error)
+	GetReportJobById(ctx context.Context, id int) (entity.ReportJob, error)
+	ClaimReportJob(ctx context.Context, staleAfter time.Duration) (entity.ReportJob, error)
+	SetReportJobProgress(ctx context.Context, id, progress int) error
+	CompleteReportJob(ctx context.Context, id int, filename, link string) error
+	FailReportJobAttempt(ctx context.Context, id int, jobErr string, nextRunAt *time.Time) error
+}
+
+type ReportSchedule interface {
+	ClaimReportScheduleRun(ctx context.Context, schedule string, scheduledAt time.Time, policy entity.ReportScheduleRetryPolicy) (entity.ReportScheduleRun, error)
+	SetReportScheduleRunFile(ctx context.Context, id int, filename, link string) error
+	FinishReportScheduleRun(ctx context.Context, id int, delivered []string, runErr string) error
+	GetReportScheduleRuns(ctx context.Context, schedule string, offset, limit int) ([]entity.ReportScheduleRun, error)
+}
+
+type ReportArchive interface {
+	SaveArchivedReport(ctx context.Context, report entity.ArchivedReport) (int, error)
+	AddUnknownArchivedReports(ctx context.Context, filenames []string) error
+	GetArchivedReports(ctx context.Context, filter entity.ArchivedReportFilter, offset, limit int) ([]entity.ArchivedReport, error)
+	GetArchivedReportById(ctx context.Context, id int) (entity.ArchivedReport, error)
+	DeleteArchivedReport(ctx context.Context, id int) error
+}
+
+type Batch interface {
+	ExecuteBatch(ctx context.Context, items []entity.BatchItem, description string, stopOnError bool) ([]entity.BatchItemResult, error)
 }
 
 type Repositories struct {
@@ -46,14 +192,48 @@ type Repositories struct {
 	Product
 	Reservation
 	Operation
+	Limit
+	Verification
+	Fee
+	Cashback
+	Subscription
+	ScheduledTransfer
+	PaymentRequest
+	Installment
+	Group
+	Approval
+	Adjustment
+	Voucher
+	ReportLink
+	ReportJob
+	ReportSchedule
+	ReportArchive
+	Batch
 }
 
 func NewRepositories(pg *postgres.Postgres) *Repositories {
 	return &Repositories{
-		User:        pgdb.NewUserRepo(pg),
-		Account:     pgdb.NewAccountRepo(pg),
-		Product:     pgdb.NewProductRepo(pg),
-		Reservation: pgdb.NewReservationRepo(pg),
-		Operation:   pgdb.NewOperationRepo(pg),
+		User:              pgdb.NewUserRepo(pg),
+		Account:           pgdb.NewAccountRepo(pg),
+		Product:           pgdb.NewProductRepo(pg),
+		Reservation:       pgdb.NewReservationRepo(pg),
+		Operation:         pgdb.NewOperationRepo(pg),
+		Limit:             pgdb.NewLimitRepo(pg),
+		Verification:      pgdb.NewVerificationRepo(pg),
+		Fee:               pgdb.NewFeeRepo(pg),
+		Cashback:          pgdb.NewCashbackRepo(pg),
+		Subscription:      pgdb.NewSubscriptionRepo(pg),
+		ScheduledTransfer: pgdb.NewScheduledTransferRepo(pg),
+		PaymentRequest:    pgdb.NewPaymentRequestRepo(pg),
+		Installment:       pgdb.NewInstallmentRepo(pg),
+		Group:             pgdb.NewGroupRepo(pg),
+		Approval:          pgdb.NewApprovalRepo(pg),
+		Adjustment:        pgdb.NewAdjustmentRepo(pg),
+		Voucher:           pgdb.NewVoucherRepo(pg),
+		ReportLink:        pgdb.NewReportLinkRepo(pg),
+		ReportJob:         pgdb.NewReportJobRepo(pg),
+		ReportSchedule:    pgdb.NewReportScheduleRepo(pg),
+		ReportArchive:     pgdb.NewReportArchiveRepo(pg),
+		Batch:             pgdb.NewBatchRepo(pg),
 	}
 }
@@ -1,10 +1,90 @@
 package repoerrs
 
-import "errors"
+import (
+	"account-management-service/internal/entity"
+	"errors"
+	"fmt"
+	"time"
+)
 
 var (
 	ErrNotFound      = errors.New("not found")
 	ErrAlreadyExists = errors.New("already exists")
 
 	ErrNotEnoughBalance = errors.New("not enough balance")
+	ErrLimitExceeded    = errors.New("limit exceeded")
+
+	ErrVerificationRequired = errors.New("verification required")
+
+	ErrAccountFrozen          = errors.New("account is frozen")
+	ErrAccountClosed          = errors.New("account is closed")
+	ErrAccountHasBalance      = errors.New("account has non-zero balance")
+	ErrAccountHasReservations = errors.New("account has active reservations")
+
+	ErrOutstandingInstallments = errors.New("order has outstanding installments")
+
+	ErrNotGroupMember      = errors.New("user is not a member of the account")
+	ErrMemberCannotSpend   = errors.New("member is not allowed to spend")
+	ErrMemberLimitExceeded = errors.New("member monthly limit exceeded")
+
+	ErrWrongStatus = errors.New("wrong status")
+	ErrExpired     = errors.New("expired")
+	ErrSameUser    = errors.New("same user")
+
+	ErrUnknownReason = errors.New("unknown or inactive reason code")
+
+	ErrBatchItemFailed = errors.New("batch item failed")
 )
+
+// LimitExceededError is returned when an operation breaks the rolling window limit rule,
+// it matches ErrLimitExceeded and keeps the violated rule
+type LimitExceededError struct {
+	Rule          entity.LimitRule
+	OperationType string
+}
+
+func (e *LimitExceededError) Error() string {
+	return fmt.Sprintf("%v: %s", ErrLimitExceeded, e.Description())
+}
+
+func (e *LimitExceededError) Is(target error) bool {
+	return target == ErrLimitExceeded
+}
+
+// Description describes the violated rule
+func (e *LimitExceededError) Description() string {
+	window := time.Duration(e.Rule.WindowSeconds) * time.Second
+	switch e.Rule.LimitType {
+	case entity.LimitTypeMaxTotal:
+		return fmt.Sprintf("%s total must not exceed %d per %s", e.OperationType, e.Rule.Value, window)
+	case entity.LimitTypeMaxCount:
+		return fmt.Sprintf("at most %d %s operations per %s", e.Rule.Value, e.OperationType, window)
+	default:
+		return fmt.Sprintf("%s amount must not exceed %d", e.OperationType, e.Rule.Value)
+	}
+}
+
+// VerificationRequiredError is returned when the account verification level does not allow the operation
+// given the amounts already spent, it matches ErrVerificationRequired and keeps the lowest level allowing it,
+// which is empty if no level does
+type VerificationRequiredError struct {
+	Level         string
+	OperationType string
+	Amount        int
+}
+
+func (e *VerificationRequiredError) Error() string {
+	return fmt.Sprintf("%v: %s", ErrVerificationRequired, e.Description())
+}
+
+func (e *VerificationRequiredError) Is(target error) bool {
+	return target == ErrVerificationRequired
+}
+
+// Description describes the level required for the operation
+func (e *VerificationRequiredError) Description() string {
+	if e.Level == "" {
+		return fmt.Sprintf("%s of %d is not allowed at any verification level", e.OperationType, e.Amount)
+	}
+	return fmt.Sprintf("%s of %d requires %s verification", e.OperationType, e.Amount, e.Level)
+}
@@ -5,18 +5,41 @@ import (
 	"account-management-service/internal/repo"
 	"account-management-service/internal/repo/repoerrs"
 	"context"
+	"errors"
+	log "github.com/sirupsen/logrus"
+	"time"
 )
 
+// scheduledTransferBatchSize is the max number of scheduled transfers executed in a single runner pass
+const scheduledTransferBatchSize = 100
+
 type AccountService struct {
-	accountRepo repo.Account
+	accountRepo           repo.Account
+	scheduledTransferRepo repo.ScheduledTransfer
+	groupRepo             repo.Group
+	limitService          Limit
+	verificationService   Verification
+	feeService            Fee
 }
 
-func NewAccountService(accountRepo repo.Account) *AccountService {
-	return &AccountService{accountRepo: accountRepo}
+func NewAccountService(accountRepo repo.Account, scheduledTransferRepo repo.ScheduledTransfer, groupRepo repo.Group, limitService Limit, verificationService Verification, feeService Fee) *AccountService {
+	return &AccountService{
+		accountRepo:           accountRepo,
+		scheduledTransferRepo: scheduledTransferRepo,
+		groupRepo:             groupRepo,
+		limitService:          limitService,
+		verificationService:   verificationService,
+		feeService:            feeService,
+	}
 }
 
-func (s *AccountService) CreateAccount(ctx context.Context) (int, error) {
-	id, err := s.accountRepo.CreateAccount(ctx)
+func (s *AccountService) CreateAccount(ctx context.Context, input AccountCreateInput) (int, error) {
+	accountType := input.Type
+	if accountType == "" {
+		accountType = entity.AccountTypePersonal
+	}
+
+	id, err := s.accountRepo.CreateAccount(ctx, accountType)
 	if err != nil {
 		if err == repoerrs.ErrAlreadyExists {
 			return 0, ErrAccountAlreadyExists
@@ -31,14 +54,309 @@ func (s *AccountService) GetAccountById(ctx context.Context, userId int) (entity
 	return s.accountRepo.GetAccountById(ctx, userId)
 }
 
+func (s *AccountService) GetAccountWallets(ctx context.Context, accountId int) ([]entity.Wallet, error) {
+	return s.accountRepo.GetWalletsByAccountId(ctx, accountId)
+}
+
 func (s *AccountService) Deposit(ctx context.Context, input AccountDepositInput) error {
-	return s.accountRepo.Deposit(ctx, input.Id, input.Amount)
+	if input.Amount <= 0 {
+		return ErrInvalidAmount
+	}
+
+	wallet := input.Wallet
+	if wallet == "" {
+		wallet = entity.WalletTypeMain
+	}
+
+	err := s.accountRepo.Deposit(ctx, input.Id, input.Amount, wallet)
+	return accountRepoError(err)
 }
 
 func (s *AccountService) Withdraw(ctx context.Context, input AccountWithdrawInput) error {
-	return s.accountRepo.Withdraw(ctx, input.Id, input.Amount)
+	// a negative withdrawal would credit the account bypassing the deposit approval
+	if input.Amount <= 0 {
+		return ErrInvalidAmount
+	}
+
+	limits, err := s.limitService.CheckLimits(ctx, input.Id, entity.OperationTypeWithdraw, input.Amount)
+	if err != nil {
+		return err
+	}
+
+	verification, err := s.verificationService.CheckVerification(ctx, input.Id, entity.OperationTypeWithdraw, input.Amount)
+	if err != nil {
+		return err
+	}
+
+	fee, err := s.feeService.CalculateFee(ctx, FeeCalculateInput{
+		AccountId:     input.Id,
+		OperationType: entity.OperationTypeWithdraw,
+		Amount:        input.Amount,
+	})
+	if err != nil {
+		return err
+	}
+
+	err = s.accountRepo.Withdraw(ctx, input.Id, input.Amount, &input.UserId, fee, limits, verification)
+	return accountRepoError(err)
 }
 
 func (s *AccountService) Transfer(ctx context.Context, input AccountTransferInput) error {
-	return s.accountRepo.Transfer(ctx, input.From, input.To, input.Amount)
+	if input.Amount <= 0 {
+		return ErrInvalidAmount
+	}
+
+	limits, err := s.limitService.CheckLimits(ctx, input.From, entity.OperationTypeTransferFrom, input.Amount)
+	if err != nil {
+		return err
+	}
+
+	verification, err := s.verificationService.CheckVerification(ctx, input.From, entity.OperationTypeTransferFrom, input.Amount)
+	if err != nil {
+		return err
+	}
+
+	fee, err := s.feeService.CalculateFee(ctx, FeeCalculateInput{
+		AccountId:     input.From,
+		OperationType: entity.OperationTypeTransferFrom,
+		Amount:        input.Amount,
+	})
+	if err != nil {
+		return err
+	}
+
+	err = s.accountRepo.Transfer(ctx, input.From, input.To, input.Amount, &input.UserId, fee, limits, verification)
+	return accountRepoError(err)
+}
+
+func (s *AccountService) ScheduleTransfer(ctx context.Context, input AccountScheduleTransferInput) (int, error) {
+	if input.From == input.To {
+		return 0, ErrCannotScheduleTransfer
+	}
+
+	// the transfer is executed by the runner on behalf of the account, so the user is checked now
+	err := checkSpender(ctx, s.groupRepo, input.From, input.UserId)
+	if err != nil {
+		return 0, err
+	}
+
+	executeAt := input.ExecuteAt
+	if executeAt.IsZero() {
+		executeAt = time.Now()
+	}
+
+	id, err := s.scheduledTransferRepo.CreateScheduledTransfer(ctx, entity.ScheduledTransfer{
+		FromAccountId: input.From,
+		ToAccountId:   input.To,
+		Amount:        input.Amount,
+		ExecuteAt:     executeAt,
+		Repeat:        input.Repeat,
+	})
+	if err != nil {
+		log.Errorf("AccountService.ScheduleTransfer - s.scheduledTransferRepo.CreateScheduledTransfer: %v", err)
+		return 0, ErrCannotScheduleTransfer
+	}
+
+	return id, nil
+}
+
+func (s *AccountService) CancelScheduledTransfer(ctx context.Context, id int) error {
+	err := s.scheduledTransferRepo.CancelScheduledTransfer(ctx, id)
+	if err == nil {
+		return nil
+	}
+
+	if !errors.Is(err, repoerrs.ErrNotFound) {
+		log.Errorf("AccountService.CancelScheduledTransfer - s.scheduledTransferRepo.CancelScheduledTransfer: %v", err)
+		return err
+	}
+
+	// nothing was cancelled, either there is no such transfer or it has already been finished
+	_, err = s.scheduledTransferRepo.GetScheduledTransferById(ctx, id)
+	if err != nil {
+		if errors.Is(err, repoerrs.ErrNotFound) {
+			return ErrScheduledTransferNotFound
+		}
+		return err
+	}
+	return ErrCannotCancelScheduledTransfer
+}
+
+func (s *AccountService) GetScheduledTransfer(ctx context.Context, id int) (entity.ScheduledTransfer, []entity.ScheduledTransferRun, error) {
+	transfer, err := s.scheduledTransferRepo.GetScheduledTransferById(ctx, id)
+	if err != nil {
+		if errors.Is(err, repoerrs.ErrNotFound) {
+			return entity.ScheduledTransfer{}, nil, ErrScheduledTransferNotFound
+		}
+		return entity.ScheduledTransfer{}, nil, err
+	}
+
+	runs, err := s.scheduledTransferRepo.GetScheduledTransferRuns(ctx, id)
+	if err != nil {
+		return entity.ScheduledTransfer{}, nil, err
+	}
+
+	return transfer, runs, nil
+}
+
+// ExecuteDueTransfers executes scheduled transfers whose time has come, it is run by the scheduler.
+// Limits, verification and fees are checked at the execution time as the rolling windows and the rules may change
+func (s *AccountService) ExecuteDueTransfers(ctx context.Context) {
+	transfers, err := s.scheduledTransferRepo.GetDueScheduledTransfers(ctx, scheduledTransferBatchSize)
+	if err != nil {
+		log.Errorf("AccountService.ExecuteDueTransfers - s.scheduledTransferRepo.GetDueScheduledTransfers: %v", err)
+		return
+	}
+
+	for _, transfer := range transfers {
+		if ctx.Err() != nil {
+			return
+		}
+
+		var rejectReason string
+		limits, err := s.limitService.CheckLimits(ctx, transfer.FromAccountId, entity.OperationTypeTransferFrom, transfer.Amount)
+		if err != nil {
+			if !errors.Is(err, ErrLimitExceeded) && !errors.Is(err, ErrAccountNotFound) {
+				// limits cannot be checked now, try again on the next run
+				continue
+			}
+			rejectReason = err.Error()
+		}
+
+		var verification entity.VerificationCheck
+		if rejectReason == "" {
+			verification, err = s.verificationService.CheckVerification(ctx, transfer.FromAccountId, entity.OperationTypeTransferFrom, transfer.Amount)
+			if err != nil {
+				if !errors.Is(err, ErrVerificationRequired) && !errors.Is(err, ErrLimitExceeded) {
+					continue
+				}
+				rejectReason = err.Error()
+			}
+		}
+
+		var fee entity.Fee
+		if rejectReason == "" {
+			fee, err = s.feeService.CalculateFee(ctx, FeeCalculateInput{
+				AccountId:     transfer.FromAccountId,
+				OperationType: entity.OperationTypeTransferFrom,
+				Amount:        transfer.Amount,
+			})
+			if err != nil {
+				continue
+			}
+		}
+
+		run, err := s.scheduledTransferRepo.ExecuteScheduledTransfer(ctx, transfer.Id, rejectReason, fee, limits, verification)
+		if err != nil {
+			// cancelled or executed by another instance in the meantime
+			if errors.Is(err, repoerrs.ErrNotFound) {
+				continue
+			}
+			log.Errorf("AccountService.ExecuteDueTransfers - s.scheduledTransferRepo.ExecuteScheduledTransfer: %v", err)
+			continue
+		}
+
+		if run.Status == entity.ScheduledTransferStatusFailed {
+			log.Warnf("scheduled transfer %d failed: %s", transfer.Id, run.Error)
+		}
+	}
+}
+
+func (s *AccountService) FreezeAccount(ctx context.Context, input AccountFreezeInput) error {
+	status := entity.AccountStatusFrozen
+	if input.DebitOnly {
+		status = entity.AccountStatusFrozenDebit
+	}
+
+	err := s.accountRepo.ChangeAccountStatus(ctx, entity.AccountStatusChange{
+		AccountId:  input.Id,
+		NewStatus:  status,
+		ReasonCode: input.ReasonCode,
+		Comment:    input.Comment,
+		ChangedBy:  &input.ChangedBy,
+	})
+	if err != nil {
+		log.Errorf("AccountService.FreezeAccount - s.accountRepo.ChangeAccountStatus: %v", err)
+		return statusRepoError(err)
+	}
+
+	return nil
+}
+
+func (s *AccountService) UnfreezeAccount(ctx context.Context, input AccountUnfreezeInput) error {
+	err := s.accountRepo.ChangeAccountStatus(ctx, entity.AccountStatusChange{
+		AccountId:  input.Id,
+		NewStatus:  entity.AccountStatusActive,
+		ReasonCode: input.ReasonCode,
+		Comment:    input.Comment,
+		ChangedBy:  &input.ChangedBy,
+	})
+	if err != nil {
+		log.Errorf("AccountService.UnfreezeAccount - s.accountRepo.ChangeAccountStatus: %v", err)
+		return statusRepoError(err)
+	}
+
+	return nil
+}
+
+func (s *AccountService) CloseAccount(ctx context.Context, input AccountCloseInput) (int, error) {
+	payout, err := s.accountRepo.CloseAccount(ctx, entity.AccountStatusChange{
+		AccountId:  input.Id,
+		ReasonCode: input.ReasonCode,
+		Comment:    input.Comment,
+		ChangedBy:  &input.ChangedBy,
+	}, input.Payout)
+	if err != nil {
+		log.Errorf("AccountService.CloseAccount - s.accountRepo.CloseAccount: %v", err)
+		return 0, statusRepoError(err)
+	}
+
+	return payout, nil
+}
+
+func (s *AccountService) GetAccountStatusHistory(ctx context.Context, accountId int) ([]entity.AccountStatusChange, error) {
+	return s.accountRepo.GetAccountStatusChanges(ctx, accountId)
+}
+
+// accountRepoError converts errors of money moving repo methods into service errors
+func accountRepoError(err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, repoerrs.ErrNotFound):
+		return ErrAccountNotFound
+	case errors.Is(err, repoerrs.ErrNotEnoughBalance):
+		return ErrNotEnoughBalance
+	case errors.Is(err, repoerrs.ErrAccountFrozen):
+		return ErrAccountFrozen
+	case errors.Is(err, repoerrs.ErrAccountClosed):
+		return ErrAccountClosed
+	case errors.Is(err, repoerrs.ErrLimitExceeded):
+		return limitRepoError(err)
+	case errors.Is(err, repoerrs.ErrVerificationRequired):
+		return verificationRepoError(err)
+	case errors.Is(err, repoerrs.ErrNotGroupMember):
+		return ErrNotGroupMember
+	case errors.Is(err, repoerrs.ErrMemberCannotSpend):
+		return ErrGroupAccessDenied
+	case errors.Is(err, repoerrs.ErrMemberLimitExceeded):
+		return ErrMemberLimitExceeded
+	default:
+		return err
+	}
+}
+
+func statusRepoError(err error) error {
+	switch {
+	case errors.Is(err, repoerrs.ErrNotFound):
+		return ErrAccountNotFound
+	case errors.Is(err, repoerrs.ErrAccountClosed):
+		return ErrAccountClosed
+	case errors.Is(err, repoerrs.ErrAccountHasBalance):
+		return ErrAccountHasBalance
+	case errors.Is(err, repoerrs.ErrAccountHasReservations):
+		return ErrAccountHasReservations
+	default:
+		return ErrCannotChangeStatus
+	}
 }
@@ -0,0 +1,170 @@
+package service
+
+import (
+	"account-management-service/internal/entity"
+	"account-management-service/internal/mocks/repomocks"
+	"account-management-service/internal/repo/repoerrs"
+	"context"
+	"errors"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+const feeAccountId = 99
+
+func TestAccountService_ExecuteDueTransfers(t *testing.T) {
+	type MockBehavior func(s *repomocks.MockScheduledTransfer, a *repomocks.MockAccount, l *repomocks.MockLimit, v *repomocks.MockVerification, f *repomocks.MockFee, ctx context.Context)
+
+	transfer := entity.ScheduledTransfer{
+		Id:            1,
+		FromAccountId: 1,
+		ToAccountId:   2,
+		Amount:        100,
+	}
+
+	testCases := []struct {
+		name         string
+		mockBehavior MockBehavior
+	}{
+		{
+			name: "OK",
+			mockBehavior: func(s *repomocks.MockScheduledTransfer, a *repomocks.MockAccount, l *repomocks.MockLimit, v *repomocks.MockVerification, f *repomocks.MockFee, ctx context.Context) {
+				s.EXPECT().GetDueScheduledTransfers(ctx, scheduledTransferBatchSize).
+					Return([]entity.ScheduledTransfer{transfer}, nil)
+				a.EXPECT().GetAccountById(ctx, transfer.FromAccountId).
+					Return(entity.Account{Id: 1, Type: entity.AccountTypePersonal}, nil).Times(3)
+				l.EXPECT().GetLimitRulesForAccount(ctx, transfer.FromAccountId, entity.AccountTypePersonal, entity.OperationTypeTransferFrom).
+					Return([]entity.LimitRule{{Scope: entity.LimitScopeGlobal, LimitType: entity.LimitTypeMaxTotal, Value: 1000, WindowSeconds: 86400}}, nil)
+				v.EXPECT().GetVerificationPoliciesForOperation(ctx, entity.OperationTypeTransferFrom).
+					Return(nil, nil)
+				f.EXPECT().GetFeeRulesForOperation(ctx, entity.OperationTypeTransferFrom, 0, entity.AccountTypePersonal).
+					Return([]entity.FeeRule{{Rate: 100, MinFee: 5}}, nil)
+				// the window rules are checked by the repo once the account is locked
+				s.EXPECT().ExecuteScheduledTransfer(ctx, transfer.Id, "", entity.Fee{AccountId: feeAccountId, Amount: 5},
+					[]entity.LimitRule{{Scope: entity.LimitScopeGlobal, LimitType: entity.LimitTypeMaxTotal, Value: 1000, WindowSeconds: 86400}}, entity.VerificationCheck{}).
+					Return(entity.ScheduledTransferRun{Status: entity.ScheduledTransferStatusSucceeded}, nil)
+			},
+		},
+		{
+			name: "limit exceeded is recorded as failed run",
+			mockBehavior: func(s *repomocks.MockScheduledTransfer, a *repomocks.MockAccount, l *repomocks.MockLimit, v *repomocks.MockVerification, f *repomocks.MockFee, ctx context.Context) {
+				s.EXPECT().GetDueScheduledTransfers(ctx, scheduledTransferBatchSize).
+					Return([]entity.ScheduledTransfer{transfer}, nil)
+				a.EXPECT().GetAccountById(ctx, transfer.FromAccountId).
+					Return(entity.Account{Id: 1, Type: entity.AccountTypePersonal}, nil)
+				l.EXPECT().GetLimitRulesForAccount(ctx, transfer.FromAccountId, entity.AccountTypePersonal, entity.OperationTypeTransferFrom).
+					Return([]entity.LimitRule{
+						{Scope: entity.LimitScopeGlobal, LimitType: entity.LimitTypeMaxAmount, Value: 50},
+					}, nil)
+				s.EXPECT().ExecuteScheduledTransfer(ctx, transfer.Id, "limit exceeded: transfer_from amount must not exceed 50", entity.Fee{}, []entity.LimitRule(nil), entity.VerificationCheck{}).
+					Return(entity.ScheduledTransferRun{Status: entity.ScheduledTransferStatusFailed}, nil)
+			},
+		},
+		{
+			name: "verification required is recorded as failed run",
+			mockBehavior: func(s *repomocks.MockScheduledTransfer, a *repomocks.MockAccount, l *repomocks.MockLimit, v *repomocks.MockVerification, f *repomocks.MockFee, ctx context.Context) {
+				s.EXPECT().GetDueScheduledTransfers(ctx, scheduledTransferBatchSize).
+					Return([]entity.ScheduledTransfer{transfer}, nil)
+				a.EXPECT().GetAccountById(ctx, transfer.FromAccountId).
+					Return(entity.Account{Id: 1, Type: entity.AccountTypePersonal, VerificationLevel: entity.VerificationLevelNone}, nil).Times(2)
+				l.EXPECT().GetLimitRulesForAccount(ctx, transfer.FromAccountId, entity.AccountTypePersonal, entity.OperationTypeTransferFrom).
+					Return(nil, nil)
+				v.EXPECT().GetVerificationPoliciesForOperation(ctx, entity.OperationTypeTransferFrom).
+					Return([]entity.VerificationPolicy{
+						{Level: entity.VerificationLevelNone, MaxAmount: 50},
+						{Level: entity.VerificationLevelBasic, MaxAmount: 500},
+					}, nil)
+				s.EXPECT().ExecuteScheduledTransfer(ctx, transfer.Id, "verification required: transfer_from of 100 requires basic verification", entity.Fee{}, []entity.LimitRule(nil), entity.VerificationCheck{}).
+					Return(entity.ScheduledTransferRun{Status: entity.ScheduledTransferStatusFailed}, nil)
+			},
+		},
+		{
+			name: "limits cannot be checked",
+			mockBehavior: func(s *repomocks.MockScheduledTransfer, a *repomocks.MockAccount, l *repomocks.MockLimit, v *repomocks.MockVerification, f *repomocks.MockFee, ctx context.Context) {
+				s.EXPECT().GetDueScheduledTransfers(ctx, scheduledTransferBatchSize).
+					Return([]entity.ScheduledTransfer{transfer}, nil)
+				a.EXPECT().GetAccountById(ctx, transfer.FromAccountId).
+					Return(entity.Account{}, errors.New("some error"))
+			},
+		},
+		{
+			name: "fee cannot be calculated",
+			mockBehavior: func(s *repomocks.MockScheduledTransfer, a *repomocks.MockAccount, l *repomocks.MockLimit, v *repomocks.MockVerification, f *repomocks.MockFee, ctx context.Context) {
+				s.EXPECT().GetDueScheduledTransfers(ctx, scheduledTransferBatchSize).
+					Return([]entity.ScheduledTransfer{transfer}, nil)
+				a.EXPECT().GetAccountById(ctx, transfer.FromAccountId).
+					Return(entity.Account{Id: 1, Type: entity.AccountTypePersonal}, nil).Times(3)
+				l.EXPECT().GetLimitRulesForAccount(ctx, transfer.FromAccountId, entity.AccountTypePersonal, entity.OperationTypeTransferFrom).
+					Return(nil, nil)
+				v.EXPECT().GetVerificationPoliciesForOperation(ctx, entity.OperationTypeTransferFrom).
+					Return(nil, nil)
+				f.EXPECT().GetFeeRulesForOperation(ctx, entity.OperationTypeTransferFrom, 0, entity.AccountTypePersonal).
+					Return(nil, errors.New("some error"))
+			},
+		},
+		{
+			name: "executed by another instance",
+			mockBehavior: func(s *repomocks.MockScheduledTransfer, a *repomocks.MockAccount, l *repomocks.MockLimit, v *repomocks.MockVerification, f *repomocks.MockFee, ctx context.Context) {
+				s.EXPECT().GetDueScheduledTransfers(ctx, scheduledTransferBatchSize).
+					Return([]entity.ScheduledTransfer{transfer}, nil)
+				a.EXPECT().GetAccountById(ctx, transfer.FromAccountId).
+					Return(entity.Account{Id: 1, Type: entity.AccountTypePersonal}, nil).Times(3)
+				l.EXPECT().GetLimitRulesForAccount(ctx, transfer.FromAccountId, entity.AccountTypePersonal, entity.OperationTypeTransferFrom).
+					Return(nil, nil)
+				v.EXPECT().GetVerificationPoliciesForOperation(ctx, entity.OperationTypeTransferFrom).
+					Return(nil, nil)
+				f.EXPECT().GetFeeRulesForOperation(ctx, entity.OperationTypeTransferFrom, 0, entity.AccountTypePersonal).
+					Return(nil, nil)
+				s.EXPECT().ExecuteScheduledTransfer(ctx, transfer.Id, "", entity.Fee{AccountId: feeAccountId}, []entity.LimitRule(nil), entity.VerificationCheck{}).
+					Return(entity.ScheduledTransferRun{}, repoerrs.ErrNotFound)
+			},
+		},
+		{
+			name: "get due transfers error",
+			mockBehavior: func(s *repomocks.MockScheduledTransfer, a *repomocks.MockAccount, l *repomocks.MockLimit, v *repomocks.MockVerification, f *repomocks.MockFee, ctx context.Context) {
+				s.EXPECT().GetDueScheduledTransfers(ctx, scheduledTransferBatchSize).
+					Return(nil, errors.New("some error"))
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// init deps
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			// init mocks
+			ctx := context.Background()
+			accountRepo := repomocks.NewMockAccount(ctrl)
+			scheduledTransferRepo := repomocks.NewMockScheduledTransfer(ctrl)
+			limitRepo := repomocks.NewMockLimit(ctrl)
+			verificationRepo := repomocks.NewMockVerification(ctrl)
+			feeRepo := repomocks.NewMockFee(ctrl)
+			tc.mockBehavior(scheduledTransferRepo, accountRepo, limitRepo, verificationRepo, feeRepo, ctx)
+
+			// init service
+			limitService := NewLimitService(limitRepo, accountRepo)
+			verificationService := NewVerificationService(verificationRepo, accountRepo)
+			feeService := NewFeeService(feeRepo, accountRepo, nil, feeAccountId)
+			s := NewAccountService(accountRepo, scheduledTransferRepo, nil, limitService, verificationService, feeService)
+
+			// run test
+			s.ExecuteDueTransfers(ctx)
+		})
+	}
+}
+
+func TestAccountService_InvalidAmount(t *testing.T) {
+	ctx := context.Background()
+
+	// nothing reaches the repo, a negative amount would move the money backwards
+	s := NewAccountService(nil, nil, nil, nil, nil, nil)
+
+	for _, amount := range []int{0, -50000} {
+		assert.ErrorIs(t, s.Deposit(ctx, AccountDepositInput{Id: 1, Amount: amount}), ErrInvalidAmount)
+		assert.ErrorIs(t, s.Withdraw(ctx, AccountWithdrawInput{Id: 1, Amount: amount}), ErrInvalidAmount)
+		assert.ErrorIs(t, s.Transfer(ctx, AccountTransferInput{From: 1, To: 2, Amount: amount}), ErrInvalidAmount)
+	}
+}
@@ -0,0 +1,94 @@
+package service
+
+import (
+	"account-management-service/internal/entity"
+	"account-management-service/internal/repo"
+	"account-management-service/internal/repo/repoerrs"
+	"context"
+	"errors"
+	log "github.com/sirupsen/logrus"
+)
+
+type AdjustmentService struct {
+	adjustmentRepo repo.Adjustment
+}
+
+func NewAdjustmentService(adjustmentRepo repo.Adjustment) *AdjustmentService {
+	return &AdjustmentService{
+		adjustmentRepo: adjustmentRepo,
+	}
+}
+
+// CreateAdjustment corrects the account balance, no limits and fees apply to adjustments
+func (s *AdjustmentService) CreateAdjustment(ctx context.Context, input AdjustmentCreateInput) (int, error) {
+	if input.Amount <= 0 || input.ReasonCode == "" || input.Comment == "" {
+		return 0, ErrInvalidAdjustment
+	}
+	switch input.Direction {
+	case entity.AdjustmentDirectionCredit, entity.AdjustmentDirectionDebit:
+	default:
+		return 0, ErrInvalidAdjustment
+	}
+
+	wallet := input.Wallet
+	if wallet == "" {
+		wallet = entity.WalletTypeMain
+	}
+
+	id, err := s.adjustmentRepo.CreateAdjustment(ctx, entity.Adjustment{
+		AccountId:  input.AccountId,
+		Direction:  input.Direction,
+		Amount:     input.Amount,
+		Wallet:     wallet,
+		ReasonCode: input.ReasonCode,
+		Comment:    input.Comment,
+		Ticket:     input.Ticket,
+		CreatedBy:  input.CreatedBy,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, repoerrs.ErrUnknownReason):
+			return 0, ErrUnknownAdjustmentReason
+		case errors.Is(err, repoerrs.ErrNotFound), errors.Is(err, repoerrs.ErrNotEnoughBalance),
+			errors.Is(err, repoerrs.ErrAccountFrozen), errors.Is(err, repoerrs.ErrAccountClosed):
+			return 0, accountRepoError(err)
+		}
+		log.Errorf("AdjustmentService.CreateAdjustment - s.adjustmentRepo.CreateAdjustment: %v", err)
+		return 0, ErrCannotCreateAdjustment
+	}
+
+	return id, nil
+}
+
+func (s *AdjustmentService) GetAccountAdjustments(ctx context.Context, accountId int) ([]entity.Adjustment, error) {
+	return s.adjustmentRepo.GetAdjustmentsByAccountId(ctx, accountId)
+}
+
+func (s *AdjustmentService) SetAdjustmentReason(ctx context.Context, code, description string) error {
+	err := s.adjustmentRepo.SetAdjustmentReason(ctx, entity.AdjustmentReason{
+		Code:        code,
+		Description: description,
+	})
+	if err != nil {
+		log.Errorf("AdjustmentService.SetAdjustmentReason - s.adjustmentRepo.SetAdjustmentReason: %v", err)
+		return ErrCannotSetAdjustmentReason
+	}
+
+	return nil
+}
+
+func (s *AdjustmentService) DeactivateAdjustmentReason(ctx context.Context, code string) error {
+	err := s.adjustmentRepo.DeactivateAdjustmentReason(ctx, code)
+	if err != nil {
+		if errors.Is(err, repoerrs.ErrNotFound) {
+			return ErrUnknownAdjustmentReason
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (s *AdjustmentService) GetAdjustmentReasons(ctx context.Context) ([]entity.AdjustmentReason, error) {
+	return s.adjustmentRepo.GetAdjustmentReasons(ctx)
+}
@@ -0,0 +1,151 @@
+package service
+
+import (
+	"account-management-service/internal/entity"
+	"account-management-service/internal/mocks/repomocks"
+	"account-management-service/internal/repo/repoerrs"
+	"context"
+	"errors"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestAdjustmentService_CreateAdjustment(t *testing.T) {
+	type args struct {
+		ctx   context.Context
+		input AdjustmentCreateInput
+	}
+
+	type MockBehavior func(r *repomocks.MockAdjustment, args args)
+
+	testCases := []struct {
+		name         string
+		args         args
+		mockBehavior MockBehavior
+		want         int
+		wantErr      error
+	}{
+		{
+			name: "OK",
+			args: args{
+				ctx: context.Background(),
+				input: AdjustmentCreateInput{
+					AccountId:  1,
+					Direction:  entity.AdjustmentDirectionCredit,
+					Amount:     100,
+					ReasonCode: "processing_error",
+					Comment:    "deposit was lost",
+					Ticket:     "SUP-1",
+					CreatedBy:  2,
+				},
+			},
+			mockBehavior: func(r *repomocks.MockAdjustment, args args) {
+				r.EXPECT().CreateAdjustment(args.ctx, entity.Adjustment{
+					AccountId:  1,
+					Direction:  entity.AdjustmentDirectionCredit,
+					Amount:     100,
+					Wallet:     entity.WalletTypeMain,
+					ReasonCode: "processing_error",
+					Comment:    "deposit was lost",
+					Ticket:     "SUP-1",
+					CreatedBy:  2,
+				}).Return(1, nil)
+			},
+			want: 1,
+		},
+		{
+			name: "without comment",
+			args: args{
+				ctx: context.Background(),
+				input: AdjustmentCreateInput{
+					AccountId:  1,
+					Direction:  entity.AdjustmentDirectionCredit,
+					Amount:     100,
+					ReasonCode: "processing_error",
+				},
+			},
+			mockBehavior: func(r *repomocks.MockAdjustment, args args) {},
+			wantErr:      ErrInvalidAdjustment,
+		},
+		{
+			name: "unknown reason",
+			args: args{
+				ctx: context.Background(),
+				input: AdjustmentCreateInput{
+					AccountId:  1,
+					Direction:  entity.AdjustmentDirectionDebit,
+					Amount:     100,
+					ReasonCode: "unknown",
+					Comment:    "comment",
+				},
+			},
+			mockBehavior: func(r *repomocks.MockAdjustment, args args) {
+				r.EXPECT().CreateAdjustment(args.ctx, gomock.Any()).
+					Return(0, repoerrs.ErrUnknownReason)
+			},
+			wantErr: ErrUnknownAdjustmentReason,
+		},
+		{
+			name: "not enough balance",
+			args: args{
+				ctx: context.Background(),
+				input: AdjustmentCreateInput{
+					AccountId:  1,
+					Direction:  entity.AdjustmentDirectionDebit,
+					Amount:     100,
+					ReasonCode: "duplicate_payment",
+					Comment:    "comment",
+				},
+			},
+			mockBehavior: func(r *repomocks.MockAdjustment, args args) {
+				r.EXPECT().CreateAdjustment(args.ctx, gomock.Any()).
+					Return(0, repoerrs.ErrNotEnoughBalance)
+			},
+			wantErr: ErrNotEnoughBalance,
+		},
+		{
+			name: "unexpected error",
+			args: args{
+				ctx: context.Background(),
+				input: AdjustmentCreateInput{
+					AccountId:  1,
+					Direction:  entity.AdjustmentDirectionCredit,
+					Amount:     100,
+					ReasonCode: "goodwill",
+					Comment:    "comment",
+				},
+			},
+			mockBehavior: func(r *repomocks.MockAdjustment, args args) {
+				r.EXPECT().CreateAdjustment(args.ctx, gomock.Any()).
+					Return(0, errors.New("some error"))
+			},
+			wantErr: ErrCannotCreateAdjustment,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// init deps
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			// init mocks
+			adjustmentRepo := repomocks.NewMockAdjustment(ctrl)
+			tc.mockBehavior(adjustmentRepo, tc.args)
+
+			// init service
+			s := NewAdjustmentService(adjustmentRepo)
+
+			// run test
+			got, err := s.CreateAdjustment(tc.args.ctx, tc.args.input)
+			if tc.wantErr != nil {
+				assert.ErrorIs(t, err, tc.wantErr)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
@@ -0,0 +1,199 @@
+package service
+
+import (
+	"account-management-service/internal/entity"
+	"account-management-service/internal/repo"
+	"account-management-service/internal/repo/repoerrs"
+	"context"
+	"errors"
+	log "github.com/sirupsen/logrus"
+	"time"
+)
+
+type ApprovalService struct {
+	approvalRepo        repo.Approval
+	limitService        Limit
+	verificationService Verification
+	feeService          Fee
+	// thresholds are the amounts from which an operation type needs approval,
+	// operation types which are not listed are executed right away
+	thresholds map[string]int
+	ttl        time.Duration
+}
+
+func NewApprovalService(approvalRepo repo.Approval, limitService Limit, verificationService Verification, feeService Fee, thresholds map[string]int, ttl time.Duration) *ApprovalService {
+	return &ApprovalService{
+		approvalRepo:        approvalRepo,
+		limitService:        limitService,
+		verificationService: verificationService,
+		feeService:          feeService,
+		thresholds:          thresholds,
+		ttl:                 ttl,
+	}
+}
+
+func (s *ApprovalService) RequiresApproval(operationType string, amount int) bool {
+	threshold, ok := s.thresholds[operationType]
+	return ok && amount >= threshold
+}
+
+func (s *ApprovalService) CreateApprovalRequest(ctx context.Context, input ApprovalCreateInput) (int, error) {
+	request := entity.ApprovalRequest{
+		OperationType: input.OperationType,
+		Amount:        input.Amount,
+		Wallet:        input.Wallet,
+		ReasonCode:    input.ReasonCode,
+		Comment:       input.Comment,
+		Ticket:        input.Ticket,
+		MakerId:       input.MakerId,
+		ExpiresAt:     time.Now().Add(s.ttl),
+	}
+
+	switch input.OperationType {
+	case entity.OperationTypeDeposit, entity.OperationTypeWithdraw:
+		if input.AccountId == 0 || input.Amount <= 0 {
+			return 0, ErrInvalidApprovalRequest
+		}
+		request.AccountId = &input.AccountId
+	case entity.OperationTypeAdjustmentCredit, entity.OperationTypeAdjustmentDebit:
+		if input.AccountId == 0 || input.Amount <= 0 || input.ReasonCode == "" || input.Comment == "" {
+			return 0, ErrInvalidApprovalRequest
+		}
+		request.AccountId = &input.AccountId
+	case entity.OperationTypeRefund, entity.OperationTypeRevenueRefund:
+		if input.OrderId == 0 {
+			return 0, ErrInvalidApprovalRequest
+		}
+		request.OrderId = &input.OrderId
+	default:
+		return 0, ErrInvalidApprovalRequest
+	}
+
+	id, err := s.approvalRepo.CreateApprovalRequest(ctx, request)
+	if err != nil {
+		log.Errorf("ApprovalService.CreateApprovalRequest - s.approvalRepo.CreateApprovalRequest: %v", err)
+		return 0, ErrCannotCreateApprovalRequest
+	}
+
+	return id, nil
+}
+
+func (s *ApprovalService) GetApprovalRequest(ctx context.Context, id int) (entity.ApprovalRequest, []entity.ApprovalEvent, error) {
+	request, err := s.approvalRepo.GetApprovalRequestById(ctx, id)
+	if err != nil {
+		if errors.Is(err, repoerrs.ErrNotFound) {
+			return entity.ApprovalRequest{}, nil, ErrApprovalRequestNotFound
+		}
+		return entity.ApprovalRequest{}, nil, err
+	}
+
+	events, err := s.approvalRepo.GetApprovalEvents(ctx, id)
+	if err != nil {
+		return entity.ApprovalRequest{}, nil, err
+	}
+
+	return request, events, nil
+}
+
+func (s *ApprovalService) GetApprovalRequests(ctx context.Context, status string) ([]entity.ApprovalRequest, error) {
+	return s.approvalRepo.GetApprovalRequests(ctx, status)
+}
+
+// ApproveRequest approves the request on behalf of the checker and executes the operation in the same transaction.
+// The operation is subject to the usual checks at the execution time, if it fails
+// the request is marked as failed with the error
+func (s *ApprovalService) ApproveRequest(ctx context.Context, input ApprovalResolveInput) (entity.ApprovalRequest, error) {
+	request, err := s.approvalRepo.GetApprovalRequestById(ctx, input.Id)
+	if err != nil {
+		return entity.ApprovalRequest{}, approvalRepoError(err)
+	}
+
+	var (
+		rejectReason string
+		fee          entity.Fee
+		limits       []entity.LimitRule
+		verification entity.VerificationCheck
+	)
+	if request.Status == entity.ApprovalStatusPending && request.OperationType == entity.OperationTypeWithdraw {
+		rejectReason, fee, limits, verification, err = s.checkWithdraw(ctx, request)
+		if err != nil {
+			return entity.ApprovalRequest{}, err
+		}
+	}
+
+	request, err = s.approvalRepo.ExecuteApprovalRequest(ctx, input.Id, input.CheckerId, input.Comment, rejectReason, fee, limits, verification)
+	if err != nil {
+		return entity.ApprovalRequest{}, approvalRepoError(err)
+	}
+
+	return request, nil
+}
+
+// checkWithdraw runs the checks of the approved withdrawal which need the services, the reason it cannot be executed
+// for is returned as rejectReason, the rolling window limits and the verification are then checked by the repo
+func (s *ApprovalService) checkWithdraw(ctx context.Context, request entity.ApprovalRequest) (string, entity.Fee, []entity.LimitRule, entity.VerificationCheck, error) {
+	accountId := *request.AccountId
+
+	limits, err := s.limitService.CheckLimits(ctx, accountId, entity.OperationTypeWithdraw, request.Amount)
+	if err != nil {
+		if errors.Is(err, ErrLimitExceeded) || errors.Is(err, ErrAccountNotFound) {
+			return err.Error(), entity.Fee{}, nil, entity.VerificationCheck{}, nil
+		}
+		return "", entity.Fee{}, nil, entity.VerificationCheck{}, err
+	}
+
+	verification, err := s.verificationService.CheckVerification(ctx, accountId, entity.OperationTypeWithdraw, request.Amount)
+	if err != nil {
+		if errors.Is(err, ErrVerificationRequired) || errors.Is(err, ErrLimitExceeded) {
+			return err.Error(), entity.Fee{}, nil, entity.VerificationCheck{}, nil
+		}
+		return "", entity.Fee{}, nil, entity.VerificationCheck{}, err
+	}
+
+	fee, err := s.feeService.CalculateFee(ctx, FeeCalculateInput{
+		AccountId:     accountId,
+		OperationType: entity.OperationTypeWithdraw,
+		Amount:        request.Amount,
+	})
+	if err != nil {
+		return "", entity.Fee{}, nil, entity.VerificationCheck{}, err
+	}
+
+	return "", fee, limits, verification, nil
+}
+
+func (s *ApprovalService) RejectRequest(ctx context.Context, input ApprovalResolveInput) error {
+	err := s.approvalRepo.RejectApprovalRequest(ctx, input.Id, input.CheckerId, input.Comment)
+	return approvalRepoError(err)
+}
+
+// ExpireStaleRequests marks pending requests past their expiration time as expired, it is run by the scheduler
+func (s *ApprovalService) ExpireStaleRequests(ctx context.Context) {
+	count, err := s.approvalRepo.ExpireApprovalRequests(ctx)
+	if err != nil {
+		log.Errorf("ApprovalService.ExpireStaleRequests - s.approvalRepo.ExpireApprovalRequests: %v", err)
+		return
+	}
+
+	if count > 0 {
+		log.Infof("%d approval requests expired", count)
+	}
+}
+
+func approvalRepoError(err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, repoerrs.ErrNotFound):
+		return ErrApprovalRequestNotFound
+	case errors.Is(err, repoerrs.ErrExpired):
+		return ErrApprovalRequestExpired
+	case errors.Is(err, repoerrs.ErrWrongStatus):
+		return ErrApprovalRequestNotPending
+	case errors.Is(err, repoerrs.ErrSameUser):
+		return ErrSelfApproval
+	default:
+		log.Errorf("approvalRepoError: %v", err)
+		return ErrCannotResolveApprovalRequest
+	}
+}
@@ -0,0 +1,222 @@
+package service
+
+import (
+	"account-management-service/internal/entity"
+	"account-management-service/internal/mocks/repomocks"
+	"account-management-service/internal/repo/repoerrs"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestApprovalService_ApproveRequest(t *testing.T) {
+	type args struct {
+		ctx   context.Context
+		input ApprovalResolveInput
+	}
+
+	type MockBehavior func(ap *repomocks.MockApproval, a *repomocks.MockAccount, l *repomocks.MockLimit, v *repomocks.MockVerification, f *repomocks.MockFee, args args)
+
+	accountId := 1
+	deposit := entity.ApprovalRequest{
+		Id:            1,
+		OperationType: entity.OperationTypeDeposit,
+		AccountId:     &accountId,
+		Amount:        100,
+		Status:        entity.ApprovalStatusPending,
+		MakerId:       1,
+	}
+	withdraw := entity.ApprovalRequest{
+		Id:            1,
+		OperationType: entity.OperationTypeWithdraw,
+		AccountId:     &accountId,
+		Amount:        100,
+		Status:        entity.ApprovalStatusPending,
+		MakerId:       1,
+	}
+
+	testCases := []struct {
+		name         string
+		args         args
+		mockBehavior MockBehavior
+		wantStatus   string
+		wantErr      error
+	}{
+		{
+			name: "OK",
+			args: args{
+				ctx:   context.Background(),
+				input: ApprovalResolveInput{Id: 1, CheckerId: 2, Comment: "checked"},
+			},
+			mockBehavior: func(ap *repomocks.MockApproval, a *repomocks.MockAccount, l *repomocks.MockLimit, v *repomocks.MockVerification, f *repomocks.MockFee, args args) {
+				ap.EXPECT().GetApprovalRequestById(args.ctx, 1).
+					Return(deposit, nil)
+				ap.EXPECT().ExecuteApprovalRequest(args.ctx, 1, 2, "checked", "", entity.Fee{}, []entity.LimitRule(nil), entity.VerificationCheck{}).
+					Return(entity.ApprovalRequest{Id: 1, Status: entity.ApprovalStatusExecuted}, nil)
+			},
+			wantStatus: entity.ApprovalStatusExecuted,
+		},
+		{
+			name: "OK: withdrawal is checked before the execution",
+			args: args{
+				ctx:   context.Background(),
+				input: ApprovalResolveInput{Id: 1, CheckerId: 2},
+			},
+			mockBehavior: func(ap *repomocks.MockApproval, a *repomocks.MockAccount, l *repomocks.MockLimit, v *repomocks.MockVerification, f *repomocks.MockFee, args args) {
+				ap.EXPECT().GetApprovalRequestById(args.ctx, 1).
+					Return(withdraw, nil)
+				a.EXPECT().GetAccountById(args.ctx, accountId).
+					Return(entity.Account{Id: 1, Type: entity.AccountTypePersonal}, nil).Times(3)
+				l.EXPECT().GetLimitRulesForAccount(args.ctx, accountId, entity.AccountTypePersonal, entity.OperationTypeWithdraw).
+					Return([]entity.LimitRule{{Scope: entity.LimitScopeGlobal, LimitType: entity.LimitTypeMaxTotal, Value: 1000, WindowSeconds: 86400}}, nil)
+				v.EXPECT().GetVerificationPoliciesForOperation(args.ctx, entity.OperationTypeWithdraw).
+					Return(nil, nil)
+				f.EXPECT().GetFeeRulesForOperation(args.ctx, entity.OperationTypeWithdraw, 0, entity.AccountTypePersonal).
+					Return([]entity.FeeRule{{Rate: 100, MinFee: 5}}, nil)
+				ap.EXPECT().ExecuteApprovalRequest(args.ctx, 1, 2, "", "", entity.Fee{AccountId: feeAccountId, Amount: 5},
+					[]entity.LimitRule{{Scope: entity.LimitScopeGlobal, LimitType: entity.LimitTypeMaxTotal, Value: 1000, WindowSeconds: 86400}}, entity.VerificationCheck{}).
+					Return(entity.ApprovalRequest{Id: 1, Status: entity.ApprovalStatusExecuted}, nil)
+			},
+			wantStatus: entity.ApprovalStatusExecuted,
+		},
+		{
+			name: "withdrawal limit exceeded is recorded as failed request",
+			args: args{
+				ctx:   context.Background(),
+				input: ApprovalResolveInput{Id: 1, CheckerId: 2},
+			},
+			mockBehavior: func(ap *repomocks.MockApproval, a *repomocks.MockAccount, l *repomocks.MockLimit, v *repomocks.MockVerification, f *repomocks.MockFee, args args) {
+				ap.EXPECT().GetApprovalRequestById(args.ctx, 1).
+					Return(withdraw, nil)
+				a.EXPECT().GetAccountById(args.ctx, accountId).
+					Return(entity.Account{Id: 1, Type: entity.AccountTypePersonal}, nil)
+				l.EXPECT().GetLimitRulesForAccount(args.ctx, accountId, entity.AccountTypePersonal, entity.OperationTypeWithdraw).
+					Return([]entity.LimitRule{{Scope: entity.LimitScopeGlobal, LimitType: entity.LimitTypeMaxAmount, Value: 50}}, nil)
+				ap.EXPECT().ExecuteApprovalRequest(args.ctx, 1, 2, "", "limit exceeded: withdraw amount must not exceed 50", entity.Fee{}, []entity.LimitRule(nil), entity.VerificationCheck{}).
+					Return(entity.ApprovalRequest{Id: 1, Status: entity.ApprovalStatusFailed}, nil)
+			},
+			wantStatus: entity.ApprovalStatusFailed,
+		},
+		{
+			name: "withdrawal limits cannot be checked",
+			args: args{
+				ctx:   context.Background(),
+				input: ApprovalResolveInput{Id: 1, CheckerId: 2},
+			},
+			mockBehavior: func(ap *repomocks.MockApproval, a *repomocks.MockAccount, l *repomocks.MockLimit, v *repomocks.MockVerification, f *repomocks.MockFee, args args) {
+				ap.EXPECT().GetApprovalRequestById(args.ctx, 1).
+					Return(withdraw, nil)
+				a.EXPECT().GetAccountById(args.ctx, accountId).
+					Return(entity.Account{}, errors.New("some error"))
+			},
+			wantErr: ErrCannotCheckLimits,
+		},
+		{
+			name: "operation fails",
+			args: args{
+				ctx:   context.Background(),
+				input: ApprovalResolveInput{Id: 1, CheckerId: 2},
+			},
+			mockBehavior: func(ap *repomocks.MockApproval, a *repomocks.MockAccount, l *repomocks.MockLimit, v *repomocks.MockVerification, f *repomocks.MockFee, args args) {
+				ap.EXPECT().GetApprovalRequestById(args.ctx, 1).
+					Return(deposit, nil)
+				ap.EXPECT().ExecuteApprovalRequest(args.ctx, 1, 2, "", "", entity.Fee{}, []entity.LimitRule(nil), entity.VerificationCheck{}).
+					Return(entity.ApprovalRequest{Id: 1, Status: entity.ApprovalStatusFailed, Error: repoerrs.ErrAccountFrozen.Error()}, nil)
+			},
+			wantStatus: entity.ApprovalStatusFailed,
+		},
+		{
+			name: "request not found",
+			args: args{
+				ctx:   context.Background(),
+				input: ApprovalResolveInput{Id: 1, CheckerId: 2},
+			},
+			mockBehavior: func(ap *repomocks.MockApproval, a *repomocks.MockAccount, l *repomocks.MockLimit, v *repomocks.MockVerification, f *repomocks.MockFee, args args) {
+				ap.EXPECT().GetApprovalRequestById(args.ctx, 1).
+					Return(entity.ApprovalRequest{}, repoerrs.ErrNotFound)
+			},
+			wantErr: ErrApprovalRequestNotFound,
+		},
+		{
+			name: "maker approves own request",
+			args: args{
+				ctx:   context.Background(),
+				input: ApprovalResolveInput{Id: 1, CheckerId: 1},
+			},
+			mockBehavior: func(ap *repomocks.MockApproval, a *repomocks.MockAccount, l *repomocks.MockLimit, v *repomocks.MockVerification, f *repomocks.MockFee, args args) {
+				ap.EXPECT().GetApprovalRequestById(args.ctx, 1).
+					Return(deposit, nil)
+				ap.EXPECT().ExecuteApprovalRequest(args.ctx, 1, 1, "", "", entity.Fee{}, []entity.LimitRule(nil), entity.VerificationCheck{}).
+					Return(entity.ApprovalRequest{}, fmt.Errorf("ApprovalRepo.ExecuteApprovalRequest: %w", repoerrs.ErrSameUser))
+			},
+			wantErr: ErrSelfApproval,
+		},
+		{
+			name: "request expired",
+			args: args{
+				ctx:   context.Background(),
+				input: ApprovalResolveInput{Id: 1, CheckerId: 2},
+			},
+			mockBehavior: func(ap *repomocks.MockApproval, a *repomocks.MockAccount, l *repomocks.MockLimit, v *repomocks.MockVerification, f *repomocks.MockFee, args args) {
+				ap.EXPECT().GetApprovalRequestById(args.ctx, 1).
+					Return(deposit, nil)
+				ap.EXPECT().ExecuteApprovalRequest(args.ctx, 1, 2, "", "", entity.Fee{}, []entity.LimitRule(nil), entity.VerificationCheck{}).
+					Return(entity.ApprovalRequest{}, fmt.Errorf("ApprovalRepo.ExecuteApprovalRequest: %w", repoerrs.ErrExpired))
+			},
+			wantErr: ErrApprovalRequestExpired,
+		},
+		{
+			name: "request already resolved",
+			args: args{
+				ctx:   context.Background(),
+				input: ApprovalResolveInput{Id: 1, CheckerId: 2},
+			},
+			mockBehavior: func(ap *repomocks.MockApproval, a *repomocks.MockAccount, l *repomocks.MockLimit, v *repomocks.MockVerification, f *repomocks.MockFee, args args) {
+				// the withdrawal of a resolved request is not checked again
+				resolved := withdraw
+				resolved.Status = entity.ApprovalStatusExecuted
+				ap.EXPECT().GetApprovalRequestById(args.ctx, 1).
+					Return(resolved, nil)
+				ap.EXPECT().ExecuteApprovalRequest(args.ctx, 1, 2, "", "", entity.Fee{}, []entity.LimitRule(nil), entity.VerificationCheck{}).
+					Return(entity.ApprovalRequest{}, fmt.Errorf("ApprovalRepo.ExecuteApprovalRequest: %w", repoerrs.ErrWrongStatus))
+			},
+			wantErr: ErrApprovalRequestNotPending,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// init deps
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			// init mocks
+			approvalRepo := repomocks.NewMockApproval(ctrl)
+			accountRepo := repomocks.NewMockAccount(ctrl)
+			limitRepo := repomocks.NewMockLimit(ctrl)
+			verificationRepo := repomocks.NewMockVerification(ctrl)
+			feeRepo := repomocks.NewMockFee(ctrl)
+			tc.mockBehavior(approvalRepo, accountRepo, limitRepo, verificationRepo, feeRepo, tc.args)
+
+			// init service
+			limitService := NewLimitService(limitRepo, accountRepo)
+			verificationService := NewVerificationService(verificationRepo, accountRepo)
+			feeService := NewFeeService(feeRepo, accountRepo, nil, feeAccountId)
+			s := NewApprovalService(approvalRepo, limitService, verificationService, feeService, map[string]int{entity.OperationTypeDeposit: 0}, time.Hour)
+
+			// run test
+			request, err := s.ApproveRequest(tc.args.ctx, tc.args.input)
+			if tc.wantErr != nil {
+				assert.ErrorIs(t, err, tc.wantErr)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.wantStatus, request.Status)
+		})
+	}
+}
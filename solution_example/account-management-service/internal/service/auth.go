@@ -101,3 +101,41 @@ func (s *AuthService) ParseToken(accessToken string) (int, error) {
 
 	return claims.UserId, nil
 }
+
+func (s *AuthService) GetUserRole(ctx context.Context, userId int) (string, error) {
+	user, err := s.userRepo.GetUserById(ctx, userId)
+	if err != nil {
+		if errors.Is(err, repoerrs.ErrNotFound) {
+			return "", ErrUserNotFound
+		}
+		log.Errorf("AuthService.GetUserRole - s.userRepo.GetUserById: %v", err)
+		return "", ErrCannotGetUser
+	}
+
+	return user.Role, nil
+}
+
+// GrantRoles grants the roles to the users by their usernames, it is the only way to get the first admin.
+// The roles are granted to the signed up users only, otherwise anyone could sign up with the username
+// and get the role. The users not signed up yet are skipped and get the role on the next start
+func (s *AuthService) GrantRoles(ctx context.Context, roles map[string]string) error {
+	for username, role := range roles {
+		switch role {
+		case entity.UserRoleUser, entity.UserRoleAdmin, entity.UserRoleIdentity, entity.UserRoleApprover:
+		default:
+			return fmt.Errorf("%w %q of user %s", ErrUnknownUserRole, role, username)
+		}
+
+		err := s.userRepo.SetUserRole(ctx, username, role)
+		if err != nil {
+			if errors.Is(err, repoerrs.ErrNotFound) {
+				log.Warnf("user %s is not signed up, the role %s is not granted", username, role)
+				continue
+			}
+			return fmt.Errorf("AuthService.GrantRoles - s.userRepo.SetUserRole: %v", err)
+		}
+		log.Infof("role %s is granted to user %s", role, username)
+	}
+
+	return nil
+}
@@ -0,0 +1,99 @@
+package service
+
+import (
+	"account-management-service/internal/entity"
+	"account-management-service/internal/mocks/repomocks"
+	"account-management-service/internal/repo/repoerrs"
+	"context"
+	"errors"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestAuthService_GrantRoles(t *testing.T) {
+	type args struct {
+		ctx   context.Context
+		roles map[string]string
+	}
+
+	type MockBehavior func(u *repomocks.MockUser, args args)
+
+	testCases := []struct {
+		name         string
+		args         args
+		mockBehavior MockBehavior
+		wantErr      bool
+	}{
+		{
+			name: "OK",
+			args: args{
+				ctx:   context.Background(),
+				roles: map[string]string{"admin": entity.UserRoleAdmin},
+			},
+			mockBehavior: func(u *repomocks.MockUser, args args) {
+				u.EXPECT().SetUserRole(args.ctx, "admin", entity.UserRoleAdmin).
+					Return(nil)
+			},
+			wantErr: false,
+		},
+		{
+			name: "user not signed up is skipped",
+			args: args{
+				ctx:   context.Background(),
+				roles: map[string]string{"admin": entity.UserRoleAdmin},
+			},
+			mockBehavior: func(u *repomocks.MockUser, args args) {
+				u.EXPECT().SetUserRole(args.ctx, "admin", entity.UserRoleAdmin).
+					Return(repoerrs.ErrNotFound)
+			},
+			wantErr: false,
+		},
+		{
+			name: "unknown role",
+			args: args{
+				ctx:   context.Background(),
+				roles: map[string]string{"admin": "root"},
+			},
+			mockBehavior: func(u *repomocks.MockUser, args args) {},
+			wantErr:      true,
+		},
+		{
+			name: "set role error",
+			args: args{
+				ctx:   context.Background(),
+				roles: map[string]string{"approver": entity.UserRoleApprover},
+			},
+			mockBehavior: func(u *repomocks.MockUser, args args) {
+				u.EXPECT().SetUserRole(args.ctx, "approver", entity.UserRoleApprover).
+					Return(errors.New("some error"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// init deps
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			// init mocks
+			userRepo := repomocks.NewMockUser(ctrl)
+			tc.mockBehavior(userRepo, tc.args)
+
+			// init service
+			s := NewAuthService(userRepo, nil, "secret", time.Hour)
+
+			// run test
+			err := s.GrantRoles(tc.args.ctx, tc.args.roles)
+			if tc.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+		})
+	}
+}
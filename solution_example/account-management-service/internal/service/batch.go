@@ -0,0 +1,192 @@
+package service
+
+import (
+	"account-management-service/internal/entity"
+	"account-management-service/internal/repo"
+	"account-management-service/internal/repo/repoerrs"
+	"context"
+	"errors"
+	"fmt"
+	log "github.com/sirupsen/logrus"
+)
+
+type BatchService struct {
+	batchRepo           repo.Batch
+	approvalService     Approval
+	limitService        Limit
+	verificationService Verification
+	feeService          Fee
+	chunkSize           int
+	maxItems            int
+}
+
+func NewBatchService(batchRepo repo.Batch, approvalService Approval, limitService Limit, verificationService Verification, feeService Fee, chunkSize, maxItems int) *BatchService {
+	return &BatchService{
+		batchRepo:           batchRepo,
+		approvalService:     approvalService,
+		limitService:        limitService,
+		verificationService: verificationService,
+		feeService:          feeService,
+		chunkSize:           chunkSize,
+		maxItems:            maxItems,
+	}
+}
+
+// ExecuteBatch validates all the items first and refuses the whole batch if any of them is malformed.
+// In atomic mode the batch is executed in a single transaction and is rolled back on the first failed item,
+// in best effort mode it is executed in chunks, each in its own transaction, and failed items are just reported.
+// Transfers are subject to the sender limits, verification and fees like a single transfer.
+// Deposits which need approval are refused, they must be submitted one by one to be approved by another user
+func (s *BatchService) ExecuteBatch(ctx context.Context, input BatchExecuteInput) ([]entity.BatchItemResult, error) {
+	if len(input.Items) == 0 || len(input.Items) > s.maxItems {
+		return nil, ErrInvalidBatchSize
+	}
+
+	results, ok := s.validateBatchItems(input.Items)
+	if !ok {
+		return results, ErrInvalidBatch
+	}
+
+	for i := range input.Items {
+		if input.Items[i].Type != entity.BatchItemTypeTransfer {
+			continue
+		}
+		if err := s.checkTransfer(ctx, &input.Items[i]); err != nil {
+			log.Errorf("BatchService.ExecuteBatch - s.checkTransfer: %v", err)
+			return nil, ErrCannotExecuteBatch
+		}
+	}
+
+	if input.Mode == entity.BatchModeAtomic {
+		results, err := s.batchRepo.ExecuteBatch(ctx, input.Items, input.Description, true)
+		if err != nil {
+			if errors.Is(err, repoerrs.ErrBatchItemFailed) {
+				return results, ErrBatchRolledBack
+			}
+			log.Errorf("BatchService.ExecuteBatch - s.batchRepo.ExecuteBatch: %v", err)
+			return nil, ErrCannotExecuteBatch
+		}
+		return results, nil
+	}
+
+	results = make([]entity.BatchItemResult, 0, len(input.Items))
+	for offset := 0; offset < len(input.Items); offset += s.chunkSize {
+		end := offset + s.chunkSize
+		if end > len(input.Items) {
+			end = len(input.Items)
+		}
+
+		chunkResults, err := s.batchRepo.ExecuteBatch(ctx, input.Items[offset:end], input.Description, false)
+		if err != nil {
+			log.Errorf("BatchService.ExecuteBatch - s.batchRepo.ExecuteBatch: %v", err)
+			for i := offset; i < end; i++ {
+				results = append(results, entity.BatchItemResult{
+					Index:  i,
+					Status: entity.BatchItemStatusFailed,
+					Error:  ErrCannotExecuteBatch.Error(),
+				})
+			}
+			continue
+		}
+
+		for _, result := range chunkResults {
+			result.Index += offset
+			results = append(results, result)
+		}
+	}
+
+	return results, nil
+}
+
+// validateBatchItems checks the items without touching the database, normalizing deposit wallets on the way.
+// Valid items of an invalid batch are reported as skipped
+func (s *BatchService) validateBatchItems(items []entity.BatchItem) ([]entity.BatchItemResult, bool) {
+	results := make([]entity.BatchItemResult, len(items))
+	ok := true
+
+	for i := range items {
+		results[i] = entity.BatchItemResult{Index: i, Status: entity.BatchItemStatusSkipped}
+
+		err := validateBatchItem(&items[i])
+		if err == nil && items[i].Type == entity.BatchItemTypeDeposit &&
+			s.approvalService.RequiresApproval(entity.OperationTypeDeposit, items[i].Amount) {
+			err = fmt.Errorf("deposit of %d requires approval", items[i].Amount)
+		}
+		if err != nil {
+			results[i].Status = entity.BatchItemStatusFailed
+			results[i].Error = err.Error()
+			ok = false
+		}
+	}
+
+	return results, ok
+}
+
+// checkTransfer finds the fee, limits and verification of the transfer, the reason it cannot be executed for
+// is set as its RejectReason, the rolling window totals are then checked by the repo
+func (s *BatchService) checkTransfer(ctx context.Context, item *entity.BatchItem) error {
+	limits, err := s.limitService.CheckLimits(ctx, item.From, entity.OperationTypeTransferFrom, item.Amount)
+	if err != nil {
+		if errors.Is(err, ErrLimitExceeded) || errors.Is(err, ErrAccountNotFound) {
+			item.RejectReason = fmt.Sprintf("account %d: %v", item.From, err)
+			return nil
+		}
+		return err
+	}
+
+	verification, err := s.verificationService.CheckVerification(ctx, item.From, entity.OperationTypeTransferFrom, item.Amount)
+	if err != nil {
+		if errors.Is(err, ErrVerificationRequired) || errors.Is(err, ErrLimitExceeded) {
+			item.RejectReason = fmt.Sprintf("account %d: %v", item.From, err)
+			return nil
+		}
+		return err
+	}
+
+	fee, err := s.feeService.CalculateFee(ctx, FeeCalculateInput{
+		AccountId:     item.From,
+		OperationType: entity.OperationTypeTransferFrom,
+		Amount:        item.Amount,
+	})
+	if err != nil {
+		return err
+	}
+
+	item.Limits = limits
+	item.Verification = verification
+	item.Fee = fee
+	return nil
+}
+
+func validateBatchItem(item *entity.BatchItem) error {
+	if item.Amount <= 0 {
+		return fmt.Errorf("amount must be positive")
+	}
+
+	switch item.Type {
+	case entity.BatchItemTypeDeposit:
+		if item.AccountId <= 0 {
+			return fmt.Errorf("account_id is required")
+		}
+		if item.Wallet == "" {
+			item.Wallet = entity.WalletTypeMain
+		}
+		if item.Wallet != entity.WalletTypeMain && item.Wallet != entity.WalletTypeBonus {
+			return fmt.Errorf("unknown wallet %q", item.Wallet)
+		}
+	case entity.BatchItemTypeTransfer:
+		if item.From <= 0 || item.To <= 0 {
+			return fmt.Errorf("from and to are required")
+		}
+		if item.From == item.To {
+			return fmt.Errorf("cannot transfer to the same account")
+		}
+		if item.Wallet != "" && item.Wallet != entity.WalletTypeMain {
+			return fmt.Errorf("only main wallet money can be transferred")
+		}
+	default:
+		return fmt.Errorf("unknown item type %q", item.Type)
+	}
+
+	return nil
+}
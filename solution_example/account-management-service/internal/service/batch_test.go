@@ -0,0 +1,248 @@
+package service
+
+import (
+	"account-management-service/internal/entity"
+	"account-management-service/internal/mocks/repomocks"
+	"account-management-service/internal/repo/repoerrs"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestBatchService_ExecuteBatch(t *testing.T) {
+	type args struct {
+		ctx   context.Context
+		input BatchExecuteInput
+	}
+
+	type MockBehavior func(b *repomocks.MockBatch, a *repomocks.MockAccount, l *repomocks.MockLimit, v *repomocks.MockVerification, f *repomocks.MockFee, args args)
+
+	deposit := entity.BatchItem{Type: entity.BatchItemTypeDeposit, AccountId: 1, Amount: 100, Wallet: entity.WalletTypeMain}
+	transfer := entity.BatchItem{Type: entity.BatchItemTypeTransfer, From: 1, To: 2, Amount: 50}
+
+	// the transfer is passed to the repo with its fee and window rules
+	checked := transfer
+	checked.Fee = entity.Fee{AccountId: feeAccountId, Amount: 5}
+	checked.Limits = []entity.LimitRule{{Scope: entity.LimitScopeGlobal, LimitType: entity.LimitTypeMaxTotal, Value: 1000, WindowSeconds: 86400}}
+
+	expectTransferChecks := func(a *repomocks.MockAccount, l *repomocks.MockLimit, v *repomocks.MockVerification, f *repomocks.MockFee, ctx context.Context) {
+		a.EXPECT().GetAccountById(ctx, transfer.From).
+			Return(entity.Account{Id: 1, Type: entity.AccountTypePersonal}, nil).Times(3)
+		l.EXPECT().GetLimitRulesForAccount(ctx, transfer.From, entity.AccountTypePersonal, entity.OperationTypeTransferFrom).
+			Return(checked.Limits, nil)
+		v.EXPECT().GetVerificationPoliciesForOperation(ctx, entity.OperationTypeTransferFrom).
+			Return(nil, nil)
+		f.EXPECT().GetFeeRulesForOperation(ctx, entity.OperationTypeTransferFrom, 0, entity.AccountTypePersonal).
+			Return([]entity.FeeRule{{Rate: 100, MinFee: 5}}, nil)
+	}
+
+	testCases := []struct {
+		name         string
+		args         args
+		mockBehavior MockBehavior
+		want         []entity.BatchItemResult
+		wantErr      error
+	}{
+		{
+			name: "atomic OK",
+			args: args{
+				ctx: context.Background(),
+				input: BatchExecuteInput{
+					Mode:  entity.BatchModeAtomic,
+					Items: []entity.BatchItem{deposit, transfer},
+				},
+			},
+			mockBehavior: func(b *repomocks.MockBatch, a *repomocks.MockAccount, l *repomocks.MockLimit, v *repomocks.MockVerification, f *repomocks.MockFee, args args) {
+				expectTransferChecks(a, l, v, f, args.ctx)
+				b.EXPECT().ExecuteBatch(args.ctx, []entity.BatchItem{deposit, checked}, "", true).
+					Return([]entity.BatchItemResult{
+						{Index: 0, Status: entity.BatchItemStatusSucceeded},
+						{Index: 1, Status: entity.BatchItemStatusSucceeded},
+					}, nil)
+			},
+			want: []entity.BatchItemResult{
+				{Index: 0, Status: entity.BatchItemStatusSucceeded},
+				{Index: 1, Status: entity.BatchItemStatusSucceeded},
+			},
+			wantErr: nil,
+		},
+		{
+			name: "invalid item",
+			args: args{
+				ctx: context.Background(),
+				input: BatchExecuteInput{
+					Mode:  entity.BatchModeAtomic,
+					Items: []entity.BatchItem{deposit, {Type: entity.BatchItemTypeTransfer, From: 1, To: 1, Amount: 50}},
+				},
+			},
+			mockBehavior: func(b *repomocks.MockBatch, a *repomocks.MockAccount, l *repomocks.MockLimit, v *repomocks.MockVerification, f *repomocks.MockFee, args args) {
+			},
+			want: []entity.BatchItemResult{
+				{Index: 0, Status: entity.BatchItemStatusSkipped},
+				{Index: 1, Status: entity.BatchItemStatusFailed, Error: "cannot transfer to the same account"},
+			},
+			wantErr: ErrInvalidBatch,
+		},
+		{
+			name: "deposit requires approval",
+			args: args{
+				ctx: context.Background(),
+				input: BatchExecuteInput{
+					Mode:  entity.BatchModeBestEffort,
+					Items: []entity.BatchItem{deposit, {Type: entity.BatchItemTypeDeposit, AccountId: 2, Amount: 1000}},
+				},
+			},
+			mockBehavior: func(b *repomocks.MockBatch, a *repomocks.MockAccount, l *repomocks.MockLimit, v *repomocks.MockVerification, f *repomocks.MockFee, args args) {
+			},
+			want: []entity.BatchItemResult{
+				{Index: 0, Status: entity.BatchItemStatusSkipped},
+				{Index: 1, Status: entity.BatchItemStatusFailed, Error: "deposit of 1000 requires approval"},
+			},
+			wantErr: ErrInvalidBatch,
+		},
+		{
+			name: "transfer over the limit is rejected",
+			args: args{
+				ctx: context.Background(),
+				input: BatchExecuteInput{
+					Mode:  entity.BatchModeBestEffort,
+					Items: []entity.BatchItem{transfer},
+				},
+			},
+			mockBehavior: func(b *repomocks.MockBatch, a *repomocks.MockAccount, l *repomocks.MockLimit, v *repomocks.MockVerification, f *repomocks.MockFee, args args) {
+				a.EXPECT().GetAccountById(args.ctx, transfer.From).
+					Return(entity.Account{Id: 1, Type: entity.AccountTypePersonal}, nil)
+				l.EXPECT().GetLimitRulesForAccount(args.ctx, transfer.From, entity.AccountTypePersonal, entity.OperationTypeTransferFrom).
+					Return([]entity.LimitRule{{Scope: entity.LimitScopeGlobal, LimitType: entity.LimitTypeMaxAmount, Value: 10}}, nil)
+
+				rejected := transfer
+				rejected.RejectReason = "account 1: limit exceeded: transfer_from amount must not exceed 10"
+				b.EXPECT().ExecuteBatch(args.ctx, []entity.BatchItem{rejected}, "", false).
+					Return([]entity.BatchItemResult{
+						{Index: 0, Status: entity.BatchItemStatusFailed, Error: rejected.RejectReason},
+					}, nil)
+			},
+			want: []entity.BatchItemResult{
+				{Index: 0, Status: entity.BatchItemStatusFailed, Error: "account 1: limit exceeded: transfer_from amount must not exceed 10"},
+			},
+			wantErr: nil,
+		},
+		{
+			name: "transfer limits cannot be checked",
+			args: args{
+				ctx: context.Background(),
+				input: BatchExecuteInput{
+					Mode:  entity.BatchModeAtomic,
+					Items: []entity.BatchItem{deposit, transfer},
+				},
+			},
+			mockBehavior: func(b *repomocks.MockBatch, a *repomocks.MockAccount, l *repomocks.MockLimit, v *repomocks.MockVerification, f *repomocks.MockFee, args args) {
+				a.EXPECT().GetAccountById(args.ctx, transfer.From).
+					Return(entity.Account{}, errors.New("some error"))
+			},
+			want:    nil,
+			wantErr: ErrCannotExecuteBatch,
+		},
+		{
+			name: "too many items",
+			args: args{
+				ctx: context.Background(),
+				input: BatchExecuteInput{
+					Mode:  entity.BatchModeAtomic,
+					Items: []entity.BatchItem{deposit, deposit, deposit, deposit, deposit},
+				},
+			},
+			mockBehavior: func(b *repomocks.MockBatch, a *repomocks.MockAccount, l *repomocks.MockLimit, v *repomocks.MockVerification, f *repomocks.MockFee, args args) {
+			},
+			want:    nil,
+			wantErr: ErrInvalidBatchSize,
+		},
+		{
+			name: "atomic rolled back",
+			args: args{
+				ctx: context.Background(),
+				input: BatchExecuteInput{
+					Mode:  entity.BatchModeAtomic,
+					Items: []entity.BatchItem{deposit, transfer},
+				},
+			},
+			mockBehavior: func(b *repomocks.MockBatch, a *repomocks.MockAccount, l *repomocks.MockLimit, v *repomocks.MockVerification, f *repomocks.MockFee, args args) {
+				expectTransferChecks(a, l, v, f, args.ctx)
+				b.EXPECT().ExecuteBatch(args.ctx, []entity.BatchItem{deposit, checked}, "", true).
+					Return([]entity.BatchItemResult{
+						{Index: 0, Status: entity.BatchItemStatusSkipped},
+						{Index: 1, Status: entity.BatchItemStatusFailed, Error: "account 1: not enough balance"},
+					}, repoerrs.ErrBatchItemFailed)
+			},
+			want: []entity.BatchItemResult{
+				{Index: 0, Status: entity.BatchItemStatusSkipped},
+				{Index: 1, Status: entity.BatchItemStatusFailed, Error: "account 1: not enough balance"},
+			},
+			wantErr: ErrBatchRolledBack,
+		},
+		{
+			name: "best effort in chunks",
+			args: args{
+				ctx: context.Background(),
+				input: BatchExecuteInput{
+					Mode:  entity.BatchModeBestEffort,
+					Items: []entity.BatchItem{deposit, transfer, deposit},
+				},
+			},
+			mockBehavior: func(b *repomocks.MockBatch, a *repomocks.MockAccount, l *repomocks.MockLimit, v *repomocks.MockVerification, f *repomocks.MockFee, args args) {
+				expectTransferChecks(a, l, v, f, args.ctx)
+				b.EXPECT().ExecuteBatch(args.ctx, []entity.BatchItem{deposit, checked}, "", false).
+					Return([]entity.BatchItemResult{
+						{Index: 0, Status: entity.BatchItemStatusSucceeded},
+						{Index: 1, Status: entity.BatchItemStatusFailed, Error: "account 1: not enough balance"},
+					}, nil)
+				b.EXPECT().ExecuteBatch(args.ctx, []entity.BatchItem{deposit}, "", false).
+					Return(nil, fmt.Errorf("BatchRepo.ExecuteBatch - tx.Commit: some error"))
+			},
+			want: []entity.BatchItemResult{
+				{Index: 0, Status: entity.BatchItemStatusSucceeded},
+				{Index: 1, Status: entity.BatchItemStatusFailed, Error: "account 1: not enough balance"},
+				{Index: 2, Status: entity.BatchItemStatusFailed, Error: ErrCannotExecuteBatch.Error()},
+			},
+			wantErr: nil,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// init deps
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			// init mocks
+			batchRepo := repomocks.NewMockBatch(ctrl)
+			accountRepo := repomocks.NewMockAccount(ctrl)
+			limitRepo := repomocks.NewMockLimit(ctrl)
+			verificationRepo := repomocks.NewMockVerification(ctrl)
+			feeRepo := repomocks.NewMockFee(ctrl)
+			tc.mockBehavior(batchRepo, accountRepo, limitRepo, verificationRepo, feeRepo, tc.args)
+
+			// init service
+			approvalService := NewApprovalService(nil, nil, nil, nil, map[string]int{entity.OperationTypeDeposit: 1000}, time.Hour)
+			limitService := NewLimitService(limitRepo, accountRepo)
+			verificationService := NewVerificationService(verificationRepo, accountRepo)
+			feeService := NewFeeService(feeRepo, accountRepo, nil, feeAccountId)
+			s := NewBatchService(batchRepo, approvalService, limitService, verificationService, feeService, 2, 4)
+
+			// run test
+			got, err := s.ExecuteBatch(tc.args.ctx, tc.args.input)
+			if tc.wantErr != nil {
+				assert.ErrorIs(t, err, tc.wantErr)
+				assert.Equal(t, tc.want, got)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
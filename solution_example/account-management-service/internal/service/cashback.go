@@ -0,0 +1,65 @@
+package service
+
+import (
+	"account-management-service/internal/entity"
+	"account-management-service/internal/repo"
+	"account-management-service/internal/repo/repoerrs"
+	"context"
+	"errors"
+	log "github.com/sirupsen/logrus"
+	"time"
+)
+
+type CashbackService struct {
+	cashbackRepo repo.Cashback
+}
+
+func NewCashbackService(cashbackRepo repo.Cashback) *CashbackService {
+	return &CashbackService{cashbackRepo: cashbackRepo}
+}
+
+// CreateCashbackRule creates a rule valid from ValidFrom (now if not set) until ValidTo (forever if not set).
+// When several rules of the product are valid at once, the latest one is applied
+func (s *CashbackService) CreateCashbackRule(ctx context.Context, input CashbackCreateRuleInput) (int, error) {
+	if input.ProductId <= 0 || input.Rate <= 0 || input.Rate > 10000 || input.Cap < 0 {
+		return 0, ErrInvalidCashbackRule
+	}
+
+	validFrom := input.ValidFrom
+	if validFrom.IsZero() {
+		validFrom = time.Now()
+	}
+	if input.ValidTo != nil && !input.ValidTo.After(validFrom) {
+		return 0, ErrInvalidCashbackRule
+	}
+
+	id, err := s.cashbackRepo.CreateCashbackRule(ctx, entity.CashbackRule{
+		ProductId: input.ProductId,
+		Rate:      input.Rate,
+		Cap:       input.Cap,
+		ValidFrom: validFrom,
+		ValidTo:   input.ValidTo,
+	})
+	if err != nil {
+		log.Errorf("CashbackService.CreateCashbackRule - s.cashbackRepo.CreateCashbackRule: %v", err)
+		return 0, ErrCannotCreateCashbackRule
+	}
+
+	return id, nil
+}
+
+func (s *CashbackService) GetAllCashbackRules(ctx context.Context) ([]entity.CashbackRule, error) {
+	return s.cashbackRepo.GetAllCashbackRules(ctx)
+}
+
+func (s *CashbackService) DeleteCashbackRule(ctx context.Context, id int) error {
+	err := s.cashbackRepo.DeleteCashbackRule(ctx, id)
+	if err != nil {
+		if errors.Is(err, repoerrs.ErrNotFound) {
+			return ErrCashbackRuleNotFound
+		}
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,120 @@
+package service
+
+import (
+	"account-management-service/internal/entity"
+	"account-management-service/internal/mocks/repomocks"
+	"context"
+	"errors"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestCashbackService_CreateCashbackRule(t *testing.T) {
+	type args struct {
+		ctx   context.Context
+		input CashbackCreateRuleInput
+	}
+
+	type MockBehavior func(c *repomocks.MockCashback, args args)
+
+	validFrom := time.Date(2022, 11, 1, 0, 0, 0, 0, time.UTC)
+	validTo := validFrom.AddDate(0, 1, 0)
+
+	testCases := []struct {
+		name         string
+		args         args
+		mockBehavior MockBehavior
+		want         int
+		wantErr      error
+	}{
+		{
+			name: "OK",
+			args: args{
+				ctx: context.Background(),
+				input: CashbackCreateRuleInput{
+					ProductId: 1,
+					Rate:      500,
+					Cap:       1000,
+					ValidFrom: validFrom,
+					ValidTo:   &validTo,
+				},
+			},
+			mockBehavior: func(c *repomocks.MockCashback, args args) {
+				c.EXPECT().CreateCashbackRule(args.ctx, entity.CashbackRule{
+					ProductId: 1,
+					Rate:      500,
+					Cap:       1000,
+					ValidFrom: validFrom,
+					ValidTo:   &validTo,
+				}).Return(1, nil)
+			},
+			want:    1,
+			wantErr: nil,
+		},
+		{
+			name: "rate above 100%",
+			args: args{
+				ctx:   context.Background(),
+				input: CashbackCreateRuleInput{ProductId: 1, Rate: 10001},
+			},
+			mockBehavior: func(c *repomocks.MockCashback, args args) {},
+			want:         0,
+			wantErr:      ErrInvalidCashbackRule,
+		},
+		{
+			name: "validity period ends before start",
+			args: args{
+				ctx: context.Background(),
+				input: CashbackCreateRuleInput{
+					ProductId: 1,
+					Rate:      500,
+					ValidFrom: validTo,
+					ValidTo:   &validFrom,
+				},
+			},
+			mockBehavior: func(c *repomocks.MockCashback, args args) {},
+			want:         0,
+			wantErr:      ErrInvalidCashbackRule,
+		},
+		{
+			name: "repo error",
+			args: args{
+				ctx:   context.Background(),
+				input: CashbackCreateRuleInput{ProductId: 1, Rate: 500, ValidFrom: validFrom},
+			},
+			mockBehavior: func(c *repomocks.MockCashback, args args) {
+				c.EXPECT().CreateCashbackRule(args.ctx, gomock.Any()).
+					Return(0, errors.New("some error"))
+			},
+			want:    0,
+			wantErr: ErrCannotCreateCashbackRule,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// init deps
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			// init mocks
+			cashbackRepo := repomocks.NewMockCashback(ctrl)
+			tc.mockBehavior(cashbackRepo, tc.args)
+
+			// init service
+			s := NewCashbackService(cashbackRepo)
+
+			// run test
+			got, err := s.CreateCashbackRule(tc.args.ctx, tc.args.input)
+			if tc.wantErr != nil {
+				assert.ErrorIs(t, err, tc.wantErr)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
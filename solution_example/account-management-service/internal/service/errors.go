@@ -10,11 +10,113 @@ var (
 	ErrCannotCreateUser  = fmt.Errorf("cannot create user")
 	ErrUserNotFound      = fmt.Errorf("user not found")
 	ErrCannotGetUser     = fmt.Errorf("cannot get user")
+	ErrUnknownUserRole   = fmt.Errorf("unknown user role")
 
 	ErrAccountAlreadyExists = fmt.Errorf("account already exists")
 	ErrCannotCreateAccount  = fmt.Errorf("cannot create account")
 	ErrAccountNotFound      = fmt.Errorf("account not found")
 	ErrCannotGetAccount     = fmt.Errorf("cannot get account")
+	ErrNotEnoughBalance     = fmt.Errorf("not enough balance")
+	ErrInvalidAmount        = fmt.Errorf("amount must be positive")
+
+	ErrAccountFrozen          = fmt.Errorf("account is frozen")
+	ErrAccountClosed          = fmt.Errorf("account is closed")
+	ErrAccountHasBalance      = fmt.Errorf("account has non-zero balance")
+	ErrAccountHasReservations = fmt.Errorf("account has active reservations")
+	ErrCannotChangeStatus     = fmt.Errorf("cannot change account status")
+
+	ErrCannotScheduleTransfer        = fmt.Errorf("cannot schedule transfer")
+	ErrScheduledTransferNotFound     = fmt.Errorf("scheduled transfer not found")
+	ErrCannotCancelScheduledTransfer = fmt.Errorf("scheduled transfer is not pending")
+
+	ErrCannotCreatePaymentRequest = fmt.Errorf("cannot create payment request")
+	ErrPaymentRequestNotFound     = fmt.Errorf("payment request not found")
+	ErrPaymentRequestNotPending   = fmt.Errorf("payment request is already resolved")
+	ErrPaymentRequestExpired      = fmt.Errorf("payment request has expired")
+
+	ErrInvalidVoucherCampaign = fmt.Errorf("invalid voucher campaign")
+	ErrCannotCreateCampaign   = fmt.Errorf("cannot create voucher campaign")
+	ErrCampaignNotFound       = fmt.Errorf("voucher campaign not found")
+	ErrVoucherNotFound        = fmt.Errorf("voucher not found")
+	ErrVoucherExpired         = fmt.Errorf("voucher has expired")
+	ErrVoucherUsedUp          = fmt.Errorf("voucher is used up")
+	ErrVoucherAlreadyRedeemed = fmt.Errorf("voucher is already redeemed by this account")
+	ErrCannotRedeemVoucher    = fmt.Errorf("cannot redeem voucher")
+
+	ErrInvalidBatchSize   = fmt.Errorf("invalid number of batch items")
+	ErrInvalidBatch       = fmt.Errorf("batch contains invalid items")
+	ErrBatchRolledBack    = fmt.Errorf("batch item failed, batch is rolled back")
+	ErrCannotExecuteBatch = fmt.Errorf("cannot execute batch")
 
 	ErrCannotCreateReservation = fmt.Errorf("cannot create reservation")
+
+	ErrCannotCreateReportLink = fmt.Errorf("cannot create report link")
+	ErrInvalidReportLink      = fmt.Errorf("invalid report link")
+	ErrReportLinkNotFound     = fmt.Errorf("report link not found")
+	ErrReportLinkExpired      = fmt.Errorf("report link expired")
+	ErrReportLinkUsed         = fmt.Errorf("report link has already been used")
+	ErrCannotDownloadReport   = fmt.Errorf("cannot download report")
+
+	ErrReportStorageUnavailable = fmt.Errorf("report storage is not available")
+	ErrCannotCreateReportJob    = fmt.Errorf("cannot create report job")
+	ErrReportJobNotFound        = fmt.Errorf("report job not found")
+	ErrUnknownReportVersion     = fmt.Errorf("unknown report version")
+	ErrUnknownReportMode        = fmt.Errorf("unknown report mode")
+	ErrInvalidReportParams      = fmt.Errorf("invalid report params")
+
+	ErrArchivedReportNotFound     = fmt.Errorf("archived report not found")
+	ErrCannotDeleteArchivedReport = fmt.Errorf("cannot delete archived report")
+
+	ErrInvalidAdjustment         = fmt.Errorf("invalid adjustment")
+	ErrUnknownAdjustmentReason   = fmt.Errorf("unknown or inactive adjustment reason")
+	ErrCannotCreateAdjustment    = fmt.Errorf("cannot create adjustment")
+	ErrCannotSetAdjustmentReason = fmt.Errorf("cannot set adjustment reason")
+
+	ErrInvalidApprovalRequest       = fmt.Errorf("invalid approval request")
+	ErrCannotCreateApprovalRequest  = fmt.Errorf("cannot create approval request")
+	ErrApprovalRequestNotFound      = fmt.Errorf("approval request not found")
+	ErrApprovalRequestNotPending    = fmt.Errorf("approval request is already resolved")
+	ErrApprovalRequestExpired       = fmt.Errorf("approval request has expired")
+	ErrSelfApproval                 = fmt.Errorf("request must be resolved by another user")
+	ErrCannotResolveApprovalRequest = fmt.Errorf("cannot resolve approval request")
+
+	ErrNotGroupMember            = fmt.Errorf("user is not a member of the account")
+	ErrGroupAccessDenied         = fmt.Errorf("member role does not allow this action")
+	ErrMemberLimitExceeded       = fmt.Errorf("member monthly limit exceeded")
+	ErrCannotChangeOwnMembership = fmt.Errorf("owner cannot change own membership")
+	ErrCannotCreateGroupAccount  = fmt.Errorf("cannot create group account")
+
+	ErrInvalidInstallmentPlan      = fmt.Errorf("invalid installment plan")
+	ErrCannotCreateInstallmentPlan = fmt.Errorf("cannot create installment plan")
+	ErrInstallmentPlanNotFound     = fmt.Errorf("installment plan not found")
+	ErrOrderAlreadyReserved        = fmt.Errorf("order already has a reservation or an installment plan")
+	ErrOutstandingInstallments     = fmt.Errorf("order has outstanding installments")
+
+	ErrLimitExceeded         = fmt.Errorf("limit exceeded")
+	ErrCannotCheckLimits     = fmt.Errorf("cannot check limits")
+	ErrInvalidLimitRule      = fmt.Errorf("invalid limit rule")
+	ErrCannotCreateLimitRule = fmt.Errorf("cannot create limit rule")
+	ErrLimitRuleNotFound     = fmt.Errorf("limit rule not found")
+
+	ErrVerificationRequired        = fmt.Errorf("verification required")
+	ErrCannotCheckVerification     = fmt.Errorf("cannot check verification")
+	ErrInvalidVerificationLevel    = fmt.Errorf("invalid verification level")
+	ErrCannotSetVerificationLevel  = fmt.Errorf("cannot set verification level")
+	ErrInvalidVerificationPolicy   = fmt.Errorf("invalid verification policy")
+	ErrCannotSetVerificationPolicy = fmt.Errorf("cannot set verification policy")
+	ErrVerificationPolicyNotFound  = fmt.Errorf("verification policy not found")
+
+	ErrInvalidFeeRule      = fmt.Errorf("invalid fee rule")
+	ErrCannotCreateFeeRule = fmt.Errorf("cannot create fee rule")
+	ErrFeeRuleNotFound     = fmt.Errorf("fee rule not found")
+	ErrCannotCalculateFee  = fmt.Errorf("cannot calculate fee")
+
+	ErrInvalidCashbackRule      = fmt.Errorf("invalid cashback rule")
+	ErrCannotCreateCashbackRule = fmt.Errorf("cannot create cashback rule")
+	ErrCashbackRuleNotFound     = fmt.Errorf("cashback rule not found")
+	ErrCapturedOrderNotFound    = fmt.Errorf("captured order not found or already refunded")
+
+	ErrCannotCreateSubscription       = fmt.Errorf("cannot create subscription")
+	ErrSubscriptionNotFound           = fmt.Errorf("subscription not found")
+	ErrCannotChangeSubscriptionStatus = fmt.Errorf("cannot change subscription status")
 )
@@ -0,0 +1,132 @@
+package service
+
+import (
+	"account-management-service/internal/entity"
+	"account-management-service/internal/repo"
+	"account-management-service/internal/repo/repoerrs"
+	"context"
+	"errors"
+	"fmt"
+	log "github.com/sirupsen/logrus"
+	"time"
+)
+
+// feeOperationTypes are the outgoing operations which may be charged a fee
+var feeOperationTypes = map[string]bool{
+	entity.OperationTypeWithdraw:     true,
+	entity.OperationTypeTransferFrom: true,
+	entity.OperationTypeReservation:  true,
+}
+
+type FeeService struct {
+	feeRepo       repo.Fee
+	accountRepo   repo.Account
+	operationRepo repo.Operation
+	feeAccountId  int
+}
+
+func NewFeeService(feeRepo repo.Fee, accountRepo repo.Account, operationRepo repo.Operation, feeAccountId int) *FeeService {
+	return &FeeService{
+		feeRepo:       feeRepo,
+		accountRepo:   accountRepo,
+		operationRepo: operationRepo,
+		feeAccountId:  feeAccountId,
+	}
+}
+
+func (s *FeeService) CreateFeeRule(ctx context.Context, input FeeCreateRuleInput) (int, error) {
+	if !feeOperationTypes[input.OperationType] {
+		return 0, ErrInvalidFeeRule
+	}
+	if input.Rate < 0 || input.Fixed < 0 || input.MinFee < 0 || input.MaxFee < 0 || input.FreeUpTo < 0 {
+		return 0, ErrInvalidFeeRule
+	}
+	if input.MaxFee > 0 && input.MinFee > input.MaxFee {
+		return 0, ErrInvalidFeeRule
+	}
+
+	rule := entity.FeeRule{
+		OperationType: input.OperationType,
+		Rate:          input.Rate,
+		Fixed:         input.Fixed,
+		MinFee:        input.MinFee,
+		MaxFee:        input.MaxFee,
+		FreeUpTo:      input.FreeUpTo,
+	}
+	if input.ProductId != 0 {
+		rule.ProductId = &input.ProductId
+	}
+	if input.AccountType != "" {
+		rule.AccountType = &input.AccountType
+	}
+
+	id, err := s.feeRepo.CreateFeeRule(ctx, rule)
+	if err != nil {
+		log.Errorf("FeeService.CreateFeeRule - s.feeRepo.CreateFeeRule: %v", err)
+		return 0, ErrCannotCreateFeeRule
+	}
+
+	return id, nil
+}
+
+func (s *FeeService) GetAllFeeRules(ctx context.Context) ([]entity.FeeRule, error) {
+	return s.feeRepo.GetAllFeeRules(ctx)
+}
+
+func (s *FeeService) DeleteFeeRule(ctx context.Context, id int) error {
+	err := s.feeRepo.DeleteFeeRule(ctx, id)
+	if err != nil {
+		if errors.Is(err, repoerrs.ErrNotFound) {
+			return ErrFeeRuleNotFound
+		}
+		return err
+	}
+
+	return nil
+}
+
+// CalculateFee returns the fee for the operation by the most specific matching rule,
+// operations of the fee account itself and operations without a matching rule are free
+func (s *FeeService) CalculateFee(ctx context.Context, input FeeCalculateInput) (entity.Fee, error) {
+	fee := entity.Fee{AccountId: s.feeAccountId}
+	if input.AccountId == s.feeAccountId {
+		return fee, nil
+	}
+
+	account, err := s.accountRepo.GetAccountById(ctx, input.AccountId)
+	if err != nil {
+		if errors.Is(err, repoerrs.ErrNotFound) {
+			return entity.Fee{}, ErrAccountNotFound
+		}
+		log.Errorf("FeeService.CalculateFee - s.accountRepo.GetAccountById: %v", err)
+		return entity.Fee{}, ErrCannotCalculateFee
+	}
+
+	rules, err := s.feeRepo.GetFeeRulesForOperation(ctx, input.OperationType, input.ProductId, account.Type)
+	if err != nil {
+		log.Errorf("FeeService.CalculateFee - s.feeRepo.GetFeeRulesForOperation: %v", err)
+		return entity.Fee{}, ErrCannotCalculateFee
+	}
+
+	if len(rules) > 0 {
+		fee.Amount = rules[0].Calculate(input.Amount)
+	}
+
+	return fee, nil
+}
+
+// GetFeeIncome returns the fees collected in the [from, to) range. Fee income is reported apart from
+// the revenue report, as fees are not the revenue of any product
+func (s *FeeService) GetFeeIncome(ctx context.Context, from, to time.Time) (int, error) {
+	if !to.After(from) {
+		return 0, fmt.Errorf("%w: the end of the range must be after its start", ErrInvalidReportParams)
+	}
+
+	income, err := s.operationRepo.GetFeeIncome(ctx, from.UTC(), to.UTC())
+	if err != nil {
+		log.Errorf("FeeService.GetFeeIncome - s.operationRepo.GetFeeIncome: %v", err)
+		return 0, errors.New("failed to get fee operations")
+	}
+
+	return income, nil
+}
@@ -0,0 +1,225 @@
+package service
+
+import (
+	"account-management-service/internal/entity"
+	"account-management-service/internal/mocks/repomocks"
+	"account-management-service/internal/repo/repoerrs"
+	"context"
+	"errors"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestFeeService_CalculateFee(t *testing.T) {
+	type args struct {
+		ctx   context.Context
+		input FeeCalculateInput
+	}
+
+	type MockBehavior func(f *repomocks.MockFee, a *repomocks.MockAccount, args args)
+
+	transfer := FeeCalculateInput{
+		AccountId:     1,
+		OperationType: entity.OperationTypeTransferFrom,
+		Amount:        1000,
+	}
+
+	testCases := []struct {
+		name         string
+		args         args
+		mockBehavior MockBehavior
+		want         entity.Fee
+		wantErr      error
+	}{
+		{
+			name: "percentage",
+			args: args{
+				ctx:   context.Background(),
+				input: transfer,
+			},
+			mockBehavior: func(f *repomocks.MockFee, a *repomocks.MockAccount, args args) {
+				a.EXPECT().GetAccountById(args.ctx, args.input.AccountId).
+					Return(entity.Account{Id: 1, Type: entity.AccountTypePersonal}, nil)
+				f.EXPECT().GetFeeRulesForOperation(args.ctx, args.input.OperationType, 0, entity.AccountTypePersonal).
+					Return([]entity.FeeRule{{Rate: 150}, {Fixed: 100}}, nil)
+			},
+			want:    entity.Fee{AccountId: feeAccountId, Amount: 15},
+			wantErr: nil,
+		},
+		{
+			name: "clamped to max",
+			args: args{
+				ctx:   context.Background(),
+				input: transfer,
+			},
+			mockBehavior: func(f *repomocks.MockFee, a *repomocks.MockAccount, args args) {
+				a.EXPECT().GetAccountById(args.ctx, args.input.AccountId).
+					Return(entity.Account{Id: 1, Type: entity.AccountTypePersonal}, nil)
+				f.EXPECT().GetFeeRulesForOperation(args.ctx, args.input.OperationType, 0, entity.AccountTypePersonal).
+					Return([]entity.FeeRule{{Rate: 100, Fixed: 50, MaxFee: 40}}, nil)
+			},
+			want:    entity.Fee{AccountId: feeAccountId, Amount: 40},
+			wantErr: nil,
+		},
+		{
+			name: "below free threshold",
+			args: args{
+				ctx:   context.Background(),
+				input: transfer,
+			},
+			mockBehavior: func(f *repomocks.MockFee, a *repomocks.MockAccount, args args) {
+				a.EXPECT().GetAccountById(args.ctx, args.input.AccountId).
+					Return(entity.Account{Id: 1, Type: entity.AccountTypePersonal}, nil)
+				f.EXPECT().GetFeeRulesForOperation(args.ctx, args.input.OperationType, 0, entity.AccountTypePersonal).
+					Return([]entity.FeeRule{{Rate: 100, MinFee: 30, FreeUpTo: 1000}}, nil)
+			},
+			want:    entity.Fee{AccountId: feeAccountId, Amount: 0},
+			wantErr: nil,
+		},
+		{
+			name: "no rules",
+			args: args{
+				ctx:   context.Background(),
+				input: transfer,
+			},
+			mockBehavior: func(f *repomocks.MockFee, a *repomocks.MockAccount, args args) {
+				a.EXPECT().GetAccountById(args.ctx, args.input.AccountId).
+					Return(entity.Account{Id: 1, Type: entity.AccountTypePersonal}, nil)
+				f.EXPECT().GetFeeRulesForOperation(args.ctx, args.input.OperationType, 0, entity.AccountTypePersonal).
+					Return(nil, nil)
+			},
+			want:    entity.Fee{AccountId: feeAccountId, Amount: 0},
+			wantErr: nil,
+		},
+		{
+			name: "fee account is not charged",
+			args: args{
+				ctx: context.Background(),
+				input: FeeCalculateInput{
+					AccountId:     feeAccountId,
+					OperationType: entity.OperationTypeWithdraw,
+					Amount:        1000,
+				},
+			},
+			mockBehavior: func(f *repomocks.MockFee, a *repomocks.MockAccount, args args) {},
+			want:         entity.Fee{AccountId: feeAccountId, Amount: 0},
+			wantErr:      nil,
+		},
+		{
+			name: "account not found",
+			args: args{
+				ctx:   context.Background(),
+				input: transfer,
+			},
+			mockBehavior: func(f *repomocks.MockFee, a *repomocks.MockAccount, args args) {
+				a.EXPECT().GetAccountById(args.ctx, args.input.AccountId).
+					Return(entity.Account{}, repoerrs.ErrNotFound)
+			},
+			want:    entity.Fee{},
+			wantErr: ErrAccountNotFound,
+		},
+		{
+			name: "get rules error",
+			args: args{
+				ctx:   context.Background(),
+				input: transfer,
+			},
+			mockBehavior: func(f *repomocks.MockFee, a *repomocks.MockAccount, args args) {
+				a.EXPECT().GetAccountById(args.ctx, args.input.AccountId).
+					Return(entity.Account{Id: 1, Type: entity.AccountTypePersonal}, nil)
+				f.EXPECT().GetFeeRulesForOperation(args.ctx, args.input.OperationType, 0, entity.AccountTypePersonal).
+					Return(nil, errors.New("some error"))
+			},
+			want:    entity.Fee{},
+			wantErr: ErrCannotCalculateFee,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// init deps
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			// init mocks
+			feeRepo := repomocks.NewMockFee(ctrl)
+			accountRepo := repomocks.NewMockAccount(ctrl)
+			tc.mockBehavior(feeRepo, accountRepo, tc.args)
+
+			// init service
+			s := NewFeeService(feeRepo, accountRepo, nil, feeAccountId)
+
+			// run test
+			got, err := s.CalculateFee(tc.args.ctx, tc.args.input)
+			if tc.wantErr != nil {
+				assert.ErrorIs(t, err, tc.wantErr)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestFeeService_GetFeeIncome(t *testing.T) {
+	type MockBehavior func(o *repomocks.MockOperation)
+
+	ctx := context.Background()
+	from := time.Date(2022, time.October, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, 0)
+
+	testCases := []struct {
+		name         string
+		from         time.Time
+		to           time.Time
+		mockBehavior MockBehavior
+		want         int
+		wantErr      error
+	}{
+		{
+			name: "OK",
+			from: from,
+			to:   to,
+			mockBehavior: func(o *repomocks.MockOperation) {
+				o.EXPECT().GetFeeIncome(ctx, from, to).
+					Return(25, nil)
+			},
+			want: 25,
+		},
+		{
+			name:         "invalid range",
+			from:         to,
+			to:           from,
+			mockBehavior: func(o *repomocks.MockOperation) {},
+			wantErr:      ErrInvalidReportParams,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// init deps
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			// init mocks
+			operationRepo := repomocks.NewMockOperation(ctrl)
+			tc.mockBehavior(operationRepo)
+
+			// init service
+			s := NewFeeService(nil, nil, operationRepo, 0)
+
+			// run test
+			got, err := s.GetFeeIncome(ctx, tc.from, tc.to)
+			if tc.wantErr != nil {
+				assert.ErrorIs(t, err, tc.wantErr)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
@@ -0,0 +1,130 @@
+package service
+
+import (
+	"account-management-service/internal/entity"
+	"account-management-service/internal/repo"
+	"account-management-service/internal/repo/repoerrs"
+	"context"
+	"errors"
+	log "github.com/sirupsen/logrus"
+)
+
+type GroupService struct {
+	groupRepo repo.Group
+}
+
+func NewGroupService(groupRepo repo.Group) *GroupService {
+	return &GroupService{groupRepo: groupRepo}
+}
+
+func (s *GroupService) CreateGroupAccount(ctx context.Context, ownerId int) (int, error) {
+	id, err := s.groupRepo.CreateGroupAccount(ctx, ownerId)
+	if err != nil {
+		log.Errorf("GroupService.CreateGroupAccount - s.groupRepo.CreateGroupAccount: %v", err)
+		return 0, ErrCannotCreateGroupAccount
+	}
+
+	return id, nil
+}
+
+// SetGroupMember adds a member or changes the role and the monthly limit of an existing one.
+// Owners cannot change themselves, so the account never loses its last owner
+func (s *GroupService) SetGroupMember(ctx context.Context, input GroupSetMemberInput) error {
+	if err := s.requireRole(ctx, input.AccountId, input.ActorId, entity.GroupRoleOwner); err != nil {
+		return err
+	}
+	if input.UserId == input.ActorId {
+		return ErrCannotChangeOwnMembership
+	}
+
+	err := s.groupRepo.SetGroupMember(ctx, entity.GroupMember{
+		AccountId:    input.AccountId,
+		UserId:       input.UserId,
+		Role:         input.Role,
+		MonthlyLimit: input.MonthlyLimit,
+	})
+	if err != nil {
+		if errors.Is(err, repoerrs.ErrNotFound) {
+			return ErrUserNotFound
+		}
+		log.Errorf("GroupService.SetGroupMember - s.groupRepo.SetGroupMember: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+func (s *GroupService) RemoveGroupMember(ctx context.Context, accountId, userId, actorId int) error {
+	if err := s.requireRole(ctx, accountId, actorId, entity.GroupRoleOwner); err != nil {
+		return err
+	}
+	if userId == actorId {
+		return ErrCannotChangeOwnMembership
+	}
+
+	err := s.groupRepo.RemoveGroupMember(ctx, accountId, userId)
+	if err != nil {
+		if errors.Is(err, repoerrs.ErrNotFound) {
+			return ErrNotGroupMember
+		}
+		return err
+	}
+
+	return nil
+}
+
+// GetGroupMembers returns the members of the account to any of its members
+func (s *GroupService) GetGroupMembers(ctx context.Context, accountId, actorId int) ([]entity.GroupMember, error) {
+	if err := s.requireRole(ctx, accountId, actorId); err != nil {
+		return nil, err
+	}
+
+	return s.groupRepo.GetGroupMembers(ctx, accountId)
+}
+
+// checkSpender returns an error if the user may not spend from the shared account, anyone may spend
+// from an account without members. It is checked when an operation run later by the service is created,
+// the operations made right away check the member role and monthly limit in the transaction
+func checkSpender(ctx context.Context, groupRepo repo.Group, accountId, userId int) error {
+	members, err := groupRepo.GetGroupMembers(ctx, accountId)
+	if err != nil {
+		return err
+	}
+	if len(members) == 0 {
+		return nil
+	}
+
+	for _, member := range members {
+		if member.UserId != userId {
+			continue
+		}
+		if member.Role == entity.GroupRoleViewer {
+			return ErrGroupAccessDenied
+		}
+		return nil
+	}
+
+	return ErrNotGroupMember
+}
+
+// requireRole checks that the user is a member of the account with one of roles, any role if none are given
+func (s *GroupService) requireRole(ctx context.Context, accountId, userId int, roles ...string) error {
+	member, err := s.groupRepo.GetGroupMember(ctx, accountId, userId)
+	if err != nil {
+		if errors.Is(err, repoerrs.ErrNotFound) {
+			return ErrNotGroupMember
+		}
+		return err
+	}
+
+	if len(roles) == 0 {
+		return nil
+	}
+	for _, role := range roles {
+		if member.Role == role {
+			return nil
+		}
+	}
+
+	return ErrGroupAccessDenied
+}
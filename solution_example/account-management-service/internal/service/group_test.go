@@ -0,0 +1,195 @@
+package service
+
+import (
+	"account-management-service/internal/entity"
+	"account-management-service/internal/mocks/repomocks"
+	"account-management-service/internal/repo/repoerrs"
+	"context"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestGroupService_SetGroupMember(t *testing.T) {
+	type args struct {
+		ctx   context.Context
+		input GroupSetMemberInput
+	}
+
+	type MockBehavior func(g *repomocks.MockGroup, args args)
+
+	spender := GroupSetMemberInput{
+		AccountId:    1,
+		UserId:       2,
+		Role:         entity.GroupRoleSpender,
+		MonthlyLimit: 5000,
+		ActorId:      1,
+	}
+
+	testCases := []struct {
+		name         string
+		args         args
+		mockBehavior MockBehavior
+		wantErr      error
+	}{
+		{
+			name: "OK",
+			args: args{
+				ctx:   context.Background(),
+				input: spender,
+			},
+			mockBehavior: func(g *repomocks.MockGroup, args args) {
+				g.EXPECT().GetGroupMember(args.ctx, 1, 1).
+					Return(entity.GroupMember{AccountId: 1, UserId: 1, Role: entity.GroupRoleOwner}, nil)
+				g.EXPECT().SetGroupMember(args.ctx, entity.GroupMember{
+					AccountId:    1,
+					UserId:       2,
+					Role:         entity.GroupRoleSpender,
+					MonthlyLimit: 5000,
+				}).Return(nil)
+			},
+			wantErr: nil,
+		},
+		{
+			name: "actor is not an owner",
+			args: args{
+				ctx:   context.Background(),
+				input: spender,
+			},
+			mockBehavior: func(g *repomocks.MockGroup, args args) {
+				g.EXPECT().GetGroupMember(args.ctx, 1, 1).
+					Return(entity.GroupMember{AccountId: 1, UserId: 1, Role: entity.GroupRoleSpender}, nil)
+			},
+			wantErr: ErrGroupAccessDenied,
+		},
+		{
+			name: "actor is not a member",
+			args: args{
+				ctx:   context.Background(),
+				input: spender,
+			},
+			mockBehavior: func(g *repomocks.MockGroup, args args) {
+				g.EXPECT().GetGroupMember(args.ctx, 1, 1).
+					Return(entity.GroupMember{}, repoerrs.ErrNotFound)
+			},
+			wantErr: ErrNotGroupMember,
+		},
+		{
+			name: "owner changes own membership",
+			args: args{
+				ctx: context.Background(),
+				input: GroupSetMemberInput{
+					AccountId: 1,
+					UserId:    1,
+					Role:      entity.GroupRoleViewer,
+					ActorId:   1,
+				},
+			},
+			mockBehavior: func(g *repomocks.MockGroup, args args) {
+				g.EXPECT().GetGroupMember(args.ctx, 1, 1).
+					Return(entity.GroupMember{AccountId: 1, UserId: 1, Role: entity.GroupRoleOwner}, nil)
+			},
+			wantErr: ErrCannotChangeOwnMembership,
+		},
+		{
+			name: "user not found",
+			args: args{
+				ctx:   context.Background(),
+				input: spender,
+			},
+			mockBehavior: func(g *repomocks.MockGroup, args args) {
+				g.EXPECT().GetGroupMember(args.ctx, 1, 1).
+					Return(entity.GroupMember{AccountId: 1, UserId: 1, Role: entity.GroupRoleOwner}, nil)
+				g.EXPECT().SetGroupMember(args.ctx, gomock.Any()).
+					Return(repoerrs.ErrNotFound)
+			},
+			wantErr: ErrUserNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// init deps
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			// init mocks
+			groupRepo := repomocks.NewMockGroup(ctrl)
+			tc.mockBehavior(groupRepo, tc.args)
+
+			// init service
+			s := NewGroupService(groupRepo)
+
+			// run test
+			err := s.SetGroupMember(tc.args.ctx, tc.args.input)
+			if tc.wantErr != nil {
+				assert.ErrorIs(t, err, tc.wantErr)
+				return
+			}
+
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestCheckSpender(t *testing.T) {
+	members := []entity.GroupMember{
+		{AccountId: 1, UserId: 1, Role: entity.GroupRoleOwner},
+		{AccountId: 1, UserId: 2, Role: entity.GroupRoleSpender, MonthlyLimit: 5000},
+		{AccountId: 1, UserId: 3, Role: entity.GroupRoleViewer},
+	}
+
+	testCases := []struct {
+		name    string
+		members []entity.GroupMember
+		userId  int
+		wantErr error
+	}{
+		{
+			name:    "account without members",
+			members: nil,
+			userId:  4,
+			wantErr: nil,
+		},
+		{
+			name:    "owner",
+			members: members,
+			userId:  1,
+			wantErr: nil,
+		},
+		{
+			name:    "spender",
+			members: members,
+			userId:  2,
+			wantErr: nil,
+		},
+		{
+			name:    "viewer",
+			members: members,
+			userId:  3,
+			wantErr: ErrGroupAccessDenied,
+		},
+		{
+			name:    "not a member",
+			members: members,
+			userId:  4,
+			wantErr: ErrNotGroupMember,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// init deps
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			ctx := context.Background()
+			groupRepo := repomocks.NewMockGroup(ctrl)
+			groupRepo.EXPECT().GetGroupMembers(ctx, 1).Return(tc.members, nil)
+
+			// run test
+			err := checkSpender(ctx, groupRepo, 1, tc.userId)
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
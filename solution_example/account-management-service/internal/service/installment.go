@@ -0,0 +1,187 @@
+package service
+
+import (
+	"account-management-service/internal/entity"
+	"account-management-service/internal/repo"
+	"account-management-service/internal/repo/repoerrs"
+	"context"
+	"errors"
+	log "github.com/sirupsen/logrus"
+	"time"
+)
+
+const (
+	// installmentChargeBatchSize is the max number of parts charged in a single scheduler run
+	installmentChargeBatchSize = 100
+	maxInstallmentParts        = 24
+)
+
+type InstallmentService struct {
+	installmentRepo repo.Installment
+	limitService    Limit
+	feeService      Fee
+	spendingOrder   []string
+	policy          entity.MissedInstallmentPolicy
+}
+
+func NewInstallmentService(installmentRepo repo.Installment, limitService Limit, feeService Fee, spendingOrder []string, policy entity.MissedInstallmentPolicy) *InstallmentService {
+	if len(spendingOrder) == 0 {
+		spendingOrder = defaultSpendingOrder
+	}
+	if policy.MaxAttempts < 1 {
+		policy.MaxAttempts = 1
+	}
+
+	return &InstallmentService{
+		installmentRepo: installmentRepo,
+		limitService:    limitService,
+		feeService:      feeService,
+		spendingOrder:   spendingOrder,
+		policy:          policy,
+	}
+}
+
+// CreateInstallmentPlan reserves the first payment now and schedules the rest of the amount
+// in equal parts, one per period. Every part is subject to the reservation limits and fee
+func (s *InstallmentService) CreateInstallmentPlan(ctx context.Context, input InstallmentCreatePlanInput) (int, error) {
+	if input.Parts < 2 || input.Parts > maxInstallmentParts || input.Amount < input.Parts {
+		return 0, ErrInvalidInstallmentPlan
+	}
+	if input.FirstPayment < 0 || input.FirstPayment >= input.Amount || input.Amount-input.FirstPayment < input.Parts-1 {
+		return 0, ErrInvalidInstallmentPlan
+	}
+	switch input.Period {
+	case entity.SubscriptionPeriodDay, entity.SubscriptionPeriodWeek, entity.SubscriptionPeriodMonth:
+	default:
+		return 0, ErrInvalidInstallmentPlan
+	}
+
+	dueAt := time.Now()
+	var parts []entity.InstallmentPart
+	for i, amount := range entity.SplitInstallments(input.Amount, input.FirstPayment, input.Parts) {
+		parts = append(parts, entity.InstallmentPart{
+			Number: i + 1,
+			Amount: amount,
+			DueAt:  dueAt,
+		})
+		dueAt = entity.NextPeriodStart(dueAt, input.Period)
+	}
+
+	limits, err := s.limitService.CheckLimits(ctx, input.AccountId, entity.OperationTypeReservation, parts[0].Amount)
+	if err != nil {
+		return 0, err
+	}
+
+	fee, err := s.feeService.CalculateFee(ctx, FeeCalculateInput{
+		AccountId:     input.AccountId,
+		OperationType: entity.OperationTypeReservation,
+		ProductId:     input.ProductId,
+		Amount:        parts[0].Amount,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	id, err := s.installmentRepo.CreateInstallmentPlan(ctx, entity.InstallmentPlan{
+		AccountId: input.AccountId,
+		ProductId: input.ProductId,
+		OrderId:   input.OrderId,
+		Amount:    input.Amount,
+		Period:    input.Period,
+	}, &input.UserId, parts, s.spendingOrder, fee, limits)
+	if err != nil {
+		switch {
+		case errors.Is(err, repoerrs.ErrAlreadyExists):
+			return 0, ErrOrderAlreadyReserved
+		case errors.Is(err, repoerrs.ErrLimitExceeded):
+			return 0, limitRepoError(err)
+		case errors.Is(err, repoerrs.ErrNotFound), errors.Is(err, repoerrs.ErrNotEnoughBalance),
+			errors.Is(err, repoerrs.ErrAccountFrozen), errors.Is(err, repoerrs.ErrAccountClosed),
+			errors.Is(err, repoerrs.ErrNotGroupMember), errors.Is(err, repoerrs.ErrMemberCannotSpend),
+			errors.Is(err, repoerrs.ErrMemberLimitExceeded):
+			return 0, accountRepoError(err)
+		}
+		log.Errorf("InstallmentService.CreateInstallmentPlan - s.installmentRepo.CreateInstallmentPlan: %v", err)
+		return 0, ErrCannotCreateInstallmentPlan
+	}
+
+	return id, nil
+}
+
+func (s *InstallmentService) GetInstallmentPlan(ctx context.Context, id int) (entity.InstallmentPlan, []entity.InstallmentPart, error) {
+	plan, err := s.installmentRepo.GetInstallmentPlanById(ctx, id)
+	if err != nil {
+		if errors.Is(err, repoerrs.ErrNotFound) {
+			return entity.InstallmentPlan{}, nil, ErrInstallmentPlanNotFound
+		}
+		return entity.InstallmentPlan{}, nil, err
+	}
+
+	parts, err := s.installmentRepo.GetInstallmentParts(ctx, id)
+	if err != nil {
+		return entity.InstallmentPlan{}, nil, err
+	}
+
+	return plan, parts, nil
+}
+
+func (s *InstallmentService) GetAccountObligations(ctx context.Context, accountId int) ([]entity.InstallmentObligation, error) {
+	return s.installmentRepo.GetInstallmentObligations(ctx, accountId)
+}
+
+// ChargeDueInstallments reserves all the parts whose due date has come, it is run by the scheduler
+func (s *InstallmentService) ChargeDueInstallments(ctx context.Context) {
+	dueParts, err := s.installmentRepo.GetDueInstallmentParts(ctx, installmentChargeBatchSize)
+	if err != nil {
+		log.Errorf("InstallmentService.ChargeDueInstallments - s.installmentRepo.GetDueInstallmentParts: %v", err)
+		return
+	}
+
+	for _, due := range dueParts {
+		if ctx.Err() != nil {
+			return
+		}
+
+		var rejectReason string
+		limits, err := s.limitService.CheckLimits(ctx, due.AccountId, entity.OperationTypeReservation, due.Amount)
+		if err != nil {
+			if !errors.Is(err, ErrLimitExceeded) {
+				// limits cannot be checked now, try again on the next run
+				continue
+			}
+			rejectReason = err.Error()
+		}
+
+		var fee entity.Fee
+		if rejectReason == "" {
+			fee, err = s.feeService.CalculateFee(ctx, FeeCalculateInput{
+				AccountId:     due.AccountId,
+				OperationType: entity.OperationTypeReservation,
+				ProductId:     due.ProductId,
+				Amount:        due.Amount,
+			})
+			if err != nil {
+				continue
+			}
+		}
+
+		part, err := s.installmentRepo.ChargeInstallmentPart(ctx, due.Id, rejectReason, fee, limits, s.spendingOrder, s.policy)
+		if err != nil {
+			// already charged by another instance
+			if errors.Is(err, repoerrs.ErrNotFound) {
+				continue
+			}
+			log.Errorf("InstallmentService.ChargeDueInstallments - s.installmentRepo.ChargeInstallmentPart: %v", err)
+			continue
+		}
+
+		switch {
+		case part.Status == entity.InstallmentPartStatusCancelled:
+			log.Warnf("installment plan %d part %d was not paid after %d attempts, the order is cancelled", part.PlanId, part.Number, part.Attempts)
+		case part.Status == entity.InstallmentPartStatusPending && part.Attempts >= s.policy.MaxAttempts:
+			log.Warnf("installment plan %d part %d was not paid after %d attempts, the plan is defaulted", part.PlanId, part.Number, part.Attempts)
+		case part.Status == entity.InstallmentPartStatusPending:
+			log.Warnf("installment plan %d part %d attempt %d failed: %s", part.PlanId, part.Number, part.Attempts, part.Error)
+		}
+	}
+}
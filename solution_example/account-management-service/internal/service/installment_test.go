@@ -0,0 +1,341 @@
+package service
+
+import (
+	"account-management-service/internal/entity"
+	"account-management-service/internal/mocks/repomocks"
+	"account-management-service/internal/repo/repoerrs"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestInstallmentService_CreateInstallmentPlan(t *testing.T) {
+	type args struct {
+		ctx   context.Context
+		input InstallmentCreatePlanInput
+	}
+
+	type MockBehavior func(i *repomocks.MockInstallment, a *repomocks.MockAccount, l *repomocks.MockLimit, f *repomocks.MockFee, args args)
+
+	plan := InstallmentCreatePlanInput{
+		AccountId: 1,
+		ProductId: 1,
+		OrderId:   1,
+		Amount:    1000,
+		Parts:     3,
+		Period:    entity.SubscriptionPeriodMonth,
+		UserId:    7,
+	}
+
+	partsAmounts := func(amounts ...int) gomock.Matcher {
+		return installmentPartsMatcher{amounts: amounts, period: plan.Period}
+	}
+
+	// the first part is checked and charged the fee like a reservation
+	expectChecks := func(a *repomocks.MockAccount, l *repomocks.MockLimit, f *repomocks.MockFee, ctx context.Context) {
+		a.EXPECT().GetAccountById(ctx, 1).
+			Return(entity.Account{Id: 1, Type: entity.AccountTypePersonal}, nil).Times(2)
+		l.EXPECT().GetLimitRulesForAccount(ctx, 1, entity.AccountTypePersonal, entity.OperationTypeReservation).
+			Return(nil, nil)
+		f.EXPECT().GetFeeRulesForOperation(ctx, entity.OperationTypeReservation, 1, entity.AccountTypePersonal).
+			Return([]entity.FeeRule{{Rate: 100, MinFee: 5}}, nil)
+	}
+
+	testCases := []struct {
+		name         string
+		args         args
+		mockBehavior MockBehavior
+		want         int
+		wantErr      error
+	}{
+		{
+			name: "even split",
+			args: args{
+				ctx:   context.Background(),
+				input: plan,
+			},
+			mockBehavior: func(i *repomocks.MockInstallment, a *repomocks.MockAccount, l *repomocks.MockLimit, f *repomocks.MockFee, args args) {
+				expectChecks(a, l, f, args.ctx)
+				i.EXPECT().CreateInstallmentPlan(args.ctx, entity.InstallmentPlan{
+					AccountId: 1,
+					ProductId: 1,
+					OrderId:   1,
+					Amount:    1000,
+					Period:    entity.SubscriptionPeriodMonth,
+				}, &args.input.UserId, partsAmounts(334, 333, 333), defaultSpendingOrder, entity.Fee{AccountId: feeAccountId, Amount: 5}, []entity.LimitRule(nil)).
+					Return(1, nil)
+			},
+			want:    1,
+			wantErr: nil,
+		},
+		{
+			name: "first payment",
+			args: args{
+				ctx: context.Background(),
+				input: InstallmentCreatePlanInput{
+					AccountId:    1,
+					ProductId:    1,
+					OrderId:      1,
+					Amount:       1000,
+					FirstPayment: 500,
+					Parts:        4,
+					Period:       entity.SubscriptionPeriodMonth,
+				},
+			},
+			mockBehavior: func(i *repomocks.MockInstallment, a *repomocks.MockAccount, l *repomocks.MockLimit, f *repomocks.MockFee, args args) {
+				expectChecks(a, l, f, args.ctx)
+				i.EXPECT().CreateInstallmentPlan(args.ctx, gomock.Any(), &args.input.UserId, partsAmounts(500, 166, 166, 168), defaultSpendingOrder, gomock.Any(), gomock.Any()).
+					Return(1, nil)
+			},
+			want:    1,
+			wantErr: nil,
+		},
+		{
+			name: "first payment covers the whole amount",
+			args: args{
+				ctx: context.Background(),
+				input: InstallmentCreatePlanInput{
+					AccountId:    1,
+					ProductId:    1,
+					OrderId:      1,
+					Amount:       1000,
+					FirstPayment: 1000,
+					Parts:        2,
+					Period:       entity.SubscriptionPeriodWeek,
+				},
+			},
+			mockBehavior: func(i *repomocks.MockInstallment, a *repomocks.MockAccount, l *repomocks.MockLimit, f *repomocks.MockFee, args args) {
+			},
+			want:    0,
+			wantErr: ErrInvalidInstallmentPlan,
+		},
+		{
+			name: "order already reserved",
+			args: args{
+				ctx:   context.Background(),
+				input: plan,
+			},
+			mockBehavior: func(i *repomocks.MockInstallment, a *repomocks.MockAccount, l *repomocks.MockLimit, f *repomocks.MockFee, args args) {
+				expectChecks(a, l, f, args.ctx)
+				i.EXPECT().CreateInstallmentPlan(args.ctx, gomock.Any(), &args.input.UserId, gomock.Any(), defaultSpendingOrder, gomock.Any(), gomock.Any()).
+					Return(0, repoerrs.ErrAlreadyExists)
+			},
+			want:    0,
+			wantErr: ErrOrderAlreadyReserved,
+		},
+		{
+			name: "not enough balance for the first part",
+			args: args{
+				ctx:   context.Background(),
+				input: plan,
+			},
+			mockBehavior: func(i *repomocks.MockInstallment, a *repomocks.MockAccount, l *repomocks.MockLimit, f *repomocks.MockFee, args args) {
+				expectChecks(a, l, f, args.ctx)
+				i.EXPECT().CreateInstallmentPlan(args.ctx, gomock.Any(), &args.input.UserId, gomock.Any(), defaultSpendingOrder, gomock.Any(), gomock.Any()).
+					Return(0, repoerrs.ErrNotEnoughBalance)
+			},
+			want:    0,
+			wantErr: ErrNotEnoughBalance,
+		},
+		{
+			name: "first part over the limit",
+			args: args{
+				ctx:   context.Background(),
+				input: plan,
+			},
+			mockBehavior: func(i *repomocks.MockInstallment, a *repomocks.MockAccount, l *repomocks.MockLimit, f *repomocks.MockFee, args args) {
+				a.EXPECT().GetAccountById(args.ctx, 1).
+					Return(entity.Account{Id: 1, Type: entity.AccountTypePersonal}, nil)
+				l.EXPECT().GetLimitRulesForAccount(args.ctx, 1, entity.AccountTypePersonal, entity.OperationTypeReservation).
+					Return([]entity.LimitRule{{Scope: entity.LimitScopeGlobal, LimitType: entity.LimitTypeMaxAmount, Value: 300}}, nil)
+			},
+			want:    0,
+			wantErr: ErrLimitExceeded,
+		},
+		{
+			name: "first part over the window limit",
+			args: args{
+				ctx:   context.Background(),
+				input: plan,
+			},
+			mockBehavior: func(i *repomocks.MockInstallment, a *repomocks.MockAccount, l *repomocks.MockLimit, f *repomocks.MockFee, args args) {
+				expectChecks(a, l, f, args.ctx)
+				i.EXPECT().CreateInstallmentPlan(args.ctx, gomock.Any(), &args.input.UserId, gomock.Any(), defaultSpendingOrder, gomock.Any(), gomock.Any()).
+					Return(0, &repoerrs.LimitExceededError{
+						Rule:          entity.LimitRule{LimitType: entity.LimitTypeMaxTotal, Value: 1000, WindowSeconds: 86400},
+						OperationType: entity.OperationTypeReservation,
+					})
+			},
+			want:    0,
+			wantErr: ErrLimitExceeded,
+		},
+		{
+			name: "repo error",
+			args: args{
+				ctx:   context.Background(),
+				input: plan,
+			},
+			mockBehavior: func(i *repomocks.MockInstallment, a *repomocks.MockAccount, l *repomocks.MockLimit, f *repomocks.MockFee, args args) {
+				expectChecks(a, l, f, args.ctx)
+				i.EXPECT().CreateInstallmentPlan(args.ctx, gomock.Any(), &args.input.UserId, gomock.Any(), defaultSpendingOrder, gomock.Any(), gomock.Any()).
+					Return(0, errors.New("some error"))
+			},
+			want:    0,
+			wantErr: ErrCannotCreateInstallmentPlan,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// init deps
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			// init mocks
+			installmentRepo := repomocks.NewMockInstallment(ctrl)
+			accountRepo := repomocks.NewMockAccount(ctrl)
+			limitRepo := repomocks.NewMockLimit(ctrl)
+			feeRepo := repomocks.NewMockFee(ctrl)
+			tc.mockBehavior(installmentRepo, accountRepo, limitRepo, feeRepo, tc.args)
+
+			// init service
+			limitService := NewLimitService(limitRepo, accountRepo)
+			feeService := NewFeeService(feeRepo, accountRepo, nil, feeAccountId)
+			s := NewInstallmentService(installmentRepo, limitService, feeService, nil, entity.MissedInstallmentPolicy{MaxAttempts: 3})
+
+			// run test
+			got, err := s.CreateInstallmentPlan(tc.args.ctx, tc.args.input)
+			if tc.wantErr != nil {
+				assert.ErrorIs(t, err, tc.wantErr)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestInstallmentService_ChargeDueInstallments(t *testing.T) {
+	type MockBehavior func(i *repomocks.MockInstallment, a *repomocks.MockAccount, l *repomocks.MockLimit, f *repomocks.MockFee, ctx context.Context)
+
+	policy := entity.MissedInstallmentPolicy{MaxAttempts: 3}
+	due := entity.DueInstallmentPart{Id: 5, AccountId: 1, ProductId: 2, Amount: 300}
+
+	testCases := []struct {
+		name         string
+		mockBehavior MockBehavior
+	}{
+		{
+			name: "OK",
+			mockBehavior: func(i *repomocks.MockInstallment, a *repomocks.MockAccount, l *repomocks.MockLimit, f *repomocks.MockFee, ctx context.Context) {
+				i.EXPECT().GetDueInstallmentParts(ctx, installmentChargeBatchSize).
+					Return([]entity.DueInstallmentPart{due}, nil)
+				a.EXPECT().GetAccountById(ctx, due.AccountId).
+					Return(entity.Account{Id: 1, Type: entity.AccountTypePersonal}, nil).Times(2)
+				l.EXPECT().GetLimitRulesForAccount(ctx, due.AccountId, entity.AccountTypePersonal, entity.OperationTypeReservation).
+					Return([]entity.LimitRule{{Scope: entity.LimitScopeGlobal, LimitType: entity.LimitTypeMaxTotal, Value: 1000, WindowSeconds: 86400}}, nil)
+				f.EXPECT().GetFeeRulesForOperation(ctx, entity.OperationTypeReservation, due.ProductId, entity.AccountTypePersonal).
+					Return([]entity.FeeRule{{Rate: 100, MinFee: 5}}, nil)
+				// the window rules are checked by the repo once the account is locked
+				i.EXPECT().ChargeInstallmentPart(ctx, due.Id, "", entity.Fee{AccountId: feeAccountId, Amount: 5},
+					[]entity.LimitRule{{Scope: entity.LimitScopeGlobal, LimitType: entity.LimitTypeMaxTotal, Value: 1000, WindowSeconds: 86400}},
+					defaultSpendingOrder, policy).
+					Return(entity.InstallmentPart{Id: due.Id, Status: entity.InstallmentPartStatusPaid}, nil)
+			},
+		},
+		{
+			name: "limit exceeded is recorded as failed attempt",
+			mockBehavior: func(i *repomocks.MockInstallment, a *repomocks.MockAccount, l *repomocks.MockLimit, f *repomocks.MockFee, ctx context.Context) {
+				i.EXPECT().GetDueInstallmentParts(ctx, installmentChargeBatchSize).
+					Return([]entity.DueInstallmentPart{due}, nil)
+				a.EXPECT().GetAccountById(ctx, due.AccountId).
+					Return(entity.Account{Id: 1, Type: entity.AccountTypePersonal}, nil)
+				l.EXPECT().GetLimitRulesForAccount(ctx, due.AccountId, entity.AccountTypePersonal, entity.OperationTypeReservation).
+					Return([]entity.LimitRule{{Scope: entity.LimitScopeGlobal, LimitType: entity.LimitTypeMaxAmount, Value: 100}}, nil)
+				i.EXPECT().ChargeInstallmentPart(ctx, due.Id, "limit exceeded: reservation amount must not exceed 100", entity.Fee{}, []entity.LimitRule(nil),
+					defaultSpendingOrder, policy).
+					Return(entity.InstallmentPart{Id: due.Id, Status: entity.InstallmentPartStatusPending, Attempts: 1}, nil)
+			},
+		},
+		{
+			name: "limits cannot be checked",
+			mockBehavior: func(i *repomocks.MockInstallment, a *repomocks.MockAccount, l *repomocks.MockLimit, f *repomocks.MockFee, ctx context.Context) {
+				i.EXPECT().GetDueInstallmentParts(ctx, installmentChargeBatchSize).
+					Return([]entity.DueInstallmentPart{due}, nil)
+				a.EXPECT().GetAccountById(ctx, due.AccountId).
+					Return(entity.Account{}, errors.New("some error"))
+			},
+		},
+		{
+			name: "charged by another instance",
+			mockBehavior: func(i *repomocks.MockInstallment, a *repomocks.MockAccount, l *repomocks.MockLimit, f *repomocks.MockFee, ctx context.Context) {
+				i.EXPECT().GetDueInstallmentParts(ctx, installmentChargeBatchSize).
+					Return([]entity.DueInstallmentPart{due}, nil)
+				a.EXPECT().GetAccountById(ctx, due.AccountId).
+					Return(entity.Account{Id: 1, Type: entity.AccountTypePersonal}, nil).Times(2)
+				l.EXPECT().GetLimitRulesForAccount(ctx, due.AccountId, entity.AccountTypePersonal, entity.OperationTypeReservation).
+					Return(nil, nil)
+				f.EXPECT().GetFeeRulesForOperation(ctx, entity.OperationTypeReservation, due.ProductId, entity.AccountTypePersonal).
+					Return(nil, nil)
+				i.EXPECT().ChargeInstallmentPart(ctx, due.Id, "", entity.Fee{AccountId: feeAccountId}, []entity.LimitRule(nil), defaultSpendingOrder, policy).
+					Return(entity.InstallmentPart{}, repoerrs.ErrNotFound)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// init deps
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			// init mocks
+			ctx := context.Background()
+			installmentRepo := repomocks.NewMockInstallment(ctrl)
+			accountRepo := repomocks.NewMockAccount(ctrl)
+			limitRepo := repomocks.NewMockLimit(ctrl)
+			feeRepo := repomocks.NewMockFee(ctrl)
+			tc.mockBehavior(installmentRepo, accountRepo, limitRepo, feeRepo, ctx)
+
+			// init service
+			limitService := NewLimitService(limitRepo, accountRepo)
+			feeService := NewFeeService(feeRepo, accountRepo, nil, feeAccountId)
+			s := NewInstallmentService(installmentRepo, limitService, feeService, nil, policy)
+
+			// run test
+			s.ChargeDueInstallments(ctx)
+		})
+	}
+}
+
+// installmentPartsMatcher matches the parts passed to the repo by their amounts, numbers and due dates
+type installmentPartsMatcher struct {
+	amounts []int
+	period  string
+}
+
+func (m installmentPartsMatcher) Matches(x interface{}) bool {
+	parts, ok := x.([]entity.InstallmentPart)
+	if !ok || len(parts) != len(m.amounts) {
+		return false
+	}
+
+	for i, part := range parts {
+		if part.Number != i+1 || part.Amount != m.amounts[i] {
+			return false
+		}
+		if i > 0 && !part.DueAt.Equal(entity.NextPeriodStart(parts[i-1].DueAt, m.period)) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (m installmentPartsMatcher) String() string {
+	return fmt.Sprintf("installment parts of %v", m.amounts)
+}
@@ -0,0 +1,121 @@
+package service
+
+import (
+	"account-management-service/internal/entity"
+	"account-management-service/internal/repo"
+	"account-management-service/internal/repo/repoerrs"
+	"context"
+	"errors"
+	"fmt"
+	log "github.com/sirupsen/logrus"
+)
+
+type LimitService struct {
+	limitRepo   repo.Limit
+	accountRepo repo.Account
+}
+
+func NewLimitService(limitRepo repo.Limit, accountRepo repo.Account) *LimitService {
+	return &LimitService{
+		limitRepo:   limitRepo,
+		accountRepo: accountRepo,
+	}
+}
+
+func (s *LimitService) CreateLimitRule(ctx context.Context, input LimitCreateRuleInput) (int, error) {
+	rule := entity.LimitRule{
+		Scope:         input.Scope,
+		OperationType: input.OperationType,
+		LimitType:     input.LimitType,
+		Value:         input.Value,
+		WindowSeconds: input.WindowSeconds,
+	}
+
+	switch input.Scope {
+	case entity.LimitScopeGlobal:
+	case entity.LimitScopeAccountType:
+		if input.AccountType == "" {
+			return 0, ErrInvalidLimitRule
+		}
+		rule.AccountType = &input.AccountType
+	case entity.LimitScopeAccount:
+		if input.AccountId == 0 {
+			return 0, ErrInvalidLimitRule
+		}
+		rule.AccountId = &input.AccountId
+	default:
+		return 0, ErrInvalidLimitRule
+	}
+
+	// rolling window rules make no sense without the window itself
+	if input.LimitType != entity.LimitTypeMaxAmount && input.WindowSeconds <= 0 {
+		return 0, ErrInvalidLimitRule
+	}
+
+	id, err := s.limitRepo.CreateLimitRule(ctx, rule)
+	if err != nil {
+		log.Errorf("LimitService.CreateLimitRule - s.limitRepo.CreateLimitRule: %v", err)
+		return 0, ErrCannotCreateLimitRule
+	}
+
+	return id, nil
+}
+
+func (s *LimitService) GetAllLimitRules(ctx context.Context) ([]entity.LimitRule, error) {
+	return s.limitRepo.GetAllLimitRules(ctx)
+}
+
+func (s *LimitService) DeleteLimitRule(ctx context.Context, id int) error {
+	err := s.limitRepo.DeleteLimitRule(ctx, id)
+	if err != nil {
+		if errors.Is(err, repoerrs.ErrNotFound) {
+			return ErrLimitRuleNotFound
+		}
+		return err
+	}
+
+	return nil
+}
+
+// CheckLimits checks an outgoing operation against every global, account type and account rule
+// limiting the single operation and returns ErrLimitExceeded wrapped with the violated rule description.
+// The rolling window rules are returned to be checked by the repo in the transaction moving the money,
+// once the account is locked, otherwise concurrent operations could all fit into what is left of the window
+func (s *LimitService) CheckLimits(ctx context.Context, accountId int, operationType string, amount int) ([]entity.LimitRule, error) {
+	account, err := s.accountRepo.GetAccountById(ctx, accountId)
+	if err != nil {
+		if errors.Is(err, repoerrs.ErrNotFound) {
+			return nil, ErrAccountNotFound
+		}
+		log.Errorf("LimitService.CheckLimits - s.accountRepo.GetAccountById: %v", err)
+		return nil, ErrCannotCheckLimits
+	}
+
+	rules, err := s.limitRepo.GetLimitRulesForAccount(ctx, accountId, account.Type, operationType)
+	if err != nil {
+		log.Errorf("LimitService.CheckLimits - s.limitRepo.GetLimitRulesForAccount: %v", err)
+		return nil, ErrCannotCheckLimits
+	}
+
+	var windowRules []entity.LimitRule
+	for _, rule := range rules {
+		if rule.LimitType != entity.LimitTypeMaxAmount {
+			windowRules = append(windowRules, rule)
+			continue
+		}
+		if amount > rule.Value {
+			return nil, fmt.Errorf("%w: %s amount must not exceed %d", ErrLimitExceeded, operationType, rule.Value)
+		}
+	}
+
+	return windowRules, nil
+}
+
+// limitRepoError keeps the violated rule description of the limit error returned by the repo
+func limitRepoError(err error) error {
+	var limitErr *repoerrs.LimitExceededError
+	if errors.As(err, &limitErr) {
+		return fmt.Errorf("%w: %s", ErrLimitExceeded, limitErr.Description())
+	}
+	return ErrLimitExceeded
+}
@@ -0,0 +1,185 @@
+package service
+
+import (
+	"account-management-service/internal/entity"
+	"account-management-service/internal/mocks/repomocks"
+	"account-management-service/internal/repo/repoerrs"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestLimitService_CheckLimits(t *testing.T) {
+	type args struct {
+		ctx           context.Context
+		accountId     int
+		operationType string
+		amount        int
+	}
+
+	type MockBehavior func(l *repomocks.MockLimit, a *repomocks.MockAccount, args args)
+
+	testCases := []struct {
+		name         string
+		args         args
+		mockBehavior MockBehavior
+		want         []entity.LimitRule
+		wantErr      error
+	}{
+		{
+			name: "OK: no rules",
+			args: args{
+				ctx:           context.Background(),
+				accountId:     1,
+				operationType: entity.OperationTypeWithdraw,
+				amount:        100,
+			},
+			mockBehavior: func(l *repomocks.MockLimit, a *repomocks.MockAccount, args args) {
+				a.EXPECT().GetAccountById(args.ctx, args.accountId).
+					Return(entity.Account{Id: 1, Type: entity.AccountTypePersonal}, nil)
+				l.EXPECT().GetLimitRulesForAccount(args.ctx, args.accountId, entity.AccountTypePersonal, args.operationType).
+					Return(nil, nil)
+			},
+			wantErr: nil,
+		},
+		{
+			name: "max amount exceeded",
+			args: args{
+				ctx:           context.Background(),
+				accountId:     1,
+				operationType: entity.OperationTypeWithdraw,
+				amount:        100,
+			},
+			mockBehavior: func(l *repomocks.MockLimit, a *repomocks.MockAccount, args args) {
+				a.EXPECT().GetAccountById(args.ctx, args.accountId).
+					Return(entity.Account{Id: 1, Type: entity.AccountTypePersonal}, nil)
+				l.EXPECT().GetLimitRulesForAccount(args.ctx, args.accountId, entity.AccountTypePersonal, args.operationType).
+					Return([]entity.LimitRule{
+						{Scope: entity.LimitScopeGlobal, LimitType: entity.LimitTypeMaxAmount, Value: 50},
+					}, nil)
+			},
+			wantErr: ErrLimitExceeded,
+		},
+		{
+			name: "window rules are left to the repo",
+			args: args{
+				ctx:           context.Background(),
+				accountId:     1,
+				operationType: entity.OperationTypeTransferFrom,
+				amount:        100,
+			},
+			mockBehavior: func(l *repomocks.MockLimit, a *repomocks.MockAccount, args args) {
+				a.EXPECT().GetAccountById(args.ctx, args.accountId).
+					Return(entity.Account{Id: 1, Type: entity.AccountTypeBusiness}, nil)
+				l.EXPECT().GetLimitRulesForAccount(args.ctx, args.accountId, entity.AccountTypeBusiness, args.operationType).
+					Return([]entity.LimitRule{
+						{Scope: entity.LimitScopeGlobal, LimitType: entity.LimitTypeMaxAmount, Value: 100},
+						{Scope: entity.LimitScopeAccountType, LimitType: entity.LimitTypeMaxTotal, Value: 1000, WindowSeconds: 86400},
+						{Scope: entity.LimitScopeAccount, LimitType: entity.LimitTypeMaxCount, Value: 5, WindowSeconds: 3600},
+					}, nil)
+			},
+			want: []entity.LimitRule{
+				{Scope: entity.LimitScopeAccountType, LimitType: entity.LimitTypeMaxTotal, Value: 1000, WindowSeconds: 86400},
+				{Scope: entity.LimitScopeAccount, LimitType: entity.LimitTypeMaxCount, Value: 5, WindowSeconds: 3600},
+			},
+			wantErr: nil,
+		},
+		{
+			name: "account not found",
+			args: args{
+				ctx:           context.Background(),
+				accountId:     1,
+				operationType: entity.OperationTypeWithdraw,
+				amount:        100,
+			},
+			mockBehavior: func(l *repomocks.MockLimit, a *repomocks.MockAccount, args args) {
+				a.EXPECT().GetAccountById(args.ctx, args.accountId).
+					Return(entity.Account{}, repoerrs.ErrNotFound)
+			},
+			wantErr: ErrAccountNotFound,
+		},
+		{
+			name: "get limit rules error",
+			args: args{
+				ctx:           context.Background(),
+				accountId:     1,
+				operationType: entity.OperationTypeWithdraw,
+				amount:        100,
+			},
+			mockBehavior: func(l *repomocks.MockLimit, a *repomocks.MockAccount, args args) {
+				a.EXPECT().GetAccountById(args.ctx, args.accountId).
+					Return(entity.Account{Id: 1, Type: entity.AccountTypePersonal}, nil)
+				l.EXPECT().GetLimitRulesForAccount(args.ctx, args.accountId, entity.AccountTypePersonal, args.operationType).
+					Return(nil, errors.New("some error"))
+			},
+			wantErr: ErrCannotCheckLimits,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// init deps
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			// init mocks
+			limitRepo := repomocks.NewMockLimit(ctrl)
+			accountRepo := repomocks.NewMockAccount(ctrl)
+			tc.mockBehavior(limitRepo, accountRepo, tc.args)
+
+			// init service
+			s := NewLimitService(limitRepo, accountRepo)
+
+			// run test
+			got, err := s.CheckLimits(tc.args.ctx, tc.args.accountId, tc.args.operationType, tc.args.amount)
+			if tc.wantErr != nil {
+				assert.ErrorIs(t, err, tc.wantErr)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestLimitRepoError(t *testing.T) {
+	testCases := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{
+			name: "max total",
+			err: fmt.Errorf("AccountRepo.Transfer - transferMoney: %w", &repoerrs.LimitExceededError{
+				Rule:          entity.LimitRule{LimitType: entity.LimitTypeMaxTotal, Value: 1000, WindowSeconds: 86400},
+				OperationType: entity.OperationTypeTransferFrom,
+			}),
+			want: "limit exceeded: transfer_from total must not exceed 1000 per 24h0m0s",
+		},
+		{
+			name: "max count",
+			err: &repoerrs.LimitExceededError{
+				Rule:          entity.LimitRule{LimitType: entity.LimitTypeMaxCount, Value: 5, WindowSeconds: 3600},
+				OperationType: entity.OperationTypeReservation,
+			},
+			want: "limit exceeded: at most 5 reservation operations per 1h0m0s",
+		},
+		{
+			name: "no rule",
+			err:  repoerrs.ErrLimitExceeded,
+			want: "limit exceeded",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := limitRepoError(tc.err)
+			assert.ErrorIs(t, err, ErrLimitExceeded)
+			assert.EqualError(t, err, tc.want)
+		})
+	}
+}
@@ -1,27 +1,35 @@
 package service
 
 import (
+	"account-management-service/internal/entity"
 	"account-management-service/internal/repo"
 	"account-management-service/internal/webapi"
+	"account-management-service/pkg/tabular"
 	"bytes"
 	"context"
-	"encoding/csv"
 	"errors"
-	"fmt"
-	"strconv"
+	log "github.com/sirupsen/logrus"
+	"io"
+	"path"
+	"strings"
+	"time"
 )
 
 type OperationService struct {
 	operationRepo repo.Operation
 	productRepo   repo.Product
-	gDrive        webapi.GDrive
+	archiveRepo   repo.ReportArchive
+	reportStorage webapi.ReportStorage
+	reportLinks   ReportLink
 }
 
-func NewOperationService(operationRepo repo.Operation, productRepo repo.Product, gDrive webapi.GDrive) *OperationService {
+func NewOperationService(operationRepo repo.Operation, productRepo repo.Product, archiveRepo repo.ReportArchive, reportStorage webapi.ReportStorage, reportLinks ReportLink) *OperationService {
 	return &OperationService{
 		operationRepo: operationRepo,
 		productRepo:   productRepo,
-		gDrive:        gDrive,
+		archiveRepo:   archiveRepo,
+		reportStorage: reportStorage,
+		reportLinks:   reportLinks,
 	}
 }
 
@@ -38,52 +46,264 @@ func (s *OperationService) OperationHistory(ctx context.Context, input Operation
 			Amount:      operation.Amount,
 			Operation:   operation.OperationType,
 			Time:        operation.CreatedAt,
+			Wallet:      operation.WalletType,
 			Product:     productNames[i],
 			Order:       operation.OrderId,
 			Description: operation.Description,
+			Member:      operation.UserId,
 		})
 	}
 	return output, nil
 }
 
-func (s *OperationService) MakeReportLink(ctx context.Context, month, year int) (string, error) {
-	if !s.gDrive.IsAvailable() {
-		return "", errors.New("google drive is not available")
+func (s *OperationService) MakeReportLink(ctx context.Context, params entity.ReportParams, createdBy int) (string, error) {
+	if !s.reportStorage.IsAvailable() {
+		return "", errors.New("report storage is not available")
 	}
 
-	file, err := s.MakeReportFile(ctx, month, year)
+	name, url, err := s.UploadReport(ctx, params, createdBy)
 	if err != nil {
 		return "", err
 	}
 
-	url, err := s.gDrive.UploadCSVFile(ctx, fmt.Sprintf("report_%d_%d.csv", month, year), file)
+	return s.reportLinks.ShareLink(ctx, name, url)
+}
+
+// UploadReport streams the report to the report storage and returns its file name and url.
+// createdBy is the user requesting the report, 0 for the reports generated by the service itself
+func (s *OperationService) UploadReport(ctx context.Context, params entity.ReportParams, createdBy int) (string, string, error) {
+	params, err := normalizeReportParams(params)
 	if err != nil {
-		return "", errors.New("failed to upload csv file")
+		return "", "", err
 	}
 
-	return url, nil
+	name := params.Filename()
+	url, err := s.uploadStream(ctx, newArchivedReport(name, entity.ArchivedReportKindReport, params.From, params.To, createdBy), func(w io.Writer) error {
+		return s.WriteReport(ctx, params, w)
+	})
+	if err != nil {
+		return "", "", err
+	}
+
+	return name, url, nil
 }
 
-func (s *OperationService) MakeReportFile(ctx context.Context, month, year int) ([]byte, error) {
-	products, amounts, err := s.operationRepo.GetAllRevenueOperationsGroupedByProduct(ctx, month, year)
+// uploadStream uploads the file to the report storage as write writes it, so the file is never kept in memory.
+// If the file fails to be written, the error of write is returned rather than the upload error it causes.
+// The uploaded file is recorded in the archive with its size
+func (s *OperationService) uploadStream(ctx context.Context, report entity.ArchivedReport, write func(w io.Writer) error) (string, error) {
+	name := report.Filename
+	pr, pw := io.Pipe()
+	counter := &countingWriter{w: pw}
+
+	written := make(chan error, 1)
+	go func() {
+		err := write(counter)
+		// the result is sent before the pipe is closed, so it is known once the upload has read the end of the pipe
+		written <- err
+		_ = pw.CloseWithError(err)
+	}()
+
+	url, err := s.reportStorage.UploadStream(ctx, name, pr)
 	if err != nil {
-		return nil, errors.New("failed to get revenue operations")
+		select {
+		case writeErr := <-written:
+			if writeErr != nil {
+				return "", writeErr
+			}
+		default:
+			// the upload has stopped reading, closing the pipe stops write
+			_ = pr.CloseWithError(err)
+			<-written
+		}
+
+		log.Errorf("OperationService.uploadStream - s.reportStorage.UploadStream: %v", err)
+		return "", errors.New("failed to upload report file")
 	}
 
-	b := bytes.Buffer{}
-	w := csv.NewWriter(&b)
+	if err = <-written; err != nil {
+		return "", err
+	}
 
-	for i := range products {
-		err := w.Write([]string{products[i], strconv.Itoa(amounts[i])})
-		if err != nil {
-			return nil, errors.New("failed to write csv")
-		}
+	// the file is uploaded anyway, so it is not failed because of the archive
+	report.Size = &counter.n
+	if _, err = s.archiveRepo.SaveArchivedReport(ctx, report); err != nil {
+		log.Errorf("OperationService.uploadStream - s.archiveRepo.SaveArchivedReport: %v", err)
 	}
 
-	w.Flush()
-	if err := w.Error(); err != nil {
-		return nil, errors.New("failed to write csv")
+	return url, nil
+}
+
+// newArchivedReport returns the archive record of the file, the format is told by the extension
+func newArchivedReport(name, kind string, from, to time.Time, createdBy int) entity.ArchivedReport {
+	report := entity.ArchivedReport{
+		Filename:   name,
+		Kind:       kind,
+		PeriodFrom: &from,
+		PeriodTo:   &to,
+		Format:     strings.TrimPrefix(path.Ext(name), "."),
+	}
+	if createdBy != 0 {
+		report.CreatedBy = &createdBy
+	}
+	return report
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	n, err := w.w.Write(p)
+	w.n += int64(n)
+	return n, err
+}
+
+// MakeReportFile makes the revenue report of the requested version and format. By default it is the products report
+// in csv of the legacy headerless version, other modes, granularities and formats get the current version
+func (s *OperationService) MakeReportFile(ctx context.Context, params entity.ReportParams) ([]byte, error) {
+	b := bytes.Buffer{}
+	if err := s.WriteReport(ctx, params, &b); err != nil {
+		return nil, err
 	}
 
 	return b.Bytes(), nil
 }
+
+// WriteReport writes the report to w. The accounts report is written as it is read from the database,
+// so nothing is written to w if the report fails before the first row is read
+func (s *OperationService) WriteReport(ctx context.Context, params entity.ReportParams, w io.Writer) error {
+	params, err := normalizeReportParams(params)
+	if err != nil {
+		return err
+	}
+
+	enc := newReportEncoder(params, w)
+
+	if params.Mode == entity.ReportModeAccounts {
+		err = s.writeAccountsReport(ctx, params, enc)
+	} else {
+		err = s.writeProductsReport(ctx, params, enc)
+	}
+	if err != nil {
+		return err
+	}
+
+	if err = enc.Close(); err != nil {
+		log.Errorf("OperationService.WriteReport - enc.Close: %v", err)
+		return errors.New("failed to write report")
+	}
+
+	return nil
+}
+
+func (s *OperationService) writeProductsReport(ctx context.Context, params entity.ReportParams, enc tabular.Encoder) error {
+	if params.Granularity != "" {
+		return s.writeSeriesReport(ctx, params, enc)
+	}
+
+	revenues, err := s.operationRepo.GetAllRevenueOperationsGroupedByProduct(ctx, params.From, params.To)
+	if err != nil {
+		return errors.New("failed to get revenue operations")
+	}
+
+	if params.Version == entity.ReportVersionLegacy {
+		err = writeLegacyRevenueReport(enc, revenues)
+	} else {
+		err = writeRevenueReport(enc, revenues)
+	}
+	if err != nil {
+		return errors.New("failed to write report")
+	}
+
+	return nil
+}
+
+func (s *OperationService) writeSeriesReport(ctx context.Context, params entity.ReportParams, enc tabular.Encoder) error {
+	revenues, err := s.operationRepo.GetProductRevenueSeries(ctx, params.From, params.To, params.Granularity)
+	if err != nil {
+		log.Errorf("OperationService.writeSeriesReport - s.operationRepo.GetProductRevenueSeries: %v", err)
+		return errors.New("failed to get revenue operations")
+	}
+
+	periods := reportPeriods(params.From, params.To, params.Granularity)
+	if err = writeRevenueSeriesReport(enc, periods, revenues); err != nil {
+		return errors.New("failed to write report")
+	}
+
+	return nil
+}
+
+func (s *OperationService) writeAccountsReport(ctx context.Context, params entity.ReportParams, enc tabular.Encoder) error {
+	report := newAccountsReportWriter(enc)
+
+	err := s.operationRepo.StreamAccountProductRevenue(ctx, params.From, params.To, params.ProductIds, params.AccountIds, report.Write)
+	if err != nil {
+		log.Errorf("OperationService.writeAccountsReport - s.operationRepo.StreamAccountProductRevenue: %v", err)
+		return errors.New("failed to get revenue operations")
+	}
+
+	if err = report.Close(); err != nil {
+		return errors.New("failed to write report")
+	}
+
+	return nil
+}
+
+// WriteOperationsExport writes the operations of the range to w as they are read from the database,
+// so the memory used does not depend on the number of operations. Cancelling ctx stops the export
+func (s *OperationService) WriteOperationsExport(ctx context.Context, params entity.OperationExportParams, w io.Writer) error {
+	params, err := normalizeOperationExportParams(params)
+	if err != nil {
+		return err
+	}
+
+	enc := newTableEncoder(w, operationExportColumns, params.Format, tabular.CSVOptions{
+		Delimiter: reportDelimiters[params.CSVDelimiter],
+		BOM:       params.CSVBOM,
+	})
+
+	err = s.operationRepo.StreamOperations(ctx, params.From, params.To, params.AccountId, params.OperationTypes,
+		func(operation entity.ExportedOperation) error {
+			return enc.WriteRow(operationExportRow(operation))
+		})
+	if err != nil {
+		log.Errorf("OperationService.WriteOperationsExport - s.operationRepo.StreamOperations: %v", err)
+		return errors.New("failed to export operations")
+	}
+
+	if err = enc.Close(); err != nil {
+		log.Errorf("OperationService.WriteOperationsExport - enc.Close: %v", err)
+		return errors.New("failed to write export")
+	}
+
+	return nil
+}
+
+// MakeOperationsExportLink streams the export to the report storage and returns the link to share it
+func (s *OperationService) MakeOperationsExportLink(ctx context.Context, params entity.OperationExportParams, createdBy int) (string, error) {
+	if !s.reportStorage.IsAvailable() {
+		return "", errors.New("report storage is not available")
+	}
+
+	params, err := normalizeOperationExportParams(params)
+	if err != nil {
+		return "", err
+	}
+
+	name := params.Filename()
+	url, err := s.uploadStream(ctx, newArchivedReport(name, entity.ArchivedReportKindExport, params.From, params.To, createdBy), func(w io.Writer) error {
+		return s.WriteOperationsExport(ctx, params, w)
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return s.reportLinks.ShareLink(ctx, name, url)
+}
+
+// MemberSpending returns how much each member has spent from the shared account
+func (s *OperationService) MemberSpending(ctx context.Context, accountId int) ([]entity.MemberSpending, error) {
+	return s.operationRepo.GetMemberSpending(ctx, accountId)
+}
@@ -0,0 +1,93 @@
+package service
+
+import (
+	"account-management-service/internal/entity"
+	"account-management-service/pkg/tabular"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// operationExportColumns are the columns of the operations export
+var operationExportColumns = []tabular.Column{
+	{Name: "id", Type: tabular.Int},
+	{Name: "account_id", Type: tabular.Int},
+	{Name: "operation_type", Type: tabular.String},
+	{Name: "amount", Type: tabular.Int},
+	{Name: "wallet_type", Type: tabular.String},
+	{Name: "product", Type: tabular.String},
+	{Name: "order_id", Type: tabular.Int},
+	{Name: "user_id", Type: tabular.Int},
+	{Name: "description", Type: tabular.String},
+	{Name: "created_at", Type: tabular.String},
+}
+
+// normalizeOperationExportParams checks the range and the format and sorts the operation types,
+// so that identical exports have the same file name
+func normalizeOperationExportParams(params entity.OperationExportParams) (entity.OperationExportParams, error) {
+	if params.From.IsZero() || params.To.IsZero() {
+		return entity.OperationExportParams{}, fmt.Errorf("%w: the range is required", ErrInvalidReportParams)
+	}
+	if !params.To.After(params.From) {
+		return entity.OperationExportParams{}, fmt.Errorf("%w: the end of the range must be after its start", ErrInvalidReportParams)
+	}
+	params.From = params.From.UTC()
+	params.To = params.To.UTC()
+
+	var err error
+	params.Format, params.CSVDelimiter, err = normalizeReportFormat(params.Format, params.CSVDelimiter, params.CSVBOM)
+	if err != nil {
+		return entity.OperationExportParams{}, err
+	}
+
+	params.OperationTypes = sortedUniqueStrings(params.OperationTypes)
+
+	return params, nil
+}
+
+func sortedUniqueStrings(values []string) []string {
+	if len(values) == 0 {
+		return nil
+	}
+
+	sorted := make([]string, len(values))
+	copy(sorted, values)
+	sort.Strings(sorted)
+
+	unique := sorted[:1]
+	for _, value := range sorted[1:] {
+		if value != unique[len(unique)-1] {
+			unique = append(unique, value)
+		}
+	}
+	return unique
+}
+
+func operationExportRow(operation entity.ExportedOperation) tabular.Row {
+	return tabular.Row{Values: []any{
+		operation.Id,
+		operation.AccountId,
+		operation.OperationType,
+		operation.Amount,
+		operation.WalletType,
+		emptyToNil(operation.Product),
+		intOrNil(operation.OrderId),
+		intOrNil(operation.UserId),
+		emptyToNil(operation.Description),
+		operation.CreatedAt.UTC().Format(time.RFC3339),
+	}}
+}
+
+func intOrNil(v *int) any {
+	if v == nil {
+		return nil
+	}
+	return *v
+}
+
+func emptyToNil(s string) any {
+	if s == "" {
+		return nil
+	}
+	return s
+}
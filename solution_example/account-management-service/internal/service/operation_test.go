@@ -4,10 +4,12 @@ import (
 	"account-management-service/internal/entity"
 	"account-management-service/internal/mocks/repomocks"
 	"account-management-service/internal/mocks/webapimocks"
+	"bytes"
 	"context"
 	"errors"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
+	"io"
 	"testing"
 	"time"
 )
@@ -18,7 +20,7 @@ func TestOperationService_OperationHistory(t *testing.T) {
 		input OperationHistoryInput
 	}
 
-	type MockBehavior func(o *repomocks.MockOperation, p *repomocks.MockProduct, g *webapimocks.MockGDrive, args args)
+	type MockBehavior func(o *repomocks.MockOperation, p *repomocks.MockProduct, r *webapimocks.MockReportStorage, args args)
 
 	testCases := []struct {
 		name         string
@@ -35,7 +37,7 @@ func TestOperationService_OperationHistory(t *testing.T) {
 					AccountId: 1,
 				},
 			},
-			mockBehavior: func(o *repomocks.MockOperation, p *repomocks.MockProduct, g *webapimocks.MockGDrive, args args) {
+			mockBehavior: func(o *repomocks.MockOperation, p *repomocks.MockProduct, r *webapimocks.MockReportStorage, args args) {
 				o.EXPECT().OperationsPagination(args.ctx, args.input.AccountId, args.input.SortType, args.input.Offset, args.input.Limit).
 					Return([]entity.Operation{
 						{
@@ -44,6 +46,7 @@ func TestOperationService_OperationHistory(t *testing.T) {
 							Amount:        100,
 							OperationType: entity.OperationTypeDeposit,
 							CreatedAt:     time.UnixMilli(123456),
+							WalletType:    entity.WalletTypeMain,
 							ProductId:     nil,
 							OrderId:       nil,
 							Description:   "",
@@ -57,6 +60,7 @@ func TestOperationService_OperationHistory(t *testing.T) {
 					Amount:      100,
 					Operation:   "deposit",
 					Time:        time.UnixMilli(123456),
+					Wallet:      "main",
 					Product:     "some product name",
 					Order:       nil,
 					Description: "",
@@ -72,7 +76,7 @@ func TestOperationService_OperationHistory(t *testing.T) {
 					AccountId: 1,
 				},
 			},
-			mockBehavior: func(o *repomocks.MockOperation, p *repomocks.MockProduct, g *webapimocks.MockGDrive, args args) {
+			mockBehavior: func(o *repomocks.MockOperation, p *repomocks.MockProduct, r *webapimocks.MockReportStorage, args args) {
 				o.EXPECT().OperationsPagination(args.ctx, args.input.AccountId, args.input.SortType, args.input.Offset, args.input.Limit).
 					Return(nil, nil, errors.New("some error"))
 			},
@@ -90,11 +94,11 @@ func TestOperationService_OperationHistory(t *testing.T) {
 			// init mocks
 			operationRepo := repomocks.NewMockOperation(ctrl)
 			productRepo := repomocks.NewMockProduct(ctrl)
-			gDrive := webapimocks.NewMockGDrive(ctrl)
-			tc.mockBehavior(operationRepo, productRepo, gDrive, tc.args)
+			reportStorage := webapimocks.NewMockReportStorage(ctrl)
+			tc.mockBehavior(operationRepo, productRepo, reportStorage, tc.args)
 
 			// init service
-			s := NewOperationService(operationRepo, productRepo, gDrive)
+			s := NewOperationService(operationRepo, productRepo, nil, reportStorage, nil)
 
 			// run test
 			got, err := s.OperationHistory(tc.args.ctx, tc.args.input)
@@ -110,13 +114,15 @@ func TestOperationService_OperationHistory(t *testing.T) {
 }
 
 func TestOperationService_MakeReportFile(t *testing.T) {
+	from := time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, 0)
+
 	type args struct {
-		ctx   context.Context
-		month int
-		year  int
+		ctx    context.Context
+		params entity.ReportParams
 	}
 
-	type MockBehavior func(o *repomocks.MockOperation, p *repomocks.MockProduct, g *webapimocks.MockGDrive, args args)
+	type MockBehavior func(o *repomocks.MockOperation, p *repomocks.MockProduct, r *webapimocks.MockReportStorage, args args)
 
 	testCases := []struct {
 		name         string
@@ -128,31 +134,245 @@ func TestOperationService_MakeReportFile(t *testing.T) {
 		{
 			name: "OK",
 			args: args{
-				ctx:   context.Background(),
-				month: 1,
-				year:  2021,
+				ctx:    context.Background(),
+				params: entity.ReportParams{Month: 1, Year: 2021, Version: entity.ReportVersionLegacy},
 			},
-			mockBehavior: func(o *repomocks.MockOperation, p *repomocks.MockProduct, g *webapimocks.MockGDrive, args args) {
-				o.EXPECT().GetAllRevenueOperationsGroupedByProduct(args.ctx, args.month, args.year).
-					Return([]string{
-						"some product name",
-					}, []int{
-						100,
+			mockBehavior: func(o *repomocks.MockOperation, p *repomocks.MockProduct, r *webapimocks.MockReportStorage, args args) {
+				o.EXPECT().GetAllRevenueOperationsGroupedByProduct(args.ctx, from, to).
+					Return([]entity.ProductRevenue{
+						{
+							Product:     "some product name",
+							Amount:      100,
+							MainAmount:  70,
+							BonusAmount: 30,
+							Orders:      2,
+							Revenue:     120,
+							Refunded:    20,
+						},
+						{
+							Product:  "refunded product",
+							Amount:   -10,
+							Refunded: 10,
+						},
+					}, nil)
+			},
+			want:    []byte("some product name,120\n"),
+			wantErr: false,
+		},
+		{
+			name: "without version the legacy report is made",
+			args: args{
+				ctx:    context.Background(),
+				params: entity.ReportParams{Month: 1, Year: 2021},
+			},
+			mockBehavior: func(o *repomocks.MockOperation, p *repomocks.MockProduct, r *webapimocks.MockReportStorage, args args) {
+				o.EXPECT().GetAllRevenueOperationsGroupedByProduct(args.ctx, from, to).
+					Return([]entity.ProductRevenue{
+						{
+							Product:    "storage",
+							Amount:     50,
+							MainAmount: 50,
+							Orders:     1,
+							Revenue:    50,
+						},
 					}, nil)
 			},
-			want:    []byte("some product name,100\n"),
+			want:    []byte("storage,50\n"),
 			wantErr: false,
 		},
+		{
+			name: "current version",
+			args: args{
+				ctx:    context.Background(),
+				params: entity.ReportParams{Month: 1, Year: 2021, Version: entity.ReportVersionCurrent},
+			},
+			mockBehavior: func(o *repomocks.MockOperation, p *repomocks.MockProduct, r *webapimocks.MockReportStorage, args args) {
+				o.EXPECT().GetAllRevenueOperationsGroupedByProduct(args.ctx, from, to).
+					Return([]entity.ProductRevenue{
+						{
+							Product:     "delivery",
+							Amount:      75,
+							MainAmount:  45,
+							BonusAmount: 30,
+							Orders:      2,
+							Revenue:     100,
+							Refunded:    20,
+							Cashback:    5,
+						},
+						{
+							Product:    "storage",
+							Amount:     50,
+							MainAmount: 50,
+							Orders:     1,
+							Revenue:    50,
+						},
+					}, nil)
+			},
+			want: []byte("product,orders,revenue,refunded,cashback,net_revenue,main_amount,bonus_amount,average_check\n" +
+				"delivery,2,100,20,5,75,45,30,37.50\n" +
+				"storage,1,50,0,0,50,50,0,50.00\n" +
+				"total,3,150,20,5,125,95,30,41.67\n"),
+			wantErr: false,
+		},
+		{
+			name: "json format",
+			args: args{
+				ctx:    context.Background(),
+				params: entity.ReportParams{Month: 1, Year: 2021, Format: entity.ReportFormatJSON},
+			},
+			mockBehavior: func(o *repomocks.MockOperation, p *repomocks.MockProduct, r *webapimocks.MockReportStorage, args args) {
+				o.EXPECT().GetAllRevenueOperationsGroupedByProduct(args.ctx, from, to).
+					Return([]entity.ProductRevenue{
+						{
+							Product:    "storage",
+							Amount:     50,
+							MainAmount: 50,
+							Orders:     1,
+							Revenue:    50,
+						},
+					}, nil)
+			},
+			want: []byte("[\n" +
+				`{"product":"storage","orders":1,"revenue":50,"refunded":0,"cashback":0,"net_revenue":50,"main_amount":50,"bonus_amount":0,"average_check":50.00},` + "\n" +
+				`{"product":"total","orders":1,"revenue":50,"refunded":0,"cashback":0,"net_revenue":50,"main_amount":50,"bonus_amount":0,"average_check":50.00}` + "\n" +
+				"]\n"),
+			wantErr: false,
+		},
+		{
+			name: "csv with semicolon and bom",
+			args: args{
+				ctx:    context.Background(),
+				params: entity.ReportParams{Month: 1, Year: 2021, Version: entity.ReportVersionLegacy, CSVDelimiter: entity.ReportDelimiterSemicolon, CSVBOM: true},
+			},
+			mockBehavior: func(o *repomocks.MockOperation, p *repomocks.MockProduct, r *webapimocks.MockReportStorage, args args) {
+				o.EXPECT().GetAllRevenueOperationsGroupedByProduct(args.ctx, from, to).
+					Return([]entity.ProductRevenue{
+						{
+							Product:     "some product name",
+							Amount:      100,
+							MainAmount:  70,
+							BonusAmount: 30,
+							Revenue:     100,
+						},
+					}, nil)
+			},
+			want:    []byte("\xEF\xBB\xBFsome product name;100\n"),
+			wantErr: false,
+		},
+		{
+			name: "legacy version in xlsx",
+			args: args{
+				ctx:    context.Background(),
+				params: entity.ReportParams{Month: 1, Year: 2021, Version: entity.ReportVersionLegacy, Format: entity.ReportFormatXLSX},
+			},
+			mockBehavior: func(o *repomocks.MockOperation, p *repomocks.MockProduct, r *webapimocks.MockReportStorage, args args) {
+			},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "accounts mode",
+			args: args{
+				ctx:    context.Background(),
+				params: entity.ReportParams{Month: 1, Year: 2021, Mode: entity.ReportModeAccounts, ProductIds: []int{2, 1, 2}},
+			},
+			mockBehavior: func(o *repomocks.MockOperation, p *repomocks.MockProduct, r *webapimocks.MockReportStorage, args args) {
+				o.EXPECT().StreamAccountProductRevenue(args.ctx, from, to, []int{1, 2}, nil, gomock.Any()).
+					DoAndReturn(func(_ context.Context, _, _ time.Time, _, _ []int, fn func(entity.AccountProductRevenue) error) error {
+						rows := []entity.AccountProductRevenue{
+							{AccountId: 1, ProductRevenue: entity.ProductRevenue{Product: "delivery", Amount: 80, MainAmount: 80, Orders: 1, Revenue: 100, Refunded: 20}},
+							{AccountId: 1, ProductRevenue: entity.ProductRevenue{Product: "storage", Amount: 50, MainAmount: 50, Orders: 1, Revenue: 50}},
+							{AccountId: 2, ProductRevenue: entity.ProductRevenue{Product: "delivery", Amount: 40, MainAmount: 10, BonusAmount: 30, Orders: 2, Revenue: 40}},
+						}
+						for _, row := range rows {
+							if err := fn(row); err != nil {
+								return err
+							}
+						}
+						return nil
+					})
+			},
+			want: []byte("account_id,product,orders,revenue,refunded,cashback,net_revenue,main_amount,bonus_amount,average_check\n" +
+				"1,delivery,1,100,20,0,80,80,0,80.00\n" +
+				"1,storage,1,50,0,0,50,50,0,50.00\n" +
+				"1,total,2,150,20,0,130,130,0,65.00\n" +
+				"2,delivery,2,40,0,0,40,10,30,20.00\n" +
+				"2,total,2,40,0,0,40,10,30,20.00\n" +
+				"total,delivery,3,140,20,0,120,90,30,40.00\n" +
+				"total,storage,1,50,0,0,50,50,0,50.00\n" +
+				"total,total,4,190,20,0,170,140,30,42.50\n"),
+			wantErr: false,
+		},
+		{
+			name: "filtered products report",
+			args: args{
+				ctx:    context.Background(),
+				params: entity.ReportParams{Month: 1, Year: 2021, AccountIds: []int{1}},
+			},
+			mockBehavior: func(o *repomocks.MockOperation, p *repomocks.MockProduct, r *webapimocks.MockReportStorage, args args) {
+			},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "time series",
+			args: args{
+				ctx:    context.Background(),
+				params: entity.ReportParams{From: from.AddDate(0, 0, 10), To: from.AddDate(0, 0, 24), Granularity: entity.ReportGranularityWeek},
+			},
+			mockBehavior: func(o *repomocks.MockOperation, p *repomocks.MockProduct, r *webapimocks.MockReportStorage, args args) {
+				o.EXPECT().GetProductRevenueSeries(args.ctx, from.AddDate(0, 0, 10), from.AddDate(0, 0, 24), entity.ReportGranularityWeek).
+					Return([]entity.PeriodProductRevenue{
+						{
+							Period:         time.Date(2021, time.January, 11, 0, 0, 0, 0, time.UTC),
+							ProductRevenue: entity.ProductRevenue{Product: "delivery", Amount: 100, MainAmount: 100, Orders: 2, Revenue: 100},
+						},
+						{
+							Period:         time.Date(2021, time.January, 18, 0, 0, 0, 0, time.UTC),
+							ProductRevenue: entity.ProductRevenue{Product: "storage", Amount: 50, MainAmount: 50, Orders: 1, Revenue: 50},
+						},
+					}, nil)
+			},
+			want: []byte("period,product,orders,revenue,refunded,cashback,net_revenue,main_amount,bonus_amount,average_check\n" +
+				"2021-01-11,delivery,2,100,0,0,100,100,0,50.00\n" +
+				"2021-01-18,delivery,0,0,0,0,0,0,0,\n" +
+				"2021-01-11,storage,0,0,0,0,0,0,0,\n" +
+				"2021-01-18,storage,1,50,0,0,50,50,0,50.00\n" +
+				"2021-01-11,total,2,100,0,0,100,100,0,50.00\n" +
+				"2021-01-18,total,1,50,0,0,50,50,0,50.00\n"),
+			wantErr: false,
+		},
+		{
+			name: "too many periods",
+			args: args{
+				ctx:    context.Background(),
+				params: entity.ReportParams{From: from, To: from.AddDate(2, 0, 0), Granularity: entity.ReportGranularityDay},
+			},
+			mockBehavior: func(o *repomocks.MockOperation, p *repomocks.MockProduct, r *webapimocks.MockReportStorage, args args) {
+			},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "unknown version",
+			args: args{
+				ctx:    context.Background(),
+				params: entity.ReportParams{Month: 1, Year: 2021, Version: 3},
+			},
+			mockBehavior: func(o *repomocks.MockOperation, p *repomocks.MockProduct, r *webapimocks.MockReportStorage, args args) {
+			},
+			want:    nil,
+			wantErr: true,
+		},
 		{
 			name: "get all revenue operations grouped by product error",
 			args: args{
-				ctx:   context.Background(),
-				month: 1,
-				year:  2021,
+				ctx:    context.Background(),
+				params: entity.ReportParams{Month: 1, Year: 2021, Version: entity.ReportVersionLegacy},
 			},
-			mockBehavior: func(o *repomocks.MockOperation, p *repomocks.MockProduct, g *webapimocks.MockGDrive, args args) {
-				o.EXPECT().GetAllRevenueOperationsGroupedByProduct(args.ctx, args.month, args.year).
-					Return(nil, nil, errors.New("some error"))
+			mockBehavior: func(o *repomocks.MockOperation, p *repomocks.MockProduct, r *webapimocks.MockReportStorage, args args) {
+				o.EXPECT().GetAllRevenueOperationsGroupedByProduct(args.ctx, from, to).
+					Return(nil, errors.New("some error"))
 			},
 			want:    nil,
 			wantErr: true,
@@ -168,14 +388,14 @@ func TestOperationService_MakeReportFile(t *testing.T) {
 			// init mocks
 			operationRepo := repomocks.NewMockOperation(ctrl)
 			productRepo := repomocks.NewMockProduct(ctrl)
-			gDrive := webapimocks.NewMockGDrive(ctrl)
-			tc.mockBehavior(operationRepo, productRepo, gDrive, tc.args)
+			reportStorage := webapimocks.NewMockReportStorage(ctrl)
+			tc.mockBehavior(operationRepo, productRepo, reportStorage, tc.args)
 
 			// init service
-			s := NewOperationService(operationRepo, productRepo, gDrive)
+			s := NewOperationService(operationRepo, productRepo, nil, reportStorage, nil)
 
 			// run test
-			got, err := s.MakeReportFile(tc.args.ctx, tc.args.month, tc.args.year)
+			got, err := s.MakeReportFile(tc.args.ctx, tc.args.params)
 			if tc.wantErr {
 				assert.Error(t, err)
 				return
@@ -188,13 +408,27 @@ func TestOperationService_MakeReportFile(t *testing.T) {
 }
 
 func TestOperationService_MakeReportLink(t *testing.T) {
+	from := time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, 0)
+
+	createdBy := 1
+	size := int64(len("some product name,100\n"))
+	archived := entity.ArchivedReport{
+		Filename:   "report_1_2021.csv",
+		Kind:       entity.ArchivedReportKindReport,
+		PeriodFrom: &from,
+		PeriodTo:   &to,
+		Format:     "csv",
+		CreatedBy:  &createdBy,
+		Size:       &size,
+	}
+
 	type args struct {
-		ctx   context.Context
-		month int
-		year  int
+		ctx    context.Context
+		params entity.ReportParams
 	}
 
-	type MockBehavior func(o *repomocks.MockOperation, p *repomocks.MockProduct, g *webapimocks.MockGDrive, args args)
+	type MockBehavior func(o *repomocks.MockOperation, p *repomocks.MockProduct, a *repomocks.MockReportArchive, r *webapimocks.MockReportStorage, args args)
 
 	testCases := []struct {
 		name         string
@@ -206,35 +440,67 @@ func TestOperationService_MakeReportLink(t *testing.T) {
 		{
 			name: "OK",
 			args: args{
-				ctx:   context.Background(),
-				month: 1,
-				year:  2021,
+				ctx:    context.Background(),
+				params: entity.ReportParams{Month: 1, Year: 2021, Version: entity.ReportVersionLegacy},
 			},
-			mockBehavior: func(o *repomocks.MockOperation, p *repomocks.MockProduct, g *webapimocks.MockGDrive, args args) {
-				g.EXPECT().IsAvailable().Return(true)
+			mockBehavior: func(o *repomocks.MockOperation, p *repomocks.MockProduct, a *repomocks.MockReportArchive, r *webapimocks.MockReportStorage, args args) {
+				r.EXPECT().IsAvailable().Return(true)
 
-				o.EXPECT().GetAllRevenueOperationsGroupedByProduct(args.ctx, args.month, args.year).
-					Return([]string{
-						"some product name",
-					}, []int{
-						100,
+				o.EXPECT().GetAllRevenueOperationsGroupedByProduct(args.ctx, from, to).
+					Return([]entity.ProductRevenue{
+						{
+							Product:     "some product name",
+							Amount:      100,
+							MainAmount:  70,
+							BonusAmount: 30,
+							Revenue:     100,
+						},
 					}, nil)
 
-				g.EXPECT().UploadCSVFile(args.ctx, "report_1_2021.csv", []byte("some product name,100\n")).
-					Return("https://example.com", nil)
+				r.EXPECT().UploadStream(args.ctx, "report_1_2021.csv", gomock.Any()).
+					DoAndReturn(readUpload(t, "some product name,100\n", "https://example.com", nil))
+				a.EXPECT().SaveArchivedReport(args.ctx, archived).
+					Return(1, nil)
 			},
 			want:    "https://example.com",
 			wantErr: false,
 		},
 		{
-			name: "gdrive is not available",
+			name: "archive error does not fail uploaded report",
 			args: args{
-				ctx:   context.Background(),
-				month: 1,
-				year:  2021,
+				ctx:    context.Background(),
+				params: entity.ReportParams{Month: 1, Year: 2021, Version: entity.ReportVersionLegacy},
 			},
-			mockBehavior: func(o *repomocks.MockOperation, p *repomocks.MockProduct, g *webapimocks.MockGDrive, args args) {
-				g.EXPECT().IsAvailable().Return(false)
+			mockBehavior: func(o *repomocks.MockOperation, p *repomocks.MockProduct, a *repomocks.MockReportArchive, r *webapimocks.MockReportStorage, args args) {
+				r.EXPECT().IsAvailable().Return(true)
+
+				o.EXPECT().GetAllRevenueOperationsGroupedByProduct(args.ctx, from, to).
+					Return([]entity.ProductRevenue{
+						{
+							Product:     "some product name",
+							Amount:      100,
+							MainAmount:  70,
+							BonusAmount: 30,
+							Revenue:     100,
+						},
+					}, nil)
+
+				r.EXPECT().UploadStream(args.ctx, "report_1_2021.csv", gomock.Any()).
+					DoAndReturn(readUpload(t, "some product name,100\n", "https://example.com", nil))
+				a.EXPECT().SaveArchivedReport(args.ctx, archived).
+					Return(0, errors.New("some error"))
+			},
+			want:    "https://example.com",
+			wantErr: false,
+		},
+		{
+			name: "report storage is not available",
+			args: args{
+				ctx:    context.Background(),
+				params: entity.ReportParams{Month: 1, Year: 2021, Version: entity.ReportVersionLegacy},
+			},
+			mockBehavior: func(o *repomocks.MockOperation, p *repomocks.MockProduct, a *repomocks.MockReportArchive, r *webapimocks.MockReportStorage, args args) {
+				r.EXPECT().IsAvailable().Return(false)
 			},
 			want:    "",
 			wantErr: true,
@@ -242,15 +508,16 @@ func TestOperationService_MakeReportLink(t *testing.T) {
 		{
 			name: "get all revenue operations grouped by product error",
 			args: args{
-				ctx:   context.Background(),
-				month: 1,
-				year:  2021,
+				ctx:    context.Background(),
+				params: entity.ReportParams{Month: 1, Year: 2021, Version: entity.ReportVersionLegacy},
 			},
-			mockBehavior: func(o *repomocks.MockOperation, p *repomocks.MockProduct, g *webapimocks.MockGDrive, args args) {
-				g.EXPECT().IsAvailable().Return(true)
+			mockBehavior: func(o *repomocks.MockOperation, p *repomocks.MockProduct, a *repomocks.MockReportArchive, r *webapimocks.MockReportStorage, args args) {
+				r.EXPECT().IsAvailable().Return(true)
 
-				o.EXPECT().GetAllRevenueOperationsGroupedByProduct(args.ctx, args.month, args.year).
-					Return(nil, nil, errors.New("some error"))
+				o.EXPECT().GetAllRevenueOperationsGroupedByProduct(args.ctx, from, to).
+					Return(nil, errors.New("some error"))
+				r.EXPECT().UploadStream(args.ctx, "report_1_2021.csv", gomock.Any()).
+					DoAndReturn(readUpload(t, "", "", nil))
 			},
 			want:    "",
 			wantErr: true,
@@ -258,22 +525,25 @@ func TestOperationService_MakeReportLink(t *testing.T) {
 		{
 			name: "upload csv file error",
 			args: args{
-				ctx:   context.Background(),
-				month: 1,
-				year:  2021,
+				ctx:    context.Background(),
+				params: entity.ReportParams{Month: 1, Year: 2021, Version: entity.ReportVersionLegacy},
 			},
-			mockBehavior: func(o *repomocks.MockOperation, p *repomocks.MockProduct, g *webapimocks.MockGDrive, args args) {
-				g.EXPECT().IsAvailable().Return(true)
+			mockBehavior: func(o *repomocks.MockOperation, p *repomocks.MockProduct, a *repomocks.MockReportArchive, r *webapimocks.MockReportStorage, args args) {
+				r.EXPECT().IsAvailable().Return(true)
 
-				o.EXPECT().GetAllRevenueOperationsGroupedByProduct(args.ctx, args.month, args.year).
-					Return([]string{
-						"some product name",
-					}, []int{
-						100,
+				o.EXPECT().GetAllRevenueOperationsGroupedByProduct(args.ctx, from, to).
+					Return([]entity.ProductRevenue{
+						{
+							Product:     "some product name",
+							Amount:      100,
+							MainAmount:  70,
+							BonusAmount: 30,
+							Revenue:     100,
+						},
 					}, nil)
 
-				g.EXPECT().UploadCSVFile(args.ctx, "report_1_2021.csv", []byte("some product name,100\n")).
-					Return("", errors.New("some error"))
+				r.EXPECT().UploadStream(args.ctx, "report_1_2021.csv", gomock.Any()).
+					DoAndReturn(readUpload(t, "some product name,100\n", "", errors.New("some error")))
 			},
 			want:    "",
 			wantErr: true,
@@ -289,14 +559,15 @@ func TestOperationService_MakeReportLink(t *testing.T) {
 			// init mocks
 			operationRepo := repomocks.NewMockOperation(ctrl)
 			productRepo := repomocks.NewMockProduct(ctrl)
-			gDrive := webapimocks.NewMockGDrive(ctrl)
-			tc.mockBehavior(operationRepo, productRepo, gDrive, tc.args)
+			archiveRepo := repomocks.NewMockReportArchive(ctrl)
+			reportStorage := webapimocks.NewMockReportStorage(ctrl)
+			tc.mockBehavior(operationRepo, productRepo, archiveRepo, reportStorage, tc.args)
 
 			// init service
-			s := NewOperationService(operationRepo, productRepo, gDrive)
+			s := NewOperationService(operationRepo, productRepo, archiveRepo, reportStorage, NewReportLinkService(nil, reportStorage, false, "", "", 0, false))
 
 			// run test
-			got, err := s.MakeReportLink(tc.args.ctx, tc.args.month, tc.args.year)
+			got, err := s.MakeReportLink(tc.args.ctx, tc.args.params, createdBy)
 			if tc.wantErr {
 				assert.Error(t, err)
 				return
@@ -307,3 +578,152 @@ func TestOperationService_MakeReportLink(t *testing.T) {
 		})
 	}
 }
+
+func TestOperationService_WriteOperationsExport(t *testing.T) {
+	type args struct {
+		ctx    context.Context
+		params entity.OperationExportParams
+	}
+
+	type MockBehavior func(o *repomocks.MockOperation, args args)
+
+	from := time.Date(2022, time.January, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, 0)
+	orderId := 5
+	operations := []entity.ExportedOperation{
+		{
+			Operation: entity.Operation{
+				Id:            1,
+				AccountId:     1,
+				Amount:        100,
+				OperationType: entity.OperationTypeDeposit,
+				CreatedAt:     from.Add(time.Hour),
+				WalletType:    entity.WalletTypeMain,
+			},
+		},
+		{
+			Operation: entity.Operation{
+				Id:            2,
+				AccountId:     1,
+				Amount:        30,
+				OperationType: entity.OperationTypeRevenue,
+				CreatedAt:     from.Add(2 * time.Hour),
+				WalletType:    entity.WalletTypeMain,
+				OrderId:       &orderId,
+				Description:   "order; paid",
+			},
+			Product: "product",
+		},
+	}
+	streamOperations := func(_ context.Context, _, _ time.Time, _ int, _ []string, fn func(entity.ExportedOperation) error) error {
+		for _, operation := range operations {
+			if err := fn(operation); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	testCases := []struct {
+		name         string
+		args         args
+		mockBehavior MockBehavior
+		want         string
+		wantErr      bool
+	}{
+		{
+			name: "OK",
+			args: args{
+				ctx:    context.Background(),
+				params: entity.OperationExportParams{From: from, To: to},
+			},
+			mockBehavior: func(o *repomocks.MockOperation, args args) {
+				o.EXPECT().StreamOperations(args.ctx, from, to, 0, nil, gomock.Any()).
+					DoAndReturn(streamOperations)
+			},
+			want: "id,account_id,operation_type,amount,wallet_type,product,order_id,user_id,description,created_at\n" +
+				"1,1,deposit,100,main,,,,,2022-01-01T01:00:00Z\n" +
+				"2,1,revenue,30,main,product,5,,order; paid,2022-01-01T02:00:00Z\n",
+		},
+		{
+			name: "semicolon delimiter and sorted operation types",
+			args: args{
+				ctx: context.Background(),
+				params: entity.OperationExportParams{
+					From:           from,
+					To:             to,
+					AccountId:      1,
+					OperationTypes: []string{"revenue", "deposit", "revenue"},
+					CSVDelimiter:   entity.ReportDelimiterSemicolon,
+				},
+			},
+			mockBehavior: func(o *repomocks.MockOperation, args args) {
+				o.EXPECT().StreamOperations(args.ctx, from, to, 1, []string{"deposit", "revenue"}, gomock.Any()).
+					DoAndReturn(streamOperations)
+			},
+			want: "id;account_id;operation_type;amount;wallet_type;product;order_id;user_id;description;created_at\n" +
+				"1;1;deposit;100;main;;;;;2022-01-01T01:00:00Z\n" +
+				"2;1;revenue;30;main;product;5;;\"order; paid\";2022-01-01T02:00:00Z\n",
+		},
+		{
+			name: "empty range",
+			args: args{
+				ctx:    context.Background(),
+				params: entity.OperationExportParams{From: from, To: from},
+			},
+			mockBehavior: func(o *repomocks.MockOperation, args args) {},
+			wantErr:      true,
+		},
+		{
+			name: "stream operations error",
+			args: args{
+				ctx:    context.Background(),
+				params: entity.OperationExportParams{From: from, To: to},
+			},
+			mockBehavior: func(o *repomocks.MockOperation, args args) {
+				o.EXPECT().StreamOperations(args.ctx, from, to, 0, nil, gomock.Any()).
+					Return(errors.New("some error"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// init deps
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			// init mocks
+			operationRepo := repomocks.NewMockOperation(ctrl)
+			tc.mockBehavior(operationRepo, tc.args)
+
+			// init service
+			s := NewOperationService(operationRepo, nil, nil, nil, nil)
+
+			// run test
+			var b bytes.Buffer
+			err := s.WriteOperationsExport(tc.args.ctx, tc.args.params, &b)
+			if tc.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want, b.String())
+		})
+	}
+}
+
+// readUpload reads the streamed file like the report storage does. The file is compared with want
+// once it is read completely, the read error is returned as the upload error
+func readUpload(t *testing.T, want, url string, err error) func(context.Context, string, io.Reader) (string, error) {
+	return func(_ context.Context, _ string, r io.Reader) (string, error) {
+		data, readErr := io.ReadAll(r)
+		if readErr != nil {
+			return "", readErr
+		}
+		assert.Equal(t, want, string(data))
+		return url, err
+	}
+}
@@ -0,0 +1,122 @@
+package service
+
+import (
+	"account-management-service/internal/entity"
+	"account-management-service/internal/repo"
+	"account-management-service/internal/repo/repoerrs"
+	"context"
+	"errors"
+	log "github.com/sirupsen/logrus"
+	"time"
+)
+
+type PaymentRequestService struct {
+	paymentRequestRepo  repo.PaymentRequest
+	limitService        Limit
+	verificationService Verification
+	feeService          Fee
+	ttl                 time.Duration
+}
+
+func NewPaymentRequestService(paymentRequestRepo repo.PaymentRequest, limitService Limit, verificationService Verification, feeService Fee, ttl time.Duration) *PaymentRequestService {
+	return &PaymentRequestService{
+		paymentRequestRepo:  paymentRequestRepo,
+		limitService:        limitService,
+		verificationService: verificationService,
+		feeService:          feeService,
+		ttl:                 ttl,
+	}
+}
+
+func (s *PaymentRequestService) CreatePaymentRequest(ctx context.Context, input PaymentRequestCreateInput) (int, error) {
+	if input.RequesterAccountId == input.PayerAccountId {
+		return 0, ErrCannotCreatePaymentRequest
+	}
+
+	id, err := s.paymentRequestRepo.CreatePaymentRequest(ctx, entity.PaymentRequest{
+		RequesterAccountId: input.RequesterAccountId,
+		PayerAccountId:     input.PayerAccountId,
+		Amount:             input.Amount,
+		Description:        input.Description,
+		ExpiresAt:          time.Now().Add(s.ttl),
+	})
+	if err != nil {
+		log.Errorf("PaymentRequestService.CreatePaymentRequest - s.paymentRequestRepo.CreatePaymentRequest: %v", err)
+		return 0, ErrCannotCreatePaymentRequest
+	}
+
+	return id, nil
+}
+
+func (s *PaymentRequestService) GetPaymentRequestById(ctx context.Context, id int) (entity.PaymentRequest, error) {
+	request, err := s.paymentRequestRepo.GetPaymentRequestById(ctx, id)
+	if err != nil {
+		if errors.Is(err, repoerrs.ErrNotFound) {
+			return entity.PaymentRequest{}, ErrPaymentRequestNotFound
+		}
+		return entity.PaymentRequest{}, err
+	}
+
+	return request, nil
+}
+
+func (s *PaymentRequestService) GetAccountPaymentRequests(ctx context.Context, accountId int, status string) ([]entity.PaymentRequest, error) {
+	return s.paymentRequestRepo.GetPaymentRequestsByAccountId(ctx, accountId, status)
+}
+
+// AcceptPaymentRequest pays the request, the payment is an ordinary transfer from the payer and is subject to its limits,
+// verification and fees
+func (s *PaymentRequestService) AcceptPaymentRequest(ctx context.Context, id, userId int) error {
+	request, err := s.GetPaymentRequestById(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	var fee entity.Fee
+	var limits []entity.LimitRule
+	var verification entity.VerificationCheck
+	if request.Status == entity.PaymentRequestStatusPending {
+		limits, err = s.limitService.CheckLimits(ctx, request.PayerAccountId, entity.OperationTypeTransferFrom, request.Amount)
+		if err != nil {
+			return err
+		}
+
+		verification, err = s.verificationService.CheckVerification(ctx, request.PayerAccountId, entity.OperationTypeTransferFrom, request.Amount)
+		if err != nil {
+			return err
+		}
+
+		fee, err = s.feeService.CalculateFee(ctx, FeeCalculateInput{
+			AccountId:     request.PayerAccountId,
+			OperationType: entity.OperationTypeTransferFrom,
+			Amount:        request.Amount,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	err = s.paymentRequestRepo.AcceptPaymentRequest(ctx, id, &userId, fee, limits, verification)
+	return paymentRequestRepoError(err)
+}
+
+func (s *PaymentRequestService) DeclinePaymentRequest(ctx context.Context, id int) error {
+	err := s.paymentRequestRepo.DeclinePaymentRequest(ctx, id)
+	return paymentRequestRepoError(err)
+}
+
+func paymentRequestRepoError(err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, repoerrs.ErrExpired):
+		return ErrPaymentRequestExpired
+	case errors.Is(err, repoerrs.ErrWrongStatus):
+		return ErrPaymentRequestNotPending
+	// accounts are referenced by foreign keys, so only the request itself may be missing
+	case errors.Is(err, repoerrs.ErrNotFound):
+		return ErrPaymentRequestNotFound
+	default:
+		return accountRepoError(err)
+	}
+}
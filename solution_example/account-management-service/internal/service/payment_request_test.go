@@ -0,0 +1,216 @@
+package service
+
+import (
+	"account-management-service/internal/entity"
+	"account-management-service/internal/mocks/repomocks"
+	"account-management-service/internal/repo/repoerrs"
+	"context"
+	"fmt"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestPaymentRequestService_AcceptPaymentRequest(t *testing.T) {
+	type args struct {
+		ctx    context.Context
+		id     int
+		userId int
+	}
+
+	type MockBehavior func(p *repomocks.MockPaymentRequest, a *repomocks.MockAccount, l *repomocks.MockLimit, v *repomocks.MockVerification, f *repomocks.MockFee, args args)
+
+	pending := entity.PaymentRequest{
+		Id:                 1,
+		RequesterAccountId: 1,
+		PayerAccountId:     2,
+		Amount:             100,
+		Status:             entity.PaymentRequestStatusPending,
+	}
+
+	testCases := []struct {
+		name         string
+		args         args
+		mockBehavior MockBehavior
+		wantErr      error
+	}{
+		{
+			name: "OK",
+			args: args{
+				ctx:    context.Background(),
+				id:     1,
+				userId: 7,
+			},
+			mockBehavior: func(p *repomocks.MockPaymentRequest, a *repomocks.MockAccount, l *repomocks.MockLimit, v *repomocks.MockVerification, f *repomocks.MockFee, args args) {
+				p.EXPECT().GetPaymentRequestById(args.ctx, args.id).
+					Return(pending, nil)
+				a.EXPECT().GetAccountById(args.ctx, pending.PayerAccountId).
+					Return(entity.Account{Id: 2, Type: entity.AccountTypePersonal}, nil).Times(3)
+				l.EXPECT().GetLimitRulesForAccount(args.ctx, pending.PayerAccountId, entity.AccountTypePersonal, entity.OperationTypeTransferFrom).
+					Return(nil, nil)
+				v.EXPECT().GetVerificationPoliciesForOperation(args.ctx, entity.OperationTypeTransferFrom).
+					Return(nil, nil)
+				f.EXPECT().GetFeeRulesForOperation(args.ctx, entity.OperationTypeTransferFrom, 0, entity.AccountTypePersonal).
+					Return([]entity.FeeRule{{Fixed: 10}}, nil)
+				p.EXPECT().AcceptPaymentRequest(args.ctx, args.id, &args.userId, entity.Fee{AccountId: feeAccountId, Amount: 10}, []entity.LimitRule(nil), entity.VerificationCheck{}).
+					Return(nil)
+			},
+			wantErr: nil,
+		},
+		{
+			name: "not found",
+			args: args{
+				ctx:    context.Background(),
+				id:     1,
+				userId: 7,
+			},
+			mockBehavior: func(p *repomocks.MockPaymentRequest, a *repomocks.MockAccount, l *repomocks.MockLimit, v *repomocks.MockVerification, f *repomocks.MockFee, args args) {
+				p.EXPECT().GetPaymentRequestById(args.ctx, args.id).
+					Return(entity.PaymentRequest{}, repoerrs.ErrNotFound)
+			},
+			wantErr: ErrPaymentRequestNotFound,
+		},
+		{
+			name: "limit exceeded",
+			args: args{
+				ctx:    context.Background(),
+				id:     1,
+				userId: 7,
+			},
+			mockBehavior: func(p *repomocks.MockPaymentRequest, a *repomocks.MockAccount, l *repomocks.MockLimit, v *repomocks.MockVerification, f *repomocks.MockFee, args args) {
+				p.EXPECT().GetPaymentRequestById(args.ctx, args.id).
+					Return(pending, nil)
+				a.EXPECT().GetAccountById(args.ctx, pending.PayerAccountId).
+					Return(entity.Account{Id: 2, Type: entity.AccountTypePersonal}, nil)
+				l.EXPECT().GetLimitRulesForAccount(args.ctx, pending.PayerAccountId, entity.AccountTypePersonal, entity.OperationTypeTransferFrom).
+					Return([]entity.LimitRule{
+						{Scope: entity.LimitScopeGlobal, LimitType: entity.LimitTypeMaxAmount, Value: 50},
+					}, nil)
+			},
+			wantErr: ErrLimitExceeded,
+		},
+		{
+			name: "window limit exceeded",
+			args: args{
+				ctx:    context.Background(),
+				id:     1,
+				userId: 7,
+			},
+			mockBehavior: func(p *repomocks.MockPaymentRequest, a *repomocks.MockAccount, l *repomocks.MockLimit, v *repomocks.MockVerification, f *repomocks.MockFee, args args) {
+				rules := []entity.LimitRule{
+					{Scope: entity.LimitScopeGlobal, LimitType: entity.LimitTypeMaxCount, Value: 5, WindowSeconds: 3600},
+				}
+
+				p.EXPECT().GetPaymentRequestById(args.ctx, args.id).
+					Return(pending, nil)
+				a.EXPECT().GetAccountById(args.ctx, pending.PayerAccountId).
+					Return(entity.Account{Id: 2, Type: entity.AccountTypePersonal}, nil).Times(3)
+				l.EXPECT().GetLimitRulesForAccount(args.ctx, pending.PayerAccountId, entity.AccountTypePersonal, entity.OperationTypeTransferFrom).
+					Return(rules, nil)
+				v.EXPECT().GetVerificationPoliciesForOperation(args.ctx, entity.OperationTypeTransferFrom).
+					Return(nil, nil)
+				f.EXPECT().GetFeeRulesForOperation(args.ctx, entity.OperationTypeTransferFrom, 0, entity.AccountTypePersonal).
+					Return(nil, nil)
+				p.EXPECT().AcceptPaymentRequest(args.ctx, args.id, &args.userId, entity.Fee{AccountId: feeAccountId}, rules, entity.VerificationCheck{}).
+					Return(fmt.Errorf("PaymentRequestRepo.AcceptPaymentRequest - r.resolvePaymentRequest: %w",
+						&repoerrs.LimitExceededError{Rule: rules[0], OperationType: entity.OperationTypeTransferFrom}))
+			},
+			wantErr: ErrLimitExceeded,
+		},
+		{
+			name: "verification required",
+			args: args{
+				ctx:    context.Background(),
+				id:     1,
+				userId: 7,
+			},
+			mockBehavior: func(p *repomocks.MockPaymentRequest, a *repomocks.MockAccount, l *repomocks.MockLimit, v *repomocks.MockVerification, f *repomocks.MockFee, args args) {
+				p.EXPECT().GetPaymentRequestById(args.ctx, args.id).
+					Return(pending, nil)
+				a.EXPECT().GetAccountById(args.ctx, pending.PayerAccountId).
+					Return(entity.Account{Id: 2, Type: entity.AccountTypePersonal, VerificationLevel: entity.VerificationLevelNone}, nil).Times(2)
+				l.EXPECT().GetLimitRulesForAccount(args.ctx, pending.PayerAccountId, entity.AccountTypePersonal, entity.OperationTypeTransferFrom).
+					Return(nil, nil)
+				v.EXPECT().GetVerificationPoliciesForOperation(args.ctx, entity.OperationTypeTransferFrom).
+					Return([]entity.VerificationPolicy{
+						{Level: entity.VerificationLevelNone, MaxAmount: 50},
+						{Level: entity.VerificationLevelBasic, MaxAmount: 500},
+					}, nil)
+			},
+			wantErr: ErrVerificationRequired,
+		},
+		{
+			name: "expired",
+			args: args{
+				ctx:    context.Background(),
+				id:     1,
+				userId: 7,
+			},
+			mockBehavior: func(p *repomocks.MockPaymentRequest, a *repomocks.MockAccount, l *repomocks.MockLimit, v *repomocks.MockVerification, f *repomocks.MockFee, args args) {
+				expired := pending
+				expired.Status = entity.PaymentRequestStatusExpired
+
+				p.EXPECT().GetPaymentRequestById(args.ctx, args.id).
+					Return(expired, nil)
+				p.EXPECT().AcceptPaymentRequest(args.ctx, args.id, &args.userId, entity.Fee{}, []entity.LimitRule(nil), entity.VerificationCheck{}).
+					Return(fmt.Errorf("PaymentRequestRepo.AcceptPaymentRequest - r.resolvePaymentRequest: %w", repoerrs.ErrExpired))
+			},
+			wantErr: ErrPaymentRequestExpired,
+		},
+		{
+			name: "not enough balance",
+			args: args{
+				ctx:    context.Background(),
+				id:     1,
+				userId: 7,
+			},
+			mockBehavior: func(p *repomocks.MockPaymentRequest, a *repomocks.MockAccount, l *repomocks.MockLimit, v *repomocks.MockVerification, f *repomocks.MockFee, args args) {
+				p.EXPECT().GetPaymentRequestById(args.ctx, args.id).
+					Return(pending, nil)
+				a.EXPECT().GetAccountById(args.ctx, pending.PayerAccountId).
+					Return(entity.Account{Id: 2, Type: entity.AccountTypePersonal}, nil).Times(3)
+				l.EXPECT().GetLimitRulesForAccount(args.ctx, pending.PayerAccountId, entity.AccountTypePersonal, entity.OperationTypeTransferFrom).
+					Return(nil, nil)
+				v.EXPECT().GetVerificationPoliciesForOperation(args.ctx, entity.OperationTypeTransferFrom).
+					Return(nil, nil)
+				f.EXPECT().GetFeeRulesForOperation(args.ctx, entity.OperationTypeTransferFrom, 0, entity.AccountTypePersonal).
+					Return([]entity.FeeRule{{Fixed: 10}}, nil)
+				p.EXPECT().AcceptPaymentRequest(args.ctx, args.id, &args.userId, entity.Fee{AccountId: feeAccountId, Amount: 10}, []entity.LimitRule(nil), entity.VerificationCheck{}).
+					Return(fmt.Errorf("PaymentRequestRepo.AcceptPaymentRequest - r.resolvePaymentRequest: %w", repoerrs.ErrNotEnoughBalance))
+			},
+			wantErr: ErrNotEnoughBalance,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// init deps
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			// init mocks
+			paymentRequestRepo := repomocks.NewMockPaymentRequest(ctrl)
+			accountRepo := repomocks.NewMockAccount(ctrl)
+			limitRepo := repomocks.NewMockLimit(ctrl)
+			verificationRepo := repomocks.NewMockVerification(ctrl)
+			feeRepo := repomocks.NewMockFee(ctrl)
+			tc.mockBehavior(paymentRequestRepo, accountRepo, limitRepo, verificationRepo, feeRepo, tc.args)
+
+			// init service
+			limitService := NewLimitService(limitRepo, accountRepo)
+			verificationService := NewVerificationService(verificationRepo, accountRepo)
+			feeService := NewFeeService(feeRepo, accountRepo, nil, feeAccountId)
+			s := NewPaymentRequestService(paymentRequestRepo, limitService, verificationService, feeService, 72*time.Hour)
+
+			// run test
+			err := s.AcceptPaymentRequest(tc.args.ctx, tc.args.id, tc.args.userId)
+			if tc.wantErr != nil {
+				assert.ErrorIs(t, err, tc.wantErr)
+				return
+			}
+
+			assert.NoError(t, err)
+		})
+	}
+}
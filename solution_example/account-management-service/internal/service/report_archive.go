@@ -0,0 +1,177 @@
+package service
+
+import (
+	"account-management-service/internal/entity"
+	"account-management-service/internal/repo"
+	"account-management-service/internal/repo/repoerrs"
+	"account-management-service/internal/webapi"
+	"context"
+	"errors"
+	log "github.com/sirupsen/logrus"
+	"time"
+)
+
+const (
+	// reportArchiveSyncChunkSize keeps the insert of the storage files within the limit of the query parameters
+	reportArchiveSyncChunkSize  = 500
+	reportArchivePurgeBatchSize = 100
+)
+
+type ReportArchiveService struct {
+	archiveRepo   repo.ReportArchive
+	reportStorage webapi.ReportStorage
+	// retention is how long the reports are kept, they are kept forever if it is 0
+	retention time.Duration
+	now       func() time.Time
+}
+
+func NewReportArchiveService(archiveRepo repo.ReportArchive, reportStorage webapi.ReportStorage, retention time.Duration) *ReportArchiveService {
+	return &ReportArchiveService{
+		archiveRepo:   archiveRepo,
+		reportStorage: reportStorage,
+		retention:     retention,
+		now:           time.Now,
+	}
+}
+
+func (s *ReportArchiveService) GetArchivedReports(ctx context.Context, input ReportArchiveListInput) ([]entity.ArchivedReport, error) {
+	return s.archiveRepo.GetArchivedReports(ctx, entity.ArchivedReportFilter{
+		Kind:      input.Kind,
+		CreatedBy: input.CreatedBy,
+	}, input.Offset, input.Limit)
+}
+
+func (s *ReportArchiveService) DownloadArchivedReport(ctx context.Context, id int) (ReportDownloadOutput, error) {
+	if !s.reportStorage.IsAvailable() {
+		return ReportDownloadOutput{}, ErrReportStorageUnavailable
+	}
+
+	report, err := s.getArchivedReport(ctx, id)
+	if err != nil {
+		return ReportDownloadOutput{}, err
+	}
+
+	data, err := s.reportStorage.DownloadFile(ctx, report.Filename)
+	if err != nil {
+		log.Errorf("ReportArchiveService.DownloadArchivedReport - s.reportStorage.DownloadFile: %v", err)
+		return ReportDownloadOutput{}, ErrCannotDownloadReport
+	}
+
+	return ReportDownloadOutput{
+		Filename: report.Filename,
+		Data:     data,
+	}, nil
+}
+
+// DeleteArchivedReport deletes the report from the storage and the archive. The report whose file
+// is no longer in the storage, e.g. deleted by hand, is deleted from the archive only
+func (s *ReportArchiveService) DeleteArchivedReport(ctx context.Context, id int) error {
+	if !s.reportStorage.IsAvailable() {
+		return ErrReportStorageUnavailable
+	}
+
+	report, err := s.getArchivedReport(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	return s.deleteReport(ctx, report, func(name string) bool {
+		names, err := s.reportStorage.GetAllFilenames(ctx)
+		if err != nil {
+			log.Errorf("ReportArchiveService.DeleteArchivedReport - s.reportStorage.GetAllFilenames: %v", err)
+			return true
+		}
+		return containsString(names, name)
+	})
+}
+
+// PurgeExpiredReports deletes the reports older than the retention, it is run by the scheduler.
+// The files of the storage missing from the archive are added to it first, so that they expire too
+func (s *ReportArchiveService) PurgeExpiredReports(ctx context.Context) {
+	if !s.reportStorage.IsAvailable() {
+		return
+	}
+
+	names, err := s.reportStorage.GetAllFilenames(ctx)
+	if err != nil {
+		log.Errorf("ReportArchiveService.PurgeExpiredReports - s.reportStorage.GetAllFilenames: %v", err)
+		return
+	}
+
+	stored := make(map[string]bool, len(names))
+	for _, name := range names {
+		stored[name] = true
+	}
+
+	for i := 0; i < len(names); i += reportArchiveSyncChunkSize {
+		end := i + reportArchiveSyncChunkSize
+		if end > len(names) {
+			end = len(names)
+		}
+		if err = s.archiveRepo.AddUnknownArchivedReports(ctx, names[i:end]); err != nil {
+			log.Errorf("ReportArchiveService.PurgeExpiredReports - s.archiveRepo.AddUnknownArchivedReports: %v", err)
+			return
+		}
+	}
+
+	if s.retention == 0 {
+		return
+	}
+
+	filter := entity.ArchivedReportFilter{CreatedBefore: s.now().Add(-s.retention)}
+	// the reports failed to be deleted stay in the archive, so they are skipped by the offset
+	var purged, failed int
+	for ctx.Err() == nil {
+		reports, err := s.archiveRepo.GetArchivedReports(ctx, filter, failed, reportArchivePurgeBatchSize)
+		if err != nil {
+			log.Errorf("ReportArchiveService.PurgeExpiredReports - s.archiveRepo.GetArchivedReports: %v", err)
+			break
+		}
+
+		for _, report := range reports {
+			err = s.deleteReport(ctx, report, func(name string) bool { return stored[name] })
+			if err != nil {
+				failed++
+				continue
+			}
+			purged++
+		}
+
+		if len(reports) < reportArchivePurgeBatchSize {
+			break
+		}
+	}
+
+	if purged > 0 || failed > 0 {
+		log.Infof("purged %d expired reports, failed to purge %d", purged, failed)
+	}
+}
+
+func (s *ReportArchiveService) getArchivedReport(ctx context.Context, id int) (entity.ArchivedReport, error) {
+	report, err := s.archiveRepo.GetArchivedReportById(ctx, id)
+	if err != nil {
+		if errors.Is(err, repoerrs.ErrNotFound) {
+			return entity.ArchivedReport{}, ErrArchivedReportNotFound
+		}
+		return entity.ArchivedReport{}, err
+	}
+	return report, nil
+}
+
+// deleteReport deletes the file and then the record, so that the file is never left without the record.
+// The error of deleting the file is ignored if stored tells it is no longer in the storage
+func (s *ReportArchiveService) deleteReport(ctx context.Context, report entity.ArchivedReport, stored func(name string) bool) error {
+	err := s.reportStorage.DeleteFile(ctx, report.Filename)
+	if err != nil && stored(report.Filename) {
+		log.Errorf("ReportArchiveService.deleteReport - s.reportStorage.DeleteFile: %v", err)
+		return ErrCannotDeleteArchivedReport
+	}
+
+	err = s.archiveRepo.DeleteArchivedReport(ctx, report.Id)
+	if err != nil && !errors.Is(err, repoerrs.ErrNotFound) {
+		log.Errorf("ReportArchiveService.deleteReport - s.archiveRepo.DeleteArchivedReport: %v", err)
+		return ErrCannotDeleteArchivedReport
+	}
+
+	return nil
+}
@@ -0,0 +1,206 @@
+package service
+
+import (
+	"account-management-service/internal/entity"
+	"account-management-service/internal/mocks/repomocks"
+	"account-management-service/internal/mocks/webapimocks"
+	"account-management-service/internal/repo/repoerrs"
+	"context"
+	"errors"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestReportArchiveService_DeleteArchivedReport(t *testing.T) {
+	type MockBehavior func(a *repomocks.MockReportArchive, st *webapimocks.MockReportStorage)
+
+	ctx := context.Background()
+	report := entity.ArchivedReport{Id: 1, Filename: "report_1_2022.csv", Kind: entity.ArchivedReportKindReport}
+
+	testCases := []struct {
+		name         string
+		mockBehavior MockBehavior
+		wantErr      error
+	}{
+		{
+			name: "OK",
+			mockBehavior: func(a *repomocks.MockReportArchive, st *webapimocks.MockReportStorage) {
+				st.EXPECT().IsAvailable().Return(true)
+				a.EXPECT().GetArchivedReportById(ctx, 1).
+					Return(report, nil)
+				st.EXPECT().DeleteFile(ctx, "report_1_2022.csv").
+					Return(nil)
+				a.EXPECT().DeleteArchivedReport(ctx, 1).
+					Return(nil)
+			},
+		},
+		{
+			name: "report not found",
+			mockBehavior: func(a *repomocks.MockReportArchive, st *webapimocks.MockReportStorage) {
+				st.EXPECT().IsAvailable().Return(true)
+				a.EXPECT().GetArchivedReportById(ctx, 1).
+					Return(entity.ArchivedReport{}, repoerrs.ErrNotFound)
+			},
+			wantErr: ErrArchivedReportNotFound,
+		},
+		{
+			name: "file missing from storage is deleted from archive",
+			mockBehavior: func(a *repomocks.MockReportArchive, st *webapimocks.MockReportStorage) {
+				st.EXPECT().IsAvailable().Return(true)
+				a.EXPECT().GetArchivedReportById(ctx, 1).
+					Return(report, nil)
+				st.EXPECT().DeleteFile(ctx, "report_1_2022.csv").
+					Return(errors.New("file not found"))
+				st.EXPECT().GetAllFilenames(ctx).
+					Return([]string{"report_2_2022.csv"}, nil)
+				a.EXPECT().DeleteArchivedReport(ctx, 1).
+					Return(nil)
+			},
+		},
+		{
+			name: "storage error keeps report in archive",
+			mockBehavior: func(a *repomocks.MockReportArchive, st *webapimocks.MockReportStorage) {
+				st.EXPECT().IsAvailable().Return(true)
+				a.EXPECT().GetArchivedReportById(ctx, 1).
+					Return(report, nil)
+				st.EXPECT().DeleteFile(ctx, "report_1_2022.csv").
+					Return(errors.New("some error"))
+				st.EXPECT().GetAllFilenames(ctx).
+					Return([]string{"report_1_2022.csv"}, nil)
+			},
+			wantErr: ErrCannotDeleteArchivedReport,
+		},
+		{
+			name: "report storage is not available",
+			mockBehavior: func(a *repomocks.MockReportArchive, st *webapimocks.MockReportStorage) {
+				st.EXPECT().IsAvailable().Return(false)
+			},
+			wantErr: ErrReportStorageUnavailable,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// init deps
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			// init mocks
+			archiveRepo := repomocks.NewMockReportArchive(ctrl)
+			reportStorage := webapimocks.NewMockReportStorage(ctrl)
+			tc.mockBehavior(archiveRepo, reportStorage)
+
+			// init service
+			s := NewReportArchiveService(archiveRepo, reportStorage, 0)
+
+			// run test
+			err := s.DeleteArchivedReport(ctx, 1)
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
+
+func TestReportArchiveService_PurgeExpiredReports(t *testing.T) {
+	type MockBehavior func(a *repomocks.MockReportArchive, st *webapimocks.MockReportStorage)
+
+	ctx := context.Background()
+	now := time.Date(2022, time.November, 23, 12, 0, 0, 0, time.UTC)
+	retention := 30 * 24 * time.Hour
+	filter := entity.ArchivedReportFilter{CreatedBefore: now.Add(-retention)}
+
+	testCases := []struct {
+		name         string
+		retention    time.Duration
+		mockBehavior MockBehavior
+	}{
+		{
+			name:      "OK",
+			retention: retention,
+			mockBehavior: func(a *repomocks.MockReportArchive, st *webapimocks.MockReportStorage) {
+				st.EXPECT().IsAvailable().Return(true)
+				st.EXPECT().GetAllFilenames(ctx).
+					Return([]string{"report_1_2022.csv", "old.csv"}, nil)
+				a.EXPECT().AddUnknownArchivedReports(ctx, []string{"report_1_2022.csv", "old.csv"}).
+					Return(nil)
+				a.EXPECT().GetArchivedReports(ctx, filter, 0, reportArchivePurgeBatchSize).
+					Return([]entity.ArchivedReport{
+						{Id: 1, Filename: "report_1_2022.csv"},
+						{Id: 2, Filename: "removed_by_hand.csv"},
+					}, nil)
+				st.EXPECT().DeleteFile(ctx, "report_1_2022.csv").
+					Return(nil)
+				a.EXPECT().DeleteArchivedReport(ctx, 1).
+					Return(nil)
+				// the file is not in the storage, so only its record is deleted
+				st.EXPECT().DeleteFile(ctx, "removed_by_hand.csv").
+					Return(errors.New("file not found"))
+				a.EXPECT().DeleteArchivedReport(ctx, 2).
+					Return(nil)
+			},
+		},
+		{
+			name:      "reports failed to be deleted are skipped",
+			retention: retention,
+			mockBehavior: func(a *repomocks.MockReportArchive, st *webapimocks.MockReportStorage) {
+				full := make([]entity.ArchivedReport, reportArchivePurgeBatchSize)
+				for i := range full {
+					full[i] = entity.ArchivedReport{Id: i + 1, Filename: "report.csv"}
+				}
+
+				st.EXPECT().IsAvailable().Return(true)
+				st.EXPECT().GetAllFilenames(ctx).
+					Return([]string{"report.csv"}, nil)
+				a.EXPECT().AddUnknownArchivedReports(ctx, []string{"report.csv"}).
+					Return(nil)
+				a.EXPECT().GetArchivedReports(ctx, filter, 0, reportArchivePurgeBatchSize).
+					Return(full, nil)
+				st.EXPECT().DeleteFile(ctx, "report.csv").
+					Return(errors.New("some error")).Times(reportArchivePurgeBatchSize)
+				a.EXPECT().GetArchivedReports(ctx, filter, reportArchivePurgeBatchSize, reportArchivePurgeBatchSize).
+					Return(nil, nil)
+			},
+		},
+		{
+			name:      "reports are kept forever without retention",
+			retention: 0,
+			mockBehavior: func(a *repomocks.MockReportArchive, st *webapimocks.MockReportStorage) {
+				st.EXPECT().IsAvailable().Return(true)
+				st.EXPECT().GetAllFilenames(ctx).
+					Return([]string{"report_1_2022.csv"}, nil)
+				a.EXPECT().AddUnknownArchivedReports(ctx, []string{"report_1_2022.csv"}).
+					Return(nil)
+			},
+		},
+		{
+			name:      "storage error",
+			retention: retention,
+			mockBehavior: func(a *repomocks.MockReportArchive, st *webapimocks.MockReportStorage) {
+				st.EXPECT().IsAvailable().Return(true)
+				st.EXPECT().GetAllFilenames(ctx).
+					Return(nil, errors.New("some error"))
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// init deps
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			// init mocks
+			archiveRepo := repomocks.NewMockReportArchive(ctrl)
+			reportStorage := webapimocks.NewMockReportStorage(ctrl)
+			tc.mockBehavior(archiveRepo, reportStorage)
+
+			// init service
+			s := NewReportArchiveService(archiveRepo, reportStorage, tc.retention)
+			s.now = func() time.Time { return now }
+
+			// run test
+			s.PurgeExpiredReports(ctx)
+		})
+	}
+}
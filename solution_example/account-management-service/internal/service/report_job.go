@@ -0,0 +1,202 @@
+package service
+
+import (
+	"account-management-service/internal/entity"
+	"account-management-service/internal/repo"
+	"account-management-service/internal/repo/repoerrs"
+	"account-management-service/internal/webapi"
+	"context"
+	"errors"
+	log "github.com/sirupsen/logrus"
+	"time"
+)
+
+// reportJobRunBatchSize limits the number of jobs run by one scheduler run
+const reportJobRunBatchSize = 10
+
+const (
+	// reportJobProgressStarted is the progress of the job while the report is streamed to the storage,
+	// the size of the report is not known until it is written
+	reportJobProgressStarted = 10
+	// reportJobProgressUploaded is the progress of the job once the report is streamed to the storage
+	reportJobProgressUploaded = 90
+)
+
+type ReportJobService struct {
+	reportJobRepo    repo.ReportJob
+	operationService Operation
+	reportStorage    webapi.ReportStorage
+	reportLinks      ReportLink
+	maxAttempts      int
+	// retryBackoff is the delay before the second attempt, it doubles with every next attempt
+	retryBackoff time.Duration
+	staleAfter   time.Duration
+}
+
+func NewReportJobService(reportJobRepo repo.ReportJob, operationService Operation, reportStorage webapi.ReportStorage, reportLinks ReportLink, maxAttempts int, retryBackoff, staleAfter time.Duration) *ReportJobService {
+	return &ReportJobService{
+		reportJobRepo:    reportJobRepo,
+		operationService: operationService,
+		reportStorage:    reportStorage,
+		reportLinks:      reportLinks,
+		maxAttempts:      maxAttempts,
+		retryBackoff:     retryBackoff,
+		staleAfter:       staleAfter,
+	}
+}
+
+// CreateReportJob queues the report generation, if the same report is already queued or running its job id is returned
+func (s *ReportJobService) CreateReportJob(ctx context.Context, input ReportJobCreateInput) (int, error) {
+	if !s.reportStorage.IsAvailable() {
+		return 0, ErrReportStorageUnavailable
+	}
+
+	params, err := normalizeReportParams(entity.ReportParams{
+		Month:        input.Month,
+		Year:         input.Year,
+		From:         input.From,
+		To:           input.To,
+		Version:      input.Version,
+		Mode:         input.Mode,
+		Granularity:  input.Granularity,
+		ProductIds:   input.ProductIds,
+		AccountIds:   input.AccountIds,
+		Format:       input.Format,
+		CSVDelimiter: input.CSVDelimiter,
+		CSVBOM:       input.CSVBOM,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	id, created, err := s.reportJobRepo.CreateReportJob(ctx, params, input.CreatedBy)
+	if err != nil {
+		log.Errorf("ReportJobService.CreateReportJob - s.reportJobRepo.CreateReportJob: %v", err)
+		return 0, ErrCannotCreateReportJob
+	}
+
+	if !created {
+		log.Debugf("report job %d is reused for the identical request", id)
+	}
+
+	return id, nil
+}
+
+// GetReportJob returns the job, the link of the finished job is issued anew on every call
+// so that it is not expired by the time it is requested
+func (s *ReportJobService) GetReportJob(ctx context.Context, id int) (entity.ReportJob, error) {
+	job, err := s.reportJobRepo.GetReportJobById(ctx, id)
+	if err != nil {
+		if errors.Is(err, repoerrs.ErrNotFound) {
+			return entity.ReportJob{}, ErrReportJobNotFound
+		}
+		return entity.ReportJob{}, err
+	}
+
+	if job.Status == entity.ReportJobStatusDone {
+		job.Link, err = s.reportLinks.ShareLink(ctx, job.Filename, job.Link)
+		if err != nil {
+			return entity.ReportJob{}, err
+		}
+	}
+
+	return job, nil
+}
+
+// RunReportJobs runs the due jobs one by one, it is run by the scheduler
+func (s *ReportJobService) RunReportJobs(ctx context.Context) {
+	for i := 0; i < reportJobRunBatchSize; i++ {
+		if ctx.Err() != nil {
+			return
+		}
+
+		job, err := s.reportJobRepo.ClaimReportJob(ctx, s.staleAfter)
+		if err != nil {
+			if !errors.Is(err, repoerrs.ErrNotFound) {
+				log.Errorf("ReportJobService.RunReportJobs - s.reportJobRepo.ClaimReportJob: %v", err)
+			}
+			return
+		}
+
+		err = s.runJob(ctx, job)
+		if err == nil {
+			continue
+		}
+
+		var nextRunAt *time.Time
+		if job.Attempts < s.maxAttempts {
+			at := time.Now().Add(s.retryBackoff << (job.Attempts - 1))
+			nextRunAt = &at
+			log.Warnf("report job %d attempt %d failed, retrying at %s: %v", job.Id, job.Attempts, at.Format(time.RFC3339), err)
+		} else {
+			log.Errorf("report job %d failed after %d attempts: %v", job.Id, job.Attempts, err)
+		}
+
+		err = s.reportJobRepo.FailReportJobAttempt(ctx, job.Id, err.Error(), nextRunAt)
+		if err != nil {
+			log.Errorf("ReportJobService.RunReportJobs - s.reportJobRepo.FailReportJobAttempt: %v", err)
+		}
+	}
+}
+
+func (s *ReportJobService) runJob(ctx context.Context, job entity.ReportJob) error {
+	stop := s.heartbeat(ctx, job.Id)
+	filename, link, err := s.operationService.UploadReport(ctx, job.Params, job.CreatedBy)
+	stop()
+	if err != nil {
+		return err
+	}
+	s.setProgress(ctx, job.Id, reportJobProgressUploaded)
+
+	err = s.reportJobRepo.CompleteReportJob(ctx, job.Id, filename, link)
+	if err != nil {
+		log.Errorf("ReportJobService.runJob - s.reportJobRepo.CompleteReportJob: %v", err)
+		return errors.New("failed to complete report job")
+	}
+
+	return nil
+}
+
+// setProgress reports the progress, it also keeps the job from being considered stale
+func (s *ReportJobService) setProgress(ctx context.Context, id, progress int) {
+	if err := s.reportJobRepo.SetReportJobProgress(ctx, id, progress); err != nil {
+		log.Errorf("ReportJobService.setProgress - s.reportJobRepo.SetReportJobProgress: %v", err)
+	}
+}
+
+// heartbeat reports the started progress right away and then repeats it every third of staleAfter,
+// so a report streamed for longer than staleAfter is not claimed again by another instance.
+// The returned stop waits for the last report to finish
+func (s *ReportJobService) heartbeat(ctx context.Context, id int) (stop func()) {
+	s.setProgress(ctx, id, reportJobProgressStarted)
+
+	interval := s.staleAfter / 3
+	if interval <= 0 {
+		return func() {}
+	}
+
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				s.setProgress(ctx, id, reportJobProgressStarted)
+			case <-done:
+				return
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+		<-stopped
+	}
+}
@@ -0,0 +1,245 @@
+package service
+
+import (
+	"account-management-service/internal/entity"
+	"account-management-service/internal/mocks/repomocks"
+	"account-management-service/internal/mocks/webapimocks"
+	"account-management-service/internal/repo/repoerrs"
+	"context"
+	"errors"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestReportJobService_CreateReportJob(t *testing.T) {
+	from := time.Date(2022, time.January, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, 0)
+
+	type args struct {
+		ctx   context.Context
+		input ReportJobCreateInput
+	}
+
+	type MockBehavior func(r *repomocks.MockReportJob, st *webapimocks.MockReportStorage, args args)
+
+	testCases := []struct {
+		name         string
+		args         args
+		mockBehavior MockBehavior
+		want         int
+		wantErr      error
+	}{
+		{
+			name: "OK",
+			args: args{
+				ctx:   context.Background(),
+				input: ReportJobCreateInput{Month: 1, Year: 2022, Format: entity.ReportFormatXLSX, CreatedBy: 1},
+			},
+			mockBehavior: func(r *repomocks.MockReportJob, st *webapimocks.MockReportStorage, args args) {
+				st.EXPECT().IsAvailable().Return(true)
+				r.EXPECT().CreateReportJob(args.ctx, entity.ReportParams{Month: 1, Year: 2022, From: from, To: to, Version: entity.ReportVersionCurrent, Mode: entity.ReportModeProducts, Format: entity.ReportFormatXLSX}, 1).
+					Return(1, true, nil)
+			},
+			want: 1,
+		},
+		{
+			name: "identical job is running",
+			args: args{
+				ctx:   context.Background(),
+				input: ReportJobCreateInput{Month: 1, Year: 2022, Version: entity.ReportVersionLegacy, CreatedBy: 2},
+			},
+			mockBehavior: func(r *repomocks.MockReportJob, st *webapimocks.MockReportStorage, args args) {
+				st.EXPECT().IsAvailable().Return(true)
+				r.EXPECT().CreateReportJob(args.ctx, entity.ReportParams{Month: 1, Year: 2022, From: from, To: to, Version: entity.ReportVersionLegacy, Mode: entity.ReportModeProducts, Format: entity.ReportFormatCSV, CSVDelimiter: entity.ReportDelimiterComma}, 2).
+					Return(1, false, nil)
+			},
+			want: 1,
+		},
+		{
+			name: "storage unavailable",
+			args: args{
+				ctx:   context.Background(),
+				input: ReportJobCreateInput{Month: 1, Year: 2022, CreatedBy: 1},
+			},
+			mockBehavior: func(r *repomocks.MockReportJob, st *webapimocks.MockReportStorage, args args) {
+				st.EXPECT().IsAvailable().Return(false)
+			},
+			wantErr: ErrReportStorageUnavailable,
+		},
+		{
+			name: "legacy version in xlsx",
+			args: args{
+				ctx:   context.Background(),
+				input: ReportJobCreateInput{Month: 1, Year: 2022, Version: entity.ReportVersionLegacy, Format: entity.ReportFormatXLSX, CreatedBy: 1},
+			},
+			mockBehavior: func(r *repomocks.MockReportJob, st *webapimocks.MockReportStorage, args args) {
+				st.EXPECT().IsAvailable().Return(true)
+			},
+			wantErr: ErrInvalidReportParams,
+		},
+		{
+			name: "unexpected error",
+			args: args{
+				ctx:   context.Background(),
+				input: ReportJobCreateInput{Month: 1, Year: 2022, CreatedBy: 1},
+			},
+			mockBehavior: func(r *repomocks.MockReportJob, st *webapimocks.MockReportStorage, args args) {
+				st.EXPECT().IsAvailable().Return(true)
+				r.EXPECT().CreateReportJob(args.ctx, gomock.Any(), 1).
+					Return(0, false, errors.New("some error"))
+			},
+			wantErr: ErrCannotCreateReportJob,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// init deps
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			// init mocks
+			reportJobRepo := repomocks.NewMockReportJob(ctrl)
+			reportStorage := webapimocks.NewMockReportStorage(ctrl)
+			tc.mockBehavior(reportJobRepo, reportStorage, tc.args)
+
+			// init service
+			s := NewReportJobService(reportJobRepo, nil, reportStorage, nil, 3, time.Minute, time.Minute)
+
+			// run test
+			got, err := s.CreateReportJob(tc.args.ctx, tc.args.input)
+			if tc.wantErr != nil {
+				assert.ErrorIs(t, err, tc.wantErr)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestReportJobService_RunReportJobs(t *testing.T) {
+	type MockBehavior func(r *repomocks.MockReportJob, o *repomocks.MockOperation, a *repomocks.MockReportArchive, st *webapimocks.MockReportStorage)
+
+	ctx := context.Background()
+	params := entity.ReportParams{Month: 1, Year: 2022, Version: entity.ReportVersionLegacy}
+	from := time.Date(2022, time.January, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, 0)
+	createdBy := 7
+	size := int64(len("product,100\n"))
+
+	testCases := []struct {
+		name         string
+		mockBehavior MockBehavior
+	}{
+		{
+			name: "OK",
+			mockBehavior: func(r *repomocks.MockReportJob, o *repomocks.MockOperation, a *repomocks.MockReportArchive, st *webapimocks.MockReportStorage) {
+				r.EXPECT().ClaimReportJob(ctx, time.Minute).
+					Return(entity.ReportJob{Id: 1, Params: params, Attempts: 1, CreatedBy: createdBy}, nil)
+				r.EXPECT().SetReportJobProgress(ctx, 1, reportJobProgressStarted).
+					Return(nil)
+				o.EXPECT().GetAllRevenueOperationsGroupedByProduct(ctx, from, to).
+					Return([]entity.ProductRevenue{{Product: "product", Amount: 100, MainAmount: 100, Revenue: 100}}, nil)
+				st.EXPECT().UploadStream(ctx, "report_1_2022.csv", gomock.Any()).
+					DoAndReturn(readUpload(t, "product,100\n", "http://storage/report_1_2022.csv", nil))
+				// the report is archived as created by the user who has requested it
+				a.EXPECT().SaveArchivedReport(ctx, entity.ArchivedReport{
+					Filename:   "report_1_2022.csv",
+					Kind:       entity.ArchivedReportKindReport,
+					PeriodFrom: &from,
+					PeriodTo:   &to,
+					Format:     "csv",
+					CreatedBy:  &createdBy,
+					Size:       &size,
+				}).Return(1, nil)
+				r.EXPECT().SetReportJobProgress(ctx, 1, reportJobProgressUploaded).
+					Return(nil)
+				r.EXPECT().CompleteReportJob(ctx, 1, "report_1_2022.csv", "http://storage/report_1_2022.csv").
+					Return(nil)
+				r.EXPECT().ClaimReportJob(ctx, time.Minute).
+					Return(entity.ReportJob{}, repoerrs.ErrNotFound)
+			},
+		},
+		{
+			name: "failed attempt is retried",
+			mockBehavior: func(r *repomocks.MockReportJob, o *repomocks.MockOperation, a *repomocks.MockReportArchive, st *webapimocks.MockReportStorage) {
+				r.EXPECT().ClaimReportJob(ctx, time.Minute).
+					Return(entity.ReportJob{Id: 1, Params: params, Attempts: 2}, nil)
+				r.EXPECT().SetReportJobProgress(ctx, 1, reportJobProgressStarted).
+					Return(nil)
+				o.EXPECT().GetAllRevenueOperationsGroupedByProduct(ctx, from, to).
+					Return(nil, errors.New("some error"))
+				st.EXPECT().UploadStream(ctx, "report_1_2022.csv", gomock.Any()).
+					DoAndReturn(readUpload(t, "", "", nil))
+				r.EXPECT().FailReportJobAttempt(ctx, 1, gomock.Any(), gomock.Not(gomock.Nil())).
+					DoAndReturn(func(_ context.Context, _ int, _ string, nextRunAt *time.Time) error {
+						// the second retry waits twice as long as the first one
+						assert.WithinDuration(t, time.Now().Add(2*time.Minute), *nextRunAt, 5*time.Second)
+						return nil
+					})
+				r.EXPECT().ClaimReportJob(ctx, time.Minute).
+					Return(entity.ReportJob{}, repoerrs.ErrNotFound)
+			},
+		},
+		{
+			name: "last attempt fails the job",
+			mockBehavior: func(r *repomocks.MockReportJob, o *repomocks.MockOperation, a *repomocks.MockReportArchive, st *webapimocks.MockReportStorage) {
+				r.EXPECT().ClaimReportJob(ctx, time.Minute).
+					Return(entity.ReportJob{Id: 1, Params: params, Attempts: 3}, nil)
+				r.EXPECT().SetReportJobProgress(ctx, 1, reportJobProgressStarted).
+					Return(nil)
+				o.EXPECT().GetAllRevenueOperationsGroupedByProduct(ctx, from, to).
+					Return([]entity.ProductRevenue{}, nil)
+				st.EXPECT().UploadStream(ctx, "report_1_2022.csv", gomock.Any()).
+					DoAndReturn(readUpload(t, "", "", errors.New("some error")))
+				r.EXPECT().FailReportJobAttempt(ctx, 1, "failed to upload report file", nil).
+					Return(nil)
+				r.EXPECT().ClaimReportJob(ctx, time.Minute).
+					Return(entity.ReportJob{}, repoerrs.ErrNotFound)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// init deps
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			// init mocks
+			reportJobRepo := repomocks.NewMockReportJob(ctrl)
+			operationRepo := repomocks.NewMockOperation(ctrl)
+			archiveRepo := repomocks.NewMockReportArchive(ctrl)
+			reportStorage := webapimocks.NewMockReportStorage(ctrl)
+			tc.mockBehavior(reportJobRepo, operationRepo, archiveRepo, reportStorage)
+
+			// init service
+			operationService := NewOperationService(operationRepo, nil, archiveRepo, reportStorage, nil)
+			s := NewReportJobService(reportJobRepo, operationService, reportStorage, nil, 3, time.Minute, time.Minute)
+
+			// run test
+			s.RunReportJobs(ctx)
+		})
+	}
+}
+
+func TestReportJobService_heartbeat(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	reportJobRepo := repomocks.NewMockReportJob(ctrl)
+	// reported right away and then every 10ms while the report is streamed
+	reportJobRepo.EXPECT().SetReportJobProgress(ctx, 1, reportJobProgressStarted).
+		Return(nil).MinTimes(2)
+
+	s := NewReportJobService(reportJobRepo, nil, nil, nil, 3, time.Minute, 30*time.Millisecond)
+
+	stop := s.heartbeat(ctx, 1)
+	time.Sleep(50 * time.Millisecond)
+	stop()
+}
@@ -0,0 +1,151 @@
+package service
+
+import (
+	"account-management-service/internal/entity"
+	"account-management-service/internal/repo"
+	"account-management-service/internal/repo/repoerrs"
+	"account-management-service/internal/webapi"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	log "github.com/sirupsen/logrus"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ReportDownloadPath is the path of the unauthenticated route serving the signed links
+const ReportDownloadPath = "/reports/download"
+
+// reportLinkUserAgentMaxLength is the size of the user_agent column
+const reportLinkUserAgentMaxLength = 255
+
+type ReportLinkService struct {
+	reportLinkRepo repo.ReportLink
+	reportStorage  webapi.ReportStorage
+	// signed links are served by the service, otherwise the links of the storage are shared as is
+	signed  bool
+	signKey []byte
+	baseURL string
+	ttl     time.Duration
+	oneTime bool
+}
+
+func NewReportLinkService(reportLinkRepo repo.ReportLink, reportStorage webapi.ReportStorage, signed bool, signKey, baseURL string, ttl time.Duration, oneTime bool) *ReportLinkService {
+	return &ReportLinkService{
+		reportLinkRepo: reportLinkRepo,
+		reportStorage:  reportStorage,
+		signed:         signed,
+		signKey:        []byte(signKey),
+		baseURL:        strings.TrimSuffix(baseURL, "/"),
+		ttl:            ttl,
+		oneTime:        oneTime,
+	}
+}
+
+// ShareLink returns the link to download the stored report: the signed link served by the service
+// or, if signed links are disabled, the link returned by the storage
+func (s *ReportLinkService) ShareLink(ctx context.Context, filename, storageLink string) (string, error) {
+	if !s.signed {
+		return storageLink, nil
+	}
+
+	expiresAt := time.Now().Add(s.ttl).Truncate(time.Second)
+	id, err := s.reportLinkRepo.CreateReportLink(ctx, entity.ReportLink{
+		Filename:  filename,
+		ExpiresAt: expiresAt,
+		OneTime:   s.oneTime,
+	})
+	if err != nil {
+		log.Errorf("ReportLinkService.ShareLink - s.reportLinkRepo.CreateReportLink: %v", err)
+		return "", ErrCannotCreateReportLink
+	}
+
+	query := url.Values{}
+	query.Set("expires", strconv.FormatInt(expiresAt.Unix(), 10))
+	query.Set("signature", s.signature(id, expiresAt.Unix()))
+
+	return fmt.Sprintf("%s%s/%d?%s", s.baseURL, ReportDownloadPath, id, query.Encode()), nil
+}
+
+// Download checks the signed link and returns the report file, every attempt is logged
+func (s *ReportLinkService) Download(ctx context.Context, input ReportDownloadInput) (ReportDownloadOutput, error) {
+	output, result, err := s.download(ctx, input)
+
+	access := entity.ReportLinkAccess{
+		LinkId:    input.LinkId,
+		IP:        input.IP,
+		UserAgent: input.UserAgent,
+		Result:    result,
+	}
+	if len(access.UserAgent) > reportLinkUserAgentMaxLength {
+		access.UserAgent = access.UserAgent[:reportLinkUserAgentMaxLength]
+	}
+	if logErr := s.reportLinkRepo.CreateReportLinkAccess(ctx, access); logErr != nil {
+		log.Errorf("ReportLinkService.Download - s.reportLinkRepo.CreateReportLinkAccess: %v", logErr)
+	}
+	log.Infof("report link %d accessed from %s: %s", input.LinkId, input.IP, result)
+
+	return output, err
+}
+
+func (s *ReportLinkService) GetLinkAccesses(ctx context.Context, linkId int) ([]entity.ReportLinkAccess, error) {
+	return s.reportLinkRepo.GetReportLinkAccesses(ctx, linkId)
+}
+
+func (s *ReportLinkService) download(ctx context.Context, input ReportDownloadInput) (ReportDownloadOutput, string, error) {
+	if !s.signed || !hmac.Equal([]byte(input.Signature), []byte(s.signature(input.LinkId, input.Expires))) {
+		return ReportDownloadOutput{}, entity.ReportLinkAccessInvalidSignature, ErrInvalidReportLink
+	}
+
+	if time.Now().Unix() >= input.Expires {
+		return ReportDownloadOutput{}, entity.ReportLinkAccessExpired, ErrReportLinkExpired
+	}
+
+	link, err := s.reportLinkRepo.GetReportLinkById(ctx, input.LinkId)
+	if err != nil {
+		if errors.Is(err, repoerrs.ErrNotFound) {
+			return ReportDownloadOutput{}, entity.ReportLinkAccessNotFound, ErrReportLinkNotFound
+		}
+		log.Errorf("ReportLinkService.Download - s.reportLinkRepo.GetReportLinkById: %v", err)
+		return ReportDownloadOutput{}, entity.ReportLinkAccessError, ErrCannotDownloadReport
+	}
+
+	if link.UsedAt != nil {
+		return ReportDownloadOutput{}, entity.ReportLinkAccessUsed, ErrReportLinkUsed
+	}
+
+	data, err := s.reportStorage.DownloadFile(ctx, link.Filename)
+	if err != nil {
+		log.Errorf("ReportLinkService.Download - s.reportStorage.DownloadFile: %v", err)
+		return ReportDownloadOutput{}, entity.ReportLinkAccessError, ErrCannotDownloadReport
+	}
+
+	// the link is used up only once the file is ready to be sent
+	if link.OneTime {
+		err = s.reportLinkRepo.UseReportLink(ctx, link.Id)
+		if err != nil {
+			if errors.Is(err, repoerrs.ErrWrongStatus) {
+				return ReportDownloadOutput{}, entity.ReportLinkAccessUsed, ErrReportLinkUsed
+			}
+			log.Errorf("ReportLinkService.Download - s.reportLinkRepo.UseReportLink: %v", err)
+			return ReportDownloadOutput{}, entity.ReportLinkAccessError, ErrCannotDownloadReport
+		}
+	}
+
+	return ReportDownloadOutput{
+		Filename: link.Filename,
+		Data:     data,
+	}, entity.ReportLinkAccessOK, nil
+}
+
+// signature signs the link id together with the expiration time, so neither can be changed in the url
+func (s *ReportLinkService) signature(linkId int, expires int64) string {
+	h := hmac.New(sha256.New, s.signKey)
+	_, _ = fmt.Fprintf(h, "%d:%d", linkId, expires)
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,163 @@
+package service
+
+import (
+	"account-management-service/internal/entity"
+	"account-management-service/internal/mocks/repomocks"
+	"account-management-service/internal/mocks/webapimocks"
+	"account-management-service/internal/repo/repoerrs"
+	"context"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestReportLinkService_ShareLink(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	reportLinkRepo := repomocks.NewMockReportLink(ctrl)
+	reportLinkRepo.EXPECT().CreateReportLink(gomock.Any(), gomock.Any()).
+		Return(7, nil)
+
+	s := NewReportLinkService(reportLinkRepo, nil, true, "secret", "http://localhost:8080/", time.Hour, false)
+
+	link, err := s.ShareLink(context.Background(), "report_1_2022.csv", "http://storage/report_1_2022.csv")
+	assert.NoError(t, err)
+
+	u, err := url.Parse(link)
+	assert.NoError(t, err)
+	assert.Equal(t, "/reports/download/7", u.Path)
+
+	expires, err := strconv.ParseInt(u.Query().Get("expires"), 10, 64)
+	assert.NoError(t, err)
+	assert.Equal(t, s.signature(7, expires), u.Query().Get("signature"))
+}
+
+func TestReportLinkService_Download(t *testing.T) {
+	type args struct {
+		ctx   context.Context
+		input ReportDownloadInput
+	}
+
+	type MockBehavior func(r *repomocks.MockReportLink, st *webapimocks.MockReportStorage, args args)
+
+	s := NewReportLinkService(nil, nil, true, "secret", "http://localhost:8080", time.Hour, true)
+	expires := time.Now().Add(time.Hour).Unix()
+	expired := time.Now().Add(-time.Hour).Unix()
+
+	accessWithResult := func(result string) gomock.Matcher {
+		return gomock.Eq(entity.ReportLinkAccess{LinkId: 1, IP: "127.0.0.1", UserAgent: "curl", Result: result})
+	}
+
+	testCases := []struct {
+		name         string
+		args         args
+		mockBehavior MockBehavior
+		want         ReportDownloadOutput
+		wantErr      error
+	}{
+		{
+			name: "OK",
+			args: args{
+				ctx:   context.Background(),
+				input: ReportDownloadInput{LinkId: 1, Expires: expires, Signature: s.signature(1, expires), IP: "127.0.0.1", UserAgent: "curl"},
+			},
+			mockBehavior: func(r *repomocks.MockReportLink, st *webapimocks.MockReportStorage, args args) {
+				r.EXPECT().GetReportLinkById(args.ctx, 1).
+					Return(entity.ReportLink{Id: 1, Filename: "report_1_2022.csv", OneTime: true}, nil)
+				st.EXPECT().DownloadFile(args.ctx, "report_1_2022.csv").
+					Return([]byte("product,100\n"), nil)
+				r.EXPECT().UseReportLink(args.ctx, 1).
+					Return(nil)
+				r.EXPECT().CreateReportLinkAccess(args.ctx, accessWithResult(entity.ReportLinkAccessOK)).
+					Return(nil)
+			},
+			want: ReportDownloadOutput{Filename: "report_1_2022.csv", Data: []byte("product,100\n")},
+		},
+		{
+			name: "invalid signature",
+			args: args{
+				ctx:   context.Background(),
+				input: ReportDownloadInput{LinkId: 1, Expires: expires + 3600, Signature: s.signature(1, expires), IP: "127.0.0.1", UserAgent: "curl"},
+			},
+			mockBehavior: func(r *repomocks.MockReportLink, st *webapimocks.MockReportStorage, args args) {
+				r.EXPECT().CreateReportLinkAccess(args.ctx, accessWithResult(entity.ReportLinkAccessInvalidSignature)).
+					Return(nil)
+			},
+			wantErr: ErrInvalidReportLink,
+		},
+		{
+			name: "expired",
+			args: args{
+				ctx:   context.Background(),
+				input: ReportDownloadInput{LinkId: 1, Expires: expired, Signature: s.signature(1, expired), IP: "127.0.0.1", UserAgent: "curl"},
+			},
+			mockBehavior: func(r *repomocks.MockReportLink, st *webapimocks.MockReportStorage, args args) {
+				r.EXPECT().CreateReportLinkAccess(args.ctx, accessWithResult(entity.ReportLinkAccessExpired)).
+					Return(nil)
+			},
+			wantErr: ErrReportLinkExpired,
+		},
+		{
+			name: "one-time link used by concurrent download",
+			args: args{
+				ctx:   context.Background(),
+				input: ReportDownloadInput{LinkId: 1, Expires: expires, Signature: s.signature(1, expires), IP: "127.0.0.1", UserAgent: "curl"},
+			},
+			mockBehavior: func(r *repomocks.MockReportLink, st *webapimocks.MockReportStorage, args args) {
+				r.EXPECT().GetReportLinkById(args.ctx, 1).
+					Return(entity.ReportLink{Id: 1, Filename: "report_1_2022.csv", OneTime: true}, nil)
+				st.EXPECT().DownloadFile(args.ctx, "report_1_2022.csv").
+					Return([]byte("product,100\n"), nil)
+				r.EXPECT().UseReportLink(args.ctx, 1).
+					Return(repoerrs.ErrWrongStatus)
+				r.EXPECT().CreateReportLinkAccess(args.ctx, accessWithResult(entity.ReportLinkAccessUsed)).
+					Return(nil)
+			},
+			wantErr: ErrReportLinkUsed,
+		},
+		{
+			name: "link not found",
+			args: args{
+				ctx:   context.Background(),
+				input: ReportDownloadInput{LinkId: 1, Expires: expires, Signature: s.signature(1, expires), IP: "127.0.0.1", UserAgent: "curl"},
+			},
+			mockBehavior: func(r *repomocks.MockReportLink, st *webapimocks.MockReportStorage, args args) {
+				r.EXPECT().GetReportLinkById(args.ctx, 1).
+					Return(entity.ReportLink{}, repoerrs.ErrNotFound)
+				r.EXPECT().CreateReportLinkAccess(args.ctx, accessWithResult(entity.ReportLinkAccessNotFound)).
+					Return(nil)
+			},
+			wantErr: ErrReportLinkNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// init deps
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			// init mocks
+			reportLinkRepo := repomocks.NewMockReportLink(ctrl)
+			reportStorage := webapimocks.NewMockReportStorage(ctrl)
+			tc.mockBehavior(reportLinkRepo, reportStorage, tc.args)
+
+			// init service
+			s := NewReportLinkService(reportLinkRepo, reportStorage, true, "secret", "http://localhost:8080", time.Hour, true)
+
+			// run test
+			got, err := s.Download(tc.args.ctx, tc.args.input)
+			if tc.wantErr != nil {
+				assert.ErrorIs(t, err, tc.wantErr)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
@@ -0,0 +1,284 @@
+package service
+
+import (
+	"account-management-service/internal/entity"
+	"account-management-service/internal/repo"
+	"account-management-service/internal/repo/repoerrs"
+	"account-management-service/internal/webapi"
+	"account-management-service/pkg/cron"
+	"context"
+	"errors"
+	"fmt"
+	log "github.com/sirupsen/logrus"
+	"strings"
+	"time"
+)
+
+type ReportScheduleService struct {
+	reportScheduleRepo repo.ReportSchedule
+	operationService   Operation
+	reportStorage      webapi.ReportStorage
+	reportLinks        ReportLink
+	// channels are the delivery channels by their type
+	channels  map[string]webapi.ReportChannel
+	schedules []entity.ReportSchedule
+	// location is the time zone of the cron expressions and the report periods
+	location *time.Location
+	// catchUp is how late the scheduled time may be run, e.g. after the service has been down
+	catchUp time.Duration
+	policy  entity.ReportScheduleRetryPolicy
+	now     func() time.Time
+}
+
+func NewReportScheduleService(reportScheduleRepo repo.ReportSchedule, operationService Operation, reportStorage webapi.ReportStorage, reportLinks ReportLink,
+	channels map[string]webapi.ReportChannel, schedules []entity.ReportSchedule, location *time.Location, catchUp time.Duration, policy entity.ReportScheduleRetryPolicy) *ReportScheduleService {
+	if location == nil {
+		location = time.UTC
+	}
+
+	return &ReportScheduleService{
+		reportScheduleRepo: reportScheduleRepo,
+		operationService:   operationService,
+		reportStorage:      reportStorage,
+		reportLinks:        reportLinks,
+		channels:           channels,
+		schedules:          schedules,
+		location:           location,
+		catchUp:            catchUp,
+		policy:             policy,
+		now:                time.Now,
+	}
+}
+
+// ValidateReportSchedules checks the configured schedules, so that a misconfigured schedule
+// is found on start and not when it is due
+func ValidateReportSchedules(schedules []entity.ReportSchedule, channels map[string]webapi.ReportChannel) error {
+	names := make(map[string]bool, len(schedules))
+	for _, schedule := range schedules {
+		if schedule.Name == "" {
+			return errors.New("report schedule without name")
+		}
+		if names[schedule.Name] {
+			return fmt.Errorf("report schedule %s: duplicate name", schedule.Name)
+		}
+		names[schedule.Name] = true
+
+		if _, err := cron.Parse(schedule.Cron); err != nil {
+			return fmt.Errorf("report schedule %s: %w", schedule.Name, err)
+		}
+
+		from, to := reportSchedulePeriod(schedule.Period, time.Now())
+		if from.IsZero() {
+			return fmt.Errorf("report schedule %s: unknown period %q", schedule.Name, schedule.Period)
+		}
+		if _, err := normalizeReportParams(reportScheduleParams(schedule, from, to)); err != nil {
+			return fmt.Errorf("report schedule %s: %w", schedule.Name, err)
+		}
+
+		if len(schedule.Channels) == 0 {
+			return fmt.Errorf("report schedule %s: no channels", schedule.Name)
+		}
+		for _, channel := range schedule.Channels {
+			if _, ok := channels[channel.Type]; !ok {
+				return fmt.Errorf("report schedule %s: channel %q is not available", schedule.Name, channel.Type)
+			}
+			if len(channel.Recipients) == 0 {
+				return fmt.Errorf("report schedule %s: channel %q has no recipients", schedule.Name, channel.Type)
+			}
+		}
+	}
+
+	return nil
+}
+
+// GetReportSchedules returns the configured schedules with the next time they are run
+func (s *ReportScheduleService) GetReportSchedules(ctx context.Context) ([]ReportScheduleOutput, error) {
+	now := s.now().In(s.location)
+
+	output := make([]ReportScheduleOutput, 0, len(s.schedules))
+	for _, schedule := range s.schedules {
+		c, err := cron.Parse(schedule.Cron)
+		if err != nil {
+			return nil, err
+		}
+		output = append(output, ReportScheduleOutput{
+			ReportSchedule: schedule,
+			NextRunAt:      c.Next(now),
+		})
+	}
+
+	return output, nil
+}
+
+func (s *ReportScheduleService) GetReportScheduleRuns(ctx context.Context, schedule string, offset, limit int) ([]entity.ReportScheduleRun, error) {
+	return s.reportScheduleRepo.GetReportScheduleRuns(ctx, schedule, offset, limit)
+}
+
+// RunReportSchedules runs the schedules whose time has come, it is run by the scheduler. Only the latest
+// scheduled time within the catch up window is run, the times missed before it are skipped
+func (s *ReportScheduleService) RunReportSchedules(ctx context.Context) {
+	now := s.now().In(s.location)
+
+	for _, schedule := range s.schedules {
+		if ctx.Err() != nil {
+			return
+		}
+
+		c, err := cron.Parse(schedule.Cron)
+		if err != nil {
+			log.Errorf("ReportScheduleService.RunReportSchedules - cron.Parse: %v", err)
+			continue
+		}
+		scheduledAt, ok := c.Last(now.Add(-s.catchUp), now)
+		if !ok {
+			continue
+		}
+
+		// the run is claimed by only one of the replicas
+		run, err := s.reportScheduleRepo.ClaimReportScheduleRun(ctx, schedule.Name, scheduledAt, s.policy)
+		if err != nil {
+			if !errors.Is(err, repoerrs.ErrNotFound) {
+				log.Errorf("ReportScheduleService.RunReportSchedules - s.reportScheduleRepo.ClaimReportScheduleRun: %v", err)
+			}
+			continue
+		}
+
+		delivered, err := s.runSchedule(ctx, schedule, run)
+		runErr := ""
+		if err != nil {
+			runErr = err.Error()
+			log.Warnf("report schedule %s attempt %d for %s failed: %v", schedule.Name, run.Attempts, scheduledAt.Format(time.RFC3339), err)
+		}
+
+		err = s.reportScheduleRepo.FinishReportScheduleRun(ctx, run.Id, delivered, runErr)
+		if err != nil {
+			log.Errorf("ReportScheduleService.RunReportSchedules - s.reportScheduleRepo.FinishReportScheduleRun: %v", err)
+		}
+	}
+}
+
+// runSchedule uploads the report unless it has been uploaded by the previous attempt and delivers it
+// through the channels it has not been delivered through yet. The keys of the delivered channels are returned
+func (s *ReportScheduleService) runSchedule(ctx context.Context, schedule entity.ReportSchedule, run entity.ReportScheduleRun) ([]string, error) {
+	delivered := append([]string{}, run.Delivered...)
+
+	from, to := reportSchedulePeriod(schedule.Period, run.ScheduledAt.In(s.location))
+
+	filename, link := run.Filename, run.Link
+	if filename == "" {
+		var err error
+		filename, link, err = s.operationService.UploadReport(ctx, reportScheduleParams(schedule, from, to), 0)
+		if err != nil {
+			return delivered, err
+		}
+
+		err = s.reportScheduleRepo.SetReportScheduleRunFile(ctx, run.Id, filename, link)
+		if err != nil {
+			log.Errorf("ReportScheduleService.runSchedule - s.reportScheduleRepo.SetReportScheduleRunFile: %v", err)
+		}
+	}
+
+	sharedLink, err := s.reportLinks.ShareLink(ctx, filename, link)
+	if err != nil {
+		log.Errorf("ReportScheduleService.runSchedule - s.reportLinks.ShareLink: %v", err)
+		return delivered, errors.New("failed to share report link")
+	}
+
+	// the file is downloaded once for all the channels which attach it
+	var data []byte
+	var downloadErr error
+	download := func() ([]byte, error) {
+		if data == nil && downloadErr == nil {
+			data, downloadErr = s.reportStorage.DownloadFile(ctx, filename)
+		}
+		return data, downloadErr
+	}
+
+	var errs []string
+	for _, channel := range schedule.Channels {
+		key := channel.Key()
+		if containsString(delivered, key) {
+			continue
+		}
+
+		delivery := entity.ReportDelivery{
+			Schedule:   schedule.Name,
+			From:       from,
+			To:         to,
+			Filename:   filename,
+			Link:       sharedLink,
+			Recipients: channel.Recipients,
+		}
+		if channel.Attach {
+			delivery.Data, err = download()
+			if err != nil {
+				log.Errorf("ReportScheduleService.runSchedule - s.reportStorage.DownloadFile: %v", err)
+				errs = append(errs, channel.Type+": failed to download report file")
+				continue
+			}
+		}
+
+		sender, ok := s.channels[channel.Type]
+		if !ok {
+			errs = append(errs, channel.Type+": channel is not available")
+			continue
+		}
+		if err = sender.DeliverReport(ctx, delivery); err != nil {
+			log.Errorf("ReportScheduleService.runSchedule - sender.DeliverReport: %v", err)
+			errs = append(errs, err.Error())
+			continue
+		}
+
+		delivered = append(delivered, key)
+	}
+
+	if len(errs) > 0 {
+		return delivered, errors.New(strings.Join(errs, "; "))
+	}
+
+	return delivered, nil
+}
+
+// reportSchedulePeriod returns the period preceding the day of at, the dates are those of the location of at.
+// Zero times are returned for the unknown period
+func reportSchedulePeriod(period string, at time.Time) (time.Time, time.Time) {
+	today := time.Date(at.Year(), at.Month(), at.Day(), 0, 0, 0, 0, time.UTC)
+
+	switch period {
+	case entity.ReportSchedulePeriodDay:
+		return today.AddDate(0, 0, -1), today
+	case entity.ReportSchedulePeriodWeek:
+		// weeks start on monday
+		monday := today.AddDate(0, 0, -(int(today.Weekday())+6)%7)
+		return monday.AddDate(0, 0, -7), monday
+	case entity.ReportSchedulePeriodMonth:
+		first := today.AddDate(0, 0, 1-today.Day())
+		return first.AddDate(0, -1, 0), first
+	default:
+		return time.Time{}, time.Time{}
+	}
+}
+
+// reportScheduleParams returns the report of the period, the report of the month is requested by the month
+// so that it is the same as the one requested by hand
+func reportScheduleParams(schedule entity.ReportSchedule, from, to time.Time) entity.ReportParams {
+	params := schedule.Params
+	if schedule.Period == entity.ReportSchedulePeriodMonth {
+		params.Month = int(from.Month())
+		params.Year = from.Year()
+		return params
+	}
+
+	params.From = from
+	params.To = to
+	return params
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
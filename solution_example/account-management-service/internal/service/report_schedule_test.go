@@ -0,0 +1,254 @@
+package service
+
+import (
+	"account-management-service/internal/entity"
+	"account-management-service/internal/mocks/repomocks"
+	"account-management-service/internal/mocks/webapimocks"
+	"account-management-service/internal/repo/repoerrs"
+	"account-management-service/internal/webapi"
+	"context"
+	"errors"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestReportScheduleService_RunReportSchedules(t *testing.T) {
+	type MockBehavior func(r *repomocks.MockReportSchedule, o *repomocks.MockOperation, a *repomocks.MockReportArchive, st *webapimocks.MockReportStorage, email, webhook *webapimocks.MockReportChannel)
+
+	ctx := context.Background()
+	policy := entity.ReportScheduleRetryPolicy{MaxAttempts: 3, RetryInterval: time.Minute, StaleAfter: time.Hour}
+	scheduledAt := time.Date(2022, time.November, 1, 6, 0, 0, 0, time.UTC)
+	from := time.Date(2022, time.October, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, 0)
+
+	emailChannel := entity.ReportChannel{Type: "email", Recipients: []string{"accounting@example.com"}, Attach: true}
+	webhookChannel := entity.ReportChannel{Type: "webhook", Recipients: []string{"http://hooks/reports"}}
+	schedule := entity.ReportSchedule{
+		Name:     "monthly_revenue",
+		Cron:     "0 6 1 * *",
+		Period:   entity.ReportSchedulePeriodMonth,
+		Params:   entity.ReportParams{Version: entity.ReportVersionLegacy},
+		Channels: []entity.ReportChannel{emailChannel, webhookChannel},
+	}
+	delivery := entity.ReportDelivery{
+		Schedule: "monthly_revenue",
+		From:     from,
+		To:       to,
+		Filename: "report_10_2022.csv",
+		Link:     "http://storage/report_10_2022.csv",
+	}
+
+	testCases := []struct {
+		name         string
+		now          time.Time
+		mockBehavior MockBehavior
+	}{
+		{
+			name: "OK",
+			now:  scheduledAt.Add(30 * time.Second),
+			mockBehavior: func(r *repomocks.MockReportSchedule, o *repomocks.MockOperation, a *repomocks.MockReportArchive, st *webapimocks.MockReportStorage, email, webhook *webapimocks.MockReportChannel) {
+				r.EXPECT().ClaimReportScheduleRun(ctx, "monthly_revenue", scheduledAt, policy).
+					Return(entity.ReportScheduleRun{Id: 1, Schedule: "monthly_revenue", ScheduledAt: scheduledAt, Attempts: 1}, nil)
+				o.EXPECT().GetAllRevenueOperationsGroupedByProduct(ctx, from, to).
+					Return([]entity.ProductRevenue{{Product: "product", Amount: 100, MainAmount: 100, Revenue: 100}}, nil)
+				st.EXPECT().UploadStream(ctx, "report_10_2022.csv", gomock.Any()).
+					DoAndReturn(readUpload(t, "product,100\n", "http://storage/report_10_2022.csv", nil))
+				a.EXPECT().SaveArchivedReport(ctx, gomock.Any()).
+					DoAndReturn(func(_ context.Context, report entity.ArchivedReport) (int, error) {
+						// scheduled reports are not created by any user
+						assert.Nil(t, report.CreatedBy)
+						return 1, nil
+					})
+				r.EXPECT().SetReportScheduleRunFile(ctx, 1, "report_10_2022.csv", "http://storage/report_10_2022.csv").
+					Return(nil)
+				st.EXPECT().DownloadFile(ctx, "report_10_2022.csv").
+					Return([]byte("product,100\n"), nil)
+
+				emailDelivery := delivery
+				emailDelivery.Recipients = emailChannel.Recipients
+				emailDelivery.Data = []byte("product,100\n")
+				email.EXPECT().DeliverReport(ctx, emailDelivery).
+					Return(nil)
+				webhookDelivery := delivery
+				webhookDelivery.Recipients = webhookChannel.Recipients
+				webhook.EXPECT().DeliverReport(ctx, webhookDelivery).
+					Return(nil)
+
+				r.EXPECT().FinishReportScheduleRun(ctx, 1, []string{emailChannel.Key(), webhookChannel.Key()}, "").
+					Return(nil)
+			},
+		},
+		{
+			name: "scheduled time is out of the catch up window",
+			now:  scheduledAt.Add(25 * time.Hour),
+			mockBehavior: func(r *repomocks.MockReportSchedule, o *repomocks.MockOperation, a *repomocks.MockReportArchive, st *webapimocks.MockReportStorage, email, webhook *webapimocks.MockReportChannel) {
+			},
+		},
+		{
+			name: "run is claimed by another replica",
+			now:  scheduledAt.Add(time.Hour),
+			mockBehavior: func(r *repomocks.MockReportSchedule, o *repomocks.MockOperation, a *repomocks.MockReportArchive, st *webapimocks.MockReportStorage, email, webhook *webapimocks.MockReportChannel) {
+				r.EXPECT().ClaimReportScheduleRun(ctx, "monthly_revenue", scheduledAt, policy).
+					Return(entity.ReportScheduleRun{}, repoerrs.ErrNotFound)
+			},
+		},
+		{
+			name: "retry delivers through the remaining channels only",
+			now:  scheduledAt.Add(time.Hour),
+			mockBehavior: func(r *repomocks.MockReportSchedule, o *repomocks.MockOperation, a *repomocks.MockReportArchive, st *webapimocks.MockReportStorage, email, webhook *webapimocks.MockReportChannel) {
+				r.EXPECT().ClaimReportScheduleRun(ctx, "monthly_revenue", scheduledAt, policy).
+					Return(entity.ReportScheduleRun{
+						Id:          1,
+						Schedule:    "monthly_revenue",
+						ScheduledAt: scheduledAt,
+						Attempts:    2,
+						Filename:    "report_10_2022.csv",
+						Link:        "http://storage/report_10_2022.csv",
+						Delivered:   []string{emailChannel.Key()},
+					}, nil)
+
+				webhookDelivery := delivery
+				webhookDelivery.Recipients = webhookChannel.Recipients
+				webhook.EXPECT().DeliverReport(ctx, webhookDelivery).
+					Return(errors.New("webhook: some error"))
+
+				r.EXPECT().FinishReportScheduleRun(ctx, 1, []string{emailChannel.Key()}, "webhook: some error").
+					Return(nil)
+			},
+		},
+		{
+			name: "report generation error fails the run",
+			now:  scheduledAt.Add(time.Minute),
+			mockBehavior: func(r *repomocks.MockReportSchedule, o *repomocks.MockOperation, a *repomocks.MockReportArchive, st *webapimocks.MockReportStorage, email, webhook *webapimocks.MockReportChannel) {
+				r.EXPECT().ClaimReportScheduleRun(ctx, "monthly_revenue", scheduledAt, policy).
+					Return(entity.ReportScheduleRun{Id: 1, Schedule: "monthly_revenue", ScheduledAt: scheduledAt, Attempts: 1}, nil)
+				o.EXPECT().GetAllRevenueOperationsGroupedByProduct(ctx, from, to).
+					Return(nil, errors.New("some error"))
+				st.EXPECT().UploadStream(ctx, "report_10_2022.csv", gomock.Any()).
+					DoAndReturn(readUpload(t, "", "", nil))
+
+				r.EXPECT().FinishReportScheduleRun(ctx, 1, []string{}, gomock.Not("")).
+					Return(nil)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// init deps
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			// init mocks
+			reportScheduleRepo := repomocks.NewMockReportSchedule(ctrl)
+			operationRepo := repomocks.NewMockOperation(ctrl)
+			archiveRepo := repomocks.NewMockReportArchive(ctrl)
+			reportStorage := webapimocks.NewMockReportStorage(ctrl)
+			email := webapimocks.NewMockReportChannel(ctrl)
+			webhook := webapimocks.NewMockReportChannel(ctrl)
+			tc.mockBehavior(reportScheduleRepo, operationRepo, archiveRepo, reportStorage, email, webhook)
+
+			// init service
+			reportLinkService := NewReportLinkService(nil, reportStorage, false, "", "", time.Hour, false)
+			operationService := NewOperationService(operationRepo, nil, archiveRepo, reportStorage, reportLinkService)
+			s := NewReportScheduleService(reportScheduleRepo, operationService, reportStorage, reportLinkService,
+				map[string]webapi.ReportChannel{"email": email, "webhook": webhook},
+				[]entity.ReportSchedule{schedule}, time.UTC, 24*time.Hour, policy)
+			s.now = func() time.Time { return tc.now }
+
+			// run test
+			s.RunReportSchedules(ctx)
+		})
+	}
+}
+
+func TestValidateReportSchedules(t *testing.T) {
+	channels := map[string]webapi.ReportChannel{"email": nil}
+	valid := entity.ReportSchedule{
+		Name:     "monthly_revenue",
+		Cron:     "0 6 1 * *",
+		Period:   entity.ReportSchedulePeriodMonth,
+		Channels: []entity.ReportChannel{{Type: "email", Recipients: []string{"accounting@example.com"}}},
+	}
+
+	testCases := []struct {
+		name    string
+		modify  func(s *entity.ReportSchedule)
+		wantErr bool
+	}{
+		{
+			name:   "OK",
+			modify: func(s *entity.ReportSchedule) {},
+		},
+		{
+			name:    "invalid cron",
+			modify:  func(s *entity.ReportSchedule) { s.Cron = "0 6 32 * *" },
+			wantErr: true,
+		},
+		{
+			name:    "unknown period",
+			modify:  func(s *entity.ReportSchedule) { s.Period = "previous_year" },
+			wantErr: true,
+		},
+		{
+			name:    "invalid report params",
+			modify:  func(s *entity.ReportSchedule) { s.Params.Format = "pdf" },
+			wantErr: true,
+		},
+		{
+			name: "unavailable channel",
+			modify: func(s *entity.ReportSchedule) {
+				s.Channels = []entity.ReportChannel{{Type: "sms", Recipients: []string{"+70000000000"}}}
+			},
+			wantErr: true,
+		},
+		{
+			name:    "channel without recipients",
+			modify:  func(s *entity.ReportSchedule) { s.Channels = []entity.ReportChannel{{Type: "email"}} },
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			schedule := valid
+			tc.modify(&schedule)
+
+			err := ValidateReportSchedules([]entity.ReportSchedule{schedule}, channels)
+			if tc.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+
+	// the names identify the runs, so they must be unique
+	err := ValidateReportSchedules([]entity.ReportSchedule{valid, valid}, channels)
+	assert.Error(t, err)
+}
+
+func TestReportSchedulePeriod(t *testing.T) {
+	// wednesday
+	at := time.Date(2022, time.November, 2, 6, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		period   string
+		wantFrom time.Time
+		wantTo   time.Time
+	}{
+		{entity.ReportSchedulePeriodDay, time.Date(2022, time.November, 1, 0, 0, 0, 0, time.UTC), time.Date(2022, time.November, 2, 0, 0, 0, 0, time.UTC)},
+		{entity.ReportSchedulePeriodWeek, time.Date(2022, time.October, 24, 0, 0, 0, 0, time.UTC), time.Date(2022, time.October, 31, 0, 0, 0, 0, time.UTC)},
+		{entity.ReportSchedulePeriodMonth, time.Date(2022, time.October, 1, 0, 0, 0, 0, time.UTC), time.Date(2022, time.November, 1, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.period, func(t *testing.T) {
+			from, to := reportSchedulePeriod(tc.period, at)
+			assert.Equal(t, tc.wantFrom, from)
+			assert.Equal(t, tc.wantTo, to)
+		})
+	}
+}
@@ -3,27 +3,78 @@ package service
 import (
 	"account-management-service/internal/entity"
 	"account-management-service/internal/repo"
+	"account-management-service/internal/repo/repoerrs"
 	"context"
+	"errors"
 )
 
+// defaultSpendingOrder spends promotional credit first
+var defaultSpendingOrder = []string{entity.WalletTypeBonus, entity.WalletTypeMain}
+
 type ReservationService struct {
 	reservationRepo repo.Reservation
+	limitService    Limit
+	feeService      Fee
+	spendingOrder   []string
 }
 
-func NewReservationService(reservationRepo repo.Reservation) *ReservationService {
-	return &ReservationService{reservationRepo: reservationRepo}
+func NewReservationService(reservationRepo repo.Reservation, limitService Limit, feeService Fee, spendingOrder []string) *ReservationService {
+	if len(spendingOrder) == 0 {
+		spendingOrder = defaultSpendingOrder
+	}
+
+	return &ReservationService{
+		reservationRepo: reservationRepo,
+		limitService:    limitService,
+		feeService:      feeService,
+		spendingOrder:   spendingOrder,
+	}
 }
 
 func (s *ReservationService) CreateReservation(ctx context.Context, input ReservationCreateInput) (int, error) {
+	limits, err := s.limitService.CheckLimits(ctx, input.AccountId, entity.OperationTypeReservation, input.Amount)
+	if err != nil {
+		return 0, err
+	}
+
+	fee, err := s.feeService.CalculateFee(ctx, FeeCalculateInput{
+		AccountId:     input.AccountId,
+		OperationType: entity.OperationTypeReservation,
+		ProductId:     input.ProductId,
+		Amount:        input.Amount,
+	})
+	if err != nil {
+		return 0, err
+	}
+
 	reservation := entity.Reservation{
 		AccountId: input.AccountId,
 		ProductId: input.ProductId,
 		OrderId:   input.OrderId,
 		Amount:    input.Amount,
+		UserId:    &input.UserId,
 	}
 
-	id, err := s.reservationRepo.CreateReservation(ctx, reservation)
+	id, err := s.reservationRepo.CreateReservation(ctx, reservation, s.spendingOrder, fee, limits)
 	if err != nil {
+		if errors.Is(err, repoerrs.ErrNotGroupMember) {
+			return 0, ErrNotGroupMember
+		}
+		if errors.Is(err, repoerrs.ErrMemberCannotSpend) {
+			return 0, ErrGroupAccessDenied
+		}
+		if errors.Is(err, repoerrs.ErrMemberLimitExceeded) {
+			return 0, ErrMemberLimitExceeded
+		}
+		if errors.Is(err, repoerrs.ErrLimitExceeded) {
+			return 0, limitRepoError(err)
+		}
+		if errors.Is(err, repoerrs.ErrAccountFrozen) {
+			return 0, ErrAccountFrozen
+		}
+		if errors.Is(err, repoerrs.ErrAccountClosed) {
+			return 0, ErrAccountClosed
+		}
 		return 0, ErrCannotCreateReservation
 	}
 
@@ -31,9 +82,23 @@ func (s *ReservationService) CreateReservation(ctx context.Context, input Reserv
 }
 
 func (s *ReservationService) RefundReservationByOrderId(ctx context.Context, orderId int) error {
-	return s.reservationRepo.RefundReservationByOrderId(ctx, orderId)
+	err := s.reservationRepo.RefundReservationByOrderId(ctx, orderId)
+	return accountRepoError(err)
+}
+
+// RefundCapturedOrder refunds the order whose revenue is already recognized, its cashback is taken back
+func (s *ReservationService) RefundCapturedOrder(ctx context.Context, orderId int) error {
+	err := s.reservationRepo.RefundCapturedOrder(ctx, orderId)
+	if errors.Is(err, repoerrs.ErrNotFound) {
+		return ErrCapturedOrderNotFound
+	}
+	return accountRepoError(err)
 }
 
 func (s *ReservationService) RevenueReservationByOrderId(ctx context.Context, orderId int) error {
-	return s.reservationRepo.RevenueReservationByOrderId(ctx, orderId)
+	err := s.reservationRepo.RevenueReservationByOrderId(ctx, orderId)
+	if errors.Is(err, repoerrs.ErrOutstandingInstallments) {
+		return ErrOutstandingInstallments
+	}
+	return accountRepoError(err)
 }
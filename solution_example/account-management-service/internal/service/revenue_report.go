@@ -0,0 +1,439 @@
+package service
+
+import (
+	"account-management-service/internal/entity"
+	"account-management-service/pkg/tabular"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// legacyRevenueReportColumns are the columns of the headerless legacy report, its consumers read them by position
+var legacyRevenueReportColumns = []tabular.Column{
+	{Name: "product", Type: tabular.String},
+	{Name: "amount", Type: tabular.Int},
+}
+
+// revenueReportColumns are the columns of the current report version
+var revenueReportColumns = []tabular.Column{
+	{Name: "product", Type: tabular.String},
+	{Name: "orders", Type: tabular.Int},
+	{Name: "revenue", Type: tabular.Int},
+	{Name: "refunded", Type: tabular.Int},
+	{Name: "cashback", Type: tabular.Int},
+	{Name: "net_revenue", Type: tabular.Int},
+	{Name: "main_amount", Type: tabular.Int},
+	{Name: "bonus_amount", Type: tabular.Int},
+	{Name: "average_check", Type: tabular.Decimal},
+}
+
+// accountsReportColumns are the columns of the accounts report, the account column is a string
+// as it holds total in the rows of the totals by product
+var accountsReportColumns = append([]tabular.Column{{Name: "account_id", Type: tabular.String}}, revenueReportColumns...)
+
+// seriesReportColumns are the columns of the time series report
+var seriesReportColumns = append([]tabular.Column{{Name: "period", Type: tabular.String}}, revenueReportColumns...)
+
+// reportDelimiters are the csv delimiters by their names
+var reportDelimiters = map[string]rune{
+	entity.ReportDelimiterComma:     ',',
+	entity.ReportDelimiterSemicolon: ';',
+	entity.ReportDelimiterTab:       '\t',
+}
+
+const revenueReportTotalRow = "total"
+
+// maxReportPeriods limits the length of the time series report
+const maxReportPeriods = 366
+
+// reportPeriodLayout is the layout of the period start in the time series report
+const reportPeriodLayout = "2006-01-02"
+
+// normalizeReportParams defaults the report to the products report in csv of the legacy version if it can be made,
+// of the current version otherwise, turns the month into the range and sorts the filters so that identical reports have the same key
+func normalizeReportParams(params entity.ReportParams) (entity.ReportParams, error) {
+	switch params.Version {
+	case 0:
+		params.Version = defaultReportVersion(params)
+	case entity.ReportVersionLegacy, entity.ReportVersionCurrent:
+	default:
+		return entity.ReportParams{}, ErrUnknownReportVersion
+	}
+
+	switch params.Mode {
+	case "":
+		params.Mode = entity.ReportModeProducts
+	case entity.ReportModeProducts, entity.ReportModeAccounts:
+	default:
+		return entity.ReportParams{}, ErrUnknownReportMode
+	}
+
+	if params.Mode == entity.ReportModeAccounts && params.Version == entity.ReportVersionLegacy {
+		return entity.ReportParams{}, fmt.Errorf("%w: the legacy version has no accounts report", ErrInvalidReportParams)
+	}
+	if params.Mode == entity.ReportModeProducts && (len(params.ProductIds) > 0 || len(params.AccountIds) > 0) {
+		return entity.ReportParams{}, fmt.Errorf("%w: only the accounts report can be filtered", ErrInvalidReportParams)
+	}
+
+	switch {
+	case params.Month != 0:
+		if params.Month < 1 || params.Month > 12 || params.Year <= 0 {
+			return entity.ReportParams{}, fmt.Errorf("%w: invalid month", ErrInvalidReportParams)
+		}
+		params.From = time.Date(params.Year, time.Month(params.Month), 1, 0, 0, 0, 0, time.UTC)
+		params.To = params.From.AddDate(0, 1, 0)
+	case params.Year != 0:
+		return entity.ReportParams{}, fmt.Errorf("%w: year without month", ErrInvalidReportParams)
+	case params.From.IsZero() || params.To.IsZero():
+		return entity.ReportParams{}, fmt.Errorf("%w: either month and year or the range is required", ErrInvalidReportParams)
+	case !params.To.After(params.From):
+		return entity.ReportParams{}, fmt.Errorf("%w: the end of the range must be after its start", ErrInvalidReportParams)
+	default:
+		params.From = params.From.UTC()
+		params.To = params.To.UTC()
+	}
+
+	if params.Granularity != "" {
+		if params.Mode != entity.ReportModeProducts || params.Version == entity.ReportVersionLegacy {
+			return entity.ReportParams{}, fmt.Errorf("%w: only the current products report can be a time series", ErrInvalidReportParams)
+		}
+		if _, ok := reportPeriodSteps[params.Granularity]; !ok {
+			return entity.ReportParams{}, fmt.Errorf("%w: unknown granularity", ErrInvalidReportParams)
+		}
+		if len(reportPeriods(params.From, params.To, params.Granularity)) > maxReportPeriods {
+			return entity.ReportParams{}, fmt.Errorf("%w: more than %d periods", ErrInvalidReportParams, maxReportPeriods)
+		}
+	}
+
+	var err error
+	params.Format, params.CSVDelimiter, err = normalizeReportFormat(params.Format, params.CSVDelimiter, params.CSVBOM)
+	if err != nil {
+		return entity.ReportParams{}, err
+	}
+	if params.Format != entity.ReportFormatCSV && params.Version == entity.ReportVersionLegacy {
+		return entity.ReportParams{}, fmt.Errorf("%w: the legacy version is csv only", ErrInvalidReportParams)
+	}
+
+	params.ProductIds = sortedUniqueIds(params.ProductIds)
+	params.AccountIds = sortedUniqueIds(params.AccountIds)
+
+	return params, nil
+}
+
+// defaultReportVersion returns the legacy version for the reports which could be requested before
+// the versions existed, so that their consumers get the same file
+func defaultReportVersion(params entity.ReportParams) int {
+	legacy := (params.Mode == "" || params.Mode == entity.ReportModeProducts) &&
+		params.Granularity == "" &&
+		(params.Format == "" || params.Format == entity.ReportFormatCSV)
+	if legacy {
+		return entity.ReportVersionLegacy
+	}
+	return entity.ReportVersionCurrent
+}
+
+// normalizeReportFormat defaults the format to csv and the csv delimiter to comma
+func normalizeReportFormat(format, delimiter string, bom bool) (string, string, error) {
+	switch format {
+	case "":
+		format = entity.ReportFormatCSV
+	case entity.ReportFormatCSV, entity.ReportFormatXLSX, entity.ReportFormatJSON, entity.ReportFormatParquet:
+	default:
+		return "", "", fmt.Errorf("%w: unknown format", ErrInvalidReportParams)
+	}
+
+	if format != entity.ReportFormatCSV {
+		if delimiter != "" || bom {
+			return "", "", fmt.Errorf("%w: the delimiter and the bom are csv options", ErrInvalidReportParams)
+		}
+		return format, "", nil
+	}
+
+	if delimiter == "" {
+		delimiter = entity.ReportDelimiterComma
+	}
+	if _, ok := reportDelimiters[delimiter]; !ok {
+		return "", "", fmt.Errorf("%w: unknown delimiter", ErrInvalidReportParams)
+	}
+
+	return format, delimiter, nil
+}
+
+// reportPeriodSteps advance the start of the period to the start of the next one
+var reportPeriodSteps = map[string]func(time.Time) time.Time{
+	entity.ReportGranularityDay:     func(t time.Time) time.Time { return t.AddDate(0, 0, 1) },
+	entity.ReportGranularityWeek:    func(t time.Time) time.Time { return t.AddDate(0, 0, 7) },
+	entity.ReportGranularityMonth:   func(t time.Time) time.Time { return t.AddDate(0, 1, 0) },
+	entity.ReportGranularityQuarter: func(t time.Time) time.Time { return t.AddDate(0, 3, 0) },
+}
+
+// periodStart truncates the time the same way date_trunc does, weeks start on monday
+func periodStart(t time.Time, granularity string) time.Time {
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	switch granularity {
+	case entity.ReportGranularityWeek:
+		return day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+	case entity.ReportGranularityMonth:
+		return day.AddDate(0, 0, 1-day.Day())
+	case entity.ReportGranularityQuarter:
+		return time.Date(t.Year(), (t.Month()-1)/3*3+1, 1, 0, 0, 0, 0, t.Location())
+	default:
+		return day
+	}
+}
+
+// reportPeriods returns the starts of the periods overlapping the [from, to) range
+func reportPeriods(from, to time.Time, granularity string) []time.Time {
+	next := reportPeriodSteps[granularity]
+
+	var periods []time.Time
+	for period := periodStart(from, granularity); period.Before(to); period = next(period) {
+		periods = append(periods, period)
+		if len(periods) > maxReportPeriods {
+			break
+		}
+	}
+	return periods
+}
+
+func sortedUniqueIds(ids []int) []int {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	sorted := make([]int, len(ids))
+	copy(sorted, ids)
+	sort.Ints(sorted)
+
+	unique := sorted[:1]
+	for _, id := range sorted[1:] {
+		if id != unique[len(unique)-1] {
+			unique = append(unique, id)
+		}
+	}
+	return unique
+}
+
+// reportColumns returns the columns of the report
+func reportColumns(params entity.ReportParams) []tabular.Column {
+	switch {
+	case params.Version == entity.ReportVersionLegacy:
+		return legacyRevenueReportColumns
+	case params.Mode == entity.ReportModeAccounts:
+		return accountsReportColumns
+	case params.Granularity != "":
+		return seriesReportColumns
+	default:
+		return revenueReportColumns
+	}
+}
+
+// newReportEncoder returns the encoder of the report format, the legacy csv report has no header
+func newReportEncoder(params entity.ReportParams, w io.Writer) tabular.Encoder {
+	return newTableEncoder(w, reportColumns(params), params.Format, tabular.CSVOptions{
+		Delimiter: reportDelimiters[params.CSVDelimiter],
+		BOM:       params.CSVBOM,
+		NoHeader:  params.Version == entity.ReportVersionLegacy,
+	})
+}
+
+// newTableEncoder returns the encoder of the normalized format, csv options apply to csv only
+func newTableEncoder(w io.Writer, columns []tabular.Column, format string, csvOptions tabular.CSVOptions) tabular.Encoder {
+	switch format {
+	case entity.ReportFormatXLSX:
+		return tabular.NewXLSXEncoder(w, columns)
+	case entity.ReportFormatJSON:
+		return tabular.NewJSONEncoder(w, columns)
+	case entity.ReportFormatParquet:
+		return tabular.NewParquetEncoder(w, columns)
+	default:
+		return tabular.NewCSVEncoder(w, columns, csvOptions)
+	}
+}
+
+// writeLegacyRevenueReport writes the report of the first version: product and gross revenue. The products
+// without revenue recognized during the period, e.g. with refunds only, are not in it, as they were not before
+func writeLegacyRevenueReport(enc tabular.Encoder, revenues []entity.ProductRevenue) error {
+	for _, revenue := range revenues {
+		if revenue.Revenue == 0 {
+			continue
+		}
+		err := enc.WriteRow(tabular.Row{Values: []any{
+			revenue.Product,
+			revenue.Revenue,
+		}})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// writeRevenueReport writes a row per product and the total row. Fees are not the revenue of any product,
+// so they are not in the report, the fee income is reported by the fee service
+func writeRevenueReport(enc tabular.Encoder, revenues []entity.ProductRevenue) error {
+	var total entity.ProductRevenue
+	for _, revenue := range revenues {
+		if err := enc.WriteRow(tabular.Row{Values: revenueReportRow(revenue.Product, revenue)}); err != nil {
+			return err
+		}
+		addRevenue(&total, revenue)
+	}
+
+	return enc.WriteRow(tabular.Row{Values: revenueReportRow(revenueReportTotalRow, total), Total: true})
+}
+
+// writeRevenueSeriesReport writes the time series of every product followed by the total series.
+// Every series has a row for each of the periods, the periods without revenue are zero
+func writeRevenueSeriesReport(enc tabular.Encoder, periods []time.Time, revenues []entity.PeriodProductRevenue) error {
+	var products []string
+	// the series are keyed by the formatted period, as the times read from the database are not comparable with ==
+	series := make(map[string]map[string]entity.ProductRevenue)
+	totals := make(map[string]entity.ProductRevenue)
+	for _, revenue := range revenues {
+		if _, ok := series[revenue.Product]; !ok {
+			series[revenue.Product] = make(map[string]entity.ProductRevenue)
+			products = append(products, revenue.Product)
+		}
+		period := revenue.Period.Format(reportPeriodLayout)
+		series[revenue.Product][period] = revenue.ProductRevenue
+
+		total := totals[period]
+		addRevenue(&total, revenue.ProductRevenue)
+		totals[period] = total
+	}
+	series[revenueReportTotalRow] = totals
+	products = append(products, revenueReportTotalRow)
+
+	for _, product := range products {
+		for _, period := range periods {
+			key := period.Format(reportPeriodLayout)
+			row := revenueReportRow(product, series[product][key])
+			err := enc.WriteRow(tabular.Row{
+				Values: append([]any{key}, row...),
+				Total:  product == revenueReportTotalRow,
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func revenueReportRow(name string, revenue entity.ProductRevenue) []any {
+	return []any{
+		name,
+		revenue.Orders,
+		revenue.Revenue,
+		revenue.Refunded,
+		revenue.Cashback,
+		revenue.Amount,
+		revenue.MainAmount,
+		revenue.BonusAmount,
+		averageCheck(revenue.Amount, revenue.Orders),
+	}
+}
+
+// averageCheck is the net revenue per order, it is empty if there are no orders
+func averageCheck(amount, orders int) any {
+	if orders == 0 {
+		return nil
+	}
+	return float64(amount) / float64(orders)
+}
+
+// accountsReportWriter writes the accounts report row by row as the revenue is read:
+// product rows of the account are followed by the account total row, the totals by product
+// and the grand total row are written at the end. Rows must come ordered by account
+type accountsReportWriter struct {
+	enc tabular.Encoder
+
+	account      int
+	accountTotal entity.ProductRevenue
+	// products are the keys of productTotals, the totals are written sorted by product
+	products      []string
+	productTotals map[string]*entity.ProductRevenue
+}
+
+func newAccountsReportWriter(enc tabular.Encoder) *accountsReportWriter {
+	return &accountsReportWriter{
+		enc:           enc,
+		productTotals: make(map[string]*entity.ProductRevenue),
+	}
+}
+
+func (a *accountsReportWriter) Write(revenue entity.AccountProductRevenue) error {
+	if revenue.AccountId != a.account {
+		if err := a.writeAccountTotal(); err != nil {
+			return err
+		}
+		a.account = revenue.AccountId
+	}
+
+	row := revenueReportRow(revenue.Product, revenue.ProductRevenue)
+	if err := a.enc.WriteRow(tabular.Row{Values: append([]any{strconv.Itoa(revenue.AccountId)}, row...)}); err != nil {
+		return err
+	}
+	addRevenue(&a.accountTotal, revenue.ProductRevenue)
+
+	productTotal, ok := a.productTotals[revenue.Product]
+	if !ok {
+		productTotal = &entity.ProductRevenue{}
+		a.productTotals[revenue.Product] = productTotal
+		a.products = append(a.products, revenue.Product)
+	}
+	addRevenue(productTotal, revenue.ProductRevenue)
+
+	return nil
+}
+
+// Close writes the total rows
+func (a *accountsReportWriter) Close() error {
+	if err := a.writeAccountTotal(); err != nil {
+		return err
+	}
+
+	sort.Strings(a.products)
+
+	var total entity.ProductRevenue
+	for _, product := range a.products {
+		productTotal := *a.productTotals[product]
+		row := revenueReportRow(product, productTotal)
+		if err := a.writeTotal(revenueReportTotalRow, row); err != nil {
+			return err
+		}
+		addRevenue(&total, productTotal)
+	}
+
+	row := revenueReportRow(revenueReportTotalRow, total)
+	return a.writeTotal(revenueReportTotalRow, row)
+}
+
+func (a *accountsReportWriter) writeAccountTotal() error {
+	if a.account == 0 {
+		return nil
+	}
+
+	row := revenueReportRow(revenueReportTotalRow, a.accountTotal)
+	a.accountTotal = entity.ProductRevenue{}
+	return a.writeTotal(strconv.Itoa(a.account), row)
+}
+
+func (a *accountsReportWriter) writeTotal(account string, row []any) error {
+	return a.enc.WriteRow(tabular.Row{Values: append([]any{account}, row...), Total: true})
+}
+
+func addRevenue(total *entity.ProductRevenue, revenue entity.ProductRevenue) {
+	total.Orders += revenue.Orders
+	total.Revenue += revenue.Revenue
+	total.Refunded += revenue.Refunded
+	total.Cashback += revenue.Cashback
+	total.Amount += revenue.Amount
+	total.MainAmount += revenue.MainAmount
+	total.BonusAmount += revenue.BonusAmount
+}
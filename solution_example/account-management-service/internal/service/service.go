@@ -6,6 +6,7 @@ import (
 	"account-management-service/internal/webapi"
 	"account-management-service/pkg/hasher"
 	"context"
+	"io"
 	"time"
 )
 
@@ -23,30 +24,81 @@ type Auth interface {
 	CreateUser(ctx context.Context, input AuthCreateUserInput) (int, error)
 	GenerateToken(ctx context.Context, input AuthGenerateTokenInput) (string, error)
 	ParseToken(token string) (int, error)
+	GetUserRole(ctx context.Context, userId int) (string, error)
+	GrantRoles(ctx context.Context, roles map[string]string) error
+}
+
+type AccountCreateInput struct {
+	Type string
 }
 
 type AccountDepositInput struct {
 	Id     int
 	Amount int
+	Wallet string
 }
 
 type AccountWithdrawInput struct {
 	Id     int
 	Amount int
+	// UserId is the user making the operation, the member role and monthly limit are checked for a shared account
+	UserId int
 }
 
 type AccountTransferInput struct {
 	From   int
 	To     int
 	Amount int
+	UserId int
+}
+
+type AccountScheduleTransferInput struct {
+	From      int
+	To        int
+	Amount    int
+	ExecuteAt time.Time
+	Repeat    string
+	UserId    int
+}
+
+type AccountFreezeInput struct {
+	Id         int
+	DebitOnly  bool
+	ReasonCode string
+	Comment    string
+	ChangedBy  int
+}
+
+type AccountUnfreezeInput struct {
+	Id         int
+	ReasonCode string
+	Comment    string
+	ChangedBy  int
+}
+
+type AccountCloseInput struct {
+	Id         int
+	Payout     bool
+	ReasonCode string
+	Comment    string
+	ChangedBy  int
 }
 
 type Account interface {
-	CreateAccount(ctx context.Context) (int, error)
+	CreateAccount(ctx context.Context, input AccountCreateInput) (int, error)
 	GetAccountById(ctx context.Context, userId int) (entity.Account, error)
+	GetAccountWallets(ctx context.Context, accountId int) ([]entity.Wallet, error)
 	Deposit(ctx context.Context, input AccountDepositInput) error
 	Withdraw(ctx context.Context, input AccountWithdrawInput) error
 	Transfer(ctx context.Context, input AccountTransferInput) error
+	ScheduleTransfer(ctx context.Context, input AccountScheduleTransferInput) (int, error)
+	CancelScheduledTransfer(ctx context.Context, id int) error
+	GetScheduledTransfer(ctx context.Context, id int) (entity.ScheduledTransfer, []entity.ScheduledTransferRun, error)
+	ExecuteDueTransfers(ctx context.Context)
+	FreezeAccount(ctx context.Context, input AccountFreezeInput) error
+	UnfreezeAccount(ctx context.Context, input AccountUnfreezeInput) error
+	CloseAccount(ctx context.Context, input AccountCloseInput) (int, error)
+	GetAccountStatusHistory(ctx context.Context, accountId int) ([]entity.AccountStatusChange, error)
 }
 
 type Product interface {
@@ -59,11 +111,14 @@ type ReservationCreateInput struct {
 	ProductId int
 	OrderId   int
 	Amount    int
+	// UserId is the user making the reservation, the member role and monthly limit are checked for a shared account
+	UserId int
 }
 
 type Reservation interface {
 	CreateReservation(ctx context.Context, input ReservationCreateInput) (int, error)
 	RefundReservationByOrderId(ctx context.Context, orderId int) error
+	RefundCapturedOrder(ctx context.Context, orderId int) error
 	RevenueReservationByOrderId(ctx context.Context, orderId int) error
 }
 
@@ -78,40 +133,437 @@ type OperationHistoryOutput struct {
 	Amount      int       `json:"amount"`
 	Operation   string    `json:"operation"`
 	Time        time.Time `json:"time"`
+	Wallet      string    `json:"wallet"`
 	Product     string    `json:"product,omitempty"`
 	Order       *int      `json:"order,omitempty"`
 	Description string    `json:"description,omitempty"`
+	Member      *int      `json:"member,omitempty"`
 }
 
 type Operation interface {
 	OperationHistory(ctx context.Context, input OperationHistoryInput) ([]OperationHistoryOutput, error)
-	MakeReportLink(ctx context.Context, month, year int) (string, error)
-	MakeReportFile(ctx context.Context, month, year int) ([]byte, error)
+	MakeReportLink(ctx context.Context, params entity.ReportParams, createdBy int) (string, error)
+	MakeReportFile(ctx context.Context, params entity.ReportParams) ([]byte, error)
+	UploadReport(ctx context.Context, params entity.ReportParams, createdBy int) (string, string, error)
+	WriteReport(ctx context.Context, params entity.ReportParams, w io.Writer) error
+	WriteOperationsExport(ctx context.Context, params entity.OperationExportParams, w io.Writer) error
+	MakeOperationsExportLink(ctx context.Context, params entity.OperationExportParams, createdBy int) (string, error)
+	MemberSpending(ctx context.Context, accountId int) ([]entity.MemberSpending, error)
+}
+
+type ReportDownloadInput struct {
+	LinkId    int
+	Expires   int64
+	Signature string
+	IP        string
+	UserAgent string
+}
+
+type ReportDownloadOutput struct {
+	Filename string
+	Data     []byte
+}
+
+type ReportJobCreateInput struct {
+	Month        int
+	Year         int
+	From         time.Time
+	To           time.Time
+	Version      int
+	Mode         string
+	Granularity  string
+	ProductIds   []int
+	AccountIds   []int
+	Format       string
+	CSVDelimiter string
+	CSVBOM       bool
+	CreatedBy    int
+}
+
+type ReportJob interface {
+	CreateReportJob(ctx context.Context, input ReportJobCreateInput) (int, error)
+	GetReportJob(ctx context.Context, id int) (entity.ReportJob, error)
+	RunReportJobs(ctx context.Context)
+}
+
+type ReportScheduleOutput struct {
+	entity.ReportSchedule
+	NextRunAt time.Time
+}
+
+type ReportSchedule interface {
+	GetReportSchedules(ctx context.Context) ([]ReportScheduleOutput, error)
+	GetReportScheduleRuns(ctx context.Context, schedule string, offset, limit int) ([]entity.ReportScheduleRun, error)
+	RunReportSchedules(ctx context.Context)
+}
+
+type ReportArchiveListInput struct {
+	Kind      string
+	CreatedBy int
+	Offset    int
+	Limit     int
+}
+
+type ReportArchive interface {
+	GetArchivedReports(ctx context.Context, input ReportArchiveListInput) ([]entity.ArchivedReport, error)
+	DownloadArchivedReport(ctx context.Context, id int) (ReportDownloadOutput, error)
+	DeleteArchivedReport(ctx context.Context, id int) error
+	PurgeExpiredReports(ctx context.Context)
+}
+
+type ReportLink interface {
+	ShareLink(ctx context.Context, filename, storageLink string) (string, error)
+	Download(ctx context.Context, input ReportDownloadInput) (ReportDownloadOutput, error)
+	GetLinkAccesses(ctx context.Context, linkId int) ([]entity.ReportLinkAccess, error)
+}
+
+type LimitCreateRuleInput struct {
+	Scope         string
+	AccountType   string
+	AccountId     int
+	OperationType string
+	LimitType     string
+	Value         int
+	WindowSeconds int
+}
+
+type Limit interface {
+	CreateLimitRule(ctx context.Context, input LimitCreateRuleInput) (int, error)
+	GetAllLimitRules(ctx context.Context) ([]entity.LimitRule, error)
+	DeleteLimitRule(ctx context.Context, id int) error
+	CheckLimits(ctx context.Context, accountId int, operationType string, amount int) ([]entity.LimitRule, error)
+}
+
+type VerificationSetLevelInput struct {
+	AccountId int
+	Level     string
+	// Reference is the id of the check in the identity service
+	Reference string
+	ChangedBy int
+}
+
+type VerificationSetPolicyInput struct {
+	Level         string
+	OperationType string
+	MaxAmount     int
+	MaxTotal      int
+	WindowSeconds int
+}
+
+type Verification interface {
+	SetVerificationLevel(ctx context.Context, input VerificationSetLevelInput) error
+	GetVerificationHistory(ctx context.Context, accountId int) ([]entity.VerificationChange, error)
+	SetVerificationPolicy(ctx context.Context, input VerificationSetPolicyInput) error
+	DeleteVerificationPolicy(ctx context.Context, level, operationType string) error
+	GetAllVerificationPolicies(ctx context.Context) ([]entity.VerificationPolicy, error)
+	CheckVerification(ctx context.Context, accountId int, operationType string, amount int) (entity.VerificationCheck, error)
+}
+
+type FeeCreateRuleInput struct {
+	OperationType string
+	ProductId     int
+	AccountType   string
+	Rate          int
+	Fixed         int
+	MinFee        int
+	MaxFee        int
+	FreeUpTo      int
+}
+
+type FeeCalculateInput struct {
+	AccountId     int
+	OperationType string
+	ProductId     int
+	Amount        int
+}
+
+type Fee interface {
+	CreateFeeRule(ctx context.Context, input FeeCreateRuleInput) (int, error)
+	GetAllFeeRules(ctx context.Context) ([]entity.FeeRule, error)
+	DeleteFeeRule(ctx context.Context, id int) error
+	CalculateFee(ctx context.Context, input FeeCalculateInput) (entity.Fee, error)
+	GetFeeIncome(ctx context.Context, from, to time.Time) (int, error)
+}
+
+type CashbackCreateRuleInput struct {
+	ProductId int
+	Rate      int
+	Cap       int
+	ValidFrom time.Time
+	ValidTo   *time.Time
+}
+
+type Cashback interface {
+	CreateCashbackRule(ctx context.Context, input CashbackCreateRuleInput) (int, error)
+	GetAllCashbackRules(ctx context.Context) ([]entity.CashbackRule, error)
+	DeleteCashbackRule(ctx context.Context, id int) error
+}
+
+type SubscriptionCreateInput struct {
+	AccountId int
+	ProductId int
+	Amount    int
+	Period    string
+	StartAt   time.Time
+	UserId    int
+}
+
+type Subscription interface {
+	CreateSubscription(ctx context.Context, input SubscriptionCreateInput) (int, error)
+	GetSubscriptionById(ctx context.Context, id int) (entity.Subscription, error)
+	GetAccountSubscriptions(ctx context.Context, accountId int) ([]entity.Subscription, error)
+	GetSubscriptionCharges(ctx context.Context, id int) ([]entity.SubscriptionCharge, error)
+	PauseSubscription(ctx context.Context, id int) error
+	ResumeSubscription(ctx context.Context, id int) error
+	CancelSubscription(ctx context.Context, id int) error
+	ChargeDueSubscriptions(ctx context.Context)
+}
+
+type PaymentRequestCreateInput struct {
+	RequesterAccountId int
+	PayerAccountId     int
+	Amount             int
+	Description        string
+}
+
+type PaymentRequest interface {
+	CreatePaymentRequest(ctx context.Context, input PaymentRequestCreateInput) (int, error)
+	GetPaymentRequestById(ctx context.Context, id int) (entity.PaymentRequest, error)
+	GetAccountPaymentRequests(ctx context.Context, accountId int, status string) ([]entity.PaymentRequest, error)
+	AcceptPaymentRequest(ctx context.Context, id, userId int) error
+	DeclinePaymentRequest(ctx context.Context, id int) error
+}
+
+type InstallmentCreatePlanInput struct {
+	AccountId    int
+	ProductId    int
+	OrderId      int
+	Amount       int
+	FirstPayment int
+	Parts        int
+	Period       string
+	UserId       int
+}
+
+type GroupSetMemberInput struct {
+	AccountId    int
+	UserId       int
+	Role         string
+	MonthlyLimit int
+	// ActorId is the user making the change, only owners manage members
+	ActorId int
+}
+
+type Group interface {
+	CreateGroupAccount(ctx context.Context, ownerId int) (int, error)
+	SetGroupMember(ctx context.Context, input GroupSetMemberInput) error
+	RemoveGroupMember(ctx context.Context, accountId, userId, actorId int) error
+	GetGroupMembers(ctx context.Context, accountId, actorId int) ([]entity.GroupMember, error)
+}
+
+type Installment interface {
+	CreateInstallmentPlan(ctx context.Context, input InstallmentCreatePlanInput) (int, error)
+	GetInstallmentPlan(ctx context.Context, id int) (entity.InstallmentPlan, []entity.InstallmentPart, error)
+	GetAccountObligations(ctx context.Context, accountId int) ([]entity.InstallmentObligation, error)
+	ChargeDueInstallments(ctx context.Context)
+}
+
+type AdjustmentCreateInput struct {
+	AccountId  int
+	Direction  string
+	Amount     int
+	Wallet     string
+	ReasonCode string
+	Comment    string
+	Ticket     string
+	CreatedBy  int
+}
+
+type Adjustment interface {
+	CreateAdjustment(ctx context.Context, input AdjustmentCreateInput) (int, error)
+	GetAccountAdjustments(ctx context.Context, accountId int) ([]entity.Adjustment, error)
+	SetAdjustmentReason(ctx context.Context, code, description string) error
+	DeactivateAdjustmentReason(ctx context.Context, code string) error
+	GetAdjustmentReasons(ctx context.Context) ([]entity.AdjustmentReason, error)
+}
+
+type ApprovalCreateInput struct {
+	OperationType string
+	AccountId     int
+	OrderId       int
+	Amount        int
+	Wallet        string
+	// ReasonCode, Comment and Ticket describe adjustments
+	ReasonCode string
+	Comment    string
+	Ticket     string
+	MakerId    int
+}
+
+type ApprovalResolveInput struct {
+	Id        int
+	CheckerId int
+	Comment   string
+}
+
+type Approval interface {
+	RequiresApproval(operationType string, amount int) bool
+	CreateApprovalRequest(ctx context.Context, input ApprovalCreateInput) (int, error)
+	GetApprovalRequest(ctx context.Context, id int) (entity.ApprovalRequest, []entity.ApprovalEvent, error)
+	GetApprovalRequests(ctx context.Context, status string) ([]entity.ApprovalRequest, error)
+	ApproveRequest(ctx context.Context, input ApprovalResolveInput) (entity.ApprovalRequest, error)
+	RejectRequest(ctx context.Context, input ApprovalResolveInput) error
+	ExpireStaleRequests(ctx context.Context)
+}
+
+type VoucherCreateCampaignInput struct {
+	Name      string
+	Amount    int
+	Wallet    string
+	Count     int
+	MaxUses   int
+	ExpiresAt time.Time
+}
+
+type VoucherRedeemInput struct {
+	Code      string
+	AccountId int
+}
+
+type Voucher interface {
+	CreateVoucherCampaign(ctx context.Context, input VoucherCreateCampaignInput) (int, []string, error)
+	RedeemVoucher(ctx context.Context, input VoucherRedeemInput) (entity.VoucherRedemption, error)
+	GetVoucherCampaignReport(ctx context.Context, campaignId int) (entity.VoucherCampaignReport, error)
+}
+
+type BatchExecuteInput struct {
+	Mode        string
+	Description string
+	Items       []entity.BatchItem
+}
+
+type Batch interface {
+	ExecuteBatch(ctx context.Context, input BatchExecuteInput) ([]entity.BatchItemResult, error)
 }
 
 type Services struct {
-	Auth        Auth
-	Account     Account
-	Product     Product
-	Reservation Reservation
-	Operation   Operation
+	Auth           Auth
+	Account        Account
+	Product        Product
+	Reservation    Reservation
+	Operation      Operation
+	ReportLink     ReportLink
+	ReportJob      ReportJob
+	ReportSchedule ReportSchedule
+	ReportArchive  ReportArchive
+	Limit          Limit
+	Verification   Verification
+	Fee            Fee
+	Cashback       Cashback
+	Subscription   Subscription
+	PaymentRequest PaymentRequest
+	Installment    Installment
+	Group          Group
+	Approval       Approval
+	Adjustment     Adjustment
+	Voucher        Voucher
+	Batch          Batch
 }
 
 type ServicesDependencies struct {
-	Repos  *repo.Repositories
-	GDrive webapi.GDrive
-	Hasher hasher.PasswordHasher
+	Repos         *repo.Repositories
+	ReportStorage webapi.ReportStorage
+	Hasher        hasher.PasswordHasher
 
 	SignKey  string
 	TokenTTL time.Duration
+
+	SpendingOrder []string
+
+	FeeAccountId int
+
+	ReportLinksSigned  bool
+	ReportLinksSignKey string
+	ReportLinksBaseURL string
+	ReportLinksTTL     time.Duration
+	ReportLinksOneTime bool
+
+	ReportJobMaxAttempts  int
+	ReportJobRetryBackoff time.Duration
+	ReportJobStaleAfter   time.Duration
+
+	ReportChannels              map[string]webapi.ReportChannel
+	ReportSchedules             []entity.ReportSchedule
+	ReportScheduleLocation      *time.Location
+	ReportScheduleCatchUp       time.Duration
+	ReportScheduleMaxAttempts   int
+	ReportScheduleRetryInterval time.Duration
+	ReportScheduleStaleAfter    time.Duration
+
+	ReportArchiveRetention time.Duration
+
+	SubscriptionRetryInterval time.Duration
+	SubscriptionGracePeriod   time.Duration
+
+	PaymentRequestTTL time.Duration
+
+	InstallmentRetryInterval time.Duration
+	InstallmentMaxAttempts   int
+	InstallmentCancelOrder   bool
+
+	ApprovalThresholds map[string]int
+	ApprovalTTL        time.Duration
+
+	BatchChunkSize int
+	BatchMaxItems  int
 }
 
 func NewServices(deps ServicesDependencies) *Services {
+	limitService := NewLimitService(deps.Repos.Limit, deps.Repos.Account)
+	feeService := NewFeeService(deps.Repos.Fee, deps.Repos.Account, deps.Repos.Operation, deps.FeeAccountId)
+	verificationService := NewVerificationService(deps.Repos.Verification, deps.Repos.Account)
+	installmentService := NewInstallmentService(deps.Repos.Installment, limitService, feeService, deps.SpendingOrder, entity.MissedInstallmentPolicy{
+		MaxAttempts:   deps.InstallmentMaxAttempts,
+		RetryInterval: deps.InstallmentRetryInterval,
+		CancelOrder:   deps.InstallmentCancelOrder,
+	})
+	accountService := NewAccountService(deps.Repos.Account, deps.Repos.ScheduledTransfer, deps.Repos.Group, limitService, verificationService, feeService)
+	reservationService := NewReservationService(deps.Repos.Reservation, limitService, feeService, deps.SpendingOrder)
+	adjustmentService := NewAdjustmentService(deps.Repos.Adjustment)
+	approvalService := NewApprovalService(deps.Repos.Approval, limitService, verificationService, feeService, deps.ApprovalThresholds, deps.ApprovalTTL)
+	reportLinkService := NewReportLinkService(deps.Repos.ReportLink, deps.ReportStorage, deps.ReportLinksSigned,
+		deps.ReportLinksSignKey, deps.ReportLinksBaseURL, deps.ReportLinksTTL, deps.ReportLinksOneTime)
+	operationService := NewOperationService(deps.Repos.Operation, deps.Repos.Product, deps.Repos.ReportArchive, deps.ReportStorage, reportLinkService)
+	reportJobService := NewReportJobService(deps.Repos.ReportJob, operationService, deps.ReportStorage, reportLinkService,
+		deps.ReportJobMaxAttempts, deps.ReportJobRetryBackoff, deps.ReportJobStaleAfter)
+	reportScheduleService := NewReportScheduleService(deps.Repos.ReportSchedule, operationService, deps.ReportStorage, reportLinkService,
+		deps.ReportChannels, deps.ReportSchedules, deps.ReportScheduleLocation, deps.ReportScheduleCatchUp, entity.ReportScheduleRetryPolicy{
+			MaxAttempts:   deps.ReportScheduleMaxAttempts,
+			RetryInterval: deps.ReportScheduleRetryInterval,
+			StaleAfter:    deps.ReportScheduleStaleAfter,
+		})
+
 	return &Services{
-		Auth:        NewAuthService(deps.Repos.User, deps.Hasher, deps.SignKey, deps.TokenTTL),
-		Account:     NewAccountService(deps.Repos.Account),
-		Product:     NewProductService(deps.Repos.Product),
-		Reservation: NewReservationService(deps.Repos.Reservation),
-		Operation:   NewOperationService(deps.Repos.Operation, deps.Repos.Product, deps.GDrive),
+		Auth:           NewAuthService(deps.Repos.User, deps.Hasher, deps.SignKey, deps.TokenTTL),
+		Account:        accountService,
+		Product:        NewProductService(deps.Repos.Product),
+		Reservation:    reservationService,
+		Operation:      operationService,
+		ReportLink:     reportLinkService,
+		ReportJob:      reportJobService,
+		ReportSchedule: reportScheduleService,
+		ReportArchive:  NewReportArchiveService(deps.Repos.ReportArchive, deps.ReportStorage, deps.ReportArchiveRetention),
+		Limit:          limitService,
+		Verification:   verificationService,
+		Fee:            feeService,
+		Cashback:       NewCashbackService(deps.Repos.Cashback),
+		Subscription:   NewSubscriptionService(deps.Repos.Subscription, deps.Repos.Group, limitService, feeService, deps.SpendingOrder, deps.SubscriptionRetryInterval, deps.SubscriptionGracePeriod),
+		PaymentRequest: NewPaymentRequestService(deps.Repos.PaymentRequest, limitService, verificationService, feeService, deps.PaymentRequestTTL),
+		Installment:    installmentService,
+		Group:          NewGroupService(deps.Repos.Group),
+		Approval:       approvalService,
+		Adjustment:     adjustmentService,
+		Voucher:        NewVoucherService(deps.Repos.Voucher, deps.Repos.Account),
+		Batch:          NewBatchService(deps.Repos.Batch, approvalService, limitService, verificationService, feeService, deps.BatchChunkSize, deps.BatchMaxItems),
 	}
 }
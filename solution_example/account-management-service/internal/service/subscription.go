@@ -0,0 +1,188 @@
+package service
+
+import (
+	"account-management-service/internal/entity"
+	"account-management-service/internal/repo"
+	"account-management-service/internal/repo/repoerrs"
+	"context"
+	"errors"
+	log "github.com/sirupsen/logrus"
+	"time"
+)
+
+// subscriptionChargeBatchSize is the max number of subscriptions charged in a single scheduler run
+const subscriptionChargeBatchSize = 100
+
+type SubscriptionService struct {
+	subscriptionRepo repo.Subscription
+	groupRepo        repo.Group
+	limitService     Limit
+	feeService       Fee
+	spendingOrder    []string
+	retryInterval    time.Duration
+	gracePeriod      time.Duration
+}
+
+func NewSubscriptionService(subscriptionRepo repo.Subscription, groupRepo repo.Group, limitService Limit, feeService Fee, spendingOrder []string, retryInterval, gracePeriod time.Duration) *SubscriptionService {
+	if len(spendingOrder) == 0 {
+		spendingOrder = defaultSpendingOrder
+	}
+
+	return &SubscriptionService{
+		subscriptionRepo: subscriptionRepo,
+		groupRepo:        groupRepo,
+		limitService:     limitService,
+		feeService:       feeService,
+		spendingOrder:    spendingOrder,
+		retryInterval:    retryInterval,
+		gracePeriod:      gracePeriod,
+	}
+}
+
+func (s *SubscriptionService) CreateSubscription(ctx context.Context, input SubscriptionCreateInput) (int, error) {
+	// the subscription is charged by the scheduler on behalf of the account, so the user is checked now
+	err := checkSpender(ctx, s.groupRepo, input.AccountId, input.UserId)
+	if err != nil {
+		return 0, err
+	}
+
+	startAt := input.StartAt
+	if startAt.IsZero() {
+		startAt = time.Now()
+	}
+
+	id, err := s.subscriptionRepo.CreateSubscription(ctx, entity.Subscription{
+		AccountId:    input.AccountId,
+		ProductId:    input.ProductId,
+		Amount:       input.Amount,
+		Period:       input.Period,
+		NextChargeAt: startAt,
+	})
+	if err != nil {
+		log.Errorf("SubscriptionService.CreateSubscription - s.subscriptionRepo.CreateSubscription: %v", err)
+		return 0, ErrCannotCreateSubscription
+	}
+
+	return id, nil
+}
+
+func (s *SubscriptionService) GetSubscriptionById(ctx context.Context, id int) (entity.Subscription, error) {
+	subscription, err := s.subscriptionRepo.GetSubscriptionById(ctx, id)
+	if err != nil {
+		if errors.Is(err, repoerrs.ErrNotFound) {
+			return entity.Subscription{}, ErrSubscriptionNotFound
+		}
+		return entity.Subscription{}, err
+	}
+
+	return subscription, nil
+}
+
+func (s *SubscriptionService) GetAccountSubscriptions(ctx context.Context, accountId int) ([]entity.Subscription, error) {
+	return s.subscriptionRepo.GetSubscriptionsByAccountId(ctx, accountId)
+}
+
+func (s *SubscriptionService) GetSubscriptionCharges(ctx context.Context, id int) ([]entity.SubscriptionCharge, error) {
+	return s.subscriptionRepo.GetSubscriptionCharges(ctx, id)
+}
+
+func (s *SubscriptionService) PauseSubscription(ctx context.Context, id int) error {
+	return s.changeStatus(ctx, id, entity.SubscriptionStatusPaused,
+		entity.SubscriptionStatusActive,
+		entity.SubscriptionStatusPastDue,
+	)
+}
+
+func (s *SubscriptionService) ResumeSubscription(ctx context.Context, id int) error {
+	return s.changeStatus(ctx, id, entity.SubscriptionStatusActive,
+		entity.SubscriptionStatusPaused,
+		entity.SubscriptionStatusSuspended,
+	)
+}
+
+func (s *SubscriptionService) CancelSubscription(ctx context.Context, id int) error {
+	return s.changeStatus(ctx, id, entity.SubscriptionStatusCancelled,
+		entity.SubscriptionStatusActive,
+		entity.SubscriptionStatusPastDue,
+		entity.SubscriptionStatusPaused,
+		entity.SubscriptionStatusSuspended,
+	)
+}
+
+func (s *SubscriptionService) changeStatus(ctx context.Context, id int, to string, from ...string) error {
+	err := s.subscriptionRepo.UpdateSubscriptionStatus(ctx, id, from, to)
+	if err == nil {
+		return nil
+	}
+
+	if !errors.Is(err, repoerrs.ErrNotFound) {
+		log.Errorf("SubscriptionService.changeStatus - s.subscriptionRepo.UpdateSubscriptionStatus: %v", err)
+		return err
+	}
+
+	// nothing was updated, either there is no such subscription or the transition is not allowed
+	if _, err = s.GetSubscriptionById(ctx, id); err != nil {
+		return err
+	}
+	return ErrCannotChangeSubscriptionStatus
+}
+
+// ChargeDueSubscriptions charges all the subscriptions whose period has started, it is run by the scheduler.
+// A charge is a reservation of the subscription product, so it is subject to the reservation limits and fees
+// checked at the charge time. A charge over the limits is recorded as failed and retried like the one without money
+func (s *SubscriptionService) ChargeDueSubscriptions(ctx context.Context) {
+	ids, err := s.subscriptionRepo.GetDueSubscriptionIds(ctx, subscriptionChargeBatchSize)
+	if err != nil {
+		log.Errorf("SubscriptionService.ChargeDueSubscriptions - s.subscriptionRepo.GetDueSubscriptionIds: %v", err)
+		return
+	}
+
+	for _, id := range ids {
+		if ctx.Err() != nil {
+			return
+		}
+
+		subscription, err := s.subscriptionRepo.GetSubscriptionById(ctx, id)
+		if err != nil {
+			log.Errorf("SubscriptionService.ChargeDueSubscriptions - s.subscriptionRepo.GetSubscriptionById: %v", err)
+			continue
+		}
+
+		var rejectReason string
+		limits, err := s.limitService.CheckLimits(ctx, subscription.AccountId, entity.OperationTypeReservation, subscription.Amount)
+		if err != nil {
+			if !errors.Is(err, ErrLimitExceeded) {
+				// limits cannot be checked now, try again on the next run
+				continue
+			}
+			rejectReason = err.Error()
+		}
+
+		var fee entity.Fee
+		if rejectReason == "" {
+			fee, err = s.feeService.CalculateFee(ctx, FeeCalculateInput{
+				AccountId:     subscription.AccountId,
+				OperationType: entity.OperationTypeReservation,
+				ProductId:     subscription.ProductId,
+				Amount:        subscription.Amount,
+			})
+			if err != nil {
+				continue
+			}
+		}
+
+		charge, err := s.subscriptionRepo.ChargeSubscription(ctx, id, rejectReason, fee, limits, s.spendingOrder, s.retryInterval, s.gracePeriod)
+		if err != nil {
+			// already charged by another instance
+			if errors.Is(err, repoerrs.ErrNotFound) {
+				continue
+			}
+			log.Errorf("SubscriptionService.ChargeDueSubscriptions - s.subscriptionRepo.ChargeSubscription: %v", err)
+			continue
+		}
+
+		if charge.Status == entity.SubscriptionChargeFailed {
+			log.Warnf("subscription %d charge attempt %d failed: %s", id, charge.Attempt, charge.Error)
+		}
+	}
+}
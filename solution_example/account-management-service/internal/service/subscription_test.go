@@ -0,0 +1,162 @@
+package service
+
+import (
+	"account-management-service/internal/entity"
+	"account-management-service/internal/mocks/repomocks"
+	"account-management-service/internal/repo/repoerrs"
+	"context"
+	"errors"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestSubscriptionService_PauseSubscription(t *testing.T) {
+	type args struct {
+		ctx context.Context
+		id  int
+	}
+
+	type MockBehavior func(s *repomocks.MockSubscription, args args)
+
+	pausable := []string{entity.SubscriptionStatusActive, entity.SubscriptionStatusPastDue}
+
+	testCases := []struct {
+		name         string
+		args         args
+		mockBehavior MockBehavior
+		wantErr      error
+	}{
+		{
+			name: "OK",
+			args: args{
+				ctx: context.Background(),
+				id:  1,
+			},
+			mockBehavior: func(s *repomocks.MockSubscription, args args) {
+				s.EXPECT().UpdateSubscriptionStatus(args.ctx, args.id, pausable, entity.SubscriptionStatusPaused).
+					Return(nil)
+			},
+			wantErr: nil,
+		},
+		{
+			name: "subscription not found",
+			args: args{
+				ctx: context.Background(),
+				id:  1,
+			},
+			mockBehavior: func(s *repomocks.MockSubscription, args args) {
+				s.EXPECT().UpdateSubscriptionStatus(args.ctx, args.id, pausable, entity.SubscriptionStatusPaused).
+					Return(repoerrs.ErrNotFound)
+				s.EXPECT().GetSubscriptionById(args.ctx, args.id).
+					Return(entity.Subscription{}, repoerrs.ErrNotFound)
+			},
+			wantErr: ErrSubscriptionNotFound,
+		},
+		{
+			name: "already cancelled",
+			args: args{
+				ctx: context.Background(),
+				id:  1,
+			},
+			mockBehavior: func(s *repomocks.MockSubscription, args args) {
+				s.EXPECT().UpdateSubscriptionStatus(args.ctx, args.id, pausable, entity.SubscriptionStatusPaused).
+					Return(repoerrs.ErrNotFound)
+				s.EXPECT().GetSubscriptionById(args.ctx, args.id).
+					Return(entity.Subscription{Id: args.id, Status: entity.SubscriptionStatusCancelled}, nil)
+			},
+			wantErr: ErrCannotChangeSubscriptionStatus,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// init deps
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			// init mocks
+			subscriptionRepo := repomocks.NewMockSubscription(ctrl)
+			tc.mockBehavior(subscriptionRepo, tc.args)
+
+			// init service
+			s := NewSubscriptionService(subscriptionRepo, nil, nil, nil, nil, time.Hour, 72*time.Hour)
+
+			// run test
+			err := s.PauseSubscription(tc.args.ctx, tc.args.id)
+			if tc.wantErr != nil {
+				assert.ErrorIs(t, err, tc.wantErr)
+				return
+			}
+
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestSubscriptionService_ChargeDueSubscriptions(t *testing.T) {
+	// init deps
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	subscriptionRepo := repomocks.NewMockSubscription(ctrl)
+	accountRepo := repomocks.NewMockAccount(ctrl)
+	limitRepo := repomocks.NewMockLimit(ctrl)
+	feeRepo := repomocks.NewMockFee(ctrl)
+
+	subscription := func(id int) entity.Subscription {
+		return entity.Subscription{Id: id, AccountId: 10, ProductId: 5, Amount: 100}
+	}
+	windowRules := []entity.LimitRule{
+		{Scope: entity.LimitScopeGlobal, LimitType: entity.LimitTypeMaxCount, Value: 5, WindowSeconds: 3600},
+	}
+	fee := entity.Fee{AccountId: feeAccountId, Amount: 10}
+
+	// every due subscription is charged even if some of them fail or were charged concurrently
+	subscriptionRepo.EXPECT().GetDueSubscriptionIds(ctx, subscriptionChargeBatchSize).
+		Return([]int{1, 2, 3, 4, 5}, nil)
+	accountRepo.EXPECT().GetAccountById(ctx, 10).
+		Return(entity.Account{Id: 10, Type: entity.AccountTypePersonal}, nil).AnyTimes()
+	feeRepo.EXPECT().GetFeeRulesForOperation(ctx, entity.OperationTypeReservation, 5, entity.AccountTypePersonal).
+		Return([]entity.FeeRule{{Fixed: 10}}, nil).AnyTimes()
+
+	// charged concurrently
+	subscriptionRepo.EXPECT().GetSubscriptionById(ctx, 1).Return(subscription(1), nil)
+	limitRepo.EXPECT().GetLimitRulesForAccount(ctx, 10, entity.AccountTypePersonal, entity.OperationTypeReservation).
+		Return(nil, nil)
+	subscriptionRepo.EXPECT().ChargeSubscription(ctx, 1, "", fee, []entity.LimitRule(nil), defaultSpendingOrder, time.Hour, 72*time.Hour).
+		Return(entity.SubscriptionCharge{}, repoerrs.ErrNotFound)
+
+	// failed to charge
+	subscriptionRepo.EXPECT().GetSubscriptionById(ctx, 2).Return(subscription(2), nil)
+	limitRepo.EXPECT().GetLimitRulesForAccount(ctx, 10, entity.AccountTypePersonal, entity.OperationTypeReservation).
+		Return(nil, nil)
+	subscriptionRepo.EXPECT().ChargeSubscription(ctx, 2, "", fee, []entity.LimitRule(nil), defaultSpendingOrder, time.Hour, 72*time.Hour).
+		Return(entity.SubscriptionCharge{}, errors.New("some error"))
+
+	// the window rules are checked by the repo
+	subscriptionRepo.EXPECT().GetSubscriptionById(ctx, 3).Return(subscription(3), nil)
+	limitRepo.EXPECT().GetLimitRulesForAccount(ctx, 10, entity.AccountTypePersonal, entity.OperationTypeReservation).
+		Return(windowRules, nil)
+	subscriptionRepo.EXPECT().ChargeSubscription(ctx, 3, "", fee, windowRules, defaultSpendingOrder, time.Hour, 72*time.Hour).
+		Return(entity.SubscriptionCharge{Status: entity.SubscriptionChargeSucceeded}, nil)
+
+	// the charge over the limit is recorded as failed
+	subscriptionRepo.EXPECT().GetSubscriptionById(ctx, 4).Return(subscription(4), nil)
+	limitRepo.EXPECT().GetLimitRulesForAccount(ctx, 10, entity.AccountTypePersonal, entity.OperationTypeReservation).
+		Return([]entity.LimitRule{{Scope: entity.LimitScopeGlobal, LimitType: entity.LimitTypeMaxAmount, Value: 50}}, nil)
+	subscriptionRepo.EXPECT().ChargeSubscription(ctx, 4, "limit exceeded: reservation amount must not exceed 50", entity.Fee{}, []entity.LimitRule(nil), defaultSpendingOrder, time.Hour, 72*time.Hour).
+		Return(entity.SubscriptionCharge{Status: entity.SubscriptionChargeFailed}, nil)
+
+	// limits cannot be checked, the subscription is left for the next run
+	subscriptionRepo.EXPECT().GetSubscriptionById(ctx, 5).Return(subscription(5), nil)
+	limitRepo.EXPECT().GetLimitRulesForAccount(ctx, 10, entity.AccountTypePersonal, entity.OperationTypeReservation).
+		Return(nil, errors.New("some error"))
+
+	limitService := NewLimitService(limitRepo, accountRepo)
+	feeService := NewFeeService(feeRepo, accountRepo, nil, feeAccountId)
+	s := NewSubscriptionService(subscriptionRepo, nil, limitService, feeService, nil, time.Hour, 72*time.Hour)
+	s.ChargeDueSubscriptions(ctx)
+}
@@ -0,0 +1,169 @@
+package service
+
+import (
+	"account-management-service/internal/entity"
+	"account-management-service/internal/repo"
+	"account-management-service/internal/repo/repoerrs"
+	"context"
+	"errors"
+	"fmt"
+	log "github.com/sirupsen/logrus"
+)
+
+type VerificationService struct {
+	verificationRepo repo.Verification
+	accountRepo      repo.Account
+}
+
+func NewVerificationService(verificationRepo repo.Verification, accountRepo repo.Account) *VerificationService {
+	return &VerificationService{
+		verificationRepo: verificationRepo,
+		accountRepo:      accountRepo,
+	}
+}
+
+func (s *VerificationService) SetVerificationLevel(ctx context.Context, input VerificationSetLevelInput) error {
+	if verificationLevelRank(input.Level) < 0 {
+		return ErrInvalidVerificationLevel
+	}
+
+	err := s.verificationRepo.SetVerificationLevel(ctx, entity.VerificationChange{
+		AccountId: input.AccountId,
+		NewLevel:  input.Level,
+		Reference: input.Reference,
+		ChangedBy: &input.ChangedBy,
+	})
+	if err != nil {
+		if errors.Is(err, repoerrs.ErrNotFound) {
+			return ErrAccountNotFound
+		}
+		log.Errorf("VerificationService.SetVerificationLevel - s.verificationRepo.SetVerificationLevel: %v", err)
+		return ErrCannotSetVerificationLevel
+	}
+
+	return nil
+}
+
+func (s *VerificationService) GetVerificationHistory(ctx context.Context, accountId int) ([]entity.VerificationChange, error) {
+	return s.verificationRepo.GetVerificationChanges(ctx, accountId)
+}
+
+func (s *VerificationService) SetVerificationPolicy(ctx context.Context, input VerificationSetPolicyInput) error {
+	if verificationLevelRank(input.Level) < 0 {
+		return ErrInvalidVerificationPolicy
+	}
+	if input.MaxAmount < 0 || input.MaxTotal < 0 || (input.MaxTotal > 0 && input.WindowSeconds <= 0) {
+		return ErrInvalidVerificationPolicy
+	}
+
+	err := s.verificationRepo.SetVerificationPolicy(ctx, entity.VerificationPolicy{
+		Level:         input.Level,
+		OperationType: input.OperationType,
+		MaxAmount:     input.MaxAmount,
+		MaxTotal:      input.MaxTotal,
+		WindowSeconds: input.WindowSeconds,
+	})
+	if err != nil {
+		log.Errorf("VerificationService.SetVerificationPolicy - s.verificationRepo.SetVerificationPolicy: %v", err)
+		return ErrCannotSetVerificationPolicy
+	}
+
+	return nil
+}
+
+func (s *VerificationService) DeleteVerificationPolicy(ctx context.Context, level, operationType string) error {
+	err := s.verificationRepo.DeleteVerificationPolicy(ctx, level, operationType)
+	if err != nil {
+		if errors.Is(err, repoerrs.ErrNotFound) {
+			return ErrVerificationPolicyNotFound
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (s *VerificationService) GetAllVerificationPolicies(ctx context.Context) ([]entity.VerificationPolicy, error) {
+	return s.verificationRepo.GetAllVerificationPolicies(ctx)
+}
+
+// CheckVerification checks an outgoing operation against the policy of the account verification level.
+// If the level does not allow the operation, ErrVerificationRequired is returned wrapped with
+// the lowest level that would allow it. Operations without any policy are not restricted.
+// The policies limiting the total within a window are returned to be checked by the repo in the transaction
+// moving the money, once the account is locked, otherwise concurrent operations could all fit into the window
+func (s *VerificationService) CheckVerification(ctx context.Context, accountId int, operationType string, amount int) (entity.VerificationCheck, error) {
+	account, err := s.accountRepo.GetAccountById(ctx, accountId)
+	if err != nil {
+		if errors.Is(err, repoerrs.ErrNotFound) {
+			return entity.VerificationCheck{}, ErrAccountNotFound
+		}
+		log.Errorf("VerificationService.CheckVerification - s.accountRepo.GetAccountById: %v", err)
+		return entity.VerificationCheck{}, ErrCannotCheckVerification
+	}
+
+	policies, err := s.verificationRepo.GetVerificationPoliciesForOperation(ctx, operationType)
+	if err != nil {
+		log.Errorf("VerificationService.CheckVerification - s.verificationRepo.GetVerificationPoliciesForOperation: %v", err)
+		return entity.VerificationCheck{}, ErrCannotCheckVerification
+	}
+	if len(policies) == 0 {
+		return entity.VerificationCheck{}, nil
+	}
+
+	byLevel := make(map[string]entity.VerificationPolicy, len(policies))
+	for _, policy := range policies {
+		byLevel[policy.Level] = policy
+	}
+
+	rank := verificationLevelRank(account.VerificationLevel)
+	if rank < 0 {
+		rank = 0
+	}
+
+	check := entity.VerificationCheck{Level: entity.VerificationLevels[rank]}
+	for _, level := range entity.VerificationLevels[rank:] {
+		if policy, ok := byLevel[level]; ok {
+			check.Policies = append(check.Policies, policy)
+		}
+	}
+
+	// the totals only grow, so the operation not allowed with nothing spent is not allowed at all
+	level, ok := check.RequiredLevel(amount, nil)
+	if !ok || level != check.Level {
+		return entity.VerificationCheck{}, verificationRequired(level, operationType, amount)
+	}
+	if check.Policies[0].MaxTotal == 0 {
+		return entity.VerificationCheck{}, nil
+	}
+
+	return check, nil
+}
+
+// verificationRequired returns ErrVerificationRequired wrapped with the lowest level allowing the operation,
+// or ErrLimitExceeded if no level allows it
+func verificationRequired(level, operationType string, amount int) error {
+	if level == "" {
+		return fmt.Errorf("%w: %s of %d is not allowed at any verification level", ErrLimitExceeded, operationType, amount)
+	}
+	return fmt.Errorf("%w: %s of %d requires %s verification", ErrVerificationRequired, operationType, amount, level)
+}
+
+// verificationRepoError keeps the required level of the verification error returned by the repo
+func verificationRepoError(err error) error {
+	var verificationErr *repoerrs.VerificationRequiredError
+	if errors.As(err, &verificationErr) {
+		return verificationRequired(verificationErr.Level, verificationErr.OperationType, verificationErr.Amount)
+	}
+	return ErrVerificationRequired
+}
+
+// verificationLevelRank returns the position of the level in entity.VerificationLevels or -1 if it is unknown
+func verificationLevelRank(level string) int {
+	for i, l := range entity.VerificationLevels {
+		if l == level {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,258 @@
+package service
+
+import (
+	"account-management-service/internal/entity"
+	"account-management-service/internal/mocks/repomocks"
+	"account-management-service/internal/repo/repoerrs"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestVerificationService_CheckVerification(t *testing.T) {
+	type args struct {
+		ctx           context.Context
+		accountId     int
+		operationType string
+		amount        int
+	}
+
+	type MockBehavior func(v *repomocks.MockVerification, a *repomocks.MockAccount, args args)
+
+	policies := []entity.VerificationPolicy{
+		{Level: entity.VerificationLevelNone, MaxAmount: 100, MaxTotal: 300, WindowSeconds: 86400},
+		{Level: entity.VerificationLevelBasic, MaxAmount: 1000, MaxTotal: 3000, WindowSeconds: 86400},
+		{Level: entity.VerificationLevelFull},
+	}
+
+	testCases := []struct {
+		name         string
+		args         args
+		mockBehavior MockBehavior
+		want         entity.VerificationCheck
+		wantErr      error
+		wantMessage  string
+	}{
+		{
+			name: "OK: no policies",
+			args: args{
+				ctx:           context.Background(),
+				accountId:     1,
+				operationType: entity.OperationTypeWithdraw,
+				amount:        100000,
+			},
+			mockBehavior: func(v *repomocks.MockVerification, a *repomocks.MockAccount, args args) {
+				a.EXPECT().GetAccountById(args.ctx, args.accountId).
+					Return(entity.Account{Id: 1, VerificationLevel: entity.VerificationLevelNone}, nil)
+				v.EXPECT().GetVerificationPoliciesForOperation(args.ctx, args.operationType).
+					Return(nil, nil)
+			},
+		},
+		{
+			name: "OK: window totals are checked by the repo",
+			args: args{
+				ctx:           context.Background(),
+				accountId:     1,
+				operationType: entity.OperationTypeWithdraw,
+				amount:        100,
+			},
+			mockBehavior: func(v *repomocks.MockVerification, a *repomocks.MockAccount, args args) {
+				a.EXPECT().GetAccountById(args.ctx, args.accountId).
+					Return(entity.Account{Id: 1, VerificationLevel: entity.VerificationLevelNone}, nil)
+				v.EXPECT().GetVerificationPoliciesForOperation(args.ctx, args.operationType).
+					Return(policies, nil)
+			},
+			want: entity.VerificationCheck{Level: entity.VerificationLevelNone, Policies: policies},
+		},
+		{
+			name: "OK: within level limits",
+			args: args{
+				ctx:           context.Background(),
+				accountId:     1,
+				operationType: entity.OperationTypeWithdraw,
+				amount:        100,
+			},
+			mockBehavior: func(v *repomocks.MockVerification, a *repomocks.MockAccount, args args) {
+				a.EXPECT().GetAccountById(args.ctx, args.accountId).
+					Return(entity.Account{Id: 1, VerificationLevel: entity.VerificationLevelBasic}, nil)
+				v.EXPECT().GetVerificationPoliciesForOperation(args.ctx, args.operationType).
+					Return([]entity.VerificationPolicy{
+						{Level: entity.VerificationLevelNone, MaxAmount: 50},
+						{Level: entity.VerificationLevelBasic, MaxAmount: 500},
+					}, nil)
+			},
+			want: entity.VerificationCheck{},
+		},
+		{
+			name: "amount requires basic verification",
+			args: args{
+				ctx:           context.Background(),
+				accountId:     1,
+				operationType: entity.OperationTypeWithdraw,
+				amount:        500,
+			},
+			mockBehavior: func(v *repomocks.MockVerification, a *repomocks.MockAccount, args args) {
+				a.EXPECT().GetAccountById(args.ctx, args.accountId).
+					Return(entity.Account{Id: 1, VerificationLevel: entity.VerificationLevelNone}, nil)
+				v.EXPECT().GetVerificationPoliciesForOperation(args.ctx, args.operationType).
+					Return(policies, nil)
+			},
+			wantErr:     ErrVerificationRequired,
+			wantMessage: "verification required: withdraw of 500 requires basic verification",
+		},
+		{
+			name: "higher level windows are checked by the repo",
+			args: args{
+				ctx:           context.Background(),
+				accountId:     1,
+				operationType: entity.OperationTypeTransferFrom,
+				amount:        1000,
+			},
+			mockBehavior: func(v *repomocks.MockVerification, a *repomocks.MockAccount, args args) {
+				a.EXPECT().GetAccountById(args.ctx, args.accountId).
+					Return(entity.Account{Id: 1, VerificationLevel: entity.VerificationLevelBasic}, nil)
+				v.EXPECT().GetVerificationPoliciesForOperation(args.ctx, args.operationType).
+					Return(policies, nil)
+			},
+			want: entity.VerificationCheck{Level: entity.VerificationLevelBasic, Policies: policies[1:]},
+		},
+		{
+			name: "operation is not allowed at the level",
+			args: args{
+				ctx:           context.Background(),
+				accountId:     1,
+				operationType: entity.OperationTypeWithdraw,
+				amount:        10,
+			},
+			mockBehavior: func(v *repomocks.MockVerification, a *repomocks.MockAccount, args args) {
+				a.EXPECT().GetAccountById(args.ctx, args.accountId).
+					Return(entity.Account{Id: 1, VerificationLevel: entity.VerificationLevelNone}, nil)
+				v.EXPECT().GetVerificationPoliciesForOperation(args.ctx, args.operationType).
+					Return([]entity.VerificationPolicy{{Level: entity.VerificationLevelFull}}, nil)
+			},
+			wantErr:     ErrVerificationRequired,
+			wantMessage: "verification required: withdraw of 10 requires full verification",
+		},
+		{
+			name: "no level allows the amount",
+			args: args{
+				ctx:           context.Background(),
+				accountId:     1,
+				operationType: entity.OperationTypeWithdraw,
+				amount:        500,
+			},
+			mockBehavior: func(v *repomocks.MockVerification, a *repomocks.MockAccount, args args) {
+				a.EXPECT().GetAccountById(args.ctx, args.accountId).
+					Return(entity.Account{Id: 1, VerificationLevel: entity.VerificationLevelFull}, nil)
+				v.EXPECT().GetVerificationPoliciesForOperation(args.ctx, args.operationType).
+					Return([]entity.VerificationPolicy{{Level: entity.VerificationLevelFull, MaxAmount: 100}}, nil)
+			},
+			wantErr: ErrLimitExceeded,
+		},
+		{
+			name: "account not found",
+			args: args{
+				ctx:           context.Background(),
+				accountId:     1,
+				operationType: entity.OperationTypeWithdraw,
+				amount:        100,
+			},
+			mockBehavior: func(v *repomocks.MockVerification, a *repomocks.MockAccount, args args) {
+				a.EXPECT().GetAccountById(args.ctx, args.accountId).
+					Return(entity.Account{}, repoerrs.ErrNotFound)
+			},
+			wantErr: ErrAccountNotFound,
+		},
+		{
+			name: "policies error",
+			args: args{
+				ctx:           context.Background(),
+				accountId:     1,
+				operationType: entity.OperationTypeWithdraw,
+				amount:        100,
+			},
+			mockBehavior: func(v *repomocks.MockVerification, a *repomocks.MockAccount, args args) {
+				a.EXPECT().GetAccountById(args.ctx, args.accountId).
+					Return(entity.Account{Id: 1, VerificationLevel: entity.VerificationLevelNone}, nil)
+				v.EXPECT().GetVerificationPoliciesForOperation(args.ctx, args.operationType).
+					Return(nil, errors.New("some error"))
+			},
+			wantErr: ErrCannotCheckVerification,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// init deps
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			// init mocks
+			verificationRepo := repomocks.NewMockVerification(ctrl)
+			accountRepo := repomocks.NewMockAccount(ctrl)
+			tc.mockBehavior(verificationRepo, accountRepo, tc.args)
+
+			// init service
+			s := NewVerificationService(verificationRepo, accountRepo)
+
+			// run test
+			got, err := s.CheckVerification(tc.args.ctx, tc.args.accountId, tc.args.operationType, tc.args.amount)
+			if tc.wantErr != nil {
+				assert.ErrorIs(t, err, tc.wantErr)
+				if tc.wantMessage != "" {
+					assert.EqualError(t, err, tc.wantMessage)
+				}
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestVerificationRepoError(t *testing.T) {
+	testCases := []struct {
+		name        string
+		err         error
+		wantErr     error
+		wantMessage string
+	}{
+		{
+			name: "level required",
+			err: fmt.Errorf("AccountRepo.Withdraw - checkVerification: %w", &repoerrs.VerificationRequiredError{
+				Level:         entity.VerificationLevelFull,
+				OperationType: entity.OperationTypeWithdraw,
+				Amount:        1000,
+			}),
+			wantErr:     ErrVerificationRequired,
+			wantMessage: "verification required: withdraw of 1000 requires full verification",
+		},
+		{
+			name: "no level allows",
+			err: &repoerrs.VerificationRequiredError{
+				OperationType: entity.OperationTypeWithdraw,
+				Amount:        1000,
+			},
+			wantErr:     ErrLimitExceeded,
+			wantMessage: "limit exceeded: withdraw of 1000 is not allowed at any verification level",
+		},
+		{
+			name:        "no level",
+			err:         repoerrs.ErrVerificationRequired,
+			wantErr:     ErrVerificationRequired,
+			wantMessage: "verification required",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := verificationRepoError(tc.err)
+			assert.ErrorIs(t, err, tc.wantErr)
+			assert.EqualError(t, err, tc.wantMessage)
+		})
+	}
+}
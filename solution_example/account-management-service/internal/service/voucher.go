@@ -0,0 +1,139 @@
+package service
+
+import (
+	"account-management-service/internal/entity"
+	"account-management-service/internal/repo"
+	"account-management-service/internal/repo/repoerrs"
+	"context"
+	"crypto/rand"
+	"errors"
+	log "github.com/sirupsen/logrus"
+	"math/big"
+	"strings"
+	"time"
+)
+
+const (
+	// voucherCodeAlphabet has no look-alike characters, so that codes can be typed in by hand
+	voucherCodeAlphabet    = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+	voucherCodeLength      = 12
+	maxVouchersPerCampaign = 10000
+)
+
+type VoucherService struct {
+	voucherRepo repo.Voucher
+	accountRepo repo.Account
+}
+
+func NewVoucherService(voucherRepo repo.Voucher, accountRepo repo.Account) *VoucherService {
+	return &VoucherService{
+		voucherRepo: voucherRepo,
+		accountRepo: accountRepo,
+	}
+}
+
+// CreateVoucherCampaign generates the campaign codes and returns them, codes are not shown anywhere else
+func (s *VoucherService) CreateVoucherCampaign(ctx context.Context, input VoucherCreateCampaignInput) (int, []string, error) {
+	wallet := input.Wallet
+	if wallet == "" {
+		wallet = entity.WalletTypeMain
+	}
+	maxUses := input.MaxUses
+	if maxUses == 0 {
+		maxUses = 1
+	}
+
+	if input.Amount <= 0 || input.Count <= 0 || input.Count > maxVouchersPerCampaign || maxUses < 0 || !input.ExpiresAt.After(time.Now()) {
+		return 0, nil, ErrInvalidVoucherCampaign
+	}
+
+	codes, err := generateVoucherCodes(input.Count)
+	if err != nil {
+		log.Errorf("VoucherService.CreateVoucherCampaign - generateVoucherCodes: %v", err)
+		return 0, nil, ErrCannotCreateCampaign
+	}
+
+	id, err := s.voucherRepo.CreateVoucherCampaign(ctx, entity.VoucherCampaign{
+		Name:      input.Name,
+		Amount:    input.Amount,
+		Wallet:    wallet,
+		MaxUses:   maxUses,
+		ExpiresAt: input.ExpiresAt,
+	}, codes)
+	if err != nil {
+		log.Errorf("VoucherService.CreateVoucherCampaign - s.voucherRepo.CreateVoucherCampaign: %v", err)
+		return 0, nil, ErrCannotCreateCampaign
+	}
+
+	return id, codes, nil
+}
+
+func (s *VoucherService) RedeemVoucher(ctx context.Context, input VoucherRedeemInput) (entity.VoucherRedemption, error) {
+	_, err := s.accountRepo.GetAccountById(ctx, input.AccountId)
+	if err != nil {
+		if errors.Is(err, repoerrs.ErrNotFound) {
+			return entity.VoucherRedemption{}, ErrAccountNotFound
+		}
+		return entity.VoucherRedemption{}, ErrCannotRedeemVoucher
+	}
+
+	redemption, err := s.voucherRepo.RedeemVoucher(ctx, strings.ToUpper(strings.TrimSpace(input.Code)), input.AccountId)
+	if err != nil {
+		switch {
+		case errors.Is(err, repoerrs.ErrNotFound):
+			return entity.VoucherRedemption{}, ErrVoucherNotFound
+		case errors.Is(err, repoerrs.ErrExpired):
+			return entity.VoucherRedemption{}, ErrVoucherExpired
+		case errors.Is(err, repoerrs.ErrWrongStatus):
+			return entity.VoucherRedemption{}, ErrVoucherUsedUp
+		case errors.Is(err, repoerrs.ErrAlreadyExists):
+			return entity.VoucherRedemption{}, ErrVoucherAlreadyRedeemed
+		case errors.Is(err, repoerrs.ErrAccountFrozen):
+			return entity.VoucherRedemption{}, ErrAccountFrozen
+		case errors.Is(err, repoerrs.ErrAccountClosed):
+			return entity.VoucherRedemption{}, ErrAccountClosed
+		}
+		log.Errorf("VoucherService.RedeemVoucher - s.voucherRepo.RedeemVoucher: %v", err)
+		return entity.VoucherRedemption{}, ErrCannotRedeemVoucher
+	}
+
+	return redemption, nil
+}
+
+func (s *VoucherService) GetVoucherCampaignReport(ctx context.Context, campaignId int) (entity.VoucherCampaignReport, error) {
+	report, err := s.voucherRepo.GetVoucherCampaignReport(ctx, campaignId)
+	if err != nil {
+		if errors.Is(err, repoerrs.ErrNotFound) {
+			return entity.VoucherCampaignReport{}, ErrCampaignNotFound
+		}
+		return entity.VoucherCampaignReport{}, err
+	}
+
+	return report, nil
+}
+
+// generateVoucherCodes returns count distinct random codes
+func generateVoucherCodes(count int) ([]string, error) {
+	seen := make(map[string]bool, count)
+	codes := make([]string, 0, count)
+	alphabetSize := big.NewInt(int64(len(voucherCodeAlphabet)))
+
+	for len(codes) < count {
+		var b strings.Builder
+		for i := 0; i < voucherCodeLength; i++ {
+			n, err := rand.Int(rand.Reader, alphabetSize)
+			if err != nil {
+				return nil, err
+			}
+			b.WriteByte(voucherCodeAlphabet[n.Int64()])
+		}
+
+		code := b.String()
+		if !seen[code] {
+			seen[code] = true
+			codes = append(codes, code)
+		}
+	}
+
+	return codes, nil
+}
@@ -0,0 +1,212 @@
+package service
+
+import (
+	"account-management-service/internal/entity"
+	"account-management-service/internal/mocks/repomocks"
+	"account-management-service/internal/repo/repoerrs"
+	"context"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestVoucherService_CreateVoucherCampaign(t *testing.T) {
+	type args struct {
+		ctx   context.Context
+		input VoucherCreateCampaignInput
+	}
+
+	type MockBehavior func(v *repomocks.MockVoucher, args args)
+
+	expiresAt := time.Now().Add(24 * time.Hour)
+
+	testCases := []struct {
+		name         string
+		args         args
+		mockBehavior MockBehavior
+		wantCodes    int
+		wantErr      error
+	}{
+		{
+			name: "OK",
+			args: args{
+				ctx: context.Background(),
+				input: VoucherCreateCampaignInput{
+					Name:      "black friday",
+					Amount:    500,
+					Count:     3,
+					ExpiresAt: expiresAt,
+				},
+			},
+			mockBehavior: func(v *repomocks.MockVoucher, args args) {
+				v.EXPECT().CreateVoucherCampaign(args.ctx, entity.VoucherCampaign{
+					Name:      "black friday",
+					Amount:    500,
+					Wallet:    entity.WalletTypeMain,
+					MaxUses:   1,
+					ExpiresAt: expiresAt,
+				}, gomock.Len(3)).
+					Return(1, nil)
+			},
+			wantCodes: 3,
+			wantErr:   nil,
+		},
+		{
+			name: "already expired",
+			args: args{
+				ctx: context.Background(),
+				input: VoucherCreateCampaignInput{
+					Name:      "black friday",
+					Amount:    500,
+					Count:     3,
+					ExpiresAt: time.Now().Add(-time.Hour),
+				},
+			},
+			mockBehavior: func(v *repomocks.MockVoucher, args args) {},
+			wantErr:      ErrInvalidVoucherCampaign,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// init deps
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			// init mocks
+			voucherRepo := repomocks.NewMockVoucher(ctrl)
+			accountRepo := repomocks.NewMockAccount(ctrl)
+			tc.mockBehavior(voucherRepo, tc.args)
+
+			// init service
+			s := NewVoucherService(voucherRepo, accountRepo)
+
+			// run test
+			_, codes, err := s.CreateVoucherCampaign(tc.args.ctx, tc.args.input)
+			if tc.wantErr != nil {
+				assert.ErrorIs(t, err, tc.wantErr)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Len(t, codes, tc.wantCodes)
+			for _, code := range codes {
+				assert.Len(t, code, voucherCodeLength)
+			}
+		})
+	}
+}
+
+func TestVoucherService_RedeemVoucher(t *testing.T) {
+	type args struct {
+		ctx   context.Context
+		input VoucherRedeemInput
+	}
+
+	type MockBehavior func(v *repomocks.MockVoucher, a *repomocks.MockAccount, args args)
+
+	testCases := []struct {
+		name         string
+		args         args
+		mockBehavior MockBehavior
+		want         entity.VoucherRedemption
+		wantErr      error
+	}{
+		{
+			name: "OK",
+			args: args{
+				ctx:   context.Background(),
+				input: VoucherRedeemInput{Code: " abcd2345efgh ", AccountId: 1},
+			},
+			mockBehavior: func(v *repomocks.MockVoucher, a *repomocks.MockAccount, args args) {
+				a.EXPECT().GetAccountById(args.ctx, args.input.AccountId).
+					Return(entity.Account{Id: 1}, nil)
+				v.EXPECT().RedeemVoucher(args.ctx, "ABCD2345EFGH", args.input.AccountId).
+					Return(entity.VoucherRedemption{Id: 1, VoucherId: 2, AccountId: 1, Amount: 500}, nil)
+			},
+			want:    entity.VoucherRedemption{Id: 1, VoucherId: 2, AccountId: 1, Amount: 500},
+			wantErr: nil,
+		},
+		{
+			name: "account not found",
+			args: args{
+				ctx:   context.Background(),
+				input: VoucherRedeemInput{Code: "ABCD2345EFGH", AccountId: 1},
+			},
+			mockBehavior: func(v *repomocks.MockVoucher, a *repomocks.MockAccount, args args) {
+				a.EXPECT().GetAccountById(args.ctx, args.input.AccountId).
+					Return(entity.Account{}, repoerrs.ErrNotFound)
+			},
+			wantErr: ErrAccountNotFound,
+		},
+		{
+			name: "voucher not found",
+			args: args{
+				ctx:   context.Background(),
+				input: VoucherRedeemInput{Code: "ABCD2345EFGH", AccountId: 1},
+			},
+			mockBehavior: func(v *repomocks.MockVoucher, a *repomocks.MockAccount, args args) {
+				a.EXPECT().GetAccountById(args.ctx, args.input.AccountId).
+					Return(entity.Account{Id: 1}, nil)
+				v.EXPECT().RedeemVoucher(args.ctx, args.input.Code, args.input.AccountId).
+					Return(entity.VoucherRedemption{}, repoerrs.ErrNotFound)
+			},
+			wantErr: ErrVoucherNotFound,
+		},
+		{
+			name: "used up",
+			args: args{
+				ctx:   context.Background(),
+				input: VoucherRedeemInput{Code: "ABCD2345EFGH", AccountId: 1},
+			},
+			mockBehavior: func(v *repomocks.MockVoucher, a *repomocks.MockAccount, args args) {
+				a.EXPECT().GetAccountById(args.ctx, args.input.AccountId).
+					Return(entity.Account{Id: 1}, nil)
+				v.EXPECT().RedeemVoucher(args.ctx, args.input.Code, args.input.AccountId).
+					Return(entity.VoucherRedemption{}, repoerrs.ErrWrongStatus)
+			},
+			wantErr: ErrVoucherUsedUp,
+		},
+		{
+			name: "already redeemed by the account",
+			args: args{
+				ctx:   context.Background(),
+				input: VoucherRedeemInput{Code: "ABCD2345EFGH", AccountId: 1},
+			},
+			mockBehavior: func(v *repomocks.MockVoucher, a *repomocks.MockAccount, args args) {
+				a.EXPECT().GetAccountById(args.ctx, args.input.AccountId).
+					Return(entity.Account{Id: 1}, nil)
+				v.EXPECT().RedeemVoucher(args.ctx, args.input.Code, args.input.AccountId).
+					Return(entity.VoucherRedemption{}, repoerrs.ErrAlreadyExists)
+			},
+			wantErr: ErrVoucherAlreadyRedeemed,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// init deps
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			// init mocks
+			voucherRepo := repomocks.NewMockVoucher(ctrl)
+			accountRepo := repomocks.NewMockAccount(ctrl)
+			tc.mockBehavior(voucherRepo, accountRepo, tc.args)
+
+			// init service
+			s := NewVoucherService(voucherRepo, accountRepo)
+
+			// run test
+			got, err := s.RedeemVoucher(tc.args.ctx, tc.args.input)
+			if tc.wantErr != nil {
+				assert.ErrorIs(t, err, tc.wantErr)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
@@ -0,0 +1,196 @@
+package email
+
+import (
+	"account-management-service/internal/entity"
+	"account-management-service/internal/webapi"
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// dialTimeout limits the connection to the server if the context has no deadline
+const dialTimeout = 30 * time.Second
+
+// base64LineLength is the length of the lines of the encoded attachment, RFC 2045 allows at most 76
+const base64LineLength = 76
+
+// EmailWebAPI delivers the reports by email through the SMTP server. STARTTLS is used if the server supports it,
+// the credentials are sent only over TLS or to localhost
+type EmailWebAPI struct {
+	host     string
+	port     int
+	username string
+	password string
+	from     string
+	now      func() time.Time
+}
+
+func New(host string, port int, username, password, from string) *EmailWebAPI {
+	return &EmailWebAPI{
+		host:     host,
+		port:     port,
+		username: username,
+		password: password,
+		from:     from,
+		now:      time.Now,
+	}
+}
+
+func (e *EmailWebAPI) DeliverReport(ctx context.Context, delivery entity.ReportDelivery) error {
+	if len(delivery.Recipients) == 0 {
+		return errors.New("email: no recipients")
+	}
+
+	msg, err := e.message(delivery)
+	if err != nil {
+		return fmt.Errorf("email: %w", err)
+	}
+
+	if err = e.send(ctx, delivery.Recipients, msg); err != nil {
+		return fmt.Errorf("email: %w", err)
+	}
+
+	return nil
+}
+
+func (e *EmailWebAPI) send(ctx context.Context, recipients []string, msg []byte) error {
+	dialer := net.Dialer{Timeout: dialTimeout}
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(e.host, strconv.Itoa(e.port)))
+	if err != nil {
+		return err
+	}
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(dialTimeout)
+	}
+	if err = conn.SetDeadline(deadline); err != nil {
+		_ = conn.Close()
+		return err
+	}
+
+	c, err := smtp.NewClient(conn, e.host)
+	if err != nil {
+		_ = conn.Close()
+		return err
+	}
+	defer func() { _ = c.Close() }()
+
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		if err = c.StartTLS(&tls.Config{ServerName: e.host}); err != nil {
+			return err
+		}
+	}
+	if e.username != "" {
+		if err = c.Auth(smtp.PlainAuth("", e.username, e.password, e.host)); err != nil {
+			return err
+		}
+	}
+
+	if err = c.Mail(e.from); err != nil {
+		return err
+	}
+	for _, recipient := range recipients {
+		if err = c.Rcpt(recipient); err != nil {
+			return err
+		}
+	}
+
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err = w.Write(msg); err != nil {
+		return err
+	}
+	if err = w.Close(); err != nil {
+		return err
+	}
+
+	return c.Quit()
+}
+
+// message makes the multipart message of the text with the link and the attached report
+func (e *EmailWebAPI) message(delivery entity.ReportDelivery) ([]byte, error) {
+	// the period is shown with the inclusive end
+	period := delivery.From.Format("2006-01-02")
+	if last := delivery.To.AddDate(0, 0, -1); last.After(delivery.From) {
+		period += " - " + last.Format("2006-01-02")
+	}
+
+	var b bytes.Buffer
+	mw := multipart.NewWriter(&b)
+
+	headers := []struct{ name, value string }{
+		{"From", e.from},
+		{"To", strings.Join(delivery.Recipients, ", ")},
+		{"Subject", mime.QEncoding.Encode("utf-8", fmt.Sprintf("Report %s for %s", delivery.Schedule, period))},
+		{"Date", e.now().Format(time.RFC1123Z)},
+		{"MIME-Version", "1.0"},
+		{"Content-Type", mime.FormatMediaType("multipart/mixed", map[string]string{"boundary": mw.Boundary()})},
+	}
+	for _, h := range headers {
+		b.WriteString(h.name + ": " + h.value + "\r\n")
+	}
+	b.WriteString("\r\n")
+
+	text := fmt.Sprintf("The report %s for %s is available at the link:\r\n%s\r\n", delivery.Schedule, period, delivery.Link)
+	if delivery.Data != nil {
+		text += "\r\nThe report file is attached.\r\n"
+	}
+
+	part, err := mw.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {"text/plain; charset=utf-8"},
+		"Content-Transfer-Encoding": {"quoted-printable"},
+	})
+	if err != nil {
+		return nil, err
+	}
+	qp := quotedprintable.NewWriter(part)
+	if _, err = io.WriteString(qp, text); err != nil {
+		return nil, err
+	}
+	if err = qp.Close(); err != nil {
+		return nil, err
+	}
+
+	if delivery.Data != nil {
+		part, err = mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {mime.FormatMediaType(webapi.ContentType(delivery.Filename), map[string]string{"name": delivery.Filename})},
+			"Content-Disposition":       {mime.FormatMediaType("attachment", map[string]string{"filename": delivery.Filename})},
+			"Content-Transfer-Encoding": {"base64"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		encoded := base64.StdEncoding.EncodeToString(delivery.Data)
+		for len(encoded) > 0 {
+			n := base64LineLength
+			if n > len(encoded) {
+				n = len(encoded)
+			}
+			if _, err = io.WriteString(part, encoded[:n]+"\r\n"); err != nil {
+				return nil, err
+			}
+			encoded = encoded[n:]
+		}
+	}
+
+	if err = mw.Close(); err != nil {
+		return nil, err
+	}
+
+	return b.Bytes(), nil
+}
@@ -0,0 +1,177 @@
+package email
+
+import (
+	"account-management-service/internal/entity"
+	"bufio"
+	"context"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeSMTPServer accepts the single message without authentication, like the local mail catchers do
+type fakeSMTPServer struct {
+	listener   net.Listener
+	from       string
+	recipients []string
+	data       string
+	done       chan struct{}
+	// rejectRcpt makes the server reject the recipients
+	rejectRcpt bool
+}
+
+func newFakeSMTPServer(t *testing.T, rejectRcpt bool) *fakeSMTPServer {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := &fakeSMTPServer{listener: listener, done: make(chan struct{}), rejectRcpt: rejectRcpt}
+	go s.serve()
+	return s
+}
+
+func (s *fakeSMTPServer) port() int {
+	return s.listener.Addr().(*net.TCPAddr).Port
+}
+
+func (s *fakeSMTPServer) serve() {
+	defer close(s.done)
+
+	conn, err := s.listener.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	r := bufio.NewReader(conn)
+	reply := func(line string) { _, _ = fmt.Fprint(conn, line+"\r\n") }
+
+	reply("220 localhost ESMTP")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		cmd := strings.TrimSpace(line)
+
+		switch {
+		case strings.HasPrefix(cmd, "EHLO"):
+			reply("250-localhost")
+			reply("250 8BITMIME")
+		case strings.HasPrefix(cmd, "MAIL FROM:"):
+			s.from = address(cmd)
+			reply("250 OK")
+		case strings.HasPrefix(cmd, "RCPT TO:"):
+			if s.rejectRcpt {
+				reply("550 no such user")
+				continue
+			}
+			s.recipients = append(s.recipients, address(cmd))
+			reply("250 OK")
+		case cmd == "DATA":
+			reply("354 end data with <CR><LF>.<CR><LF>")
+			var data strings.Builder
+			for {
+				line, err = r.ReadString('\n')
+				if err != nil || line == ".\r\n" {
+					break
+				}
+				data.WriteString(strings.TrimPrefix(line, "."))
+			}
+			s.data = data.String()
+			reply("250 OK")
+		case cmd == "QUIT":
+			reply("221 bye")
+			return
+		default:
+			reply("250 OK")
+		}
+	}
+}
+
+// address returns the address of the MAIL or RCPT command, the parameters after it are ignored
+func address(cmd string) string {
+	_, rest, _ := strings.Cut(cmd, "<")
+	addr, _, _ := strings.Cut(rest, ">")
+	return addr
+}
+
+func TestEmailWebAPI_DeliverReport(t *testing.T) {
+	server := newFakeSMTPServer(t, false)
+	defer server.listener.Close()
+
+	e := New("127.0.0.1", server.port(), "", "", "reports@example.com")
+	e.now = func() time.Time { return time.Date(2022, time.November, 1, 6, 0, 0, 0, time.UTC) }
+
+	err := e.DeliverReport(context.Background(), entity.ReportDelivery{
+		Schedule:   "monthly_revenue",
+		From:       time.Date(2022, time.October, 1, 0, 0, 0, 0, time.UTC),
+		To:         time.Date(2022, time.November, 1, 0, 0, 0, 0, time.UTC),
+		Filename:   "report_10_2022_v2.csv",
+		Link:       "http://localhost:8080/reports/download/1",
+		Data:       []byte("product,orders\ntotal,1\n"),
+		Recipients: []string{"accounting@example.com", "cfo@example.com"},
+	})
+	assert.NoError(t, err)
+	<-server.done
+
+	assert.Equal(t, "reports@example.com", server.from)
+	assert.Equal(t, []string{"accounting@example.com", "cfo@example.com"}, server.recipients)
+
+	msg, err := mail.ReadMessage(strings.NewReader(server.data))
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "Report monthly_revenue for 2022-10-01 - 2022-10-31", msg.Header.Get("Subject"))
+	assert.Equal(t, "accounting@example.com, cfo@example.com", msg.Header.Get("To"))
+
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	assert.NoError(t, err)
+	assert.Equal(t, "multipart/mixed", mediaType)
+
+	mr := multipart.NewReader(msg.Body, params["boundary"])
+
+	text, err := mr.NextPart()
+	if !assert.NoError(t, err) {
+		return
+	}
+	body, _ := io.ReadAll(text)
+	assert.Contains(t, string(body), "http://localhost:8080/reports/download/1")
+
+	attachment, err := mr.NextPart()
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "report_10_2022_v2.csv", attachment.FileName())
+	assert.Equal(t, `text/csv; name=report_10_2022_v2.csv`, attachment.Header.Get("Content-Type"))
+	// the part reader decodes quoted-printable only, the attachment is base64
+	encoded, _ := io.ReadAll(attachment)
+	assert.Equal(t, "cHJvZHVjdCxvcmRlcnMKdG90YWwsMQo=\r\n", string(encoded))
+
+	_, err = mr.NextPart()
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestEmailWebAPI_DeliverReportRejected(t *testing.T) {
+	server := newFakeSMTPServer(t, true)
+	defer server.listener.Close()
+
+	e := New("127.0.0.1", server.port(), "", "", "reports@example.com")
+
+	err := e.DeliverReport(context.Background(), entity.ReportDelivery{
+		Schedule:   "monthly_revenue",
+		Link:       "http://localhost:8080/reports/download/1",
+		Recipients: []string{"unknown@example.com"},
+	})
+	assert.Error(t, err)
+	<-server.done
+	assert.Empty(t, server.data)
+}
@@ -1,24 +1,28 @@
 package gdrive
 
 import (
+	"account-management-service/internal/webapi"
 	"bytes"
 	"context"
 	"errors"
 	"fmt"
 	"google.golang.org/api/drive/v3"
 	"google.golang.org/api/option"
+	"io"
 )
 
 type GDriveWebAPI struct {
 	driveService *drive.Service
 	isAvailable  bool
+	// public files are readable by anyone with the link
+	public bool
 }
 
 var (
 	ErrFileNotFound = errors.New("file not found")
 )
 
-func New(apiJSONFilePath string) *GDriveWebAPI {
+func New(apiJSONFilePath string, public bool) *GDriveWebAPI {
 	if apiJSONFilePath == "" {
 		return &GDriveWebAPI{isAvailable: false}
 	}
@@ -31,6 +35,7 @@ func New(apiJSONFilePath string) *GDriveWebAPI {
 	return &GDriveWebAPI{
 		driveService: driveService,
 		isAvailable:  true,
+		public:       public,
 	}
 }
 
@@ -38,24 +43,29 @@ func (w *GDriveWebAPI) IsAvailable() bool {
 	return w.isAvailable
 }
 
-func (w *GDriveWebAPI) UploadCSVFile(ctx context.Context, name string, data []byte) (string, error) {
+func (w *GDriveWebAPI) UploadFile(ctx context.Context, name string, data []byte) (string, error) {
+	return w.UploadStream(ctx, name, bytes.NewReader(data))
+}
+
+// UploadStream uploads the file by chunks of the resumable upload, so only the chunk is kept in memory
+func (w *GDriveWebAPI) UploadStream(ctx context.Context, name string, r io.Reader) (string, error) {
 	fileId, err := w.getFileIdByName(ctx, name)
 	if err != nil {
 		if !errors.Is(err, ErrFileNotFound) {
-			return "", fmt.Errorf("GDriveWebAPI.UploadCSVFile: w.getFileIdByName: %w", err)
+			return "", fmt.Errorf("GDriveWebAPI.UploadStream: w.getFileIdByName: %w", err)
 		}
 
-		id, err := w.createFile(ctx, name, data)
+		id, err := w.createFile(ctx, name, r)
 		if err != nil {
-			return "", fmt.Errorf("GDriveWebAPI.UploadCSVFile: w.createFile: %w", err)
+			return "", fmt.Errorf("GDriveWebAPI.UploadStream: w.createFile: %w", err)
 		}
 
 		return w.getFileURL(id), nil
 	}
 
-	err = w.updateFile(ctx, fileId, data)
+	err = w.updateFile(ctx, fileId, r)
 	if err != nil {
-		return "", fmt.Errorf("GDriveWebAPI.UploadCSVFile: w.updateFile: %w", err)
+		return "", fmt.Errorf("GDriveWebAPI.UploadStream: w.updateFile: %w", err)
 	}
 
 	return w.getFileURL(fileId), nil
@@ -75,6 +85,26 @@ func (w *GDriveWebAPI) DeleteFile(ctx context.Context, name string) error {
 	return nil
 }
 
+func (w *GDriveWebAPI) DownloadFile(ctx context.Context, name string) ([]byte, error) {
+	fileId, err := w.getFileIdByName(ctx, name)
+	if err != nil {
+		return nil, fmt.Errorf("GDriveWebAPI.DownloadFile: w.getFileIdByName: %w", err)
+	}
+
+	resp, err := w.driveService.Files.Get(fileId).Context(ctx).Download()
+	if err != nil {
+		return nil, fmt.Errorf("GDriveWebAPI.DownloadFile: w.driveService.Files.Get: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("GDriveWebAPI.DownloadFile: io.ReadAll: %w", err)
+	}
+
+	return data, nil
+}
+
 func (w *GDriveWebAPI) GetAllFilenames(ctx context.Context) ([]string, error) {
 	files, err := w.getAllFiles(ctx)
 	if err != nil {
@@ -89,19 +119,14 @@ func (w *GDriveWebAPI) GetAllFilenames(ctx context.Context) ([]string, error) {
 	return names, nil
 }
 
-// createFile creates a csv file in Google Drive with public read access and returns its ID and URL
-func (w *GDriveWebAPI) createFile(ctx context.Context, name string, content []byte) (string, error) {
+// createFile creates a file in Google Drive, with public read access if configured, and returns its ID
+func (w *GDriveWebAPI) createFile(ctx context.Context, name string, content io.Reader) (string, error) {
 	file := &drive.File{
 		Name:     name,
-		MimeType: "text/csv",
+		MimeType: webapi.ContentType(name),
 	}
 
-	permissions := &drive.Permission{
-		Type: "anyone",
-		Role: "reader",
-	}
-
-	_, err := w.driveService.Files.Create(file).Context(ctx).Media(bytes.NewReader(content)).Do()
+	_, err := w.driveService.Files.Create(file).Context(ctx).Media(content).Do()
 	if err != nil {
 		return "", err
 	}
@@ -111,16 +136,23 @@ func (w *GDriveWebAPI) createFile(ctx context.Context, name string, content []by
 		return "", err
 	}
 
-	_, err = w.driveService.Permissions.Create(fileId, permissions).Context(ctx).Do()
-	if err != nil {
-		return "", err
+	if w.public {
+		permissions := &drive.Permission{
+			Type: "anyone",
+			Role: "reader",
+		}
+
+		_, err = w.driveService.Permissions.Create(fileId, permissions).Context(ctx).Do()
+		if err != nil {
+			return "", err
+		}
 	}
 
 	return fileId, nil
 }
 
-func (w *GDriveWebAPI) updateFile(ctx context.Context, id string, content []byte) error {
-	_, err := w.driveService.Files.Update(id, &drive.File{}).Context(ctx).Media(bytes.NewReader(content)).Do()
+func (w *GDriveWebAPI) updateFile(ctx context.Context, id string, content io.Reader) error {
+	_, err := w.driveService.Files.Update(id, &drive.File{}).Context(ctx).Media(content).Do()
 
 	return err
 }
@@ -0,0 +1,132 @@
+package localfs
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// URLPrefix is the path the service serves the stored files from
+const URLPrefix = "/reports"
+
+var (
+	ErrInvalidFilename = errors.New("invalid filename")
+)
+
+// LocalFSWebAPI keeps files in the directory on the local filesystem,
+// the files are served by the service itself under URLPrefix
+type LocalFSWebAPI struct {
+	dir     string
+	baseURL string
+}
+
+func New(dir, baseURL string) *LocalFSWebAPI {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		panic(err)
+	}
+
+	return &LocalFSWebAPI{
+		dir:     dir,
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+	}
+}
+
+func (w *LocalFSWebAPI) IsAvailable() bool {
+	return true
+}
+
+// Dir returns the directory to serve the files from
+func (w *LocalFSWebAPI) Dir() string {
+	return w.dir
+}
+
+func (w *LocalFSWebAPI) UploadFile(ctx context.Context, name string, data []byte) (string, error) {
+	return w.UploadStream(ctx, name, bytes.NewReader(data))
+}
+
+func (w *LocalFSWebAPI) UploadStream(ctx context.Context, name string, r io.Reader) (string, error) {
+	path, err := w.path(name)
+	if err != nil {
+		return "", fmt.Errorf("LocalFSWebAPI.UploadStream: %w", err)
+	}
+
+	// write to a temporary file first so that the link never serves a partially written file
+	tmp, err := os.CreateTemp(w.dir, ".upload-*")
+	if err != nil {
+		return "", fmt.Errorf("LocalFSWebAPI.UploadStream: os.CreateTemp: %w", err)
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	if _, err = io.Copy(tmp, r); err != nil {
+		_ = tmp.Close()
+		return "", fmt.Errorf("LocalFSWebAPI.UploadStream: io.Copy: %w", err)
+	}
+	if err = tmp.Close(); err != nil {
+		return "", fmt.Errorf("LocalFSWebAPI.UploadStream: tmp.Close: %w", err)
+	}
+
+	if err = os.Rename(tmp.Name(), path); err != nil {
+		return "", fmt.Errorf("LocalFSWebAPI.UploadStream: os.Rename: %w", err)
+	}
+
+	return fmt.Sprintf("%s%s/%s", w.baseURL, URLPrefix, url.PathEscape(name)), nil
+}
+
+func (w *LocalFSWebAPI) DownloadFile(ctx context.Context, name string) ([]byte, error) {
+	path, err := w.path(name)
+	if err != nil {
+		return nil, fmt.Errorf("LocalFSWebAPI.DownloadFile: %w", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("LocalFSWebAPI.DownloadFile: os.ReadFile: %w", err)
+	}
+
+	return data, nil
+}
+
+func (w *LocalFSWebAPI) DeleteFile(ctx context.Context, name string) error {
+	path, err := w.path(name)
+	if err != nil {
+		return fmt.Errorf("LocalFSWebAPI.DeleteFile: %w", err)
+	}
+
+	if err = os.Remove(path); err != nil {
+		return fmt.Errorf("LocalFSWebAPI.DeleteFile: os.Remove: %w", err)
+	}
+
+	return nil
+}
+
+func (w *LocalFSWebAPI) GetAllFilenames(ctx context.Context) ([]string, error) {
+	entries, err := os.ReadDir(w.dir)
+	if err != nil {
+		return nil, fmt.Errorf("LocalFSWebAPI.GetAllFilenames: os.ReadDir: %w", err)
+	}
+
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		names = append(names, entry.Name())
+	}
+
+	return names, nil
+}
+
+// path returns the path of the file in the directory, names must not point outside of it
+func (w *LocalFSWebAPI) path(name string) (string, error) {
+	if name == "" || strings.HasPrefix(name, ".") || filepath.Base(name) != name {
+		return "", ErrInvalidFilename
+	}
+
+	return filepath.Join(w.dir, name), nil
+}
@@ -0,0 +1,39 @@
+package localfs
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLocalFSWebAPI(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	w := New(dir, "http://localhost:8080/")
+
+	link, err := w.UploadFile(ctx, "report_1_2022.csv", []byte("product,100,100,0\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, "http://localhost:8080/reports/report_1_2022.csv", link)
+
+	data, err := os.ReadFile(filepath.Join(dir, "report_1_2022.csv"))
+	assert.NoError(t, err)
+	assert.Equal(t, "product,100,100,0\n", string(data))
+
+	data, err = w.DownloadFile(ctx, "report_1_2022.csv")
+	assert.NoError(t, err)
+	assert.Equal(t, "product,100,100,0\n", string(data))
+
+	names, err := w.GetAllFilenames(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"report_1_2022.csv"}, names)
+
+	_, err = w.UploadFile(ctx, "../report.csv", nil)
+	assert.ErrorIs(t, err, ErrInvalidFilename)
+
+	assert.NoError(t, w.DeleteFile(ctx, "report_1_2022.csv"))
+	names, err = w.GetAllFilenames(ctx)
+	assert.NoError(t, err)
+	assert.Empty(t, names)
+}
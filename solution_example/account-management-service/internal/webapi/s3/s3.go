@@ -0,0 +1,340 @@
+package s3
+
+import (
+	"account-management-service/internal/webapi"
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxLinkTTL is the longest expiration of the presigned url allowed by S3
+const maxLinkTTL = 7 * 24 * time.Hour
+
+// partSize is the size of the parts of the multipart upload, the smallest part S3 accepts
+const partSize = 5 << 20
+
+// S3WebAPI keeps files in a bucket of S3 compatible object storage (AWS S3, MinIO, etc.).
+// The bucket is addressed in path style, links to the files are presigned urls
+type S3WebAPI struct {
+	client    *http.Client
+	endpoint  *url.URL
+	region    string
+	bucket    string
+	accessKey string
+	secretKey string
+	linkTTL   time.Duration
+	now       func() time.Time
+}
+
+func New(endpoint, region, bucket, accessKey, secretKey string, linkTTL time.Duration) *S3WebAPI {
+	u, err := url.Parse(strings.TrimSuffix(endpoint, "/"))
+	if err != nil || u.Host == "" {
+		panic(fmt.Sprintf("invalid s3 endpoint %q", endpoint))
+	}
+
+	if linkTTL <= 0 || linkTTL > maxLinkTTL {
+		linkTTL = maxLinkTTL
+	}
+
+	return &S3WebAPI{
+		client:    &http.Client{Timeout: 30 * time.Second},
+		endpoint:  u,
+		region:    region,
+		bucket:    bucket,
+		accessKey: accessKey,
+		secretKey: secretKey,
+		linkTTL:   linkTTL,
+		now:       time.Now,
+	}
+}
+
+func (w *S3WebAPI) IsAvailable() bool {
+	return w.bucket != "" && w.accessKey != "" && w.secretKey != ""
+}
+
+func (w *S3WebAPI) UploadFile(ctx context.Context, name string, data []byte) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, w.objectURL(name).String(), bytes.NewReader(data))
+	if err != nil {
+		return "", fmt.Errorf("S3WebAPI.UploadFile: http.NewRequestWithContext: %w", err)
+	}
+	req.Header.Set("Content-Type", webapi.ContentType(name))
+
+	_, err = w.do(req, data)
+	if err != nil {
+		return "", fmt.Errorf("S3WebAPI.UploadFile: w.do: %w", err)
+	}
+
+	return w.presignedURL(name), nil
+}
+
+// UploadStream uploads the file by parts of the multipart upload, so only the part is kept in memory.
+// The file which fits into a single part is uploaded by the single request
+func (w *S3WebAPI) UploadStream(ctx context.Context, name string, r io.Reader) (string, error) {
+	part := make([]byte, partSize)
+	n, err := io.ReadFull(r, part)
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return w.UploadFile(ctx, name, part[:n])
+	}
+	if err != nil {
+		return "", fmt.Errorf("S3WebAPI.UploadStream: io.ReadFull: %w", err)
+	}
+
+	uploadId, err := w.createMultipartUpload(ctx, name)
+	if err != nil {
+		return "", fmt.Errorf("S3WebAPI.UploadStream: w.createMultipartUpload: %w", err)
+	}
+
+	parts, err := w.uploadParts(ctx, name, uploadId, part, r)
+	if err != nil {
+		// the uploaded parts are kept by S3 until the upload is aborted, the request context may be already cancelled
+		if abortErr := w.abortMultipartUpload(context.Background(), name, uploadId); abortErr != nil {
+			err = fmt.Errorf("%w, abort: %v", err, abortErr)
+		}
+		return "", fmt.Errorf("S3WebAPI.UploadStream: w.uploadParts: %w", err)
+	}
+
+	if err = w.completeMultipartUpload(ctx, name, uploadId, parts); err != nil {
+		return "", fmt.Errorf("S3WebAPI.UploadStream: w.completeMultipartUpload: %w", err)
+	}
+
+	return w.presignedURL(name), nil
+}
+
+type initiateMultipartUploadResult struct {
+	UploadId string `xml:"UploadId"`
+}
+
+type completedPart struct {
+	PartNumber int    `xml:"PartNumber"`
+	ETag       string `xml:"ETag"`
+}
+
+type completeMultipartUpload struct {
+	XMLName xml.Name        `xml:"CompleteMultipartUpload"`
+	Parts   []completedPart `xml:"Part"`
+}
+
+func (w *S3WebAPI) createMultipartUpload(ctx context.Context, name string) (string, error) {
+	u := w.objectURL(name)
+	u.RawQuery = canonicalQuery(url.Values{"uploads": {""}})
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u.String(), nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", webapi.ContentType(name))
+
+	body, _, err := w.send(req, nil)
+	if err != nil {
+		return "", err
+	}
+
+	var result initiateMultipartUploadResult
+	if err = xml.Unmarshal(body, &result); err != nil {
+		return "", err
+	}
+	if result.UploadId == "" {
+		return "", errors.New("no upload id in response")
+	}
+
+	return result.UploadId, nil
+}
+
+// uploadParts uploads the first part, which is already read, and the rest of r
+func (w *S3WebAPI) uploadParts(ctx context.Context, name, uploadId string, part []byte, r io.Reader) ([]completedPart, error) {
+	var parts []completedPart
+	size := len(part)
+
+	for size > 0 {
+		number := len(parts) + 1
+
+		u := w.objectURL(name)
+		u.RawQuery = canonicalQuery(url.Values{"partNumber": {strconv.Itoa(number)}, "uploadId": {uploadId}})
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodPut, u.String(), bytes.NewReader(part[:size]))
+		if err != nil {
+			return nil, err
+		}
+
+		_, header, err := w.send(req, part[:size])
+		if err != nil {
+			return nil, fmt.Errorf("part %d: %w", number, err)
+		}
+		parts = append(parts, completedPart{PartNumber: number, ETag: header.Get("ETag")})
+
+		size, err = io.ReadFull(r, part)
+		if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+			return nil, err
+		}
+	}
+
+	return parts, nil
+}
+
+func (w *S3WebAPI) completeMultipartUpload(ctx context.Context, name, uploadId string, parts []completedPart) error {
+	payload, err := xml.Marshal(completeMultipartUpload{Parts: parts})
+	if err != nil {
+		return err
+	}
+
+	u := w.objectURL(name)
+	u.RawQuery = canonicalQuery(url.Values{"uploadId": {uploadId}})
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u.String(), bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+
+	body, _, err := w.send(req, payload)
+	if err != nil {
+		return err
+	}
+
+	// the completion may fail after the 200 status has been sent, then the body is the error
+	var result struct {
+		XMLName xml.Name
+	}
+	if err = xml.Unmarshal(body, &result); err == nil && result.XMLName.Local == "Error" {
+		return fmt.Errorf("upload is not completed: %s", body)
+	}
+
+	return nil
+}
+
+func (w *S3WebAPI) abortMultipartUpload(ctx context.Context, name, uploadId string) error {
+	u := w.objectURL(name)
+	u.RawQuery = canonicalQuery(url.Values{"uploadId": {uploadId}})
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, u.String(), nil)
+	if err != nil {
+		return err
+	}
+
+	_, _, err = w.send(req, nil)
+	return err
+}
+
+func (w *S3WebAPI) DownloadFile(ctx context.Context, name string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, w.objectURL(name).String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("S3WebAPI.DownloadFile: http.NewRequestWithContext: %w", err)
+	}
+
+	data, err := w.do(req, nil)
+	if err != nil {
+		return nil, fmt.Errorf("S3WebAPI.DownloadFile: w.do: %w", err)
+	}
+
+	return data, nil
+}
+
+func (w *S3WebAPI) DeleteFile(ctx context.Context, name string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, w.objectURL(name).String(), nil)
+	if err != nil {
+		return fmt.Errorf("S3WebAPI.DeleteFile: http.NewRequestWithContext: %w", err)
+	}
+
+	_, err = w.do(req, nil)
+	if err != nil {
+		return fmt.Errorf("S3WebAPI.DeleteFile: w.do: %w", err)
+	}
+
+	return nil
+}
+
+type listBucketResult struct {
+	Contents []struct {
+		Key string `xml:"Key"`
+	} `xml:"Contents"`
+	IsTruncated           bool   `xml:"IsTruncated"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+}
+
+func (w *S3WebAPI) GetAllFilenames(ctx context.Context) ([]string, error) {
+	var names []string
+	var token string
+
+	for {
+		u := w.objectURL("")
+		query := url.Values{"list-type": {"2"}}
+		if token != "" {
+			query.Set("continuation-token", token)
+		}
+		u.RawQuery = canonicalQuery(query)
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+		if err != nil {
+			return nil, fmt.Errorf("S3WebAPI.GetAllFilenames: http.NewRequestWithContext: %w", err)
+		}
+
+		body, err := w.do(req, nil)
+		if err != nil {
+			return nil, fmt.Errorf("S3WebAPI.GetAllFilenames: w.do: %w", err)
+		}
+
+		var result listBucketResult
+		if err = xml.Unmarshal(body, &result); err != nil {
+			return nil, fmt.Errorf("S3WebAPI.GetAllFilenames: xml.Unmarshal: %w", err)
+		}
+
+		for _, object := range result.Contents {
+			names = append(names, object.Key)
+		}
+
+		if !result.IsTruncated || result.NextContinuationToken == "" {
+			return names, nil
+		}
+		token = result.NextContinuationToken
+	}
+}
+
+// do signs and sends the request, responses other than 2xx are returned as errors with the S3 error body
+func (w *S3WebAPI) do(req *http.Request, payload []byte) ([]byte, error) {
+	body, _, err := w.send(req, payload)
+	return body, err
+}
+
+// send is do which also returns the response headers
+func (w *S3WebAPI) send(req *http.Request, payload []byte) ([]byte, http.Header, error) {
+	signRequest(req, hashHex(payload), w.now().UTC(), w.region, w.accessKey, w.secretKey)
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, nil, fmt.Errorf("unexpected status %d: %s", resp.StatusCode, body)
+	}
+
+	return body, resp.Header, nil
+}
+
+func (w *S3WebAPI) objectURL(name string) *url.URL {
+	u := *w.endpoint
+	u.Path = u.Path + "/" + w.bucket
+	if name != "" {
+		u.Path += "/" + name
+	}
+	// the path is escaped the same way it is escaped for the signature
+	u.RawPath = uriEncode(u.Path, false)
+	return &u
+}
+
+func (w *S3WebAPI) presignedURL(name string) string {
+	return presignURL(w.objectURL(name), w.now().UTC(), w.linkTTL, w.region, w.accessKey, w.secretKey)
+}
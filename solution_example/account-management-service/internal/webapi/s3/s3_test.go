@@ -0,0 +1,102 @@
+package s3
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeMultipartServer keeps the parts of the multipart upload and the objects put by the single request
+type fakeMultipartServer struct {
+	mu        sync.Mutex
+	parts     map[string][]byte
+	objects   map[string][]byte
+	completed []completedPart
+	aborted   bool
+}
+
+func (f *fakeMultipartServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	body, _ := io.ReadAll(r.Body)
+	query := r.URL.Query()
+
+	switch {
+	case r.Method == http.MethodPost && query.Has("uploads"):
+		_, _ = fmt.Fprint(w, "<InitiateMultipartUploadResult><UploadId>upload-1</UploadId></InitiateMultipartUploadResult>")
+	case r.Method == http.MethodPut && query.Get("uploadId") == "upload-1":
+		f.parts[query.Get("partNumber")] = body
+		w.Header().Set("ETag", fmt.Sprintf("%q", "etag-"+query.Get("partNumber")))
+	case r.Method == http.MethodPost && query.Get("uploadId") == "upload-1":
+		var complete completeMultipartUpload
+		_ = xml.Unmarshal(body, &complete)
+		f.completed = complete.Parts
+		_, _ = fmt.Fprint(w, "<CompleteMultipartUploadResult></CompleteMultipartUploadResult>")
+	case r.Method == http.MethodDelete && query.Get("uploadId") == "upload-1":
+		f.aborted = true
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == http.MethodPut:
+		f.objects[r.URL.Path] = body
+	default:
+		w.WriteHeader(http.StatusBadRequest)
+	}
+}
+
+func TestS3WebAPI_UploadStream(t *testing.T) {
+	ctx := context.Background()
+
+	fake := &fakeMultipartServer{parts: map[string][]byte{}, objects: map[string][]byte{}}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	w := New(server.URL, exampleRegion, "bucket", exampleAccessKey, exampleSecretKey, time.Hour)
+
+	// the small file is put by the single request
+	link, err := w.UploadStream(ctx, "small.csv", strings.NewReader("product,100\n"))
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(link, server.URL+"/bucket/small.csv?"))
+	assert.Equal(t, []byte("product,100\n"), fake.objects["/bucket/small.csv"])
+
+	// the large file is uploaded by parts
+	data := bytes.Repeat([]byte("0123456789"), partSize/10+1)
+	_, err = w.UploadStream(ctx, "large.csv", bytes.NewReader(data))
+	assert.NoError(t, err)
+
+	assert.Len(t, fake.parts, 2)
+	assert.Equal(t, data, append(append([]byte{}, fake.parts["1"]...), fake.parts["2"]...))
+	assert.Equal(t, []completedPart{{PartNumber: 1, ETag: `"etag-1"`}, {PartNumber: 2, ETag: `"etag-2"`}}, fake.completed)
+	assert.False(t, fake.aborted)
+}
+
+func TestS3WebAPI_UploadStreamAborted(t *testing.T) {
+	ctx := context.Background()
+
+	fake := &fakeMultipartServer{parts: map[string][]byte{}, objects: map[string][]byte{}}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	w := New(server.URL, exampleRegion, "bucket", exampleAccessKey, exampleSecretKey, time.Hour)
+
+	// the file fails to be generated after the first part
+	r := io.MultiReader(bytes.NewReader(make([]byte, partSize)), &failingReader{})
+	_, err := w.UploadStream(ctx, "large.csv", r)
+	assert.Error(t, err)
+	assert.True(t, fake.aborted)
+	assert.Nil(t, fake.completed)
+}
+
+type failingReader struct{}
+
+func (failingReader) Read([]byte) (int, error) {
+	return 0, fmt.Errorf("report failed")
+}
@@ -1,10 +1,42 @@
 package webapi
 
-import "context"
+import (
+	"account-management-service/internal/entity"
+	"context"
+	"io"
+	"path"
+	"strings"
+)
 
-type GDrive interface {
-	UploadCSVFile(ctx context.Context, name string, data []byte) (string, error)
+// ReportStorage keeps report files and returns links to share them.
+// UploadStream uploads the file read from r until EOF without keeping the whole file in memory
+type ReportStorage interface {
+	UploadFile(ctx context.Context, name string, data []byte) (string, error)
+	UploadStream(ctx context.Context, name string, r io.Reader) (string, error)
+	DownloadFile(ctx context.Context, name string) ([]byte, error)
 	DeleteFile(ctx context.Context, name string) error
 	GetAllFilenames(ctx context.Context) ([]string, error)
 	IsAvailable() bool
 }
+
+// ReportChannel delivers the scheduled report to the recipients of the delivery
+type ReportChannel interface {
+	DeliverReport(ctx context.Context, delivery entity.ReportDelivery) error
+}
+
+// ContentType returns the MIME type of the report file by its extension,
+// the system MIME tables are not used as they differ between environments
+func ContentType(name string) string {
+	switch strings.ToLower(path.Ext(name)) {
+	case ".csv":
+		return "text/csv"
+	case ".json":
+		return "application/json"
+	case ".xlsx":
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	case ".parquet":
+		return "application/vnd.apache.parquet"
+	default:
+		return "application/octet-stream"
+	}
+}
@@ -0,0 +1,74 @@
+package webhook
+
+import (
+	"account-management-service/internal/entity"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// WebhookWebAPI delivers the link to the report by posting it to the recipient urls, e.g. a messenger bot.
+// The file is never attached
+type WebhookWebAPI struct {
+	client *http.Client
+}
+
+func New() *WebhookWebAPI {
+	return &WebhookWebAPI{
+		client: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+type payload struct {
+	Schedule string `json:"schedule"`
+	From     string `json:"from"`
+	To       string `json:"to"`
+	Filename string `json:"filename"`
+	Link     string `json:"link"`
+}
+
+func (w *WebhookWebAPI) DeliverReport(ctx context.Context, delivery entity.ReportDelivery) error {
+	body, err := json.Marshal(payload{
+		Schedule: delivery.Schedule,
+		From:     delivery.From.Format("2006-01-02"),
+		To:       delivery.To.Format("2006-01-02"),
+		Filename: delivery.Filename,
+		Link:     delivery.Link,
+	})
+	if err != nil {
+		return fmt.Errorf("webhook: %w", err)
+	}
+
+	for _, url := range delivery.Recipients {
+		if err = w.post(ctx, url, body); err != nil {
+			return fmt.Errorf("webhook: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (w *WebhookWebAPI) post(ctx context.Context, url string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("%s responded with status %d", url, resp.StatusCode)
+	}
+
+	return nil
+}
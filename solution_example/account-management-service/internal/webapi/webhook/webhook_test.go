@@ -0,0 +1,44 @@
+package webhook
+
+import (
+	"account-management-service/internal/entity"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestWebhookWebAPI_DeliverReport(t *testing.T) {
+	var received []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received = append(received, r.URL.Path+" "+string(body))
+		if r.URL.Path == "/broken" {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	delivery := entity.ReportDelivery{
+		Schedule:   "monthly_revenue",
+		From:       time.Date(2022, time.October, 1, 0, 0, 0, 0, time.UTC),
+		To:         time.Date(2022, time.November, 1, 0, 0, 0, 0, time.UTC),
+		Filename:   "report_10_2022_v2.csv",
+		Link:       "http://localhost:8080/reports/download/1",
+		Data:       []byte("not sent"),
+		Recipients: []string{server.URL + "/hook"},
+	}
+
+	w := New()
+	err := w.DeliverReport(context.Background(), delivery)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{`/hook {"schedule":"monthly_revenue","from":"2022-10-01","to":"2022-11-01",` +
+		`"filename":"report_10_2022_v2.csv","link":"http://localhost:8080/reports/download/1"}`}, received)
+
+	delivery.Recipients = []string{server.URL + "/broken"}
+	err = w.DeliverReport(context.Background(), delivery)
+	assert.Error(t, err)
+}
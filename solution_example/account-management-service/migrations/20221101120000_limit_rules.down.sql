@@ -0,0 +1,9 @@
+drop index if exists operations_account_id_operation_type_created_at_idx;
+
+drop table if exists limit_rules;
+
+alter table accounts
+    drop column if exists type;
+
+alter table users
+    drop column if exists role;
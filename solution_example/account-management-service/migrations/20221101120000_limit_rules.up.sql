@@ -0,0 +1,21 @@
+alter table users
+    add column role varchar(32) not null default 'user';
+
+alter table accounts
+    add column type varchar(32) not null default 'personal';
+
+create table limit_rules
+(
+    id             serial primary key,
+    scope          varchar(32)  not null,
+    account_type   varchar(32)           default null,
+    account_id     int                   default null,
+    operation_type varchar(255) not null,
+    limit_type     varchar(32)  not null,
+    value          int          not null,
+    window_seconds int          not null default 0,
+    created_at     timestamp    not null default now(),
+    foreign key (account_id) references accounts (id)
+);
+
+create index operations_account_id_operation_type_created_at_idx on operations (account_id, operation_type, created_at);
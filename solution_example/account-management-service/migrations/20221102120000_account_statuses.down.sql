@@ -0,0 +1,4 @@
+drop table if exists account_status_changes;
+
+alter table accounts
+    drop column if exists status;
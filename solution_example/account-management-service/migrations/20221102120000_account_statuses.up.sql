@@ -0,0 +1,16 @@
+alter table accounts
+    add column status varchar(32) not null default 'active';
+
+create table account_status_changes
+(
+    id          serial primary key,
+    account_id  int          not null,
+    old_status  varchar(32)  not null,
+    new_status  varchar(32)  not null,
+    reason_code varchar(64)  not null,
+    comment     varchar(255)          default null,
+    changed_by  int                   default null,
+    created_at  timestamp    not null default now(),
+    foreign key (account_id) references accounts (id),
+    foreign key (changed_by) references users (id)
+);
@@ -0,0 +1,15 @@
+drop table if exists reservation_funds;
+
+alter table operations
+    drop column if exists wallet_type;
+
+alter table accounts
+    add column balance int not null default 0;
+
+update accounts
+set balance = wallets.balance
+from wallets
+where wallets.account_id = accounts.id
+  and wallets.wallet_type = 'main';
+
+drop table if exists wallets;
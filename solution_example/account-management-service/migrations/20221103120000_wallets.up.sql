@@ -0,0 +1,31 @@
+create table wallets
+(
+    account_id  int         not null,
+    wallet_type varchar(32) not null,
+    balance     int         not null default 0,
+    primary key (account_id, wallet_type),
+    foreign key (account_id) references accounts (id)
+);
+
+insert into wallets (account_id, wallet_type, balance)
+select id, 'main', balance
+from accounts;
+
+alter table accounts
+    drop column balance;
+
+alter table operations
+    add column wallet_type varchar(32) not null default 'main';
+
+create table reservation_funds
+(
+    reservation_id int         not null,
+    wallet_type    varchar(32) not null,
+    amount         int         not null,
+    primary key (reservation_id, wallet_type),
+    foreign key (reservation_id) references reservations (id) on delete cascade
+);
+
+insert into reservation_funds (reservation_id, wallet_type, amount)
+select id, 'main', amount
+from reservations;
@@ -0,0 +1,2 @@
+drop table if exists subscription_charges;
+drop table if exists subscriptions;
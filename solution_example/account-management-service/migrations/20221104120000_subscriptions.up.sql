@@ -0,0 +1,34 @@
+create table subscriptions
+(
+    id              serial primary key,
+    account_id      int         not null,
+    product_id      int         not null,
+    amount          int         not null,
+    period          varchar(16) not null,
+    status          varchar(32) not null default 'active',
+    next_charge_at  timestamp   not null,
+    next_attempt_at timestamp   not null,
+    failed_attempts int         not null default 0,
+    grace_until     timestamp            default null,
+    created_at      timestamp   not null default now(),
+    foreign key (account_id) references accounts (id),
+    foreign key (product_id) references products (id)
+);
+
+create index subscriptions_due_idx on subscriptions (next_attempt_at) where status in ('active', 'past_due');
+
+create table subscription_charges
+(
+    id              serial primary key,
+    subscription_id int          not null,
+    period_start    timestamp    not null,
+    amount          int          not null,
+    status          varchar(32)  not null,
+    attempt         int          not null default 1,
+    error           varchar(255)          default null,
+    created_at      timestamp    not null default now(),
+    foreign key (subscription_id) references subscriptions (id)
+);
+
+-- a period can be charged only once, failed attempts are kept for history
+create unique index subscription_charges_period_idx on subscription_charges (subscription_id, period_start) where status = 'succeeded';
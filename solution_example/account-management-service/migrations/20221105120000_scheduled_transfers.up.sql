@@ -0,0 +1,25 @@
+create table scheduled_transfers
+(
+    id              serial primary key,
+    from_account_id int         not null,
+    to_account_id   int         not null,
+    amount          int         not null,
+    execute_at      timestamp   not null,
+    repeat          varchar(16) not null default '',
+    status          varchar(32) not null default 'pending',
+    created_at      timestamp   not null default now(),
+    foreign key (from_account_id) references accounts (id),
+    foreign key (to_account_id) references accounts (id)
+);
+
+create index scheduled_transfers_due_idx on scheduled_transfers (execute_at) where status = 'pending';
+
+create table scheduled_transfer_runs
+(
+    id                    serial primary key,
+    scheduled_transfer_id int          not null,
+    status                varchar(32)  not null,
+    error                 varchar(255)          default null,
+    created_at            timestamp    not null default now(),
+    foreign key (scheduled_transfer_id) references scheduled_transfers (id)
+);
@@ -0,0 +1 @@
+drop table if exists payment_requests;
@@ -0,0 +1,17 @@
+create table payment_requests
+(
+    id                   serial primary key,
+    requester_account_id int          not null,
+    payer_account_id     int          not null,
+    amount               int          not null,
+    description          varchar(255) not null default '',
+    status               varchar(32)  not null default 'pending',
+    expires_at           timestamp    not null,
+    resolved_at          timestamp             default null,
+    created_at           timestamp    not null default now(),
+    foreign key (requester_account_id) references accounts (id),
+    foreign key (payer_account_id) references accounts (id)
+);
+
+create index payment_requests_requester_idx on payment_requests (requester_account_id);
+create index payment_requests_payer_idx on payment_requests (payer_account_id);
@@ -0,0 +1,14 @@
+create table fee_rules
+(
+    id             serial primary key,
+    operation_type varchar(255) not null,
+    product_id     int                   default null,
+    account_type   varchar(32)           default null,
+    rate           int          not null default 0,
+    fixed          int          not null default 0,
+    min_fee        int          not null default 0,
+    max_fee        int          not null default 0,
+    free_up_to     int          not null default 0,
+    created_at     timestamp    not null default now(),
+    foreign key (product_id) references products (id)
+);
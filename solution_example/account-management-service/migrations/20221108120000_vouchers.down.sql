@@ -0,0 +1,5 @@
+drop table if exists voucher_redemptions;
+
+drop table if exists vouchers;
+
+drop table if exists voucher_campaigns;
@@ -0,0 +1,33 @@
+create table voucher_campaigns
+(
+    id          serial primary key,
+    name        varchar(255) not null,
+    amount      int          not null,
+    wallet_type varchar(32)  not null default 'main',
+    max_uses    int          not null default 1,
+    expires_at  timestamp    not null,
+    created_at  timestamp    not null default now()
+);
+
+create table vouchers
+(
+    id          serial primary key,
+    campaign_id int         not null,
+    code        varchar(32) not null unique,
+    used_count  int         not null default 0,
+    foreign key (campaign_id) references voucher_campaigns (id)
+);
+
+create index vouchers_campaign_id_idx on vouchers (campaign_id);
+
+create table voucher_redemptions
+(
+    id         serial primary key,
+    voucher_id int       not null,
+    account_id int       not null,
+    amount     int       not null,
+    created_at timestamp not null default now(),
+    foreign key (voucher_id) references vouchers (id),
+    foreign key (account_id) references accounts (id),
+    unique (voucher_id, account_id)
+);
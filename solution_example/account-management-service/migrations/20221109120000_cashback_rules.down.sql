@@ -0,0 +1,3 @@
+drop index if exists operations_order_id_idx;
+
+drop table if exists cashback_rules;
@@ -0,0 +1,15 @@
+create table cashback_rules
+(
+    id         serial primary key,
+    product_id int       not null,
+    rate       int       not null,
+    cap        int       not null default 0,
+    valid_from timestamp not null default now(),
+    valid_to   timestamp          default null,
+    created_at timestamp not null default now(),
+    foreign key (product_id) references products (id)
+);
+
+create index cashback_rules_product_id_idx on cashback_rules (product_id);
+
+create index operations_order_id_idx on operations (order_id);
@@ -0,0 +1,2 @@
+drop table if exists installment_parts;
+drop table if exists installment_plans;
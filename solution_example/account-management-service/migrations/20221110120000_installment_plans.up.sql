@@ -0,0 +1,31 @@
+create table installment_plans
+(
+    id         serial primary key,
+    account_id int         not null,
+    product_id int         not null,
+    order_id   int         not null unique,
+    amount     int         not null,
+    period     varchar(16) not null,
+    status     varchar(32) not null default 'active',
+    created_at timestamp   not null default now(),
+    foreign key (account_id) references accounts (id),
+    foreign key (product_id) references products (id)
+);
+
+create table installment_parts
+(
+    id              serial primary key,
+    plan_id         int          not null,
+    number          int          not null,
+    amount          int          not null,
+    due_at          timestamp    not null,
+    status          varchar(32)  not null default 'pending',
+    attempts        int          not null default 0,
+    next_attempt_at timestamp    not null,
+    error           varchar(255)          default null,
+    paid_at         timestamp             default null,
+    unique (plan_id, number),
+    foreign key (plan_id) references installment_plans (id)
+);
+
+create index installment_parts_due_idx on installment_parts (next_attempt_at) where status = 'pending';
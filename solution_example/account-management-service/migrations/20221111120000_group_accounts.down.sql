@@ -0,0 +1,9 @@
+drop index if exists operations_account_user_idx;
+
+alter table operations
+    drop column if exists user_id;
+
+alter table reservations
+    drop column if exists user_id;
+
+drop table if exists account_members;
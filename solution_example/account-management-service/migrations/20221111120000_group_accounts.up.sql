@@ -0,0 +1,19 @@
+create table account_members
+(
+    account_id    int         not null,
+    user_id       int         not null,
+    role          varchar(32) not null,
+    monthly_limit int         not null default 0,
+    created_at    timestamp   not null default now(),
+    primary key (account_id, user_id),
+    foreign key (account_id) references accounts (id),
+    foreign key (user_id) references users (id)
+);
+
+alter table reservations
+    add column user_id int default null references users (id);
+
+alter table operations
+    add column user_id int default null references users (id);
+
+create index operations_account_user_idx on operations (account_id, user_id) where user_id is not null;
@@ -0,0 +1,6 @@
+drop table if exists verification_policies;
+
+drop table if exists verification_changes;
+
+alter table accounts
+    drop column if exists verification_level;
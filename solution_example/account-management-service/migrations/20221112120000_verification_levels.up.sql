@@ -0,0 +1,34 @@
+alter table accounts
+    add column verification_level varchar(32) not null default 'none';
+
+create table verification_changes
+(
+    id         serial primary key,
+    account_id int          not null,
+    old_level  varchar(32)  not null,
+    new_level  varchar(32)  not null,
+    reference  varchar(255)          default null,
+    changed_by int                   default null,
+    created_at timestamp    not null default now(),
+    foreign key (account_id) references accounts (id),
+    foreign key (changed_by) references users (id)
+);
+
+create table verification_policies
+(
+    level          varchar(32)  not null,
+    operation_type varchar(255) not null,
+    max_amount     int          not null default 0,
+    max_total      int          not null default 0,
+    window_seconds int          not null default 0,
+    updated_at     timestamp    not null default now(),
+    primary key (level, operation_type)
+);
+
+insert into verification_policies (level, operation_type, max_amount, max_total, window_seconds)
+values ('none', 'withdraw', 15000, 40000, 2592000),
+       ('none', 'transfer_from', 15000, 40000, 2592000),
+       ('basic', 'withdraw', 60000, 200000, 2592000),
+       ('basic', 'transfer_from', 60000, 200000, 2592000),
+       ('full', 'withdraw', 0, 0, 0),
+       ('full', 'transfer_from', 0, 0, 0);
@@ -0,0 +1,3 @@
+drop table if exists approval_events;
+
+drop table if exists approval_requests;
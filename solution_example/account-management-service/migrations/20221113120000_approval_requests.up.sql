@@ -0,0 +1,35 @@
+create table approval_requests
+(
+    id             serial primary key,
+    operation_type varchar(255) not null,
+    account_id     int                   default null,
+    order_id       int                   default null,
+    amount         int          not null default 0,
+    wallet         varchar(32)  not null default '',
+    status         varchar(32)  not null default 'pending',
+    maker_id       int          not null,
+    checker_id     int                   default null,
+    error          varchar(255) not null default '',
+    expires_at     timestamp    not null,
+    resolved_at    timestamp             default null,
+    created_at     timestamp    not null default now(),
+    foreign key (account_id) references accounts (id),
+    foreign key (maker_id) references users (id),
+    foreign key (checker_id) references users (id)
+);
+
+create index approval_requests_status_expires_at_idx on approval_requests (status, expires_at);
+
+create table approval_events
+(
+    id         serial primary key,
+    request_id int          not null,
+    action     varchar(32)  not null,
+    user_id    int                   default null,
+    comment    varchar(255) not null default '',
+    created_at timestamp    not null default now(),
+    foreign key (request_id) references approval_requests (id),
+    foreign key (user_id) references users (id)
+);
+
+create index approval_events_request_id_idx on approval_events (request_id);
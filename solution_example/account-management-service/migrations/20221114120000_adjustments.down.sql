@@ -0,0 +1,8 @@
+alter table approval_requests
+    drop column if exists reason_code,
+    drop column if exists comment,
+    drop column if exists ticket;
+
+drop table if exists adjustments;
+
+drop table if exists adjustment_reasons;
@@ -0,0 +1,38 @@
+create table adjustment_reasons
+(
+    code        varchar(64)  primary key,
+    description varchar(255) not null default '',
+    active      boolean      not null default true,
+    created_at  timestamp    not null default now()
+);
+
+insert into adjustment_reasons (code, description)
+values ('duplicate_payment', 'Payment was credited twice'),
+       ('processing_error', 'Operation was processed incorrectly'),
+       ('chargeback', 'Chargeback from the payment provider'),
+       ('goodwill', 'Goodwill compensation'),
+       ('migration', 'Correction after data migration');
+
+create table adjustments
+(
+    id          serial primary key,
+    account_id  int          not null,
+    direction   varchar(32)  not null,
+    amount      int          not null,
+    wallet      varchar(32)  not null,
+    reason_code varchar(64)  not null,
+    comment     varchar(255) not null,
+    ticket      varchar(64)  not null default '',
+    created_by  int          not null,
+    created_at  timestamp    not null default now(),
+    foreign key (account_id) references accounts (id),
+    foreign key (reason_code) references adjustment_reasons (code),
+    foreign key (created_by) references users (id)
+);
+
+create index adjustments_account_id_idx on adjustments (account_id);
+
+alter table approval_requests
+    add column reason_code varchar(64)  not null default '',
+    add column comment     varchar(255) not null default '',
+    add column ticket      varchar(64)  not null default '';
@@ -0,0 +1,3 @@
+drop table if exists report_link_accesses;
+
+drop table if exists report_links;
@@ -0,0 +1,22 @@
+create table report_links
+(
+    id         serial primary key,
+    filename   varchar(255) not null,
+    expires_at timestamp    not null,
+    one_time   boolean      not null default false,
+    used_at    timestamp,
+    created_at timestamp    not null default now()
+);
+
+-- attempts are logged even for links that do not exist, so link_id is not a foreign key
+create table report_link_accesses
+(
+    id         serial primary key,
+    link_id    int          not null,
+    ip         varchar(64)  not null,
+    user_agent varchar(255) not null default '',
+    result     varchar(32)  not null,
+    created_at timestamp    not null default now()
+);
+
+create index report_link_accesses_link_id_idx on report_link_accesses (link_id);
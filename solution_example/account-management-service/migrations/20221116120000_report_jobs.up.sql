@@ -0,0 +1,24 @@
+create table report_jobs
+(
+    id          serial primary key,
+    key         varchar(255) not null,
+    month       int          not null,
+    year        int          not null,
+    status      varchar(32)  not null default 'pending',
+    progress    int          not null default 0,
+    filename    varchar(255) not null default '',
+    link        text         not null default '',
+    error       varchar(255) not null default '',
+    attempts    int          not null default 0,
+    next_run_at timestamp    not null default now(),
+    created_by  int          not null,
+    created_at  timestamp    not null default now(),
+    updated_at  timestamp    not null default now(),
+    finished_at timestamp,
+    foreign key (created_by) references users (id)
+);
+
+-- only one active job per report, identical requests get the id of the active one
+create unique index report_jobs_active_key_idx on report_jobs (key) where status in ('pending', 'running');
+
+create index report_jobs_pending_idx on report_jobs (next_run_at) where status = 'pending';
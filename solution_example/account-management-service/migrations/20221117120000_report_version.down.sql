@@ -0,0 +1,6 @@
+-- jobs of other versions cannot be told apart from the legacy ones without the column
+delete from report_jobs where version <> 1;
+
+update report_jobs set key = 'revenue:' || year || ':' || month;
+
+alter table report_jobs drop column if exists version;
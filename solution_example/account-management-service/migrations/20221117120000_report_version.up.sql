@@ -0,0 +1,4 @@
+-- jobs created before the report schema was versioned generate the legacy report
+alter table report_jobs add column version int not null default 1;
+
+update report_jobs set key = 'revenue:v1:' || year || ':' || month;
@@ -0,0 +1,8 @@
+delete from report_jobs where mode <> 'products';
+
+alter table report_jobs alter column key type varchar(255);
+
+alter table report_jobs
+    drop column if exists mode,
+    drop column if exists product_ids,
+    drop column if exists account_ids;
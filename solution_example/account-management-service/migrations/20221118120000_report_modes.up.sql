@@ -0,0 +1,7 @@
+alter table report_jobs
+    add column mode        varchar(32) not null default 'products',
+    add column product_ids int[]       not null default '{}',
+    add column account_ids int[]       not null default '{}';
+
+-- the key of the filtered report lists the filters
+alter table report_jobs alter column key type text;
@@ -0,0 +1,8 @@
+delete from report_jobs where month = 0 or granularity <> '';
+
+alter table report_jobs
+    drop column if exists date_from,
+    drop column if exists date_to,
+    drop column if exists granularity;
+
+drop index if exists operations_operation_type_created_at_idx;
@@ -0,0 +1,15 @@
+-- reports select the operations of the type in the range of created_at
+create index operations_operation_type_created_at_idx on operations (operation_type, created_at);
+
+alter table report_jobs
+    add column date_from   timestamp,
+    add column date_to     timestamp,
+    add column granularity varchar(16) not null default '';
+
+update report_jobs
+set date_from = make_timestamp(year, month, 1, 0, 0, 0),
+    date_to   = make_timestamp(year, month, 1, 0, 0, 0) + interval '1 month';
+
+alter table report_jobs
+    alter column date_from set not null,
+    alter column date_to set not null;
@@ -0,0 +1,6 @@
+delete from report_jobs where format <> 'csv' or csv_delimiter <> 'comma' or csv_bom;
+
+alter table report_jobs
+    drop column if exists format,
+    drop column if exists csv_delimiter,
+    drop column if exists csv_bom;
@@ -0,0 +1,6 @@
+alter table report_jobs
+    add column format        varchar(16) not null default 'csv',
+    add column csv_delimiter varchar(16) not null default '',
+    add column csv_bom       boolean     not null default false;
+
+update report_jobs set csv_delimiter = 'comma';
@@ -0,0 +1 @@
+drop index if exists operations_created_at_id_idx;
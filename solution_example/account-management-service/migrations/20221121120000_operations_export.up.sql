@@ -0,0 +1,2 @@
+-- exports read the operations of the range in the order of creation
+create index operations_created_at_id_idx on operations (created_at, id);
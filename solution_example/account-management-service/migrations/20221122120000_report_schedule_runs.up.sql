@@ -0,0 +1,17 @@
+create table report_schedule_runs
+(
+    id           serial primary key,
+    schedule     varchar(255) not null,
+    scheduled_at timestamp    not null,
+    status       varchar(32)  not null default 'running',
+    attempts     int          not null default 1,
+    filename     varchar(255) not null default '',
+    link         text         not null default '',
+    delivered    text[]       not null default '{}',
+    error        varchar(255) not null default '',
+    started_at   timestamp    not null default now(),
+    finished_at  timestamp
+);
+
+-- each scheduled time is run once, the replica which has inserted the run generates the report
+create unique index report_schedule_runs_schedule_idx on report_schedule_runs (schedule, scheduled_at);
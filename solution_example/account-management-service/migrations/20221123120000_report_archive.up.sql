@@ -0,0 +1,15 @@
+create table report_archive
+(
+    id          serial primary key,
+    filename    varchar(255) not null unique,
+    kind        varchar(32)  not null,
+    period_from timestamp,
+    period_to   timestamp,
+    format      varchar(32)  not null default '',
+    created_by  int references users (id) on delete set null,
+    size        bigint,
+    created_at  timestamp    not null default now()
+);
+
+-- the retention purge deletes the oldest reports
+create index report_archive_created_at_idx on report_archive (created_at);
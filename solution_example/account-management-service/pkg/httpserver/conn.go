@@ -0,0 +1,29 @@
+package httpserver
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"time"
+)
+
+var ErrNoConn = errors.New("connection is not available in the request context")
+
+type connCtxKey struct{}
+
+// connContext keeps the connection in the request context,
+// so a handler can change the deadlines set by the server timeouts
+func connContext(ctx context.Context, c net.Conn) context.Context {
+	return context.WithValue(ctx, connCtxKey{}, c)
+}
+
+// SetWriteDeadline overrides the write timeout of the server for the request connection.
+// The server sets the deadline again when it reads the next request on the connection
+func SetWriteDeadline(r *http.Request, deadline time.Time) error {
+	c, ok := r.Context().Value(connCtxKey{}).(net.Conn)
+	if !ok {
+		return ErrNoConn
+	}
+	return c.SetWriteDeadline(deadline)
+}
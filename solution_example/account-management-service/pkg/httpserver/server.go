@@ -25,6 +25,7 @@ func New(handler http.Handler, opts ...Option) *Server {
 		ReadTimeout:  defaultReadTimeout,
 		WriteTimeout: defaultWriteTimeout,
 		Addr:         defaultAddr,
+		ConnContext:  connContext,
 	}
 
 	s := &Server{
@@ -0,0 +1,19 @@
+package scheduler
+
+import "time"
+
+type Option func(*Scheduler)
+
+func Interval(interval time.Duration) Option {
+	return func(s *Scheduler) {
+		if interval > 0 {
+			s.interval = interval
+		}
+	}
+}
+
+func RunTimeout(timeout time.Duration) Option {
+	return func(s *Scheduler) {
+		s.runTimeout = timeout
+	}
+}
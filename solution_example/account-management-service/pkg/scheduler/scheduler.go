@@ -0,0 +1,77 @@
+package scheduler
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	defaultInterval   = time.Minute
+	defaultRunTimeout = 30 * time.Second
+)
+
+// Job is a unit of background work, it must respect context cancellation
+type Job func(ctx context.Context)
+
+// Scheduler runs the job periodically in its own goroutine, runs never overlap
+type Scheduler struct {
+	job        Job
+	interval   time.Duration
+	runTimeout time.Duration
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func New(job Job, opts ...Option) *Scheduler {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	s := &Scheduler{
+		job:        job,
+		interval:   defaultInterval,
+		runTimeout: defaultRunTimeout,
+		ctx:        ctx,
+		cancel:     cancel,
+		done:       make(chan struct{}),
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	s.start()
+
+	return s
+}
+
+func (s *Scheduler) start() {
+	go func() {
+		defer close(s.done)
+
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-s.ctx.Done():
+				return
+			case <-ticker.C:
+				s.run()
+			}
+		}
+	}()
+}
+
+func (s *Scheduler) run() {
+	ctx, cancel := context.WithTimeout(s.ctx, s.runTimeout)
+	defer cancel()
+
+	s.job(ctx)
+}
+
+// Shutdown stops the scheduler and waits for the current run to finish
+func (s *Scheduler) Shutdown() {
+	s.cancel()
+	<-s.done
+}
@@ -0,0 +1,98 @@
+package tabular
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+)
+
+// utf8BOM lets Excel recognize the encoding of the csv file
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+type CSVOptions struct {
+	// Delimiter is the field delimiter, comma by default
+	Delimiter rune
+	// BOM prepends the byte order mark
+	BOM bool
+	// NoHeader omits the header row
+	NoHeader bool
+}
+
+type CSVEncoder struct {
+	w       *csv.Writer
+	columns []Column
+	started bool
+	opts    CSVOptions
+	out     io.Writer
+}
+
+func NewCSVEncoder(w io.Writer, columns []Column, opts CSVOptions) *CSVEncoder {
+	cw := csv.NewWriter(w)
+	if opts.Delimiter != 0 {
+		cw.Comma = opts.Delimiter
+	}
+
+	return &CSVEncoder{
+		w:       cw,
+		columns: columns,
+		opts:    opts,
+		out:     w,
+	}
+}
+
+func (e *CSVEncoder) WriteRow(row Row) error {
+	if err := checkRow(e.columns, row); err != nil {
+		return err
+	}
+	if err := e.start(); err != nil {
+		return err
+	}
+
+	record := make([]string, len(row.Values))
+	for i, value := range row.Values {
+		switch v := value.(type) {
+		case string:
+			record[i] = v
+		case int:
+			record[i] = strconv.Itoa(v)
+		case float64:
+			record[i] = strconv.FormatFloat(v, 'f', 2, 64)
+		}
+	}
+
+	return e.w.Write(record)
+}
+
+func (e *CSVEncoder) Close() error {
+	if err := e.start(); err != nil {
+		return err
+	}
+
+	e.w.Flush()
+	return e.w.Error()
+}
+
+// start writes the BOM and the header with the first row, so that nothing is written
+// until the table has rows or is closed
+func (e *CSVEncoder) start() error {
+	if e.started {
+		return nil
+	}
+	e.started = true
+
+	if e.opts.BOM {
+		if _, err := e.out.Write(utf8BOM); err != nil {
+			return err
+		}
+	}
+
+	if e.opts.NoHeader {
+		return nil
+	}
+
+	header := make([]string, len(e.columns))
+	for i, column := range e.columns {
+		header[i] = column.Name
+	}
+	return e.w.Write(header)
+}
@@ -0,0 +1,107 @@
+package tabular
+
+import (
+	"bytes"
+	"encoding/csv"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestCSVEncoder(t *testing.T) {
+	testCases := []struct {
+		name    string
+		opts    CSVOptions
+		rows    []Row
+		want    string
+		wantBOM bool
+	}{
+		{
+			name: "comma by default",
+			opts: CSVOptions{},
+			rows: testRows,
+			want: "product,amount,share\n" +
+				"delivery,100,0.75\n" +
+				"\"storage, <cold> & \"\"dry\"\"\",50,\n" +
+				",-20,0.50\n" +
+				"total,130,1.00\n",
+		},
+		{
+			name: "semicolon",
+			opts: CSVOptions{Delimiter: ';'},
+			rows: testRows[:2],
+			want: "product;amount;share\n" +
+				"delivery;100;0.75\n" +
+				"\"storage, <cold> & \"\"dry\"\"\";50;\n",
+		},
+		{
+			name: "tab",
+			opts: CSVOptions{Delimiter: '\t'},
+			rows: testRows[:1],
+			want: "product\tamount\tshare\n" +
+				"delivery\t100\t0.75\n",
+		},
+		{
+			name:    "BOM",
+			opts:    CSVOptions{Delimiter: ';', BOM: true},
+			rows:    testRows[:1],
+			want:    "product;amount;share\ndelivery;100;0.75\n",
+			wantBOM: true,
+		},
+		{
+			name: "no header",
+			opts: CSVOptions{NoHeader: true},
+			rows: testRows[:1],
+			want: "delivery,100,0.75\n",
+		},
+		{
+			name:    "empty table",
+			opts:    CSVOptions{BOM: true},
+			rows:    nil,
+			want:    "product,amount,share\n",
+			wantBOM: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			e := NewCSVEncoder(&buf, testColumns, tc.opts)
+			for _, row := range tc.rows {
+				assert.NoError(t, e.WriteRow(row))
+			}
+			assert.NoError(t, e.Close())
+
+			got := buf.Bytes()
+			assert.Equal(t, tc.wantBOM, bytes.HasPrefix(got, utf8BOM))
+			got = bytes.TrimPrefix(got, utf8BOM)
+			assert.Equal(t, tc.want, string(got))
+
+			// the file is read back with the same delimiter into the header and the rows
+			r := csv.NewReader(bytes.NewReader(got))
+			if tc.opts.Delimiter != 0 {
+				r.Comma = tc.opts.Delimiter
+			}
+			records, err := r.ReadAll()
+			assert.NoError(t, err)
+
+			wantRecords := len(tc.rows)
+			if !tc.opts.NoHeader {
+				wantRecords++
+				assert.Equal(t, []string{"product", "amount", "share"}, records[0])
+			}
+			assert.Len(t, records, wantRecords)
+			if len(tc.rows) > 1 {
+				assert.Equal(t, "storage, <cold> & \"dry\"", records[len(records)-len(tc.rows)+1][0])
+			}
+		})
+	}
+}
+
+func TestCSVEncoder_WriteRowError(t *testing.T) {
+	var buf bytes.Buffer
+	e := NewCSVEncoder(&buf, testColumns, CSVOptions{BOM: true})
+
+	// nothing is written until the row fits the columns
+	assert.Error(t, e.WriteRow(Row{Values: []any{"delivery"}}))
+	assert.Empty(t, buf.Bytes())
+}
@@ -0,0 +1,93 @@
+package tabular
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"strconv"
+)
+
+// JSONEncoder writes the table as the array of objects keyed by the column names,
+// the keys keep the order of the columns
+type JSONEncoder struct {
+	w       *bufio.Writer
+	columns []Column
+	// keys are the encoded column names
+	keys    [][]byte
+	started bool
+}
+
+func NewJSONEncoder(w io.Writer, columns []Column) *JSONEncoder {
+	keys := make([][]byte, len(columns))
+	for i, column := range columns {
+		keys[i], _ = marshalString(column.Name)
+	}
+
+	return &JSONEncoder{
+		w:       bufio.NewWriter(w),
+		columns: columns,
+		keys:    keys,
+	}
+}
+
+func (e *JSONEncoder) WriteRow(row Row) error {
+	if err := checkRow(e.columns, row); err != nil {
+		return err
+	}
+
+	if e.started {
+		e.w.WriteString(",\n")
+	} else {
+		e.w.WriteString("[\n")
+		e.started = true
+	}
+
+	e.w.WriteByte('{')
+	for i, value := range row.Values {
+		if i > 0 {
+			e.w.WriteByte(',')
+		}
+		e.w.Write(e.keys[i])
+		e.w.WriteByte(':')
+
+		switch v := value.(type) {
+		case nil:
+			e.w.WriteString("null")
+		case string:
+			b, err := marshalString(v)
+			if err != nil {
+				return err
+			}
+			e.w.Write(b)
+		case int:
+			e.w.WriteString(strconv.Itoa(v))
+		case float64:
+			e.w.WriteString(strconv.FormatFloat(v, 'f', 2, 64))
+		}
+	}
+	_, err := e.w.WriteString("}")
+
+	return err
+}
+
+func (e *JSONEncoder) Close() error {
+	if e.started {
+		e.w.WriteString("\n]\n")
+	} else {
+		e.w.WriteString("[]\n")
+	}
+
+	return e.w.Flush()
+}
+
+// marshalString encodes the string without escaping the html characters, the output is not embedded into html
+func marshalString(s string) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(s); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
@@ -0,0 +1,248 @@
+package tabular
+
+import (
+	"encoding/binary"
+	"io"
+	"math"
+)
+
+var parquetMagic = []byte("PAR1")
+
+// Values of the parquet format enums
+const (
+	parquetTypeInt64     = 2
+	parquetTypeDouble    = 5
+	parquetTypeByteArray = 6
+
+	parquetOptional = 1
+	parquetUTF8     = 0
+
+	parquetPlain = 0
+	parquetRLE   = 3
+
+	parquetUncompressed = 0
+	parquetDataPage     = 0
+)
+
+const parquetCreatedBy = "account-management-service"
+
+// parquetRowGroupSize is the number of rows kept in memory before they are written as the row group
+const parquetRowGroupSize = 10000
+
+// ParquetEncoder writes the table as the parquet file of uncompressed plain encoded columns, all of them
+// are optional. The rows are kept in memory until there are enough of them for the row group,
+// the footer describing the row groups is written on Close
+type ParquetEncoder struct {
+	w       io.Writer
+	columns []Column
+	// rows are the rows of the row group being collected
+	rows      [][]any
+	rowGroups []parquetRowGroup
+	numRows   int
+	// offset is the number of bytes written
+	offset int64
+}
+
+func NewParquetEncoder(w io.Writer, columns []Column) *ParquetEncoder {
+	return &ParquetEncoder{
+		w:       w,
+		columns: columns,
+	}
+}
+
+func (e *ParquetEncoder) WriteRow(row Row) error {
+	if err := checkRow(e.columns, row); err != nil {
+		return err
+	}
+
+	// the rows are kept until the row group is written, so the caller may reuse the values
+	e.rows = append(e.rows, append([]any(nil), row.Values...))
+	if len(e.rows) < parquetRowGroupSize {
+		return nil
+	}
+	return e.writeRowGroup()
+}
+
+func (e *ParquetEncoder) Close() error {
+	if len(e.rows) > 0 {
+		if err := e.writeRowGroup(); err != nil {
+			return err
+		}
+	}
+	if e.offset == 0 {
+		if err := e.write(parquetMagic); err != nil {
+			return err
+		}
+	}
+
+	footer := e.fileMetaData()
+	footer = appendUint32(footer, uint32(len(footer)))
+	footer = append(footer, parquetMagic...)
+	return e.write(footer)
+}
+
+// parquetRowGroup describes the written row group
+type parquetRowGroup struct {
+	chunks []parquetChunk
+	rows   int
+}
+
+// parquetChunk describes the column chunk made of the single data page
+type parquetChunk struct {
+	offset int64
+	size   int64
+}
+
+func (e *ParquetEncoder) writeRowGroup() error {
+	if e.offset == 0 {
+		if err := e.write(parquetMagic); err != nil {
+			return err
+		}
+	}
+
+	group := parquetRowGroup{rows: len(e.rows)}
+	for i := range e.columns {
+		data := e.columnChunk(i)
+		group.chunks = append(group.chunks, parquetChunk{offset: e.offset, size: int64(len(data))})
+		if err := e.write(data); err != nil {
+			return err
+		}
+	}
+
+	e.rowGroups = append(e.rowGroups, group)
+	e.numRows += len(e.rows)
+	e.rows = e.rows[:0]
+	return nil
+}
+
+func (e *ParquetEncoder) write(data []byte) error {
+	n, err := e.w.Write(data)
+	e.offset += int64(n)
+	return err
+}
+
+// columnChunk encodes the column of the collected rows as the page header followed by the page
+func (e *ParquetEncoder) columnChunk(i int) []byte {
+	// definition levels are written as the single bit packed run of one bit values, 1 means the value is present
+	groups := (len(e.rows) + 7) / 8
+	levels := appendUvarint(nil, uint64(groups)<<1|1)
+	packed := make([]byte, groups)
+
+	var values []byte
+	for r, row := range e.rows {
+		if row[i] == nil {
+			continue
+		}
+		packed[r/8] |= 1 << (r % 8)
+
+		switch v := row[i].(type) {
+		case string:
+			values = appendUint32(values, uint32(len(v)))
+			values = append(values, v...)
+		case int:
+			values = appendUint64(values, uint64(v))
+		case float64:
+			values = appendUint64(values, math.Float64bits(v))
+		}
+	}
+	levels = append(levels, packed...)
+
+	page := appendUint32(nil, uint32(len(levels)))
+	page = append(page, levels...)
+	page = append(page, values...)
+
+	var header thriftWriter
+	header.i32Field(1, parquetDataPage)
+	header.i32Field(2, int32(len(page)))
+	header.i32Field(3, int32(len(page)))
+	header.structField(5)
+	header.i32Field(1, int32(len(e.rows)))
+	header.i32Field(2, parquetPlain)
+	header.i32Field(3, parquetRLE)
+	header.i32Field(4, parquetRLE)
+	header.structEnd()
+	header.buf = append(header.buf, 0)
+
+	return append(header.buf, page...)
+}
+
+func (e *ParquetEncoder) fileMetaData() []byte {
+	var w thriftWriter
+	w.i32Field(1, 1)
+
+	w.listField(2, thriftStruct, len(e.columns)+1)
+	w.structBegin()
+	w.binaryField(4, "schema")
+	w.i32Field(5, int32(len(e.columns)))
+	w.structEnd()
+	for _, column := range e.columns {
+		w.structBegin()
+		w.i32Field(1, parquetType(column.Type))
+		w.i32Field(3, parquetOptional)
+		w.binaryField(4, column.Name)
+		if column.Type == String {
+			w.i32Field(6, parquetUTF8)
+		}
+		w.structEnd()
+	}
+
+	w.i64Field(3, int64(e.numRows))
+
+	w.listField(4, thriftStruct, len(e.rowGroups))
+	for _, group := range e.rowGroups {
+		var size int64
+		w.structBegin()
+		w.listField(1, thriftStruct, len(group.chunks))
+		for i, chunk := range group.chunks {
+			size += chunk.size
+
+			w.structBegin()
+			w.i64Field(2, chunk.offset)
+			w.structField(3)
+			w.i32Field(1, parquetType(e.columns[i].Type))
+			w.listField(2, thriftI32, 2)
+			w.i32(parquetPlain)
+			w.i32(parquetRLE)
+			w.listField(3, thriftBinary, 1)
+			w.binary(e.columns[i].Name)
+			w.i32Field(4, parquetUncompressed)
+			w.i64Field(5, int64(group.rows))
+			w.i64Field(6, chunk.size)
+			w.i64Field(7, chunk.size)
+			w.i64Field(9, chunk.offset)
+			w.structEnd()
+			w.structEnd()
+		}
+		w.i64Field(2, size)
+		w.i64Field(3, int64(group.rows))
+		w.structEnd()
+	}
+
+	w.binaryField(6, parquetCreatedBy)
+	w.buf = append(w.buf, 0)
+
+	return w.buf
+}
+
+func parquetType(t ColumnType) int32 {
+	switch t {
+	case Int:
+		return parquetTypeInt64
+	case Decimal:
+		return parquetTypeDouble
+	default:
+		return parquetTypeByteArray
+	}
+}
+
+func appendUint32(buf []byte, v uint32) []byte {
+	var b [4]byte
+	binary.LittleEndian.PutUint32(b[:], v)
+	return append(buf, b[:]...)
+}
+
+func appendUint64(buf []byte, v uint64) []byte {
+	var b [8]byte
+	binary.LittleEndian.PutUint64(b[:], v)
+	return append(buf, b[:]...)
+}
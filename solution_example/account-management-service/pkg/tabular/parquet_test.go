@@ -0,0 +1,268 @@
+package tabular
+
+import (
+	"bytes"
+	"encoding/binary"
+	"flag"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+// the golden file is checked by pyarrow, run the tests with -update to write it anew
+var update = flag.Bool("update", false, "update the golden files")
+
+const parquetGoldenFile = "testdata/report.parquet"
+
+// thriftReader decodes the thrift compact protocol into maps of the field ids,
+// integers are read as int64, binaries as strings and lists as slices
+type thriftReader struct {
+	buf []byte
+	pos int
+}
+
+func (r *thriftReader) byte() byte {
+	b := r.buf[r.pos]
+	r.pos++
+	return b
+}
+
+func (r *thriftReader) varint() int64 {
+	v, n := binary.Varint(r.buf[r.pos:])
+	r.pos += n
+	return v
+}
+
+func (r *thriftReader) uvarint() uint64 {
+	v, n := binary.Uvarint(r.buf[r.pos:])
+	r.pos += n
+	return v
+}
+
+func (r *thriftReader) readStruct() map[int16]any {
+	fields := map[int16]any{}
+	var last int16
+	for {
+		header := r.byte()
+		if header == 0 {
+			return fields
+		}
+
+		id := last + int16(header>>4)
+		if header>>4 == 0 {
+			id = int16(r.varint())
+		}
+		fields[id] = r.readValue(header & 0x0F)
+		last = id
+	}
+}
+
+func (r *thriftReader) readValue(typ byte) any {
+	switch typ {
+	case thriftI32, thriftI64:
+		return r.varint()
+	case thriftBinary:
+		n := int(r.uvarint())
+		v := string(r.buf[r.pos : r.pos+n])
+		r.pos += n
+		return v
+	case thriftList:
+		header := r.byte()
+		size := int(header >> 4)
+		if size == 15 {
+			size = int(r.uvarint())
+		}
+		list := make([]any, size)
+		for i := range list {
+			list[i] = r.readValue(header & 0x0F)
+		}
+		return list
+	case thriftStruct:
+		return r.readStruct()
+	default:
+		panic(fmt.Sprintf("unexpected thrift type %d", typ))
+	}
+}
+
+// readParquet checks the file layout and decodes the footer and the column chunks back into the rows
+func readParquet(t *testing.T, data []byte) (map[int16]any, [][]any) {
+	assert.True(t, bytes.HasPrefix(data, parquetMagic))
+	assert.True(t, bytes.HasSuffix(data, parquetMagic))
+
+	footerLen := int(binary.LittleEndian.Uint32(data[len(data)-8:]))
+	footerStart := len(data) - 8 - footerLen
+	footerReader := &thriftReader{buf: data[footerStart : len(data)-8]}
+	footer := footerReader.readStruct()
+	assert.Equal(t, footerLen, footerReader.pos)
+
+	schema := footer[2].([]any)
+	columns := len(schema) - 1
+
+	var rows [][]any
+	for _, g := range footer[4].([]any) {
+		group := g.(map[int16]any)
+		groupRows := make([][]any, group[3].(int64))
+		for i := range groupRows {
+			groupRows[i] = make([]any, columns)
+		}
+
+		for i, c := range group[1].([]any) {
+			meta := c.(map[int16]any)[3].(map[int16]any)
+			assert.Equal(t, int64(len(groupRows)), meta[5])
+
+			offset := meta[9].(int64)
+			page := &thriftReader{buf: data[offset : offset+meta[7].(int64)]}
+			pageHeader := page.readStruct()
+			assert.Equal(t, int64(parquetDataPage), pageHeader[1])
+			assert.Equal(t, int64(len(groupRows)), pageHeader[5].(map[int16]any)[1])
+			assert.Equal(t, pageHeader[2], int64(len(page.buf)-page.pos))
+
+			// definition levels are the single bit packed run
+			levelsLen := int(binary.LittleEndian.Uint32(page.buf[page.pos:]))
+			page.pos += 4
+			levelsEnd := page.pos + levelsLen
+			runHeader := page.uvarint()
+			assert.Equal(t, uint64(1), runHeader&1)
+			packed := page.buf[page.pos:levelsEnd]
+			assert.Equal(t, int(runHeader>>1), len(packed))
+			page.pos = levelsEnd
+
+			typ := meta[1].(int64)
+			for r := range groupRows {
+				if packed[r/8]&(1<<(r%8)) == 0 {
+					continue
+				}
+				switch typ {
+				case parquetTypeByteArray:
+					n := int(binary.LittleEndian.Uint32(page.buf[page.pos:]))
+					groupRows[r][i] = string(page.buf[page.pos+4 : page.pos+4+n])
+					page.pos += 4 + n
+				case parquetTypeInt64:
+					groupRows[r][i] = int(int64(binary.LittleEndian.Uint64(page.buf[page.pos:])))
+					page.pos += 8
+				case parquetTypeDouble:
+					groupRows[r][i] = math.Float64frombits(binary.LittleEndian.Uint64(page.buf[page.pos:]))
+					page.pos += 8
+				}
+			}
+			assert.Equal(t, len(page.buf), page.pos)
+		}
+
+		rows = append(rows, groupRows...)
+	}
+
+	return footer, rows
+}
+
+func TestParquetEncoder(t *testing.T) {
+	var buf bytes.Buffer
+	e := NewParquetEncoder(&buf, testColumns)
+	for _, row := range testRows {
+		assert.NoError(t, e.WriteRow(row))
+	}
+	assert.NoError(t, e.Close())
+
+	footer, rows := readParquet(t, buf.Bytes())
+
+	assert.Equal(t, int64(1), footer[1])
+	assert.Equal(t, int64(len(testRows)), footer[3])
+	assert.Equal(t, parquetCreatedBy, footer[6])
+
+	schema := footer[2].([]any)
+	assert.Len(t, schema, 4)
+	assert.Equal(t, "schema", schema[0].(map[int16]any)[4])
+	assert.Equal(t, int64(3), schema[0].(map[int16]any)[5])
+	for i, column := range testColumns {
+		element := schema[i+1].(map[int16]any)
+		assert.Equal(t, column.Name, element[4])
+		assert.Equal(t, int64(parquetType(column.Type)), element[1])
+		assert.Equal(t, int64(parquetOptional), element[3])
+	}
+	assert.Equal(t, int64(parquetUTF8), schema[1].(map[int16]any)[6])
+
+	want := make([][]any, len(testRows))
+	for i, row := range testRows {
+		want[i] = row.Values
+	}
+	assert.Equal(t, want, rows)
+}
+
+func TestParquetEncoder_RowGroups(t *testing.T) {
+	var buf bytes.Buffer
+	e := NewParquetEncoder(&buf, testColumns)
+
+	// the values slice is reused by the caller for every row
+	values := make([]any, len(testColumns))
+	for i := 0; i < parquetRowGroupSize+3; i++ {
+		values[0], values[1], values[2] = fmt.Sprintf("product %d", i), i, float64(i)/4
+		assert.NoError(t, e.WriteRow(Row{Values: values}))
+	}
+	assert.NoError(t, e.Close())
+
+	footer, rows := readParquet(t, buf.Bytes())
+
+	assert.Equal(t, int64(parquetRowGroupSize+3), footer[3])
+	groups := footer[4].([]any)
+	assert.Len(t, groups, 2)
+	assert.Equal(t, int64(parquetRowGroupSize), groups[0].(map[int16]any)[3])
+	assert.Equal(t, int64(3), groups[1].(map[int16]any)[3])
+
+	assert.Len(t, rows, parquetRowGroupSize+3)
+	for _, i := range []int{0, 1, parquetRowGroupSize - 1, parquetRowGroupSize, parquetRowGroupSize + 2} {
+		assert.Equal(t, []any{fmt.Sprintf("product %d", i), i, float64(i) / 4}, rows[i])
+	}
+}
+
+func TestParquetEncoder_Empty(t *testing.T) {
+	var buf bytes.Buffer
+	e := NewParquetEncoder(&buf, testColumns)
+	assert.NoError(t, e.Close())
+
+	footer, rows := readParquet(t, buf.Bytes())
+
+	assert.Equal(t, int64(0), footer[3])
+	assert.Len(t, footer[4], 0)
+	assert.Empty(t, rows)
+}
+
+func encodeTestRows(t *testing.T) []byte {
+	var buf bytes.Buffer
+	e := NewParquetEncoder(&buf, testColumns)
+	for _, row := range testRows {
+		assert.NoError(t, e.WriteRow(row))
+	}
+	assert.NoError(t, e.Close())
+	return buf.Bytes()
+}
+
+func TestParquetEncoder_Golden(t *testing.T) {
+	data := encodeTestRows(t)
+
+	if *update {
+		assert.NoError(t, os.WriteFile(parquetGoldenFile, data, 0644))
+	}
+
+	golden, err := os.ReadFile(parquetGoldenFile)
+	assert.NoError(t, err)
+	assert.Equal(t, golden, data)
+}
+
+// TestParquetEncoder_PyArrow reads the encoded rows with pyarrow, the reference implementation of parquet.
+// It is skipped where pyarrow is not installed
+func TestParquetEncoder_PyArrow(t *testing.T) {
+	if err := exec.Command("python3", "-c", "import pyarrow.parquet").Run(); err != nil {
+		t.Skip("pyarrow is not available, install it with pip install pyarrow")
+	}
+
+	path := filepath.Join(t.TempDir(), "report.parquet")
+	assert.NoError(t, os.WriteFile(path, encodeTestRows(t), 0644))
+
+	for _, file := range []string{parquetGoldenFile, path} {
+		out, err := exec.Command("python3", "testdata/check_parquet.py", file).CombinedOutput()
+		assert.NoError(t, err, "%s: %s", file, out)
+	}
+}
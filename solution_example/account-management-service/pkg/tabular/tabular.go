@@ -0,0 +1,59 @@
+// Package tabular encodes tables row by row into csv, xlsx, json and parquet
+package tabular
+
+import "fmt"
+
+type ColumnType int
+
+const (
+	String ColumnType = iota
+	Int
+	// Decimal is the number with two digits after the point
+	Decimal
+)
+
+type Column struct {
+	Name string
+	Type ColumnType
+}
+
+// Row holds the values of the columns: string, int or float64 matching the column type,
+// nil is the empty cell
+type Row struct {
+	Values []any
+	// Total marks the rows of totals, encoders may highlight them
+	Total bool
+}
+
+// Encoder writes the rows as they come, the output is complete only after Close
+type Encoder interface {
+	WriteRow(row Row) error
+	Close() error
+}
+
+func checkRow(columns []Column, row Row) error {
+	if len(row.Values) != len(columns) {
+		return fmt.Errorf("row has %d values, %d columns expected", len(row.Values), len(columns))
+	}
+
+	for i, value := range row.Values {
+		if value == nil {
+			continue
+		}
+
+		var ok bool
+		switch columns[i].Type {
+		case String:
+			_, ok = value.(string)
+		case Int:
+			_, ok = value.(int)
+		case Decimal:
+			_, ok = value.(float64)
+		}
+		if !ok {
+			return fmt.Errorf("value %v of type %T does not fit column %s", value, value, columns[i].Name)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,69 @@
+package tabular
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+var testColumns = []Column{
+	{Name: "product", Type: String},
+	{Name: "amount", Type: Int},
+	{Name: "share", Type: Decimal},
+}
+
+var testRows = []Row{
+	{Values: []any{"delivery", 100, 0.75}},
+	{Values: []any{"storage, <cold> & \"dry\"", 50, nil}},
+	{Values: []any{nil, -20, 0.5}},
+	{Values: []any{"total", 130, 1.0}, Total: true},
+}
+
+func TestCheckRow(t *testing.T) {
+	testCases := []struct {
+		name    string
+		row     Row
+		wantErr bool
+	}{
+		{
+			name:    "OK",
+			row:     Row{Values: []any{"delivery", 100, 0.75}},
+			wantErr: false,
+		},
+		{
+			name:    "empty cells",
+			row:     Row{Values: []any{nil, nil, nil}},
+			wantErr: false,
+		},
+		{
+			name:    "missing value",
+			row:     Row{Values: []any{"delivery", 100}},
+			wantErr: true,
+		},
+		{
+			name:    "extra value",
+			row:     Row{Values: []any{"delivery", 100, 0.75, 1}},
+			wantErr: true,
+		},
+		{
+			name:    "int in decimal column",
+			row:     Row{Values: []any{"delivery", 100, 1}},
+			wantErr: true,
+		},
+		{
+			name:    "string in int column",
+			row:     Row{Values: []any{"delivery", "100", 0.75}},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := checkRow(testColumns, tc.row)
+			if tc.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
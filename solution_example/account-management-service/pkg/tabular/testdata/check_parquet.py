@@ -0,0 +1,37 @@
+"""Reads the parquet file written by ParquetEncoder with pyarrow and compares it to the test rows.
+
+Usage: python3 check_parquet.py report.parquet
+"""
+import sys
+
+import pyarrow as pa
+import pyarrow.parquet as pq
+
+# testColumns and testRows of tabular_test.go
+SCHEMA = pa.schema([
+    ("product", pa.string()),
+    ("amount", pa.int64()),
+    ("share", pa.float64()),
+])
+ROWS = [
+    {"product": "delivery", "amount": 100, "share": 0.75},
+    {"product": 'storage, <cold> & "dry"', "amount": 50, "share": None},
+    {"product": None, "amount": -20, "share": 0.5},
+    {"product": "total", "amount": 130, "share": 1.0},
+]
+
+
+def main(path):
+    metadata = pq.ParquetFile(path).metadata
+    if metadata.created_by != "account-management-service":
+        sys.exit("unexpected created_by %r" % metadata.created_by)
+
+    table = pq.read_table(path)
+    if not table.schema.equals(SCHEMA):
+        sys.exit("unexpected schema:\n%s" % table.schema)
+    if table.to_pylist() != ROWS:
+        sys.exit("unexpected rows:\n%s" % table.to_pylist())
+
+
+if __name__ == "__main__":
+    main(sys.argv[1])
@@ -0,0 +1,199 @@
+package tabular
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/xml"
+	"io"
+	"strconv"
+)
+
+// The package parts of the workbook with the single sheet, the sheet itself is written row by row
+const (
+	xlsxContentTypes = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+		`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+		`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>` +
+		`</Types>`
+
+	xlsxRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+		`</Relationships>`
+
+	xlsxWorkbook = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+		`<sheets><sheet name="Report" sheetId="1" r:id="rId1"/></sheets>` +
+		`</workbook>`
+
+	xlsxWorkbookRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+		`<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>` +
+		`</Relationships>`
+
+	// xlsxStyles defines the cell formats referenced by the xlsxStyle constants
+	xlsxStyles = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+		`<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts>` +
+		`<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>` +
+		`<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>` +
+		`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>` +
+		`<cellXfs count="4">` +
+		`<xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/>` +
+		`<xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/>` +
+		`<xf numFmtId="2" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+		`<xf numFmtId="2" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1" applyNumberFormat="1"/>` +
+		`</cellXfs>` +
+		`<cellStyles count="1"><cellStyle name="Normal" xfId="0" builtinId="0"/></cellStyles>` +
+		`</styleSheet>`
+
+	// xlsxSheetStart freezes the header row
+	xlsxSheetStart = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+		`<sheetViews><sheetView workbookViewId="0"><pane ySplit="1" topLeftCell="A2" activePane="bottomLeft" state="frozen"/></sheetView></sheetViews>` +
+		`<sheetData>`
+
+	xlsxSheetEnd = `</sheetData></worksheet>`
+)
+
+// Indexes of the cellXfs of xlsxStyles, the bold ones are used for the header and the totals
+const (
+	xlsxStyleDefault     = 0
+	xlsxStyleBold        = 1
+	xlsxStyleDecimal     = 2
+	xlsxStyleBoldDecimal = 3
+)
+
+// XLSXEncoder writes the table as the workbook with the single sheet. Numbers are written as numeric cells,
+// the header and the total rows are bold
+type XLSXEncoder struct {
+	zw      *zip.Writer
+	sheet   *bufio.Writer
+	columns []Column
+	rows    int
+	err     error
+}
+
+func NewXLSXEncoder(w io.Writer, columns []Column) *XLSXEncoder {
+	e := &XLSXEncoder{
+		zw:      zip.NewWriter(w),
+		columns: columns,
+	}
+
+	sheet, err := e.zw.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		e.err = err
+		return e
+	}
+	e.sheet = bufio.NewWriter(sheet)
+	e.sheet.WriteString(xlsxSheetStart)
+
+	header := make([]any, len(columns))
+	for i, column := range columns {
+		header[i] = column.Name
+	}
+	e.writeRow(header, true)
+
+	return e
+}
+
+func (e *XLSXEncoder) WriteRow(row Row) error {
+	if e.err != nil {
+		return e.err
+	}
+	if err := checkRow(e.columns, row); err != nil {
+		return err
+	}
+
+	e.writeRow(row.Values, row.Total)
+	return e.err
+}
+
+func (e *XLSXEncoder) Close() error {
+	if e.err != nil {
+		return e.err
+	}
+
+	e.sheet.WriteString(xlsxSheetEnd)
+	if err := e.sheet.Flush(); err != nil {
+		return err
+	}
+
+	parts := []struct {
+		name    string
+		content string
+	}{
+		{"[Content_Types].xml", xlsxContentTypes},
+		{"_rels/.rels", xlsxRels},
+		{"xl/workbook.xml", xlsxWorkbook},
+		{"xl/_rels/workbook.xml.rels", xlsxWorkbookRels},
+		{"xl/styles.xml", xlsxStyles},
+	}
+	for _, part := range parts {
+		w, err := e.zw.Create(part.name)
+		if err != nil {
+			return err
+		}
+		if _, err = io.WriteString(w, part.content); err != nil {
+			return err
+		}
+	}
+
+	return e.zw.Close()
+}
+
+func (e *XLSXEncoder) writeRow(values []any, bold bool) {
+	e.rows++
+	row := strconv.Itoa(e.rows)
+
+	e.sheet.WriteString(`<row r="` + row + `">`)
+	for i, value := range values {
+		if value == nil {
+			continue
+		}
+
+		ref := xlsxColumnName(i) + row
+		switch v := value.(type) {
+		case string:
+			style := xlsxStyleDefault
+			if bold {
+				style = xlsxStyleBold
+			}
+			e.sheet.WriteString(`<c r="` + ref + `" t="inlineStr" s="` + strconv.Itoa(style) + `"><is><t xml:space="preserve">`)
+			if err := xml.EscapeText(e.sheet, []byte(v)); err != nil {
+				e.err = err
+				return
+			}
+			e.sheet.WriteString(`</t></is></c>`)
+		case int:
+			style := xlsxStyleDefault
+			if bold {
+				style = xlsxStyleBold
+			}
+			e.sheet.WriteString(`<c r="` + ref + `" s="` + strconv.Itoa(style) + `"><v>` + strconv.Itoa(v) + `</v></c>`)
+		case float64:
+			style := xlsxStyleDecimal
+			if bold {
+				style = xlsxStyleBoldDecimal
+			}
+			e.sheet.WriteString(`<c r="` + ref + `" s="` + strconv.Itoa(style) + `"><v>` + strconv.FormatFloat(v, 'f', -1, 64) + `</v></c>`)
+		}
+	}
+
+	if _, err := e.sheet.WriteString(`</row>`); err != nil {
+		e.err = err
+	}
+}
+
+// xlsxColumnName converts the zero based column index to the column name: A, B, ..., Z, AA, AB, ...
+func xlsxColumnName(i int) string {
+	name := ""
+	for i++; i > 0; i = (i - 1) / 26 {
+		name = string(rune('A'+(i-1)%26)) + name
+	}
+	return name
+}
@@ -0,0 +1,123 @@
+package tabular
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"testing"
+)
+
+// xlsxSheet is the part of the worksheet read back by the tests
+type xlsxSheet struct {
+	Pane struct {
+		YSplit string `xml:"ySplit,attr"`
+		State  string `xml:"state,attr"`
+	} `xml:"sheetViews>sheetView>pane"`
+	Rows []struct {
+		R     string `xml:"r,attr"`
+		Cells []struct {
+			R string `xml:"r,attr"`
+			T string `xml:"t,attr"`
+			S string `xml:"s,attr"`
+			V string `xml:"v"`
+			// Is is the inline string
+			Is string `xml:"is>t"`
+		} `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+func TestXLSXEncoder(t *testing.T) {
+	var buf bytes.Buffer
+	e := NewXLSXEncoder(&buf, testColumns)
+	for _, row := range testRows {
+		assert.NoError(t, e.WriteRow(row))
+	}
+	assert.NoError(t, e.Close())
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	assert.NoError(t, err)
+
+	parts := map[string][]byte{}
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		assert.NoError(t, err)
+		parts[f.Name], err = io.ReadAll(rc)
+		assert.NoError(t, err)
+		assert.NoError(t, rc.Close())
+	}
+
+	for _, name := range []string{"[Content_Types].xml", "_rels/.rels", "xl/workbook.xml", "xl/_rels/workbook.xml.rels", "xl/styles.xml"} {
+		assert.Contains(t, parts, name)
+		assert.NoError(t, xml.Unmarshal(parts[name], new(struct{})), name)
+	}
+
+	var sheet xlsxSheet
+	assert.NoError(t, xml.Unmarshal(parts["xl/worksheets/sheet1.xml"], &sheet))
+
+	// the header row is frozen
+	assert.Equal(t, "1", sheet.Pane.YSplit)
+	assert.Equal(t, "frozen", sheet.Pane.State)
+
+	assert.Len(t, sheet.Rows, 5)
+
+	header := sheet.Rows[0]
+	assert.Equal(t, "1", header.R)
+	assert.Len(t, header.Cells, 3)
+	for i, name := range []string{"product", "amount", "share"} {
+		assert.Equal(t, xlsxColumnName(i)+"1", header.Cells[i].R)
+		assert.Equal(t, "inlineStr", header.Cells[i].T)
+		assert.Equal(t, "1", header.Cells[i].S)
+		assert.Equal(t, name, header.Cells[i].Is)
+	}
+
+	first := sheet.Rows[1]
+	assert.Equal(t, "delivery", first.Cells[0].Is)
+	assert.Equal(t, "0", first.Cells[0].S)
+	// numbers are numeric cells, decimals have the two digits format
+	assert.Equal(t, "", first.Cells[1].T)
+	assert.Equal(t, "100", first.Cells[1].V)
+	assert.Equal(t, "0", first.Cells[1].S)
+	assert.Equal(t, "0.75", first.Cells[2].V)
+	assert.Equal(t, "2", first.Cells[2].S)
+
+	// the text is escaped and the empty cells are skipped
+	second := sheet.Rows[2]
+	assert.Len(t, second.Cells, 2)
+	assert.Equal(t, "storage, <cold> & \"dry\"", second.Cells[0].Is)
+	assert.Equal(t, "B3", second.Cells[1].R)
+
+	third := sheet.Rows[3]
+	assert.Len(t, third.Cells, 2)
+	assert.Equal(t, "B4", third.Cells[0].R)
+	assert.Equal(t, "-20", third.Cells[0].V)
+
+	// the total row is bold
+	total := sheet.Rows[4]
+	assert.Equal(t, "total", total.Cells[0].Is)
+	assert.Equal(t, "1", total.Cells[0].S)
+	assert.Equal(t, "1", total.Cells[1].S)
+	assert.Equal(t, "1", total.Cells[2].V)
+	assert.Equal(t, "3", total.Cells[2].S)
+}
+
+func TestXLSXColumnName(t *testing.T) {
+	testCases := []struct {
+		i    int
+		want string
+	}{
+		{i: 0, want: "A"},
+		{i: 25, want: "Z"},
+		{i: 26, want: "AA"},
+		{i: 27, want: "AB"},
+		{i: 701, want: "ZZ"},
+		{i: 702, want: "AAA"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.want, func(t *testing.T) {
+			assert.Equal(t, tc.want, xlsxColumnName(tc.i))
+		})
+	}
+}
@@ -72,6 +72,10 @@ func (cv *CustomValidator) newValidationError(field string, value interface{}, t
 		return fmt.Errorf("field %s must be at least %s characters", field, param)
 	case "max":
 		return fmt.Errorf("field %s must be at most %s characters", field, param)
+	case "gt":
+		return fmt.Errorf("field %s must be greater than %s", field, param)
+	case "oneof":
+		return fmt.Errorf("field %s must be one of: %s", field, param)
 	default:
 		return fmt.Errorf("field %s is invalid", field)
 	}